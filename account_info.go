@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// userAccountClient returns the client for the user's own credentials if connected,
+// otherwise the main account.
+func userAccountClient(userID int64) (*BinanceClient, string, error) {
+	client, err := userClient(userID)
+	if err != nil {
+		return nil, personalAccountName, err
+	}
+	if client != nil {
+		return client, personalAccountName, nil
+	}
+	client, err = accountClient(defaultAccountName)
+	return client, defaultAccountName, err
+}
+
+// userAccountExchange is userAccountClient for commands that also work on a connected Bybit account.
+func userAccountExchange(userID int64) (Exchange, string, error) {
+	exchange, err := userExchange(userID)
+	if err != nil || exchange != nil {
+		return exchange, personalAccountName, err
+	}
+	client, err := accountClient(defaultAccountName)
+	if err != nil {
+		return nil, defaultAccountName, err
+	}
+	return client, defaultAccountName, nil
+}
+
+// handlePositionsCommand lists the open USDT-M positions on the sender's account.
+func handlePositionsCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	exchange, account, err := userAccountExchange(senderID(message))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Account %s is unavailable: %v", account, err)))
+		return
+	}
+
+	positions, err := exchange.Positions(context.Background())
+	if err != nil {
+		binanceLog.Error("Failed to get positions", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to get positions: %v", err)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatPositions(chatID, account, positions))
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
+
+// formatPositions renders the open positions on an account with their unrealized PnL and the total.
+func formatPositions(chatID int64, account string, positions []ExchangePosition) string {
+	text := tr(chatID, "<b>Open positions (%s)</b>\n", account)
+	open := 0
+	var totalPnL float64
+	for _, position := range positions {
+		open++
+		totalPnL += position.UnrealizedPnL
+
+		side := tr(chatID, "Long")
+		if position.Amount < 0 {
+			side = tr(chatID, "Short")
+		}
+		text += fmt.Sprintf("\n<b>%s</b> %s %s\n", position.Symbol, side, formatFloat(position.Amount))
+		text += tr(chatID, "Entry: %s | Mark: %s | Liq.: %s\n", formatFloat(position.EntryPrice), formatFloat(position.MarkPrice), formatFloat(position.LiquidationPrice))
+		text += tr(chatID, "Unrealized PnL: %.2f USDT\n", position.UnrealizedPnL)
+	}
+	if open == 0 {
+		text += tr(chatID, "\nNo open positions.")
+	} else {
+		text += tr(chatID, "\n<b>Total unrealized PnL:</b> %.2f USDT", totalPnL)
+	}
+	return text
+}
+
+// handleBalanceCommand shows the futures wallet balances on the sender's account.
+func handleBalanceCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	exchange, account, err := userAccountExchange(senderID(message))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Account %s is unavailable: %v", account, err)))
+		return
+	}
+
+	balances, err := exchange.Balance(context.Background())
+	if err != nil {
+		binanceLog.Error("Failed to get balance", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to get balance: %v", err)))
+		return
+	}
+
+	text := tr(chatID, "<b>Futures balance (%s)</b>\n", account)
+	shown := 0
+	for _, balance := range balances {
+		if balance.Wallet == 0 {
+			continue
+		}
+		shown++
+		text += tr(chatID, "\n<b>%s</b>: %s (available %s, unrealized PnL %.2f)\n",
+			balance.Asset, formatFloat(balance.Wallet), formatFloat(balance.Available), balance.UnrealizedPnL)
+	}
+	if shown == 0 {
+		text += tr(chatID, "\nNo funds in the futures wallet.")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
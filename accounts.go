@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// defaultAccountName refers to the Binance credentials from the main configuration.
+const defaultAccountName = "main"
+
+// Routing rule match types.
+const (
+	RouteMatchSymbol = "symbol"
+	RouteMatchSource = "source"
+)
+
+// BinanceAccount holds an additional API key pair, e.g. for a sub-account. Binance accounts
+// use the Binance API URL from the main configuration; Bybit accounts use BYBIT_API_URL.
+type BinanceAccount struct {
+	ID         uint    `gorm:"primaryKey"`
+	Name       string  `gorm:"uniqueIndex;size:64"`
+	Exchange   string  `gorm:"size:16"` // ExchangeBinance or ExchangeBybit; empty for accounts added before Bybit
+	APIKey     string  `gorm:"serializer:encrypted"`
+	APISecret  string  `gorm:"serializer:encrypted"`
+	Mirror     bool    // Also execute signals confirmed on other accounts
+	SizeFactor float64 // Multiplies the trade amount of mirrored signals; 0 means 1
+}
+
+// sizeFactor returns the multiplier applied to the amount of signals mirrored to the account.
+func (a *BinanceAccount) sizeFactor() float64 {
+	if a.SizeFactor <= 0 {
+		return 1
+	}
+	return a.SizeFactor
+}
+
+// RoutingRule sends signals matching a symbol or source to a specific account.
+type RoutingRule struct {
+	ID          uint   `gorm:"primaryKey"`
+	MatchType   string // RouteMatchSymbol or RouteMatchSource
+	Pattern     string // Symbol (e.g. BTCUSDT) or signal source, compared case-insensitively
+	AccountName string
+}
+
+// ListBinanceAccounts retrieves all additional Binance accounts ordered by name.
+func ListBinanceAccounts() ([]BinanceAccount, error) {
+	var accounts []BinanceAccount
+	if err := db.Order("name").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetBinanceAccount retrieves an additional Binance account by name.
+func GetBinanceAccount(name string) (*BinanceAccount, error) {
+	var account BinanceAccount
+	if err := db.Where("name = ?", name).First(&account).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve account: %w", err)
+	}
+	return &account, nil
+}
+
+// SaveBinanceAccount creates or updates an additional Binance account.
+func SaveBinanceAccount(account *BinanceAccount) error {
+	if err := db.Save(account).Error; err != nil {
+		return fmt.Errorf("failed to save account: %w", err)
+	}
+	accountClients.Delete(account.Name)
+	accountBybitClients.Delete(account.Name)
+	return nil
+}
+
+// SetAccountMirror turns mirroring of signals to an additional account on or off and sets the
+// factor its trade amount is scaled by.
+func SetAccountMirror(id uint, mirror bool, sizeFactor float64) error {
+	err := db.Model(&BinanceAccount{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"mirror": mirror, "size_factor": sizeFactor}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	return nil
+}
+
+// DeleteBinanceAccount removes an additional Binance account along with its routing rules.
+func DeleteBinanceAccount(id uint) error {
+	var account BinanceAccount
+	if err := db.First(&account, id).Error; err != nil {
+		return fmt.Errorf("failed to retrieve account: %w", err)
+	}
+	if err := db.Where("account_name = ?", account.Name).Delete(&RoutingRule{}).Error; err != nil {
+		return fmt.Errorf("failed to delete routing rules: %w", err)
+	}
+	if err := db.Delete(&account).Error; err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	accountClients.Delete(account.Name)
+	accountBybitClients.Delete(account.Name)
+	return nil
+}
+
+// ListRoutingRules retrieves all routing rules.
+func ListRoutingRules() ([]RoutingRule, error) {
+	var rules []RoutingRule
+	if err := db.Order("id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve routing rules: %w", err)
+	}
+	return rules, nil
+}
+
+// SaveRoutingRule creates or updates a routing rule.
+func SaveRoutingRule(rule *RoutingRule) error {
+	if err := db.Save(rule).Error; err != nil {
+		return fmt.Errorf("failed to save routing rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRoutingRule removes a routing rule.
+func DeleteRoutingRule(id uint) error {
+	if err := db.Delete(&RoutingRule{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete routing rule: %w", err)
+	}
+	return nil
+}
+
+// resolveAccount returns the name of the account that should execute the signal.
+// Symbol rules take precedence over source rules; without a match the main account is used.
+func resolveAccount(signal *AlertMessage) string {
+	rules, err := ListRoutingRules()
+	if err != nil {
+		binanceLog.Error("Failed to load routing rules", "error", err)
+		return defaultAccountName
+	}
+
+	var sourceMatch string
+	for _, rule := range rules {
+		switch rule.MatchType {
+		case RouteMatchSymbol:
+			if strings.EqualFold(rule.Pattern, signal.Symbol) {
+				return rule.AccountName
+			}
+		case RouteMatchSource:
+			if sourceMatch == "" && signal.Source != "" && strings.EqualFold(rule.Pattern, signal.Source) {
+				sourceMatch = rule.AccountName
+			}
+		}
+	}
+	if sourceMatch != "" {
+		return sourceMatch
+	}
+	return defaultAccountName
+}
+
+// AccountClientStore caches Binance clients for additional accounts with concurrency safety.
+type AccountClientStore struct {
+	sync.RWMutex
+	clients map[string]*BinanceClient
+}
+
+// NewAccountClientStore creates a new instance of AccountClientStore.
+func NewAccountClientStore() *AccountClientStore {
+	return &AccountClientStore{
+		clients: make(map[string]*BinanceClient),
+	}
+}
+
+func (s *AccountClientStore) Set(name string, client *BinanceClient) {
+	s.Lock()
+	defer s.Unlock()
+	s.clients[name] = client
+}
+
+func (s *AccountClientStore) Get(name string) (*BinanceClient, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	client, exists := s.clients[name]
+	return client, exists
+}
+
+// Delete removes and closes a cached client, so the next use creates it from the current keys.
+func (s *AccountClientStore) Delete(name string) {
+	s.Lock()
+	defer s.Unlock()
+	if client, exists := s.clients[name]; exists {
+		client.Close()
+		delete(s.clients, name)
+	}
+}
+
+// Clear removes and closes every cached client.
+func (s *AccountClientStore) Clear() {
+	s.Lock()
+	defer s.Unlock()
+	for name, client := range s.clients {
+		client.Close()
+		delete(s.clients, name)
+	}
+}
+
+var accountClients = NewAccountClientStore()
+
+// accountClient returns the Binance client for the named account, creating it on first use.
+func accountClient(name string) (*BinanceClient, error) {
+	if name == "" || name == defaultAccountName {
+		client := mainBinanceClient()
+		if client == nil {
+			return nil, errors.New("Binance client is not initialized")
+		}
+		return client, nil
+	}
+
+	if client, exists := accountClients.Get(name); exists {
+		return client, nil
+	}
+
+	account, err := GetBinanceAccount(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("account %q does not exist", name)
+		}
+		return nil, err
+	}
+	if account.Exchange == ExchangeBybit {
+		return nil, errNotBinance
+	}
+
+	client := newBinanceClientWithKeys(bot, name, account.APIKey, account.APISecret)
+	if err := client.testAPIKey(); err != nil {
+		return nil, fmt.Errorf("API key test failed for account %q: %v", name, err)
+	}
+	accountClients.Set(name, client)
+	return client, nil
+}
+
+// accountBybitClients caches Bybit clients for additional Bybit accounts, keyed by name.
+var accountBybitClients = NewBybitClientStore()
+
+// accountExchange returns the exchange client for the named account, Binance or Bybit,
+// creating it on first use.
+func accountExchange(name string) (Exchange, error) {
+	if client, exists := accountBybitClients.Get(name); exists {
+		return client, nil
+	}
+
+	client, err := accountClient(name)
+	if errors.Is(err, errNotBinance) {
+		account, err := GetBinanceAccount(name)
+		if err != nil {
+			return nil, err
+		}
+		bybit := newBybitClient(account.APIKey, account.APISecret)
+		accountBybitClients.Set(name, bybit)
+		return bybit, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
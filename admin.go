@@ -1,29 +1,32 @@
 package main
 
 import (
-	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/gorilla/csrf"
-	"github.com/gorilla/sessions"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-// Session management
-var store *sessions.CookieStore
-
 // Templates
 var templates *template.Template
 
-// Admin credentials
-const (
-	adminUsername = "admin"
-)
+// signalsPageEntries is how many signals the admin signals page shows.
+const signalsPageEntries = 200
+
+// logsPageEntries is how many log entries the admin logs page shows before following new ones.
+const logsPageEntries = 500
 
 // ConfigPageData holds data passed to the config template
 type ConfigPageData struct {
@@ -34,34 +37,217 @@ type ConfigPageData struct {
 	SuccessMessage    string
 }
 
+// AccountsPageData holds data passed to the accounts template
+type AccountsPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Accounts          []BinanceAccount
+	Rules             []RoutingRule
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// AuditPageData holds data passed to the audit log template
+type AuditPageData struct {
+	Entries []AuditLog
+	Limit   int
+}
+
+// SignalsPageData holds data passed to the signals template
+type SignalsPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Signals           []SignalsPageEntry
+	Statuses          []string
+	Status            string // Filter values as entered
+	Symbol            string
+	From              string
+	To                string
+	Limit             int
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// SignalsPageEntry is a stored signal shown on the signals page
+type SignalsPageEntry struct {
+	Signal    Signal
+	Available bool // Still held by the bot, so it can be sent again
+	Pending   bool // Neither confirmed nor dismissed, so it can be answered
+}
+
+// TokensPageData holds data passed to the API tokens template
+type TokensPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Tokens            []APIToken
+	NewToken          string // Shown once after it is created
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// WebhooksPageData holds data passed to the outgoing webhooks template
+type WebhooksPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Webhooks          []OutgoingWebhook
+	Events            []string
+	NewSecret         string // Shown once after a webhook is created
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// FiltersPageData holds data passed to the signal filters template
+type FiltersPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Filters           []FilterRule
+	Fields            []string
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// BacktestPageData holds data passed to the backtest template
+type BacktestPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Running           bool
+	Done, Total       int             // Signals replayed so far by the running backtest
+	Report            *BacktestReport // Last finished backtest, nil before the first
+	LastError         string          // Why the last backtest failed
+	DefaultDays       int
+	MaxDays           int
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// DashboardPageData holds data passed to the dashboard template
+type DashboardPageData struct {
+	CSRFTemplateField template.HTML
+	Stats             DashboardStats
+	RefreshSeconds    int
+	UpdatedAt         time.Time
+}
+
+// ConfigHistoryPageData holds data passed to the config history template
+type ConfigHistoryPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Versions          []ConfigHistoryEntry
+	Limit             int
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// ConfigHistoryEntry is a configuration version shown on the config history page
+type ConfigHistoryEntry struct {
+	Version ConfigVersion
+	Config  Config
+	Current bool
+}
+
+// PasswordPageData holds data passed to the password template
+type PasswordPageData struct {
+	CSRFToken          string
+	CSRFTemplateField  template.HTML
+	Username           string
+	MustChangePassword bool
+	MinLength          int
+	ErrorMessage       string
+	SuccessMessage     string
+}
+
+// AdminUsersPageData holds data passed to the admin accounts template
+type AdminUsersPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Users             []AdminUser
+	Current           string // Username of the logged-in account
+	MinLength         int
+	NewPassword       string // Temporary password after a reset, shown once
+	NewPasswordFor    string
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// UsersPageData holds data passed to the Telegram users template
+type UsersPageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Users             []TelegramUser
+	Editing           *TelegramUser // User whose settings form is shown
+	TPLevels          string        // Editing's TP levels for the form
+	RolesEnforced     bool
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// TradePageData holds data passed to the trade console template
+type TradePageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Form              url.Values // Submitted values, kept when the signal is rejected
+	MaxTPs            int
+	CanConfirm        bool // An Admin User ID is configured to confirm signals as
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
+// LogsPageData holds data passed to the logs template
+type LogsPageData struct {
+	Entries []LogEntry
+	Levels  []string
+	Level   string // Filter values as entered
+	Search  string
+	Query   string // The filter as a query string, for following and downloading
+	LastID  uint64 // Last entry shown, followed on from there
+	Limit   int
+}
+
+// WebhookArchivePageData holds data passed to the webhook archive template
+type WebhookArchivePageData struct {
+	CSRFToken         string
+	CSRFTemplateField template.HTML
+	Payloads          []WebhookPayload
+	Outcomes          []string
+	Outcome           string // Filter values as entered
+	Search            string
+	Limit             int
+	ErrorMessage      string
+	SuccessMessage    string
+}
+
 // LoginPageData holds data passed to the login template
 type LoginPageData struct {
 	CSRFToken         string
 	CSRFTemplateField template.HTML
+	RememberDays      int // 0 hides "Remember me"
 	ErrorMessage      string
 }
 
-// initAdmin initializes session store and templates.
-func initAdmin() {
+// initAdmin initializes session store and templates. secure limits the session cookie to
+// HTTPS.
+func initAdmin(secure bool) {
 	// Use a persistent session secret from an environment variable
 	sessionSecret := os.Getenv("SESSION_SECRET")
 	if sessionSecret == "" {
-		log.Fatal("SESSION_SECRET environment variable is not set")
+		adminLog.Error("SESSION_SECRET environment variable is not set")
+		os.Exit(1)
 	}
-	store = sessions.NewCookieStore([]byte(sessionSecret))
+	initSessionStore(sessionSecret, secure)
 
-	// Optional: Set secure session options
-	store.Options = &sessions.Options{
-		HttpOnly: true,
-		Path:     "/",
-		Secure:   true, // Set to true if using HTTPS
+	if err := ensureAdminUser(); err != nil {
+		adminLog.Error("Failed to set up admin accounts", "error", err)
+		os.Exit(1)
 	}
 
 	// Load templates
 	var err error
-	templates, err = template.ParseFiles("templates/login.html", "templates/config.html")
+	templates, err = template.New("").
+		Funcs(template.FuncMap{"maskKey": maskKey, "formatFloat": formatFloat, "exchangeName": exchangeName}).
+		ParseFiles("templates/login.html", "templates/config.html", "templates/accounts.html", "templates/audit.html", "templates/config_history.html", "templates/dashboard.html", "templates/signals.html", "templates/tokens.html", "templates/webhooks.html", "templates/filters.html", "templates/backtest.html", "templates/password.html", "templates/admins.html", "templates/users.html", "templates/trade.html", "templates/logs.html", "templates/webhook_archive.html")
 	if err != nil {
-		log.Fatalf("Error parsing templates: %v", err)
+		adminLog.Error("Error parsing templates", "error", err)
+		os.Exit(1)
 	}
 }
 
@@ -71,10 +257,14 @@ func adminLoginHandler(w http.ResponseWriter, r *http.Request) {
 		data := LoginPageData{
 			CSRFToken:         csrf.Token(r),
 			CSRFTemplateField: csrf.TemplateField(r),
+			RememberDays:      int(sessionRememberFor.Hours() / 24),
+		}
+		if r.URL.Query().Get("expired") != "" {
+			data.ErrorMessage = "Your session has expired. Please log in again"
 		}
 		// Render the login page
 		if err := templates.ExecuteTemplate(w, "login.html", data); err != nil {
-			log.Printf("Error rendering login template: %v", err)
+			adminLog.Error("Error rendering login template", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 		return
@@ -82,7 +272,7 @@ func adminLoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Process login form submission
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing login form: %v", err)
+		adminLog.Error("Error parsing login form", "error", err)
 		http.Error(w, "Invalid form data", http.StatusBadRequest)
 		return
 	}
@@ -91,32 +281,103 @@ func adminLoginHandler(w http.ResponseWriter, r *http.Request) {
 	password := r.FormValue("password")
 
 	// Authenticate user
-	if authenticateUser(username, password) {
-		session, _ := store.Get(r, "session-name")
-		session.Values["authenticated"] = true
-		session.Save(r, w)
+	user, err := authenticateAdmin(username, password)
+	if err == nil {
+		auditAdmin(user.Username, AuditLogin, "from "+requestHost(r))
+		startAdminSession(w, r, user, r.FormValue("remember") == "on")
 
-		http.Redirect(w, r, "/admin/config", http.StatusSeeOther)
-	} else {
-		data := LoginPageData{
-			CSRFToken:         csrf.Token(r),
-			CSRFTemplateField: csrf.TemplateField(r),
-			ErrorMessage:      "Invalid credentials",
+		if user.MustChangePassword {
+			http.Redirect(w, r, "/admin/password", http.StatusSeeOther)
+			return
 		}
-		if err := templates.ExecuteTemplate(w, "login.html", data); err != nil {
-			log.Printf("Error rendering login template: %v", err)
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	var errorMessage string
+	switch {
+	case errors.Is(err, errAccountLocked):
+		auditAdmin(user.Username, AuditLoginFailed, "locked, from "+requestHost(r))
+		errorMessage = fmt.Sprintf("Too many failed logins. The account is locked until %s UTC", user.LockedUntil.UTC().Format("15:04"))
+	case errors.Is(err, errInvalidCredentials):
+		if user != nil {
+			auditAdmin(user.Username, AuditLoginFailed, "from "+requestHost(r))
+		} else {
+			auditAdmin("", AuditLoginFailed, fmt.Sprintf("unknown account %q from %s", truncateAuditText(username, 64), requestHost(r)))
+		}
+		errorMessage = "Invalid credentials"
+	default:
+		adminLog.Error("Error checking login", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	data := LoginPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		RememberDays:      int(sessionRememberFor.Hours() / 24),
+		ErrorMessage:      errorMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "login.html", data); err != nil {
+		adminLog.Error("Error rendering login template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// requireAdmin returns the logged-in admin account and renews its session. Without one it
+// redirects to the login page and returns false, as it does for deleted accounts and sessions
+// from before a password change; accounts that must change their password are sent to the
+// password page.
+func requireAdmin(w http.ResponseWriter, r *http.Request) (*AdminUser, bool) {
+	username, version, expired := loadAdminSession(w, r)
+	if username == "" {
+		if expired {
+			http.Redirect(w, r, "/admin/login?expired=1", http.StatusFound)
+		} else {
+			http.Redirect(w, r, "/admin/login", http.StatusFound)
+		}
+		return nil, false
+	}
+	user, err := GetAdminUser(username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			adminLog.Error("Error fetching admin account", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return nil, false
 		}
+		endAdminSession(w, r)
+		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		return nil, false
+	}
+	if user.SessionVersion != version {
+		endAdminSession(w, r)
+		http.Redirect(w, r, "/admin/login?expired=1", http.StatusFound)
+		return nil, false
+	}
+	if user.MustChangePassword && r.URL.Path != "/admin/password" {
+		http.Redirect(w, r, "/admin/password", http.StatusFound)
+		return nil, false
+	}
+	return user, true
+}
+
+// adminLogoutHandler ends the admin session. It only accepts POST, so other sites cannot log
+// admins out with a link.
+func adminLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
+		return
 	}
+	if username, _, _ := loadAdminSession(w, r); username != "" {
+		auditAdmin(username, AuditLogout, "from "+requestHost(r))
+	}
+	endAdminSession(w, r)
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
 }
 
 // adminConfigHandler handles the configuration page.
 func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 	// Check if user is authenticated
-	session, _ := store.Get(r, "session-name")
-	auth, ok := session.Values["authenticated"].(bool)
-	if !ok || !auth {
-		http.Redirect(w, r, "/admin/login", http.StatusFound)
+	if _, ok := requireAdmin(w, r); !ok {
 		return
 	}
 
@@ -124,7 +385,7 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 		// Fetch current config from the database
 		config, err := getConfig()
 		if err != nil {
-			log.Printf("Error fetching config: %v", err)
+			adminLog.Error("Error fetching config", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
@@ -134,7 +395,7 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 			Config:            *config,
 		}
 		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
-			log.Printf("Error rendering config template: %v", err)
+			adminLog.Error("Error rendering config template", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 		return
@@ -142,7 +403,7 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Process config form submission
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing config form: %v", err)
+		adminLog.Error("Error parsing config form", "error", err)
 		http.Error(w, "Invalid form data", http.StatusBadRequest)
 		return
 	}
@@ -152,6 +413,27 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 	binanceAPIKey := r.FormValue("binance_api_key")
 	binanceAPISecret := r.FormValue("binance_api_secret")
 	binanceAPIURL := r.FormValue("binance_api_url")
+	orderIDPrefix := r.FormValue("order_id_prefix")
+	adminUserIDStr := r.FormValue("admin_user_id")
+	alertChatIDStr := r.FormValue("alert_chat_id")
+	discordWebhookURL := strings.TrimSpace(r.FormValue("discord_webhook_url"))
+	broadcastToTraders := r.FormValue("broadcast_to_traders") == "on"
+	dailySummary := r.FormValue("daily_summary") == "on"
+	weeklySummary := r.FormValue("weekly_summary") == "on"
+	monthlyReport := r.FormValue("monthly_report") == "on"
+	reportEmail := strings.TrimSpace(r.FormValue("report_email"))
+	summaryHourStr := r.FormValue("summary_hour")
+	messageRetentionStr := r.FormValue("message_retention_hours")
+	dualConfirmStr := r.FormValue("dual_confirm_notional")
+	exposureLimitStr := r.FormValue("exposure_limit_percent")
+	newsHaltStr := r.FormValue("news_halt_minutes")
+	newsCurrencies := strings.ToUpper(strings.TrimSpace(r.FormValue("news_currencies")))
+	signalExpiryBySource := strings.TrimSpace(r.FormValue("signal_expiry_by_source"))
+	entryTypeBySource := strings.ToLower(strings.TrimSpace(r.FormValue("entry_type_by_source")))
+	duplicateGuard := r.FormValue("duplicate_guard") == "on"
+	duplicateGuardStr := r.FormValue("duplicate_guard_minutes")
+	tradingHalted := r.FormValue("trading_halted") == "on"
+	recvWindowStr := r.FormValue("binance_recv_window")
 
 	// Validate inputs
 	if botToken == "" || chatIDStr == "" || binanceAPIKey == "" || binanceAPISecret == "" || binanceAPIURL == "" {
@@ -164,11 +446,12 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 				BinanceAPIKey:    binanceAPIKey,
 				BinanceAPISecret: binanceAPISecret,
 				BinanceAPIURL:    binanceAPIURL,
+				OrderIDPrefix:    orderIDPrefix,
 				// Include previously entered data to retain user input
 			},
 		}
 		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
-			log.Printf("Error rendering config template: %v", err)
+			adminLog.Error("Error rendering config template", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 		return
@@ -186,16 +469,331 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 				BinanceAPIKey:    binanceAPIKey,
 				BinanceAPISecret: binanceAPISecret,
 				BinanceAPIURL:    binanceAPIURL,
+				OrderIDPrefix:    orderIDPrefix,
 				// Include previously entered data to retain user input
 			},
 		}
 		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
-			log.Printf("Error rendering config template: %v", err)
+			adminLog.Error("Error rendering config template", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The admin user ID is optional; without it bot roles are not enforced
+	var adminUserID int64
+	if adminUserIDStr != "" {
+		adminUserID, err = strconv.ParseInt(adminUserIDStr, 10, 64)
+		if err != nil {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      "Invalid Admin User ID",
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// The alert chat is optional; without it failures are sent to the admin user
+	var alertChatID int64
+	if alertChatIDStr != "" {
+		alertChatID, err = strconv.ParseInt(alertChatIDStr, 10, 64)
+		if err != nil {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      "Invalid Error Alert Chat ID",
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// The summary hour is optional and defaults to midnight
+	var summaryHour int
+	if summaryHourStr != "" {
+		summaryHour, err = strconv.Atoi(summaryHourStr)
+		if err != nil || summaryHour < 0 || summaryHour > 23 {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      "Summary Hour must be a whole number from 0 to 23",
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// Message retention is optional and defaults to keeping messages
+	var messageRetentionHours int
+	if messageRetentionStr != "" {
+		messageRetentionHours, err = strconv.Atoi(messageRetentionStr)
+		if err != nil || messageRetentionHours < 0 || messageRetentionHours > maxMessageRetentionHours {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      fmt.Sprintf("Message Retention must be a whole number of hours from 0 to %d", maxMessageRetentionHours),
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// The two-trader limit is optional and defaults to off
+	var dualConfirmNotional float64
+	if dualConfirmStr != "" {
+		dualConfirmNotional, err = strconv.ParseFloat(dualConfirmStr, 64)
+		if err != nil || dualConfirmNotional < 0 {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      "Two-Trader Confirmation Limit must be a non-negative number of USDT",
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// The exposure warning is optional and defaults to off
+	var exposureLimit float64
+	if exposureLimitStr != "" {
+		exposureLimit, err = strconv.ParseFloat(exposureLimitStr, 64)
+		if err != nil || exposureLimit < 0 {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      "Exposure Warning must be a non-negative percentage of equity",
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// News halts are optional and default to off
+	var newsHaltMinutes int
+	if newsHaltStr != "" {
+		newsHaltMinutes, err = strconv.Atoi(newsHaltStr)
+		if err != nil || newsHaltMinutes < 0 || newsHaltMinutes > maxNewsHaltMinutes {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      fmt.Sprintf("News Halt must be a whole number of minutes from 0 to %d", maxNewsHaltMinutes),
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// The duplicate guard window is optional and defaults to open positions only
+	var duplicateGuardMinutes int
+	if duplicateGuardStr != "" {
+		duplicateGuardMinutes, err = strconv.Atoi(duplicateGuardStr)
+		if err != nil || duplicateGuardMinutes < 0 {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      "Duplicate Guard Window must be a whole number of minutes, 0 or more",
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// Per-source signal expiry is optional; signals expire by their timeframe without it
+	if _, err := parseSignalExpiry(signalExpiryBySource); err != nil {
+		data := ConfigPageData{
+			CSRFToken:         csrf.Token(r),
+			CSRFTemplateField: csrf.TemplateField(r),
+			ErrorMessage:      fmt.Sprintf("Signal Expiry by Source: %v", err),
+			Config: Config{
+				TelegramBotToken: botToken,
+				TelegramChatID:   chatID,
+				BinanceAPIKey:    binanceAPIKey,
+				BinanceAPISecret: binanceAPISecret,
+				BinanceAPIURL:    binanceAPIURL,
+				OrderIDPrefix:    orderIDPrefix,
+				AdminUserID:      adminUserID,
+			},
+		}
+		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+			adminLog.Error("Error rendering config template", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Per-source entry types are optional; signals use the user's Trading Mode without them
+	if _, err := parseEntryTypes(entryTypeBySource); err != nil {
+		data := ConfigPageData{
+			CSRFToken:         csrf.Token(r),
+			CSRFTemplateField: csrf.TemplateField(r),
+			ErrorMessage:      fmt.Sprintf("Entry Type by Source: %v", err),
+			Config: Config{
+				TelegramBotToken: botToken,
+				TelegramChatID:   chatID,
+				BinanceAPIKey:    binanceAPIKey,
+				BinanceAPISecret: binanceAPISecret,
+				BinanceAPIURL:    binanceAPIURL,
+				OrderIDPrefix:    orderIDPrefix,
+				AdminUserID:      adminUserID,
+			},
+		}
+		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+			adminLog.Error("Error rendering config template", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 		return
 	}
 
+	// recvWindow is optional and defaults to Binance's
+	var recvWindow int
+	if recvWindowStr != "" {
+		recvWindow, err = strconv.Atoi(recvWindowStr)
+		if err != nil || recvWindow < 0 || recvWindow > maxRecvWindow {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      fmt.Sprintf("Binance recvWindow must be between 0 and %d milliseconds", maxRecvWindow),
+				Config: Config{
+					TelegramBotToken: botToken,
+					TelegramChatID:   chatID,
+					BinanceAPIKey:    binanceAPIKey,
+					BinanceAPISecret: binanceAPISecret,
+					BinanceAPIURL:    binanceAPIURL,
+					OrderIDPrefix:    orderIDPrefix,
+					AdminUserID:      adminUserID,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// The Discord webhook is optional
+	if discordWebhookURL != "" {
+		if err := validateDiscordWebhookURL(discordWebhookURL); err != nil {
+			data := ConfigPageData{
+				CSRFToken:         csrf.Token(r),
+				CSRFTemplateField: csrf.TemplateField(r),
+				ErrorMessage:      err.Error(),
+				Config: Config{
+					TelegramBotToken:  botToken,
+					TelegramChatID:    chatID,
+					BinanceAPIKey:     binanceAPIKey,
+					BinanceAPISecret:  binanceAPISecret,
+					BinanceAPIURL:     binanceAPIURL,
+					OrderIDPrefix:     orderIDPrefix,
+					AdminUserID:       adminUserID,
+					DiscordWebhookURL: discordWebhookURL,
+				},
+			}
+			if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
+				adminLog.Error("Error rendering config template", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
 	// Save config to the database
 	newConfig := Config{
 		TelegramBotToken: botToken,
@@ -203,6 +801,30 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 		BinanceAPIKey:    binanceAPIKey,
 		BinanceAPISecret: binanceAPISecret,
 		BinanceAPIURL:    binanceAPIURL,
+		OrderIDPrefix:    orderIDPrefix,
+		AdminUserID:      adminUserID,
+		AlertChatID:      alertChatID,
+
+		BinanceRecvWindow: recvWindow,
+		DiscordWebhookURL: discordWebhookURL,
+
+		BroadcastToTraders: broadcastToTraders,
+		DailySummary:       dailySummary,
+		WeeklySummary:      weeklySummary,
+		SummaryHour:        summaryHour,
+		MonthlyReport:      monthlyReport,
+		ReportEmail:        reportEmail,
+
+		MessageRetentionHours: messageRetentionHours,
+		DualConfirmNotional:   dualConfirmNotional,
+		ExposureLimitPercent:  exposureLimit,
+		NewsHaltMinutes:       newsHaltMinutes,
+		NewsCurrencies:        newsCurrencies,
+		SignalExpiryBySource:  signalExpiryBySource,
+		EntryTypeBySource:     entryTypeBySource,
+		DuplicateGuard:        duplicateGuard,
+		DuplicateGuardMinutes: duplicateGuardMinutes,
+		TradingHalted:         tradingHalted,
 	}
 
 	// Validate Telegram API key
@@ -214,7 +836,7 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 			Config:            newConfig,
 		}
 		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
-			log.Printf("Error rendering config template: %v", err)
+			adminLog.Error("Error rendering config template", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 		return
@@ -229,33 +851,21 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 			Config:            newConfig,
 		}
 		if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
-			log.Printf("Error rendering config template: %v", err)
+			adminLog.Error("Error rendering config template", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 		return
 	}
 
-	err = saveConfig(&newConfig)
+	err = saveConfig(&newConfig, adminAuthor(r), "")
 	if err != nil {
-		log.Printf("Error saving config: %v", err)
+		adminLog.Error("Error saving config", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-
-	// Update GlobalConfig
+	// The listeners added with onConfigChange pick up the change
 	SetGlobalConfig(newConfig)
 
-	// Re-initialize the Telegram bot asynchronously
-	go func() {
-		botInstance, err := initTelegramBot(&newConfig)
-		if err != nil {
-			log.Printf("Error initializing Telegram bot: %v", err)
-		} else {
-			// Update the global bot instance
-			bot = botInstance
-		}
-	}()
-
 	data := ConfigPageData{
 		CSRFToken:         csrf.Token(r),
 		CSRFTemplateField: csrf.TemplateField(r),
@@ -263,32 +873,1253 @@ func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
 		SuccessMessage:    "Configuration updated successfully",
 	}
 	if err := templates.ExecuteTemplate(w, "config.html", data); err != nil {
-		log.Printf("Error rendering config template: %v", err)
+		adminLog.Error("Error rendering config template", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
-// authenticateUser verifies the provided credentials.
-func authenticateUser(username, password string) bool {
-	// Securely compare usernames
-	usernameMatch := subtle.ConstantTimeCompare([]byte(username), []byte(adminUsername)) == 1
-	if !usernameMatch {
-		return false
+// adminAuthor describes who made an admin panel request, for the configuration history.
+func adminAuthor(r *http.Request) string {
+	session, _ := store.Get(r, sessionName)
+	username, _ := session.Values["username"].(string)
+	return fmt.Sprintf("%s from %s", username, requestHost(r))
+}
+
+// requestHost returns the address an admin panel request came from.
+func requestHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// adminAccountsHandler handles the page for additional Binance accounts and routing rules.
+func adminAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
 	}
 
-	// Retrieve the hashed password from environment variable
-	hashedPassword := os.Getenv("ADMIN_PASSWORD_HASH")
-	if hashedPassword == "" {
-		log.Println("ADMIN_PASSWORD_HASH environment variable is not set")
-		return false
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing accounts form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		if err := handleAccountsAction(r); err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = "Accounts updated successfully"
+		}
 	}
 
-	// Compare hashed passwords
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	accounts, err := ListBinanceAccounts()
+	if err != nil {
+		adminLog.Error("Error fetching accounts", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	rules, err := ListRoutingRules()
 	if err != nil {
-		// Password does not match
-		return false
+		adminLog.Error("Error fetching routing rules", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := AccountsPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Accounts:          accounts,
+		Rules:             rules,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "accounts.html", data); err != nil {
+		adminLog.Error("Error rendering accounts template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// parseSizeFactor parses the size factor field of the accounts page, which defaults to 1.
+func parseSizeFactor(value string) (float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return 1, nil
+	}
+	factor, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || factor <= 0 {
+		return 0, fmt.Errorf("Size factor must be a positive number")
+	}
+	return factor, nil
+}
+
+// handleAccountsAction applies a form submission from the accounts page.
+func handleAccountsAction(r *http.Request) error {
+	switch r.FormValue("action") {
+	case "add_account":
+		name := strings.TrimSpace(r.FormValue("name"))
+		apiKey := r.FormValue("api_key")
+		apiSecret := r.FormValue("api_secret")
+		if name == "" || apiKey == "" || apiSecret == "" {
+			return fmt.Errorf("All fields are required")
+		}
+		if strings.EqualFold(name, defaultAccountName) {
+			return fmt.Errorf("The name %q is reserved for the main configuration", defaultAccountName)
+		}
+		sizeFactor, err := parseSizeFactor(r.FormValue("size_factor"))
+		if err != nil {
+			return err
+		}
+		exchange := r.FormValue("exchange")
+		switch exchange {
+		case ExchangeBinance:
+			if err := validateBinanceAPIKeys(apiKey, apiSecret, GetGlobalConfig().BinanceAPIURL); err != nil {
+				return fmt.Errorf("Binance API Key validation failed: %v", err)
+			}
+		case ExchangeBybit:
+			if err := validateBybitAPIKeys(apiKey, apiSecret); err != nil {
+				return fmt.Errorf("Bybit API Key validation failed: %v", err)
+			}
+		default:
+			return fmt.Errorf("Invalid exchange")
+		}
+		return SaveBinanceAccount(&BinanceAccount{Name: name, Exchange: exchange, APIKey: apiKey, APISecret: apiSecret,
+			Mirror: r.FormValue("mirror") == "on", SizeFactor: sizeFactor})
+
+	case "update_mirror":
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid account ID")
+		}
+		sizeFactor, err := parseSizeFactor(r.FormValue("size_factor"))
+		if err != nil {
+			return err
+		}
+		return SetAccountMirror(uint(id), r.FormValue("mirror") == "on", sizeFactor)
+
+	case "delete_account":
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid account ID")
+		}
+		return DeleteBinanceAccount(uint(id))
+
+	case "add_rule":
+		matchType := r.FormValue("match_type")
+		pattern := strings.TrimSpace(r.FormValue("pattern"))
+		accountName := r.FormValue("account")
+		if matchType != RouteMatchSymbol && matchType != RouteMatchSource {
+			return fmt.Errorf("Invalid match type")
+		}
+		if pattern == "" || accountName == "" {
+			return fmt.Errorf("All fields are required")
+		}
+		if accountName != defaultAccountName {
+			if _, err := GetBinanceAccount(accountName); err != nil {
+				return fmt.Errorf("Unknown account %q", accountName)
+			}
+		}
+		if matchType == RouteMatchSymbol {
+			pattern = strings.ToUpper(pattern)
+		}
+		return SaveRoutingRule(&RoutingRule{MatchType: matchType, Pattern: pattern, AccountName: accountName})
+
+	case "delete_rule":
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid rule ID")
+		}
+		return DeleteRoutingRule(uint(id))
+	}
+	return fmt.Errorf("Unknown action")
+}
+
+// maskKey hides all but the last four characters of an API key.
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
 	}
+	return "****" + key[len(key)-4:]
+}
 
-	return true
+// adminAuditHandler shows the most recent audit log entries.
+func adminAuditHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	entries, err := ListAuditLogs(auditPageEntries)
+	if err != nil {
+		adminLog.Error("Error fetching audit log", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := AuditPageData{Entries: entries, Limit: auditPageEntries}
+	if err := templates.ExecuteTemplate(w, "audit.html", data); err != nil {
+		adminLog.Error("Error rendering audit template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// adminConfigHistoryHandler shows the saved configuration versions and rolls back to one.
+func adminConfigHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing config history form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		if id, err := rollbackConfig(r); err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = fmt.Sprintf("Rolled back to version %d", id)
+		}
+	}
+
+	versions, err := ListConfigVersions(configHistoryEntries)
+	if err != nil {
+		adminLog.Error("Error fetching config history", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	entries := make([]ConfigHistoryEntry, 0, len(versions))
+	for i, version := range versions {
+		config, err := version.Config()
+		if err != nil {
+			adminLog.Error("Error decoding config version", "error", err)
+		}
+		entries = append(entries, ConfigHistoryEntry{Version: version, Config: config, Current: i == 0})
+	}
+
+	data := ConfigHistoryPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Versions:          entries,
+		Limit:             configHistoryEntries,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "config_history.html", data); err != nil {
+		adminLog.Error("Error rendering config history template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// rollbackConfig saves the configuration version chosen on the history page as a new version,
+// after validating its keys again, and applies it. It returns the version rolled back to.
+func rollbackConfig(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid version ID")
+	}
+	version, err := GetConfigVersion(uint(id))
+	if err != nil {
+		return 0, fmt.Errorf("Unknown version %d", id)
+	}
+	config, err := version.Config()
+	if err != nil {
+		return 0, err
+	}
+
+	// Keys that were valid then may have been revoked since
+	if err := validateTelegramAPIKey(config.TelegramBotToken); err != nil {
+		return 0, fmt.Errorf("Telegram API Key validation failed: %v", err)
+	}
+	if err := validateBinanceAPIKeys(config.BinanceAPIKey, config.BinanceAPISecret, config.BinanceAPIURL); err != nil {
+		return 0, fmt.Errorf("Binance API Key validation failed: %v", err)
+	}
+	if err := saveConfig(&config, adminAuthor(r), fmt.Sprintf("Rollback to version %d", version.ID)); err != nil {
+		return 0, fmt.Errorf("Failed to save configuration: %v", err)
+	}
+	SetGlobalConfig(config)
+	return version.ID, nil
+}
+
+// adminDashboardHandler shows open positions, pending signals, the last 24 hours of trades,
+// webhook counts and connectivity, reloading itself every dashboardRefreshSeconds.
+func adminDashboardHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	data := DashboardPageData{
+		CSRFTemplateField: csrf.TemplateField(r),
+		Stats:             collectDashboardStats(),
+		RefreshSeconds:    dashboardRefreshSeconds,
+		UpdatedAt:         time.Now(),
+	}
+	if err := templates.ExecuteTemplate(w, "dashboard.html", data); err != nil {
+		adminLog.Error("Error rendering dashboard template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// adminSignalsHandler lists stored signals, filtered by status, symbol and date, and confirms,
+// dismisses or resends them.
+func adminSignalsHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		adminLog.Error("Error parsing signals form", "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if message, err := handleSignalAction(r, admin); err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = message
+		}
+	}
+
+	data := SignalsPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Statuses:          signalStatuses(),
+		Status:            r.FormValue("status"),
+		Symbol:            strings.ToUpper(strings.TrimSpace(r.FormValue("symbol"))),
+		From:              r.FormValue("from"),
+		To:                r.FormValue("to"),
+		Limit:             signalsPageEntries,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	filter := SignalFilter{Status: data.Status, Symbol: data.Symbol}
+	if data.From != "" {
+		from, err := time.Parse("2006-01-02", data.From)
+		if err != nil {
+			data.ErrorMessage = "Invalid From date"
+		}
+		filter.From = from
+	}
+	if data.To != "" {
+		to, err := time.Parse("2006-01-02", data.To)
+		if err != nil {
+			data.ErrorMessage = "Invalid To date"
+		} else {
+			// Include the whole end day
+			filter.To = to.Add(24 * time.Hour)
+		}
+	}
+
+	signals, err := ListSignals(filter, signalsPageEntries)
+	if err != nil {
+		adminLog.Error("Error fetching signals", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	for _, signal := range signals {
+		entry := SignalsPageEntry{Signal: signal}
+		if alert, exists := signalStore.Get(signal.SignalID); exists {
+			entry.Available = true
+			entry.Pending = !alert.Confirmed && !alert.Dismissed && !alert.Expired
+		}
+		data.Signals = append(data.Signals, entry)
+	}
+
+	if err := templates.ExecuteTemplate(w, "signals.html", data); err != nil {
+		adminLog.Error("Error rendering signals template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleSignalAction applies a confirm, dismiss or resend from the signals page and describes
+// what it did. Actions are taken as the configured admin user, like pressing the signal's
+// buttons in Telegram, where their results are posted, and are audited under the admin account.
+func handleSignalAction(r *http.Request, admin *AdminUser) (string, error) {
+	signalID := r.FormValue("signal_id")
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		return "", fmt.Errorf("Signal %s is no longer held by the bot; only signals from the last %d days can be acted on",
+			signalID, int(signalRetention.Hours()/24))
+	}
+	if bot == nil {
+		return "", fmt.Errorf("The Telegram bot is not running")
+	}
+	userID := GetGlobalConfig().AdminUserID
+	chatID := signal.ChatID
+	messageID, _ := messageStore.Get(signalID)
+
+	action := r.FormValue("action")
+	if (action == "confirm" || action == "dismiss") && (signal.Confirmed || signal.Dismissed) {
+		return "", fmt.Errorf("Signal %s has already been answered", signalID)
+	}
+	if action == "confirm" && signalExpired(signal) {
+		return "", fmt.Errorf("Signal %s expired at %s", signalID, signal.expiryTime().UTC().Format("2006-01-02 15:04 UTC"))
+	}
+	switch action {
+	case "confirm":
+		return confirmSignalAsAdmin(admin, signal, signalID), nil
+	case "dismiss":
+		recordAudit(AuditLog{UserID: userID, ChatID: chatID, Admin: admin.Username, Action: AuditDismiss, SignalID: signalID, Details: "admin panel"})
+		dismissSignal(chatID, messageID, signalID)
+		return fmt.Sprintf("Dismissed signal %s", signalID), nil
+	case "resend":
+		showStoredSignal(chatID, signalID)
+		return fmt.Sprintf("Sent signal %s to Telegram again", signalID), nil
+	}
+	return "", fmt.Errorf("Unknown action")
+}
+
+// confirmSignalAsAdmin confirms a signal from the admin panel as the configured admin user and
+// describes what happened. The orders are placed in the background and reported in Telegram.
+func confirmSignalAsAdmin(admin *AdminUser, signal *AlertMessage, signalID string) string {
+	userID := GetGlobalConfig().AdminUserID
+	chatID := signal.ChatID
+	messageID, _ := messageStore.Get(signalID)
+	if signal.InfoOnly || outsideTradingHours(chatID) {
+		return fmt.Sprintf("Signal %s can't be confirmed outside the chat's trading hours (%s)", signalID, userSettings.Get(chatID).TradingHours)
+	}
+	if event := activeNewsHalt(time.Now()); event != nil {
+		return fmt.Sprintf("Signal %s can't be confirmed while trading is paused around %s (%s)", signalID, event.Title, event.Country)
+	}
+	if !guardDuplicatePosition(chatID, userID, signal) {
+		return fmt.Sprintf("Signal %s adds to a %s position; press Add to Position in Telegram to confirm it", signalID, signal.Symbol)
+	}
+	recordAudit(AuditLog{UserID: userID, ChatID: chatID, Admin: admin.Username, Action: AuditConfirm, SignalID: signalID, Details: "admin panel"})
+	if !approveLargeTrade(chatID, userID, signalID) {
+		return fmt.Sprintf("Signal %s is above the two-trader limit; another trader must confirm it in Telegram", signalID)
+	}
+	// Placing the orders can take longer than the page should
+	go confirmSignal(chatID, userID, messageID, signalID)
+	return fmt.Sprintf("Confirmed signal %s; the result is posted in Telegram", signalID)
+}
+
+// adminTokensHandler handles the page for creating and revoking REST API tokens.
+func adminTokensHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var errorMessage, successMessage, newToken string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing tokens form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		token, err := handleTokensAction(r, admin)
+		switch {
+		case err != nil:
+			errorMessage = err.Error()
+		case token != "":
+			newToken = token
+			successMessage = "Token created. Copy it now, it is not shown again"
+		default:
+			successMessage = "Token revoked"
+		}
+	}
+
+	tokens, err := ListAPITokens()
+	if err != nil {
+		adminLog.Error("Error fetching API tokens", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := TokensPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Tokens:            tokens,
+		NewToken:          newToken,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "tokens.html", data); err != nil {
+		adminLog.Error("Error rendering tokens template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleTokensAction applies a form submission from the API tokens page, returning the token
+// if one was created.
+func handleTokensAction(r *http.Request, admin *AdminUser) (string, error) {
+	switch r.FormValue("action") {
+	case "create":
+		name := strings.TrimSpace(r.FormValue("name"))
+		if name == "" {
+			return "", fmt.Errorf("A name is required")
+		}
+		token, err := CreateAPIToken(name)
+		if err == nil {
+			auditAdmin(admin.Username, AuditAPIToken, fmt.Sprintf("created %q", name))
+		}
+		return token, err
+
+	case "delete":
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("Invalid token ID")
+		}
+		if err := DeleteAPIToken(uint(id)); err != nil {
+			return "", err
+		}
+		auditAdmin(admin.Username, AuditAPIToken, fmt.Sprintf("revoked token %d", id))
+		return "", nil
+	}
+	return "", fmt.Errorf("Unknown action")
+}
+
+// adminWebhooksHandler handles the page for adding, testing and removing outgoing webhooks.
+func adminWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var errorMessage, successMessage, newSecret string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing webhooks form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		secret, message, err := handleWebhooksAction(r, admin)
+		if err != nil {
+			errorMessage = err.Error()
+		} else {
+			newSecret = secret
+			successMessage = message
+		}
+	}
+
+	hooks, err := ListOutgoingWebhooks()
+	if err != nil {
+		adminLog.Error("Error fetching outgoing webhooks", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := WebhooksPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Webhooks:          hooks,
+		Events:            webhookEvents,
+		NewSecret:         newSecret,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "webhooks.html", data); err != nil {
+		adminLog.Error("Error rendering webhooks template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleWebhooksAction applies a form submission from the outgoing webhooks page, returning
+// the secret of a created webhook and the message to show.
+func handleWebhooksAction(r *http.Request, admin *AdminUser) (string, string, error) {
+	switch r.FormValue("action") {
+	case "create":
+		name := strings.TrimSpace(r.FormValue("name"))
+		webhookURL := strings.TrimSpace(r.FormValue("url"))
+		if name == "" || webhookURL == "" {
+			return "", "", fmt.Errorf("A name and URL are required")
+		}
+		secret, err := CreateOutgoingWebhook(name, webhookURL, r.FormValue("secret"), r.Form["events"])
+		if err != nil {
+			return "", "", err
+		}
+		auditAdmin(admin.Username, AuditWebhook, fmt.Sprintf("created %q for %s", name, webhookURL))
+		if r.FormValue("secret") != "" {
+			return "", "Webhook created", nil
+		}
+		return secret, "Webhook created. Copy its secret now, it is not shown again", nil
+
+	case "test":
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return "", "", fmt.Errorf("Invalid webhook ID")
+		}
+		hook, err := GetOutgoingWebhook(uint(id))
+		if err != nil {
+			return "", "", err
+		}
+		status, err := testWebhook(hook)
+		if err != nil {
+			return "", "", err
+		}
+		return "", fmt.Sprintf("Sent a ping event to %s: %s", hook.Name, status), nil
+
+	case "delete":
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return "", "", fmt.Errorf("Invalid webhook ID")
+		}
+		if err := DeleteOutgoingWebhook(uint(id)); err != nil {
+			return "", "", err
+		}
+		auditAdmin(admin.Username, AuditWebhook, fmt.Sprintf("deleted webhook %d", id))
+		return "", "Webhook deleted", nil
+	}
+	return "", "", fmt.Errorf("Unknown action")
+}
+
+// adminFiltersHandler handles the page for adding, switching and removing signal filters.
+func adminFiltersHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing filters form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		message, err := handleFiltersAction(r, admin)
+		if err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = message
+		}
+	}
+
+	filters, err := ListFilterRules()
+	if err != nil {
+		adminLog.Error("Error fetching signal filters", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := FiltersPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Filters:           filters,
+		Fields:            filterFieldNames(),
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "filters.html", data); err != nil {
+		adminLog.Error("Error rendering filters template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleFiltersAction applies a form submission from the signal filters page, returning the
+// message to show.
+func handleFiltersAction(r *http.Request, admin *AdminUser) (string, error) {
+	action := r.FormValue("action")
+	if action == "create" {
+		name := strings.TrimSpace(r.FormValue("name"))
+		rule := strings.TrimSpace(r.FormValue("rule"))
+		if name == "" || rule == "" {
+			return "", fmt.Errorf("A name and rule are required")
+		}
+		source := strings.TrimSpace(r.FormValue("source"))
+		if err := CreateFilterRule(name, source, rule, r.FormValue("filter_action"), strings.TrimSpace(r.FormValue("note"))); err != nil {
+			return "", err
+		}
+		auditAdmin(admin.Username, AuditSignalFilter, fmt.Sprintf("created %q: %s", name, rule))
+		return "Filter created", nil
+	}
+
+	id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("Invalid filter ID")
+	}
+	switch action {
+	case "enable", "disable":
+		if err := SetFilterRuleEnabled(uint(id), action == "enable"); err != nil {
+			return "", err
+		}
+		auditAdmin(admin.Username, AuditSignalFilter, fmt.Sprintf("%sd filter %d", action, id))
+		return fmt.Sprintf("Filter %sd", action), nil
+
+	case "delete":
+		if err := DeleteFilterRule(uint(id)); err != nil {
+			return "", err
+		}
+		auditAdmin(admin.Username, AuditSignalFilter, fmt.Sprintf("deleted filter %d", id))
+		return "Filter deleted", nil
+	}
+	return "", fmt.Errorf("Unknown action")
+}
+
+// adminBacktestHandler handles the page that replays stored signals with the main chat's
+// settings and shows the last report. Backtests run in the background; the page refreshes
+// while one is running.
+func adminBacktestHandler(w http.ResponseWriter, r *http.Request) {
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing backtest form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		days, err := parseBacktestDays(strings.TrimSpace(r.FormValue("days")))
+		if err == nil {
+			err = backtests.Start(GetGlobalConfig().TelegramChatID, days, nil)
+		}
+		if err != nil {
+			errorMessage = err.Error()
+		} else {
+			auditAdmin(admin.Username, AuditBacktest, fmt.Sprintf("started a backtest of the last %d days", days))
+			successMessage = fmt.Sprintf("Backtest of the last %d days started", days)
+		}
+	}
+
+	running, done, total, report, lastErr := backtests.Status()
+	data := BacktestPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Running:           running,
+		Done:              done,
+		Total:             total,
+		Report:            report,
+		DefaultDays:       defaultBacktestDays,
+		MaxDays:           maxBacktestDays,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if lastErr != nil && !running {
+		data.LastError = lastErr.Error()
+	}
+	if err := templates.ExecuteTemplate(w, "backtest.html", data); err != nil {
+		adminLog.Error("Error rendering backtest template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// adminPasswordHandler handles the page where the logged-in admin changes their password.
+func adminPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing password form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		if err := changeAdminPassword(w, r, admin); err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = "Password changed"
+			admin.MustChangePassword = false
+		}
+	}
+
+	data := PasswordPageData{
+		CSRFToken:          csrf.Token(r),
+		CSRFTemplateField:  csrf.TemplateField(r),
+		Username:           admin.Username,
+		MustChangePassword: admin.MustChangePassword,
+		MinLength:          minAdminPasswordLength,
+		ErrorMessage:       errorMessage,
+		SuccessMessage:     successMessage,
+	}
+	if err := templates.ExecuteTemplate(w, "password.html", data); err != nil {
+		adminLog.Error("Error rendering password template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// changeAdminPassword applies the password form after checking the current password. The
+// account's other sessions are logged out; this one continues.
+func changeAdminPassword(w http.ResponseWriter, r *http.Request, admin *AdminUser) error {
+	current := r.FormValue("current_password")
+	password := r.FormValue("new_password")
+	if bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(current)) != nil {
+		return fmt.Errorf("The current password is wrong")
+	}
+	if password != r.FormValue("confirm_password") {
+		return fmt.Errorf("The new passwords do not match")
+	}
+	if password == current {
+		return fmt.Errorf("The new password must be different from the current one")
+	}
+	if err := validateAdminPassword(password); err != nil {
+		return err
+	}
+	if err := SetAdminPassword(admin, password, false); err != nil {
+		return err
+	}
+	auditAdmin(admin.Username, AuditPasswordChange, "from "+requestHost(r))
+	updated, err := GetAdminUser(admin.Username)
+	if err != nil {
+		return fmt.Errorf("failed to reload admin account: %w", err)
+	}
+	startAdminSession(w, r, updated, sessionRemembered(r))
+	return nil
+}
+
+// adminAdminUsersHandler handles the page for adding, resetting, unlocking and deleting admin
+// panel accounts.
+func adminAdminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	data := AdminUsersPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Current:           admin.Username,
+		MinLength:         minAdminPasswordLength,
+	}
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing admin accounts form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		if err := handleAdminUsersAction(r, admin, &data); err != nil {
+			data.ErrorMessage = err.Error()
+		}
+	}
+
+	users, err := ListAdminUsers()
+	if err != nil {
+		adminLog.Error("Error fetching admin accounts", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	data.Users = users
+	if err := templates.ExecuteTemplate(w, "admins.html", data); err != nil {
+		adminLog.Error("Error rendering admin accounts template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleAdminUsersAction applies a form submission from the admin accounts page, setting the
+// success message and any temporary password on data.
+func handleAdminUsersAction(r *http.Request, admin *AdminUser, data *AdminUsersPageData) error {
+	username := strings.TrimSpace(r.FormValue("username"))
+	switch r.FormValue("action") {
+	case "create":
+		if err := CreateAdminUser(username, r.FormValue("password")); err != nil {
+			return err
+		}
+		auditAdmin(admin.Username, AuditAdminAccount, fmt.Sprintf("created %s", username))
+		data.SuccessMessage = fmt.Sprintf("Created %s; they must change the password at their first login", username)
+		return nil
+
+	case "reset":
+		password, err := ResetAdminPassword(username, false)
+		if err != nil {
+			return err
+		}
+		auditAdmin(admin.Username, AuditAdminAccount, fmt.Sprintf("reset password of %s", username))
+		data.NewPassword = password
+		data.NewPasswordFor = username
+		data.SuccessMessage = "Password reset. Copy the temporary password now, it is not shown again"
+		return nil
+
+	case "unlock":
+		if err := UnlockAdminUser(username); err != nil {
+			return err
+		}
+		auditAdmin(admin.Username, AuditAdminAccount, fmt.Sprintf("unlocked %s", username))
+		data.SuccessMessage = fmt.Sprintf("Unlocked %s", username)
+		return nil
+
+	case "delete":
+		if username == admin.Username {
+			return fmt.Errorf("You cannot delete your own account")
+		}
+		if err := DeleteAdminUser(username); err != nil {
+			return err
+		}
+		auditAdmin(admin.Username, AuditAdminAccount, fmt.Sprintf("deleted %s", username))
+		data.SuccessMessage = fmt.Sprintf("Deleted %s", username)
+		return nil
+	}
+	return fmt.Errorf("Unknown action")
+}
+
+// adminUsersHandler lists the Telegram users and chats with their settings, and edits a user's
+// settings or turns their trading on or off.
+func adminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		adminLog.Error("Error parsing users form", "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost {
+		if message, err := handleUsersAction(r, admin); err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = message
+		}
+	}
+
+	users, err := listTelegramUsers()
+	if err != nil {
+		adminLog.Error("Error fetching Telegram users", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	data := UsersPageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Users:             users,
+		RolesEnforced:     GetGlobalConfig().AdminUserID != 0,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	if id, err := strconv.ParseInt(r.FormValue("edit"), 10, 64); err == nil {
+		for i := range users {
+			if users[i].ID == id {
+				data.Editing = &users[i]
+				data.TPLevels = formatTPLevels(users[i].Settings.TPLevels)
+			}
+		}
+	}
+
+	if err := templates.ExecuteTemplate(w, "users.html", data); err != nil {
+		adminLog.Error("Error rendering users template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleUsersAction applies a form submission from the Telegram users page and describes what
+// it did. Settings changes are applied like changes made in Telegram, including to the user's
+// pending signals, and the user is told about them.
+func handleUsersAction(r *http.Request, admin *AdminUser) (string, error) {
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		return "", fmt.Errorf("Invalid user ID")
+	}
+
+	switch r.FormValue("action") {
+	case "save":
+		old := *userSettings.Get(id)
+		updated, err := parseUserSettingsForm(r.FormValue, old)
+		if err != nil {
+			return "", err
+		}
+		userSettings.Set(id, &updated)
+		changes := fieldChanges(old, updated)
+		if changes == "" {
+			return fmt.Sprintf("No settings of %d changed", id), nil
+		}
+		auditAdmin(admin.Username, AuditUserSettings, fmt.Sprintf("settings of %d: %s", id, changes))
+		if bot != nil {
+			updatePendingSignals(id, &updated)
+			bot.Send(tgbotapi.NewMessage(id, tr(id, "An admin changed your settings: %s", changes)))
+		}
+		return fmt.Sprintf("Saved settings of %d: %s", id, changes), nil
+
+	case "enable_trading", "disable_trading":
+		if id < 0 {
+			return "", fmt.Errorf("Roles apply to users, not groups or channels")
+		}
+		enable := r.FormValue("action") == "enable_trading"
+		switch {
+		case enable && hasRole(id, RoleTrader):
+			return fmt.Sprintf("User %d can already trade", id), nil
+		case !enable && id == GetGlobalConfig().AdminUserID:
+			return "", fmt.Errorf("User %d is the configured Admin User ID and is always an admin", id)
+		}
+		role := RoleViewer
+		if enable {
+			role = RoleTrader
+		}
+		if err := SetUserRole(id, role); err != nil {
+			return "", err
+		}
+		auditAdmin(admin.Username, AuditUserSettings, fmt.Sprintf("role of %d: %s", id, role))
+		if enable {
+			return fmt.Sprintf("User %d is now a trader", id), nil
+		}
+		return fmt.Sprintf("User %d is now a viewer and can no longer trade", id), nil
+	}
+	return "", fmt.Errorf("Unknown action")
+}
+
+// adminTradeHandler handles the trade console, which sends a signal entered by hand through the
+// same pipeline as a webhook alert, optionally confirming it straight away.
+func adminTradeHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	data := TradePageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		MaxTPs:            maxTPLevels,
+		CanConfirm:        GetGlobalConfig().AdminUserID != 0,
+	}
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			adminLog.Error("Error parsing trade form", "error", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		if message, err := sendManualSignal(r, admin); err != nil {
+			data.ErrorMessage = err.Error()
+			data.Form = r.PostForm
+		} else {
+			data.SuccessMessage = message
+		}
+	}
+
+	if err := templates.ExecuteTemplate(w, "trade.html", data); err != nil {
+		adminLog.Error("Error rendering trade template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// sendManualSignal sends the signal from the trade console to Telegram and describes what it
+// did. With "confirm" set it is confirmed as the configured admin user, as on the signals page.
+func sendManualSignal(r *http.Request, admin *AdminUser) (string, error) {
+	if bot == nil {
+		return "", fmt.Errorf("The Telegram bot is not running")
+	}
+	confirm := r.FormValue("confirm") == "on"
+	if confirm && GetGlobalConfig().AdminUserID == 0 {
+		return "", fmt.Errorf("Set the Admin User ID to confirm signals from the admin panel")
+	}
+	alert, err := parseTradeForm(r.FormValue)
+	if err != nil {
+		return "", err
+	}
+
+	adminLog.Info("Admin created signal", "signal_id", alert.SignalID, "symbol", alert.Symbol, "username", admin.Username, "signal", fmt.Sprintf("%+v", *alert))
+	if _, err := sendSignalMessage(r.Context(), alert); err != nil {
+		adminLog.Error("Failed to send manual signal", "error", err)
+		return "", fmt.Errorf("Failed to send the signal: %v", err)
+	}
+	recordAudit(AuditLog{ChatID: alert.ChatID, Admin: admin.Username, Action: AuditManualSignal, SignalID: alert.SignalID, Details: describeManualSignal(alert)})
+
+	message := fmt.Sprintf("Sent signal %s for %s to Telegram", alert.SignalID, alert.Symbol)
+	if confirm {
+		message += ". " + confirmSignalAsAdmin(admin, alert, alert.SignalID)
+	}
+	return message, nil
+}
+
+// adminLogsHandler shows the recent application log, filtered by level and text, and follows
+// new entries as they are logged.
+func adminLogsHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	level, search := logFilter(r)
+	entries := filterLogEntries(logBuffer.Entries(), level, search)
+	data := LogsPageData{
+		Levels: []string{LogDebug, LogInfo, LogWarn, LogError},
+		Level:  level,
+		Search: search,
+		Query:  url.Values{"level": {level}, "q": {search}}.Encode(),
+		Limit:  logsPageEntries,
+	}
+	if all := logBuffer.Entries(); len(all) > 0 {
+		data.LastID = all[len(all)-1].ID
+	}
+	if len(entries) > logsPageEntries {
+		entries = entries[len(entries)-logsPageEntries:]
+	}
+	data.Entries = entries
+
+	if err := templates.ExecuteTemplate(w, "logs.html", data); err != nil {
+		adminLog.Error("Error rendering logs template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// logFilter reads the level and search text of the logs page.
+func logFilter(r *http.Request) (level, search string) {
+	level = r.FormValue("level")
+	if _, ok := logLevels[level]; !ok {
+		level = LogInfo
+	}
+	return level, strings.TrimSpace(r.FormValue("q"))
+}
+
+// adminLogsStreamHandler streams log entries matching the logs page's filter as server-sent
+// events, starting after the entry in "after" or the Last-Event-ID of a reconnect.
+func adminLogsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	level, search := logFilter(r)
+	after, _ := strconv.ParseUint(r.FormValue("after"), 10, 64)
+	if id, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+		after = id
+	}
+
+	// The stream outlives the server's write timeout
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		adminLog.Error("Failed to lift write deadline for log stream", "error", err)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	updates, done, unfollow := logBuffer.Follow()
+	defer unfollow()
+	send := func(entry LogEntry) bool {
+		if entry.ID <= after {
+			return true
+		}
+		after = entry.ID
+		if !logEntryMatches(entry, level, search) {
+			return true
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", entry.ID, data); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+
+	// Entries logged since the page was rendered, then new ones
+	for _, entry := range logBuffer.Entries() {
+		if !send(entry) {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case entry := <-updates:
+			if !send(entry) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || controller.Flush() != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// adminLogsDownloadHandler sends the kept log entries matching the logs page's filter as a
+// text file.
+func adminLogsDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	level, search := logFilter(r)
+	filename := fmt.Sprintf("bot-log-%s.log", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	for _, entry := range filterLogEntries(logBuffer.Entries(), level, search) {
+		if _, err := fmt.Fprintln(w, entry.String()); err != nil {
+			return
+		}
+	}
+}
+
+// adminWebhookArchiveHandler shows the webhook payloads received recently, filtered by outcome
+// and text, and runs one through the webhook pipeline again on request.
+func adminWebhookArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if user is authenticated
+	admin, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		adminLog.Error("Error parsing webhook archive form", "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	var errorMessage, successMessage string
+	if r.Method == http.MethodPost && r.FormValue("action") == "reprocess" {
+		if message, err := reprocessWebhookAsAdmin(r, admin); err != nil {
+			errorMessage = err.Error()
+		} else {
+			successMessage = message
+		}
+	}
+
+	data := WebhookArchivePageData{
+		CSRFToken:         csrf.Token(r),
+		CSRFTemplateField: csrf.TemplateField(r),
+		Outcomes:          []string{WebhookAccepted, WebhookRejected, WebhookFailed},
+		Outcome:           r.FormValue("outcome"),
+		Search:            strings.TrimSpace(r.FormValue("q")),
+		Limit:             webhookArchivePageEntries,
+		ErrorMessage:      errorMessage,
+		SuccessMessage:    successMessage,
+	}
+	payloads, err := ListWebhookPayloads(WebhookPayloadFilter{Outcome: data.Outcome, Search: data.Search}, webhookArchivePageEntries)
+	if err != nil {
+		adminLog.Error("Error fetching webhook archive", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	data.Payloads = payloads
+
+	if err := templates.ExecuteTemplate(w, "webhook_archive.html", data); err != nil {
+		adminLog.Error("Error rendering webhook archive template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// reprocessWebhookAsAdmin replays the archived payload in the form and describes its outcome.
+func reprocessWebhookAsAdmin(r *http.Request, admin *AdminUser) (string, error) {
+	id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("Invalid payload ID")
+	}
+	result, err := reprocessWebhookPayload(r.Context(), uint(id), admin.Username)
+	if err != nil {
+		return "", err
+	}
+	auditAdmin(admin.Username, AuditReprocessWebhook, fmt.Sprintf("payload %d: %s", id, result.Outcome))
+	if result.Outcome != WebhookAccepted {
+		return "", fmt.Errorf("Payload %d was %s again: %s", id, result.Outcome, result.Detail)
+	}
+	return fmt.Sprintf("Payload %d reprocessed: %s", id, result.Detail), nil
 }
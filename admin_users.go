@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// bootstrapAdminUsername is the account created from ADMIN_PASSWORD_HASH when there are none.
+const bootstrapAdminUsername = "admin"
+
+// maxFailedLogins wrong passwords in a row lock an admin account for adminLockout.
+const (
+	maxFailedLogins = 5
+	adminLockout    = 15 * time.Minute
+)
+
+// minAdminPasswordLength is the shortest password an admin account accepts.
+const minAdminPasswordLength = 10
+
+// Login failures returned by authenticateAdmin.
+var (
+	errInvalidCredentials = errors.New("invalid credentials")
+	errAccountLocked      = errors.New("account locked")
+)
+
+// dummyPasswordHash is compared against for unknown usernames, so they take as long to reject
+// as wrong passwords.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+// AdminUser is an account that can log in to the admin panel.
+type AdminUser struct {
+	ID                 uint   `gorm:"primaryKey"`
+	Username           string `gorm:"uniqueIndex;size:64"`
+	PasswordHash       string // bcrypt
+	MustChangePassword bool   // Set for new accounts and resets, until the user picks a password
+	FailedLogins       int    // Wrong passwords since the last login or lockout
+	LockedUntil        time.Time
+	LastLoginAt        time.Time // Zero if the account never logged in
+	PasswordChangedAt  time.Time
+	SessionVersion     int // Sessions started with another version are logged out
+	CreatedAt          time.Time
+}
+
+// Locked reports whether the account is locked after too many failed logins.
+func (u AdminUser) Locked() bool {
+	return time.Now().Before(u.LockedUntil)
+}
+
+// ensureAdminUser creates the first admin account from ADMIN_PASSWORD_HASH if there are none.
+// Once accounts exist the variable is not used.
+func ensureAdminUser() error {
+	var count int64
+	if err := db.Model(&AdminUser{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count admin accounts: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	hash := os.Getenv("ADMIN_PASSWORD_HASH")
+	if hash == "" {
+		adminLog.Warn("No admin accounts exist; set ADMIN_PASSWORD_HASH or run the bot with -reset-admin-password to create one")
+		return nil
+	}
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return fmt.Errorf("ADMIN_PASSWORD_HASH is not a bcrypt hash: %w", err)
+	}
+	user := AdminUser{Username: bootstrapAdminUsername, PasswordHash: hash, PasswordChangedAt: time.Now()}
+	if err := db.Create(&user).Error; err != nil {
+		return fmt.Errorf("failed to create admin account: %w", err)
+	}
+	adminLog.Info("Created admin account from ADMIN_PASSWORD_HASH", "username", bootstrapAdminUsername)
+	return nil
+}
+
+// authenticateAdmin checks a login and returns the account. Wrong passwords count towards
+// locking the account; logins to a locked account are refused without checking the password.
+func authenticateAdmin(username, password string) (*AdminUser, error) {
+	user, err := GetAdminUser(username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, errInvalidCredentials
+	}
+	if user.Locked() {
+		return user, errAccountLocked
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		updates := map[string]interface{}{"failed_logins": user.FailedLogins + 1}
+		if user.FailedLogins+1 >= maxFailedLogins {
+			updates["failed_logins"] = 0
+			updates["locked_until"] = time.Now().Add(adminLockout)
+		}
+		if err := db.Model(user).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to record failed login: %w", err)
+		}
+		return user, errInvalidCredentials
+	}
+
+	updates := map[string]interface{}{"failed_logins": 0, "last_login_at": time.Now()}
+	if err := db.Model(user).Updates(updates).Error; err != nil {
+		adminLog.Error("Failed to record login", "username", user.Username, "error", err)
+	}
+	return user, nil
+}
+
+// GetAdminUser returns the admin account with the username.
+func GetAdminUser(username string) (*AdminUser, error) {
+	var user AdminUser
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListAdminUsers returns the admin accounts by username.
+func ListAdminUsers() ([]AdminUser, error) {
+	var users []AdminUser
+	if err := db.Order("username").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve admin accounts: %w", err)
+	}
+	return users, nil
+}
+
+// validateAdminPassword checks a new password against the password rules.
+func validateAdminPassword(password string) error {
+	if len(password) < minAdminPasswordLength {
+		return fmt.Errorf("Passwords must be at least %d characters", minAdminPasswordLength)
+	}
+	return nil
+}
+
+// CreateAdminUser adds an admin account. Its password must be changed at the first login.
+func CreateAdminUser(username, password string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return fmt.Errorf("A username is required")
+	}
+	if err := validateAdminPassword(password); err != nil {
+		return err
+	}
+	if _, err := GetAdminUser(username); err == nil {
+		return fmt.Errorf("The username %q is taken", username)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := AdminUser{Username: username, PasswordHash: string(hash), MustChangePassword: true, PasswordChangedAt: time.Now()}
+	if err := db.Create(&user).Error; err != nil {
+		return fmt.Errorf("failed to create admin account: %w", err)
+	}
+	return nil
+}
+
+// SetAdminPassword replaces an account's password, unlocks it and logs out its sessions.
+// mustChange makes the user pick a new password at their next login, for passwords set by
+// someone else.
+func SetAdminPassword(user *AdminUser, password string, mustChange bool) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	updates := map[string]interface{}{
+		"password_hash":        string(hash),
+		"must_change_password": mustChange,
+		"password_changed_at":  time.Now(),
+		"failed_logins":        0,
+		"locked_until":         time.Time{},
+		"session_version":      gorm.Expr("session_version + 1"),
+	}
+	if err := db.Model(user).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to save password: %w", err)
+	}
+	return nil
+}
+
+// ResetAdminPassword gives an account a random temporary password, creating the account if
+// create is set and it does not exist. It returns the temporary password.
+func ResetAdminPassword(username string, create bool) (string, error) {
+	secret := make([]byte, 8)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	password := hex.EncodeToString(secret)
+
+	user, err := GetAdminUser(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) && create {
+		return password, CreateAdminUser(username, password)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unknown admin account %q", username)
+	}
+	return password, SetAdminPassword(user, password, true)
+}
+
+// UnlockAdminUser lifts a lockout after failed logins.
+func UnlockAdminUser(username string) error {
+	err := db.Model(&AdminUser{}).Where("username = ?", username).
+		Updates(map[string]interface{}{"failed_logins": 0, "locked_until": time.Time{}}).Error
+	if err != nil {
+		return fmt.Errorf("failed to unlock admin account: %w", err)
+	}
+	return nil
+}
+
+// DeleteAdminUser removes an admin account, refusing to remove the last one.
+func DeleteAdminUser(username string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&AdminUser{}).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count admin accounts: %w", err)
+		}
+		if count <= 1 {
+			return fmt.Errorf("The last admin account cannot be deleted")
+		}
+		if err := tx.Where("username = ?", username).Delete(&AdminUser{}).Error; err != nil {
+			return fmt.Errorf("failed to delete admin account: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// apiTokenPrefix starts every API token, so leaked tokens are easy to recognize.
+const apiTokenPrefix = "tgb_"
+
+// defaultAPILimit and maxAPILimit bound how many rows list endpoints return.
+const (
+	defaultAPILimit = 100
+	maxAPILimit     = 1000
+)
+
+// APIToken grants access to the REST API. Only a hash of the token is stored; the token
+// itself is shown once when it is created.
+type APIToken struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"size:64"`
+	TokenHash  string `gorm:"uniqueIndex;size:64"`
+	Hint       string // First characters of the token, to tell tokens apart
+	CreatedAt  time.Time
+	LastUsedAt time.Time // Zero if the token was never used
+}
+
+// APIConfig is the configuration returned by the API, without the bot token and API keys.
+type APIConfig struct {
+	TelegramChatID        int64
+	BinanceAPIURL         string
+	BinanceRecvWindow     int
+	AdminUserID           int64
+	AlertChatID           int64
+	OrderIDPrefix         string
+	BroadcastToTraders    bool
+	DailySummary          bool
+	WeeklySummary         bool
+	MonthlyReport         bool
+	SummaryHour           int
+	MessageRetentionHours int
+	DualConfirmNotional   float64
+	ExposureLimitPercent  float64
+	NewsHaltMinutes       int
+	NewsCurrencies        string
+	SignalExpiryBySource  string
+	EntryTypeBySource     string
+	DuplicateGuard        bool
+	DuplicateGuardMinutes int
+	TradingHalted         bool
+}
+
+// hashAPIToken returns the stored hash of a token.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken adds a token with the name and returns the token.
+func CreateAPIToken(name string) (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+	record := APIToken{Name: name, TokenHash: hashAPIToken(token), Hint: token[:len(apiTokenPrefix)+6]}
+	if err := db.Create(&record).Error; err != nil {
+		return "", fmt.Errorf("failed to save API token: %w", err)
+	}
+	return token, nil
+}
+
+// ListAPITokens returns the API tokens, newest first.
+func ListAPITokens() ([]APIToken, error) {
+	var tokens []APIToken
+	if err := db.Order("id desc").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteAPIToken revokes an API token by ID.
+func DeleteAPIToken(id uint) error {
+	if err := db.Delete(&APIToken{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete API token: %w", err)
+	}
+	return nil
+}
+
+// authenticateAPIToken returns the token record for a bearer token and records its use.
+func authenticateAPIToken(token string) (*APIToken, error) {
+	var record APIToken
+	if err := db.Where("token_hash = ?", hashAPIToken(token)).First(&record).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&record).Update("last_used_at", time.Now()).Error; err != nil {
+		adminLog.Error("Failed to record API token use", "error", err)
+	}
+	return &record, nil
+}
+
+// apiAuth only lets requests with a valid "Authorization: Bearer <token>" header through.
+func apiAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeAPIError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		if _, err := authenticateAPIToken(token); err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				adminLog.Error("Failed to check API token", "error", err)
+				writeAPIError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			writeAPIError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeAPIJSON writes a JSON response.
+func writeAPIJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		adminLog.Error("Failed to write API response", "error", err)
+	}
+}
+
+// writeAPIError writes a JSON error response.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": message})
+}
+
+// apiLimit parses the limit and offset query parameters.
+func apiLimit(r *http.Request) (limit, offset int, err error) {
+	limit = defaultAPILimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAPILimit {
+			return 0, 0, fmt.Errorf("limit must be from 1 to %d", maxAPILimit)
+		}
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a non-negative number")
+		}
+	}
+	return limit, offset, nil
+}
+
+// apiTimeRange parses the from and to query parameters, as RFC 3339 times or YYYY-MM-DD dates.
+// A date as to includes the whole day.
+func apiTimeRange(r *http.Request) (from, to time.Time, err error) {
+	parse := func(name string, endOfDay bool) (time.Time, error) {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			return time.Time{}, nil
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time or a YYYY-MM-DD date", name)
+		}
+		if endOfDay {
+			t = t.Add(24 * time.Hour)
+		}
+		return t, nil
+	}
+	if from, err = parse("from", false); err != nil {
+		return
+	}
+	to, err = parse("to", true)
+	return
+}
+
+// apiSignalsHandler serves GET /api/v1/signals, filtered by status, symbol, from and to.
+func apiSignalsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := apiLimit(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := apiTimeRange(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := SignalFilter{
+		Status: r.URL.Query().Get("status"),
+		Symbol: strings.ToUpper(r.URL.Query().Get("symbol")),
+		From:   from,
+		To:     to,
+	}
+	signals, err := ListSignals(filter, limit)
+	if err != nil {
+		adminLog.Error("API failed to list signals", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to retrieve signals")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{"signals": signals})
+}
+
+// apiTradesHandler serves GET /api/v1/trades: the trades closed between from and to, or a page
+// of the most recent trades with limit and offset.
+func apiTradesHandler(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := apiLimit(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := apiTimeRange(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if from.IsZero() && to.IsZero() {
+		trades, total, err := GetRecentTrades(offset, limit)
+		if err != nil {
+			adminLog.Error("API failed to list trades", "error", err)
+			writeAPIError(w, http.StatusInternalServerError, "failed to retrieve trades")
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, map[string]interface{}{"trades": trades, "total": total})
+		return
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	trades, err := GetTradesBetween(from, to)
+	if err != nil {
+		adminLog.Error("API failed to list trades", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to retrieve trades")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{"trades": trades, "total": len(trades)})
+}
+
+// apiSettingsHandler serves GET /api/v1/settings: the configuration without secrets and the
+// main chat's trading settings.
+func apiSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	config := GetGlobalConfig()
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{
+		"config": APIConfig{
+			TelegramChatID:        config.TelegramChatID,
+			BinanceAPIURL:         config.BinanceAPIURL,
+			BinanceRecvWindow:     config.BinanceRecvWindow,
+			AdminUserID:           config.AdminUserID,
+			AlertChatID:           config.AlertChatID,
+			OrderIDPrefix:         config.OrderIDPrefix,
+			BroadcastToTraders:    config.BroadcastToTraders,
+			DailySummary:          config.DailySummary,
+			WeeklySummary:         config.WeeklySummary,
+			MonthlyReport:         config.MonthlyReport,
+			SummaryHour:           config.SummaryHour,
+			MessageRetentionHours: config.MessageRetentionHours,
+			DualConfirmNotional:   config.DualConfirmNotional,
+			ExposureLimitPercent:  config.ExposureLimitPercent,
+			NewsHaltMinutes:       config.NewsHaltMinutes,
+			NewsCurrencies:        config.NewsCurrencies,
+			SignalExpiryBySource:  config.SignalExpiryBySource,
+			EntryTypeBySource:     config.EntryTypeBySource,
+			DuplicateGuard:        config.DuplicateGuard,
+			DuplicateGuardMinutes: config.DuplicateGuardMinutes,
+			TradingHalted:         config.TradingHalted,
+		},
+		"settings": userSettings.Get(config.TelegramChatID),
+	})
+}
+
+// apiPositionsHandler serves GET /api/v1/positions: the main account's open USDT-M positions.
+func apiPositionsHandler(w http.ResponseWriter, r *http.Request) {
+	positions, status := openPositions()
+	if !status.OK {
+		writeAPIError(w, http.StatusBadGateway, "Binance is unavailable: "+status.Detail)
+		return
+	}
+	if positions == nil {
+		positions = []DashboardPosition{}
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{"positions": positions})
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/delivery"
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Audit log actions.
+const (
+	AuditConfirm     = "confirm"
+	AuditDismiss     = "dismiss"
+	AuditEdit        = "edit"
+	AuditFieldChange = "field_change"
+	AuditOrder       = "order"
+	AuditCancelOrder = "cancel_order"
+	AuditPanic       = "panic"
+
+	// Admin panel actions
+	AuditLogin            = "login"
+	AuditLoginFailed      = "login_failed"
+	AuditLogout           = "logout"
+	AuditPasswordChange   = "password_change"
+	AuditAdminAccount     = "admin_account"
+	AuditAPIToken         = "api_token"
+	AuditWebhook          = "webhook"
+	AuditSignalFilter     = "signal_filter"
+	AuditBacktest         = "backtest"
+	AuditUserSettings     = "user_settings"
+	AuditManualSignal     = "manual_signal"
+	AuditReprocessWebhook = "reprocess_webhook"
+)
+
+// defaultAuditEntries and maxAuditEntries bound how many entries /audit shows.
+const (
+	defaultAuditEntries = 20
+	maxAuditEntries     = 100
+)
+
+// maxAuditMessageLength keeps /audit within Telegram's 4096 character message limit.
+const maxAuditMessageLength = 4000
+
+// auditPageEntries is how many entries the admin audit page shows.
+const auditPageEntries = 200
+
+// AuditLog records a user action on a signal, an admin panel action or an order the bot sent
+// to Binance.
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	UserID    int64     `gorm:"index"` // 0 for orders, which are placed on behalf of the confirming user
+	ChatID    int64
+	Admin     string `gorm:"index;size:64"` // Admin panel account that took the action
+	Action    string `gorm:"index;size:32"`
+	SignalID  string `gorm:"index;size:128"`
+	Details   string
+	Response  string // Binance API response or error for orders
+}
+
+// recordAudit adds an entry to the audit log, logging failures.
+func recordAudit(entry AuditLog) {
+	if err := db.Create(&entry).Error; err != nil {
+		dbLog.Error("Failed to record audit entry", "action", entry.Action, "signal_id", entry.SignalID, "error", err)
+	}
+}
+
+// auditAction records a button press or edit by a user.
+func auditAction(userID, chatID int64, action, signalID, details string) {
+	recordAudit(AuditLog{UserID: userID, ChatID: chatID, Action: action, SignalID: signalID, Details: details})
+}
+
+// auditAdmin records an action taken by an admin panel account.
+func auditAdmin(admin, action, details string) {
+	recordAudit(AuditLog{Admin: admin, Action: action, Details: details})
+}
+
+// auditFieldChange records an edit of a signal's field from oldValue to its current value.
+func auditFieldChange(userID, chatID int64, signal *AlertMessage, fieldName string, oldValue float64) {
+	var newValue float64
+	if price := signalPrice(signal, fieldName); price != nil {
+		newValue = *price
+	}
+	auditAction(userID, chatID, AuditFieldChange, signal.SignalID,
+		fmt.Sprintf("%s: %s -> %s", fieldName, formatFloat(oldValue), formatFloat(newValue)))
+}
+
+// auditOrder records an order request sent to Binance with its parameters and the API
+// response, or the error if the request failed.
+func auditOrder(action, clientID, params string, response interface{}, err error) {
+	signalID, _, _ := orderSignalID(clientID)
+	entry := AuditLog{Action: action, SignalID: signalID, Details: fmt.Sprintf("%s client_id=%s", params, clientID)}
+	if err != nil {
+		entry.Response = "error: " + err.Error()
+	} else if data, err := json.Marshal(response); err == nil {
+		entry.Response = string(data)
+	}
+	recordAudit(entry)
+
+	logger := binanceLog.With("signal_id", signalID, "client_order_id", clientID, "params", params)
+	switch {
+	case err != nil && action == AuditCancelOrder:
+		// Orders that already filled or were cancelled can't be cancelled, which is often expected
+		logger.Warn("Failed to cancel order", "error", err)
+	case err != nil:
+		reportError(binanceLog, ErrorSourceOrder, "Failed to place order", err,
+			"signal_id", signalID, "client_order_id", clientID, "params", params)
+	case action == AuditCancelOrder:
+		logger.Info("Cancelled order")
+	default:
+		logger.Info("Placed order", "order_id", orderID(response))
+	}
+}
+
+// orderID returns the Binance order ID from an order placement response, or 0.
+func orderID(response interface{}) int64 {
+	switch res := response.(type) {
+	case *futures.CreateOrderResponse:
+		return res.OrderID
+	case *delivery.CreateOrderResponse:
+		return res.OrderID
+	}
+	return 0
+}
+
+// ListAuditLogs returns the most recent audit entries, newest first.
+func ListAuditLogs(limit int) ([]AuditLog, error) {
+	var entries []AuditLog
+	if err := db.Order("id desc").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// handleAuditCommand sends the most recent audit entries. "/audit 50" shows 50.
+func handleAuditCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	limit := defaultAuditEntries
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > maxAuditEntries {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /audit [1-%d]", maxAuditEntries)))
+			return
+		}
+		limit = n
+	}
+
+	entries, err := ListAuditLogs(limit)
+	if err != nil {
+		dbLog.Error("Failed to list audit log", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load the audit log.")))
+		return
+	}
+	if len(entries) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "The audit log is empty.")))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(tr(chatID, "Audit Log:\n"))
+	for _, entry := range entries {
+		line := fmt.Sprintf("\n%s %s", formatUserTime(chatID, entry.CreatedAt), entry.Action)
+		if entry.Admin != "" {
+			line += " by " + entry.Admin
+		} else if entry.UserID != 0 {
+			line += fmt.Sprintf(" by %d", entry.UserID)
+		}
+		if entry.SignalID != "" {
+			line += " " + entry.SignalID
+		}
+		if entry.Details != "" {
+			line += ": " + entry.Details
+		}
+		if entry.Response != "" {
+			line += " -> " + truncateAuditText(entry.Response, 200)
+		}
+		if sb.Len()+len(line) > maxAuditMessageLength {
+			break
+		}
+		sb.WriteString(line)
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, sb.String()))
+}
+
+// truncateAuditText shortens long API responses for Telegram.
+func truncateAuditText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return text[:max] + "…"
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// marginTopUpCooldown is the minimum time between top-ups for the same symbol, so a burst of
+// account updates doesn't drain the wallet before the first transfer is reflected.
+const marginTopUpCooldown = time.Minute
+
+// MarginTopUpStore remembers the last top-up per symbol with concurrency safety.
+type MarginTopUpStore struct {
+	sync.Mutex
+	last map[string]time.Time
+}
+
+// NewMarginTopUpStore creates a new instance of MarginTopUpStore.
+func NewMarginTopUpStore() *MarginTopUpStore {
+	return &MarginTopUpStore{
+		last: make(map[string]time.Time),
+	}
+}
+
+// Claim reports whether a top-up for the symbol may go ahead now and records it if so.
+func (s *MarginTopUpStore) Claim(symbol string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if time.Since(s.last[symbol]) < marginTopUpCooldown {
+		return false
+	}
+	s.last[symbol] = time.Now()
+	return true
+}
+
+var marginTopUps = NewMarginTopUpStore()
+
+// checkIsolatedMargin adds margin to an isolated position whose margin ratio has crossed the
+// user's threshold. maintMargin comes from MARGIN_CALL events; for ACCOUNT_UPDATE events it is
+// zero and estimated from the symbol's leverage bracket instead.
+func (b *BinanceClient) checkIsolatedMargin(position futures.WsPosition, maintMargin float64, userID int64) {
+	settings := userSettings.Get(userID)
+	if !settings.AutoMarginEnabled || !strings.EqualFold(string(position.MarginType), string(futures.MarginTypeIsolated)) {
+		return
+	}
+
+	amount, err := strconv.ParseFloat(position.Amount, 64)
+	if err != nil || amount == 0 {
+		return
+	}
+	wallet, _ := strconv.ParseFloat(position.IsolatedWallet, 64)
+	unrealized, _ := strconv.ParseFloat(position.UnrealizedPnL, 64)
+
+	if maintMargin <= 0 {
+		markPrice, _ := strconv.ParseFloat(position.MarkPrice, 64)
+		if markPrice <= 0 {
+			// ACCOUNT_UPDATE carries no mark price, so derive it from the unrealized PnL
+			entry, _ := strconv.ParseFloat(position.EntryPrice, 64)
+			markPrice = entry + unrealized/amount
+		}
+		notional := math.Abs(amount) * markPrice
+		bracket, err := b.leverageBracketForNotional(context.Background(), position.Symbol, notional)
+		if err != nil {
+			binanceLog.Error("Failed to get leverage brackets", "symbol", position.Symbol, "error", err)
+			return
+		}
+		maintMargin = notional*bracket.MaintMarginRatio - bracket.Cum
+	}
+
+	// Margin ratio as Binance shows it: maintenance margin over margin balance
+	ratio := 100.0
+	if equity := wallet + unrealized; equity > 0 {
+		ratio = maintMargin / equity * 100
+	}
+	if ratio < settings.AutoMarginThreshold || !marginTopUps.Claim(position.Symbol) {
+		return
+	}
+
+	service := b.Client.NewUpdatePositionMarginService().
+		Symbol(position.Symbol).
+		Amount(strconv.FormatFloat(settings.AutoMarginAmount, 'f', 2, 64)).
+		Type(1) // 1 adds margin, 2 reduces it
+	if position.Side != "" {
+		service = service.PositionSide(position.Side)
+	}
+	if err := service.Do(context.Background()); err != nil {
+		msg := tr(userID, "Margin ratio for %s is %.2f%%, but adding %.2f USDT margin failed: %v",
+			position.Symbol, ratio, settings.AutoMarginAmount, err)
+		b.sendMessageToUser(userID, msg)
+		return
+	}
+	b.sendMessageToUser(userID, tr(userID, "Margin ratio for %s reached %.2f%%. Added %.2f USDT isolated margin.",
+		position.Symbol, ratio, settings.AutoMarginAmount))
+}
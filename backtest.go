@@ -0,0 +1,486 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Candles signals are replayed over: 5 minute klines for up to about five days after each
+// signal, which a single klines request returns.
+const (
+	backtestInterval = "5m"
+	backtestCandles  = 1500
+)
+
+// backtestMaxSignals bounds the signals a backtest replays, the most recent ones in its range.
+const backtestMaxSignals = 200
+
+// defaultBacktestDays and maxBacktestDays bound the range /backtest replays.
+const (
+	defaultBacktestDays = 30
+	maxBacktestDays     = 365
+)
+
+// Fee rates of Binance USDT-M futures without discounts, charged on simulated fills: limit
+// entries as maker, market entries and TP/SL exits as taker.
+const (
+	backtestMakerFee = 0.0002
+	backtestTakerFee = 0.0005
+)
+
+// Outcomes of a replayed signal.
+const (
+	BacktestTP   = "tp"   // Closed by its TPs
+	BacktestSL   = "sl"   // Stopped out, possibly after some TPs
+	BacktestOpen = "open" // Still open after the last candle, closed at its close
+)
+
+// backtestCandle is a closed kline a signal is replayed over.
+type backtestCandle struct {
+	OpenTime               time.Time
+	Open, High, Low, Close float64
+}
+
+// BacktestTrade is a stored signal replayed with the current settings, as the trade it would
+// have been.
+type BacktestTrade struct {
+	Trade
+	Timeframe string
+	Outcome   string // BacktestTP, BacktestSL or BacktestOpen
+	TPsHit    int
+}
+
+// BacktestReport is the hypothetical performance of the stored signals received in a range.
+type BacktestReport struct {
+	From, To   time.Time
+	Signals    int // Stored signals in the range with an entry price
+	Unfilled   int // Limit entries the price never reached
+	Skipped    int // Signals without klines, e.g. delisted symbols
+	Trades     []BacktestTrade
+	FinishedAt time.Time
+}
+
+// Performance returns the totals of all replayed trades.
+func (r *BacktestReport) Performance() PerformanceData {
+	return calculatePerformanceMetrics(r.trades())
+}
+
+// trades returns the replayed trades without their backtest details.
+func (r *BacktestReport) trades() []Trade {
+	trades := make([]Trade, len(r.Trades))
+	for i, trade := range r.Trades {
+		trades[i] = trade.Trade
+	}
+	return trades
+}
+
+// Outcomes counts the replayed trades by outcome.
+func (r *BacktestReport) Outcomes() map[string]int {
+	counts := make(map[string]int)
+	for _, trade := range r.Trades {
+		counts[trade.Outcome]++
+	}
+	return counts
+}
+
+// ByStrategy returns the performance of each strategy, untagged signals last under "".
+func (r *BacktestReport) ByStrategy() []PerformanceGroup {
+	return groupPerformance(r.trades(), func(t Trade) string { return t.Strategy })
+}
+
+// ByTimeframe returns the performance of each signal timeframe, signals without one last
+// under "".
+func (r *BacktestReport) ByTimeframe() []PerformanceGroup {
+	timeframes := make(map[string]string, len(r.Trades))
+	for _, trade := range r.Trades {
+		timeframes[trade.SignalID] = trade.Timeframe
+	}
+	return groupPerformance(r.trades(), func(t Trade) string { return timeframes[t.SignalID] })
+}
+
+// backtestSignalType returns the stored signal's side, Buy or Sell. The side of signals stored
+// without one is read from where their TPs or SL lie, and is "" if neither is set.
+func backtestSignalType(signal *Signal) string {
+	if signal.Side != "" {
+		return signal.Side
+	}
+	if len(signal.TPs) > 0 && signal.TPs[0] > 0 {
+		if signal.TPs[0] > signal.EntryPrice {
+			return "Buy"
+		}
+		return "Sell"
+	}
+	if signal.SL > 0 {
+		if signal.SL < signal.EntryPrice {
+			return "Buy"
+		}
+		return "Sell"
+	}
+	return ""
+}
+
+// backtestClosePcts returns the share of the position closed at each TP, as ExecuteTrade closes
+// it: all of it at the single TP when auto-calculating, otherwise each level's close percentage,
+// with the last TP closing the rest (see tpCloseQuantities). The rounding of the shares to the
+// step size, and the TPs of positions too small to split closing all of it, are left out.
+func backtestClosePcts(settings *UserSettings, tps int) []float64 {
+	if settings.AutoCalculateTPs {
+		return []float64{100}
+	}
+	pcts := make([]float64, tps)
+	for i := range pcts {
+		if i < len(settings.TPLevels) {
+			pcts[i] = settings.TPLevels[i].ClosePct
+		}
+	}
+	return pcts
+}
+
+// backtestFill is a replayed position: where it was entered and left, and how.
+type backtestFill struct {
+	Entry    float64
+	Exit     float64 // Average exit price, weighted by the share closed
+	Outcome  string
+	TPsHit   int
+	OpenedAt time.Time
+	ClosedAt time.Time
+}
+
+// simulateSignal replays a signal over candles, entering at the first candle's open, or for
+// limit entries once a candle reaches the entry price, and closing the position's share of
+// each TP it reaches and the rest at the SL. The order of prices within a candle is unknown,
+// so a candle reaching both the SL and a TP stops the position out. It returns false if the
+// entry is never filled.
+func simulateSignal(signalType string, entry float64, tps, closePcts []float64, sl float64, limit bool, candles []backtestCandle) (backtestFill, bool) {
+	direction := 1.0
+	if signalType == "Sell" {
+		direction = -1
+	}
+
+	start := -1
+	fill := backtestFill{Entry: entry, Outcome: BacktestOpen}
+	for i, candle := range candles {
+		if !limit {
+			fill.Entry = candle.Open
+		} else if candle.Low > entry || candle.High < entry {
+			continue
+		}
+		start = i
+		fill.OpenedAt = candle.OpenTime
+		break
+	}
+	if start < 0 {
+		return fill, false
+	}
+
+	remaining := 1.0
+	var exitValue float64
+	closeShare := func(share, price float64) {
+		share = min(share, remaining)
+		exitValue += share * price
+		remaining -= share
+	}
+	for _, candle := range candles[start:] {
+		fill.ClosedAt = candle.OpenTime
+		if sl > 0 && ((direction > 0 && candle.Low <= sl) || (direction < 0 && candle.High >= sl)) {
+			closeShare(remaining, sl)
+			fill.Outcome = BacktestSL
+			break
+		}
+		for fill.TPsHit < len(tps) {
+			tp := tps[fill.TPsHit]
+			if tp <= 0 || (direction > 0 && candle.High < tp) || (direction < 0 && candle.Low > tp) {
+				break
+			}
+			share := remaining
+			if fill.TPsHit < len(tps)-1 && fill.TPsHit < len(closePcts) {
+				share = closePcts[fill.TPsHit] / 100
+			}
+			closeShare(share, tp)
+			fill.TPsHit++
+		}
+		if remaining <= 1e-9 {
+			fill.Outcome = BacktestTP
+			break
+		}
+	}
+	if remaining > 1e-9 {
+		closeShare(remaining, candles[len(candles)-1].Close)
+	}
+	fill.Exit = exitValue
+	return fill, true
+}
+
+// backtestKlines fetches the closed candles after a signal was received.
+func (b *BinanceClient) backtestKlines(ctx context.Context, symbol string, from time.Time) ([]backtestCandle, error) {
+	klines, err := b.Client.NewKlinesService().
+		Symbol(symbol).
+		Interval(backtestInterval).
+		StartTime(from.UnixMilli()).
+		Limit(backtestCandles).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines: %v", err)
+	}
+
+	now := time.Now().UnixMilli()
+	candles := make([]backtestCandle, 0, len(klines))
+	for _, k := range klines {
+		if k.CloseTime > now {
+			break
+		}
+		candle := backtestCandle{OpenTime: time.UnixMilli(k.OpenTime)}
+		for _, field := range []struct {
+			value string
+			dest  *float64
+		}{{k.Open, &candle.Open}, {k.High, &candle.High}, {k.Low, &candle.Low}, {k.Close, &candle.Close}} {
+			if *field.dest, err = strconv.ParseFloat(field.value, 64); err != nil {
+				return nil, fmt.Errorf("failed to parse kline: %v", err)
+			}
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// backtest replays the stored signals received between from and to with the settings, sized
+// with their amount and leverage. progress is called after each signal.
+func (b *BinanceClient) backtest(ctx context.Context, from, to time.Time, settings *UserSettings, progress func(done, total int)) (*BacktestReport, error) {
+	signals, err := ListSignals(SignalFilter{From: from, To: to}, backtestMaxSignals)
+	if err != nil {
+		return nil, err
+	}
+	signals = slices.DeleteFunc(signals, func(s Signal) bool { return s.EntryPrice <= 0 || backtestSignalType(&s) == "" })
+
+	report := &BacktestReport{From: from, To: to, Signals: len(signals)}
+	for i := range signals {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		trade, filled, err := b.backtestSignal(ctx, &signals[i], settings)
+		switch {
+		case errors.Is(err, errBinanceBusy):
+			// Klines give way to trading; stop rather than skip every remaining signal
+			return nil, err
+		case err != nil:
+			binanceLog.Warn("Skipping signal in backtest", "signal_id", signals[i].SignalID, "symbol", signals[i].Symbol, "error", err)
+			report.Skipped++
+		case !filled:
+			report.Unfilled++
+		default:
+			report.Trades = append(report.Trades, trade)
+		}
+		if progress != nil {
+			progress(i+1, len(signals))
+		}
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// backtestSignal replays a stored signal with the TPs and SL ExecuteTrade would place for it
+// with the settings.
+func (b *BinanceClient) backtestSignal(ctx context.Context, signal *Signal, settings *UserSettings) (BacktestTrade, bool, error) {
+	alert := filterEnabledTPs(&AlertMessage{
+		SignalID:   signal.SignalID,
+		SignalType: backtestSignalType(signal),
+		Symbol:     signal.Symbol,
+		EntryPrice: signal.EntryPrice,
+		TPs:        signal.TPs,
+		SL:         signal.SL,
+	}, settings)
+	if settings.AutoCalculateTPs {
+		recalcSingleTPAndSL(alert, settings)
+	} else {
+		recalcManualTPAndSL(alert, settings)
+	}
+
+	candles, err := b.backtestKlines(ctx, signal.Symbol, signal.Timestamp)
+	if err != nil {
+		return BacktestTrade{}, false, err
+	}
+	if len(candles) == 0 {
+		return BacktestTrade{}, false, fmt.Errorf("no klines since %s", signal.Timestamp.Format(time.RFC3339))
+	}
+
+	// Stop entries, like limits, fill once a candle reaches the entry
+	limit := settings.TradingMode == "Limit" || settings.TradingMode == TradingModeStop
+	fill, filled := simulateSignal(alert.SignalType, alert.EntryPrice, alert.TPs, backtestClosePcts(settings, len(alert.TPs)), alert.SL, limit, candles)
+	if !filled {
+		return BacktestTrade{}, false, nil
+	}
+
+	quantity := settings.AmountUSDT * float64(settings.Leverage) / fill.Entry
+	direction := 1.0
+	side := "BUY"
+	if alert.SignalType == "Sell" {
+		direction, side = -1, "SELL"
+	}
+	trade := Trade{
+		SignalID:    signal.SignalID,
+		Symbol:      signal.Symbol,
+		Side:        side,
+		Strategy:    signal.Strategy,
+		EntryPrice:  fill.Entry,
+		ExitPrice:   fill.Exit,
+		GrossProfit: (fill.Exit - fill.Entry) * direction * quantity,
+		TakerFees:   fill.Exit * quantity * backtestTakerFee,
+		OpenedAt:    fill.OpenedAt,
+		Timestamp:   fill.ClosedAt,
+	}
+	if limit {
+		trade.MakerFees = fill.Entry * quantity * backtestMakerFee
+	} else {
+		trade.TakerFees += fill.Entry * quantity * backtestTakerFee
+	}
+	if alert.SL > 0 {
+		trade.InitialRisk = math.Abs(fill.Entry-alert.SL) * quantity
+	}
+	trade.Profit = trade.GrossProfit - trade.Fees()
+
+	return BacktestTrade{Trade: trade, Timeframe: signal.Timeframe, Outcome: fill.Outcome, TPsHit: fill.TPsHit}, true, nil
+}
+
+// backtestRunner runs one backtest at a time in the background and keeps the last report for
+// the admin panel.
+type backtestRunner struct {
+	mu          sync.Mutex
+	running     bool
+	done, total int
+	last        *BacktestReport
+	lastErr     error
+}
+
+var backtests backtestRunner
+
+// Status returns whether a backtest is running and how far it got, and the last report or
+// the error the last backtest failed with.
+func (r *backtestRunner) Status() (running bool, done, total int, last *BacktestReport, lastErr error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running, r.done, r.total, r.last, r.lastErr
+}
+
+// Start replays the signals received in the last days with a copy of the chat's settings in
+// the background, calling finished with the report or error. It fails if a backtest is
+// already running.
+func (r *backtestRunner) Start(chatID int64, days int, finished func(*BacktestReport, error)) error {
+	client := mainBinanceClient()
+	if client == nil {
+		return fmt.Errorf("Binance is not configured")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return fmt.Errorf("a backtest is already running (%d of %d signals)", r.done, r.total)
+	}
+	r.running, r.done, r.total = true, 0, 0
+
+	settings := *userSettings.Get(chatID)
+	settings.TPLevels = slices.Clone(settings.TPLevels)
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+	go func() {
+		report, err := client.backtest(context.Background(), from, to, &settings, func(done, total int) {
+			r.mu.Lock()
+			r.done, r.total = done, total
+			r.mu.Unlock()
+		})
+		r.mu.Lock()
+		r.running = false
+		if err == nil {
+			r.last = report
+		}
+		r.lastErr = err
+		r.mu.Unlock()
+		if finished != nil {
+			finished(report, err)
+		}
+	}()
+	return nil
+}
+
+// parseBacktestDays reads the days a backtest goes back, defaultBacktestDays if empty.
+func parseBacktestDays(value string) (int, error) {
+	if value == "" {
+		return defaultBacktestDays, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > maxBacktestDays {
+		return 0, fmt.Errorf("days must be a whole number from 1 to %d", maxBacktestDays)
+	}
+	return days, nil
+}
+
+// handleBacktestCommand replays the signals of the last days, 30 by default, with the chat's
+// current settings for "/backtest [days]" and replies with the report.
+func handleBacktestCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	days, err := parseBacktestDays(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /backtest [days], with days from 1 to %d", maxBacktestDays)))
+		return
+	}
+
+	err = backtests.Start(chatID, days, func(report *BacktestReport, err error) {
+		if err != nil {
+			telegramLog.Error("Backtest failed", "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Backtest failed: %v", err)))
+			return
+		}
+		if _, err := bot.Send(tgbotapi.NewMessage(chatID, formatBacktestReport(chatID, report))); err != nil {
+			telegramLog.Error("Failed to send backtest report", "chat_id", chatID, "error", err)
+		}
+	})
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Backtest not started: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Replaying the signals of the last %d days with your current settings...", days)))
+}
+
+// formatBacktestReport renders a backtest report for Telegram.
+func formatBacktestReport(chatID int64, report *BacktestReport) string {
+	data := report.Performance()
+	outcomes := report.Outcomes()
+
+	text := tr(chatID, "Backtest from %s to %s:\n", formatUserTime(chatID, report.From), formatUserTime(chatID, report.To))
+	text += tr(chatID, "%d signals: %d simulated, %d not filled, %d skipped\n",
+		report.Signals, len(report.Trades), report.Unfilled, report.Skipped)
+	if len(report.Trades) == 0 {
+		return text
+	}
+	text += formatGroupResult(chatID, data)
+	text += tr(chatID, "Gross Profit: %.2f\n", data.GrossProfit) + tr(chatID, "Fees: %.2f\n", data.TotalFees)
+	text += tr(chatID, "Closed by TPs: %d, stopped out: %d, still open: %d\n",
+		outcomes[BacktestTP], outcomes[BacktestSL], outcomes[BacktestOpen])
+	text += formatRiskMetrics(chatID, data)
+
+	for _, section := range []struct {
+		title, untagged string
+		groups          []PerformanceGroup
+	}{
+		{tr(chatID, "\nBy Strategy:\n"), tr(chatID, "Untagged"), report.ByStrategy()},
+		{tr(chatID, "\nBy Timeframe:\n"), tr(chatID, "No timeframe"), report.ByTimeframe()},
+	} {
+		text += section.title
+		for _, group := range section.groups {
+			name := group.Name
+			if name == "" {
+				name = section.untagged
+			}
+			text += fmt.Sprintf("%s: ", name) + formatGroupResult(chatID, group.PerformanceData)
+		}
+	}
+	text += tr(chatID, "\nHypothetical results over 5m candles up to 5 days after each signal, with standard fees. A candle reaching both the SL and a TP counts as stopped out.")
+	return text
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mattn/go-sqlite3"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Backup defaults, overridden with the BACKUP_* environment variables.
+const (
+	defaultBackupDir           = "backups"
+	defaultBackupIntervalHours = 24
+	defaultBackupKeep          = 7
+)
+
+// backupPrefix starts every backup name, followed by the time it was taken.
+const backupPrefix = "config-"
+
+// backupTimeLayout is the time in backup names, so they sort oldest first.
+const backupTimeLayout = "20060102-150405"
+
+// maxListedBackups is how many backups /backup and /restore list.
+const maxListedBackups = 20
+
+// backupMu keeps backups and restores from running at the same time.
+var backupMu sync.Mutex
+
+// backupDir returns the directory backups are written to.
+func backupDir() string {
+	if dir := os.Getenv("BACKUP_DIR"); dir != "" {
+		return dir
+	}
+	return defaultBackupDir
+}
+
+// envInt reads a non-negative whole number from the environment, falling back to def.
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		slog.Warn("Invalid environment variable, using the default", "name", name, "value", value, "default", def)
+		return def
+	}
+	return n
+}
+
+// backupStorage is the S3-compatible bucket backups are uploaded to.
+type backupStorage struct {
+	client *minio.Client
+	bucket string
+}
+
+// newBackupStorage connects to the bucket in BACKUP_S3_ENDPOINT and BACKUP_S3_BUCKET, or
+// returns nil if uploads are not configured. The endpoint uses HTTPS unless it starts with http://.
+func newBackupStorage() (*backupStorage, error) {
+	endpoint := os.Getenv("BACKUP_S3_ENDPOINT")
+	bucket := os.Getenv("BACKUP_S3_BUCKET")
+	if endpoint == "" || bucket == "" {
+		return nil, nil
+	}
+	secure := !strings.HasPrefix(endpoint, "http://")
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(os.Getenv("BACKUP_S3_ACCESS_KEY"), os.Getenv("BACKUP_S3_SECRET_KEY"), ""),
+		Secure: secure,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to backup storage: %w", err)
+	}
+	return &backupStorage{client: client, bucket: bucket}, nil
+}
+
+// backupExtension returns the file extension of the driver's backups: SQLite snapshots or SQL dumps.
+func backupExtension(driver string) string {
+	if driver == DriverSQLite {
+		return ".db"
+	}
+	return ".sql"
+}
+
+// sqlitePath returns the database file of a SQLite DSN such as "file:config.db?_busy_timeout=5000".
+func sqlitePath(dsn string) (string, error) {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || path == ":memory:" {
+		return "", errors.New("in-memory SQLite databases cannot be backed up")
+	}
+	return path, nil
+}
+
+// mysqlArgs returns the mysql and mysqldump connection arguments for a DSN and its password,
+// which is passed in MYSQL_PWD so it does not show in the process list.
+func mysqlArgs(dsn string) ([]string, string, error) {
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid MySQL DB_DSN: %w", err)
+	}
+	args := []string{"--user=" + cfg.User}
+	if cfg.Net == "unix" {
+		args = append(args, "--socket="+cfg.Addr)
+	} else {
+		host, port, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			host, port = cfg.Addr, "3306"
+		}
+		args = append(args, "--host="+host, "--port="+port)
+	}
+	return append(args, cfg.DBName), cfg.Passwd, nil
+}
+
+// runDatabaseTool runs pg_dump, psql, mysqldump or mysql, including its output in errors.
+func runDatabaseTool(cmd *exec.Cmd) error {
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", filepath.Base(cmd.Path), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// createBackup snapshots the database into the backup directory, uploads it if storage is
+// configured and removes all but the newest BACKUP_KEEP local backups. It returns the backup's name.
+func createBackup() (string, error) {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	name, err := createBackupLocked()
+	if err == nil {
+		pruneBackups()
+	}
+	return name, err
+}
+
+// createBackupLocked is createBackup without pruning, for callers holding backupMu.
+func createBackupLocked() (string, error) {
+	driver, dsn := databaseSettings()
+	if err := os.MkdirAll(backupDir(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	name := backupPrefix + time.Now().UTC().Format(backupTimeLayout) + backupExtension(driver)
+	path := filepath.Join(backupDir(), name)
+
+	switch driver {
+	case DriverSQLite:
+		// VACUUM INTO writes a consistent copy while the bot keeps using the database
+		if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+			return "", fmt.Errorf("failed to snapshot database: %w", err)
+		}
+	case DriverPostgres:
+		cmd := exec.Command("pg_dump", "--clean", "--if-exists", "--no-owner", "--file="+path, "--dbname="+dsn)
+		if err := runDatabaseTool(cmd); err != nil {
+			return "", err
+		}
+	case DriverMySQL:
+		args, password, err := mysqlArgs(dsn)
+		if err != nil {
+			return "", err
+		}
+		cmd := exec.Command("mysqldump", append([]string{"--single-transaction", "--result-file=" + path}, args...)...)
+		cmd.Env = append(os.Environ(), "MYSQL_PWD="+password)
+		if err := runDatabaseTool(cmd); err != nil {
+			return "", err
+		}
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		dbLog.Error("Failed to restrict backup permissions", "error", err)
+	}
+
+	storage, err := newBackupStorage()
+	if err != nil {
+		return name, err
+	}
+	if storage != nil {
+		if _, err := storage.client.FPutObject(context.Background(), storage.bucket, name, path, minio.PutObjectOptions{}); err != nil {
+			return name, fmt.Errorf("backup %s was saved locally but the upload failed: %w", name, err)
+		}
+	}
+	return name, nil
+}
+
+// pruneBackups removes local backups beyond the newest BACKUP_KEEP. Uploaded backups are kept;
+// use the bucket's lifecycle rules to expire them.
+func pruneBackups() {
+	keep := envInt("BACKUP_KEEP", defaultBackupKeep)
+	names, err := localBackups()
+	if err != nil || keep == 0 || len(names) <= keep {
+		return
+	}
+	for _, name := range names[keep:] {
+		if err := os.Remove(filepath.Join(backupDir(), name)); err != nil {
+			dbLog.Error("Failed to remove old backup", "backup", name, "error", err)
+		}
+	}
+}
+
+// localBackups returns the names of the backups in the backup directory, newest first.
+func localBackups() ([]string, error) {
+	entries, err := os.ReadDir(backupDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), backupPrefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// ListBackups returns the local and uploaded backups, newest first.
+func ListBackups() ([]string, error) {
+	names, err := localBackups()
+	if err != nil {
+		return nil, err
+	}
+	storage, err := newBackupStorage()
+	if err != nil || storage == nil {
+		return names, err
+	}
+
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+	for object := range storage.client.ListObjects(context.Background(), storage.bucket, minio.ListObjectsOptions{Prefix: backupPrefix}) {
+		if object.Err != nil {
+			return names, fmt.Errorf("failed to list uploaded backups: %w", object.Err)
+		}
+		if !seen[object.Key] {
+			names = append(names, object.Key)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// fetchBackup returns the local path of a backup, downloading it from storage if needed.
+func fetchBackup(name string) (string, error) {
+	if filepath.Base(name) != name || !strings.HasPrefix(name, backupPrefix) {
+		return "", fmt.Errorf("invalid backup name %q", name)
+	}
+	path := filepath.Join(backupDir(), name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	storage, err := newBackupStorage()
+	if err != nil {
+		return "", err
+	}
+	if storage == nil {
+		return "", fmt.Errorf("backup %s not found", name)
+	}
+	if err := storage.client.FGetObject(context.Background(), storage.bucket, name, path, minio.GetObjectOptions{}); err != nil {
+		return "", fmt.Errorf("failed to download backup %s: %w", name, err)
+	}
+	return path, nil
+}
+
+// restoreBackup replaces the database with a backup, after backing up the current one, then
+// applies any migrations newer than the backup and reloads the configuration. It returns the
+// name of the backup taken before restoring.
+func restoreBackup(name string) (string, error) {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	driver, dsn := databaseSettings()
+	if filepath.Ext(name) != backupExtension(driver) {
+		return "", fmt.Errorf("backup %s is not a %s backup", name, driver)
+	}
+	path, err := fetchBackup(name)
+	if err != nil {
+		return "", err
+	}
+	previous, err := createBackupLocked()
+	if err != nil {
+		return "", fmt.Errorf("failed to back up the current database: %w", err)
+	}
+	// Pruning could otherwise remove the backup being restored
+	defer pruneBackups()
+
+	switch driver {
+	case DriverSQLite:
+		if err := restoreSQLite(path, dsn); err != nil {
+			return previous, err
+		}
+	case DriverPostgres:
+		cmd := exec.Command("psql", "--quiet", "--set=ON_ERROR_STOP=1", "--file="+path, "--dbname="+dsn)
+		if err := runDatabaseTool(cmd); err != nil {
+			return previous, err
+		}
+	case DriverMySQL:
+		args, password, err := mysqlArgs(dsn)
+		if err != nil {
+			return previous, err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return previous, fmt.Errorf("failed to open backup: %w", err)
+		}
+		defer file.Close()
+		cmd := exec.Command("mysql", args...)
+		cmd.Env = append(os.Environ(), "MYSQL_PWD="+password)
+		cmd.Stdin = file
+		if err := runDatabaseTool(cmd); err != nil {
+			return previous, err
+		}
+	}
+
+	if err := applyMigrations(); err != nil {
+		return previous, err
+	}
+	config, err := getConfig()
+	if err != nil {
+		return previous, err
+	}
+	SetGlobalConfig(*config)
+	return previous, nil
+}
+
+// restoreSQLite copies the backup into the database with SQLite's online backup, on the write
+// connection, so the bot keeps its database handle and its other writes wait in the write queue
+// until the copy is done. Reads see the database as it was until then.
+func restoreSQLite(backupPath, dsn string) error {
+	if _, err := sqlitePath(dsn); err != nil {
+		return err
+	}
+	source, err := sql.Open("sqlite3", sqliteDSN("file:"+backupPath, map[string]string{"mode": "ro"}))
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer source.Close()
+	writer, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	ctx := context.Background()
+	dst, err := writer.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the write connection: %w", err)
+	}
+	defer dst.Close()
+	src, err := source.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer src.Close()
+
+	return dst.Raw(func(dstConn any) error {
+		return src.Raw(func(srcConn any) error {
+			to, ok := dstConn.(*sqlite3.SQLiteConn)
+			from, ok2 := srcConn.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return errors.New("the database is not a SQLite connection")
+			}
+			backup, err := to.Backup("main", from, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start restoring: %w", err)
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("failed to copy backup: %w", err)
+			}
+			if err := backup.Finish(); err != nil {
+				return fmt.Errorf("failed to copy backup: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+var backupStart sync.Once
+
+// startBackupScheduler backs up the database every BACKUP_INTERVAL_HOURS; 0 turns it off.
+func startBackupScheduler() {
+	hours := envInt("BACKUP_INTERVAL_HOURS", defaultBackupIntervalHours)
+	if hours == 0 {
+		return
+	}
+	backupStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(time.Duration(hours) * time.Hour)
+			defer ticker.Stop()
+			for range ticker.C {
+				name, err := createBackup()
+				if err != nil {
+					dbLog.Error("Scheduled backup failed", "error", err)
+					continue
+				}
+				dbLog.Info("Database backed up", "backup", name)
+			}
+		}()
+	})
+}
+
+// handleBackupCommand backs up the database now and lists the available backups.
+func handleBackupCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	name, err := createBackup()
+	if err != nil {
+		dbLog.Error("Backup failed", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Backup failed: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Backup created: %s", name)+"\n\n"+backupList(chatID)))
+}
+
+// handleRestoreCommand restores the backup named in "/restore <name>", or lists the backups.
+func handleRestoreCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /restore <backup name>")+"\n\n"+backupList(chatID)))
+		return
+	}
+
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Restoring %s...", name)))
+	previous, err := restoreBackup(name)
+	if err != nil {
+		dbLog.Error("Restore failed", "backup", name, "error", err)
+		text := tr(chatID, "Restore failed: %v", err)
+		if previous != "" {
+			text += "\n" + tr(chatID, "The database before the restore was saved as %s.", previous)
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+		return
+	}
+	dbLog.Info("Database restored", "backup", name)
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Restored %s.", name)+"\n"+
+		tr(chatID, "The database before the restore was saved as %s.", previous)))
+}
+
+// backupList renders the newest backups for a chat.
+func backupList(chatID int64) string {
+	names, err := ListBackups()
+	if err != nil {
+		dbLog.Error("Failed to list backups", "error", err)
+	}
+	if len(names) == 0 {
+		return tr(chatID, "No backups found.")
+	}
+	if len(names) > maxListedBackups {
+		names = names[:maxListedBackups]
+	}
+	return tr(chatID, "Available backups:\n") + strings.Join(names, "\n")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// benchmarkSymbols are the coins performance reports compare holding to.
+var benchmarkSymbols = []string{"BTCUSDT", "ETHUSDT"}
+
+// benchmarkTimeout bounds the kline requests of a benchmark comparison, which holds up the report.
+const benchmarkTimeout = 10 * time.Second
+
+// benchmarkReturn is the price change of holding a symbol over a period.
+type benchmarkReturn struct {
+	Symbol     string
+	StartPrice float64
+	EndPrice   float64
+}
+
+// Change returns the return of holding the symbol as a fraction of its start price.
+func (r benchmarkReturn) Change() float64 {
+	return r.EndPrice/r.StartPrice - 1
+}
+
+// benchmarkPrices returns a symbol's price at from, the open of its first one-minute kline
+// then, and now, the close of the latest one.
+func (b *BinanceClient) benchmarkPrices(ctx context.Context, symbol string, from time.Time) (benchmarkReturn, error) {
+	result := benchmarkReturn{Symbol: symbol}
+	first, err := b.Client.NewKlinesService().Symbol(symbol).Interval("1m").StartTime(from.UnixMilli()).Limit(1).Do(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to get klines: %v", err)
+	}
+	last, err := b.Client.NewKlinesService().Symbol(symbol).Interval("1m").Limit(1).Do(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to get klines: %v", err)
+	}
+	if len(first) == 0 || len(last) == 0 {
+		return result, fmt.Errorf("no klines for %s", symbol)
+	}
+	if result.StartPrice, err = strconv.ParseFloat(first[0].Open, 64); err != nil {
+		return result, fmt.Errorf("failed to parse kline: %v", err)
+	}
+	if result.EndPrice, err = strconv.ParseFloat(last[0].Close, 64); err != nil {
+		return result, fmt.Errorf("failed to parse kline: %v", err)
+	}
+	if result.StartPrice <= 0 {
+		return result, fmt.Errorf("no price for %s at %s", symbol, from.Format(time.RFC3339))
+	}
+	return result, nil
+}
+
+// benchmarkText compares the net profit of a performance period with holding each benchmark
+// symbol over it, both on the chat's trade amount as capital. It is empty unless the chat
+// asked for the comparison and the period has a start.
+func benchmarkText(chatID int64, from time.Time, data PerformanceData) string {
+	settings := userSettings.Get(chatID)
+	capital := settings.AmountUSDT
+	client := mainBinanceClient()
+	if !settings.ShowBenchmark || client == nil || capital <= 0 || !from.Before(time.Now()) {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), benchmarkTimeout)
+	defer cancel()
+	var returns []benchmarkReturn
+	for _, symbol := range benchmarkSymbols {
+		result, err := client.benchmarkPrices(ctx, symbol, from)
+		if err != nil {
+			binanceLog.Error("Failed to get benchmark", "symbol", symbol, "error", err)
+			continue
+		}
+		returns = append(returns, result)
+	}
+	if len(returns) == 0 {
+		return ""
+	}
+	return formatBenchmark(chatID, capital, data, returns)
+}
+
+// formatBenchmark renders the strategy's net profit next to holding each symbol with capital,
+// with how far ahead or behind the strategy is.
+func formatBenchmark(chatID int64, capital float64, data PerformanceData, returns []benchmarkReturn) string {
+	text := tr(chatID, "\nVs. Buy & Hold (%.2f USDT capital):\n", capital)
+	text += tr(chatID, "Strategy: %+.2f USDT (%+.2f%%)\n", data.NetProfit, data.NetProfit/capital*100)
+	for _, result := range returns {
+		held := capital * result.Change()
+		text += tr(chatID, "Holding %s: %+.2f USDT (%+.2f%%), strategy %+.2f\n",
+			strings.TrimSuffix(result.Symbol, "USDT"), held, result.Change()*100, data.NetProfit-held)
+	}
+	return text
+}
+
+// toggleShowBenchmark toggles the buy-and-hold comparison of performance reports.
+func toggleShowBenchmark(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.ShowBenchmark = !settings.ShowBenchmark
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Buy & Hold Benchmark has been %s.", enabledText(chatID, settings.ShowBenchmark)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/delivery"
+)
+
+const (
+	// MarketTypeUSDTM trades USDT-margined perpetuals via the futures client.
+	MarketTypeUSDTM = "USDT-M"
+	// MarketTypeCoinM trades coin-margined perpetuals via the delivery client.
+	MarketTypeCoinM = "COIN-M"
+)
+
+// deliveryBaseURL derives the COIN-M REST endpoint from the configured USDT-M endpoint.
+func deliveryBaseURL(futuresURL string) string {
+	if strings.Contains(futuresURL, "testnet") {
+		return "https://testnet.binancefuture.com"
+	}
+	return "https://dapi.binance.com"
+}
+
+// deliverySymbol maps a USDT-M symbol such as BTCUSDT to its COIN-M perpetual, BTCUSD_PERP.
+// Symbols that already look like delivery contracts are returned unchanged.
+func deliverySymbol(symbol string) string {
+	if strings.Contains(symbol, "_") {
+		return symbol
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(symbol, "USDT"), "USD")
+	return base + "USD_PERP"
+}
+
+// executeDeliveryTrade places the entry and TP/SL orders on COIN-M futures.
+// Quantities are expressed in contracts, each worth the symbol's contract size in USD.
+func (b *BinanceClient) executeDeliveryTrade(ctx context.Context, signal *AlertMessage, settings *UserSettings, userID int64) error {
+	symbol := deliverySymbol(signal.Symbol)
+
+	side := delivery.SideTypeBuy
+	if signal.SignalType == "Sell" {
+		side = delivery.SideTypeSell
+	}
+
+	if err := b.setDeliveryMarginModeAndLeverage(ctx, symbol, settings); err != nil {
+		return fmt.Errorf("failed to set margin mode or leverage: %v", err)
+	}
+
+	info, err := b.getDeliverySymbolInfo(ctx, symbol)
+	if err != nil {
+		msg := tr(userID, "Failed to get contract info for %s: %v", symbol, err)
+		b.sendMessageToUser(userID, msg)
+		return err
+	}
+
+	contracts, err := calculateDeliveryContracts(info, settings.AmountUSDT)
+	if err != nil {
+		msg := tr(userID, "Failed to calculate quantity for %s: %v", symbol, err)
+		b.sendMessageToUser(userID, msg)
+		return err
+	}
+
+	clientID := clientOrderID(signal.SignalID, OrderTagEntry)
+	order := b.Delivery.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Quantity(contracts).
+		NewClientOrderID(clientID)
+	params := fmt.Sprintf("symbol=%s side=%s type=MARKET quantity=%s", symbol, side, contracts)
+	if settings.TradingMode == "Limit" {
+		price, err := formatDeliveryPrice(info, signal.EntryPrice)
+		if err != nil {
+			return err
+		}
+		order = order.Type(delivery.OrderTypeLimit).TimeInForce(delivery.TimeInForceTypeGTC).Price(price)
+		params = fmt.Sprintf("symbol=%s side=%s type=LIMIT quantity=%s price=%s", symbol, side, contracts, price)
+	} else {
+		order = order.Type(delivery.OrderTypeMarket)
+	}
+	res, err := order.Do(ctx)
+	auditOrder(AuditOrder, clientID, params, res, err)
+	if err != nil {
+		txt := tr(userID, "Failed to execute trade for %s: %v", symbol, err)
+		b.sendMessageToUser(userID, txt)
+		return err
+	}
+	recordDeliveryOrder(res)
+	b.sendMessageToUser(userID, tr(userID, "Trade executed for %s (%s, %s contracts)", symbol, settings.TradingMode, contracts))
+
+	// TP/SL orders close the position, so they can only be placed once it exists
+	if settings.TradingMode != "Market" {
+		return nil
+	}
+
+	protect := context.WithoutCancel(ctx)
+	closeSide := delivery.SideTypeSell
+	if side == delivery.SideTypeSell {
+		closeSide = delivery.SideTypeBuy
+	}
+
+	// Each TP closes its level's share of the contracts and the last one the rest
+	tps := placedTPs(signal.TPs, settings)
+	count, _ := strconv.ParseFloat(contracts, 64)
+	quantities, _ := tpCloseQuantities(count, 1, tps, settings.TPLevels)
+	for i, tp := range tps {
+		if tp <= 0 {
+			continue
+		}
+		var quantity string
+		if i < len(quantities) {
+			quantity = quantities[i]
+		}
+		if err := b.placeDeliveryStopOrder(protect, info, closeSide, delivery.OrderTypeTakeProfitMarket, tp, quantity, clientOrderID(signal.SignalID, tpOrderTag(i)), delivery.WorkingType(workingType(settings))); err != nil {
+			b.sendMessageToUser(userID, tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err))
+			return err
+		}
+	}
+	if settings.UseSL && signal.SL > 0 {
+		if err := b.placeDeliveryStopOrder(protect, info, closeSide, delivery.OrderTypeStopMarket, signal.SL, "", clientOrderID(signal.SignalID, OrderTagSL), delivery.WorkingType(workingType(settings))); err != nil {
+			b.sendMessageToUser(userID, tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err))
+			return err
+		}
+	}
+	b.sendMessageToUser(userID, tr(userID, "TP/SL orders placed for %s.", symbol))
+	return nil
+}
+
+// setDeliveryMarginModeAndLeverage configures the margin mode and leverage on COIN-M futures.
+func (b *BinanceClient) setDeliveryMarginModeAndLeverage(ctx context.Context, symbol string, settings *UserSettings) error {
+	marginType := delivery.MarginTypeCrossed
+	if settings.MarginMode == "Isolated" {
+		marginType = delivery.MarginTypeIsolated
+	}
+
+	// Binance rejects a margin type change if it is already set, so this is best-effort
+	if err := b.Delivery.NewChangeMarginTypeService().
+		Symbol(symbol).
+		MarginType(marginType).
+		Do(ctx); err != nil {
+		binanceLog.Warn("Failed to set margin mode", "symbol", symbol, "market_type", MarketTypeCoinM, "error", err)
+	}
+
+	leverage := settings.Leverage
+	if leverage <= 0 {
+		leverage = 5
+	}
+	if _, err := b.Delivery.NewChangeLeverageService().
+		Symbol(symbol).
+		Leverage(leverage).
+		Do(ctx); err != nil {
+		return fmt.Errorf("failed to set leverage: %v", err)
+	}
+	return nil
+}
+
+// getDeliverySymbolInfo fetches contract details from the COIN-M exchange info, cached for
+// symbolInfoTTL.
+func (b *BinanceClient) getDeliverySymbolInfo(ctx context.Context, symbol string) (*delivery.Symbol, error) {
+	info, err := deliveryExchangeInfo.Get(b.Delivery.BaseURL, func() (*delivery.ExchangeInfo, error) {
+		return b.Delivery.NewExchangeInfoService().Do(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol == symbol {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("symbol %s not found", symbol)
+}
+
+// calculateDeliveryContracts converts a USD amount into a whole number of contracts.
+func calculateDeliveryContracts(info *delivery.Symbol, amountUSD float64) (string, error) {
+	if info.ContractSize <= 0 {
+		return "", fmt.Errorf("invalid contract size for %s", info.Symbol)
+	}
+	contracts := math.Floor(amountUSD / float64(info.ContractSize))
+	if contracts < 1 {
+		return "", fmt.Errorf("amount %.2f is below one contract of %d USD", amountUSD, info.ContractSize)
+	}
+	return strconv.FormatFloat(contracts, 'f', 0, 64), nil
+}
+
+// formatDeliveryPrice rounds the price based on the contract's PRICE_FILTER tickSize.
+func formatDeliveryPrice(info *delivery.Symbol, price float64) (string, error) {
+	tsStr, err := getFilterValue(info.Filters, "PRICE_FILTER", "tickSize")
+	if err != nil {
+		return "", fmt.Errorf("failed to get tick size: %v", err)
+	}
+	tickSize, err := strconv.ParseFloat(tsStr, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tick size: %v", err)
+	}
+	price = math.Round(price/tickSize) * tickSize
+	return formatDecimal(price, tickSize), nil
+}
+
+// placeDeliveryStopOrder places a TP or SL market order on COIN-M futures that reduces the
+// position by quantity contracts, or closes it if quantity is empty.
+func (b *BinanceClient) placeDeliveryStopOrder(ctx context.Context, info *delivery.Symbol, side delivery.SideType, orderType delivery.OrderType, stopPrice float64, quantity, clientID string, workingType delivery.WorkingType) error {
+	price, err := formatDeliveryPrice(info, stopPrice)
+	if err != nil {
+		return err
+	}
+	order := b.Delivery.NewCreateOrderService().
+		Symbol(info.Symbol).
+		Side(side).
+		Type(orderType).
+		StopPrice(price).
+		WorkingType(workingType).
+		PriceProtect(true).
+		NewClientOrderID(clientID)
+	params := fmt.Sprintf("symbol=%s side=%s type=%s stop_price=%s working_type=%s", info.Symbol, side, orderType, price, workingType)
+	if quantity != "" {
+		order = order.Quantity(quantity).ReduceOnly(true)
+		params += fmt.Sprintf(" quantity=%s reduce_only=true", quantity)
+	} else {
+		order = order.ClosePosition(true)
+		params += " close_position=true"
+	}
+	res, err := order.Do(ctx)
+	auditOrder(AuditOrder, clientID, params, res, err)
+	if err == nil {
+		recordDeliveryOrder(res)
+	}
+	return err
+}
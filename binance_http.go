@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// maxRecvWindow is the largest recvWindow Binance accepts, in milliseconds.
+const maxRecvWindow = 60000
+
+// serverTimeSyncInterval is how often the offset between the local clock and Binance's is
+// measured again.
+const serverTimeSyncInterval = 10 * time.Minute
+
+// Default timeouts, in seconds: defaultBinanceTimeout for each Binance request, overridden by
+// BINANCE_TIMEOUT, and defaultTradeTimeout for placing a confirmed trade up to its entry order,
+// overridden by TRADE_TIMEOUT.
+const (
+	defaultBinanceTimeout = 10
+	defaultTradeTimeout   = 60
+)
+
+// binanceTimeout returns how long a Binance request may take, not counting the time it is held
+// for the request weight budget.
+func binanceTimeout() time.Duration {
+	return time.Duration(envInt("BINANCE_TIMEOUT", defaultBinanceTimeout)) * time.Second
+}
+
+// tradeContext returns the context a confirmed trade is placed with, which gives up on the
+// trade if Binance hasn't taken its entry order within TRADE_TIMEOUT.
+func tradeContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(envInt("TRADE_TIMEOUT", defaultTradeTimeout))*time.Second)
+}
+
+// clockDriftWarning is the offset from Binance's clock above which a warning is logged; with
+// Binance's default recvWindow a larger one would reject signed requests.
+const clockDriftWarning = time.Second
+
+// serverTimeOffset is how many milliseconds the local clock is ahead of Binance's. Signed
+// requests are timestamped with Binance's time, so a drifting clock doesn't fail them with
+// -1021 "Timestamp outside recvWindow".
+var serverTimeOffset atomic.Int64
+
+// syncServerTime measures the offset to Binance's clock against the configured API URL.
+func syncServerTime(ctx context.Context) error {
+	client := futures.NewClient("", "")
+	client.BaseURL = GetGlobalConfig().BinanceAPIURL
+	client.HTTPClient = newBinanceHTTPClient("")
+
+	start := time.Now()
+	serverTime, err := client.NewServerTimeService().Do(ctx)
+	if err != nil {
+		return err
+	}
+	// Binance reads its clock about halfway through the request
+	local := start.Add(time.Since(start) / 2)
+	offset := local.UnixMilli() - serverTime
+	serverTimeOffset.Store(offset)
+
+	drift := time.Duration(offset) * time.Millisecond
+	if drift > clockDriftWarning || drift < -clockDriftWarning {
+		binanceLog.Warn("Local clock is off from Binance's, correcting signed requests", "offset_ms", offset)
+	} else {
+		binanceLog.Debug("Measured Binance server time offset", "offset_ms", offset)
+	}
+	return nil
+}
+
+var serverTimeSyncStart sync.Once
+
+// startServerTimeSync measures the offset to Binance's clock now and every
+// serverTimeSyncInterval until the bot shuts down.
+func startServerTimeSync() {
+	serverTimeSyncStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(serverTimeSyncInterval)
+			defer ticker.Stop()
+			for {
+				if GetGlobalConfig().BinanceAPIURL != "" {
+					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+					if err := syncServerTime(ctx); err != nil {
+						binanceLog.Warn("Failed to sync Binance server time", "error", err)
+					}
+					cancel()
+				}
+				select {
+				case <-ticker.C:
+				case <-shuttingDown:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// binanceTransport sends Binance requests within binanceBudget and binanceTimeout, and signs
+// them again with the server time offset and the configured recvWindow, which the Binance library
+// only takes per request.
+type binanceTransport struct {
+	secret string
+	base   http.RoundTripper
+}
+
+// newBinanceHTTPClient returns the HTTP client for a Binance client with the API secret.
+func newBinanceHTTPClient(secret string) *http.Client {
+	return &http.Client{Transport: &binanceTransport{secret: secret, base: http.DefaultTransport}}
+}
+
+func (t *binanceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := binanceBudget.Acquire(req.Context(), host, priorityOf(req)); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	signed, err := t.sign(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The timeout also covers reading the body, so it ends when the library closes it
+	ctx, cancel := context.WithTimeout(signed.Context(), binanceTimeout())
+	resp, err := t.base.RoundTrip(signed.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	binanceBudget.Record(host, resp)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose is a response body that releases its request's timeout when closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// hostOf returns the host of a Binance API URL, which binanceBudget is kept by.
+func hostOf(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// sign returns a signed request with a new timestamp and signature, or unsigned ones as they are.
+func (t *binanceTransport) sign(req *http.Request) (*http.Request, error) {
+	query := req.URL.Query()
+	if query.Get("signature") == "" || t.secret == "" {
+		return req, nil
+	}
+
+	// The library signs the encoded query followed by the form body
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	query.Del("signature")
+	query.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()-serverTimeOffset.Load(), 10))
+	if window := GetGlobalConfig().BinanceRecvWindow; window > 0 {
+		query.Set("recvWindow", strconv.Itoa(window))
+	}
+	encoded := query.Encode()
+	mac := hmac.New(sha256.New, []byte(t.secret))
+	mac.Write([]byte(encoded))
+	mac.Write(body)
+
+	signed := req.Clone(req.Context())
+	signed.URL.RawQuery = encoded + "&signature=" + hex.EncodeToString(mac.Sum(nil))
+	signed.Body = http.NoBody
+	if len(body) > 0 {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	signed.ContentLength = int64(len(body))
+	return signed, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestPriority decides which Binance requests give way when the request weight budget
+// runs low.
+type requestPriority int
+
+const (
+	priorityLow      requestPriority = iota // Price checks and exchange info, skipped when the budget runs low
+	priorityNormal                          // Account and position queries, held until the next minute
+	priorityCritical                        // Placing and cancelling orders, sent while Binance takes requests
+)
+
+// Request weight Binance allows per minute and IP on each host, and the shares of it lower
+// priority requests may use.
+const (
+	binanceWeightLimit  = 2400
+	lowPriorityShare    = 0.7
+	normalPriorityShare = 0.9
+)
+
+// maxBudgetWait is the longest a request is held for the budget before failing with
+// errBinanceBusy.
+const maxBudgetWait = time.Minute
+
+// errBinanceBusy is returned for requests held back to keep the request weight for orders.
+var errBinanceBusy = errors.New("Binance request limit is nearly reached, try again in a minute")
+
+// lowPriorityPaths are the endpoints of price checks and exchange info, matched by suffix.
+var lowPriorityPaths = []string{
+	"/ping", "/time", "/exchangeInfo", "/depth", "/klines", "/premiumIndex", "/fundingRate",
+	"/ticker/price", "/ticker/bookTicker", "/ticker/24hr", "/openInterest", "/leverageBracket",
+}
+
+// criticalPaths are the endpoints that place, change or cancel orders and positions, or keep
+// the user data stream up, when not read with GET.
+var criticalPaths = []string{
+	"/order", "/batchOrders", "/allOpenOrders", "/leverage", "/marginType", "/positionMargin",
+	"/listenKey",
+}
+
+// priorityOf classifies a Binance request by endpoint.
+func priorityOf(req *http.Request) requestPriority {
+	path := req.URL.Path
+	if req.Method != http.MethodGet {
+		for _, suffix := range criticalPaths {
+			if strings.HasSuffix(path, suffix) {
+				return priorityCritical
+			}
+		}
+	}
+	for _, suffix := range lowPriorityPaths {
+		if strings.HasSuffix(path, suffix) {
+			return priorityLow
+		}
+	}
+	return priorityNormal
+}
+
+// hostBudget is the request weight used on a Binance host in the current minute.
+type hostBudget struct {
+	used         int
+	minute       int64     // Binance minute the weight was reported in
+	blockedUntil time.Time // Set when Binance answers 429 or 418
+}
+
+// RequestBudget tracks the request weight Binance reports in its response headers, per host,
+// and holds back lower priority requests before the limit is reached, so placing and
+// cancelling orders never runs into it and the IP is not banned.
+type RequestBudget struct {
+	mu    sync.Mutex
+	hosts map[string]*hostBudget
+}
+
+// NewRequestBudget creates a new instance of RequestBudget.
+func NewRequestBudget() *RequestBudget {
+	return &RequestBudget{hosts: make(map[string]*hostBudget)}
+}
+
+// binanceBudget is shared by all Binance clients, since the limit is per IP.
+var binanceBudget = NewRequestBudget()
+
+// binanceMinute returns the current minute on Binance's clock, which its weight counts reset on.
+func binanceMinute(now time.Time) int64 {
+	return (now.UnixMilli() - serverTimeOffset.Load()) / time.Minute.Milliseconds()
+}
+
+// state returns a host's budget, forgetting weight from earlier minutes. b.mu must be held.
+func (b *RequestBudget) state(host string, now time.Time) *hostBudget {
+	budget, exists := b.hosts[host]
+	if !exists {
+		budget = &hostBudget{}
+		b.hosts[host] = budget
+	}
+	if minute := binanceMinute(now); budget.minute != minute {
+		budget.used = 0
+		budget.minute = minute
+	}
+	return budget
+}
+
+// Usage returns the weight used on a host in the current minute and the limit.
+func (b *RequestBudget) Usage(host string) (used, limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state(host, time.Now()).used, binanceWeightLimit
+}
+
+// Acquire returns once a request with the priority may be sent to the host, or errBinanceBusy
+// if it is skipped or would be held longer than maxBudgetWait or ctx allows.
+func (b *RequestBudget) Acquire(ctx context.Context, host string, priority requestPriority) error {
+	for {
+		wait := b.wait(host, priority, time.Now())
+		if wait == 0 {
+			return nil
+		}
+		if wait < 0 || wait > maxBudgetWait {
+			return errBinanceBusy
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return errBinanceBusy
+		}
+		binanceLog.Debug("Holding Binance request for the request limit", "host", host, "wait", wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// wait returns how long a request must wait, 0 to send it now, or -1 to skip it.
+func (b *RequestBudget) wait(host string, priority requestPriority, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	budget := b.state(host, now)
+	nextMinute := (budget.minute+1)*time.Minute.Milliseconds() + serverTimeOffset.Load()
+	untilNextMinute := max(time.Duration(nextMinute-now.UnixMilli())*time.Millisecond, time.Millisecond)
+
+	switch {
+	case now.Before(budget.blockedUntil):
+		if priority == priorityLow {
+			return -1
+		}
+		return budget.blockedUntil.Sub(now)
+	case priority == priorityLow && float64(budget.used) >= lowPriorityShare*binanceWeightLimit:
+		return -1
+	case priority == priorityNormal && float64(budget.used) >= normalPriorityShare*binanceWeightLimit:
+		return untilNextMinute
+	case budget.used >= binanceWeightLimit:
+		return untilNextMinute
+	}
+	return 0
+}
+
+// Record updates a host's budget from a Binance response.
+func (b *RequestBudget) Record(host string, resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	budget := b.state(host, now)
+	if used, err := strconv.Atoi(resp.Header.Get("X-Mbx-Used-Weight-1m")); err == nil {
+		budget.used = used
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusTeapot {
+		return
+	}
+	// Binance says how long to back off; going on after a 429 gets the IP banned, which is a 418
+	retryAfter := time.Minute
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	budget.blockedUntil = now.Add(retryAfter)
+	if resp.StatusCode == http.StatusTeapot {
+		reportError(binanceLog, ErrorSourceBinance, "Binance banned the IP for too many requests", nil,
+			"host", host, "retry_after", retryAfter)
+	} else {
+		binanceLog.Warn("Binance request limit reached, backing off", "host", host, "retry_after", retryAfter)
+	}
+}
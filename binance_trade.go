@@ -3,13 +3,16 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/adshao/go-binance/v2/delivery"
 	"github.com/adshao/go-binance/v2/futures"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/gorilla/websocket"
@@ -21,96 +24,135 @@ const (
 )
 
 type BinanceClient struct {
-	Client *futures.Client
-	Bot    *tgbotapi.BotAPI
-	mu     sync.Mutex // Mutex for concurrency control
+	Client   *futures.Client  // USDT-M futures
+	Delivery *delivery.Client // COIN-M futures
+	Bot      *tgbotapi.BotAPI
+	Account  string     // Account the client trades on, keying the stores that follow its orders and positions
+	mu       sync.Mutex // Mutex for concurrency control
+
+	monitorOnce  sync.Once    // Ensures a single user data stream per client
+	monitorState MonitorState // State of the user data stream, for health checks and the dashboard
+
+	closed    chan struct{} // Closed by Close, to stop the client's background work
+	closeOnce sync.Once
 }
 
-// safeGo runs the given function in a new goroutine and logs panics.
+// safeGo runs the given function in a new goroutine and reports panics.
 // Use for launching goroutines that interact with external APIs or clients.
 func (b *BinanceClient) safeGo(name string, fn func()) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("[PANIC][%s]: %v\nStack trace: %s", name, r, debug.Stack())
-				if b.Bot != nil && b != nil {
-					// Try to notify admin about the panic
-					config := GetGlobalConfig()
-					if config.AdminUserID != 0 {
-						b.sendMessageToUser(config.AdminUserID, fmt.Sprintf("⚠️ Panic in goroutine [%s]: %v", name, r))
-					}
-				}
+				reportError(binanceLog, ErrorSourcePanic, "Panic in goroutine", fmt.Errorf("%v", r),
+					"goroutine", name, "stack", string(debug.Stack()))
 			}
 		}()
 		fn()
+	}()
 }
 
+// NewBinanceClient creates the main account's client. Its API key is checked in the background
+// until it works, so a bad key or an unreachable Binance doesn't stop the bot.
 func NewBinanceClient(botInstance *tgbotapi.BotAPI) *BinanceClient {
 	config := GetGlobalConfig()
-	client := futures.NewClient(config.BinanceAPIKey, config.BinanceAPISecret)
+	binanceClient := newBinanceClientWithKeys(botInstance, defaultAccountName, config.BinanceAPIKey, config.BinanceAPISecret)
+	binanceClient.safeGo("superviseAPIKey", binanceClient.superviseAPIKey)
+	return binanceClient
+}
+
+// newBinanceClientWithKeys creates USDT-M and COIN-M clients for an account's API key pair
+// against the configured Binance API URL.
+func newBinanceClientWithKeys(botInstance *tgbotapi.BotAPI, account, apiKey, apiSecret string) *BinanceClient {
+	config := GetGlobalConfig()
+	client := futures.NewClient(apiKey, apiSecret)
 	client.BaseURL = config.BinanceAPIURL
+	client.HTTPClient = newBinanceHTTPClient(apiSecret)
 	client.Debug = true
 
-	binanceClient := &BinanceClient{
-		Client: client,
-		Bot:    botInstance,
-	}
+	deliveryClient := delivery.NewClient(apiKey, apiSecret)
+	deliveryClient.BaseURL = deliveryBaseURL(config.BinanceAPIURL)
+	deliveryClient.HTTPClient = newBinanceHTTPClient(apiSecret)
 
-	if err := binanceClient.testAPIKey(); err != nil {
-		log.Fatalf("Binance API key test failed: %v", err)
-	} else {
-		log.Println("Binance API key is valid and has required permissions.")
+	return &BinanceClient{
+		Client:   client,
+		Delivery: deliveryClient,
+		Bot:      botInstance,
+		Account:  account,
+		closed:   make(chan struct{}),
 	}
+}
 
-	return binanceClient
+// Close stops the client's user data stream monitor and API key checks, for a client replaced
+// by a new configuration. Trades in progress finish.
+func (b *BinanceClient) Close() {
+	b.closeOnce.Do(func() { close(b.closed) })
+}
+
+// stopping reports whether the client was closed or the bot is shutting down.
+func (b *BinanceClient) stopping() bool {
+	select {
+	case <-b.closed:
+		return true
+	case <-shuttingDown:
+		return true
+	default:
+		return false
+	}
 }
 
 // Validate Binance API
 func validateBinanceAPIKeys(apiKey, apiSecret, apiURL string) error {
 	client := futures.NewClient(apiKey, apiSecret)
 	client.BaseURL = apiURL
+	client.HTTPClient = newBinanceHTTPClient(apiSecret)
 	_, err := client.NewGetAccountService().Do(context.Background())
 	if err != nil {
 		return fmt.Errorf("invalid Binance API Key/Secret: %v", err)
 	}
-	log.Printf("[ExecuteTrade] User %d | Symbol: %s | Complete", userID, signal.Symbol)
 	return nil
 }
+
 func (b *BinanceClient) testAPIKey() error {
-	_, err := b.Client.NewGetAccountService().Do(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := b.Client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return fmt.Errorf("API key test failed: %v", err)
 	}
 	return nil
 }
 
-// ExecuteTrade places an order (Market or Limit) and then places TPs/SL as needed.
-func (b *BinanceClient) ExecuteTrade(signal *AlertMessage, settings *UserSettings, userID int64) error {
+// ExecuteTrade places an order (Market, Limit or Stop) and then places TPs/SL as needed. The trade is
+// abandoned if ctx is done before the entry order is placed; once it is, the TPs and SL are
+// placed regardless, so the position isn't left unprotected.
+func (b *BinanceClient) ExecuteTrade(ctx context.Context, signal *AlertMessage, settings *UserSettings, userID int64) error {
+	// Shutting down waits for trades in progress, but doesn't start new ones
+	if !inFlightTrades.Begin() {
+		b.sendMessageToUser(userID, tr(userID, "The bot is restarting, please try again in a minute."))
+		return fmt.Errorf("the bot is shutting down")
+	}
+	defer inFlightTrades.End()
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	log.Printf("[ExecuteTrade] User %d | Starting execution | Symbol: %s | Signal: %#v | Settings: %#v", 
-		userID, signal.Symbol, signal, settings)
-
 	if signal == nil {
 		err := fmt.Errorf("no valid signal provided")
-		b.sendMessageToUser(userID, "Signal not found or invalid.")
-		log.Printf("[ExecuteTrade] User %d | Failed: signal is nil", userID)
+		b.sendMessageToUser(userID, tr(userID, "Signal not found or invalid."))
+		binanceLog.Error("Failed to execute trade: signal is nil", "user_id", userID)
 		return err
 	}
+	binanceLog.Info("Executing trade", "signal_id", signal.SignalID, "symbol", signal.Symbol, "user_id", userID,
+		"signal", signal.SignalType, "trading_mode", settings.TradingMode, "market_type", settings.MarketType,
+		"amount_usdt", settings.AmountUSDT, "leverage", settings.Leverage)
 
 	symbol := signal.Symbol
 	if symbol == "" {
 		err := fmt.Errorf("signal has an empty symbol field")
-		b.sendMessageToUser(userID, "Signal has no symbol specified.")
+		b.sendMessageToUser(userID, tr(userID, "Signal has no symbol specified."))
 		return err
 	}
 
-	side := futures.SideTypeBuy
-	if signal.SignalType == "Sell" {
-		side = futures.SideTypeSell
-	}
-
 	// Recalculate SL and TP based on settings
 	if settings.AutoCalculateTPs {
 		recalcSingleTPAndSL(signal, settings)
@@ -118,98 +160,317 @@ func (b *BinanceClient) ExecuteTrade(signal *AlertMessage, settings *UserSetting
 		recalcManualTPAndSL(signal, settings)
 	}
 
+	// COIN-M trades go through the delivery client with contract-based sizing
+	if settings.MarketType == MarketTypeCoinM {
+		if settings.TradingMode == TradingModeStop {
+			return newTradeGuardError("Stop entries are only available for USDT-M futures.")
+		}
+		return b.executeDeliveryTrade(ctx, signal, settings, userID)
+	}
+
 	// Set margin mode + leverage (e.g., Cross/Isolated, 5x)
-	if err := b.setMarginModeAndLeverage(symbol, settings); err != nil {
+	if err := b.setMarginModeAndLeverage(ctx, symbol, settings, userID); err != nil {
 		return fmt.Errorf("failed to set margin mode or leverage: %v", err)
 	}
 
-	// Calculate quantity from the user's USDT amount and the signal's entry price
-	quantity, err := b.calculateQuantity(symbol, settings.AmountUSDT, signal.EntryPrice)
+	// Place the Market, Limit or Stop entry, sized from the user's USDT amount and the signal's
+	// entry price. Large market entries are split into iceberg slices.
+	side := signalSide(signal)
+	iceberg := useIceberg(settings)
+	var quantity string
+	var err error
+	if iceberg {
+		quantity, err = b.placeIcebergEntry(ctx, signal, settings, userID)
+		if quantity != "" {
+			err = nil // The slices that filled are protected below; the rest were reported
+		}
+	} else {
+		quantity, err = b.PlaceEntry(ctx, signal, settings)
+	}
 	if err != nil {
-		msg := fmt.Sprintf("Failed to calculate quantity for %s: %v", symbol, err)
-		b.sendMessageToUser(userID, msg)
+		var guardErr *TradeGuardError
+		if !errors.As(err, &guardErr) {
+			b.sendMessageToUser(userID, tr(userID, "Failed to execute trade for %s: %v", symbol, err))
+		}
 		return err
 	}
 
-	// Place Market or Limit order
 	if settings.TradingMode == "Market" {
-		err = b.placeMarketOrder(symbol, side, quantity)
-		if err != nil {
-			txt := fmt.Sprintf("Failed to execute trade for %s: %v", symbol, err)
-			b.sendMessageToUser(userID, txt)
-			return err
-		}
-		txt := fmt.Sprintf("Trade executed for %s (%s) at market price", symbol, settings.TradingMode)
+		txt := tr(userID, "Trade executed for %s (%s) at market price", symbol, settings.TradingMode)
 		b.sendMessageToUser(userID, txt)
+		if !iceberg {
+			b.trackPosition(signal, side, userID)
+		}
+		protect := context.WithoutCancel(ctx)
 
 		// If TP/SL is relevant, place OCO orders
-		if (signal.TP1 != 0) || (settings.UseSL && signal.SL > 0) {
-			err = b.placeOCOOrder(symbol, side, quantity, signal, settings)
+		if signalTP(signal, 0) != 0 || (settings.UseSL && signal.SL > 0) {
+			protected := b.replaceEarlierProtection(protect, signal, quantity, userID)
+			err = b.placeOCOOrder(protect, symbol, side, protected, signal, settings)
 			if err != nil {
-				msg := fmt.Sprintf("Failed to place TPs/SL for %s: %v", symbol, err)
+				msg := tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err)
 				b.sendMessageToUser(userID, msg)
 				return err
 			}
-			msg := fmt.Sprintf("TP/SL orders placed for %s.", symbol)
+			msg := tr(userID, "TP/SL orders placed for %s.", symbol)
 			b.sendMessageToUser(userID, msg)
-
-			// Start monitoring the orders
-			b.safeGo("monitorOrdersViaWebSocket", func() {
-				b.monitorOrdersViaWebSocket(userID)
-			})
 		}
-	} else if settings.TradingMode == "Limit" {
-		err = b.placeLimitOrder(symbol, side, quantity, signal.EntryPrice)
-		if err != nil {
-			txt := fmt.Sprintf("Failed to execute trade for %s: %v", symbol, err)
-			b.sendMessageToUser(userID, txt)
-			return err
+
+		// Ladder additional entries against the position if DCA rescue is enabled
+		if settings.DCAEnabled {
+			if err := b.placeDCALadder(protect, symbol, side, quantity, signal, settings, userID); err != nil {
+				b.sendMessageToUser(userID, tr(userID, "Failed to place DCA ladder for %s: %v", symbol, err))
+			}
 		}
-		txt := fmt.Sprintf("Trade executed for %s (%s) at price %.4f", symbol, settings.TradingMode, signal.EntryPrice)
+	} else if settings.TradingMode == TradingModeStop {
+		// TP/SL orders close the position, so they are placed once the entry fills
+		stopEntries.Set(stopEntryKey{Account: b.Account, ClientID: clientOrderID(signal.SignalID, OrderTagEntry)}, &StopEntry{
+			Signal:   *signal,
+			Settings: *settings,
+			Quantity: quantity,
+			UserID:   userID,
+		})
+		txt := tr(userID, "Stop entry placed for %s at %.4f. TP/SL orders are placed once the price triggers it and the entry fills.", symbol, signal.EntryPrice)
 		b.sendMessageToUser(userID, txt)
+		b.trackPosition(signal, side, userID)
+	} else {
+		txt := tr(userID, "Trade executed for %s (%s) at price %.4f", symbol, settings.TradingMode, signal.EntryPrice)
+		b.sendMessageToUser(userID, txt)
+		b.trackPosition(signal, side, userID)
 	}
 
 	return nil
 }
 
-// monitorOrdersViaWebSocket uses WebSocket to monitor order status and sends a notification if they are hit.
-func (b *BinanceClient) monitorOrdersViaWebSocket(userID int64) {
+// trackPosition registers the signal's position for PnL tracking and starts the order monitor.
+// A signal added to an open position in the same direction joins its tracking, keeping the
+// fills so far.
+func (b *BinanceClient) trackPosition(signal *AlertMessage, side futures.SideType, userID int64) {
+	key := b.key(signal.Symbol)
+	positionTracker.Lock()
+	if position, exists := positionTracker.positions[key]; exists && position.Opened && position.Side == side {
+		if !position.hasSignal(signal.SignalID) {
+			position.Added = append(position.Added, signal.SignalID)
+		}
+	} else {
+		positionTracker.positions[key] = &TrackedPosition{
+			SignalID:   signal.SignalID,
+			Symbol:     signal.Symbol,
+			Side:       side,
+			EntryPrice: signal.EntryPrice,
+		}
+	}
+	positionTracker.Unlock()
+	b.startOrderMonitor(userID)
+}
+
+// MonitorState is the state of a client's user data stream monitor.
+type MonitorState struct {
+	mu        sync.Mutex
+	started   bool
+	connected bool
+	lastEvent time.Time
+	failures  int // Failed attempts to connect since the stream was last connected
+}
+
+// setStarted records that the monitor was started.
+func (s *MonitorState) setStarted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = true
+}
+
+// setConnected records whether the monitor's WebSocket is connected.
+func (s *MonitorState) setConnected(connected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = connected
+	if connected {
+		s.failures = 0
+	}
+}
+
+// recordFailure records a failed attempt to connect and returns how many there have been in
+// a row.
+func (s *MonitorState) recordFailure() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = false
+	s.failures++
+	return s.failures
+}
+
+// Failures returns how many attempts to connect have failed since the stream was last connected.
+func (s *MonitorState) Failures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failures
+}
+
+// recordEvent records a message received on the WebSocket.
+func (s *MonitorState) recordEvent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = true
+	s.lastEvent = time.Now()
+}
+
+// Snapshot returns whether the monitor was started and is connected, and when it last
+// received a message.
+func (s *MonitorState) Snapshot() (started, connected bool, lastEvent time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started, s.connected, s.lastEvent
+}
+
+// startOrderMonitor starts the user data stream monitor unless it is already running.
+func (b *BinanceClient) startOrderMonitor(userID int64) {
+	b.monitorOnce.Do(func() {
+		if !orderMonitors.Begin() {
+			return
+		}
+		b.monitorState.setStarted()
+		b.safeGo("superviseOrderMonitor", func() {
+			defer orderMonitors.End()
+			b.superviseOrderMonitor(userID)
+		})
+	})
+}
+
+// userStreamBaseURL returns the user data stream endpoint matching the configured REST API.
+func userStreamBaseURL() string {
+	if strings.Contains(GetGlobalConfig().BinanceAPIURL, "testnet") {
+		return "wss://stream.binancefuture.com/ws/"
+	}
+	return "wss://fstream.binance.com/ws/"
+}
+
+// monitorOrdersViaWebSocket uses WebSocket to monitor order status and position changes.
+// It notifies the user about filled orders and records the trade result when a tracked position closes.
+// It returns nil when the bot shuts down, and an error when the stream can't be opened or drops.
+func (b *BinanceClient) monitorOrdersViaWebSocket(userID int64) error {
 	// Start user data stream to get a listen key
-	listenKey, err := b.Client.NewStartUserStreamService().Do(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	listenKey, err := b.Client.NewStartUserStreamService().Do(ctx)
+	cancel()
 	if err != nil {
-		log.Fatalf("Failed to start user stream: %v", err)
+		return fmt.Errorf("failed to start user stream: %w", err)
 	}
 
-	wsURL := "wss://fstream.binance.com/ws/" + listenKey // Append listenKey to the WebSocket URL
+	wsURL := userStreamBaseURL() + listenKey // Append listenKey to the WebSocket URL
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
-		log.Fatalf("Failed to connect to WebSocket: %v", err)
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
 	defer conn.Close()
+	b.monitorState.setConnected(true)
+
+	// Keep the listen key alive; Binance expires it after 60 minutes. On shutdown, close the
+	// connection to end the read below and let Binance drop the listen key.
+	stopped := make(chan struct{})
+	defer close(stopped)
+	b.safeGo("keepaliveUserStream", func() {
+		keepalive := time.NewTicker(30 * time.Minute)
+		defer keepalive.Stop()
+		for {
+			select {
+			case <-keepalive.C:
+				if err := b.Client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(context.Background()); err != nil {
+					binanceLog.Error("Failed to keep user stream alive", "user_id", userID, "error", err)
+				}
+			case <-shuttingDown:
+				conn.Close()
+				return
+			case <-b.closed:
+				conn.Close()
+				return
+			case <-stopped:
+				return
+			}
+		}
+	})
 
 	// Listen for messages
 	for {
 		_, message, err := conn.ReadMessage()
+		if b.stopping() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := b.Client.NewCloseUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				binanceLog.Warn("Failed to close user stream", "user_id", userID, "error", err)
+			}
+			binanceLog.Info("Stopped user stream monitor", "user_id", userID)
+			return nil
+		}
 		if err != nil {
-			log.Printf("Error reading WebSocket message: %v", err)
-			continue
+			// The connection is done for after a read error, so reconnect
+			return fmt.Errorf("user stream disconnected: %w", err)
 		}
+		b.monitorState.recordEvent()
 
-		var event map[string]interface{}
+		var event futures.WsUserDataEvent
 		if err := json.Unmarshal(message, &event); err != nil {
-			log.Printf("Error unmarshalling WebSocket message: %v", err)
+			binanceLog.Warn("Failed to decode user stream message", "user_id", userID, "error", err)
 			continue
 		}
 
-		// Check for order execution event
-		if eventType, ok := event["e"].(string); ok && eventType == "ORDER_TRADE_UPDATE" {
-			order := event["o"].(map[string]interface{})
-			orderStatus := order["X"].(string)
-			if orderStatus == "FILLED" {
-				symbol := order["s"].(string)
-				clientOrderID := order["c"].(string)
-				msg := fmt.Sprintf("Order %s for %s has been filled.", clientOrderID, symbol)
+		switch event.Event {
+		case futures.UserDataEventTypeListenKeyExpired:
+			return fmt.Errorf("user stream listen key expired")
+		case futures.UserDataEventTypeOrderTradeUpdate:
+			order := event.OrderTradeUpdate
+			b.recordOrderFill(order)
+			recordOrderUpdate(order)
+			b.handleStopEntryUpdate(order, userID)
+			if order.Status == futures.OrderStatusTypeFilled {
+				recordOrderStatus(order.ClientOrderID)
+				price, _ := strconv.ParseFloat(order.AveragePrice, 64)
+				quantity, _ := strconv.ParseFloat(order.AccumulatedFilledQty, 64)
+				msg, ok := formatOrderFill(userID, b.key(order.Symbol), order.ClientOrderID, price, quantity)
+				if !ok {
+					msg = tr(userID, "Order %s for %s has been filled.", order.ClientOrderID, order.Symbol)
+				}
 				b.sendMessageToUser(userID, msg)
+				postToDiscord(msg)
+				fireOrderFill(ExchangeBinance, order.Symbol, order.ClientOrderID, price, quantity)
+				// Moving the TPs or cancelling linked orders waits on the REST API, which
+				// mustn't hold up the stream
+				if isDCAOrder(order.ClientOrderID) {
+					b.safeGo("handleDCAFill", func() {
+						b.handleDCAFill(order, userID)
+					})
+				} else {
+					b.safeGo("handleOCOFill", func() {
+						b.handleOCOFill(order.Symbol, order.ClientOrderID, userID)
+					})
+				}
+			}
+		case futures.UserDataEventTypeMarginCall:
+			for _, position := range event.MarginCallPositions {
+				maintMargin, _ := strconv.ParseFloat(position.MaintenanceMarginRequired, 64)
+				b.safeGo("checkIsolatedMargin", func() {
+					b.checkIsolatedMargin(position, maintMargin, userID)
+				})
+			}
+		case futures.UserDataEventTypeAccountUpdate:
+			for _, position := range event.AccountUpdate.Positions {
+				b.safeGo("checkIsolatedMargin", func() {
+					b.checkIsolatedMargin(position, 0, userID)
+				})
+				if !b.recordPositionUpdate(position) {
+					continue
+				}
+				symbol := position.Symbol
+				time.AfterFunc(positionSettleDelay, func() {
+					b.cancelDCALadder(symbol, userID)
+					closed, err := b.finalizePosition(symbol)
+					if err != nil {
+						reportError(binanceLog, ErrorSourceDatabase, "Failed to record closed position", err, "symbol", symbol, "user_id", userID)
+					}
+					if closed != nil {
+						b.sendMessageToUser(userID, formatClosedPosition(userID, closed))
+					}
+				})
 			}
 		}
 	}
@@ -225,18 +486,16 @@ func recalcSingleTPAndSL(signal *AlertMessage, settings *UserSettings) {
 	tpPct := settings.AutoTPPercentage / 100.0
 	slPct := settings.AutoSLPercentage / 100.0
 
-	// Zero out additional TPs since we only use TP1 if auto-calc is toggled
-	signal.TP2, signal.TP3 = 0, 0
-
+	// Drop additional TPs since we only use TP1 if auto-calc is toggled
 	if signal.SignalType == "Sell" {
-		signal.TP1 = entry * (1 - tpPct)
+		signal.TPs = []float64{entry * (1 - tpPct)}
 		if settings.UseSL {
 			signal.SL = entry * (1 + slPct)
 		} else {
 			signal.SL = 0
 		}
 	} else {
-		signal.TP1 = entry * (1 + tpPct)
+		signal.TPs = []float64{entry * (1 + tpPct)}
 		if settings.UseSL {
 			signal.SL = entry * (1 - slPct)
 		} else {
@@ -252,24 +511,16 @@ func recalcManualTPAndSL(signal *AlertMessage, settings *UserSettings) {
 		return
 	}
 
-	tp1Pct := settings.TP1Percentage / 100.0
-	tp2Pct := settings.TP2Percentage / 100.0
-	tp3Pct := settings.TP3Percentage / 100.0
 	slPct := settings.ManualSLPercentage / 100.0
 
+	signal.TPs = tpPrices(signal.SignalType, entry, settings.TPLevels)
 	if signal.SignalType == "Sell" {
-		signal.TP1 = entry * (1 - tp1Pct)
-		signal.TP2 = entry * (1 - tp2Pct)
-		signal.TP3 = entry * (1 - tp3Pct)
 		if settings.UseSL {
 			signal.SL = entry * (1 + slPct)
 		} else {
 			signal.SL = 0
 		}
 	} else {
-		signal.TP1 = entry * (1 + tp1Pct)
-		signal.TP2 = entry * (1 + tp2Pct)
-		signal.TP3 = entry * (1 + tp3Pct)
 		if settings.UseSL {
 			signal.SL = entry * (1 - slPct)
 		} else {
@@ -278,8 +529,24 @@ func recalcManualTPAndSL(signal *AlertMessage, settings *UserSettings) {
 	}
 }
 
-// setMarginModeAndLeverage configures the margin mode and leverage on Binance Futures.
-func (b *BinanceClient) setMarginModeAndLeverage(symbol string, settings *UserSettings) error {
+// setMarginModeAndLeverage configures the margin mode and leverage on Binance Futures, telling
+// the user if the leverage had to be lowered.
+func (b *BinanceClient) setMarginModeAndLeverage(ctx context.Context, symbol string, settings *UserSettings, userID int64) error {
+	leverage, err := b.SetLeverage(ctx, symbol, settings)
+	if err != nil {
+		return err
+	}
+	if settings.Leverage > leverage {
+		b.sendMessageToUser(userID, tr(userID,
+			"Leverage %dx exceeds the maximum of %dx allowed for a %.2f USDT position on %s. Using %dx instead.",
+			settings.Leverage, leverage, settings.AmountUSDT, symbol, leverage))
+	}
+	return nil
+}
+
+// SetLeverage sets the margin type and leverage on Binance Futures, clamping the leverage to the
+// maximum of the position's notional bracket.
+func (b *BinanceClient) SetLeverage(ctx context.Context, symbol string, settings *UserSettings) (int, error) {
 	var marginType futures.MarginType
 	if settings.MarginMode == "Isolated" {
 		marginType = MarginTypeIsolated
@@ -291,9 +558,10 @@ func (b *BinanceClient) setMarginModeAndLeverage(symbol string, settings *UserSe
 	err := b.Client.NewChangeMarginTypeService().
 		Symbol(symbol).
 		MarginType(marginType).
-		Do(context.Background())
+		Do(ctx)
 	if err != nil {
-		log.Printf("Failed to set margin mode for %s: %v", symbol, err)
+		// Binance also refuses to set the margin mode a symbol already has
+		binanceLog.Warn("Failed to set margin mode", "symbol", symbol, "margin_mode", settings.MarginMode, "error", err)
 	}
 
 	// Then set leverage
@@ -301,19 +569,115 @@ func (b *BinanceClient) setMarginModeAndLeverage(symbol string, settings *UserSe
 	if leverage <= 0 {
 		leverage = 5
 	}
+
+	// Clamp to the maximum leverage Binance allows for this position's notional
+	maxLeverage, err := b.maxLeverageForNotional(ctx, symbol, settings.AmountUSDT)
+	if err != nil {
+		binanceLog.Warn("Failed to get leverage brackets", "symbol", symbol, "error", err)
+	} else if leverage > maxLeverage {
+		leverage = maxLeverage
+	}
+
 	_, err = b.Client.NewChangeLeverageService().
 		Symbol(symbol).
 		Leverage(leverage).
-		Do(context.Background())
+		Do(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to set leverage: %v", err)
+		return 0, fmt.Errorf("failed to set leverage: %v", err)
 	}
-	return nil
+	return leverage, nil
+}
+
+// maxLeverageForNotional returns the highest initial leverage of the bracket containing the notional.
+func (b *BinanceClient) maxLeverageForNotional(ctx context.Context, symbol string, notional float64) (int, error) {
+	bracket, err := b.leverageBracketForNotional(ctx, symbol, notional)
+	if err != nil {
+		return 0, err
+	}
+	return bracket.InitialLeverage, nil
+}
+
+// leverageBracketForNotional returns the leverage bracket containing the notional,
+// or the last bracket if the notional exceeds every cap.
+func (b *BinanceClient) leverageBracketForNotional(ctx context.Context, symbol string, notional float64) (*futures.Bracket, error) {
+	brackets, err := leverageBrackets.Get(b.Client.BaseURL+"|"+b.Account+"|"+symbol, func() ([]*futures.LeverageBracket, error) {
+		return b.Client.NewGetLeverageBracketService().Symbol(symbol).Do(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(brackets) == 0 || len(brackets[0].Brackets) == 0 {
+		return nil, fmt.Errorf("no leverage brackets for symbol %s", symbol)
+	}
+
+	// The brackets are cached, so return a copy
+	for _, bracket := range brackets[0].Brackets {
+		if notional >= bracket.NotionalFloor && notional < bracket.NotionalCap {
+			return &bracket, nil
+		}
+	}
+	last := brackets[0].Brackets[len(brackets[0].Brackets)-1]
+	return &last, nil
+}
+
+// estimateLiquidationPrice estimates the liquidation price of an isolated one-way position,
+// using the maintenance margin ratio and amount (cum) of its leverage bracket. For cross margin
+// the real liquidation price is further away, since the whole wallet balance backs the position.
+func estimateLiquidationPrice(side futures.SideType, entryPrice, quantity float64, leverage int, bracket *futures.Bracket) float64 {
+	if entryPrice <= 0 || quantity <= 0 || leverage <= 0 {
+		return 0
+	}
+	direction := 1.0
+	if side == futures.SideTypeSell {
+		direction = -1.0
+	}
+	margin := quantity * entryPrice / float64(leverage)
+	price := (margin + bracket.Cum - direction*quantity*entryPrice) /
+		(quantity*bracket.MaintMarginRatio - direction*quantity)
+	if price < 0 {
+		return 0
+	}
+	return price
+}
+
+// LiquidationInfo holds what is needed to estimate a signal's liquidation price without
+// further API calls, so the estimate can follow edits of the entry price.
+type LiquidationInfo struct {
+	MarginMode string
+	Leverage   int // Leverage after clamping to the bracket maximum
+	AmountUSDT float64
+	Bracket    futures.Bracket
+}
+
+// Price estimates the liquidation price for an entry on the given side.
+func (l *LiquidationInfo) Price(side futures.SideType, entryPrice float64) float64 {
+	if entryPrice <= 0 {
+		return 0
+	}
+	return estimateLiquidationPrice(side, entryPrice, l.AmountUSDT/entryPrice, l.Leverage, &l.Bracket)
+}
+
+// liquidationInfo looks up the leverage bracket for the trade the settings would place.
+func (b *BinanceClient) liquidationInfo(ctx context.Context, symbol string, settings *UserSettings) (*LiquidationInfo, error) {
+	bracket, err := b.leverageBracketForNotional(ctx, symbol, settings.AmountUSDT)
+	if err != nil {
+		return nil, err
+	}
+	leverage := settings.Leverage
+	if leverage > bracket.InitialLeverage {
+		leverage = bracket.InitialLeverage
+	}
+	return &LiquidationInfo{
+		MarginMode: settings.MarginMode,
+		Leverage:   leverage,
+		AmountUSDT: settings.AmountUSDT,
+		Bracket:    *bracket,
+	}, nil
 }
 
 // calculateQuantity computes an order quantity based on the user's USDT amount and the entry price.
-func (b *BinanceClient) calculateQuantity(symbol string, amountUSDT, entryPrice float64) (string, error) {
-	sInfo, err := b.getSymbolInfo(symbol)
+func (b *BinanceClient) calculateQuantity(ctx context.Context, symbol string, amountUSDT, entryPrice float64) (string, error) {
+	sInfo, err := b.getSymbolInfo(ctx, symbol)
 	if err != nil {
 		return "", err
 	}
@@ -325,13 +689,9 @@ func (b *BinanceClient) calculateQuantity(symbol string, amountUSDT, entryPrice
 	quantity := amountUSDT / entryPrice
 
 	// Retrieve the "LOT_SIZE" stepSize from the symbol info
-	stepStr, err := getFilterValue(sInfo.Filters, "LOT_SIZE", "stepSize")
-	if err != nil {
-		return "", fmt.Errorf("failed to get step size: %v", err)
-	}
-	stepSize, err := strconv.ParseFloat(stepStr, 64)
+	stepSize, err := lotStepSize(sInfo)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse step size: %v", err)
+		return "", err
 	}
 
 	// Round down based on the step size
@@ -339,9 +699,12 @@ func (b *BinanceClient) calculateQuantity(symbol string, amountUSDT, entryPrice
 	return formatDecimal(quantity, stepSize), nil
 }
 
-// getSymbolInfo fetches symbol details (filters, etc.) from Binance exchange info.
-func (b *BinanceClient) getSymbolInfo(symbol string) (*futures.Symbol, error) {
-	info, err := b.Client.NewExchangeInfoService().Do(context.Background())
+// getSymbolInfo fetches symbol details (filters, etc.) from Binance exchange info, cached for
+// symbolInfoTTL.
+func (b *BinanceClient) getSymbolInfo(ctx context.Context, symbol string) (*futures.Symbol, error) {
+	info, err := futuresExchangeInfo.Get(b.Client.BaseURL, func() (*futures.ExchangeInfo, error) {
+		return b.Client.NewExchangeInfoService().Do(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -354,8 +717,8 @@ func (b *BinanceClient) getSymbolInfo(symbol string) (*futures.Symbol, error) {
 }
 
 // getCurrentPrice retrieves the current price for the given symbol.
-func (b *BinanceClient) getCurrentPrice(symbol string) (float64, error) {
-	stats, err := b.Client.NewListPriceChangeStatsService().Symbol(symbol).Do(context.Background())
+func (b *BinanceClient) getCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	stats, err := b.Client.NewListPriceChangeStatsService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -369,20 +732,142 @@ func (b *BinanceClient) getCurrentPrice(symbol string) (float64, error) {
 	return cp, nil
 }
 
+// getFundingRates returns the last settled and the predicted next funding rate for the symbol.
+func (b *BinanceClient) getFundingRates(ctx context.Context, symbol string) (current, predicted float64, err error) {
+	history, err := b.Client.NewFundingRateService().Symbol(symbol).Limit(1).Do(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(history) > 0 {
+		current, err = strconv.ParseFloat(history[0].FundingRate, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse funding rate: %v", err)
+		}
+	}
+
+	index, err := b.Client.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(index) == 0 {
+		return 0, 0, fmt.Errorf("no premium index data for symbol %s", symbol)
+	}
+	predicted, err = strconv.ParseFloat(index[0].LastFundingRate, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse predicted funding rate: %v", err)
+	}
+	return current, predicted, nil
+}
+
+// fundingWarning returns a warning, in the chat's language, when the funding rate works against
+// the signal's direction by more than the user's threshold, or an empty string if funding is acceptable.
+func (b *BinanceClient) fundingWarning(ctx context.Context, chatID int64, signal *AlertMessage, settings *UserSettings) (string, error) {
+	if settings.FundingRateThreshold <= 0 || signal.Symbol == "" {
+		return "", nil
+	}
+
+	current, predicted, err := b.getFundingRates(ctx, signal.Symbol)
+	if err != nil {
+		return "", err
+	}
+
+	// Positive funding is paid by longs, negative funding by shorts
+	direction := 1.0
+	if signal.SignalType == "Sell" {
+		direction = -1.0
+	}
+	worst := math.Max(current*direction, predicted*direction) * 100
+	if worst <= settings.FundingRateThreshold {
+		return "", nil
+	}
+
+	return tr(chatID, "Funding rate is against this position: current %.4f%%, predicted %.4f%% (threshold %.4f%%)",
+		current*100, predicted*100, settings.FundingRateThreshold), nil
+}
+
+// Reasons a market order is aborted when the book or mark price moved too far from the entry.
+const (
+	bookSlippageReason = "Market order for %s aborted: Book price %s moved %.2f%% from entry %s (max slippage %.2f%%)."
+	markSlippageReason = "Market order for %s aborted: Mark price %s moved %.2f%% from entry %s (max slippage %.2f%%)."
+)
+
+// checkSlippage compares the executable book price and the mark price against the signal entry
+// and returns a TradeGuardError if either moved by more than maxSlippage (a fraction).
+func (b *BinanceClient) checkSlippage(ctx context.Context, symbol string, side futures.SideType, entryPrice, maxSlippage float64) error {
+	if maxSlippage <= 0 || entryPrice <= 0 {
+		return nil
+	}
+
+	tickers, err := b.Client.NewListBookTickersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get book ticker: %v", err)
+	}
+	if len(tickers) == 0 {
+		return fmt.Errorf("no book ticker data for symbol %s", symbol)
+	}
+
+	// A buy fills at the ask, a sell at the bid
+	priceStr := tickers[0].AskPrice
+	if side == futures.SideTypeSell {
+		priceStr = tickers[0].BidPrice
+	}
+	bookPrice, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse book price: %v", err)
+	}
+
+	markPrice, err := b.getMarkPrice(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range []struct {
+		reason string
+		price  float64
+	}{{bookSlippageReason, bookPrice}, {markSlippageReason, markPrice}} {
+		slippage := math.Abs(p.price-entryPrice) / entryPrice
+		if slippage > maxSlippage {
+			return newTradeGuardError(p.reason, symbol, formatFloat(p.price), slippage*100, formatFloat(entryPrice), maxSlippage*100)
+		}
+	}
+	return nil
+}
+
+// getMarkPrice retrieves the current mark price for the given symbol.
+func (b *BinanceClient) getMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	index, err := b.Client.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(index) == 0 {
+		return 0, fmt.Errorf("no mark price data for symbol %s", symbol)
+	}
+	mp, err := strconv.ParseFloat(index[0].MarkPrice, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse mark price: %v", err)
+	}
+	return mp, nil
+}
+
 // placeMarketOrder submits a Market order to Binance Futures.
-func (b *BinanceClient) placeMarketOrder(symbol string, side futures.SideType, quantity string) error {
-	_, err := b.Client.NewCreateOrderService().
+func (b *BinanceClient) placeMarketOrder(ctx context.Context, symbol string, side futures.SideType, quantity, clientID string) error {
+	res, err := b.Client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(side).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantity).
-		Do(context.Background())
+		NewClientOrderID(clientID).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=MARKET quantity=%s", symbol, side, quantity), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
 	return err
 }
 
 // placeLimitOrder submits a Limit (GTC) order to Binance Futures at user's specified price.
-func (b *BinanceClient) placeLimitOrder(symbol string, side futures.SideType, quantity string, price float64) error {
-	info, err := b.getSymbolInfo(symbol)
+func (b *BinanceClient) placeLimitOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, price float64, clientID string) error {
+	info, err := b.getSymbolInfo(ctx, symbol)
 	if err != nil {
 		return err
 	}
@@ -391,80 +876,201 @@ func (b *BinanceClient) placeLimitOrder(symbol string, side futures.SideType, qu
 		return err
 	}
 
-	_, err = b.Client.NewCreateOrderService().
+	res, err := b.Client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(side).
 		Type(futures.OrderTypeLimit).
 		TimeInForce(futures.TimeInForceTypeGTC).
 		Quantity(quantity).
 		Price(pStr).
-		Do(context.Background())
+		NewClientOrderID(clientID).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=LIMIT quantity=%s price=%s", symbol, side, quantity, pStr), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
+
+	return err
+}
 
+// placeStopEntryOrder submits a stop entry that Binance triggers when the price reaches
+// stopPrice, above the current price for a buy or below it for a sell. With a limitPrice it is
+// a Stop (GTC) order that rests at that price once triggered, otherwise a Stop-Market order.
+func (b *BinanceClient) placeStopEntryOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, stopPrice, limitPrice float64, clientID string, workingType futures.WorkingType) error {
+	info, err := b.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	sStr, err := b.formatPrice(info, stopPrice)
+	if err != nil {
+		return err
+	}
+
+	order := b.Client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Quantity(quantity).
+		StopPrice(sStr).
+		WorkingType(workingType).
+		PriceProtect(true).
+		NewClientOrderID(clientID)
+	desc := fmt.Sprintf("symbol=%s side=%s type=STOP_MARKET quantity=%s stop_price=%s working_type=%s", symbol, side, quantity, sStr, workingType)
+	if limitPrice > 0 {
+		pStr, err := b.formatPrice(info, limitPrice)
+		if err != nil {
+			return err
+		}
+		order = order.Type(futures.OrderTypeStop).TimeInForce(futures.TimeInForceTypeGTC).Price(pStr)
+		desc = fmt.Sprintf("symbol=%s side=%s type=STOP quantity=%s stop_price=%s price=%s working_type=%s", symbol, side, quantity, sStr, pStr, workingType)
+	} else {
+		order = order.Type(futures.OrderTypeStopMarket)
+	}
+	res, err := order.Do(ctx)
+	auditOrder(AuditOrder, clientID, desc, res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
 	return err
 }
 
-// placeOCOOrder places the relevant Take-Profit and Stop-Loss orders.
-func (b *BinanceClient) placeOCOOrder(symbol string, side futures.SideType, quantity string, signal *AlertMessage, settings *UserSettings) error {
+// placeOCOOrder places the relevant Take-Profit and Stop-Loss orders and links them
+// so that a fill on either side cancels the other (see handleOCOFill).
+func (b *BinanceClient) placeOCOOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, signal *AlertMessage, settings *UserSettings) error {
 	tpSide := invertSide(side)
 	slSide := invertSide(side)
 
-	// If auto-calc was used, only TP1 is relevant
-	if settings.AutoCalculateTPs {
-		if signal.TP1 > 0 {
-			if err := b.placeTPOrder(symbol, tpSide, quantity, signal.TP1); err != nil {
-				return err
-			}
+	// If auto-calc was used, only TP1 is relevant; otherwise place one TP per level, each
+	// closing its level's share and the last one the rest of the position
+	tps := placedTPs(signal.TPs, settings)
+	partials, err := splitTPQuantities(ctx, b, symbol, quantity, tps, settings)
+	if err != nil {
+		return err
+	}
+	for i, tpPrice := range tps {
+		if tpPrice <= 0 {
+			continue
 		}
-	} else {
-		// Otherwise place up to three TPs
-		tps := []float64{signal.TP1, signal.TP2, signal.TP3}
-		for _, tpPrice := range tps {
-			if tpPrice <= 0 {
-				continue
-			}
-			if err := b.placeTPOrder(symbol, tpSide, quantity, tpPrice); err != nil {
+		clientID := clientOrderID(signal.SignalID, tpOrderTag(i))
+		if i < len(partials) && partials[i] != "" {
+			if err := b.placePartialTPOrder(ctx, symbol, tpSide, partials[i], tpPrice, clientID, workingType(settings)); err != nil {
 				return err
 			}
+			ocoGroups.AddPartial(b.key(symbol), signal.SignalID, clientID)
+			continue
+		}
+		if err := b.placeTPOrder(ctx, symbol, tpSide, quantity, tpPrice, clientID, workingType(settings)); err != nil {
+			return err
 		}
+		ocoGroups.Add(b.key(symbol), signal.SignalID, clientID, false)
+	}
+	// With Trail After TP2, a trailing stop replaces the TPs after TP2 once it fills (see
+	// handlePartialTPFill)
+	if partials != nil && useTrailingTP(settings, tps) {
+		trailingTPs.Set(b.key(symbol), &TrailingTP{Signal: *signal, Settings: *settings})
+	} else {
+		trailingTPs.Delete(b.key(symbol))
 	}
 
 	if settings.UseSL && signal.SL > 0 {
-		if err := b.placeSLOrder(symbol, slSide, quantity, signal.SL); err != nil {
+		clientID := clientOrderID(signal.SignalID, OrderTagSL)
+		if err := b.placeSLOrder(ctx, symbol, slSide, quantity, signal.SL, clientID, workingType(settings)); err != nil {
 			return err
 		}
+		ocoGroups.Add(b.key(symbol), signal.SignalID, clientID, true)
 	}
 	return nil
 }
 
-// placeTPOrder places a Take-Profit-Market order for a given TP price.
-func (b *BinanceClient) placeTPOrder(symbol string, side futures.SideType, quantity string, tpPrice float64) error {
-	_, err := b.Client.NewCreateOrderService().
+// placeTPOrder places a Take-Profit-Market order for a given TP price that closes the rest of
+// the position.
+func (b *BinanceClient) placeTPOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, tpPrice float64, clientID string, workingType futures.WorkingType) error {
+	stopPrice, err := b.formatStopPrice(ctx, symbol, tpPrice)
+	if err != nil {
+		return err
+	}
+	res, err := b.Client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(side).
 		Type(futures.OrderTypeTakeProfitMarket).
-		StopPrice(fmt.Sprintf("%.4f", tpPrice)).
+		StopPrice(stopPrice).
 		ClosePosition(true).
-		WorkingType(futures.WorkingTypeMarkPrice).
+		WorkingType(workingType).
 		PriceProtect(true).
-		Do(context.Background())
+		NewClientOrderID(clientID).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=TAKE_PROFIT_MARKET stop_price=%s working_type=%s close_position=true", symbol, side, stopPrice, workingType), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
 
 	return err
 }
 
+// placePartialTPOrder places a reduce-only Take-Profit-Market order that closes quantity of the
+// position at the TP price, leaving the rest open.
+func (b *BinanceClient) placePartialTPOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, tpPrice float64, clientID string, workingType futures.WorkingType) error {
+	stopPrice, err := b.formatStopPrice(ctx, symbol, tpPrice)
+	if err != nil {
+		return err
+	}
+	res, err := b.Client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(futures.OrderTypeTakeProfitMarket).
+		Quantity(quantity).
+		StopPrice(stopPrice).
+		ReduceOnly(true).
+		WorkingType(workingType).
+		PriceProtect(true).
+		NewClientOrderID(clientID).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=TAKE_PROFIT_MARKET quantity=%s stop_price=%s working_type=%s reduce_only=true", symbol, side, quantity, stopPrice, workingType), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
+	return err
+}
+
 // placeSLOrder places a Stop-Loss-Market order at the given price.
-func (b *BinanceClient) placeSLOrder(symbol string, side futures.SideType, quantity string, slPrice float64) error {
-	_, err := b.Client.NewCreateOrderService().
+func (b *BinanceClient) placeSLOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, slPrice float64, clientID string, workingType futures.WorkingType) error {
+	stopPrice, err := b.formatStopPrice(ctx, symbol, slPrice)
+	if err != nil {
+		return err
+	}
+	res, err := b.Client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(side).
 		Type(futures.OrderTypeStopMarket).
-		StopPrice(fmt.Sprintf("%.4f", slPrice)).
+		StopPrice(stopPrice).
 		ClosePosition(true).
-		WorkingType(futures.WorkingTypeMarkPrice).
+		WorkingType(workingType).
 		PriceProtect(true).
-		Do(context.Background())
+		NewClientOrderID(clientID).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=STOP_MARKET stop_price=%s working_type=%s close_position=true", symbol, side, stopPrice, workingType), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
 	return err
 }
 
+// workingType returns the price TP/SL orders trigger on, defaulting to the mark price.
+func workingType(settings *UserSettings) futures.WorkingType {
+	if settings.WorkingType == string(futures.WorkingTypeContractPrice) {
+		return futures.WorkingTypeContractPrice
+	}
+	return futures.WorkingTypeMarkPrice
+}
+
+// formatStopPrice rounds a TP/SL trigger price to the symbol's tick size.
+func (b *BinanceClient) formatStopPrice(ctx context.Context, symbol string, price float64) (string, error) {
+	symbolInfo, err := b.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return "", err
+	}
+	return b.formatPrice(symbolInfo, price)
+}
+
 // formatPrice rounds the price based on the symbol's PRICE_FILTER tickSize.
 func (b *BinanceClient) formatPrice(symbolInfo *futures.Symbol, price float64) (string, error) {
 	tsStr, err := getFilterValue(symbolInfo.Filters, "PRICE_FILTER", "tickSize")
@@ -508,6 +1114,94 @@ func formatDecimal(value, step float64) string {
 	return fmt.Sprintf("%."+strconv.Itoa(decimalPlaces)+"f", value)
 }
 
+// Order tags identify the role of an order placed for a signal.
+const (
+	OrderTagEntry = "entry"
+	OrderTagTP    = "tp" // Followed by the TP number, e.g. "tp1"
+	OrderTagSL    = "sl"
+)
+
+// tpOrderTag returns the order tag for the TP at the zero-based level.
+func tpOrderTag(level int) string {
+	return fmt.Sprintf("%s%d", OrderTagTP, level+1)
+}
+
+// isTPOrderTag reports whether the tag belongs to a TP order.
+func isTPOrderTag(tag string) bool {
+	_, ok := tpField(strings.ToUpper(tag))
+	return ok
+}
+
+// maxClientOrderIDLength is Binance's limit for newClientOrderId.
+const maxClientOrderIDLength = 36
+
+// orderIDPrefix returns the configured client order ID prefix, limited to characters Binance accepts.
+func orderIDPrefix() string {
+	prefix := sanitizeSignalID(GetGlobalConfig().OrderIDPrefix)
+	if prefix == "" {
+		prefix = defaultOrderIDPrefix
+	}
+	if len(prefix) > 8 {
+		prefix = prefix[:8]
+	}
+	return prefix
+}
+
+// clientOrderID builds a deterministic client order ID of the form <prefix>-<signalID>-<tag>,
+// so orders on Binance can be traced back to their signal. Characters other than letters,
+// digits and underscores are dropped from the signal ID and long ones are truncated, so compare
+// order IDs with isSignalOrder and get their signal with orderSignalID.
+func clientOrderID(signalID, tag string) string {
+	prefix := orderIDPrefix()
+	maxIDLen := maxClientOrderIDLength - len(prefix) - len(tag) - 2
+	short := sanitizeSignalID(signalID)
+	if len(short) > maxIDLen {
+		short = short[:maxIDLen]
+	}
+	orderSignalIDs.Store(short, signalID)
+	return fmt.Sprintf("%s-%s-%s", prefix, short, tag)
+}
+
+// parseClientOrderID extracts the signal ID as it appears in a client order ID built by
+// clientOrderID, and the tag. It returns false for orders that were not placed by this bot.
+func parseClientOrderID(id string) (signalID, tag string, ok bool) {
+	parts := strings.Split(id, "-")
+	if len(parts) != 3 || parts[0] != orderIDPrefix() {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// isSignalOrder reports whether a client order ID was built by clientOrderID for the signal.
+func isSignalOrder(clientID, signalID string) bool {
+	_, tag, ok := parseClientOrderID(clientID)
+	return ok && clientID == clientOrderID(signalID, tag)
+}
+
+// orderSignalIDs maps signal IDs as they appear in client order IDs to the signal IDs they were
+// built from, for the orders placed since the bot started.
+var orderSignalIDs sync.Map
+
+// orderSignalID returns the ID of the signal a client order ID built by clientOrderID belongs
+// to, and the order's tag. Orders placed before the bot started are matched to the stored
+// signals. It returns false for orders that were not placed by this bot.
+func orderSignalID(clientID string) (signalID, tag string, ok bool) {
+	short, tag, ok := parseClientOrderID(clientID)
+	if !ok {
+		return "", "", false
+	}
+	if signalID, exists := orderSignalIDs.Load(short); exists {
+		return signalID.(string), tag, true
+	}
+	signalID, err := findOrderSignalID(clientID, short, tag)
+	if err != nil {
+		binanceLog.Warn("Failed to find the signal of an order", "client_order_id", clientID, "error", err)
+		return short, tag, true
+	}
+	orderSignalIDs.Store(short, signalID)
+	return signalID, tag, true
+}
+
 // invertSide flips Buy order to Sell (for TPs) or vice versa.
 func invertSide(side futures.SideType) futures.SideType {
 	if side == futures.SideTypeBuy {
@@ -520,6 +1214,6 @@ func invertSide(side futures.SideType) futures.SideType {
 func (b *BinanceClient) sendMessageToUser(userID int64, message string) {
 	msg := tgbotapi.NewMessage(userID, message)
 	if _, err := b.Bot.Send(msg); err != nil {
-		log.Printf("Failed to send message to user %d: %v", userID, err)
+		telegramLog.Error("Failed to send message to user", "user_id", userID, "error", err)
 	}
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Dimensions performance reports break trades down by.
+const (
+	BreakdownSymbol    = "symbol"
+	BreakdownSide      = "side"
+	BreakdownTimeframe = "timeframe"
+)
+
+// Orders of a breakdown: most profitable or least profitable groups first.
+const (
+	BreakdownBest  = "best"
+	BreakdownWorst = "worst"
+)
+
+// maxBreakdownRows bounds the groups a breakdown message lists.
+const maxBreakdownRows = 15
+
+// PerformanceGroup is the performance of the trades of a symbol, side, timeframe or strategy.
+type PerformanceGroup struct {
+	Name string
+	PerformanceData
+}
+
+// WinRate returns the percentage of the group's trades that were profitable.
+func (g PerformanceGroup) WinRate() float64 {
+	return g.WinLossRatio * 100
+}
+
+// groupPerformance splits trades by key and calculates each group's performance, in name order
+// with the empty key last.
+func groupPerformance(trades []Trade, key func(Trade) string) []PerformanceGroup {
+	grouped := make(map[string][]Trade)
+	for _, trade := range trades {
+		grouped[key(trade)] = append(grouped[key(trade)], trade)
+	}
+	names := make([]string, 0, len(grouped))
+	for name := range grouped {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "" || names[j] == "" {
+			return names[j] == ""
+		}
+		return names[i] < names[j]
+	})
+
+	groups := make([]PerformanceGroup, len(names))
+	for i, name := range names {
+		groups[i] = PerformanceGroup{Name: name, PerformanceData: calculatePerformanceMetrics(grouped[name])}
+	}
+	return groups
+}
+
+// rankPerformanceGroups orders groups by net profit, the best or the worst first.
+func rankPerformanceGroups(groups []PerformanceGroup, order string) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if order == BreakdownWorst {
+			return groups[i].NetProfit < groups[j].NetProfit
+		}
+		return groups[i].NetProfit > groups[j].NetProfit
+	})
+}
+
+// tradeTimeframes returns the timeframes of the trades' signals by signal ID. Trades without a
+// stored signal, or whose signal was stored before timeframes were, are left out.
+func tradeTimeframes(trades []Trade) map[string]string {
+	var ids []string
+	for _, trade := range trades {
+		if trade.SignalID != "" {
+			ids = append(ids, trade.SignalID)
+		}
+	}
+	timeframes := make(map[string]string)
+	if len(ids) == 0 {
+		return timeframes
+	}
+	var signals []Signal
+	if err := db.Select("signal_id", "timeframe").Where("signal_id IN ?", ids).Find(&signals).Error; err != nil {
+		telegramLog.Error("Failed to retrieve signal timeframes", "error", err)
+		return timeframes
+	}
+	for _, signal := range signals {
+		timeframes[signal.SignalID] = signal.Timeframe
+	}
+	return timeframes
+}
+
+// tradeDirection returns "Long" or "Short" for a trade's entry side.
+func tradeDirection(trade Trade) string {
+	if trade.Side == "SELL" {
+		return "Short"
+	}
+	return "Long"
+}
+
+// breakdownGroups groups trades by a breakdown dimension, returning false for unknown ones.
+func breakdownGroups(trades []Trade, by string) ([]PerformanceGroup, bool) {
+	switch by {
+	case BreakdownSymbol:
+		return groupPerformance(trades, func(t Trade) string { return t.Symbol }), true
+	case BreakdownSide:
+		return groupPerformance(trades, tradeDirection), true
+	case BreakdownTimeframe:
+		timeframes := tradeTimeframes(trades)
+		return groupPerformance(trades, func(t Trade) string { return timeframes[t.SignalID] }), true
+	}
+	return nil, false
+}
+
+// breakdownKeyboard offers the breakdowns of a performance period, ranked by order. Buttons
+// with an order edit the breakdown message they are on; those without send a new one.
+func breakdownKeyboard(chatID int64, timePeriod, order string) tgbotapi.InlineKeyboardMarkup {
+	data := func(by string) string {
+		if order == "" {
+			return fmt.Sprintf("%s|%s|%s", ActionPerformance, timePeriod, by)
+		}
+		return fmt.Sprintf("%s|%s|%s|%s", ActionPerformance, timePeriod, by, order)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "By Symbol"), data(BreakdownSymbol)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Long/Short"), data(BreakdownSide)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "By Timeframe"), data(BreakdownTimeframe)),
+		),
+	)
+}
+
+// showPerformanceBreakdown sends, or with an order edits, the breakdown of a period's trades by
+// symbol, direction or timeframe, ranked by net profit. messageID is the breakdown message
+// to edit, 0 to send a new one.
+func showPerformanceBreakdown(chatID int64, messageID int, timePeriod, by, order string) {
+	if order != BreakdownWorst {
+		order = BreakdownBest
+	}
+	trades, err := GetTradesForPeriod(timePeriod)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to fetch trade data: %v", err)))
+		return
+	}
+	groups, ok := breakdownGroups(trades, by)
+	if !ok {
+		telegramLog.Warn("Unknown performance breakdown", "breakdown", by)
+		return
+	}
+	rankPerformanceGroups(groups, order)
+
+	text := formatPerformanceBreakdown(chatID, by, order, groups)
+	otherOrder, otherLabel := BreakdownWorst, tr(chatID, "Worst First")
+	if order == BreakdownWorst {
+		otherOrder, otherLabel = BreakdownBest, tr(chatID, "Best First")
+	}
+	keyboard := breakdownKeyboard(chatID, timePeriod, order)
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(otherLabel, fmt.Sprintf("%s|%s|%s|%s", ActionPerformance, timePeriod, by, otherOrder)),
+	))
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		edit.ReplyMarkup = &keyboard
+		if _, err := bot.Send(edit); err != nil {
+			telegramLog.Error("Failed to edit performance breakdown", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send performance breakdown", "chat_id", chatID, "error", err)
+	}
+}
+
+// formatPerformanceBreakdown renders ranked groups, numbered, with each group's trades, win rate
+// and net profit.
+func formatPerformanceBreakdown(chatID int64, by, order string, groups []PerformanceGroup) string {
+	var title, untagged string
+	switch by {
+	case BreakdownSymbol:
+		title = tr(chatID, "By Symbol")
+	case BreakdownSide:
+		title = tr(chatID, "Long/Short")
+	case BreakdownTimeframe:
+		title, untagged = tr(chatID, "By Timeframe"), tr(chatID, "No timeframe")
+	}
+	if order == BreakdownWorst {
+		title += tr(chatID, ", worst first:\n")
+	} else {
+		title += tr(chatID, ", best first:\n")
+	}
+	if len(groups) == 0 {
+		return title + tr(chatID, "No trades in this period.")
+	}
+
+	text := title
+	for i, group := range groups {
+		if i == maxBreakdownRows {
+			text += tr(chatID, "...and %d more\n", len(groups)-maxBreakdownRows)
+			break
+		}
+		name := group.Name
+		if name == "" {
+			name = untagged
+		} else if by == BreakdownSide {
+			name = tr(chatID, name)
+		}
+		text += fmt.Sprintf("%d. %s: ", i+1, name) + formatGroupResult(chatID, group.PerformanceData)
+	}
+	return text
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bridgeQueueSize bounds the signals waiting to be written to the bridge. Further signals are
+// dropped, so a stalled Expert Advisor doesn't hold up confirmations.
+const bridgeQueueSize = 100
+
+// bridgeWriteTimeout is how long a connected bridge client gets to take a signal before it is
+// disconnected.
+const bridgeWriteTimeout = 2 * time.Second
+
+// BridgeSignal is a confirmed signal as republished to MetaTrader or cTrader bridges.
+type BridgeSignal struct {
+	SignalID   string    `json:"signal_id"`
+	Time       int64     `json:"time"` // Unix time of the confirmation
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"` // BUY or SELL
+	EntryPrice float64   `json:"entry_price"`
+	SL         float64   `json:"sl"` // 0 without a stop loss
+	TPs        []float64 `json:"tps"`
+}
+
+// csvLine returns the signal as a line of semicolon-separated fields, with the TPs separated by
+// commas, which MQL5's StringSplit reads easily:
+//
+//	1700000000123;1700000005;BTCUSD;BUY;64000;63000;65000,66000
+func (s BridgeSignal) csvLine() string {
+	tps := make([]string, len(s.TPs))
+	for i, tp := range s.TPs {
+		tps[i] = strconv.FormatFloat(tp, 'f', -1, 64)
+	}
+	return strings.Join([]string{
+		s.SignalID,
+		strconv.FormatInt(s.Time, 10),
+		s.Symbol,
+		s.Side,
+		strconv.FormatFloat(s.EntryPrice, 'f', -1, 64),
+		strconv.FormatFloat(s.SL, 'f', -1, 64),
+		strings.Join(tps, ","),
+	}, ";")
+}
+
+// signalBridge republishes confirmed signals, one per line, to a file an Expert Advisor or cBot
+// polls and to the clients connected to a local TCP port.
+type signalBridge struct {
+	file    string
+	json    bool
+	symbols map[string]string // Binance symbol to broker symbol, e.g. BTCUSDT to BTCUSD
+	queue   chan BridgeSignal
+
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+}
+
+// bridge is nil unless BRIDGE_FILE or BRIDGE_ADDR is set.
+var bridge *signalBridge
+
+// parseBridgeSymbols reads BRIDGE_SYMBOLS, e.g. "BTCUSDT=BTCUSD,XAUUSDT=XAUUSD".
+func parseBridgeSymbols(value string) (map[string]string, error) {
+	symbols := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return nil, fmt.Errorf("invalid symbol mapping %q, expected SYMBOL=BROKER_SYMBOL", pair)
+		}
+		symbols[strings.ToUpper(strings.TrimSpace(from))] = strings.TrimSpace(to)
+	}
+	return symbols, nil
+}
+
+// startBridge sets up the bridge from BRIDGE_FILE, BRIDGE_ADDR, BRIDGE_FORMAT and
+// BRIDGE_SYMBOLS. Without BRIDGE_FILE and BRIDGE_ADDR signals are not republished.
+func startBridge() error {
+	file, addr := os.Getenv("BRIDGE_FILE"), os.Getenv("BRIDGE_ADDR")
+	if file == "" && addr == "" {
+		return nil
+	}
+
+	b := &signalBridge{file: file, queue: make(chan BridgeSignal, bridgeQueueSize), clients: make(map[net.Conn]struct{})}
+	switch format := os.Getenv("BRIDGE_FORMAT"); format {
+	case "", "csv":
+	case "json":
+		b.json = true
+	default:
+		return fmt.Errorf("invalid BRIDGE_FORMAT %q, expected csv or json", format)
+	}
+	symbols, err := parseBridgeSymbols(os.Getenv("BRIDGE_SYMBOLS"))
+	if err != nil {
+		return fmt.Errorf("invalid BRIDGE_SYMBOLS: %w", err)
+	}
+	b.symbols = symbols
+
+	if addr != "" {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on BRIDGE_ADDR: %w", err)
+		}
+		slog.Info("Republishing confirmed signals to bridge clients", "addr", listener.Addr())
+		go b.accept(listener)
+		go func() {
+			<-shuttingDown
+			listener.Close()
+			b.disconnectAll()
+		}()
+	}
+	if file != "" {
+		slog.Info("Republishing confirmed signals to a file", "file", file)
+	}
+
+	go b.run()
+	bridge = b
+	return nil
+}
+
+// accept registers bridge clients until the listener is closed. Clients only read; anything
+// they send is ignored.
+func (b *signalBridge) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		telegramLog.Info("Bridge client connected", "remote", conn.RemoteAddr().String())
+		b.mu.Lock()
+		b.clients[conn] = struct{}{}
+		b.mu.Unlock()
+	}
+}
+
+// disconnectAll closes every client connection.
+func (b *signalBridge) disconnectAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.clients {
+		conn.Close()
+		delete(b.clients, conn)
+	}
+}
+
+// run writes queued signals one at a time, so they arrive in order.
+func (b *signalBridge) run() {
+	for signal := range b.queue {
+		line, err := b.encode(signal)
+		if err != nil {
+			telegramLog.Warn("Failed to encode bridge signal", "signal_id", signal.SignalID, "error", err)
+			continue
+		}
+		if b.file != "" {
+			if err := appendLine(b.file, line); err != nil {
+				telegramLog.Warn("Failed to write bridge file", "file", b.file, "error", err)
+			}
+		}
+		b.broadcast(line)
+	}
+}
+
+// encode returns the signal as a line in the bridge's format, ending in a newline.
+func (b *signalBridge) encode(signal BridgeSignal) ([]byte, error) {
+	if !b.json {
+		return []byte(signal.csvLine() + "\n"), nil
+	}
+	line, err := json.Marshal(signal)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// appendLine appends a line to a file, creating it if needed.
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// broadcast sends a line to every connected client, disconnecting those that don't take it in time.
+func (b *signalBridge) broadcast(line []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.clients {
+		conn.SetWriteDeadline(time.Now().Add(bridgeWriteTimeout))
+		if _, err := conn.Write(line); err != nil {
+			telegramLog.Info("Bridge client disconnected", "remote", conn.RemoteAddr().String(), "error", err)
+			conn.Close()
+			delete(b.clients, conn)
+		}
+	}
+}
+
+// publishToBridge republishes a confirmed signal to the bridge, with the TPs the trade uses. It
+// does nothing without a bridge, and returns without waiting for it.
+func publishToBridge(signal *AlertMessage) {
+	if bridge == nil {
+		return
+	}
+	symbol := signal.Symbol
+	if mapped, ok := bridge.symbols[symbol]; ok {
+		symbol = mapped
+	}
+	out := BridgeSignal{
+		SignalID:   signal.SignalID,
+		Time:       time.Now().Unix(),
+		Symbol:     symbol,
+		Side:       strings.ToUpper(signal.SignalType),
+		EntryPrice: signal.EntryPrice,
+		SL:         signal.SL,
+		TPs:        signal.TPs,
+	}
+	if out.TPs == nil {
+		out.TPs = []float64{}
+	}
+
+	select {
+	case bridge.queue <- out:
+	default:
+		telegramLog.Warn("Bridge queue is full, dropping signal", "signal_id", signal.SignalID)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// traderIDs returns the users who receive signal keyboards in private chats when
+// broadcasting: the configured admin and everyone with the trader or admin role.
+func traderIDs() ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	if adminUserID := GetGlobalConfig().AdminUserID; adminUserID != 0 {
+		ids = append(ids, adminUserID)
+		seen[adminUserID] = true
+	}
+
+	roles, err := ListUserRoles()
+	if err != nil {
+		return ids, err
+	}
+	for _, userRole := range roles {
+		if roleRanks[userRole.Role] >= roleRanks[RoleTrader] && !seen[userRole.UserID] {
+			ids = append(ids, userRole.UserID)
+			seen[userRole.UserID] = true
+		}
+	}
+	return ids, nil
+}
+
+// traderSignalID derives the ID of a trader's private copy of a signal.
+func traderSignalID(signalID string, traderID int64) string {
+	return fmt.Sprintf("%s_%d", signalID, traderID)
+}
+
+// sendTraderSignals sends each trader their own copy of a broadcast signal with the
+// Confirm/Edit/Dismiss keyboard in a private chat, so they can act on it independently.
+// original must be the signal as received, before any chat's settings were applied.
+// chart is the rendered signal chart, or nil if none is available.
+func sendTraderSignals(ctx context.Context, original *AlertMessage, signalID string, chart []byte) {
+	ids, err := traderIDs()
+	if err != nil {
+		telegramLog.Error("Failed to load traders", "signal_id", signalID, "error", err)
+	}
+
+	for _, traderID := range ids {
+		copyID := traderSignalID(signalID, traderID)
+		signal := *original
+		signal.SignalID = copyID
+		signal.TPs = slices.Clone(original.TPs)
+		prepareSignal(ctx, &signal, traderID)
+		if credential, err := GetUserCredential(traderID); err == nil && credential != nil {
+			signal.Account = personalAccountName
+		}
+		signalStore.Set(copyID, &signal)
+		recordSignalStatus(&signal, SignalReceived)
+		dismissOutsideHours(&signal)
+
+		quiet := quietMode(traderID)
+		if filterSignal(traderID, &signal) || holdSignal(traderID, copyID, quiet) {
+			continue
+		}
+
+		if chart != nil && userSettings.Get(traderID).ShowChart {
+			sendSignalChart(traderID, &signal, chart, quiet == QuietModeSilent)
+		}
+
+		msg := tgbotapi.NewMessage(traderID, constructSignalMessageText(&signal))
+		msg.ParseMode = "HTML"
+		msg.DisableNotification = quiet == QuietModeSilent
+		if !signal.Dismissed {
+			msg.ReplyMarkup = createSignalInlineKeyboard(traderID, copyID)
+		}
+		sentMessage, err := bot.Send(msg)
+		if err != nil {
+			// Telegram only allows messaging users who have started the bot
+			telegramLog.Warn("Failed to send signal to trader", "signal_id", signalID, "user_id", traderID, "error", err)
+			continue
+		}
+		messageStore.Set(copyID, sentMessage.MessageID)
+	}
+}
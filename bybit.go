@@ -0,0 +1,682 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+// defaultBybitAPIURL is the Bybit V5 REST API, overridden by BYBIT_API_URL, e.g. with
+// https://api-testnet.bybit.com for the testnet.
+const defaultBybitAPIURL = "https://api.bybit.com"
+
+// bybitRecvWindow is how long, in milliseconds, Bybit accepts a signed request after its
+// timestamp.
+const bybitRecvWindow = "5000"
+
+// bybitPingInterval is how often the private stream is pinged; Bybit drops it after 10 minutes
+// without one, and recommends every 20 seconds.
+const bybitPingInterval = 20 * time.Second
+
+// Bybit return codes for settings that already have the requested value.
+const (
+	bybitLeverageNotModified   = 110043
+	bybitMarginModeNotModified = 110026
+)
+
+// bybitAPIURL returns the configured Bybit REST API URL.
+func bybitAPIURL() string {
+	if apiURL := os.Getenv("BYBIT_API_URL"); apiURL != "" {
+		return strings.TrimRight(apiURL, "/")
+	}
+	return defaultBybitAPIURL
+}
+
+// bybitStreamURL returns the private WebSocket stream matching the REST API, e.g.
+// wss://stream-testnet.bybit.com/v5/private for https://api-testnet.bybit.com.
+func bybitStreamURL() string {
+	return strings.Replace(bybitAPIURL(), "https://api", "wss://stream", 1) + "/v5/private"
+}
+
+// BybitAPIError is an error returned by the Bybit API.
+type BybitAPIError struct {
+	Code    int
+	Message string
+}
+
+func (e *BybitAPIError) Error() string {
+	return fmt.Sprintf("bybit: code=%d, msg=%s", e.Code, e.Message)
+}
+
+// BybitClient trades linear (USDT) perpetuals on Bybit through its V5 API.
+type BybitClient struct {
+	APIKey    string
+	APISecret string
+	BaseURL   string
+	http      *http.Client
+}
+
+// newBybitClient creates a Bybit client for an API key pair against the configured API URL.
+func newBybitClient(apiKey, apiSecret string) *BybitClient {
+	return &BybitClient{
+		APIKey:    apiKey,
+		APISecret: apiSecret,
+		BaseURL:   bybitAPIURL(),
+		http:      &http.Client{Timeout: binanceTimeout()},
+	}
+}
+
+// validateBybitAPIKeys checks that a Bybit API key pair can read the account's balance.
+func validateBybitAPIKeys(apiKey, apiSecret string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := newBybitClient(apiKey, apiSecret).Balance(ctx); err != nil {
+		return fmt.Errorf("invalid Bybit API Key/Secret: %v", err)
+	}
+	return nil
+}
+
+// sign returns the signature of a request payload: the query string for GET, the JSON body
+// otherwise.
+func (c *BybitClient) sign(timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.APISecret))
+	mac.Write([]byte(timestamp + c.APIKey + bybitRecvWindow + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// get calls a GET endpoint and decodes its result into result.
+func (c *BybitClient) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	query := params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, query, result)
+}
+
+// post calls a POST endpoint with a JSON body and decodes its result into result, if not nil.
+func (c *BybitClient) post(ctx context.Context, path string, body map[string]interface{}, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, string(data), result)
+}
+
+// do signs and sends a request, returning a BybitAPIError for a non-zero return code.
+func (c *BybitClient) do(req *http.Request, payload string, result interface{}) error {
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	req.Header.Set("X-BAPI-API-KEY", c.APIKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", bybitRecvWindow)
+	req.Header.Set("X-BAPI-SIGN", c.sign(timestamp, payload))
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode, string(data))
+	}
+
+	var response struct {
+		RetCode int             `json:"retCode"`
+		RetMsg  string          `json:"retMsg"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to decode Bybit response: %v", err)
+	}
+	if response.RetCode != 0 {
+		return &BybitAPIError{Code: response.RetCode, Message: response.RetMsg}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// bybitInstrument holds the trading rules of a linear contract.
+type bybitInstrument struct {
+	Symbol      string `json:"symbol"`
+	PriceFilter struct {
+		TickSize string `json:"tickSize"`
+	} `json:"priceFilter"`
+	LotSizeFilter struct {
+		QtyStep     string `json:"qtyStep"`
+		MinOrderQty string `json:"minOrderQty"`
+	} `json:"lotSizeFilter"`
+	LeverageFilter struct {
+		MaxLeverage string `json:"maxLeverage"`
+	} `json:"leverageFilter"`
+}
+
+// instrument fetches the trading rules of a linear contract.
+func (c *BybitClient) instrument(ctx context.Context, symbol string) (*bybitInstrument, error) {
+	var result struct {
+		List []bybitInstrument `json:"list"`
+	}
+	params := url.Values{"category": {"linear"}, "symbol": {symbol}}
+	if err := c.get(ctx, "/v5/market/instruments-info", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.List) == 0 {
+		return nil, fmt.Errorf("symbol %s not found", symbol)
+	}
+	return &result.List[0], nil
+}
+
+// qtyStep returns the contract's quantity step.
+func (i *bybitInstrument) qtyStep() (float64, error) {
+	step, err := strconv.ParseFloat(i.LotSizeFilter.QtyStep, 64)
+	if err != nil || step <= 0 {
+		return 0, fmt.Errorf("failed to parse quantity step %q", i.LotSizeFilter.QtyStep)
+	}
+	return step, nil
+}
+
+// formatPrice rounds a price to the contract's tick size.
+func (i *bybitInstrument) formatPrice(price float64) (string, error) {
+	tickSize, err := strconv.ParseFloat(i.PriceFilter.TickSize, 64)
+	if err != nil || tickSize <= 0 {
+		return "", fmt.Errorf("failed to parse tick size %q", i.PriceFilter.TickSize)
+	}
+	return formatDecimal(math.Round(price/tickSize)*tickSize, tickSize), nil
+}
+
+// Name returns "Bybit".
+func (c *BybitClient) Name() string {
+	return "Bybit"
+}
+
+// SetLeverage sets the margin mode and leverage, clamped to the contract's maximum. Unified
+// trading accounts set the margin mode for the whole account, so failing to set it per symbol
+// is only logged.
+func (c *BybitClient) SetLeverage(ctx context.Context, symbol string, settings *UserSettings) (int, error) {
+	info, err := c.instrument(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	leverage := settings.Leverage
+	if leverage <= 0 {
+		leverage = 5
+	}
+	if maxLeverage, err := strconv.ParseFloat(info.LeverageFilter.MaxLeverage, 64); err == nil && maxLeverage >= 1 && float64(leverage) > maxLeverage {
+		leverage = int(maxLeverage)
+	}
+	value := strconv.Itoa(leverage)
+
+	tradeMode := 0
+	if settings.MarginMode == "Isolated" {
+		tradeMode = 1
+	}
+	err = c.post(ctx, "/v5/position/switch-isolated", map[string]interface{}{
+		"category": "linear", "symbol": symbol, "tradeMode": tradeMode, "buyLeverage": value, "sellLeverage": value,
+	}, nil)
+	if err != nil && !isBybitError(err, bybitMarginModeNotModified) {
+		binanceLog.Warn("Failed to set Bybit margin mode", "symbol", symbol, "margin_mode", settings.MarginMode, "error", err)
+	}
+
+	err = c.post(ctx, "/v5/position/set-leverage", map[string]interface{}{
+		"category": "linear", "symbol": symbol, "buyLeverage": value, "sellLeverage": value,
+	}, nil)
+	if err != nil && !isBybitError(err, bybitLeverageNotModified) {
+		return 0, fmt.Errorf("failed to set leverage: %v", err)
+	}
+	return leverage, nil
+}
+
+// isBybitError reports whether err is a Bybit API error with the return code.
+func isBybitError(err error, code int) bool {
+	apiErr, ok := err.(*BybitAPIError)
+	return ok && apiErr.Code == code
+}
+
+// PlaceEntry sizes the entry from the user's USDT amount and places it. Market entries are
+// aborted with a TradeGuardError if the price moved too far from the signal.
+func (c *BybitClient) PlaceEntry(ctx context.Context, signal *AlertMessage, settings *UserSettings) (string, error) {
+	symbol := signal.Symbol
+	if signal.EntryPrice <= 0 {
+		return "", fmt.Errorf("entry price is invalid (<= 0)")
+	}
+	info, err := c.instrument(ctx, symbol)
+	if err != nil {
+		return "", err
+	}
+	step, err := info.qtyStep()
+	if err != nil {
+		return "", err
+	}
+	quantity := math.Floor(settings.AmountUSDT/signal.EntryPrice/step) * step
+	if minQty, _ := strconv.ParseFloat(info.LotSizeFilter.MinOrderQty, 64); quantity <= 0 || quantity < minQty {
+		return "", fmt.Errorf("%.2f USDT is below the minimum order quantity of %s %s", settings.AmountUSDT, info.LotSizeFilter.MinOrderQty, symbol)
+	}
+	qty := formatDecimal(quantity, step)
+
+	side := "Buy"
+	if signal.SignalType == "Sell" {
+		side = "Sell"
+	}
+	clientID := clientOrderID(signal.SignalID, OrderTagEntry)
+	order := map[string]interface{}{
+		"category": "linear", "symbol": symbol, "side": side, "qty": qty, "orderLinkId": clientID,
+	}
+	if settings.TradingMode == "Limit" {
+		price, err := info.formatPrice(signal.EntryPrice)
+		if err != nil {
+			return "", err
+		}
+		order["orderType"] = "Limit"
+		order["price"] = price
+		order["timeInForce"] = "GTC"
+	} else {
+		if err := c.checkSlippage(ctx, symbol, side, signal.EntryPrice, settings.MaxSlippage); err != nil {
+			return "", err
+		}
+		order["orderType"] = "Market"
+	}
+	return qty, c.placeOrder(ctx, order)
+}
+
+// placeOrder creates an order and records it in the audit log.
+func (c *BybitClient) placeOrder(ctx context.Context, order map[string]interface{}) error {
+	var result struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	err := c.post(ctx, "/v5/order/create", order, &result)
+	clientID, _ := order["orderLinkId"].(string)
+	params := fmt.Sprintf("exchange=bybit symbol=%v side=%v type=%v quantity=%v", order["symbol"], order["side"], order["orderType"], order["qty"])
+	if price, ok := order["price"]; ok {
+		params += fmt.Sprintf(" price=%v", price)
+	}
+	if trigger, ok := order["triggerPrice"]; ok {
+		params += fmt.Sprintf(" trigger_price=%v trigger_by=%v reduce_only=true", trigger, order["triggerBy"])
+	}
+	auditOrder(AuditOrder, clientID, params, result, err)
+	return err
+}
+
+// checkSlippage compares the executable book price and the mark price against the signal entry
+// and returns a TradeGuardError if either moved by more than maxSlippage (a fraction).
+func (c *BybitClient) checkSlippage(ctx context.Context, symbol, side string, entryPrice, maxSlippage float64) error {
+	if maxSlippage <= 0 {
+		return nil
+	}
+	var result struct {
+		List []struct {
+			Ask1Price string `json:"ask1Price"`
+			Bid1Price string `json:"bid1Price"`
+			MarkPrice string `json:"markPrice"`
+		} `json:"list"`
+	}
+	if err := c.get(ctx, "/v5/market/tickers", url.Values{"category": {"linear"}, "symbol": {symbol}}, &result); err != nil {
+		return fmt.Errorf("failed to get ticker: %v", err)
+	}
+	if len(result.List) == 0 {
+		return fmt.Errorf("no ticker data for symbol %s", symbol)
+	}
+
+	// A buy fills at the ask, a sell at the bid
+	ticker := result.List[0]
+	bookPrice := ticker.Ask1Price
+	if side == "Sell" {
+		bookPrice = ticker.Bid1Price
+	}
+	for _, p := range []struct {
+		name   string
+		reason string
+		price  string
+	}{{"Book", bookSlippageReason, bookPrice}, {"Mark", markSlippageReason, ticker.MarkPrice}} {
+		price, err := strconv.ParseFloat(p.price, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s price: %v", strings.ToLower(p.name), err)
+		}
+		slippage := math.Abs(price-entryPrice) / entryPrice
+		if slippage > maxSlippage {
+			return newTradeGuardError(p.reason, symbol, formatFloat(price), slippage*100, formatFloat(entryPrice), maxSlippage*100)
+		}
+	}
+	return nil
+}
+
+// QuantityStep returns the contract's quantity step.
+func (c *BybitClient) QuantityStep(ctx context.Context, symbol string) (float64, error) {
+	info, err := c.instrument(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return info.qtyStep()
+}
+
+// PlaceTP places a reduce-only conditional market order for quantity at the TP level. Bybit
+// reduces a reduce-only order to what is left of the position, so the last TP closes the rest.
+func (c *BybitClient) PlaceTP(ctx context.Context, signal *AlertMessage, level int, price float64, quantity string, partial bool, settings *UserSettings) error {
+	return c.placeStopOrder(ctx, signal, price, quantity, true, clientOrderID(signal.SignalID, tpOrderTag(level)), settings)
+}
+
+// PlaceSL places a reduce-only conditional market order closing the position at the signal's SL.
+func (c *BybitClient) PlaceSL(ctx context.Context, signal *AlertMessage, quantity string, settings *UserSettings) error {
+	return c.placeStopOrder(ctx, signal, signal.SL, quantity, false, clientOrderID(signal.SignalID, OrderTagSL), settings)
+}
+
+// placeStopOrder places a conditional market order reducing the signal's position once the trigger
+// price is crossed: upwards for a long's TP or a short's SL, downwards otherwise. Bybit cancels
+// reduce-only orders once the position they would reduce is closed.
+func (c *BybitClient) placeStopOrder(ctx context.Context, signal *AlertMessage, price float64, quantity string, takeProfit bool, clientID string, settings *UserSettings) error {
+	info, err := c.instrument(ctx, signal.Symbol)
+	if err != nil {
+		return err
+	}
+	trigger, err := info.formatPrice(price)
+	if err != nil {
+		return err
+	}
+
+	long := signal.SignalType != "Sell"
+	side, direction := "Sell", 2 // 1 triggers when the price rises to the trigger, 2 when it falls
+	if !long {
+		side = "Buy"
+	}
+	if long == takeProfit {
+		direction = 1
+	}
+	triggerBy := "MarkPrice"
+	if workingType(settings) == futures.WorkingTypeContractPrice {
+		triggerBy = "LastPrice"
+	}
+	return c.placeOrder(ctx, map[string]interface{}{
+		"category": "linear", "symbol": signal.Symbol, "side": side, "orderType": "Market", "qty": quantity,
+		"triggerPrice": trigger, "triggerDirection": direction, "triggerBy": triggerBy,
+		"reduceOnly": true, "closeOnTrigger": true, "orderLinkId": clientID,
+	})
+}
+
+// Positions returns the open USDT perpetual positions.
+func (c *BybitClient) Positions(ctx context.Context) ([]ExchangePosition, error) {
+	var result struct {
+		List []struct {
+			Symbol        string `json:"symbol"`
+			Side          string `json:"side"`
+			Size          string `json:"size"`
+			AvgPrice      string `json:"avgPrice"`
+			MarkPrice     string `json:"markPrice"`
+			UnrealisedPnl string `json:"unrealisedPnl"`
+			LiqPrice      string `json:"liqPrice"`
+		} `json:"list"`
+	}
+	if err := c.get(ctx, "/v5/position/list", url.Values{"category": {"linear"}, "settleCoin": {"USDT"}}, &result); err != nil {
+		return nil, err
+	}
+	var positions []ExchangePosition
+	for _, item := range result.List {
+		amount, _ := strconv.ParseFloat(item.Size, 64)
+		if amount == 0 {
+			continue
+		}
+		if item.Side == "Sell" {
+			amount = -amount
+		}
+		position := ExchangePosition{Symbol: item.Symbol, Amount: amount}
+		position.EntryPrice, _ = strconv.ParseFloat(item.AvgPrice, 64)
+		position.MarkPrice, _ = strconv.ParseFloat(item.MarkPrice, 64)
+		position.UnrealizedPnL, _ = strconv.ParseFloat(item.UnrealisedPnl, 64)
+		position.LiquidationPrice, _ = strconv.ParseFloat(item.LiqPrice, 64)
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// CancelOrders cancels every open USDT perpetual order, including conditional TP/SL orders.
+func (c *BybitClient) CancelOrders(ctx context.Context) ([]string, error) {
+	var open struct {
+		List []struct {
+			Symbol string `json:"symbol"`
+		} `json:"list"`
+	}
+	if err := c.get(ctx, "/v5/order/realtime", url.Values{"category": {"linear"}, "settleCoin": {"USDT"}, "limit": {"50"}}, &open); err != nil {
+		return nil, fmt.Errorf("failed to list open orders: %v", err)
+	}
+	if len(open.List) == 0 {
+		return nil, nil
+	}
+
+	var result struct {
+		List []struct {
+			OrderID     string `json:"orderId"`
+			OrderLinkID string `json:"orderLinkId"`
+		} `json:"list"`
+	}
+	err := c.post(ctx, "/v5/order/cancel-all", map[string]interface{}{"category": "linear", "settleCoin": "USDT"}, &result)
+	auditOrder(AuditCancelOrder, "", "exchange=bybit settle_coin=USDT all=true", result, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel orders: %v", err)
+	}
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, order := range open.List {
+		if !seen[order.Symbol] {
+			seen[order.Symbol] = true
+			symbols = append(symbols, order.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// ClosePosition market-closes a USDT perpetual position with a reduce-only order.
+func (c *BybitClient) ClosePosition(ctx context.Context, position ExchangePosition) error {
+	side := "Sell"
+	if position.Amount < 0 {
+		side = "Buy"
+	}
+	return c.placeOrder(ctx, map[string]interface{}{
+		"category": "linear", "symbol": position.Symbol, "side": side, "orderType": "Market",
+		"qty": strconv.FormatFloat(math.Abs(position.Amount), 'f', -1, 64), "reduceOnly": true,
+	})
+}
+
+// Balance returns the coin balances of the unified trading account.
+func (c *BybitClient) Balance(ctx context.Context) ([]ExchangeBalance, error) {
+	var result struct {
+		List []struct {
+			Coin []struct {
+				Coin                string `json:"coin"`
+				WalletBalance       string `json:"walletBalance"`
+				UnrealisedPnl       string `json:"unrealisedPnl"`
+				AvailableToWithdraw string `json:"availableToWithdraw"`
+				TotalPositionIM     string `json:"totalPositionIM"`
+				TotalOrderIM        string `json:"totalOrderIM"`
+				Locked              string `json:"locked"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := c.get(ctx, "/v5/account/wallet-balance", url.Values{"accountType": {"UNIFIED"}}, &result); err != nil {
+		return nil, err
+	}
+	var balances []ExchangeBalance
+	for _, account := range result.List {
+		for _, coin := range account.Coin {
+			balance := ExchangeBalance{Asset: coin.Coin}
+			balance.Wallet, _ = strconv.ParseFloat(coin.WalletBalance, 64)
+			balance.UnrealizedPnL, _ = strconv.ParseFloat(coin.UnrealisedPnl, 64)
+			if available, err := strconv.ParseFloat(coin.AvailableToWithdraw, 64); err == nil {
+				balance.Available = available
+			} else {
+				// Newer accounts no longer report it, so subtract the margin in use
+				positionIM, _ := strconv.ParseFloat(coin.TotalPositionIM, 64)
+				orderIM, _ := strconv.ParseFloat(coin.TotalOrderIM, 64)
+				locked, _ := strconv.ParseFloat(coin.Locked, 64)
+				balance.Available = max(balance.Wallet-positionIM-orderIM-locked, 0)
+			}
+			balances = append(balances, balance)
+		}
+	}
+	return balances, nil
+}
+
+// StreamEvents reports order updates from Bybit's private stream.
+func (c *BybitClient) StreamEvents(ctx context.Context, handle func(ExchangeEvent)) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, bybitStreamURL(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// Authenticate with a signature of the expiry, then subscribe to linear order updates
+	expires := strconv.FormatInt(time.Now().Add(10*time.Second).UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(c.APISecret))
+	mac.Write([]byte("GET/realtime" + expires))
+	requests := []map[string]interface{}{
+		{"op": "auth", "args": []string{c.APIKey, expires, hex.EncodeToString(mac.Sum(nil))}},
+		{"op": "subscribe", "args": []string{"order.linear"}},
+	}
+	for _, request := range requests {
+		if err := conn.WriteJSON(request); err != nil {
+			return fmt.Errorf("failed to subscribe to Bybit stream: %w", err)
+		}
+	}
+
+	// Only the pinger writes from here on
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		ticker := time.NewTicker(bybitPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WriteJSON(map[string]string{"op": "ping"}); err != nil {
+					return
+				}
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Bybit stream disconnected: %w", err)
+		}
+
+		var event struct {
+			Op      string `json:"op"`
+			Success *bool  `json:"success"`
+			RetMsg  string `json:"ret_msg"`
+			Topic   string `json:"topic"`
+			Data    []struct {
+				Symbol      string `json:"symbol"`
+				OrderLinkID string `json:"orderLinkId"`
+				OrderStatus string `json:"orderStatus"`
+				AvgPrice    string `json:"avgPrice"`
+				CumExecQty  string `json:"cumExecQty"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(message, &event); err != nil {
+			binanceLog.Warn("Failed to decode Bybit stream message", "error", err)
+			continue
+		}
+		if event.Success != nil && !*event.Success {
+			return fmt.Errorf("Bybit stream %s failed: %s", event.Op, event.RetMsg)
+		}
+		if event.Topic != "order.linear" {
+			continue
+		}
+		for _, order := range event.Data {
+			price, _ := strconv.ParseFloat(order.AvgPrice, 64)
+			quantity, _ := strconv.ParseFloat(order.CumExecQty, 64)
+			handle(ExchangeEvent{
+				Symbol:        order.Symbol,
+				ClientOrderID: order.OrderLinkID,
+				Status:        order.OrderStatus,
+				Filled:        order.OrderStatus == "Filled",
+				Price:         price,
+				Quantity:      quantity,
+			})
+		}
+	}
+}
+
+// BybitClientStore caches Bybit clients for users' own credentials with concurrency safety.
+type BybitClientStore struct {
+	sync.RWMutex
+	clients map[string]*BybitClient
+}
+
+// NewBybitClientStore creates a new instance of BybitClientStore.
+func NewBybitClientStore() *BybitClientStore {
+	return &BybitClientStore{
+		clients: make(map[string]*BybitClient),
+	}
+}
+
+func (s *BybitClientStore) Set(name string, client *BybitClient) {
+	s.Lock()
+	defer s.Unlock()
+	s.clients[name] = client
+}
+
+func (s *BybitClientStore) Get(name string) (*BybitClient, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	client, exists := s.clients[name]
+	return client, exists
+}
+
+// Delete removes a cached client and stops its event stream, so the next use creates it from
+// the current keys.
+func (s *BybitClientStore) Delete(name string) {
+	s.Lock()
+	defer s.Unlock()
+	if client, exists := s.clients[name]; exists {
+		stopExchangeEvents(client)
+		delete(s.clients, name)
+	}
+}
+
+// Clear removes every cached client and stops their event streams.
+func (s *BybitClientStore) Clear() {
+	s.Lock()
+	defer s.Unlock()
+	for name, client := range s.clients {
+		stopExchangeEvents(client)
+		delete(s.clients, name)
+	}
+}
+
+// bybitClients caches Bybit clients for users with their own Bybit credentials, keyed by user ID.
+var bybitClients = NewBybitClientStore()
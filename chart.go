@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Signal chart dimensions and the number of candles shown.
+const (
+	chartWidth   = 800
+	chartHeight  = 450
+	chartPadding = 20
+	chartCandles = 120
+)
+
+var (
+	chartBackground = color.RGBA{R: 0x16, G: 0x1a, B: 0x25, A: 0xff}
+	chartBullish    = color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff}
+	chartBearish    = color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff}
+	chartEntry      = color.RGBA{R: 0x42, G: 0xa5, B: 0xf5, A: 0xff}
+	chartTP         = color.RGBA{R: 0x66, G: 0xbb, B: 0x6a, A: 0xff}
+	chartSL         = color.RGBA{R: 0xff, G: 0x70, B: 0x43, A: 0xff}
+)
+
+// chartLevel is a horizontal price line drawn across the chart.
+type chartLevel struct {
+	Price float64
+	Color color.RGBA
+}
+
+// chartInterval maps a TradingView timeframe ("15", "240", "D", "1h", "M") to a Binance kline
+// interval. Unknown timeframes fall back to 1h.
+func chartInterval(timeframe string) string {
+	// An uppercase M is a month, not a minute
+	if tf := strings.TrimSpace(timeframe); tf == "M" || tf == "1M" {
+		return "1M"
+	}
+	tf := strings.ToLower(strings.TrimSpace(timeframe))
+	switch tf {
+	case "1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w":
+		return tf
+	case "d":
+		return "1d"
+	case "w":
+		return "1w"
+	}
+
+	minutes, err := strconv.Atoi(tf)
+	if err != nil {
+		return "1h"
+	}
+	intervals := map[int]string{
+		1: "1m", 3: "3m", 5: "5m", 15: "15m", 30: "30m",
+		60: "1h", 120: "2h", 240: "4h", 360: "6h", 480: "8h", 720: "12h", 1440: "1d",
+	}
+	if interval, ok := intervals[minutes]; ok {
+		return interval
+	}
+	return "1h"
+}
+
+// signalChart renders recent candles for the signal's symbol with its entry, TP and SL levels as a PNG.
+func (b *BinanceClient) signalChart(ctx context.Context, signal *AlertMessage) ([]byte, error) {
+	klines, err := b.Client.NewKlinesService().
+		Symbol(signal.Symbol).
+		Interval(chartInterval(signal.Timeframe)).
+		Limit(chartCandles).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines: %v", err)
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no klines for %s", signal.Symbol)
+	}
+
+	levels := []chartLevel{{Price: signal.EntryPrice, Color: chartEntry}}
+	for _, tp := range signal.TPs {
+		levels = append(levels, chartLevel{Price: tp, Color: chartTP})
+	}
+	levels = append(levels, chartLevel{Price: signal.SL, Color: chartSL})
+	return renderChart(klines, levels)
+}
+
+// renderChart draws candlesticks and dashed level lines. Levels at or below zero are skipped.
+func renderChart(klines []*futures.Kline, levels []chartLevel) ([]byte, error) {
+	type candle struct{ open, high, low, close float64 }
+	candles := make([]candle, 0, len(klines))
+	low, high := math.MaxFloat64, 0.0
+	for _, k := range klines {
+		var c candle
+		var err error
+		if c.open, err = strconv.ParseFloat(k.Open, 64); err != nil {
+			return nil, err
+		}
+		if c.high, err = strconv.ParseFloat(k.High, 64); err != nil {
+			return nil, err
+		}
+		if c.low, err = strconv.ParseFloat(k.Low, 64); err != nil {
+			return nil, err
+		}
+		if c.close, err = strconv.ParseFloat(k.Close, 64); err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+		low = math.Min(low, c.low)
+		high = math.Max(high, c.high)
+	}
+	for _, level := range levels {
+		if level.Price > 0 {
+			low = math.Min(low, level.Price)
+			high = math.Max(high, level.Price)
+		}
+	}
+	if high <= low {
+		high = low * 1.01
+	}
+	margin := (high - low) * 0.03
+	low, high = low-margin, high+margin
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+
+	plotHeight := float64(chartHeight - 2*chartPadding)
+	y := func(price float64) int {
+		return chartPadding + int((high-price)/(high-low)*plotHeight)
+	}
+	fill := func(x0, y0, x1, y1 int, c color.RGBA) {
+		if y1 < y0 {
+			y0, y1 = y1, y0
+		}
+		draw.Draw(img, image.Rect(x0, y0, x1+1, y1+1), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	slot := float64(chartWidth-2*chartPadding) / float64(len(candles))
+	body := int(math.Max(1, slot*0.7))
+	for i, c := range candles {
+		col := chartBullish
+		if c.close < c.open {
+			col = chartBearish
+		}
+		center := chartPadding + int((float64(i)+0.5)*slot)
+		fill(center, y(c.high), center, y(c.low), col)
+		fill(center-body/2, y(c.open), center-body/2+body-1, y(c.close), col)
+	}
+
+	for _, level := range levels {
+		if level.Price <= 0 {
+			continue
+		}
+		ly := y(level.Price)
+		for x := chartPadding; x < chartWidth-chartPadding; x += 9 {
+			fill(x, ly, int(math.Min(float64(x+5), chartWidth-chartPadding)), ly+1, level.Color)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sendSignalChart sends a rendered signal chart with a short legend as the caption, without a
+// notification sound if silent is set.
+func sendSignalChart(chatID int64, signal *AlertMessage, chart []byte, silent bool) {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: signal.Symbol + ".png", Bytes: chart})
+	photo.DisableNotification = silent
+	photo.Caption = tr(chatID, "%s %s: blue entry, green TPs, red SL", signal.Symbol, chartInterval(signal.Timeframe))
+	if _, err := bot.Send(photo); err != nil {
+		telegramLog.Error("Failed to send chart", "signal_id", signal.SignalID, "chat_id", chatID, "symbol", signal.Symbol, "error", err)
+	}
+}
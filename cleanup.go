@@ -0,0 +1,133 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMessageRetentionHours keeps cleanup inside the 48 hours Telegram lets bots delete their messages.
+const maxMessageRetentionHours = 47
+
+// messageCleanupInterval is how often the cleanup scheduler looks for stale messages.
+const messageCleanupInterval = 5 * time.Minute
+
+// Kinds of tracked bot messages. Menus and prompts are deleted once stale; dismissed signals
+// are collapsed to one line so the chat keeps a record of them.
+const (
+	messageKindMenu      = "menu"
+	messageKindPrompt    = "prompt"
+	messageKindDismissed = "dismissed"
+)
+
+// TrackedMessage is a bot message to clean up once it is older than the retention period.
+type TrackedMessage struct {
+	ChatID    int64
+	MessageID int
+	Kind      string
+	SignalID  string // Set for dismissed signals
+	SentAt    time.Time
+}
+
+// Track records a message for cleanup.
+func (m *MessageStore) Track(message TrackedMessage) {
+	m.Lock()
+	defer m.Unlock()
+	m.tracked = append(m.tracked, message)
+}
+
+// TakeExpired removes and returns the tracked messages sent before the cutoff.
+func (m *MessageStore) TakeExpired(cutoff time.Time) []TrackedMessage {
+	m.Lock()
+	defer m.Unlock()
+
+	var expired, kept []TrackedMessage
+	for _, message := range m.tracked {
+		if message.SentAt.Before(cutoff) {
+			expired = append(expired, message)
+		} else {
+			kept = append(kept, message)
+		}
+	}
+	m.tracked = kept
+	return expired
+}
+
+// messageRetention returns how long bot messages are kept before cleanup, or 0 if cleanup is off.
+func messageRetention() time.Duration {
+	return time.Duration(GetGlobalConfig().MessageRetentionHours) * time.Hour
+}
+
+// trackMessage records a sent menu or prompt for cleanup. Nothing is tracked while cleanup is
+// off or if the message failed to send.
+func trackMessage(sent tgbotapi.Message, kind string) {
+	if sent.MessageID == 0 || sent.Chat == nil || messageRetention() == 0 {
+		return
+	}
+	messageStore.Track(TrackedMessage{
+		ChatID:    sent.Chat.ID,
+		MessageID: sent.MessageID,
+		Kind:      kind,
+		SentAt:    time.Now(),
+	})
+}
+
+// trackDismissedSignal records a dismissed signal's message to be collapsed once stale.
+func trackDismissedSignal(chatID int64, messageID int, signalID string) {
+	if messageRetention() == 0 {
+		return
+	}
+	messageStore.Track(TrackedMessage{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Kind:      messageKindDismissed,
+		SignalID:  signalID,
+		SentAt:    time.Now(),
+	})
+}
+
+// cleanupMessage deletes a stale menu or prompt, or collapses a dismissed signal message.
+func cleanupMessage(message TrackedMessage) {
+	chatID := message.ChatID
+	if message.Kind != messageKindDismissed {
+		if _, err := bot.Request(tgbotapi.NewDeleteMessage(chatID, message.MessageID)); err != nil {
+			telegramLog.Error("Failed to delete message", "chat_id", chatID, "message_id", message.MessageID, "error", err)
+		}
+		return
+	}
+
+	signal, exists := signalStore.Get(message.SignalID)
+	if !exists {
+		return
+	}
+	text := tr(chatID, "Dismissed: %s %s", tr(chatID, signal.SignalType), signal.Symbol)
+	if signal.Timeframe != "" {
+		text += " " + signal.Timeframe
+	}
+	if _, err := bot.Send(tgbotapi.NewEditMessageText(chatID, message.MessageID, text)); err != nil {
+		telegramLog.Error("Failed to collapse message", "signal_id", signal.SignalID, "symbol", signal.Symbol, "chat_id", chatID, "message_id", message.MessageID, "error", err)
+	}
+}
+
+var cleanupSchedulerStart sync.Once
+
+// startCleanupScheduler deletes stale menus and prompts and collapses dismissed signals once
+// they are older than MessageRetentionHours.
+func startCleanupScheduler() {
+	cleanupSchedulerStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(messageCleanupInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				retention := messageRetention()
+				if bot == nil || retention == 0 {
+					continue
+				}
+				for _, message := range messageStore.TakeExpired(time.Now().Add(-retention)) {
+					cleanupMessage(message)
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// botCommands are registered with Telegram so clients show them in the command menu.
+var botCommands = []struct{ Command, Description string }{
+	{"start", "Show the welcome message"},
+	{"settings", "View and change your trading settings"},
+	{"signals", "List pending signals"},
+	{"positions", "Show open positions"},
+	{"ticker", "Pin a live positions message"},
+	{"balance", "Show your futures balance"},
+	{"exposure", "Show long and short exposure per asset"},
+	{"performance", "Show trading performance"},
+	{"backtest", "Replay past signals with your settings"},
+	{"report", "Get a monthly PDF report"},
+	{"history", "Page through recent trades"},
+	{"price", "Show a symbol's price, e.g. /price BTCUSDT"},
+	{"quote", "Show a symbol's full market data"},
+	{"news", "List high-impact news that pauses trading"},
+	{"watch", "Add symbols to your watchlist"},
+	{"unwatch", "Remove symbols from your watchlist"},
+	{"summary", "Summarize the last day or week"},
+	{"profiles", "Manage settings profiles"},
+	{"mute", "Mute signal notifications for a while"},
+	{"connect", "Trade on your own Binance account"},
+	{"disconnect", "Remove your Binance API key"},
+	{"pin", "Require a PIN or authenticator code to trade"},
+	{"language", "Choose the bot language"},
+}
+
+// registerBotCommands publishes botCommands in English and in each translated language.
+func registerBotCommands() {
+	for _, lang := range languageNames {
+		commands := make([]tgbotapi.BotCommand, 0, len(botCommands))
+		for _, c := range botCommands {
+			commands = append(commands, tgbotapi.BotCommand{Command: c.Command, Description: translate(lang.Code, c.Description)})
+		}
+
+		config := tgbotapi.NewSetMyCommands(commands...)
+		if lang.Code != LangEnglish {
+			config = tgbotapi.NewSetMyCommandsWithScopeAndLanguage(tgbotapi.NewBotCommandScopeDefault(), lang.Code, commands...)
+		}
+		if _, err := bot.Request(config); err != nil {
+			telegramLog.Error("Failed to register bot commands", "language", lang.Code, "error", err)
+		}
+	}
+}
+
+// quickActions are the reply keyboard buttons, in English, and the commands they run.
+var quickActions = [][]struct{ Label, Command string }{
+	{{"Settings", "settings"}, {"Positions", "positions"}},
+	{{"Performance", "performance"}, {"Balance", "balance"}},
+}
+
+// quickActionKeyboard builds the persistent reply keyboard in the chat's language.
+func quickActionKeyboard(chatID int64) tgbotapi.ReplyKeyboardMarkup {
+	var rows [][]tgbotapi.KeyboardButton
+	for _, actions := range quickActions {
+		var row []tgbotapi.KeyboardButton
+		for _, action := range actions {
+			row = append(row, tgbotapi.NewKeyboardButton(tr(chatID, action.Label)))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
+
+// handleQuickAction runs the command for a reply keyboard button and reports whether the
+// message was one. Quick actions need the same role as the command they run.
+func handleQuickAction(message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+	for _, actions := range quickActions {
+		for _, action := range actions {
+			if message.Text != tr(chatID, action.Label) {
+				continue
+			}
+			if required, ok := commandRoles[action.Command]; ok && !hasRole(senderID(message), required) {
+				bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This command requires the %s role.", required)))
+				return true
+			}
+			switch action.Command {
+			case "settings":
+				showSettingsMenu(chatID)
+			case "positions":
+				handlePositionsCommand(message)
+			case "performance":
+				showPerformanceOptions(chatID)
+			case "balance":
+				handleBalanceCommand(message)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// toggleQuickActions shows or removes the quick action reply keyboard.
+func toggleQuickActions(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.QuickActions = !settings.QuickActions
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Quick Actions keyboard has been %s.", enabledText(chatID, settings.QuickActions)))
+	if settings.QuickActions {
+		msg.ReplyMarkup = quickActionKeyboard(chatID)
+	} else {
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+	}
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// compactSignalText renders a signal in at most five short lines without emoji, leaving out
+// prices that are not set, for reading on a watch or small screen.
+func compactSignalText(signal *AlertMessage) string {
+	chatID := signal.ChatID
+
+	header := fmt.Sprintf("<b>%s %s</b>", strings.ToUpper(tr(chatID, signal.SignalType)), signal.Symbol)
+	if signal.Timeframe != "" {
+		header += " " + signal.Timeframe
+	}
+	header += " | " + formatSignalTime(chatID, signal.Time)
+	lines := []string{header}
+
+	prices := []string{tr(chatID, "Entry %s", formatFloat(signal.EntryPrice))}
+	if signal.SL > 0 {
+		prices = append(prices, tr(chatID, "SL %s", formatFloat(signal.SL)))
+	}
+	lines = append(lines, strings.Join(prices, " | "))
+
+	var tps []string
+	for _, tp := range signal.TPs {
+		if tp > 0 {
+			tps = append(tps, formatFloat(tp))
+		}
+	}
+	if len(tps) > 0 {
+		lines = append(lines, tr(chatID, "TP %s", strings.Join(tps, " / ")))
+	}
+
+	var extras []string
+	if signal.Liquidation != nil {
+		side := futures.SideTypeBuy
+		if signal.SignalType == "Sell" {
+			side = futures.SideTypeSell
+		}
+		if liq := signal.Liquidation.Price(side, signal.EntryPrice); liq > 0 {
+			extras = append(extras, tr(chatID, "Liq %s", formatFloat(roundToSignificant(liq, 6))))
+		}
+	}
+	if signal.Account != "" {
+		extras = append(extras, signal.Account)
+	}
+	if signal.Profile != "" {
+		extras = append(extras, signal.Profile)
+	}
+	if signal.LeverageOverride > 0 {
+		extras = append(extras, fmt.Sprintf("%dx", signal.LeverageOverride))
+	}
+	if signal.AmountOverride > 0 {
+		extras = append(extras, fmt.Sprintf("%.0f USDT", signal.AmountOverride))
+	}
+	if signal.FundingWarning != "" {
+		extras = append(extras, tr(chatID, "high funding"))
+	}
+	if signal.LiquidityWarning != "" {
+		extras = append(extras, tr(chatID, "thin liquidity"))
+	}
+	for _, note := range signal.FilterNotes {
+		extras = append(extras, html.EscapeString(note))
+	}
+	if len(extras) > 0 {
+		lines = append(lines, strings.Join(extras, " | "))
+	}
+
+	if signal.Confirmed {
+		lines = append(lines, tr(chatID, "Confirmed"))
+	} else if signal.Dismissed {
+		lines = append(lines, tr(chatID, "Dismissed"))
+	} else if signal.Expired {
+		lines = append(lines, tr(chatID, "Expired"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toggleCompactMessages toggles the short signal message layout.
+func toggleCompactMessages(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.CompactMessages = !settings.CompactMessages
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Compact Messages have been %s.", enabledText(chatID, settings.CompactMessages)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -15,14 +15,76 @@ var (
 
 // Config holds the application configuration.
 type Config struct {
-	ID               uint `gorm:"primaryKey"`
-	TelegramBotToken string
+	ID               uint   `gorm:"primaryKey"`
+	TelegramBotToken string `gorm:"serializer:encrypted"`
 	TelegramChatID   int64
-	BinanceAPIKey    string
-	BinanceAPISecret string
+	BinanceAPIKey    string `gorm:"serializer:encrypted"`
+	BinanceAPISecret string `gorm:"serializer:encrypted"`
 	BinanceAPIURL    string
+	AdminUserID      int64
+	// BinanceRecvWindow is how many milliseconds after its timestamp Binance accepts a signed
+	// request; 0 uses Binance's default of 5000
+	BinanceRecvWindow int
+	AlertChatID       int64  // Chat alerted about critical failures; 0 alerts the admin user
+	OrderIDPrefix     string // Prefix for client order IDs placed by the bot
+
+	// DiscordWebhookURL is a Discord channel webhook that signals, confirmations, fills and
+	// summaries are mirrored to; empty mirrors nothing
+	DiscordWebhookURL string `gorm:"serializer:encrypted"`
+
+	// BroadcastToTraders posts signals to the chat without a keyboard and sends each
+	// trader their own copy to confirm in a private chat
+	BroadcastToTraders bool
+
+	// DailySummary and WeeklySummary send a signal and PnL summary to the chat at SummaryHour
+	// (0-23, in the chat's timezone); weekly summaries go out on Mondays
+	DailySummary  bool
+	WeeklySummary bool
+	SummaryHour   int
+
+	// MonthlyReport sends last month's PDF report to the chat at SummaryHour on the 1st, and
+	// ReportEmail emails it to these comma-separated addresses through SMTP_ADDR
+	MonthlyReport bool
+	ReportEmail   string
+
+	// MessageRetentionHours deletes stale settings menus and prompts and collapses dismissed
+	// signals after this many hours; 0 keeps them
+	MessageRetentionHours int
+
+	// ExposureLimitPercent warns in /exposure and on the dashboard when the long or the short
+	// notional across open positions exceeds this percentage of equity; 0 never warns
+	ExposureLimitPercent float64
+
+	// NewsHaltMinutes pauses trading this many minutes before and after each high-impact
+	// economic calendar event of NewsCurrencies (comma-separated, USD if empty); 0 never pauses
+	NewsHaltMinutes int
+	NewsCurrencies  string
+
+	// SignalExpiryBySource sets how long signals of a source stay valid, e.g.
+	// "tradingview=2h, telegram=45m"; others expire after two candles of their timeframe, or 4h
+	SignalExpiryBySource string
+
+	// EntryTypeBySource places the entries of a source's signals as market, limit, midpoint or
+	// stop orders regardless of the user's Trading Mode, e.g. "breakout=stop, tradingview=limit"
+	EntryTypeBySource string
+
+	// DuplicateGuard asks for Add to Position before a Confirm stacks onto an open position in
+	// the same symbol and direction, or onto a signal for them confirmed in the chat within
+	// DuplicateGuardMinutes (0 checks open positions only)
+	DuplicateGuard        bool
+	DuplicateGuardMinutes int
+
+	// DualConfirmNotional requires Confirm from two different traders for trades larger than
+	// this many USDT; 0 lets one trader confirm any trade
+	DualConfirmNotional float64
+
+	// TradingHalted refuses every new trade, set by /panic until /panic off
+	TradingHalted bool
 }
 
+// defaultOrderIDPrefix is used when no OrderIDPrefix is configured.
+const defaultOrderIDPrefix = "tgbot"
+
 // Validate checks the Config fields for validity.
 func (config *Config) Validate() error {
 	if config.TelegramBotToken == "" {
@@ -40,6 +102,46 @@ func (config *Config) Validate() error {
 	if config.BinanceAPIURL == "" {
 		return errors.New("Binance API URL cannot be empty")
 	}
+	if config.SummaryHour < 0 || config.SummaryHour > 23 {
+		return errors.New("Summary hour must be between 0 and 23")
+	}
+	if config.MessageRetentionHours < 0 || config.MessageRetentionHours > maxMessageRetentionHours {
+		return fmt.Errorf("Message retention must be between 0 and %d hours", maxMessageRetentionHours)
+	}
+	if config.BinanceRecvWindow < 0 || config.BinanceRecvWindow > maxRecvWindow {
+		return fmt.Errorf("Binance recvWindow must be between 0 and %d milliseconds", maxRecvWindow)
+	}
+	if config.ExposureLimitPercent < 0 {
+		return errors.New("Exposure warning limit cannot be negative")
+	}
+	if config.NewsHaltMinutes < 0 || config.NewsHaltMinutes > maxNewsHaltMinutes {
+		return fmt.Errorf("News halt must be between 0 and %d minutes", maxNewsHaltMinutes)
+	}
+	if _, err := parseSignalExpiry(config.SignalExpiryBySource); err != nil {
+		return fmt.Errorf("Signal expiry by source: %v", err)
+	}
+	if _, err := parseEntryTypes(config.EntryTypeBySource); err != nil {
+		return fmt.Errorf("Entry type by source: %v", err)
+	}
+	if config.DuplicateGuardMinutes < 0 {
+		return errors.New("Duplicate guard window cannot be negative")
+	}
+	if config.DualConfirmNotional < 0 {
+		return errors.New("Two-trader confirmation limit cannot be negative")
+	}
+	if config.ReportEmail != "" {
+		if _, err := parseEmailList(config.ReportEmail); err != nil {
+			return fmt.Errorf("Report email: %v", err)
+		}
+		if _, ok := loadSMTPSettings(); !ok {
+			return errors.New("Report email needs SMTP_ADDR to be set")
+		}
+	}
+	if config.DiscordWebhookURL != "" {
+		if err := validateDiscordWebhookURL(config.DiscordWebhookURL); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -55,17 +157,20 @@ func getConfig() (*Config, error) {
 	return &config, nil
 }
 
-// saveConfig saves the configuration to the database.
-func saveConfig(config *Config) error {
+// saveConfig saves the configuration to the database and adds it to the configuration
+// history with its author and an optional note.
+func saveConfig(config *Config, author, note string) error {
 	if err := config.Validate(); err != nil {
 		return err
 	}
 	config.ID = 1 // Fixed ID to enforce single record
 
-	if err := db.Save(config).Error; err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
-	}
-	return nil
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(config).Error; err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+		return recordConfigVersion(tx, config, author, note)
+	})
 }
 
 // GetGlobalConfig safely retrieves the GlobalConfig.
@@ -75,9 +180,35 @@ func GetGlobalConfig() Config {
 	return GlobalConfig
 }
 
-// SetGlobalConfig safely sets the GlobalConfig.
+// SetGlobalConfig safely sets the GlobalConfig and passes the change to the listeners added
+// with onConfigChange.
 func SetGlobalConfig(config Config) {
+	// Changes reach the listeners one at a time and in order
+	configListeners.Lock()
+	defer configListeners.Unlock()
+
 	configMutex.Lock()
-	defer configMutex.Unlock()
+	previous := GlobalConfig
 	GlobalConfig = config
+	configMutex.Unlock()
+
+	for _, listener := range configListeners.list {
+		listener(previous, config)
+	}
+}
+
+// configListeners are called with the previous and the new configuration whenever it changes.
+var configListeners struct {
+	sync.Mutex
+	list []func(previous, current Config)
+}
+
+// onConfigChange adds a listener that updates a part of the bot when the configuration is saved,
+// rolled back or restored. Listeners run after GlobalConfig holds the new configuration, so
+// anything reading it sees the change; they should return quickly and do slow work, such as
+// requests to Telegram, in the background.
+func onConfigChange(listener func(previous, current Config)) {
+	configListeners.Lock()
+	defer configListeners.Unlock()
+	configListeners.list = append(configListeners.list, listener)
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// configHistoryEntries is how many versions the admin history page shows.
+const configHistoryEntries = 100
+
+// ConfigVersion is a saved version of the configuration, kept so a bad change can be rolled back.
+type ConfigVersion struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	Author    string    // Admin panel user and address that saved the version
+	Note      string    // e.g. which version a rollback restored
+	Changes   string    // Fields changed from the previous version
+	Data      string    `gorm:"serializer:encrypted"` // The configuration as JSON, with its secrets
+}
+
+// Config decodes the configuration of the version.
+func (v *ConfigVersion) Config() (Config, error) {
+	var config Config
+	if err := json.Unmarshal([]byte(v.Data), &config); err != nil {
+		return config, fmt.Errorf("failed to decode configuration version %d: %w", v.ID, err)
+	}
+	return config, nil
+}
+
+// newConfigVersion returns the version row for a configuration.
+func newConfigVersion(config *Config, previous *Config) (*ConfigVersion, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	version := &ConfigVersion{Data: string(data)}
+	if previous != nil {
+		version.Changes = configChanges(previous, config)
+	}
+	return version, nil
+}
+
+// configChanges lists the names of the fields that differ between two configurations.
+func configChanges(old, updated *Config) string {
+	return fieldChanges(*old, *updated)
+}
+
+// fieldChanges lists the names of the fields that differ between two structs of the same type,
+// ignoring ID.
+func fieldChanges(old, updated interface{}) string {
+	oldValue := reflect.ValueOf(old)
+	newValue := reflect.ValueOf(updated)
+	var changes string
+	for i := 0; i < oldValue.NumField(); i++ {
+		name := oldValue.Type().Field(i).Name
+		if name == "ID" || reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		if changes != "" {
+			changes += ", "
+		}
+		changes += name
+	}
+	return changes
+}
+
+// recordConfigVersion adds a version of the configuration in the transaction, noting the
+// fields changed from the version before it.
+func recordConfigVersion(tx *gorm.DB, config *Config, author, note string) error {
+	var previous *Config
+	var last ConfigVersion
+	err := tx.Order("id desc").Limit(1).Find(&last).Error
+	if err != nil {
+		return fmt.Errorf("failed to retrieve configuration history: %w", err)
+	}
+	if last.ID != 0 {
+		lastConfig, err := last.Config()
+		if err != nil {
+			return err
+		}
+		previous = &lastConfig
+	}
+
+	version, err := newConfigVersion(config, previous)
+	if err != nil {
+		return err
+	}
+	version.Author = author
+	version.Note = note
+	if err := tx.Create(version).Error; err != nil {
+		return fmt.Errorf("failed to record configuration version: %w", err)
+	}
+	return nil
+}
+
+// ListConfigVersions returns the most recent configuration versions, newest first.
+func ListConfigVersions(limit int) ([]ConfigVersion, error) {
+	var versions []ConfigVersion
+	if err := db.Order("id desc").Limit(limit).Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve configuration history: %w", err)
+	}
+	return versions, nil
+}
+
+// GetConfigVersion retrieves a configuration version by ID.
+func GetConfigVersion(id uint) (*ConfigVersion, error) {
+	var version ConfigVersion
+	if err := db.First(&version, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve configuration version %d: %w", id, err)
+	}
+	return &version, nil
+}
+
+// migrateConfigHistory adds the configuration history table, with the configuration saved
+// before history was kept as its first version.
+func migrateConfigHistory(tx *gorm.DB) error {
+	type configVersion struct {
+		ID        uint      `gorm:"primaryKey"`
+		CreatedAt time.Time `gorm:"index"`
+		Author    string
+		Note      string
+		Changes   string
+		Data      string
+	}
+	if err := tx.Table("config_versions").AutoMigrate(&configVersion{}); err != nil {
+		return err
+	}
+	var count int64
+	if err := tx.Table("config_versions").Count(&count).Error; err != nil {
+		return err
+	}
+	var config Config
+	if err := tx.Limit(1).Find(&config, 1).Error; err != nil {
+		return err
+	}
+	if count > 0 || config.ID == 0 {
+		return nil
+	}
+	return recordConfigVersion(tx, &config, "", "Configuration before history was kept")
+}
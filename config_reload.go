@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// clientReloadMu keeps Telegram re-initializations from overlapping when the configuration is
+// saved twice in quick succession.
+var clientReloadMu sync.Mutex
+
+// binanceClient is the main account's client. Saving the configuration can replace it while
+// webhooks and Telegram updates use it, so it is read with mainBinanceClient.
+var binanceClient atomic.Pointer[BinanceClient]
+
+// watchConfig makes configuration changes take effect without a restart. Settings read through
+// GetGlobalConfig, such as the summary hour or the order ID prefix, apply on their next use;
+// the Telegram bot and Binance clients are replaced when their keys change.
+func watchConfig() {
+	onConfigChange(reloadClients)
+}
+
+// reloadClients replaces the Telegram bot when its token changes, which also replaces the Binance
+// clients, or just the Binance clients when the Binance keys or API URL change. Other changes
+// keep the bot running, so saving the configuration doesn't interrupt Telegram updates.
+func reloadClients(previous, current Config) {
+	telegramChanged := current.TelegramBotToken != previous.TelegramBotToken || (bot == nil && current.TelegramBotToken != "")
+	binanceChanged := current.BinanceAPIKey != previous.BinanceAPIKey || current.BinanceAPISecret != previous.BinanceAPISecret ||
+		current.BinanceAPIURL != previous.BinanceAPIURL
+
+	switch {
+	case telegramChanged:
+		// Creating the bot asks Telegram about the token, so don't hold up the caller
+		go func() {
+			clientReloadMu.Lock()
+			defer clientReloadMu.Unlock()
+			botInstance, err := initTelegramBot(&current)
+			if err != nil {
+				slog.Error("Error initializing Telegram bot", "error", err)
+				return
+			}
+			bot = botInstance
+		}()
+	case binanceChanged:
+		clientReloadMu.Lock()
+		defer clientReloadMu.Unlock()
+		slog.Info("Reloading Binance clients with new configuration")
+		client := NewBinanceClient(bot)
+		setBinanceClient(client)
+		// The old client's user data stream stopped with it, so open positions are picked up
+		// and followed with the new one
+		client.safeGo("reconcileOpenPositions", func() {
+			if err := client.reconcileOpenPositions(current.TelegramChatID); err != nil {
+				binanceLog.Error("Failed to reconcile open positions", "error", err)
+			}
+		})
+		// Additional accounts and users' own keys are recreated against the new API URL
+		if current.BinanceAPIURL != previous.BinanceAPIURL {
+			accountClients.Clear()
+			userClients.Clear()
+		}
+	}
+}
+
+// setBinanceClient makes client the main account's client, closing the one it replaces. Trades
+// already placed with the old client finish.
+func setBinanceClient(client *BinanceClient) {
+	previous := binanceClient.Swap(client)
+	if previous != nil && previous != client {
+		previous.Close()
+	}
+}
+
+// mainBinanceClient returns the main account's client, nil until the Telegram bot has started.
+func mainBinanceClient() *BinanceClient {
+	return binanceClient.Load()
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback actions for the two-step confirmation summary.
+const (
+	ActionExecute = "exec"
+	ActionBack    = "back"
+)
+
+// Two-step confirmations auto-cancel after confirmTimeout; the countdown is refreshed every confirmTick.
+const (
+	confirmTimeout = 30 * time.Second
+	confirmTick    = 10 * time.Second
+)
+
+// PendingConfirmation is a signal whose Confirm was pressed and awaits Execute.
+type PendingConfirmation struct {
+	UserID   int64     // User who pressed Confirm; only they can execute
+	Summary  string    // Trade summary shown above the countdown
+	Deadline time.Time // When the confirmation auto-cancels
+	done     chan struct{}
+}
+
+// PendingConfirmationStore manages pending confirmations by signal ID with concurrency safety.
+type PendingConfirmationStore struct {
+	sync.RWMutex
+	pending map[string]*PendingConfirmation
+}
+
+// NewPendingConfirmationStore creates a new instance of PendingConfirmationStore.
+func NewPendingConfirmationStore() *PendingConfirmationStore {
+	return &PendingConfirmationStore{
+		pending: make(map[string]*PendingConfirmation),
+	}
+}
+
+func (s *PendingConfirmationStore) Set(signalID string, p *PendingConfirmation) {
+	s.Lock()
+	defer s.Unlock()
+	s.pending[signalID] = p
+}
+
+func (s *PendingConfirmationStore) Get(signalID string) (*PendingConfirmation, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	p, exists := s.pending[signalID]
+	return p, exists
+}
+
+// Take removes and returns the pending confirmation, stopping its countdown. Only one caller
+// gets it, so a late Execute cannot race the auto-cancel.
+func (s *PendingConfirmationStore) Take(signalID string) (*PendingConfirmation, bool) {
+	s.Lock()
+	defer s.Unlock()
+	p, exists := s.pending[signalID]
+	if exists {
+		delete(s.pending, signalID)
+		close(p.done)
+	}
+	return p, exists
+}
+
+var pendingConfirmations = NewPendingConfirmationStore()
+
+// toggleTwoStepConfirm toggles the trade summary step between Confirm and execution.
+func toggleTwoStepConfirm(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.TwoStepConfirm = !settings.TwoStepConfirm
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Two-Step Confirm has been %s.", enabledText(chatID, settings.TwoStepConfirm)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
+
+// requestConfirmation replaces the signal keyboard with a trade summary and Execute/Back buttons
+// that auto-cancel after confirmTimeout.
+func requestConfirmation(chatID, userID int64, messageID int, signalID string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+	if _, pending := pendingConfirmations.Get(signalID); pending {
+		return // Confirm pressed again while the summary is shown
+	}
+
+	p := &PendingConfirmation{
+		UserID:   userID,
+		Summary:  tradeSummary(chatID, userID, signal),
+		Deadline: time.Now().Add(confirmTimeout),
+		done:     make(chan struct{}),
+	}
+	pendingConfirmations.Set(signalID, p)
+	showConfirmationCountdown(chatID, messageID, signalID, p)
+	go runConfirmationCountdown(chatID, messageID, signalID, p)
+}
+
+// tradeSummary describes the order a confirmation by userID would place: size, leverage,
+// margin used and the liquidation estimate.
+func tradeSummary(chatID, userID int64, signal *AlertMessage) string {
+	settingsChat := settingsChatID(chatID, userID)
+	settings := applySignalOverrides(signal, applySymbolOverride(settingsChat, signal.Symbol, signalSettings(settingsChat, signal)))
+	routed := *signal // tradingClient records the account on the signal, which must not change the stored one
+	client, err := tradingClient(userID, &routed)
+	if errors.Is(err, errNotBinance) {
+		// Order estimates use Binance's symbol rules and brackets; a user's own account isn't recorded on the signal
+		account := routed.Account
+		if account == "" {
+			account = personalAccountName
+		}
+		return tr(chatID, "<b>Account:</b> %s (Bybit)\n", account) + mirrorSummary(chatID, account)
+	}
+	if err != nil {
+		return tr(chatID, "Account %s is unavailable: %v", routed.Account, err)
+	}
+
+	estimate, err := client.estimateOrder(context.Background(), signal, settings)
+	if err != nil {
+		binanceLog.Warn("Failed to estimate order", "signal_id", signal.SignalID, "symbol", signal.Symbol, "error", err)
+		return tr(chatID, "Could not estimate the order: %v", err)
+	}
+
+	text := tr(chatID, "<b>Account:</b> %s\n", routed.Account)
+	text += tr(chatID, "<b>Order Size:</b> %s %s (%.2f USDT)\n", estimate.Quantity, signal.Symbol, estimate.Notional)
+	text += tr(chatID, "<b>Leverage:</b> %dx %s\n", estimate.Leverage, settings.MarginMode)
+	text += tr(chatID, "<b>Margin Used:</b> %.2f USDT\n", estimate.Margin())
+	if estimate.Liquidation > 0 {
+		text += tr(chatID, "<b>Est. Liquidation Price:</b> %s (isolated)\n", formatFloat(estimate.Liquidation))
+	}
+	return text + mirrorSummary(chatID, routed.Account)
+}
+
+// showConfirmationCountdown renders the signal with its trade summary and the time left to execute.
+func showConfirmationCountdown(chatID int64, messageID int, signalID string, p *PendingConfirmation) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		return
+	}
+
+	remaining := time.Until(p.Deadline).Round(time.Second)
+	text := constructSignalMessageText(signal)
+	text += tr(chatID, "\n\n<b>Review before executing</b>\n")
+	text += p.Summary
+	text += tr(chatID, "\nAuto-cancels in %d seconds.", int(remaining.Seconds()))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Execute"), fmt.Sprintf("%s|%s", ActionExecute, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Back"), fmt.Sprintf("%s|%s", ActionBack, signalID)),
+		),
+	)
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = &keyboard
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
+
+// runConfirmationCountdown refreshes the countdown until the confirmation is taken or expires,
+// then restores the signal keyboard.
+func runConfirmationCountdown(chatID int64, messageID int, signalID string, p *PendingConfirmation) {
+	ticker := time.NewTicker(confirmTick)
+	defer ticker.Stop()
+	timeout := time.NewTimer(time.Until(p.Deadline))
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			if _, ok := pendingConfirmations.Get(signalID); !ok {
+				return
+			}
+			showConfirmationCountdown(chatID, messageID, signalID, p)
+		case <-timeout.C:
+			if _, ok := pendingConfirmations.Take(signalID); ok {
+				restoreSignalMessage(chatID, messageID, signalID)
+				bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Confirmation timed out. The signal was not executed.")))
+			}
+			return
+		}
+	}
+}
+
+// executeConfirmation runs a pending confirmation if userID is the user who pressed Confirm.
+func executeConfirmation(chatID, userID int64, messageID int, signalID string) {
+	p, exists := pendingConfirmations.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This confirmation has expired. Press Confirm again.")))
+		return
+	}
+	if p.UserID != userID {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Only the user who pressed Confirm can execute this signal.")))
+		return
+	}
+	if _, ok := pendingConfirmations.Take(signalID); !ok {
+		return // Expired in the meantime
+	}
+	confirmSignal(chatID, userID, messageID, signalID)
+}
+
+// cancelConfirmation returns from the summary to the signal keyboard.
+func cancelConfirmation(chatID int64, messageID int, signalID string) {
+	if _, ok := pendingConfirmations.Take(signalID); ok {
+		restoreSignalMessage(chatID, messageID, signalID)
+	}
+}
+
+// restoreSignalMessage shows the signal with its regular keyboard again.
+func restoreSignalMessage(chatID int64, messageID int, signalID string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, constructSignalMessageText(signal))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = createSignalInlineKeyboard(chatID, signalID)
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
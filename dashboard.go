@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dashboardRefreshSeconds is how often the admin dashboard reloads itself.
+const dashboardRefreshSeconds = 30
+
+// dashboardTimeout bounds the Telegram and Binance requests made for each dashboard view.
+const dashboardTimeout = 5 * time.Second
+
+// Webhook request outcomes counted for the dashboard.
+const (
+	WebhookAccepted = "accepted" // Sent to Telegram
+	WebhookRejected = "rejected" // Wrong method, unreadable or invalid alert
+	WebhookFailed   = "failed"   // Valid alert that could not be sent
+)
+
+// WebhookCounts are the webhook requests by outcome since the bot started.
+type WebhookCounts struct {
+	Accepted int
+	Rejected int
+	Failed   int
+	LastAt   time.Time // When the last request arrived, zero if none has
+}
+
+// Total returns the number of webhook requests.
+func (c WebhookCounts) Total() int {
+	return c.Accepted + c.Rejected + c.Failed
+}
+
+// WebhookStats counts webhook requests with concurrency safety.
+type WebhookStats struct {
+	sync.Mutex
+	counts WebhookCounts
+}
+
+// NewWebhookStats creates a new instance of WebhookStats.
+func NewWebhookStats() *WebhookStats {
+	return &WebhookStats{}
+}
+
+// Record counts a webhook request with its outcome.
+func (s *WebhookStats) Record(outcome string) {
+	s.Lock()
+	defer s.Unlock()
+	switch outcome {
+	case WebhookAccepted:
+		s.counts.Accepted++
+	case WebhookRejected:
+		s.counts.Rejected++
+	case WebhookFailed:
+		s.counts.Failed++
+	}
+	s.counts.LastAt = time.Now()
+}
+
+// Counts returns the current counts.
+func (s *WebhookStats) Counts() WebhookCounts {
+	s.Lock()
+	defer s.Unlock()
+	return s.counts
+}
+
+var webhookStats = NewWebhookStats()
+
+// ConnectionStatus is the result of checking a connection to Telegram or Binance.
+type ConnectionStatus struct {
+	OK      bool
+	Idle    bool   // Not started yet, which is not a failure
+	Detail  string // Bot username or error
+	Latency time.Duration
+}
+
+// DashboardPosition is an open position on the main Binance account.
+type DashboardPosition struct {
+	Symbol        string
+	Side          string
+	Amount        float64
+	EntryPrice    float64
+	MarkPrice     float64
+	UnrealizedPnL float64
+}
+
+// DashboardStats is what the admin dashboard shows.
+type DashboardStats struct {
+	Positions      []DashboardPosition
+	UnrealizedPnL  float64
+	PendingSignals []*AlertMessage
+	Trades         []Trade // Closed in the last 24 hours, newest first
+	Wins           int
+	NetProfit      float64
+	Fees           float64
+	Webhooks       WebhookCounts
+	Telegram       ConnectionStatus
+	Binance        ConnectionStatus
+	OrderMonitor   ConnectionStatus
+	Exposure       *Exposure // Nil when the balance is unavailable
+}
+
+// collectDashboardStats gathers the dashboard figures. Failures are shown on the dashboard
+// rather than failing the page, since it is most useful when something is broken.
+func collectDashboardStats() DashboardStats {
+	stats := DashboardStats{
+		PendingSignals: signalStore.Recent(func(signal *AlertMessage) bool {
+			return !signal.Confirmed && !signal.Dismissed && !signal.Filtered
+		}),
+		Webhooks: webhookStats.Counts(),
+		Telegram: checkTelegram(),
+	}
+	stats.Positions, stats.Binance = openPositions()
+	stats.OrderMonitor = orderMonitorStatus()
+	for _, position := range stats.Positions {
+		stats.UnrealizedPnL += position.UnrealizedPnL
+	}
+	if stats.Binance.OK {
+		stats.Exposure = dashboardExposure(stats.Positions)
+	}
+
+	now := time.Now()
+	trades, err := GetTradesBetween(now.Add(-24*time.Hour), now)
+	if err != nil {
+		adminLog.Error("Error fetching dashboard trades", "error", err)
+	}
+	sort.Slice(trades, func(i, j int) bool { return trades[i].Timestamp.After(trades[j].Timestamp) })
+	stats.Trades = trades
+	for _, trade := range trades {
+		if trade.Profit > 0 {
+			stats.Wins++
+		}
+		stats.NetProfit += trade.Profit
+		stats.Fees += trade.Fees()
+	}
+	return stats
+}
+
+// checkTelegram checks that the bot token still works.
+func checkTelegram() ConnectionStatus {
+	if bot == nil {
+		return ConnectionStatus{Detail: "Bot is not initialized"}
+	}
+	// GetMe has no timeout of its own
+	result := make(chan ConnectionStatus, 1)
+	start := time.Now()
+	go func() {
+		me, err := bot.GetMe()
+		if err != nil {
+			result <- ConnectionStatus{Detail: err.Error(), Latency: time.Since(start)}
+			return
+		}
+		result <- ConnectionStatus{OK: true, Detail: "@" + me.UserName, Latency: time.Since(start)}
+	}()
+	select {
+	case status := <-result:
+		return status
+	case <-time.After(dashboardTimeout):
+		return ConnectionStatus{Detail: "request timed out", Latency: dashboardTimeout}
+	}
+}
+
+// orderMonitorStatus describes the main account's user data stream, which reconnects on its
+// own after it drops.
+func orderMonitorStatus() ConnectionStatus {
+	client := mainBinanceClient()
+	if client == nil {
+		return ConnectionStatus{Detail: "Binance is not configured"}
+	}
+	started, connected, lastEvent := client.monitorState.Snapshot()
+	switch {
+	case !started:
+		return ConnectionStatus{Idle: true, Detail: "Starts with the first trade"}
+	case !connected:
+		failures := client.monitorState.Failures()
+		return ConnectionStatus{Detail: fmt.Sprintf("Reconnecting after %d failed attempts", failures)}
+	case lastEvent.IsZero():
+		return ConnectionStatus{OK: true, Detail: "No events yet"}
+	}
+	return ConnectionStatus{OK: true, Detail: "Last event " + lastEvent.UTC().Format("2006-01-02 15:04:05") + " UTC"}
+}
+
+// dashboardExposure calculates the main account's exposure from the dashboard's positions and
+// its balance, returning nil if the balance can't be fetched.
+func dashboardExposure(positions []DashboardPosition) *Exposure {
+	client, err := accountClient(defaultAccountName)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardTimeout)
+	defer cancel()
+	balances, err := client.Balance(ctx)
+	if err != nil {
+		adminLog.Error("Error fetching dashboard balance", "error", err)
+		return nil
+	}
+	open := make([]ExchangePosition, len(positions))
+	for i, position := range positions {
+		open[i] = ExchangePosition{Symbol: position.Symbol, Amount: position.Amount, MarkPrice: position.MarkPrice}
+	}
+	exposure := calculateExposure(open, balances, GetGlobalConfig().ExposureLimitPercent)
+	return &exposure
+}
+
+// openPositions returns the main account's open USDT-M positions, largest unrealized PnL
+// first, and the status of the request as the Binance connectivity check.
+func openPositions() ([]DashboardPosition, ConnectionStatus) {
+	client, err := accountClient(defaultAccountName)
+	if err != nil {
+		return nil, ConnectionStatus{Detail: err.Error()}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardTimeout)
+	defer cancel()
+	start := time.Now()
+	risks, err := client.Client.NewGetPositionRiskService().Do(ctx)
+	used, limit := binanceBudget.Usage(hostOf(client.Client.BaseURL))
+	detail := fmt.Sprintf("Connected, request weight %d of %d this minute", used, limit)
+	status := ConnectionStatus{OK: err == nil, Detail: detail, Latency: time.Since(start)}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = errors.New("request timed out")
+		}
+		status.Detail = err.Error()
+		return nil, status
+	}
+
+	var positions []DashboardPosition
+	for _, risk := range risks {
+		amount, _ := strconv.ParseFloat(risk.PositionAmt, 64)
+		if amount == 0 {
+			continue
+		}
+		position := DashboardPosition{Symbol: risk.Symbol, Side: "Long", Amount: amount}
+		if amount < 0 {
+			position.Side = "Short"
+		}
+		position.EntryPrice, _ = strconv.ParseFloat(risk.EntryPrice, 64)
+		position.MarkPrice, _ = strconv.ParseFloat(risk.MarkPrice, 64)
+		position.UnrealizedPnL, _ = strconv.ParseFloat(risk.UnRealizedProfit, 64)
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].UnrealizedPnL > positions[j].UnrealizedPnL })
+	return positions, status
+}
@@ -1,85 +1,266 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var db *gorm.DB
 
+// Database drivers selectable with the DB_DRIVER environment variable.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// defaultSQLiteDSN is the database file used when DB_DSN is not set for SQLite.
+const defaultSQLiteDSN = "config.db"
+
 // Signal represents a trading signal stored in the database.
 type Signal struct {
 	ID         uint   `gorm:"primaryKey"`
-	SignalID   string `gorm:"uniqueIndex"`
+	SignalID   string `gorm:"uniqueIndex;size:128"`
+	ChatID     int64
+	Symbol     string
+	Side       string `gorm:"size:8"` // Buy or Sell; empty for signals stored before it was
+	Timeframe  string `gorm:"size:16"`
 	EntryPrice float64
-	TP1        float64
-	TP2        float64
-	TP3        float64
+	TPs        []float64 `gorm:"serializer:json"`
 	SL         float64
+	Strategy   string    `gorm:"index;size:64"` // Strategy tag, or the source if the signal has none
+	Status     string    `gorm:"index;size:16"` // Lifecycle status, e.g. SignalReceived
+	StatusAt   time.Time // When the signal reached its status
 	Timestamp  time.Time `gorm:"autoCreateTime"`
 }
 
 // Trade represents a trade result stored in the database.
 type Trade struct {
-	ID         uint   `gorm:"primaryKey"`
-	SignalID   string `gorm:"index"`
-	EntryPrice float64
-	ExitPrice  float64
-	Profit     float64
-	Timestamp  time.Time `gorm:"autoCreateTime"`
+	ID          uint   `gorm:"primaryKey"`
+	SignalID    string `gorm:"index;size:128"`
+	Symbol      string
+	Side        string // Side of the entry order, BUY or SELL
+	Strategy    string `gorm:"index;size:64"` // Strategy of the trade's signal
+	EntryPrice  float64
+	ExitPrice   float64
+	GrossProfit float64 // Realized PnL before fees
+	MakerFees   float64
+	TakerFees   float64
+	Profit      float64   // Net profit after fees
+	InitialRisk float64   // Loss at the SL when the position opened, 0 if it had no SL
+	OpenedAt    time.Time // Zero if the position was restored on startup
+	Timestamp   time.Time `gorm:"autoCreateTime"`
+	ImportID    string    `gorm:"index;size:64"` // Symbol and last trade ID of trades imported by /import
+}
+
+// Duration returns how long the position was open, or zero if unknown.
+func (t *Trade) Duration() time.Duration {
+	if t.OpenedAt.IsZero() {
+		return 0
+	}
+	return t.Timestamp.Sub(t.OpenedAt)
+}
+
+// Fees returns the total fees paid for the trade.
+func (t *Trade) Fees() float64 {
+	return t.MakerFees + t.TakerFees
+}
+
+// databaseSettings returns the DB_DRIVER and DB_DSN environment variables, defaulting to the
+// config.db SQLite file.
+func databaseSettings() (driver, dsn string) {
+	driver = strings.ToLower(os.Getenv("DB_DRIVER"))
+	dsn = os.Getenv("DB_DSN")
+	if driver == "" {
+		driver = DriverSQLite
+	}
+	if driver == DriverSQLite && dsn == "" {
+		dsn = defaultSQLiteDSN
+	}
+	return driver, dsn
 }
 
-// initDatabase initializes the database connection and migrates the schema.
-func initDatabase() error {
-	var err error
-	db, err = gorm.Open(sqlite.Open("config.db"), &gorm.Config{})
+// databaseDialector picks the database from the DB_DRIVER and DB_DSN environment variables.
+// Without them the bot uses the config.db SQLite file.
+func databaseDialector() (gorm.Dialector, error) {
+	driver, dsn := databaseSettings()
+	if dsn == "" {
+		return nil, fmt.Errorf("DB_DSN must be set for the %s driver", driver)
+	}
+
+	switch driver {
+	case DriverSQLite:
+		return sqlite.Open(sqliteWriterDSN(dsn)), nil
+	case DriverPostgres:
+		return postgres.Open(dsn), nil
+	case DriverMySQL:
+		// Timestamps are only scanned into time.Time with parseTime
+		if !strings.Contains(dsn, "parseTime=") {
+			return nil, errors.New("the MySQL DB_DSN must include parseTime=true")
+		}
+		return mysql.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q, use sqlite, postgres or mysql", driver)
+	}
+}
+
+// initDatabase initializes the database connection and applies pending migrations if migrate
+// is set or MIGRATE_ON_STARTUP allows it. Otherwise it fails if migrations are pending.
+func initDatabase(migrate bool) error {
+	dialector, err := databaseDialector()
+	if err != nil {
+		return err
+	}
+	db, err = gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
-
-	// Migrate the schema
-	if err := db.AutoMigrate(&Config{}, &Signal{}, &Trade{}); err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+	if driver, dsn := databaseSettings(); driver == DriverSQLite {
+		if err := configureSQLite(db, dsn); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	if migrate || migrateOnStartup() {
+		return applyMigrations()
+	}
+	return checkMigrations()
 }
 
-// StoreSignal saves a trading signal to the database.
-func StoreSignal(signalID string, entryPrice, tp1, tp2, tp3, sl float64) error {
-	signal := Signal{
-		SignalID:   signalID,
-		EntryPrice: entryPrice,
-		TP1:        tp1,
-		TP2:        tp2,
-		TP3:        tp3,
-		SL:         sl,
+// StoreSignal saves a trading signal and its current prices to the database, keeping its status.
+func StoreSignal(alert *AlertMessage) error {
+	var signal Signal
+	err := db.Where("signal_id = ?", alert.SignalID).First(&signal).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to retrieve signal: %w", err)
 	}
+	signal.SignalID = alert.SignalID
+	signal.ChatID = alert.ChatID
+	signal.Symbol = alert.Symbol
+	signal.Side = alert.SignalType
+	signal.Timeframe = alert.Timeframe
+	signal.EntryPrice = alert.EntryPrice
+	signal.TPs = alert.TPs
+	signal.SL = alert.SL
+	signal.Strategy = signalStrategy(alert)
 
-	if err := db.Create(&signal).Error; err != nil {
+	if err := db.Save(&signal).Error; err != nil {
 		return fmt.Errorf("failed to store signal: %w", err)
 	}
 	return nil
 }
 
-// StoreTrade saves a trade result to the database.
-func StoreTrade(signalID string, entryPrice, exitPrice, profit float64) error {
-	trade := Trade{
-		SignalID:   signalID,
-		EntryPrice: entryPrice,
-		ExitPrice:  exitPrice,
-		Profit:     profit,
+// GetSignal retrieves a stored signal by its signal ID.
+func GetSignal(signalID string) (*Signal, error) {
+	var signal Signal
+	if err := db.Where("signal_id = ?", signalID).First(&signal).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve signal: %w", err)
+	}
+	return &signal, nil
+}
+
+// maxOrderSignalCandidates caps the stored signals findOrderSignalID compares an order with.
+const maxOrderSignalCandidates = 50
+
+// findOrderSignalID returns the ID of the stored signal whose client order ID with tag is
+// clientID, where short is the signal ID as it appears in clientID. It returns short if no stored
+// signal matches.
+func findOrderSignalID(clientID, short, tag string) (string, error) {
+	var count int64
+	if err := db.Model(&Signal{}).Where("signal_id = ?", short).Count(&count).Error; err != nil {
+		return "", fmt.Errorf("failed to retrieve signal: %w", err)
+	}
+	if count > 0 {
+		return short, nil
 	}
 
-	if err := db.Create(&trade).Error; err != nil {
+	// Characters may have been dropped anywhere in the signal ID, and its end cut off. Only
+	// underscores need escaping in the pattern, as the rest are letters and digits.
+	var pattern strings.Builder
+	pattern.WriteString("%")
+	for _, r := range short {
+		if r == '_' {
+			pattern.WriteString("!")
+		}
+		pattern.WriteRune(r)
+		pattern.WriteString("%")
+	}
+	var candidates []string
+	err := db.Model(&Signal{}).Where("signal_id LIKE ? ESCAPE '!'", pattern.String()).
+		Order("id DESC").Limit(maxOrderSignalCandidates).Pluck("signal_id", &candidates).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve signals: %w", err)
+	}
+	for _, candidate := range candidates {
+		if clientOrderID(candidate, tag) == clientID {
+			return candidate, nil
+		}
+	}
+	return short, nil
+}
+
+// SignalFilter selects stored signals; zero fields match every signal.
+type SignalFilter struct {
+	Status string
+	Symbol string
+	From   time.Time // Received at or after
+	To     time.Time // Received before
+}
+
+// ListSignals retrieves the most recent stored signals matching the filter, newest first.
+func ListSignals(filter SignalFilter, limit int) ([]Signal, error) {
+	query := db.Order("id desc").Limit(limit)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Symbol != "" {
+		query = query.Where("symbol = ?", filter.Symbol)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("timestamp >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("timestamp < ?", filter.To)
+	}
+	var signals []Signal
+	if err := query.Find(&signals).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve signals: %w", err)
+	}
+	return signals, nil
+}
+
+// StoreTrade saves a trade result to the database. The net profit is the gross profit less fees.
+func StoreTrade(trade *Trade) error {
+	trade.Profit = trade.GrossProfit - trade.Fees()
+
+	if err := db.Create(trade).Error; err != nil {
 		return fmt.Errorf("failed to store trade: %w", err)
 	}
 	return nil
 }
 
+// GetRecentTrades retrieves a page of trades, newest first, along with the total number of trades.
+func GetRecentTrades(offset, limit int) ([]Trade, int64, error) {
+	var total int64
+	if err := db.Model(&Trade{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trades: %w", err)
+	}
+
+	var trades []Trade
+	if err := db.Order("timestamp desc, id desc").Offset(offset).Limit(limit).Find(&trades).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve trades: %w", err)
+	}
+	return trades, total, nil
+}
+
 // GetTradesForPeriod retrieves trades from the database for a given period.
 func GetTradesForPeriod(period string) ([]Trade, error) {
 	var trades []Trade
@@ -92,6 +273,17 @@ func GetTradesForPeriod(period string) ([]Trade, error) {
 	return trades, nil
 }
 
+// GetTradesBetween retrieves trades recorded in [from, to).
+func GetTradesBetween(from, to time.Time) ([]Trade, error) {
+	var trades []Trade
+
+	if err := db.Where("timestamp >= ? AND timestamp < ?", from, to).Find(&trades).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve trades: %w", err)
+	}
+
+	return trades, nil
+}
+
 // calculateStartTime calculates the start time for a given period.
 func calculateStartTime(period string) time.Time {
 	now := time.Now()
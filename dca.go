@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// OrderTagDCA prefixes the tags of DCA ladder orders, e.g. "dca1", "dca2".
+const OrderTagDCA = "dca"
+
+// maxDCAOrders caps the number of ladder orders a user can configure.
+const maxDCAOrders = 10
+
+// DCALadder holds the state needed to re-price TPs as ladder orders fill.
+type DCALadder struct {
+	Signal   AlertMessage // Copy of the signal as executed
+	Settings UserSettings // Copy of the settings used for the trade
+	Tags     []string     // Tags of the ladder orders placed
+	Filled   int
+}
+
+// DCALadderStore manages DCA ladders by account and symbol with concurrency safety.
+type DCALadderStore struct {
+	sync.RWMutex
+	ladders map[positionKey]*DCALadder
+}
+
+// NewDCALadderStore creates a new instance of DCALadderStore.
+func NewDCALadderStore() *DCALadderStore {
+	return &DCALadderStore{
+		ladders: make(map[positionKey]*DCALadder),
+	}
+}
+
+func (s *DCALadderStore) Set(key positionKey, ladder *DCALadder) {
+	s.Lock()
+	defer s.Unlock()
+	s.ladders[key] = ladder
+}
+
+func (s *DCALadderStore) Get(key positionKey) (*DCALadder, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	ladder, exists := s.ladders[key]
+	return ladder, exists
+}
+
+func (s *DCALadderStore) Delete(key positionKey) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.ladders, key)
+}
+
+// All returns a copy of every ladder by account and symbol.
+func (s *DCALadderStore) All() map[positionKey]DCALadder {
+	s.RLock()
+	defer s.RUnlock()
+	ladders := make(map[positionKey]DCALadder, len(s.ladders))
+	for key, ladder := range s.ladders {
+		ladders[key] = *ladder
+	}
+	return ladders
+}
+
+var dcaLadders = NewDCALadderStore()
+
+// dcaLevelPrices returns the ladder prices for an entry, each a further step against the position.
+// Levels at or beyond the stop loss are dropped since they would never fill before the SL.
+func dcaLevelPrices(signal *AlertMessage, settings *UserSettings) []float64 {
+	step := settings.DCAStepPercentage / 100.0
+	if step <= 0 || signal.EntryPrice <= 0 {
+		return nil
+	}
+
+	var prices []float64
+	for i := 1; i <= settings.DCAMaxOrders; i++ {
+		var price float64
+		if signal.SignalType == "Sell" {
+			price = signal.EntryPrice * (1 + step*float64(i))
+			if signal.SL > 0 && price >= signal.SL {
+				break
+			}
+		} else {
+			price = signal.EntryPrice * (1 - step*float64(i))
+			if price <= 0 || (signal.SL > 0 && price <= signal.SL) {
+				break
+			}
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+// placeDCALadder places limit orders of the entry quantity at each DCA level below (long)
+// or above (short) the entry, and remembers the ladder so TPs can follow the average entry.
+func (b *BinanceClient) placeDCALadder(ctx context.Context, symbol string, side futures.SideType, quantity string, signal *AlertMessage, settings *UserSettings, userID int64) error {
+	prices := dcaLevelPrices(signal, settings)
+	if len(prices) == 0 {
+		b.sendMessageToUser(userID, tr(userID, "No DCA levels fit between entry and SL for %s.", symbol))
+		return nil
+	}
+
+	// A ladder left from an earlier signal on the position would be forgotten and never cancelled
+	if earlier, exists := dcaLadders.Get(b.key(symbol)); exists && earlier.Signal.SignalID != signal.SignalID {
+		b.cancelDCALadder(symbol, userID)
+	}
+	ladder := &DCALadder{Signal: *signal, Settings: *settings}
+	var levels []string
+	for i, price := range prices {
+		tag := fmt.Sprintf("%s%d", OrderTagDCA, i+1)
+		if err := b.placeLimitOrder(ctx, symbol, side, quantity, price, clientOrderID(signal.SignalID, tag)); err != nil {
+			// Keep whatever was placed so it is still tracked and cancelled on close
+			if len(ladder.Tags) > 0 {
+				dcaLadders.Set(b.key(symbol), ladder)
+			}
+			return fmt.Errorf("failed to place DCA order %d: %v", i+1, err)
+		}
+		ladder.Tags = append(ladder.Tags, tag)
+		levels = append(levels, formatFloat(price))
+	}
+	dcaLadders.Set(b.key(symbol), ladder)
+
+	b.sendMessageToUser(userID, tr(userID, "DCA ladder placed for %s: %d order(s) of %s at %s.",
+		symbol, len(prices), quantity, strings.Join(levels, ", ")))
+	return nil
+}
+
+// isDCAOrder reports whether a client order ID belongs to a DCA ladder order.
+func isDCAOrder(clientID string) bool {
+	_, tag, ok := parseClientOrderID(clientID)
+	return ok && strings.HasPrefix(tag, OrderTagDCA)
+}
+
+// handleDCAFill moves the TP orders to the new average entry after a ladder order fills. It makes
+// REST calls, so the user data stream runs it in a goroutine of its own.
+func (b *BinanceClient) handleDCAFill(update futures.WsOrderTradeUpdate, userID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	symbol := update.Symbol
+	ladder, exists := dcaLadders.Get(b.key(symbol))
+	if !exists {
+		return
+	}
+	position, exists := positionTracker.Get(b.key(symbol))
+	if !exists {
+		return
+	}
+	ladder.Filled++
+
+	// Recalculate TPs from the average entry with the same settings as the original trade
+	adjusted := ladder.Signal
+	adjusted.EntryPrice = position.AverageEntryPrice()
+	if ladder.Settings.AutoCalculateTPs {
+		recalcSingleTPAndSL(&adjusted, &ladder.Settings)
+	} else {
+		recalcManualTPAndSL(&adjusted, &ladder.Settings)
+	}
+
+	// TPs that close part of the position are resized to their share of the larger one
+	remaining := strconv.FormatFloat(position.EntryQty-position.ExitQty, 'f', -1, 64)
+	partials, err := splitTPQuantities(context.Background(), b, symbol, remaining, placedTPs(adjusted.TPs, &ladder.Settings), &ladder.Settings)
+	if err != nil {
+		b.sendMessageToUser(userID, tr(userID, "Failed to resize the partial TPs for %s after DCA fill: %v", symbol, err))
+		return
+	}
+
+	tpSide := invertSide(position.Side)
+	var moved []string
+	for i, tpPrice := range adjusted.TPs {
+		if tpPrice <= 0 {
+			continue
+		}
+		tag := tpOrderTag(i)
+		clientID := clientOrderID(ladder.Signal.SignalID, tag)
+		res, err := b.Client.NewCancelOrderService().
+			Symbol(symbol).
+			OrigClientOrderID(clientID).
+			Do(context.Background())
+		auditOrder(AuditCancelOrder, clientID, "symbol="+symbol, res, err)
+		if err != nil {
+			// The TP may already have filled or been cancelled, so only replace the ones we
+			// cancelled; auditOrder logged it
+			continue
+		}
+		if i < len(partials) && partials[i] != "" {
+			err = b.placePartialTPOrder(context.Background(), symbol, tpSide, partials[i], tpPrice, clientID, workingType(&ladder.Settings))
+		} else {
+			err = b.placeTPOrder(context.Background(), symbol, tpSide, "", tpPrice, clientID, workingType(&ladder.Settings))
+		}
+		if err != nil {
+			b.sendMessageToUser(userID, tr(userID, "Failed to replace %s for %s after DCA fill: %v", strings.ToUpper(tag), symbol, err))
+			continue
+		}
+		moved = append(moved, fmt.Sprintf("%s %s", strings.ToUpper(tag), formatFloat(tpPrice)))
+	}
+
+	msg := tr(userID, "DCA order %d/%d filled for %s.\nNew average entry: %s",
+		ladder.Filled, len(ladder.Tags), symbol, formatFloat(adjusted.EntryPrice))
+	if len(moved) > 0 {
+		msg += tr(userID, "\nTPs moved to: %s", strings.Join(moved, ", "))
+	}
+	b.sendMessageToUser(userID, msg)
+}
+
+// cancelDCALadder cancels any unfilled ladder orders once the position has closed,
+// so they cannot reopen it.
+func (b *BinanceClient) cancelDCALadder(symbol string, userID int64) {
+	ladder, exists := dcaLadders.Get(b.key(symbol))
+	if !exists {
+		return
+	}
+	dcaLadders.Delete(b.key(symbol))
+	if ladder.Filled >= len(ladder.Tags) {
+		return
+	}
+
+	cancelled := 0
+	for _, tag := range ladder.Tags {
+		clientID := clientOrderID(ladder.Signal.SignalID, tag)
+		res, err := b.Client.NewCancelOrderService().
+			Symbol(symbol).
+			OrigClientOrderID(clientID).
+			Do(context.Background())
+		auditOrder(AuditCancelOrder, clientID, "symbol="+symbol, res, err)
+		if err != nil {
+			// Filled ladder orders can no longer be cancelled
+			continue
+		}
+		cancelled++
+	}
+	if cancelled > 0 {
+		b.sendMessageToUser(userID, tr(userID, "Cancelled %d unfilled DCA order(s) for %s.", cancelled, symbol))
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discordQueueSize bounds the messages waiting to be posted to Discord. Further messages are
+// dropped, so a slow or unreachable Discord doesn't hold up signals and trades.
+const discordQueueSize = 100
+
+// discordMaxLength is the longest message content Discord accepts.
+const discordMaxLength = 2000
+
+// discordMaxAttempts is how often a message is sent before it is given up, when Discord
+// rate limits it or fails with a server error.
+const discordMaxAttempts = 3
+
+// discordPost is a message waiting to be posted to a Discord webhook.
+type discordPost struct {
+	webhookURL string
+	content    string
+}
+
+var (
+	discordQueue  = make(chan discordPost, discordQueueSize)
+	discordStart  sync.Once
+	discordClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// validateDiscordWebhookURL checks that a Discord webhook URL is an HTTPS URL, as Discord
+// gives them out, e.g. https://discord.com/api/webhooks/<id>/<token>.
+func validateDiscordWebhookURL(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("Discord webhook URL must be an https:// URL")
+	}
+	return nil
+}
+
+// postToDiscord mirrors a message, formatted in Telegram's HTML, to the Discord channel of the
+// configured webhook. It does nothing without one, and returns without waiting for Discord.
+func postToDiscord(text string) {
+	webhookURL := GetGlobalConfig().DiscordWebhookURL
+	if webhookURL == "" {
+		return
+	}
+	discordStart.Do(func() {
+		go runDiscordQueue()
+	})
+
+	select {
+	case discordQueue <- discordPost{webhookURL: webhookURL, content: discordContent(text)}:
+	default:
+		telegramLog.Warn("Discord queue is full, dropping message")
+	}
+}
+
+// runDiscordQueue posts queued messages one at a time, so they arrive in order.
+func runDiscordQueue() {
+	for post := range discordQueue {
+		if err := sendDiscordPost(post); err != nil {
+			telegramLog.Warn("Failed to post message to Discord", "error", err)
+		}
+	}
+}
+
+// sendDiscordPost posts a message to a Discord webhook, waiting as long as Discord asks when
+// it rate limits the webhook.
+func sendDiscordPost(post discordPost) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"content": post.content,
+		// Signals and summaries mention symbols and users, never ping anyone with them
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := discordClient.Post(post.webhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retry || attempt == discordMaxAttempts {
+			return fmt.Errorf("Discord answered %s", resp.Status)
+		}
+		wait := time.Duration(attempt) * time.Second
+		if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+			wait = time.Duration(seconds * float64(time.Second))
+		}
+		time.Sleep(wait)
+	}
+}
+
+// postConfirmationToDiscord mirrors the outcome of a confirmed signal to Discord; err is the
+// error the trade failed with, or nil if it was executed.
+func postConfirmationToDiscord(signal *AlertMessage, userID int64, err error) {
+	account := signal.Account
+	if account == "" {
+		account = defaultAccountName
+	}
+	if err != nil {
+		postToDiscord(fmt.Sprintf("\u274C <b>%s %s</b> signal confirmed by user %d, but the trade failed: %s",
+			signal.Symbol, signal.SignalType, userID, handleBinanceError(GetGlobalConfig().TelegramChatID, err)))
+		return
+	}
+	postToDiscord(fmt.Sprintf("\u2705 <b>%s %s</b> signal confirmed by user %d and executed on the %s account.",
+		signal.Symbol, signal.SignalType, userID, account))
+}
+
+// telegramTags matches the HTML tags Telegram messages are formatted with.
+var telegramTags = regexp.MustCompile(`</?([a-z]+)[^>]*>`)
+
+// discordContent converts a message in Telegram's HTML to Discord's Markdown, shortened to
+// what Discord accepts.
+func discordContent(text string) string {
+	text = telegramTags.ReplaceAllStringFunc(text, func(tag string) string {
+		switch telegramTags.FindStringSubmatch(tag)[1] {
+		case "b", "strong":
+			return "**"
+		case "i", "em":
+			return "*"
+		case "code":
+			return "`"
+		case "pre":
+			return "```"
+		}
+		return ""
+	})
+	text = html.UnescapeString(text)
+
+	if runes := []rune(text); len(runes) > discordMaxLength {
+		text = string(runes[:discordMaxLength-1]) + "…"
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dualConfirmWindow is how long the first Confirm on a large trade waits for a second trader.
+const dualConfirmWindow = 5 * time.Minute
+
+// LargeTradeApproval is the first Confirm on a trade that needs two traders.
+type LargeTradeApproval struct {
+	UserID     int64
+	ApprovedAt time.Time
+}
+
+// LargeTradeApprovalStore manages first approvals by signal ID with concurrency safety.
+type LargeTradeApprovalStore struct {
+	sync.Mutex
+	approvals map[string]LargeTradeApproval
+}
+
+// NewLargeTradeApprovalStore creates a new instance of LargeTradeApprovalStore.
+func NewLargeTradeApprovalStore() *LargeTradeApprovalStore {
+	return &LargeTradeApprovalStore{
+		approvals: make(map[string]LargeTradeApproval),
+	}
+}
+
+// Approve records userID's Confirm and returns the user who approved first within
+// dualConfirmWindow, or 0 if this is the first approval. A second approval by a different
+// user uses up the first one.
+func (s *LargeTradeApprovalStore) Approve(key string, userID int64) int64 {
+	s.Lock()
+	defer s.Unlock()
+	first, exists := s.approvals[key]
+	if exists && time.Since(first.ApprovedAt) < dualConfirmWindow {
+		if first.UserID != userID {
+			delete(s.approvals, key)
+		}
+		return first.UserID
+	}
+	s.approvals[key] = LargeTradeApproval{UserID: userID, ApprovedAt: time.Now()}
+	return 0
+}
+
+var largeTradeApprovals = NewLargeTradeApprovalStore()
+
+// approvalKey returns the key approvals of a signal are stored under. Traders' private copies
+// of a broadcast signal share the original's key, so two traders can approve from their own chats.
+func approvalKey(chatID int64, signalID string) string {
+	return strings.TrimSuffix(signalID, fmt.Sprintf("_%d", chatID))
+}
+
+// approveLargeTrade applies the two-trader rule to a Confirm tap and reports whether the trade
+// may go ahead. Trades above DualConfirmNotional need Confirm from two different traders; the
+// first tap is recorded and tells the chat a second trader is needed.
+func approveLargeTrade(chatID, userID int64, signalID string) bool {
+	limit := GetGlobalConfig().DualConfirmNotional
+	signal, exists := signalStore.Get(signalID)
+	if limit <= 0 || !exists {
+		return true
+	}
+	settings := applySignalOverrides(signal, applySymbolOverride(chatID, signal.Symbol, signalSettings(chatID, signal)))
+	if settings.AmountUSDT <= limit {
+		return true
+	}
+
+	switch largeTradeApprovals.Approve(approvalKey(chatID, signalID), userID) {
+	case 0:
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID,
+			"This %.2f USDT trade is above the %.2f USDT limit. Another trader must also press Confirm within %d minutes.",
+			settings.AmountUSDT, limit, int(dualConfirmWindow.Minutes()))))
+		return false
+	case userID:
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "You already confirmed this trade. It needs a second trader.")))
+		return false
+	default:
+		return true
+	}
+}
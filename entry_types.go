@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entry order types a signal or its source can ask for instead of the user's Trading Mode.
+const (
+	EntryMarket   = "market"   // Market order at the current price
+	EntryLimit    = "limit"    // Limit order at the signal's entry
+	EntryMidpoint = "midpoint" // Limit order halfway between the signal's high and low
+	EntryStop     = "stop"     // Stop order beyond the high for longs or the low for shorts
+)
+
+// TradingModeStop is the trading mode of stop entries, which are triggered when the price
+// breaks out instead of filling right away.
+const TradingModeStop = "Stop"
+
+// entryTypes lists the entry order types in the order they are offered.
+var entryTypes = []string{EntryMarket, EntryLimit, EntryMidpoint, EntryStop}
+
+// validEntryType reports whether text is one of the entry order types.
+func validEntryType(text string) bool {
+	for _, entryType := range entryTypes {
+		if text == entryType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEntryTypes parses per-source entry order types such as "breakout=stop, tradingview=limit"
+// into entry types by lowercase source.
+func parseEntryTypes(text string) (map[string]string, error) {
+	types := make(map[string]string)
+	for _, part := range strings.Split(text, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		source, entryType, ok := strings.Cut(part, "=")
+		source = strings.ToLower(strings.TrimSpace(source))
+		if !ok || source == "" {
+			return nil, fmt.Errorf("expected SOURCE=TYPE, e.g. breakout=stop")
+		}
+		entryType = strings.ToLower(strings.TrimSpace(entryType))
+		if !validEntryType(entryType) {
+			return nil, fmt.Errorf("invalid entry type %q for %s, expected one of %s", entryType, source, strings.Join(entryTypes, ", "))
+		}
+		types[source] = entryType
+	}
+	return types, nil
+}
+
+// signalEntryType returns the entry order type of a signal: the one the alert asked for,
+// otherwise the one configured for its source, otherwise "" for the user's Trading Mode.
+func signalEntryType(signal *AlertMessage) string {
+	if entryType := strings.ToLower(signal.OrderType); validEntryType(entryType) {
+		return entryType
+	}
+	types, _ := parseEntryTypes(GetGlobalConfig().EntryTypeBySource)
+	return types[strings.ToLower(signal.Source)]
+}
+
+// entryTypeMode returns the trading mode an entry order type is placed with, or mode if the
+// signal has no entry type of its own.
+func entryTypeMode(entryType, mode string) string {
+	switch entryType {
+	case EntryMarket:
+		return "Market"
+	case EntryLimit, EntryMidpoint:
+		return "Limit"
+	case EntryStop:
+		return TradingModeStop
+	}
+	return mode
+}
+
+// entryOrderPrice returns the price the signal's entry order is placed at: the midpoint for
+// midpoint entries, the high for long stop entries and the low for short ones. Signals without
+// that price, or any other entry type, enter at their entry price.
+func entryOrderPrice(signal *AlertMessage) float64 {
+	price := signal.EntryPrice
+	switch signalEntryType(signal) {
+	case EntryMidpoint:
+		price = signal.Midpoint
+		if price == 0 && signal.HighPrice > 0 && signal.LowPrice > 0 {
+			price = (signal.HighPrice + signal.LowPrice) / 2
+		}
+	case EntryStop:
+		price = signal.HighPrice
+		if signal.SignalType == "Sell" {
+			price = signal.LowPrice
+		}
+	}
+	if price <= 0 {
+		return signal.EntryPrice
+	}
+	return price
+}
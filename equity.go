@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCaptionLength is the longest photo caption Telegram accepts, in UTF-16 code units.
+const maxCaptionLength = 1024
+
+var (
+	chartDrawdown = color.RGBA{R: 0x5c, G: 0x25, B: 0x2b, A: 0xff}
+	chartZero     = color.RGBA{R: 0x78, G: 0x7b, B: 0x86, A: 0xff}
+)
+
+// equityPoint is the cumulative net profit after a trade closed.
+type equityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// equityCurve returns the cumulative net profit of the trades in the order they closed.
+func equityCurve(trades []Trade) []equityPoint {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	curve := make([]equityPoint, len(sorted))
+	var equity float64
+	for i, trade := range sorted {
+		equity += trade.Profit
+		curve[i] = equityPoint{Time: trade.Timestamp, Equity: equity}
+	}
+	return curve
+}
+
+// maxDrawdown returns the largest fall of the equity curve below an earlier peak, counting the
+// start of the curve at 0 as the first peak.
+func maxDrawdown(curve []equityPoint) float64 {
+	var peak, drawdown float64
+	for _, point := range curve {
+		peak = math.Max(peak, point.Equity)
+		drawdown = math.Max(drawdown, peak-point.Equity)
+	}
+	return drawdown
+}
+
+// renderEquityChart draws the equity curve from start as a PNG.
+func renderEquityChart(start time.Time, curve []equityPoint) ([]byte, error) {
+	img, err := drawEquityChart(start, curve)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawEquityChart draws the equity curve from start, with the drawdown below each peak shaded
+// and the zero line dashed. Time runs left to right; equity changes when a trade closes.
+func drawEquityChart(start time.Time, curve []equityPoint) (*image.RGBA, error) {
+	if len(curve) == 0 {
+		return nil, fmt.Errorf("no trades to chart")
+	}
+	end := curve[len(curve)-1].Time
+	if start.After(curve[0].Time) {
+		start = curve[0].Time
+	}
+	if !end.After(start) {
+		end = start.Add(time.Second)
+	}
+
+	low, high := 0.0, 0.0
+	for _, point := range curve {
+		low = math.Min(low, point.Equity)
+		high = math.Max(high, point.Equity)
+	}
+	if high <= low {
+		high = low + 1
+	}
+	margin := (high - low) * 0.05
+	low, high = low-margin, high+margin
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+
+	plotWidth := chartWidth - 2*chartPadding
+	plotHeight := float64(chartHeight - 2*chartPadding)
+	y := func(equity float64) int {
+		return chartPadding + int((high-equity)/(high-low)*plotHeight)
+	}
+	fill := func(x0, y0, x1, y1 int, c color.RGBA) {
+		if y1 < y0 {
+			y0, y1 = y1, y0
+		}
+		draw.Draw(img, image.Rect(x0, y0, x1+1, y1+1), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	zero := y(0)
+	for x := chartPadding; x < chartWidth-chartPadding; x += 9 {
+		fill(x, zero, min(x+5, chartWidth-chartPadding), zero, chartZero)
+	}
+
+	// Step through the curve one pixel column at a time
+	next := 0
+	var equity, peak float64
+	previousY := zero
+	span := end.Sub(start)
+	for column := 0; column <= plotWidth; column++ {
+		t := start.Add(time.Duration(float64(span) * float64(column) / float64(plotWidth)))
+		for next < len(curve) && !curve[next].Time.After(t) {
+			equity = curve[next].Equity
+			peak = math.Max(peak, equity)
+			next++
+		}
+		x := chartPadding + column
+		if peak > equity {
+			fill(x, y(peak), x, y(equity), chartDrawdown)
+		}
+		fill(x, previousY, x, y(equity)+1, chartEntry)
+		previousY = y(equity)
+	}
+	return img, nil
+}
+
+// sendPerformanceChart sends the equity curve of the trades with the performance summary as its
+// caption, or after it as a message when the summary is too long for a caption. The keyboard
+// goes with the summary. It reports whether the chart was sent.
+func sendPerformanceChart(chatID int64, start time.Time, trades []Trade, summary string, keyboard tgbotapi.InlineKeyboardMarkup) bool {
+	chart, err := renderEquityChart(start, equityCurve(trades))
+	if err != nil {
+		telegramLog.Error("Failed to render equity chart", "error", err)
+		return false
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "equity.png", Bytes: chart})
+	long := len(utf16.Encode([]rune(summary))) > maxCaptionLength
+	if long {
+		photo.Caption = tr(chatID, "Equity curve: cumulative net profit, with drawdowns shaded red")
+	} else {
+		photo.Caption = summary
+		photo.ReplyMarkup = keyboard
+	}
+	if _, err := bot.Send(photo); err != nil {
+		telegramLog.Error("Failed to send equity chart", "chat_id", chatID, "error", err)
+		return false
+	}
+	if long {
+		msg := tgbotapi.NewMessage(chatID, summary)
+		msg.ReplyMarkup = keyboard
+		if _, err := bot.Send(msg); err != nil {
+			telegramLog.Error("Failed to send performance data", "chat_id", chatID, "error", err)
+		}
+	}
+	return true
+}
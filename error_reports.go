@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sources of reported errors, shown in alerts and tagged in Sentry.
+const (
+	ErrorSourceOrder     = "order"
+	ErrorSourceBinance   = "binance"
+	ErrorSourceWebSocket = "websocket"
+	ErrorSourceDatabase  = "database"
+	ErrorSourcePanic     = "panic"
+)
+
+// errorAlertInterval is the least time between Telegram alerts for the same failure; repeats
+// in between are counted in the next alert.
+const errorAlertInterval = 5 * time.Minute
+
+// maxStackFrames bounds the stack trace sent to Sentry.
+const maxStackFrames = 50
+
+// errorAlerts throttles Telegram alerts by source and message.
+var errorAlerts = struct {
+	mu         sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+}{last: make(map[string]time.Time), suppressed: make(map[string]int)}
+
+// reportError logs a critical failure and reports it to the alert chat and, if SENTRY_DSN is
+// set, to Sentry with a stack trace. fields are key and value pairs like slog's, such as
+// "signal_id", id. Reports are sent in the background so the failing path is not held up.
+func reportError(logger *slog.Logger, source, message string, err error, fields ...any) {
+	logger.Error(message, append(append([]any{"source", source}, fields...), "error", err)...)
+
+	stack := make([]uintptr, maxStackFrames)
+	stack = stack[:runtime.Callers(2, stack)]
+	go sendErrorAlert(source, message, err, fields)
+	if sentry != nil {
+		go sentry.Send(source, message, err, fields, stack)
+	}
+}
+
+// sendErrorAlert sends a failure to the alert chat, or the admin user if none is set, unless
+// the same failure was sent in the last errorAlertInterval.
+func sendErrorAlert(source, message string, err error, fields []any) {
+	config := GetGlobalConfig()
+	chatID := config.AlertChatID
+	if chatID == 0 {
+		chatID = config.AdminUserID
+	}
+	if bot == nil || chatID == 0 {
+		return
+	}
+
+	key := source + "|" + message
+	errorAlerts.mu.Lock()
+	if time.Since(errorAlerts.last[key]) < errorAlertInterval {
+		errorAlerts.suppressed[key]++
+		errorAlerts.mu.Unlock()
+		return
+	}
+	suppressed := errorAlerts.suppressed[key]
+	errorAlerts.last[key] = time.Now()
+	delete(errorAlerts.suppressed, key)
+	errorAlerts.mu.Unlock()
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "⚠️ %s failure: %s", source, message)
+	if err != nil {
+		fmt.Fprintf(&text, "\n%v", err)
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		// Stack traces are for the log and Sentry, not the chat
+		if fields[i] != "stack" {
+			fmt.Fprintf(&text, "\n%v: %v", fields[i], fields[i+1])
+		}
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(&text, "\n(%d more since the last alert)", suppressed)
+	}
+	alert := text.String()
+	if len(alert) > maxAuditMessageLength {
+		alert = alert[:maxAuditMessageLength]
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(chatID, alert)); err != nil {
+		slog.Warn("Failed to send error alert", "chat_id", chatID, "error", err)
+	}
+}
+
+// SentryClient sends error events to a Sentry project through its envelope API.
+type SentryClient struct {
+	endpoint    string
+	auth        string
+	environment string
+	serverName  string
+	client      *http.Client
+}
+
+// sentry is set from SENTRY_DSN by initErrorReporting, and nil without it.
+var sentry *SentryClient
+
+// initErrorReporting sets up Sentry from SENTRY_DSN, with SENTRY_ENVIRONMENT naming the
+// deployment, e.g. production.
+func initErrorReporting() {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return
+	}
+	client, err := newSentryClient(dsn, os.Getenv("SENTRY_ENVIRONMENT"))
+	if err != nil {
+		slog.Warn("Invalid SENTRY_DSN, errors are not sent to Sentry", "error", err)
+		return
+	}
+	sentry = client
+}
+
+// newSentryClient parses a DSN of the form https://<key>@<host>/<project>.
+func newSentryClient(dsn, environment string) (*SentryClient, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	project := strings.Trim(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || project == "" || u.Host == "" {
+		return nil, fmt.Errorf("expected https://<key>@<host>/<project>")
+	}
+	// Self-hosted Sentry may live under a path prefix
+	prefix := ""
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		prefix, project = "/"+project[:i], project[i+1:]
+	}
+	hostname, _ := os.Hostname()
+	return &SentryClient{
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=webhook_bot/1.0, sentry_key=%s", u.User.Username()),
+		environment: environment,
+		serverName:  hostname,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// sentryFrame is a stack frame in a Sentry event.
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Send reports an error as a Sentry event, logging failures to send it.
+func (c *SentryClient) Send(source, message string, err error, fields []any, stack []uintptr) {
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+	level := "error"
+	if source == ErrorSourcePanic {
+		level = "fatal"
+	}
+
+	// The event has its own stack trace
+	extra := make(map[string]string)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] != "stack" {
+			extra[fmt.Sprint(fields[i])] = fmt.Sprint(fields[i+1])
+		}
+	}
+	value := message
+	errorType := source
+	if err != nil {
+		value = message + ": " + err.Error()
+		errorType = fmt.Sprintf("%T", err)
+	}
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(eventID),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339Nano),
+		"level":       level,
+		"platform":    "go",
+		"logger":      source,
+		"server_name": c.serverName,
+		"message":     map[string]string{"formatted": message},
+		"tags":        map[string]string{"source": source},
+		"extra":       extra,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       errorType,
+				"value":      value,
+				"stacktrace": map[string]interface{}{"frames": sentryFrames(stack)},
+			}},
+		},
+	}
+	if c.environment != "" {
+		event["environment"] = c.environment
+	}
+
+	payload, jsonErr := json.Marshal(event)
+	if jsonErr != nil {
+		slog.Warn("Failed to encode Sentry event", "error", jsonErr)
+		return
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "{\"event_id\":%q,\"sent_at\":%q}\n", event["event_id"], event["timestamp"])
+	fmt.Fprintf(&body, "{\"type\":\"event\",\"length\":%d}\n", len(payload))
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	req, reqErr := http.NewRequest(http.MethodPost, c.endpoint, &body)
+	if reqErr != nil {
+		slog.Warn("Failed to create Sentry request", "error", reqErr)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+	resp, sendErr := c.client.Do(req)
+	if sendErr != nil {
+		slog.Warn("Failed to send event to Sentry", "error", sendErr)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		slog.Warn("Sentry rejected event", "status", resp.Status)
+	}
+}
+
+// sentryFrames converts a stack from runtime.Callers to Sentry's frame order, innermost last.
+func sentryFrames(stack []uintptr) []sentryFrame {
+	var frames []sentryFrame
+	callers := runtime.CallersFrames(stack)
+	for {
+		frame, more := callers.Next()
+		function := frame.Function
+		module := ""
+		if i := strings.LastIndex(function, "."); i >= 0 {
+			module, function = function[:i], function[i+1:]
+		}
+		frames = append(frames, sentryFrame{
+			Function: function,
+			Module:   module,
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    module == "main" || strings.HasPrefix(module, "main."),
+		})
+		if !more {
+			break
+		}
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trade lifecycle events sent to outgoing webhooks.
+const (
+	EventSignalReceived = "signal.received"
+	EventTradeExecuted  = "trade.executed"
+	EventTPHit          = "tp.hit"
+	EventSLHit          = "sl.hit"
+	EventPositionClosed = "position.closed"
+	EventPing           = "ping" // Sent by Send Test in the admin panel
+)
+
+// webhookEvents lists the events an outgoing webhook can subscribe to.
+var webhookEvents = []string{EventSignalReceived, EventTradeExecuted, EventTPHit, EventSLHit, EventPositionClosed}
+
+// webhookSecretPrefix starts the secrets generated for outgoing webhooks.
+const webhookSecretPrefix = "whsec_"
+
+// webhookRetryDelays are the waits before each retry of a delivery that failed with a network
+// error, a 429 or a server error. Other responses are not retried.
+var webhookRetryDelays = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// OutgoingWebhook posts trade lifecycle events as JSON to a URL, signed with its secret.
+type OutgoingWebhook struct {
+	ID             uint   `gorm:"primaryKey"`
+	Name           string `gorm:"size:64"`
+	URL            string
+	Secret         string `gorm:"serializer:encrypted"`
+	Events         string // Comma-separated events it receives; empty receives all of them
+	CreatedAt      time.Time
+	LastDeliveryAt time.Time // Zero if nothing was delivered yet
+	LastStatus     string    // Response status or error of the last delivery
+}
+
+// Subscribed reports whether the webhook receives the event.
+func (h *OutgoingWebhook) Subscribed(event string) bool {
+	return h.Events == "" || slices.Contains(strings.Split(h.Events, ","), event)
+}
+
+// ListOutgoingWebhooks retrieves all outgoing webhooks, newest first.
+func ListOutgoingWebhooks() ([]OutgoingWebhook, error) {
+	var hooks []OutgoingWebhook
+	if err := db.Order("id desc").Find(&hooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve outgoing webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// CreateOutgoingWebhook adds an outgoing webhook for the events. Without a secret one is
+// generated; the secret is returned either way.
+func CreateOutgoingWebhook(name, webhookURL, secret string, events []string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("URL must be an http:// or https:// URL")
+	}
+	for _, event := range events {
+		if !slices.Contains(webhookEvents, event) {
+			return "", fmt.Errorf("unknown event %q", event)
+		}
+	}
+	if secret == "" {
+		random := make([]byte, 24)
+		if _, err := rand.Read(random); err != nil {
+			return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = webhookSecretPrefix + hex.EncodeToString(random)
+	}
+
+	hook := OutgoingWebhook{Name: name, URL: webhookURL, Secret: secret, Events: strings.Join(events, ",")}
+	if err := db.Create(&hook).Error; err != nil {
+		return "", fmt.Errorf("failed to save outgoing webhook: %w", err)
+	}
+	return secret, nil
+}
+
+// GetOutgoingWebhook retrieves an outgoing webhook by ID.
+func GetOutgoingWebhook(id uint) (*OutgoingWebhook, error) {
+	var hook OutgoingWebhook
+	if err := db.First(&hook, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve outgoing webhook: %w", err)
+	}
+	return &hook, nil
+}
+
+// DeleteOutgoingWebhook removes an outgoing webhook by ID.
+func DeleteOutgoingWebhook(id uint) error {
+	if err := db.Delete(&OutgoingWebhook{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete outgoing webhook: %w", err)
+	}
+	return nil
+}
+
+// WebhookEvent is the JSON body of an event delivery. Receivers can use ID to ignore a
+// delivery they already processed, since failed deliveries are retried.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// TradeExecutedEvent is the data of a trade.executed event.
+type TradeExecutedEvent struct {
+	SignalID    string
+	Symbol      string
+	Side        string // Buy or Sell
+	Account     string
+	Exchange    string
+	TradingMode string
+	MarketType  string
+	AmountUSDT  float64
+	Leverage    int
+}
+
+// OrderFillEvent is the data of a tp.hit or sl.hit event.
+type OrderFillEvent struct {
+	SignalID      string
+	Symbol        string
+	Tag           string // tpN or sl
+	ClientOrderID string
+	Exchange      string
+	Price         float64 // Average fill price
+	Quantity      float64
+}
+
+// encodeWebhookEvent returns the JSON body of an event with a new ID.
+func encodeWebhookEvent(event string, data interface{}) ([]byte, error) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return json.Marshal(WebhookEvent{ID: hex.EncodeToString(id), Event: event, CreatedAt: time.Now().UTC(), Data: data})
+}
+
+// fireWebhookEvent sends an event to every outgoing webhook subscribed to it. data is encoded
+// right away, so the caller may change it afterwards; delivery happens in the background.
+func fireWebhookEvent(event string, data interface{}) {
+	body, err := encodeWebhookEvent(event, data)
+	if err != nil {
+		webhookLog.Warn("Failed to encode outgoing webhook event", "event", event, "error", err)
+		return
+	}
+
+	go func() {
+		hooks, err := ListOutgoingWebhooks()
+		if err != nil {
+			webhookLog.Warn("Failed to load outgoing webhooks", "event", event, "error", err)
+			return
+		}
+		for _, hook := range hooks {
+			if hook.Subscribed(event) {
+				go deliverWebhook(hook, event, body)
+			}
+		}
+	}()
+}
+
+// testWebhook sends a ping event to a webhook and waits for its outcome, without retrying.
+func testWebhook(hook *OutgoingWebhook) (string, error) {
+	body, err := encodeWebhookEvent(EventPing, map[string]string{"Webhook": hook.Name})
+	if err != nil {
+		return "", err
+	}
+	status, _ := postWebhook(*hook, EventPing, body)
+	return status, nil
+}
+
+// fireTradeExecuted sends the trade.executed event for a signal executed on an account.
+func fireTradeExecuted(signal *AlertMessage, account, exchange string, settings *UserSettings) {
+	fireWebhookEvent(EventTradeExecuted, TradeExecutedEvent{
+		SignalID:    signal.SignalID,
+		Symbol:      signal.Symbol,
+		Side:        signal.SignalType,
+		Account:     account,
+		Exchange:    exchange,
+		TradingMode: settings.TradingMode,
+		MarketType:  settings.MarketType,
+		AmountUSDT:  settings.AmountUSDT,
+		Leverage:    settings.Leverage,
+	})
+}
+
+// fireOrderFill sends the tp.hit or sl.hit event for a filled order placed by the bot. Fills of
+// entries and DCA orders send nothing.
+func fireOrderFill(exchange, symbol, clientOrderID string, price, quantity float64) {
+	signalID, tag, ok := orderSignalID(clientOrderID)
+	if !ok {
+		return
+	}
+	var event string
+	switch {
+	case strings.HasPrefix(tag, "tp"):
+		event = EventTPHit
+	case tag == "sl":
+		event = EventSLHit
+	default:
+		return
+	}
+	fireWebhookEvent(event, OrderFillEvent{
+		SignalID:      signalID,
+		Symbol:        symbol,
+		Tag:           tag,
+		ClientOrderID: clientOrderID,
+		Exchange:      exchange,
+		Price:         price,
+		Quantity:      quantity,
+	})
+}
+
+// signWebhook returns the signature of a delivery: the hex HMAC-SHA256 of the timestamp, a dot
+// and the body, keyed with the webhook's secret.
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook posts an event to a webhook, retrying after webhookRetryDelays, and records
+// the outcome on the webhook. Retries still waiting when the bot shuts down are dropped.
+func deliverWebhook(hook OutgoingWebhook, event string, body []byte) {
+	var status string
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		status, retry = postWebhook(hook, event, body)
+		if !retry || attempt == len(webhookRetryDelays) {
+			break
+		}
+		webhookLog.Debug("Retrying outgoing webhook", "webhook", hook.Name, "event", event, "status", status, "attempt", attempt+1)
+		select {
+		case <-time.After(webhookRetryDelays[attempt]):
+		case <-shuttingDown:
+			return
+		}
+	}
+
+	err := db.Model(&OutgoingWebhook{}).Where("id = ?", hook.ID).
+		Updates(map[string]interface{}{"last_delivery_at": time.Now(), "last_status": status}).Error
+	if err != nil {
+		webhookLog.Warn("Failed to record outgoing webhook delivery", "webhook", hook.Name, "error", err)
+	}
+}
+
+// postWebhook sends a signed event to a webhook once, returning the response status or error
+// and whether the delivery should be retried.
+func postWebhook(hook OutgoingWebhook, event string, body []byte) (string, bool) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err.Error(), false
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "webhook_bot/1.0")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(hook.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		webhookLog.Warn("Failed to deliver outgoing webhook", "webhook", hook.Name, "event", event, "error", err)
+		return err.Error(), true
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		webhookLog.Warn("Outgoing webhook rejected event", "webhook", hook.Name, "event", event, "status", resp.Status)
+	}
+	return resp.Status, resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
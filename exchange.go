@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/gorilla/websocket"
+)
+
+// Exchanges a user's own credentials can be registered for with /connect.
+const (
+	ExchangeBinance = "binance"
+	ExchangeBybit   = "bybit"
+)
+
+// errNotBinance is returned for Binance-only features on an account of another exchange.
+var errNotBinance = errors.New("only available for Binance accounts")
+
+// Exchange is a futures exchange signals can be traded on. Orders are linear (USDT-margined)
+// and tagged with clientOrderID, so fills can be matched back to their signal.
+type Exchange interface {
+	// Name returns the exchange's name as shown to users.
+	Name() string
+	// SetLeverage sets the margin mode and leverage for the symbol, clamped to what the
+	// exchange allows for the position size, and returns the leverage set.
+	SetLeverage(ctx context.Context, symbol string, settings *UserSettings) (int, error)
+	// PlaceEntry places the signal's entry order, Market or Limit as the settings say, and
+	// returns its quantity.
+	PlaceEntry(ctx context.Context, signal *AlertMessage, settings *UserSettings) (string, error)
+	// QuantityStep returns the step size order quantities of the symbol are rounded to.
+	QuantityStep(ctx context.Context, symbol string) (float64, error)
+	// PlaceTP places the take-profit order for the TP level at price. A partial TP closes
+	// quantity of the position; otherwise it closes what is left of it, quantity being the
+	// whole position's.
+	PlaceTP(ctx context.Context, signal *AlertMessage, level int, price float64, quantity string, partial bool, settings *UserSettings) error
+	// PlaceSL places the stop-loss order at the signal's SL, closing the position.
+	PlaceSL(ctx context.Context, signal *AlertMessage, quantity string, settings *UserSettings) error
+	// Positions returns the open positions.
+	Positions(ctx context.Context) ([]ExchangePosition, error)
+	// CancelOrders cancels every open order and returns the symbols they were on. The error
+	// joins one error for each symbol whose orders could not be cancelled.
+	CancelOrders(ctx context.Context) ([]string, error)
+	// ClosePosition market-closes the position with a reduce-only order.
+	ClosePosition(ctx context.Context, position ExchangePosition) error
+	// Balance returns the futures wallet balances.
+	Balance(ctx context.Context) ([]ExchangeBalance, error)
+	// StreamEvents calls handle with the account's order updates until ctx is done, which
+	// returns nil, or the stream fails.
+	StreamEvents(ctx context.Context, handle func(ExchangeEvent)) error
+}
+
+// ExchangePosition is an open position on an exchange.
+type ExchangePosition struct {
+	Symbol           string
+	Amount           float64 // Negative for shorts
+	EntryPrice       float64
+	MarkPrice        float64
+	UnrealizedPnL    float64
+	LiquidationPrice float64
+}
+
+// ExchangeBalance is a futures wallet balance on an exchange.
+type ExchangeBalance struct {
+	Asset         string
+	Wallet        float64
+	Available     float64
+	UnrealizedPnL float64
+}
+
+// ExchangeEvent is an update to one of the account's orders.
+type ExchangeEvent struct {
+	Symbol        string
+	ClientOrderID string
+	Status        string // The exchange's order status, e.g. FILLED on Binance or Filled on Bybit
+	Filled        bool
+	Price         float64 // Average fill price
+	Quantity      float64 // Quantity filled so far
+}
+
+// executeExchangeTrade places a confirmed signal on an exchange other than Binance: the entry,
+// then for Market entries the TPs and SL. As with ExecuteTrade, the trade is abandoned if ctx is
+// done before the entry is placed, and the TPs and SL are placed regardless once it is.
+func executeExchangeTrade(ctx context.Context, exchange Exchange, signal *AlertMessage, settings *UserSettings, userID int64) error {
+	if !inFlightTrades.Begin() {
+		sendTradeMessage(userID, tr(userID, "The bot is restarting, please try again in a minute."))
+		return fmt.Errorf("the bot is shutting down")
+	}
+	defer inFlightTrades.End()
+
+	symbol := signal.Symbol
+	if symbol == "" {
+		sendTradeMessage(userID, tr(userID, "Signal has no symbol specified."))
+		return fmt.Errorf("signal has an empty symbol field")
+	}
+	if settings.MarketType == MarketTypeCoinM {
+		return newTradeGuardError("COIN-M futures are only available on Binance, not %s.", exchange.Name())
+	}
+	binanceLog.Info("Executing trade", "exchange", exchange.Name(), "signal_id", signal.SignalID, "symbol", symbol,
+		"user_id", userID, "signal", signal.SignalType, "trading_mode", settings.TradingMode,
+		"amount_usdt", settings.AmountUSDT, "leverage", settings.Leverage)
+
+	if settings.AutoCalculateTPs {
+		recalcSingleTPAndSL(signal, settings)
+	} else {
+		recalcManualTPAndSL(signal, settings)
+	}
+
+	leverage, err := exchange.SetLeverage(ctx, symbol, settings)
+	if err != nil {
+		return fmt.Errorf("failed to set margin mode or leverage: %v", err)
+	}
+	if settings.Leverage > 0 && leverage < settings.Leverage {
+		sendTradeMessage(userID, tr(userID, "Leverage %dx exceeds the maximum allowed for a %.2f USDT position on %s. Using %dx instead.",
+			settings.Leverage, settings.AmountUSDT, symbol, leverage))
+	}
+
+	quantity, err := exchange.PlaceEntry(ctx, signal, settings)
+	if err != nil {
+		var guardErr *TradeGuardError
+		if !errors.As(err, &guardErr) {
+			sendTradeMessage(userID, tr(userID, "Failed to execute trade for %s on %s: %v", symbol, exchange.Name(), err))
+		}
+		return err
+	}
+	if settings.TradingMode == "Market" {
+		sendTradeMessage(userID, tr(userID, "Trade executed for %s on %s (%s) at market price", symbol, exchange.Name(), settings.TradingMode))
+	} else {
+		sendTradeMessage(userID, tr(userID, "Trade executed for %s on %s (%s) at price %.4f", symbol, exchange.Name(), settings.TradingMode, signal.EntryPrice))
+	}
+	watchExchangeEvents(exchange, userID)
+
+	// TP/SL orders close the position, so they can only be placed once it exists
+	if settings.TradingMode != "Market" || (signalTP(signal, 0) == 0 && !(settings.UseSL && signal.SL > 0)) {
+		return nil
+	}
+	protect := context.WithoutCancel(ctx)
+	tps := placedTPs(signal.TPs, settings)
+	quantities, err := splitTPQuantities(protect, exchange, symbol, quantity, tps, settings)
+	if err != nil {
+		sendTradeMessage(userID, tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err))
+		return err
+	}
+	for i, tp := range tps {
+		if tp <= 0 {
+			continue
+		}
+		tpQuantity, partial := quantity, i < len(quantities) && quantities[i] != ""
+		if partial {
+			tpQuantity = quantities[i]
+		}
+		if err := exchange.PlaceTP(protect, signal, i, tp, tpQuantity, partial, settings); err != nil {
+			sendTradeMessage(userID, tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err))
+			return err
+		}
+	}
+	if settings.UseSL && signal.SL > 0 {
+		if err := exchange.PlaceSL(protect, signal, quantity, settings); err != nil {
+			sendTradeMessage(userID, tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err))
+			return err
+		}
+	}
+	sendTradeMessage(userID, tr(userID, "TP/SL orders placed for %s.", symbol))
+	return nil
+}
+
+// splitTPQuantities returns the quantity each of the TPs closes on the exchange (see
+// tpCloseQuantities), or nil when only one TP is placed or the position is too small to split,
+// and every TP closes the whole position.
+func splitTPQuantities(ctx context.Context, exchange Exchange, symbol, quantity string, tps []float64, settings *UserSettings) ([]string, error) {
+	placed := 0
+	for _, tp := range tps {
+		if tp > 0 {
+			placed++
+		}
+	}
+	if placed < 2 {
+		return nil, nil
+	}
+	step, err := exchange.QuantityStep(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	qty, _ := strconv.ParseFloat(quantity, 64)
+	quantities, ok := tpCloseQuantities(qty, step, tps, settings.TPLevels)
+	if !ok {
+		binanceLog.Warn("Position too small to split across its TPs, placing TPs that close all of it",
+			"exchange", exchange.Name(), "symbol", symbol, "quantity", quantity)
+		return nil, nil
+	}
+	return quantities, nil
+}
+
+// sendTradeMessage tells the user who confirmed a trade how it went.
+func sendTradeMessage(userID int64, message string) {
+	if bot == nil {
+		return
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(userID, message)); err != nil {
+		telegramLog.Error("Failed to send message to user", "user_id", userID, "error", err)
+	}
+}
+
+// Name returns "Binance".
+func (b *BinanceClient) Name() string {
+	return "Binance"
+}
+
+// PlaceEntry sizes the entry from the user's USDT amount and places it on USDT-M futures.
+// Market entries are aborted with a TradeGuardError if the price moved too far from the signal;
+// stop entries wait for the price to reach the signal's entry.
+func (b *BinanceClient) PlaceEntry(ctx context.Context, signal *AlertMessage, settings *UserSettings) (string, error) {
+	symbol := signal.Symbol
+	side := signalSide(signal)
+	quantity, err := b.calculateQuantity(ctx, symbol, settings.AmountUSDT, signal.EntryPrice)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate quantity: %v", err)
+	}
+
+	clientID := clientOrderID(signal.SignalID, OrderTagEntry)
+	switch settings.TradingMode {
+	case "Limit":
+		return quantity, b.placeLimitOrder(ctx, symbol, side, quantity, signal.EntryPrice, clientID)
+	case TradingModeStop:
+		// Binance would trigger a stop the price has already crossed straight away
+		if err := b.checkStopTrigger(ctx, symbol, side, signal.EntryPrice); err != nil {
+			return "", err
+		}
+		limitPrice := stopLimitPrice(side, signal.EntryPrice, settings.StopLimitOffset)
+		return quantity, b.placeStopEntryOrder(ctx, symbol, side, quantity, signal.EntryPrice, limitPrice, clientID, workingType(settings))
+	}
+	// Abort if the price has run away from the signal entry since it was posted
+	if err := b.checkSlippage(ctx, symbol, side, signal.EntryPrice, settings.MaxSlippage); err != nil {
+		return "", err
+	}
+	return quantity, b.placeMarketOrder(ctx, symbol, side, quantity, clientID)
+}
+
+// QuantityStep returns the symbol's LOT_SIZE step size.
+func (b *BinanceClient) QuantityStep(ctx context.Context, symbol string) (float64, error) {
+	info, err := b.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return lotStepSize(info)
+}
+
+// PlaceTP places the TAKE_PROFIT_MARKET order for the TP level, reduce-only for partial TPs.
+func (b *BinanceClient) PlaceTP(ctx context.Context, signal *AlertMessage, level int, price float64, quantity string, partial bool, settings *UserSettings) error {
+	clientID := clientOrderID(signal.SignalID, tpOrderTag(level))
+	if partial {
+		return b.placePartialTPOrder(ctx, signal.Symbol, invertSide(signalSide(signal)), quantity, price, clientID, workingType(settings))
+	}
+	return b.placeTPOrder(ctx, signal.Symbol, invertSide(signalSide(signal)), quantity, price, clientID, workingType(settings))
+}
+
+// PlaceSL places the STOP_MARKET order at the signal's SL.
+func (b *BinanceClient) PlaceSL(ctx context.Context, signal *AlertMessage, quantity string, settings *UserSettings) error {
+	clientID := clientOrderID(signal.SignalID, OrderTagSL)
+	return b.placeSLOrder(ctx, signal.Symbol, invertSide(signalSide(signal)), quantity, signal.SL, clientID, workingType(settings))
+}
+
+// Positions returns the open USDT-M positions.
+func (b *BinanceClient) Positions(ctx context.Context) ([]ExchangePosition, error) {
+	risks, err := b.Client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var positions []ExchangePosition
+	for _, risk := range risks {
+		amount, _ := strconv.ParseFloat(risk.PositionAmt, 64)
+		if amount == 0 {
+			continue
+		}
+		position := ExchangePosition{Symbol: risk.Symbol, Amount: amount}
+		position.EntryPrice, _ = strconv.ParseFloat(risk.EntryPrice, 64)
+		position.MarkPrice, _ = strconv.ParseFloat(risk.MarkPrice, 64)
+		position.UnrealizedPnL, _ = strconv.ParseFloat(risk.UnRealizedProfit, 64)
+		position.LiquidationPrice, _ = strconv.ParseFloat(risk.LiquidationPrice, 64)
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// CancelOrders cancels the open USDT-M orders on every symbol with open orders or a tracked
+// position, and forgets the TP/SL orders, trailing TP and DCA ladder linked to them.
+func (b *BinanceClient) CancelOrders(ctx context.Context) ([]string, error) {
+	// Tracked positions' orders are cancelled even if the open orders can't be listed
+	var errs []error
+	orders, err := b.Client.NewListOpenOrdersService().Do(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list open orders: %v", err))
+	}
+	symbols := make(map[string]bool)
+	for _, order := range orders {
+		symbols[order.Symbol] = true
+	}
+	for _, symbol := range positionTracker.Symbols(b.Account) {
+		symbols[symbol] = true
+	}
+	sorted := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		sorted = append(sorted, symbol)
+	}
+	sort.Strings(sorted)
+
+	var cancelled []string
+	for _, symbol := range sorted {
+		err := b.Client.NewCancelAllOpenOrdersService().Symbol(symbol).Do(ctx)
+		auditOrder(AuditCancelOrder, "", "symbol="+symbol+" all=true", nil, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel %s orders: %v", symbol, err))
+			continue
+		}
+		cancelled = append(cancelled, symbol)
+		ocoGroups.Delete(b.key(symbol))
+		trailingTPs.Delete(b.key(symbol))
+		dcaLadders.Delete(b.key(symbol))
+	}
+	return cancelled, errors.Join(errs...)
+}
+
+// ClosePosition market-closes a USDT-M position. A tracked position's close is recorded against
+// its signal.
+func (b *BinanceClient) ClosePosition(ctx context.Context, position ExchangePosition) error {
+	side := futures.SideTypeSell
+	if position.Amount < 0 {
+		side = futures.SideTypeBuy
+	}
+	quantity := strconv.FormatFloat(math.Abs(position.Amount), 'f', -1, 64)
+	order := b.Client.NewCreateOrderService().
+		Symbol(position.Symbol).
+		Side(side).
+		Type(futures.OrderTypeMarket).
+		Quantity(quantity).
+		ReduceOnly(true)
+	var clientID string
+	if tracked, exists := positionTracker.Get(b.key(position.Symbol)); exists {
+		clientID = clientOrderID(tracked.SignalID, OrderTagPanic)
+		order = order.NewClientOrderID(clientID)
+	}
+	res, err := order.Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=MARKET quantity=%s reduce_only=true", position.Symbol, side, quantity), res, err)
+	if err != nil {
+		return err
+	}
+	recordFuturesOrder(res)
+	return nil
+}
+
+// Balance returns the USDT-M futures wallet balances.
+func (b *BinanceClient) Balance(ctx context.Context) ([]ExchangeBalance, error) {
+	assets, err := b.Client.NewGetBalanceService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]ExchangeBalance, 0, len(assets))
+	for _, asset := range assets {
+		balance := ExchangeBalance{Asset: asset.Asset}
+		balance.Wallet, _ = strconv.ParseFloat(asset.Balance, 64)
+		balance.Available, _ = strconv.ParseFloat(asset.AvailableBalance, 64)
+		balance.UnrealizedPnL, _ = strconv.ParseFloat(asset.CrossUnPnl, 64)
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// StreamEvents reports order updates from a user data stream of its own. The bot's order
+// monitor keeps its own stream, which also tracks DCA ladders, OCO groups and PnL.
+func (b *BinanceClient) StreamEvents(ctx context.Context, handle func(ExchangeEvent)) error {
+	listenKey, err := b.Client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start user stream: %w", err)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, userStreamBaseURL()+listenKey, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("user stream disconnected: %w", err)
+		}
+		var event futures.WsUserDataEvent
+		if err := json.Unmarshal(message, &event); err != nil || event.Event != futures.UserDataEventTypeOrderTradeUpdate {
+			continue
+		}
+		order := event.OrderTradeUpdate
+		price, _ := strconv.ParseFloat(order.AveragePrice, 64)
+		quantity, _ := strconv.ParseFloat(order.AccumulatedFilledQty, 64)
+		handle(ExchangeEvent{
+			Symbol:        order.Symbol,
+			ClientOrderID: order.ClientOrderID,
+			Status:        string(order.Status),
+			Filled:        order.Status == futures.OrderStatusTypeFilled,
+			Price:         price,
+			Quantity:      quantity,
+		})
+	}
+}
+
+// signalSide returns the entry side of a signal.
+func signalSide(signal *AlertMessage) futures.SideType {
+	if signal.SignalType == "Sell" {
+		return futures.SideTypeSell
+	}
+	return futures.SideTypeBuy
+}
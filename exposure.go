@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exposureQuotes are the quote assets stripped from symbols to find their underlying asset,
+// and the stablecoins counted at face value in the account equity.
+var exposureQuotes = []string{"USDT", "USDC", "FDUSD", "BUSD"}
+
+// AssetExposure is the open long and short notional on one underlying asset.
+type AssetExposure struct {
+	Asset     string
+	Long      float64
+	Short     float64
+	Positions int
+}
+
+// Net returns the long notional less the short notional.
+func (e AssetExposure) Net() float64 {
+	return e.Long - e.Short
+}
+
+// Exposure is the notional of an account's open positions by underlying asset and direction,
+// relative to its equity.
+type Exposure struct {
+	Assets      []AssetExposure // Largest gross notional first
+	Long        float64
+	Short       float64
+	LongAssets  int     // Assets with a long position
+	ShortAssets int     // Assets with a short position
+	Equity      float64 // Stablecoin wallet balances with unrealized PnL, 0 if unknown
+	Limit       float64 // Percentage of equity one direction may reach before a warning, 0 for none
+}
+
+// Percent returns notional as a percentage of equity, or 0 when the equity is unknown.
+func (e Exposure) Percent(notional float64) float64 {
+	if e.Equity <= 0 {
+		return 0
+	}
+	return notional / e.Equity * 100
+}
+
+// LongOverLimit reports whether the long notional across assets exceeds the limit.
+func (e Exposure) LongOverLimit() bool {
+	return e.Limit > 0 && e.Percent(e.Long) > e.Limit
+}
+
+// ShortOverLimit reports whether the short notional across assets exceeds the limit.
+func (e Exposure) ShortOverLimit() bool {
+	return e.Limit > 0 && e.Percent(e.Short) > e.Limit
+}
+
+// underlyingAsset returns the asset a symbol trades, e.g. BTC for BTCUSDT and PEPE for 1000PEPEUSDT.
+func underlyingAsset(symbol string) string {
+	for _, quote := range exposureQuotes {
+		if base, ok := strings.CutSuffix(symbol, quote); ok && base != "" {
+			symbol = base
+			break
+		}
+	}
+	if base, ok := strings.CutPrefix(symbol, "1000"); ok && base != "" {
+		return base
+	}
+	return symbol
+}
+
+// calculateExposure sums the notional of the positions at their mark price by underlying
+// asset and direction. Positions on the same asset in different symbols, such as BTCUSDT and
+// BTCUSDC, add up.
+func calculateExposure(positions []ExchangePosition, balances []ExchangeBalance, limit float64) Exposure {
+	exposure := Exposure{Limit: limit}
+	for _, balance := range balances {
+		for _, quote := range exposureQuotes {
+			if balance.Asset == quote {
+				exposure.Equity += balance.Wallet + balance.UnrealizedPnL
+			}
+		}
+	}
+
+	byAsset := make(map[string]*AssetExposure)
+	for _, position := range positions {
+		if position.Amount == 0 {
+			continue
+		}
+		asset := underlyingAsset(position.Symbol)
+		entry, ok := byAsset[asset]
+		if !ok {
+			entry = &AssetExposure{Asset: asset}
+			byAsset[asset] = entry
+		}
+		notional := math.Abs(position.Amount) * position.MarkPrice
+		if position.Amount > 0 {
+			entry.Long += notional
+			exposure.Long += notional
+		} else {
+			entry.Short += notional
+			exposure.Short += notional
+		}
+		entry.Positions++
+	}
+	for _, entry := range byAsset {
+		if entry.Long > 0 {
+			exposure.LongAssets++
+		}
+		if entry.Short > 0 {
+			exposure.ShortAssets++
+		}
+		exposure.Assets = append(exposure.Assets, *entry)
+	}
+	sort.Slice(exposure.Assets, func(i, j int) bool {
+		return exposure.Assets[i].Long+exposure.Assets[i].Short > exposure.Assets[j].Long+exposure.Assets[j].Short
+	})
+	return exposure
+}
+
+// accountExposure fetches an account's positions and balances and calculates its exposure
+// against the configured limit.
+func accountExposure(ctx context.Context, exchange Exchange) (Exposure, error) {
+	positions, err := exchange.Positions(ctx)
+	if err != nil {
+		return Exposure{}, fmt.Errorf("failed to get positions: %v", err)
+	}
+	balances, err := exchange.Balance(ctx)
+	if err != nil {
+		return Exposure{}, fmt.Errorf("failed to get balance: %v", err)
+	}
+	return calculateExposure(positions, balances, GetGlobalConfig().ExposureLimitPercent), nil
+}
+
+// formatExposure renders an account's exposure by asset with the totals per direction and a
+// warning for each direction over the limit.
+func formatExposure(chatID int64, account string, exposure Exposure) string {
+	text := tr(chatID, "<b>Exposure (%s)</b>\n", account)
+	if exposure.Equity > 0 {
+		text += tr(chatID, "Equity: %.2f USDT\n", exposure.Equity)
+	}
+	if len(exposure.Assets) == 0 {
+		return text + tr(chatID, "\nNo open positions.")
+	}
+
+	text += "\n"
+	for _, asset := range exposure.Assets {
+		text += fmt.Sprintf("<b>%s</b>: ", asset.Asset)
+		var parts []string
+		if asset.Long > 0 {
+			parts = append(parts, tr(chatID, "long %.2f (%.0f%%)", asset.Long, exposure.Percent(asset.Long)))
+		}
+		if asset.Short > 0 {
+			parts = append(parts, tr(chatID, "short %.2f (%.0f%%)", asset.Short, exposure.Percent(asset.Short)))
+		}
+		text += strings.Join(parts, " | ") + "\n"
+	}
+	text += tr(chatID, "\n<b>Long:</b> %.2f USDT (%.0f%% of equity) across %d assets\n", exposure.Long, exposure.Percent(exposure.Long), exposure.LongAssets)
+	text += tr(chatID, "<b>Short:</b> %.2f USDT (%.0f%% of equity) across %d assets\n", exposure.Short, exposure.Percent(exposure.Short), exposure.ShortAssets)
+	text += tr(chatID, "<b>Net:</b> %+.2f USDT\n", exposure.Long-exposure.Short)
+	if exposure.LongOverLimit() {
+		text += tr(chatID, "\n\u26A0\uFE0F Long exposure is %.0f%% of equity, above the %.0f%% limit. Positions in the same direction tend to move together.\n",
+			exposure.Percent(exposure.Long), exposure.Limit)
+	}
+	if exposure.ShortOverLimit() {
+		text += tr(chatID, "\n\u26A0\uFE0F Short exposure is %.0f%% of equity, above the %.0f%% limit. Positions in the same direction tend to move together.\n",
+			exposure.Percent(exposure.Short), exposure.Limit)
+	}
+	return text
+}
+
+// handleExposureCommand shows the long and short notional per asset on the sender's account.
+func handleExposureCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	exchange, account, err := userAccountExchange(senderID(message))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Account %s is unavailable: %v", account, err)))
+		return
+	}
+
+	exposure, err := accountExposure(context.Background(), exchange)
+	if err != nil {
+		binanceLog.Error("Failed to get exposure", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to get exposure: %v", err)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatExposure(chatID, account, exposure))
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Signal filter rules are conditions on a signal such as
+//
+//	volume_24h < 5000000 or (side == short and btc_change_24h > 2)
+//
+// combining comparisons of a field with a value using and, or, not and parentheses. Text fields
+// compare case-insensitively with ==, != and in (a, b, ...); number fields also with <, <=, >
+// and >=.
+
+// filterFieldKind tells text fields from number fields.
+type filterFieldKind int
+
+const (
+	filterText filterFieldKind = iota
+	filterNumber
+)
+
+// filterFields lists the fields rules can use. The *_24h and funding_rate fields are fetched from
+// Binance when a rule uses them.
+var filterFields = map[string]filterFieldKind{
+	"symbol":         filterText,
+	"side":           filterText, // long or short
+	"source":         filterText,
+	"strategy":       filterText,
+	"timeframe":      filterText,
+	"entry":          filterNumber,
+	"sl":             filterNumber, // 0 without a stop loss
+	"sl_percent":     filterNumber, // Distance from entry to SL in percent, 0 without a stop loss
+	"tps":            filterNumber, // Number of TPs
+	"rr":             filterNumber, // TP1 distance over SL distance, 0 without a TP or SL
+	"hour":           filterNumber, // Hour the signal was received, UTC
+	"volume_24h":     filterNumber, // 24h volume in USDT
+	"change_24h":     filterNumber, // 24h price change in percent
+	"funding_rate":   filterNumber, // Next funding rate in percent
+	"btc_change_24h": filterNumber, // BTCUSDT 24h price change in percent
+}
+
+// filterFieldNames returns the fields rules can use, in name order.
+func filterFieldNames() []string {
+	names := make([]string, 0, len(filterFields))
+	for name := range filterFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// filterEnv is what a rule is evaluated against: a signal and the market data fetched for it,
+// so rules sharing a field fetch it once.
+type filterEnv struct {
+	signal *AlertMessage
+	quotes map[string]*MarketQuote
+}
+
+func newFilterEnv(signal *AlertMessage) *filterEnv {
+	return &filterEnv{signal: signal, quotes: make(map[string]*MarketQuote)}
+}
+
+// quote returns the market data of a symbol, fetching it on first use.
+func (e *filterEnv) quote(symbol string) (*MarketQuote, error) {
+	if quote, ok := e.quotes[symbol]; ok {
+		return quote, nil
+	}
+	client := mainBinanceClient()
+	if client == nil {
+		return nil, errors.New("market data is unavailable without a Binance connection")
+	}
+	quote, err := client.getMarketQuote(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market data for %s: %w", symbol, err)
+	}
+	e.quotes[symbol] = quote
+	return quote, nil
+}
+
+// text returns the lower-cased value of a text field.
+func (e *filterEnv) text(field string) string {
+	s := e.signal
+	switch field {
+	case "symbol":
+		return strings.ToLower(s.Symbol)
+	case "side":
+		if s.SignalType == "Sell" {
+			return "short"
+		}
+		return "long"
+	case "source":
+		return strings.ToLower(s.Source)
+	case "strategy":
+		return strings.ToLower(signalStrategy(s))
+	case "timeframe":
+		return strings.ToLower(s.Timeframe)
+	}
+	return ""
+}
+
+// number returns the value of a number field.
+func (e *filterEnv) number(field string) (float64, error) {
+	s := e.signal
+	switch field {
+	case "entry":
+		return s.EntryPrice, nil
+	case "sl":
+		return s.SL, nil
+	case "sl_percent":
+		if s.SL <= 0 || s.EntryPrice <= 0 {
+			return 0, nil
+		}
+		return math.Abs(s.EntryPrice-s.SL) / s.EntryPrice * 100, nil
+	case "tps":
+		return float64(len(s.TPs)), nil
+	case "rr":
+		if s.SL <= 0 || len(s.TPs) == 0 || s.SL == s.EntryPrice {
+			return 0, nil
+		}
+		return math.Abs(s.TPs[0]-s.EntryPrice) / math.Abs(s.EntryPrice-s.SL), nil
+	case "hour":
+		received := s.ReceivedAt
+		if received.IsZero() {
+			received = time.Now()
+		}
+		return float64(received.UTC().Hour()), nil
+	case "volume_24h", "change_24h", "funding_rate":
+		quote, err := e.quote(s.Symbol)
+		if err != nil {
+			return 0, err
+		}
+		switch field {
+		case "volume_24h":
+			return quote.QuoteVolume, nil
+		case "change_24h":
+			return quote.ChangePercent, nil
+		}
+		return quote.FundingRate * 100, nil
+	case "btc_change_24h":
+		quote, err := e.quote("BTCUSDT")
+		if err != nil {
+			return 0, err
+		}
+		return quote.ChangePercent, nil
+	}
+	return 0, fmt.Errorf("unknown field %q", field)
+}
+
+// filterExpr is a parsed rule or part of one.
+type filterExpr interface {
+	eval(env *filterEnv) (bool, error)
+}
+
+type filterAnd struct{ left, right filterExpr }
+
+func (x filterAnd) eval(env *filterEnv) (bool, error) {
+	ok, err := x.left.eval(env)
+	if err != nil || !ok {
+		return false, err
+	}
+	return x.right.eval(env)
+}
+
+type filterOr struct{ left, right filterExpr }
+
+func (x filterOr) eval(env *filterEnv) (bool, error) {
+	ok, err := x.left.eval(env)
+	if err != nil || ok {
+		return ok, err
+	}
+	return x.right.eval(env)
+}
+
+type filterNot struct{ expr filterExpr }
+
+func (x filterNot) eval(env *filterEnv) (bool, error) {
+	ok, err := x.expr.eval(env)
+	return !ok, err
+}
+
+// filterCompare compares a field with one value, or with a list of values for in.
+type filterCompare struct {
+	field  string
+	op     string
+	texts  []string // Lower-cased values of a text field
+	number float64
+}
+
+func (x filterCompare) eval(env *filterEnv) (bool, error) {
+	if filterFields[x.field] == filterText {
+		matched := slices.Contains(x.texts, env.text(x.field))
+		return matched == (x.op != "!="), nil
+	}
+
+	value, err := env.number(x.field)
+	if err != nil {
+		return false, err
+	}
+	switch x.op {
+	case "==":
+		return value == x.number, nil
+	case "!=":
+		return value != x.number, nil
+	case "<":
+		return value < x.number, nil
+	case "<=":
+		return value <= x.number, nil
+	case ">":
+		return value > x.number, nil
+	case ">=":
+		return value >= x.number, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", x.op)
+}
+
+// filterToken is a word, number, quoted text, operator or parenthesis of a rule.
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeFilterRule splits a rule into tokens.
+func tokenizeFilterRule(rule string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(rule)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, filterToken{text: string(r)})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				op += "="
+				i++
+			}
+			switch op {
+			case "!":
+				return nil, errors.New("expected != after !")
+			case "=":
+				op = "=="
+			}
+			tokens = append(tokens, filterToken{text: op})
+		case r == '"' || r == '\'':
+			end := slices.Index(runes[i+1:], r)
+			if end < 0 {
+				return nil, errors.New("unterminated quoted text")
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i+1 : i+1+end]), quoted: true})
+			i += end + 2
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()=!<>,"'`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser parses tokens by recursive descent: or binds loosest, then and, then not.
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+// parseFilterRule parses a rule, rejecting unknown fields and operators that don't suit a field.
+func parseFilterRule(rule string) (filterExpr, error) {
+	tokens, err := tokenizeFilterRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("rule is empty")
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+// peekKeyword reports whether the next token is the unquoted keyword.
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+// next returns the next token, or an error at the end of the rule.
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, errors.New("rule ends too early")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{expr}, nil
+	}
+	if p.peekKeyword("(") {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekKeyword(")") {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field := strings.ToLower(token.text)
+	kind, known := filterFields[field]
+	if token.quoted || !known {
+		return nil, fmt.Errorf("unknown field %q, expected one of %s", token.text, strings.Join(filterFieldNames(), ", "))
+	}
+
+	token, err = p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(token.text)
+	switch {
+	case op == "in" && !token.quoted:
+		if kind != filterText {
+			return nil, fmt.Errorf("in only works with text fields, not %s", field)
+		}
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			values[i] = filterTextValue(field, value)
+		}
+		return filterCompare{field: field, op: "==", texts: values}, nil
+	case !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, op) || token.quoted:
+		return nil, fmt.Errorf("expected a comparison after %s, got %q", field, token.text)
+	}
+
+	token, err = p.next()
+	if err != nil {
+		return nil, err
+	}
+	if kind == filterText {
+		if op != "==" && op != "!=" {
+			return nil, fmt.Errorf("%s is text and can only be compared with ==, != or in", field)
+		}
+		return filterCompare{field: field, op: op, texts: []string{filterTextValue(field, token.text)}}, nil
+	}
+	number, err := strconv.ParseFloat(token.text, 64)
+	if err != nil || token.quoted {
+		return nil, fmt.Errorf("%s must be compared with a number, got %q", field, token.text)
+	}
+	return filterCompare{field: field, op: op, number: number}, nil
+}
+
+// parseList parses "(a, b, ...)" after in.
+func (p *filterParser) parseList() ([]string, error) {
+	if !p.peekKeyword("(") {
+		return nil, errors.New("expected ( after in")
+	}
+	p.pos++
+	var values []string
+	for {
+		token, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, token.text)
+		token, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		if token.text == ")" && !token.quoted {
+			return values, nil
+		}
+		if token.text != "," || token.quoted {
+			return nil, fmt.Errorf("expected , or ) in list, got %q", token.text)
+		}
+	}
+}
+
+// filterTextValue lower-cases a value compared with a text field, accepting buy and sell for
+// the side.
+func filterTextValue(field, value string) string {
+	value = strings.ToLower(value)
+	if field == "side" {
+		switch value {
+		case "buy":
+			return "long"
+		case "sell":
+			return "short"
+		}
+	}
+	return value
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Health check results.
+const (
+	HealthOK       = "ok"
+	HealthDown     = "down"
+	HealthDisabled = "disabled" // Not configured or not started, which is not a failure
+)
+
+// Overall health, from the checks.
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusDegraded    = "degraded"    // An optional check is down
+	HealthStatusUnavailable = "unavailable" // A required check is down
+)
+
+// startedAt is when the process started, for the reported uptime.
+var startedAt = time.Now()
+
+// HealthCheck is the result of checking one dependency. Details never include error messages,
+// since the health endpoints are public and errors can contain URLs with the bot token.
+type HealthCheck struct {
+	Status    string     `json:"status"`
+	Detail    string     `json:"detail,omitempty"`
+	LatencyMS int64      `json:"latency_ms,omitempty"`
+	LastEvent *time.Time `json:"last_event,omitempty"`
+	Required  bool       `json:"required"` // Down makes the bot unready
+}
+
+// HealthReport is the response of /healthz and /readyz.
+type HealthReport struct {
+	Status        string                 `json:"status"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+	Checks        map[string]HealthCheck `json:"checks"`
+}
+
+// healthzHandler reports whether the process is alive, with the checks that need no requests
+// to Telegram or Binance. It answers 200 while the server runs, so orchestrators only restart
+// the bot when it stops responding.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	report := newHealthReport(map[string]HealthCheck{
+		"database":  checkDatabaseHealth(),
+		"telegram":  telegramListenerHealth(),
+		"websocket": orderMonitorHealth(),
+	})
+	writeAPIJSON(w, http.StatusOK, report)
+}
+
+// readyzHandler reports whether the bot can take webhook alerts, checking the database,
+// Telegram and Binance. It answers 503 when a required check is down.
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	report := newHealthReport(map[string]HealthCheck{
+		"database":  checkDatabaseHealth(),
+		"telegram":  checkTelegramHealth(),
+		"binance":   checkBinanceHealth(),
+		"websocket": orderMonitorHealth(),
+	})
+	status := http.StatusOK
+	if report.Status == HealthStatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	writeAPIJSON(w, status, report)
+}
+
+// newHealthReport sums up the checks.
+func newHealthReport(checks map[string]HealthCheck) HealthReport {
+	report := HealthReport{
+		Status:        HealthStatusOK,
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+		Checks:        checks,
+	}
+	for _, check := range checks {
+		if check.Status != HealthDown {
+			continue
+		}
+		if check.Required {
+			report.Status = HealthStatusUnavailable
+			break
+		}
+		report.Status = HealthStatusDegraded
+	}
+	return report
+}
+
+// checkDatabaseHealth pings the database.
+func checkDatabaseHealth() HealthCheck {
+	check := HealthCheck{Status: HealthDown, Required: true}
+	if db == nil {
+		check.Detail = "not open"
+		return check
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		check.Detail = "not open"
+		return check
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardTimeout)
+	defer cancel()
+	start := time.Now()
+	err = sqlDB.PingContext(ctx)
+	check.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		check.Detail = "ping failed"
+		return check
+	}
+	check.Status = HealthOK
+	return check
+}
+
+// telegramListenerHealth reports whether the bot is receiving Telegram updates, without
+// contacting Telegram.
+func telegramListenerHealth() HealthCheck {
+	if !telegramConfigured() {
+		return HealthCheck{Status: HealthDisabled, Detail: "not configured"}
+	}
+	if bot == nil || updatesChan == nil {
+		return HealthCheck{Status: HealthDown, Detail: "not running", Required: true}
+	}
+	return HealthCheck{Status: HealthOK, Detail: "receiving updates", Required: true}
+}
+
+// checkTelegramHealth checks that the bot token works. Until Telegram is configured in the admin
+// panel it is not required, so the panel stays reachable.
+func checkTelegramHealth() HealthCheck {
+	if !telegramConfigured() {
+		return HealthCheck{Status: HealthDisabled, Detail: "not configured"}
+	}
+	status := checkTelegram()
+	check := HealthCheck{Status: HealthOK, LatencyMS: status.Latency.Milliseconds(), Required: true}
+	if !status.OK {
+		check.Status = HealthDown
+		check.Detail = "request failed"
+		if bot == nil {
+			check.Detail = "not running"
+		}
+	}
+	return check
+}
+
+// telegramConfigured reports whether a bot token and chat are set.
+func telegramConfigured() bool {
+	config := GetGlobalConfig()
+	return config.TelegramBotToken != "" && config.TelegramChatID != 0
+}
+
+// checkBinanceHealth pings the Binance API. Signals still reach Telegram while Binance is
+// unreachable, so it is not required.
+func checkBinanceHealth() HealthCheck {
+	client := mainBinanceClient()
+	if client == nil {
+		return HealthCheck{Status: HealthDisabled, Detail: "not configured"}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardTimeout)
+	defer cancel()
+	start := time.Now()
+	err := client.Client.NewPingService().Do(ctx)
+	check := HealthCheck{Status: HealthOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		check.Status = HealthDown
+		check.Detail = "request failed"
+		if errors.Is(err, context.DeadlineExceeded) {
+			check.Detail = "request timed out"
+		}
+	}
+	return check
+}
+
+// orderMonitorHealth reports the state of the main account's user data stream, which starts
+// with the first trade and reports fills and closed positions.
+func orderMonitorHealth() HealthCheck {
+	client := mainBinanceClient()
+	if client == nil {
+		return HealthCheck{Status: HealthDisabled, Detail: "not configured"}
+	}
+	started, connected, lastEvent := client.monitorState.Snapshot()
+	check := HealthCheck{Status: HealthOK, Detail: "connected"}
+	if !lastEvent.IsZero() {
+		check.LastEvent = &lastEvent
+	}
+	switch {
+	case !started:
+		check.Status = HealthDisabled
+		check.Detail = "starts with the first trade"
+	case !connected:
+		check.Status = HealthDown
+		check.Detail = "reconnecting"
+	}
+	return check
+}
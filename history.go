@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionHistory is the callback action for trade history pagination.
+const ActionHistory = "hist"
+
+// Trade history page sizes.
+const (
+	defaultHistoryPageSize = 10
+	maxHistoryPageSize     = 25
+)
+
+// handleHistoryCommand shows the first page of recent trades. "/history 20" sets the page size.
+func handleHistoryCommand(message *tgbotapi.Message) {
+	pageSize := defaultHistoryPageSize
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > maxHistoryPageSize {
+			bot.Send(tgbotapi.NewMessage(message.Chat.ID, tr(message.Chat.ID, "Usage: /history [1-%d]", maxHistoryPageSize)))
+			return
+		}
+		pageSize = n
+	}
+	showTradeHistory(message.Chat.ID, 0, 0, pageSize)
+}
+
+// handleHistoryCallback handles "hist|<page>|<pageSize>" from the pagination buttons.
+func handleHistoryCallback(chatID int64, messageID int, parts []string) {
+	if len(parts) < 2 {
+		telegramLog.Warn("Invalid history callback data", "data", parts)
+		return
+	}
+	page, err := strconv.Atoi(parts[0])
+	if err != nil || page < 0 {
+		return
+	}
+	pageSize, err := strconv.Atoi(parts[1])
+	if err != nil || pageSize < 1 || pageSize > maxHistoryPageSize {
+		return
+	}
+	showTradeHistory(chatID, messageID, page, pageSize)
+}
+
+// showTradeHistory sends a page of recent trades, or edits messageID in place when paging.
+func showTradeHistory(chatID int64, messageID int, page, pageSize int) {
+	trades, total, err := GetRecentTrades(page*pageSize, pageSize)
+	if err != nil {
+		telegramLog.Error("Failed to load trade history", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load trade history.")))
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	text := tr(chatID, "<b>Trade History</b>\n\n")
+	if total == 0 {
+		text += tr(chatID, "No trades yet.")
+	} else {
+		pages := int((total + int64(pageSize) - 1) / int64(pageSize))
+		text += tr(chatID, "Page %d of %d (%d trades)\n", page+1, pages, total)
+		for _, trade := range trades {
+			text += "\n" + formatTradeHistoryEntry(chatID, &trade)
+		}
+
+		var row []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "« Prev"),
+				fmt.Sprintf("%s|%d|%d", ActionHistory, page-1, pageSize)))
+		}
+		if page+1 < pages {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Next »"),
+				fmt.Sprintf("%s|%d|%d", ActionHistory, page+1, pageSize)))
+		}
+		if len(row) > 0 {
+			keyboard = append(keyboard, row)
+		}
+	}
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		edit.ParseMode = "HTML"
+		if len(keyboard) > 0 {
+			edit.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+		}
+		if _, err := bot.Send(edit); err != nil {
+			telegramLog.Error("Failed to edit trade history", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if len(keyboard) > 0 {
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send trade history", "chat_id", chatID, "error", err)
+	}
+}
+
+// formatTradeHistoryEntry describes one trade on two lines, with its close time in the chat's timezone.
+func formatTradeHistoryEntry(chatID int64, trade *Trade) string {
+	emoji := "\U0001F7E2"
+	if trade.Profit < 0 {
+		emoji = "\U0001F534"
+	}
+
+	symbol := trade.Symbol
+	if symbol == "" {
+		symbol = trade.SignalID // Trades stored before symbols were recorded
+	}
+	direction := "-"
+	switch trade.Side {
+	case "BUY":
+		direction = "Long"
+	case "SELL":
+		direction = "Short"
+	}
+
+	duration := "-"
+	if d := trade.Duration(); d > 0 {
+		duration = formatDuration(d)
+	}
+
+	pnl := fmt.Sprintf("%.2f USDT", trade.Profit)
+	if r := trade.RMultipleText(); r != "" {
+		pnl += " (" + r + ")"
+	}
+
+	return fmt.Sprintf("%s <b>%s</b> %s | %s\n    %s → %s | PnL %s | %s\n",
+		emoji, symbol, direction, formatUserTime(chatID, trade.Timestamp),
+		formatFloat(trade.EntryPrice), formatFloat(trade.ExitPrice), pnl, duration)
+}
+
+// formatDuration renders a duration as e.g. "2d 3h", "3h 12m" or "45m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
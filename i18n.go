@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionLanguage is the callback action for choosing the bot language.
+const ActionLanguage = "lang"
+
+// Supported languages. English text doubles as the catalog key, so it needs no catalog.
+const (
+	LangEnglish = "en"
+	LangSpanish = "es"
+)
+
+// languageNames lists the supported languages in menu order with their display names.
+var languageNames = []struct{ Code, Name string }{
+	{LangEnglish, "English"},
+	{LangSpanish, "Español"},
+}
+
+// tr translates an English message into the chat's language and formats it with args.
+// Messages missing from the catalog are shown in English.
+func tr(chatID int64, message string, args ...interface{}) string {
+	return translate(userSettings.Get(chatID).Language, message, args...)
+}
+
+// translate looks up an English message in a language's catalog and formats it with args.
+func translate(lang, message string, args ...interface{}) string {
+	if translated, ok := catalog[lang][message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// enabledText translates "enabled" or "disabled" for toggle confirmations.
+func enabledText(chatID int64, enabled bool) string {
+	if enabled {
+		return tr(chatID, "enabled")
+	}
+	return tr(chatID, "disabled")
+}
+
+// handleLanguageCommand shows the language choices.
+func handleLanguageCommand(chatID int64) {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range languageNames {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(lang.Name, fmt.Sprintf("%s|%s", ActionLanguage, lang.Code)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Choose your language:"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send language options", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindMenu)
+}
+
+// setLanguage stores the chat's language choice.
+func setLanguage(chatID int64, lang string) {
+	if lang != LangEnglish {
+		if _, ok := catalog[lang]; !ok {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid language selected.")))
+			return
+		}
+	}
+
+	settings := userSettings.Get(chatID)
+	settings.Language = lang
+	userSettings.Set(chatID, settings)
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Language has been updated.")))
+}
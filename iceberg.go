@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// OrderTagIceberg prefixes the tags of the slices of an iceberg entry after the first, e.g.
+// "ice2"; the first slice keeps the entry tag.
+const OrderTagIceberg = "ice"
+
+// maxIcebergSlices caps the number of orders an entry is split into; larger entries get
+// larger slices.
+const maxIcebergSlices = 10
+
+// minIcebergNotional is the smallest IcebergNotional accepted, well above Binance's minimum
+// order notional so every slice can be placed.
+const minIcebergNotional = 100
+
+// Slices are placed a random delay apart in this range, so the entry doesn't trade on a
+// regular schedule.
+const (
+	icebergMinDelay = time.Second
+	icebergMaxDelay = 4 * time.Second
+)
+
+// icebergTag returns the order tag of the zero-based slice of an iceberg entry.
+func icebergTag(slice int) string {
+	if slice == 0 {
+		return OrderTagEntry
+	}
+	return fmt.Sprintf("%s%d", OrderTagIceberg, slice+1)
+}
+
+// isEntryOrderTag reports whether the tag belongs to an entry order or an iceberg slice of one.
+func isEntryOrderTag(tag string) bool {
+	return tag == OrderTagEntry || strings.HasPrefix(tag, OrderTagIceberg)
+}
+
+// useIceberg reports whether the settings split the entry into slices: USDT-M market entries
+// larger than IcebergNotional.
+func useIceberg(settings *UserSettings) bool {
+	return settings.TradingMode == "Market" && settings.MarketType != MarketTypeCoinM &&
+		settings.IcebergNotional > 0 && settings.AmountUSDT > settings.IcebergNotional
+}
+
+// lotStepSize returns the symbol's LOT_SIZE step size, which order quantities are rounded to.
+func lotStepSize(info *futures.Symbol) (float64, error) {
+	stepStr, err := getFilterValue(info.Filters, "LOT_SIZE", "stepSize")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get step size: %v", err)
+	}
+	step, err := strconv.ParseFloat(stepStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse step size: %v", err)
+	}
+	return step, nil
+}
+
+// icebergSlices splits quantity into slices of at most the share of notional per slice,
+// rounded down to the step size, with the rest in the last slice.
+func icebergSlices(quantity, step, amountUSDT, sliceNotional float64) []float64 {
+	count := min(int(math.Ceil(amountUSDT/sliceNotional)), maxIcebergSlices)
+	size := math.Floor(quantity/float64(count)/step) * step
+	if count <= 1 || size <= 0 {
+		return []float64{quantity}
+	}
+	slices := make([]float64, count)
+	for i := range slices[:count-1] {
+		slices[i] = size
+	}
+	slices[count-1] = quantity - size*float64(count-1)
+	return slices
+}
+
+// placeIcebergEntry places a market entry as a series of smaller market orders a random few
+// seconds apart, to take less of the book at once, and reports the aggregate fill to the user.
+// Before each slice the price is checked against MaxSlippage; if it moved too far, or ctx is
+// done, the remaining slices are skipped. It returns the quantity filled, empty if nothing was,
+// and why it stopped early.
+func (b *BinanceClient) placeIcebergEntry(ctx context.Context, signal *AlertMessage, settings *UserSettings, userID int64) (string, error) {
+	symbol, side := signal.Symbol, signalSide(signal)
+	info, err := b.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return "", err
+	}
+	step, err := lotStepSize(info)
+	if err != nil {
+		return "", err
+	}
+	total, err := b.calculateQuantity(ctx, symbol, settings.AmountUSDT, signal.EntryPrice)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate quantity: %v", err)
+	}
+	quantity, _ := strconv.ParseFloat(total, 64)
+	slices := icebergSlices(quantity, step, settings.AmountUSDT, settings.IcebergNotional)
+	b.sendMessageToUser(userID, tr(userID, "Splitting the %s entry of %s into %d orders.", symbol, total, len(slices)))
+
+	var filled, notional float64
+	var placed int
+	var stopped error
+	for i, slice := range slices {
+		if i > 0 {
+			delay := icebergMinDelay + rand.N(icebergMaxDelay-icebergMinDelay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				stopped = ctx.Err()
+			}
+		}
+		if stopped == nil {
+			stopped = b.checkSlippage(ctx, symbol, side, signal.EntryPrice, settings.MaxSlippage)
+		}
+		if stopped != nil {
+			break
+		}
+
+		res, err := b.placeIcebergSlice(ctx, symbol, side, formatDecimal(slice, step), clientOrderID(signal.SignalID, icebergTag(i)))
+		if err != nil {
+			stopped = err
+			break
+		}
+		// Track the position as the first slice fills, so the fills of later ones are counted
+		if placed == 0 {
+			b.trackPosition(signal, side, userID)
+		}
+		placed++
+		executed, _ := strconv.ParseFloat(res.ExecutedQuantity, 64)
+		price, _ := strconv.ParseFloat(res.AvgPrice, 64)
+		filled += executed
+		notional += executed * price
+	}
+	if placed == 0 {
+		return "", stopped
+	}
+
+	text := tr(userID, "Iceberg entry for %s: %s filled in %d of %d orders", symbol, formatDecimal(filled, step), placed, len(slices))
+	if filled > 0 {
+		text += tr(userID, " at an average price of %s", formatFloat(roundToSixDecimal(notional/filled)))
+	}
+	if stopped != nil {
+		text += tr(userID, ". The remaining orders were skipped: %v", tradeErrorText(userID, stopped))
+	}
+	b.sendMessageToUser(userID, text+".")
+	if filled <= 0 {
+		if stopped == nil {
+			stopped = fmt.Errorf("none of the %d orders filled", placed)
+		}
+		return "", stopped
+	}
+	return formatDecimal(filled, step), stopped
+}
+
+// placeIcebergSlice submits one Market order of an iceberg entry and waits for its fill.
+func (b *BinanceClient) placeIcebergSlice(ctx context.Context, symbol string, side futures.SideType, quantity, clientID string) (*futures.CreateOrderResponse, error) {
+	res, err := b.Client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(futures.OrderTypeMarket).
+		Quantity(quantity).
+		NewClientOrderID(clientID).
+		NewOrderResponseType(futures.NewOrderRespTypeRESULT).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=MARKET quantity=%s", symbol, side, quantity), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
+	return res, err
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// importWindow is the longest time range Binance returns account trades for in one request.
+const importWindow = 7 * 24 * time.Hour
+
+// importPageSize is the most trades or income records Binance returns per request.
+const importPageSize = 1000
+
+// positionDust is the quantity below which a rebuilt position counts as closed.
+const positionDust = 1e-9
+
+// importDateLayout is the date format of /import arguments.
+const importDateLayout = "2006-01-02"
+
+// ImportResult counts what an import of trade history did.
+type ImportResult struct {
+	Imported   int // Closed positions stored as trades
+	Duplicates int // Positions already stored, by an earlier import or while the bot traded them
+	Incomplete int // Positions opened before the range or still open at its end
+}
+
+// importedPosition is a position rebuilt from account trades.
+type importedPosition struct {
+	TrackedPosition
+	size        float64 // Quantity still open
+	orderIDs    []int64 // Entry orders
+	lastTradeID int64
+	closedAt    time.Time
+}
+
+// importTrades rebuilds the positions closed between from and to from the account's futures
+// trades and stores each as a Trade, linked to its signal when the bot placed its entry.
+func (b *BinanceClient) importTrades(from, to time.Time) (ImportResult, error) {
+	var result ImportResult
+	symbols, err := b.tradedSymbols(from, to)
+	if err != nil {
+		return result, err
+	}
+	for _, symbol := range symbols {
+		fills, err := b.accountTrades(symbol, from, to)
+		if err != nil {
+			return result, err
+		}
+		positions, incomplete := rebuildPositions(symbol, fills)
+		result.Incomplete += incomplete
+		for _, position := range positions {
+			stored, err := b.storeImportedTrade(position)
+			if err != nil {
+				return result, err
+			}
+			if stored {
+				result.Imported++
+			} else {
+				result.Duplicates++
+			}
+		}
+	}
+	return result, nil
+}
+
+// tradedSymbols returns the symbols with realized PnL between from and to, from the income history.
+func (b *BinanceClient) tradedSymbols(from, to time.Time) ([]string, error) {
+	seen := make(map[string]bool)
+	for start := from; start.Before(to); start = start.Add(importWindow) {
+		end := minTime(start.Add(importWindow), to)
+		for pageStart := start; ; {
+			incomes, err := b.Client.NewGetIncomeHistoryService().
+				IncomeType("REALIZED_PNL").
+				StartTime(pageStart.UnixMilli()).
+				EndTime(end.UnixMilli() - 1).
+				Limit(importPageSize).
+				Do(context.Background())
+			if err != nil {
+				return nil, fmt.Errorf("failed to get income history: %w", err)
+			}
+			for _, income := range incomes {
+				seen[income.Symbol] = true
+			}
+			if len(incomes) < importPageSize {
+				break
+			}
+			pageStart = time.UnixMilli(incomes[len(incomes)-1].Time + 1)
+		}
+	}
+
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// accountTrades returns the account's fills for a symbol between from and to, oldest first.
+func (b *BinanceClient) accountTrades(symbol string, from, to time.Time) ([]*futures.AccountTrade, error) {
+	var fills []*futures.AccountTrade
+	for start := from; start.Before(to); start = start.Add(importWindow) {
+		end := minTime(start.Add(importWindow), to)
+		for pageStart := start; ; {
+			page, err := b.Client.NewListAccountTradeService().
+				Symbol(symbol).
+				StartTime(pageStart.UnixMilli()).
+				EndTime(end.UnixMilli() - 1).
+				Limit(importPageSize).
+				Do(context.Background())
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s trades: %w", symbol, err)
+			}
+			fills = append(fills, page...)
+			if len(page) < importPageSize {
+				break
+			}
+			pageStart = time.UnixMilli(page[len(page)-1].Time + 1)
+		}
+	}
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].ID < fills[j].ID })
+	return fills, nil
+}
+
+// rebuildPositions replays a symbol's fills and returns the positions that were opened and
+// closed within them, and how many positions were incomplete. Hedge-mode long and short
+// positions are rebuilt separately.
+func rebuildPositions(symbol string, fills []*futures.AccountTrade) ([]*importedPosition, int) {
+	var closed []*importedPosition
+	incomplete := 0
+	open := make(map[futures.PositionSideType]*importedPosition)
+	predating := make(map[futures.PositionSideType]bool)
+
+	for _, fill := range fills {
+		qty, _ := strconv.ParseFloat(fill.Quantity, 64)
+		price, _ := strconv.ParseFloat(fill.Price, 64)
+		realized, _ := strconv.ParseFloat(fill.RealizedPnl, 64)
+		fee, _ := strconv.ParseFloat(fill.Commission, 64)
+
+		for qty > positionDust {
+			position := open[fill.PositionSide]
+			if position == nil {
+				// Fills realizing PnL on a flat position close one opened before the range
+				if realized != 0 {
+					if !predating[fill.PositionSide] {
+						incomplete++
+					}
+					predating[fill.PositionSide] = true
+					break
+				}
+				predating[fill.PositionSide] = false
+				position = &importedPosition{
+					TrackedPosition: TrackedPosition{Symbol: symbol, Side: fill.Side, OpenedAt: time.UnixMilli(fill.Time)},
+				}
+				open[fill.PositionSide] = position
+			}
+
+			// A fill larger than the position closes it and opens the rest the other way
+			part := qty
+			if fill.Side != position.Side {
+				part = math.Min(qty, position.size)
+			}
+			share := part / qty
+			partFee := fee * share
+			position.addFee(partFee, fill.CommissionAsset, fill.Maker)
+			position.lastTradeID = fill.ID
+			if fill.Side == position.Side {
+				position.EntryQty += part
+				position.EntryNotional += part * price
+				position.size += part
+				position.orderIDs = append(position.orderIDs, fill.OrderID)
+			} else {
+				position.ExitQty += part
+				position.ExitNotional += part * price
+				position.RealizedPnL += realized
+				position.size -= part
+				// Only the closing part realizes PnL
+				realized = 0
+			}
+			qty -= part
+			fee -= partFee
+
+			if position.size <= positionDust {
+				position.closedAt = time.UnixMilli(fill.Time)
+				delete(open, fill.PositionSide)
+				closed = append(closed, position)
+			}
+		}
+	}
+	return closed, incomplete + len(open)
+}
+
+// storeImportedTrade stores a rebuilt position as a trade unless it is already stored, and
+// reports whether it was stored.
+func (b *BinanceClient) storeImportedTrade(position *importedPosition) (bool, error) {
+	importID := fmt.Sprintf("%s-%d", position.Symbol, position.lastTradeID)
+	var count int64
+	if err := db.Model(&Trade{}).Where("import_id = ?", importID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check imported trades: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	// Entries the bot placed are in the orders table, which links them to their signal
+	var order Order
+	err := db.Where("symbol = ? AND order_id IN ? AND signal_id <> ''", position.Symbol, position.orderIDs).First(&order).Error
+	if err == nil {
+		position.SignalID = order.SignalID
+		if err := db.Model(&Trade{}).Where("signal_id = ?", order.SignalID).Count(&count).Error; err != nil {
+			return false, fmt.Errorf("failed to check signal trades: %w", err)
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+
+	b.convertOtherFees(&position.TrackedPosition)
+	trade := &Trade{
+		SignalID:    position.SignalID,
+		Symbol:      position.Symbol,
+		Side:        string(position.Side),
+		Strategy:    tradeStrategy(position.SignalID),
+		EntryPrice:  position.AverageEntryPrice(),
+		ExitPrice:   position.AverageExitPrice(),
+		GrossProfit: position.RealizedPnL,
+		MakerFees:   position.MakerFees,
+		TakerFees:   position.TakerFees,
+		InitialRisk: tradeInitialRisk(position.SignalID, position.AverageEntryPrice(), position.EntryQty),
+		OpenedAt:    position.OpenedAt,
+		Timestamp:   position.closedAt,
+		ImportID:    importID,
+	}
+	if err := StoreTrade(trade); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// minTime returns the earlier of two times.
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// handleImportCommand imports the sender's closed futures positions for "/import <from> [to]",
+// with dates as YYYY-MM-DD in UTC. The range ends today if to is left out.
+func handleImportCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	usage := tr(chatID, "Usage: /import <from> [to], with dates as YYYY-MM-DD")
+	if len(args) < 1 || len(args) > 2 {
+		bot.Send(tgbotapi.NewMessage(chatID, usage))
+		return
+	}
+	from, err := time.Parse(importDateLayout, args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, usage))
+		return
+	}
+	to := time.Now().UTC()
+	if len(args) == 2 {
+		end, err := time.Parse(importDateLayout, args[1])
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, usage))
+			return
+		}
+		// Include the whole end day
+		to = minTime(end.Add(24*time.Hour), to)
+	}
+	if !from.Before(to) {
+		bot.Send(tgbotapi.NewMessage(chatID, usage))
+		return
+	}
+
+	client, account, err := userAccountClient(senderID(message))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Account %s is unavailable: %v", account, err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Importing trades from %s to %s...", from.Format(importDateLayout), to.Format(importDateLayout))))
+
+	go func() {
+		result, err := client.importTrades(from, to)
+		if err != nil {
+			dbLog.Error("Trade import failed", "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Import stopped after %d trades: %v", result.Imported, err)))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID,
+			"Imported %d trades. %d were already recorded and %d positions were skipped because they opened before the range or are still open.",
+			result.Imported, result.Duplicates, result.Incomplete)))
+	}()
+}
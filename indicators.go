@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Indicator periods, and the candles fetched so the slowest EMA has settled.
+const (
+	rsiPeriod        = 14
+	atrPeriod        = 14
+	emaFastPeriod    = 50
+	emaSlowPeriod    = 200
+	indicatorCandles = 250
+)
+
+// RSI levels above and below which signals note the market as overbought or oversold.
+const (
+	rsiOverbought = 70
+	rsiOversold   = 30
+)
+
+// maxATRSLMultiplier caps the ATR multiple an ATR-based SL may be set to.
+const maxATRSLMultiplier = 20
+
+// SignalIndicators is the technical context of a signal, computed from closed candles of its
+// timeframe. Indicators without enough candles are 0.
+type SignalIndicators struct {
+	Interval string // Binance kline interval, e.g. 1h
+	Close    float64
+	RSI      float64
+	EMAFast  float64 // EMA(50)
+	EMASlow  float64 // EMA(200)
+	ATR      float64
+}
+
+// signalIndicators fetches recent candles of the signal's timeframe and computes RSI, EMA(50),
+// EMA(200) and ATR from the closed ones.
+func (b *BinanceClient) signalIndicators(ctx context.Context, signal *AlertMessage) (*SignalIndicators, error) {
+	interval := chartInterval(signal.Timeframe)
+	klines, err := b.Client.NewKlinesService().
+		Symbol(signal.Symbol).
+		Interval(interval).
+		Limit(indicatorCandles + 1).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines: %v", err)
+	}
+	// The last candle is still open
+	if len(klines) < 2 {
+		return nil, fmt.Errorf("not enough klines for %s", signal.Symbol)
+	}
+	klines = klines[:len(klines)-1]
+
+	highs := make([]float64, len(klines))
+	lows := make([]float64, len(klines))
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		for _, field := range []struct {
+			value string
+			dest  *float64
+		}{{k.High, &highs[i]}, {k.Low, &lows[i]}, {k.Close, &closes[i]}} {
+			if *field.dest, err = strconv.ParseFloat(field.value, 64); err != nil {
+				return nil, fmt.Errorf("failed to parse kline: %v", err)
+			}
+		}
+	}
+
+	return &SignalIndicators{
+		Interval: interval,
+		Close:    closes[len(closes)-1],
+		RSI:      rsi(closes, rsiPeriod),
+		EMAFast:  ema(closes, emaFastPeriod),
+		EMASlow:  ema(closes, emaSlowPeriod),
+		ATR:      atr(highs, lows, closes, atrPeriod),
+	}, nil
+}
+
+// rsi returns Wilder's relative strength index of the closes, or 0 with too few of them.
+func rsi(closes []float64, period int) float64 {
+	if len(closes) <= period {
+		return 0
+	}
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		change := closes[i] - closes[i-1]
+		gain += math.Max(change, 0)
+		loss += math.Max(-change, 0)
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	for i := period + 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		gain = (gain*float64(period-1) + math.Max(change, 0)) / float64(period)
+		loss = (loss*float64(period-1) + math.Max(-change, 0)) / float64(period)
+	}
+	if loss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// ema returns the exponential moving average of the closes, seeded with the simple average of
+// the first period, or 0 with too few closes.
+func ema(closes []float64, period int) float64 {
+	if len(closes) < period {
+		return 0
+	}
+	var value float64
+	for _, c := range closes[:period] {
+		value += c
+	}
+	value /= float64(period)
+	k := 2 / float64(period+1)
+	for _, c := range closes[period:] {
+		value = c*k + value*(1-k)
+	}
+	return value
+}
+
+// atr returns Wilder's average true range, or 0 with too few candles.
+func atr(highs, lows, closes []float64, period int) float64 {
+	if len(closes) <= period {
+		return 0
+	}
+	trueRange := func(i int) float64 {
+		return math.Max(highs[i]-lows[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+	}
+	var value float64
+	for i := 1; i <= period; i++ {
+		value += trueRange(i)
+	}
+	value /= float64(period)
+	for i := period + 1; i < len(closes); i++ {
+		value = (value*float64(period-1) + trueRange(i)) / float64(period)
+	}
+	return value
+}
+
+// indicatorText renders the technical context block of a signal message, e.g.
+// "Context (1h): RSI 72 (overbought) | EMA50 above EMA200 | ATR 350.5 (0.55%)".
+func indicatorText(chatID int64, indicators *SignalIndicators) string {
+	var parts []string
+	if indicators.RSI > 0 {
+		part := fmt.Sprintf("RSI %.0f", indicators.RSI)
+		if indicators.RSI >= rsiOverbought {
+			part += tr(chatID, " (overbought)")
+		} else if indicators.RSI <= rsiOversold {
+			part += tr(chatID, " (oversold)")
+		}
+		parts = append(parts, part)
+	}
+	if indicators.EMAFast > 0 && indicators.EMASlow > 0 {
+		if indicators.EMAFast > indicators.EMASlow {
+			parts = append(parts, tr(chatID, "EMA50 above EMA200"))
+		} else {
+			parts = append(parts, tr(chatID, "EMA50 below EMA200"))
+		}
+	}
+	if indicators.ATR > 0 && indicators.Close > 0 {
+		parts = append(parts, fmt.Sprintf("ATR %s (%.2f%%)", formatFloat(roundToSixDecimal(indicators.ATR)), indicators.ATR/indicators.Close*100))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return tr(chatID, "<b>Context (%s):</b> %s\n", indicators.Interval, strings.Join(parts, " | "))
+}
+
+// applyATRStopLoss places the SL the settings' ATR multiple away from the entry, instead of the
+// SL percentage, when the settings ask for it and the signal's ATR is known.
+func applyATRStopLoss(signal *AlertMessage, settings *UserSettings) {
+	if settings.ATRSLMultiplier <= 0 || !settings.UseSL || signal.Indicators == nil || signal.Indicators.ATR <= 0 {
+		return
+	}
+	distance := signal.Indicators.ATR * settings.ATRSLMultiplier
+	if signal.SignalType == "Sell" {
+		signal.SL = roundToSixDecimal(signal.EntryPrice + distance)
+	} else if signal.EntryPrice > distance {
+		signal.SL = roundToSixDecimal(signal.EntryPrice - distance)
+	}
+}
+
+// toggleShowIndicators toggles the indicator context block of signal messages.
+func toggleShowIndicators(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.ShowIndicators = !settings.ShowIndicators
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Indicator Context has been %s.", enabledText(chatID, settings.ShowIndicators)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
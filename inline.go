@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxInlineResults caps the signals offered for one inline query.
+const maxInlineResults = 20
+
+// inlineCacheSeconds is how long Telegram may reuse an inline answer; kept short so signal
+// status changes show up quickly.
+const inlineCacheSeconds = 10
+
+// Recent returns the stored signals that match, newest first.
+func (s *SignalStore) Recent(match func(*AlertMessage) bool) []*AlertMessage {
+	s.RLock()
+	defer s.RUnlock()
+
+	var signals []*AlertMessage
+	for _, signal := range s.signals {
+		if match(signal) {
+			signals = append(signals, signal)
+		}
+	}
+	sort.Slice(signals, func(i, j int) bool { return signals[i].ReceivedAt.After(signals[j].ReceivedAt) })
+	return signals
+}
+
+// inlineSignals returns the user's recent signals matching the search: their private copies and
+// the main chat's signals they have no copy of.
+func inlineSignals(userID int64, search string) []*AlertMessage {
+	mainChatID := GetGlobalConfig().TelegramChatID
+	words := strings.Fields(strings.ToLower(search))
+	candidates := signalStore.Recent(func(signal *AlertMessage) bool {
+		if signal.ChatID != userID && signal.ChatID != mainChatID {
+			return false
+		}
+		text := strings.ToLower(strings.Join([]string{signal.Symbol, signal.SignalType, signal.Timeframe}, " "))
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+		return true
+	})
+
+	var signals []*AlertMessage
+	for _, signal := range candidates {
+		if signal.ChatID == mainChatID && signal.ChatID != userID {
+			if _, hasCopy := signalStore.Get(traderSignalID(signal.SignalID, userID)); hasCopy {
+				continue
+			}
+		}
+		signals = append(signals, signal)
+		if len(signals) == maxInlineResults {
+			break
+		}
+	}
+	return signals
+}
+
+// signalCardText renders a read-only card of the signal in the user's language for sharing in
+// other chats. Account, profile and size details are left out.
+func signalCardText(signal *AlertMessage, userID int64) string {
+	emoji := "\U000026AA"
+	if signal.SignalType == "Buy" {
+		emoji = "\U0001F7E2"
+	} else if signal.SignalType == "Sell" {
+		emoji = "\U0001F534"
+	}
+
+	text := tr(userID, "%s <b>%s Signal</b>\n\n", emoji, tr(userID, signal.SignalType))
+	text += tr(userID, "<b>Symbol:</b> %s\n", signal.Symbol)
+	if signal.Timeframe != "" {
+		text += tr(userID, "<b>Timeframe:</b> %s\n", signal.Timeframe)
+	}
+	text += tr(userID, "<b>Time:</b> %s\n", formatSignalTime(userID, signal.Time))
+	text += tr(userID, "<b>Entry Price:</b> %s\n", formatFloat(signal.EntryPrice))
+	for i, tp := range signal.TPs {
+		if tp > 0 {
+			text += tr(userID, "<b>TP%d:</b> %s\n", i+1, formatFloat(tp))
+		}
+	}
+	if signal.SL > 0 {
+		text += tr(userID, "<b>SL:</b> %s\n", formatFloat(signal.SL))
+	}
+	if signal.Confirmed {
+		text += "\n" + tr(userID, "Confirmed")
+	} else if signal.Dismissed {
+		text += "\n" + tr(userID, "Dismissed")
+	}
+	return text
+}
+
+// handleInlineQuery answers "@bot <search>" typed in any chat with the user's recent signals,
+// each sent as a read-only card when picked. Only traders get results.
+func handleInlineQuery(query *tgbotapi.InlineQuery) {
+	userID := query.From.ID
+	results := []interface{}{}
+	if hasRole(userID, RoleTrader) {
+		for i, signal := range inlineSignals(userID, query.Query) {
+			title := fmt.Sprintf("%s %s", tr(userID, signal.SignalType), signal.Symbol)
+			if signal.Timeframe != "" {
+				title += " " + signal.Timeframe
+			}
+			article := tgbotapi.NewInlineQueryResultArticleHTML(strconv.Itoa(i), title, signalCardText(signal, userID))
+			article.Description = tr(userID, "Entry %s", formatFloat(signal.EntryPrice)) + " | " + formatSignalTime(userID, signal.Time)
+			results = append(results, article)
+		}
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheSeconds,
+		IsPersonal:    true,
+	}
+	if _, err := bot.Request(answer); err != nil {
+		telegramLog.Error("Failed to answer inline query", "error", err)
+	}
+}
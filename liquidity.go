@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// liquidityDepthLevels is how many order book levels count as top-of-book liquidity.
+const liquidityDepthLevels = 20
+
+// bookDepth returns the USDT notional on the top liquidityDepthLevels levels of the side of the
+// book an order on side Buy or Sell fills against: the asks for a buy, the bids for a sell.
+func (b *BinanceClient) bookDepth(ctx context.Context, symbol, signalType string) (float64, error) {
+	book, err := b.Client.NewDepthService().Symbol(symbol).Limit(liquidityDepthLevels).Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get order book: %v", err)
+	}
+	levels := book.Asks
+	if signalType == "Sell" {
+		levels = book.Bids
+	}
+	var depth float64
+	for _, level := range levels {
+		price, quantity, err := level.Parse()
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse order book: %v", err)
+		}
+		depth += price * quantity
+	}
+	return depth, nil
+}
+
+// quoteVolume returns the symbol's 24h volume in USDT.
+func (b *BinanceClient) quoteVolume(ctx context.Context, symbol string) (float64, error) {
+	stats, err := b.Client.NewListPriceChangeStatsService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get 24h volume: %v", err)
+	}
+	if len(stats) == 0 {
+		return 0, fmt.Errorf("no price data for symbol %s", symbol)
+	}
+	return strconv.ParseFloat(stats[0].QuoteVolume, 64)
+}
+
+// liquidityWarning describes why the market is too thin for the order the settings would
+// place: a 24h volume below MinQuoteVolume, or an order larger than MaxBookShare percent of the
+// top-of-book liquidity it would take, in the chat's language. It returns "" if neither applies.
+func (b *BinanceClient) liquidityWarning(ctx context.Context, chatID int64, signal *AlertMessage, settings *UserSettings) (string, error) {
+	if (settings.MaxBookShare <= 0 && settings.MinQuoteVolume <= 0) || signal.Symbol == "" {
+		return "", nil
+	}
+
+	var warnings []string
+	if settings.MinQuoteVolume > 0 {
+		volume, err := b.quoteVolume(ctx, signal.Symbol)
+		if err != nil {
+			return "", err
+		}
+		if volume < settings.MinQuoteVolume {
+			warnings = append(warnings, tr(chatID, "24h volume is %.0f USDT, below the %.0f USDT minimum", volume, settings.MinQuoteVolume))
+		}
+	}
+	if settings.MaxBookShare > 0 {
+		depth, err := b.bookDepth(ctx, signal.Symbol, signal.SignalType)
+		if err != nil {
+			return "", err
+		}
+		side := tr(chatID, "ask")
+		if signal.SignalType == "Sell" {
+			side = tr(chatID, "bid")
+		}
+		if depth <= 0 {
+			warnings = append(warnings, tr(chatID, "the order book has no %s levels", side))
+		} else if share := settings.AmountUSDT / depth * 100; share > settings.MaxBookShare {
+			warnings = append(warnings, tr(chatID, "the %.2f USDT order is %.1f%% of the %.0f USDT on the top %d %s levels (limit %.1f%%)",
+				settings.AmountUSDT, share, depth, liquidityDepthLevels, side, settings.MaxBookShare))
+		}
+	}
+	if len(warnings) == 0 {
+		return "", nil
+	}
+	return tr(chatID, "Thin liquidity: %s", strings.Join(warnings, "; ")), nil
+}
+
+// toggleBlockOnThinLiquidity toggles whether trades are blocked when liquidity is too thin.
+func toggleBlockOnThinLiquidity(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.BlockOnThinLiquidity = !settings.BlockOnThinLiquidity
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Block on Thin Liquidity has been set to %t.", settings.BlockOnThinLiquidity))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -0,0 +1,736 @@
+package main
+
+// catalog holds the translations for each language other than English, keyed by the English message.
+// Translations must keep the formatting verbs of the English message in the same order.
+var catalog = map[string]map[string]string{
+	LangSpanish: {
+		// Start and commands
+		"Welcome! Use /settings to configure your trading options.\nUse /connect in a private chat to trade signals on your own Binance account.\nUse /language to change the bot language.": "¡Bienvenido! Usa /settings para configurar tus opciones de trading.\nUsa /connect en un chat privado para operar las señales en tu propia cuenta de Binance.\nUsa /language para cambiar el idioma del bot.",
+		"Unknown command.": "Comando desconocido.",
+		"Please use the /settings commands to interact.": "Usa los comandos de /settings para interactuar.",
+		"Please select an option from the menu.":         "Selecciona una opción del menú.",
+		"This command requires the %s role.":             "Este comando requiere el rol %s.",
+		"Your role does not allow this action.":          "Tu rol no permite esta acción.",
+		"Choose your language:":                          "Elige tu idioma:",
+		"Invalid language selected.":                     "Idioma no válido.",
+		"Language has been updated.":                     "Se ha actualizado el idioma.",
+
+		// Signal messages
+		"%s <b>%s Signal</b>\n\n":                "%s <b>Señal de %s</b>\n\n",
+		"Buy":                                    "Compra",
+		"Sell":                                   "Venta",
+		"<b>Symbol:</b> %s\n":                    "<b>Símbolo:</b> %s\n",
+		"<b>Timeframe:</b> %s\n":                 "<b>Temporalidad:</b> %s\n",
+		"<b>Time:</b> %s\n":                      "<b>Hora:</b> %s\n",
+		"<b>Entry Price:</b> %s\n":               "<b>Precio de entrada:</b> %s\n",
+		"<b>TP%d:</b> %s\n":                      "<b>TP%d:</b> %s\n",
+		"<b>SL:</b> %s\n":                        "<b>SL:</b> %s\n",
+		"<b>High Price:</b> %s\n":                "<b>Precio máximo:</b> %s\n",
+		"<b>Low Price:</b> %s\n":                 "<b>Precio mínimo:</b> %s\n",
+		"<b>Midpoint:</b> %s\n":                  "<b>Punto medio:</b> %s\n",
+		"<b>Account:</b> %s\n":                   "<b>Cuenta:</b> %s\n",
+		"<b>Account:</b> %s (Bybit)\n":           "<b>Cuenta:</b> %s (Bybit)\n",
+		"<b>Profile:</b> %s\n":                   "<b>Perfil:</b> %s\n",
+		"<b>Est. Liquidation (%s %dx):</b> %s\n": "<b>Liquidación est. (%s %dx):</b> %s\n",
+		"<i>Isolated estimate; with cross margin your balance pushes liquidation further away.</i>\n":                      "<i>Estimación aislada; con margen cruzado tu saldo aleja la liquidación.</i>\n",
+		"\n⚠️ SL is beyond the estimated liquidation price and may never execute. Lower the leverage or tighten the SL.\n": "\n⚠️ El SL está más allá del precio de liquidación estimado y podría no ejecutarse nunca. Reduce el apalancamiento o acerca el SL.\n",
+		"\n✅ Signal confirmed and sent to Binance.":                                                                        "\n✅ Señal confirmada y enviada a Binance.",
+		"\n❌ Signal has been dismissed.":                                                                                   "\n❌ La señal ha sido descartada.",
+		"%s %s: blue entry, green TPs, red SL":                                                                             "%s %s: entrada en azul, TPs en verde, SL en rojo",
+
+		// Signal keyboard and editing
+		"Confirm":                    "Confirmar",
+		"Edit":                       "Editar",
+		"Dismiss":                    "Descartar",
+		"Preview":                    "Vista previa",
+		"Profile":                    "Perfil",
+		"Back":                       "Atrás",
+		"Entry Price":                "Precio de entrada",
+		"Add TP%d":                   "Añadir TP%d",
+		"Set High Price":             "Usar precio máximo",
+		"Set Low Price":              "Usar precio mínimo",
+		"Set Midpoint":               "Usar punto medio",
+		"Signal not found.":          "Señal no encontrada.",
+		"Signal has been dismissed.": "La señal ha sido descartada.",
+		"This %.2f USDT trade is above the %.2f USDT limit. Another trader must also press Confirm within %d minutes.": "Esta operación de %.2f USDT supera el límite de %.2f USDT. Otro trader también debe pulsar Confirmar en un plazo de %d minutos.",
+		"You already confirmed this trade. It needs a second trader.":                                                  "Ya confirmaste esta operación. Necesita un segundo trader.",
+		"Unknown field.":                        "Campo desconocido.",
+		"Invalid value for Entry Price.":        "Valor no válido para el precio de entrada.",
+		"Unable to find the message to update.": "No se encontró el mensaje a actualizar.",
+		"Please enter the new value for %s.":    "Introduce el nuevo valor para %s.",
+		"Signal updated successfully.\nSymbol: %s\nTime: %s\nField: %s\nNew Value: %s": "Señal actualizada correctamente.\nSímbolo: %s\nHora: %s\nCampo: %s\nNuevo valor: %s",
+		"\n\n<b>Review before executing</b>\n":                                         "\n\n<b>Revisa antes de ejecutar</b>\n",
+		"<b>Order Size:</b> %s %s (%.2f USDT)\n":                                       "<b>Tamaño de la orden:</b> %s %s (%.2f USDT)\n",
+		"<b>Leverage:</b> %dx %s\n":                                                    "<b>Apalancamiento:</b> %dx %s\n",
+		"<b>Margin Used:</b> %.2f USDT\n":                                              "<b>Margen utilizado:</b> %.2f USDT\n",
+		"Could not estimate the order: %v":                                             "No se pudo estimar la orden: %v",
+		"\nAuto-cancels in %d seconds.":                                                "\nSe cancela automáticamente en %d segundos.",
+		"Execute":                                                                      "Ejecutar",
+		"Confirmation timed out. The signal was not executed.":                         "La confirmación expiró. La señal no se ejecutó.",
+		"This confirmation has expired. Press Confirm again.":                          "Esta confirmación ha expirado. Pulsa Confirmar de nuevo.",
+		"Only the user who pressed Confirm can execute this signal.":                   "Solo el usuario que pulsó Confirmar puede ejecutar esta señal.",
+		"Undo (%ds)": "Deshacer (%ds)",
+		"The undo window for this trade has closed.":                               "El plazo para deshacer esta operación ha terminado.",
+		"Only the user who executed this trade can undo it.":                       "Solo el usuario que ejecutó esta operación puede deshacerla.",
+		"Trade undone: the %s position was closed and its TP/SL orders cancelled.": "Operación deshecha: se cerró la posición de %s y se cancelaron sus órdenes TP/SL.",
+		"Failed to undo the trade for %s: %v":                                      "No se pudo deshacer la operación de %s: %v",
+		"Trade executed on %s successfully.":                                       "Operación ejecutada en %s correctamente.",
+
+		// Mirroring
+		"<b>Mirrored %s to %d accounts:</b>\n": "<b>%s replicado en %d cuentas:</b>\n",
+		"<b>Mirrored To:</b> %s\n":             "<b>Replicado en:</b> %s\n",
+		"\u2705 %s: executed\n":                "\u2705 %s: ejecutada\n",
+
+		// Order preview
+		"\U0001F50D <b>Order Preview for %s</b>\n\n":       "\U0001F50D <b>Vista previa de la orden para %s</b>\n\n",
+		"<b>Entry:</b> %s\n":                               "<b>Entrada:</b> %s\n",
+		"<b>Margin:</b> %s %dx\n":                          "<b>Margen:</b> %s %dx\n",
+		"<b>Notional:</b> %.2f USDT\n":                     "<b>Nocional:</b> %.2f USDT\n",
+		"<b>Estimated Margin:</b> %.2f USDT\n":             "<b>Margen estimado:</b> %.2f USDT\n",
+		"<b>Est. Liquidation Price:</b> %s (isolated)\n":   "<b>Precio de liquidación est.:</b> %s (aislado)\n",
+		"TP/SL orders are not placed for limit entries.\n": "Las órdenes TP/SL no se colocan en entradas límite.\n",
+		"\n❌ Binance rejected the test order: %v":          "\n❌ Binance rechazó la orden de prueba: %v",
+		"\n✅ Binance accepted the test order.":             "\n✅ Binance aceptó la orden de prueba.",
+		"Failed to build preview for %s: %v":               "No se pudo generar la vista previa para %s: %v",
+
+		// Parsed text signals
+		"<b>Parsed Signal</b>\n\n<b>Direction:</b> %s\n<b>Symbol:</b> %s\n<b>Entry:</b> %s\n": "<b>Señal detectada</b>\n\n<b>Dirección:</b> %s\n<b>Símbolo:</b> %s\n<b>Entrada:</b> %s\n",
+		"<b>SL:</b> %s\n<b>Source:</b> %s\n\nSend it as a signal?":                            "<b>SL:</b> %s\n<b>Origen:</b> %s\n\n¿Enviarla como señal?",
+		"Use Signal":                    "Usar señal",
+		"Discard":                       "Descartar",
+		"Parsed signal discarded.":      "Señal detectada descartada.",
+		"Parsed signal not found.":      "Señal detectada no encontrada.",
+		"Failed to send the signal: %v": "No se pudo enviar la señal: %v",
+		"Signal for %s sent.":           "Señal de %s enviada.",
+		"Could not read a signal from the forwarded message: %v": "No se pudo leer una señal del mensaje reenviado: %v",
+
+		// Settings menu
+		"Your Current Settings:\n\n<b>Market Type:</b> %s\n<b>TP/SL Trigger Price:</b> %s\n<b>Margin Mode:</b> %s\n<b>Leverage:</b> %dx\n<b>Asset Mode:</b> %s\n<b>Trading Mode:</b> %s\n<b>Amount (USDT):</b> %.2f\n<b>Use Stop Loss:</b> %t\n<b>Simplified TP/SL:</b> %s %t\n<b>Dynamic Calculation:</b> %s %t\n<b>Tolerance in Market Mode:</b> %s %t\n<b>Funding Warning Threshold:</b> %.4f%%\n<b>Block on High Funding:</b> %t\n<b>DCA Ladder:</b> %t\n<b>Auto Margin Top-Up:</b> %t\n<b>Chart Snapshot:</b> %t\n": "Tu configuración actual:\n\n<b>Tipo de mercado:</b> %s\n<b>Precio de activación TP/SL:</b> %s\n<b>Modo de margen:</b> %s\n<b>Apalancamiento:</b> %dx\n<b>Modo de activos:</b> %s\n<b>Modo de trading:</b> %s\n<b>Importe (USDT):</b> %.2f\n<b>Usar stop loss:</b> %t\n<b>TP/SL simplificado:</b> %s %t\n<b>Cálculo dinámico:</b> %s %t\n<b>Tolerancia en modo mercado:</b> %s %t\n<b>Umbral de aviso de funding:</b> %.4f%%\n<b>Bloquear con funding alto:</b> %t\n<b>Escalera DCA:</b> %t\n<b>Recarga automática de margen:</b> %t\n<b>Gráfico:</b> %t\n",
+		"<b>Market Price Tolerance (fraction):</b> %.4f\n":                         "<b>Tolerancia de precio de mercado (fracción):</b> %.4f\n",
+		"<b>Max Slippage (fraction):</b> %.4f\n":                                   "<b>Deslizamiento máximo (fracción):</b> %.4f\n",
+		"<b>Top-Up at Margin Ratio:</b> %.2f%%\n<b>Top-Up Amount:</b> %.2f USDT\n": "<b>Recargar con ratio de margen:</b> %.2f%%\n<b>Importe de recarga:</b> %.2f USDT\n",
+		"<b>DCA Step:</b> %.2f%%\n<b>DCA Max Orders:</b> %d\n":                     "<b>Paso DCA:</b> %.2f%%\n<b>Órdenes DCA máximas:</b> %d\n",
+		"<b>Auto SL Percentage:</b> %.2f%%\n<b>Auto TP Percentage:</b> %.2f%%\n":   "<b>Porcentaje SL automático:</b> %.2f%%\n<b>Porcentaje TP automático:</b> %.2f%%\n",
+		"<b>TP%d Percentage:</b> %.2f%%\n":                                         "<b>Porcentaje TP%d:</b> %.2f%%\n",
+		"<b>SL Percentage:</b> %.2f%%\n":                                           "<b>Porcentaje SL:</b> %.2f%%\n",
+		"\nClose Percentage for Each TP:\n":                                        "\nPorcentaje de cierre por TP:\n",
+		"TP%d: %.2f%%\n":                                                           "TP%d: %.2f%%\n",
+		"Current Settings":                                                         "Configuración actual",
+		"Market Type":                                                              "Tipo de mercado",
+		"TP/SL Trigger Price":                                                      "Precio de activación TP/SL",
+		"Margin Mode":                                                              "Modo de margen",
+		"Leverage":                                                                 "Apalancamiento",
+		"Asset Mode":                                                               "Modo de activos",
+		"Trading Mode":                                                             "Modo de trading",
+		"Amount (USDT)":                                                            "Importe (USDT)",
+		"Use Stop Loss":                                                            "Usar stop loss",
+		"%s Simplified TP/SL":                                                      "%s TP/SL simplificado",
+		"%s Dynamic Calculation":                                                   "%s Cálculo dinámico",
+		"%s Tolerance in Market Mode":                                              "%s Tolerancia en modo mercado",
+		"Funding Threshold %":                                                      "Umbral de funding %",
+		"Block High Funding":                                                       "Bloquear funding alto",
+		"DCA Ladder":                                                               "Escalera DCA",
+		"DCA Step %":                                                               "Paso DCA %",
+		"DCA Max Orders":                                                           "Órdenes DCA máximas",
+		"Auto Margin Top-Up":                                                       "Recarga automática de margen",
+		"Top-Up Ratio %":                                                           "Ratio de recarga %",
+		"Top-Up Amount":                                                            "Importe de recarga",
+		"Chart Snapshot":                                                           "Gráfico",
+		"Set Market Tolerance":                                                     "Tolerancia de mercado",
+		"Set Max Slippage":                                                         "Deslizamiento máximo",
+		"Set Auto SL %":                                                            "SL automático %",
+		"Set Auto TP %":                                                            "TP automático %",
+		"Set TP%d %%":                                                              "TP%d %%",
+		"Set SL %":                                                                 "SL %",
+		"TP%d Close %%":                                                            "Cierre TP%d %%",
+		"Add TP":                                                                   "Añadir TP",
+		"Remove TP":                                                                "Quitar TP",
+		"Symbol Overrides":                                                         "Ajustes por símbolo",
+		"Settings Profiles":                                                        "Perfiles de configuración",
+		"View Performance":                                                         "Ver rendimiento",
+		"Market":                                                                   "Mercado",
+		"Limit":                                                                    "Límite",
+		"Cross":                                                                    "Cruzado",
+		"Isolated":                                                                 "Aislado",
+		"Single":                                                                   "Único",
+		"Multi":                                                                    "Múltiple",
+		"Mark Price":                                                               "Precio de marca",
+		"Last Price":                                                               "Último precio",
+		"Default":                                                                  "Predeterminado",
+		"Timezone":                                                                 "Zona horaria",
+		"Time Format":                                                              "Formato de hora",
+		"<b>Timezone:</b> %s (%s)\n":                                               "<b>Zona horaria:</b> %s (%s)\n",
+		"Please enter your timezone, e.g. Europe/Madrid, America/New_York or UTC.":     "Introduce tu zona horaria, p. ej. Europe/Madrid, America/New_York o UTC.",
+		"Unknown timezone. Use a name such as Europe/Madrid, America/New_York or UTC.": "Zona horaria desconocida. Usa un nombre como Europe/Madrid, America/New_York o UTC.",
+		"Time Format has been set to %s.":                                              "El formato de hora se ha establecido en %s.",
+		"Two-Step Confirm":                                                             "Confirmación en dos pasos",
+		"<b>Two-Step Confirm:</b> %t\n":                                                "<b>Confirmación en dos pasos:</b> %t\n",
+		"Two-Step Confirm has been %s.":                                                "Confirmación en dos pasos: %s.",
+		"Quiet Hours":                                                                  "Horas de silencio",
+		"Quiet Mode":                                                                   "Modo silencio",
+		"<b>Quiet Hours:</b> %s (%s)\n":                                                "<b>Horas de silencio:</b> %s (%s)\n",
+		"off":                                                                          "desactivado",
+		"silent":                                                                       "sin sonido",
+		"digest":                                                                       "resumen",
+		"suppress":                                                                     "ocultar",
+		"Quiet Mode has been set to %s.":                                               "El modo silencio se ha establecido en %s.",
+		"Invalid quiet hours: %v":                                                      "Horas de silencio no válidas: %v",
+		"Please enter your quiet hours as START-END in your timezone (e.g., 22-7), or \"off\".": "Introduce tus horas de silencio como INICIO-FIN en tu zona horaria (p. ej., 22-7), u \"off\".",
+		"Notifications are not muted.":                                                     "Las notificaciones no están silenciadas.",
+		"Notifications are muted until %s.":                                                "Las notificaciones están silenciadas hasta %s.",
+		"Usage: /mute <30m|2h|1d> or /mute off":                                            "Uso: /mute <30m|2h|1d> o /mute off",
+		"Notifications have been unmuted.":                                                 "Se han reactivado las notificaciones.",
+		"Notifications are muted until %s. Signals are handled as set in Quiet Mode (%s).": "Las notificaciones están silenciadas hasta %s. Las señales se gestionan según el modo silencio (%s).",
+		"<b>Signals received during quiet hours: %d</b>\n\n":                               "<b>Señales recibidas durante las horas de silencio: %d</b>\n\n",
+		"Show %s %s": "Ver %s %s",
+		"\nPrices may have moved since these signals arrived.": "\nLos precios pueden haber cambiado desde que llegaron estas señales.",
+		"Select Margin Mode for %s:":                           "Selecciona el modo de margen para %s:",
+
+		// Settings changes
+		"enabled":                                                               "activado",
+		"disabled":                                                              "desactivado",
+		"%s has been updated.":                                                  "%s se ha actualizado.",
+		"%s has been updated to %s.":                                            "%s se ha actualizado a %s.",
+		"%s for %s has been updated.":                                           "%s para %s se ha actualizado.",
+		"Use Stop Loss has been set to %t.":                                     "Usar stop loss se ha establecido en %t.",
+		"Auto Calculate TPs has been set to %t.":                                "Cálculo automático de TPs se ha establecido en %t.",
+		"Dynamic Calculation has been set to %t.":                               "Cálculo dinámico se ha establecido en %t.",
+		"Block on High Funding has been set to %t.":                             "Bloquear con funding alto se ha establecido en %t.",
+		"Tolerance in Market Mode has been %s.":                                 "Tolerancia en modo mercado: %s.",
+		"DCA Ladder has been %s.":                                               "Escalera DCA: %s.",
+		" It applies to Market entries on USDT-M.":                              " Se aplica a entradas a mercado en USDT-M.",
+		"Chart Snapshot has been %s.":                                           "Gráfico: %s.",
+		"Auto Margin Top-Up has been %s.":                                       "Recarga automática de margen: %s.",
+		" It applies to Isolated positions only.":                               " Solo se aplica a posiciones aisladas.",
+		"Please enter the new percentage for %s (e.g., 1.5).":                   "Introduce el nuevo porcentaje para %s (p. ej., 1.5).",
+		"Market Price Tolerance is not applicable in Market mode.":              "La tolerancia de precio de mercado no se aplica en modo mercado.",
+		"TP%d is not set up. Use Add TP first.":                                 "TP%d no está configurado. Usa Añadir TP primero.",
+		"Invalid percentage. Please enter a number between 0 and %.0f.":         "Porcentaje no válido. Introduce un número entre 0 y %.0f.",
+		"You can set up at most %d TPs.":                                        "Puedes configurar como máximo %d TPs.",
+		"At least one TP is required.":                                          "Se necesita al menos un TP.",
+		"TP%d has been added.":                                                  "TP%d añadido.",
+		"TP%d has been removed.":                                                "TP%d eliminado.",
+		"Unknown setting.":                                                      "Ajuste desconocido.",
+		"Invalid Market Type selected.":                                         "Tipo de mercado no válido.",
+		"Invalid TP/SL Trigger Price selected.":                                 "Precio de activación TP/SL no válido.",
+		"Invalid Margin Mode selected.":                                         "Modo de margen no válido.",
+		"Invalid Asset Mode selected.":                                          "Modo de activos no válido.",
+		"Invalid Trading Mode selected.":                                        "Modo de trading no válido.",
+		"Invalid leverage value. Enter a positive integer up to 125.":           "Apalancamiento no válido. Introduce un entero positivo de hasta 125.",
+		"Invalid leverage value. Enter an integer up to 125, or 0 for default.": "Apalancamiento no válido. Introduce un entero de hasta 125, o 0 para el predeterminado.",
+		"Invalid value. Enter a positive integer up to %d.":                     "Valor no válido. Introduce un entero positivo de hasta %d.",
+		"Invalid value. Enter a positive number, or 0 for default.":             "Valor no válido. Introduce un número positivo, o 0 para el predeterminado.",
+
+		// Symbol overrides
+		"<b>Symbol Overrides</b>\n\n":                          "<b>Ajustes por símbolo</b>\n\n",
+		"No overrides yet. Symbols use your default settings.": "Aún no hay ajustes por símbolo. Los símbolos usan tu configuración predeterminada.",
+		"Add Override":    "Añadir ajuste",
+		"Delete Override": "Eliminar ajuste",
+		"Please enter the symbol to override (e.g., BTCUSDT).":             "Introduce el símbolo a ajustar (p. ej., BTCUSDT).",
+		"Please enter the new value for %s on %s (0 to use your default).": "Introduce el nuevo valor de %s para %s (0 para usar el predeterminado).",
+		"Invalid symbol.":                   "Símbolo no válido.",
+		"Unknown override field.":           "Campo de ajuste desconocido.",
+		"Override for %s has been removed.": "Se ha eliminado el ajuste de %s.",
+		"Failed to load symbol overrides.":  "No se pudieron cargar los ajustes por símbolo.",
+		"Failed to save symbol override.":   "No se pudo guardar el ajuste por símbolo.",
+		"Failed to delete symbol override.": "No se pudo eliminar el ajuste por símbolo.",
+
+		// Settings profiles
+		"<b>Settings Profiles</b>\n\n": "<b>Perfiles de configuración</b>\n\n",
+		"No profiles yet. Save your current settings as a profile to pick it on signals.":                  "Aún no hay perfiles. Guarda tu configuración actual como perfil para elegirlo en las señales.",
+		"Apply a profile as your current settings, or pick one on a signal to use it for that trade only.": "Aplica un perfil como tu configuración actual, o elige uno en una señal para usarlo solo en esa operación.",
+		"Apply %s":              "Aplicar %s",
+		"Delete":                "Eliminar",
+		"Save Current Settings": "Guardar configuración actual",
+		"Please enter a name for a profile with your current settings (e.g., scalp). An existing profile with that name is replaced.": "Introduce un nombre para un perfil con tu configuración actual (p. ej., scalp). Un perfil existente con ese nombre se reemplaza.",
+		"Invalid profile name. Use up to %d letters, digits or underscores.":                                                          "Nombre de perfil no válido. Usa hasta %d letras, dígitos o guiones bajos.",
+		"Profile %s has been saved.":                                    "Se ha guardado el perfil %s.",
+		"Profile %s has been removed.":                                  "Se ha eliminado el perfil %s.",
+		"Profile %s is now your current settings.":                      "El perfil %s es ahora tu configuración actual.",
+		"You have no settings profiles yet. Use /profiles to save one.": "Aún no tienes perfiles de configuración. Usa /profiles para guardar uno.",
+		"Failed to load profile %s.":                                    "No se pudo cargar el perfil %s.",
+		"Failed to load settings profiles.":                             "No se pudieron cargar los perfiles de configuración.",
+		"Failed to save settings profile.":                              "No se pudo guardar el perfil de configuración.",
+		"Failed to delete settings profile.":                            "No se pudo eliminar el perfil de configuración.",
+
+		// Binance accounts
+		"Please send your %s API key. Enable futures trading on the key and keep withdrawals disabled.": "Envía tu clave API de %s. Activa el trading de futuros en la clave y deja los retiros desactivados.",
+		"Now send your %s API secret.":                                                                "Ahora envía tu secreto API de %s.",
+		"API key cannot be empty. Use /connect to try again.":                                         "La clave API no puede estar vacía. Usa /connect para intentarlo de nuevo.",
+		"API secret cannot be empty. Use /connect to try again.":                                      "El secreto API no puede estar vacío. Usa /connect para intentarlo de nuevo.",
+		"%s API key validation failed: %v\nUse /connect to try again.":                                "La validación de la clave API de %s falló: %v\nUsa /connect para intentarlo de nuevo.",
+		"Your %s account is connected. Signals you confirm will now execute on it.":                   "Tu cuenta de %s está conectada. Las señales que confirmes se ejecutarán en ella.",
+		"Your %s account has been disconnected.":                                                      "Tu cuenta de %s ha sido desconectada.",
+		"For your security, please use /connect in a private chat with the bot.":                      "Por tu seguridad, usa /connect en un chat privado con el bot.",
+		"Failed to save your %s credentials.":                                                         "No se pudieron guardar tus credenciales de %s.",
+		"Unknown exchange %q. Use /connect binance or /connect bybit.":                                "Exchange %q desconocido. Usa /connect binance o /connect bybit.",
+		"Could not check your trade code. The signal was not executed.":                               "No se pudo comprobar tu código de operación. La señal no se ejecutó.",
+		"Please enter your PIN within %d seconds to confirm this signal.":                             "Introduce tu PIN en menos de %d segundos para confirmar esta señal.",
+		"Please enter the code from your authenticator app within %d seconds to confirm this signal.": "Introduce el código de tu app de autenticación en menos de %d segundos para confirmar esta señal.",
+		"Wrong code.":                  "Código incorrecto.",
+		"The signal was not executed.": "La señal no se ejecutó.",
+		"Too many wrong codes. Your trade code is locked until %s.":                                    "Demasiados códigos incorrectos. Tu código de operación está bloqueado hasta las %s.",
+		"Could not check your trade code.":                                                             "No se pudo comprobar tu código de operación.",
+		"You have no trade code set.":                                                                  "No tienes ningún código de operación configurado.",
+		"Please send your current PIN to continue.":                                                    "Envía tu PIN actual para continuar.",
+		"Please send the current code from your authenticator app to continue.":                        "Envía el código actual de tu app de autenticación para continuar.",
+		"Failed to remove your trade code.":                                                            "No se pudo eliminar tu código de operación.",
+		"Your trade code has been removed. Confirm now executes right away.":                           "Tu código de operación ha sido eliminado. Confirmar ahora ejecuta la operación de inmediato.",
+		"Usage: /pin, /pin totp or /pin off":                                                           "Uso: /pin, /pin totp o /pin off",
+		"For your security, please use /pin in a private chat with the bot.":                           "Por tu seguridad, usa /pin en un chat privado con el bot.",
+		"Please send a PIN of 4 to 8 digits. You will be asked for it each time you confirm a signal.": "Envía un PIN de 4 a 8 dígitos. Se te pedirá cada vez que confirmes una señal.",
+		"Failed to set up your authenticator.":                                                         "No se pudo configurar tu autenticador.",
+		"Add this key to your authenticator app, then send the 6-digit code it shows to finish.\n\n<b>Key:</b> <code>%s</code>\n<b>Link:</b> <code>%s</code>": "Añade esta clave a tu app de autenticación y envía el código de 6 dígitos que muestre para terminar.\n\n<b>Clave:</b> <code>%s</code>\n<b>Enlace:</b> <code>%s</code>",
+		"The PIN must be 4 to 8 digits. Use /pin to try again.":                            "El PIN debe tener de 4 a 8 dígitos. Usa /pin para intentarlo de nuevo.",
+		"Failed to save your trade code.":                                                  "No se pudo guardar tu código de operación.",
+		"That code doesn't match. Use /pin totp to try again.":                             "Ese código no coincide. Usa /pin totp para intentarlo de nuevo.",
+		"Your trade code is set. You will be asked for it each time you confirm a signal.": "Tu código de operación está configurado. Se te pedirá cada vez que confirmes una señal.",
+		"Failed to disconnect your %s account.":                                            "No se pudo desconectar tu cuenta de %s.",
+		"Account %s is unavailable: %v":                                                    "La cuenta %s no está disponible: %v",
+
+		// Errors
+		"An unexpected error occurred. Please try again later.": "Se produjo un error inesperado. Inténtalo de nuevo más tarde.",
+		"Trade could not be placed because the requested price is outside Binance's allowable range. Please move closer to the current market price and try again.": "No se pudo colocar la operación porque el precio solicitado está fuera del rango permitido por Binance. Acércate al precio de mercado actual e inténtalo de nuevo.",
+		"Binance did not respond in time. Check your open orders and positions before trying again, as the order may still have been placed.":                       "Binance no respondió a tiempo. Revisa tus órdenes abiertas y posiciones antes de volver a intentarlo, ya que la orden pudo haberse colocado.",
+		"Binance's request limit is nearly reached. Please try again in a minute.":                                                                                  "El límite de solicitudes de Binance está casi alcanzado. Inténtalo de nuevo en un minuto.",
+
+		// Roles
+		"Usage: /role <user_id> <admin|trader|viewer>": "Uso: /role <user_id> <admin|trader|viewer>",
+		"Invalid user ID.":                                     "ID de usuario no válido.",
+		"Failed to set role: %v":                               "No se pudo asignar el rol: %v",
+		"User %d is now a %s.":                                 "El usuario %d ahora es %s.",
+		"Failed to load roles.":                                "No se pudieron cargar los roles.",
+		"User Roles:\n":                                        "Roles de usuario:\n",
+		"Usage: /audit [1-%d]":                                 "Uso: /audit [1-%d]",
+		"Failed to load the audit log.":                        "No se pudo cargar el registro de auditoría.",
+		"The audit log is empty.":                              "El registro de auditoría está vacío.",
+		"Audit Log:\n":                                         "Registro de auditoría:\n",
+		"Backup failed: %v":                                    "La copia de seguridad falló: %v",
+		"Backup created: %s":                                   "Copia de seguridad creada: %s",
+		"Usage: /restore <backup name>":                        "Uso: /restore <nombre de la copia>",
+		"Usage: /import <from> [to], with dates as YYYY-MM-DD": "Uso: /import <desde> [hasta], con fechas en formato AAAA-MM-DD",
+		"Importing trades from %s to %s...":                    "Importando operaciones del %s al %s...",
+		"Import stopped after %d trades: %v":                   "La importación se detuvo tras %d operaciones: %v",
+		"Imported %d trades. %d were already recorded and %d positions were skipped because they opened before the range or are still open.": "Se importaron %d operaciones. %d ya estaban registradas y se omitieron %d posiciones porque se abrieron antes del rango o siguen abiertas.",
+		"An admin changed your settings: %s":                        "Un administrador cambió tu configuración: %s",
+		"Restoring %s...":                                           "Restaurando %s...",
+		"Restore failed: %v":                                        "La restauración falló: %v",
+		"The database before the restore was saved as %s.":          "La base de datos anterior a la restauración se guardó como %s.",
+		"Restored %s.":                                              "Restaurada %s.",
+		"No backups found.":                                         "No se encontraron copias de seguridad.",
+		"Available backups:\n":                                      "Copias de seguridad disponibles:\n",
+		"%d: %s (configured admin)\n":                               "%d: %s (administrador configurado)\n",
+		"No admin user is configured, so roles are not enforced.\n": "No hay administrador configurado, así que los roles no se aplican.\n",
+		"\nUsers without a role are viewers.":                       "\nLos usuarios sin rol son observadores.",
+
+		// Trade history and performance
+		"Usage: /history [1-%d]":                       "Uso: /history [1-%d]",
+		"<b>Trade History</b>\n\n":                     "<b>Historial de operaciones</b>\n\n",
+		"No trades yet.":                               "Aún no hay operaciones.",
+		"Page %d of %d (%d trades)\n":                  "Página %d de %d (%d operaciones)\n",
+		"« Prev":                                       "« Anterior",
+		"Next »":                                       "Siguiente »",
+		"Failed to load trade history.":                "No se pudo cargar el historial de operaciones.",
+		"Select the time period for performance data:": "Selecciona el periodo para los datos de rendimiento:",
+		"Previous Day":                                 "Día anterior",
+		"Previous Week":                                "Semana anterior",
+		"Previous Month":                               "Mes anterior",
+		"Previous Year":                                "Año anterior",
+		"Recent Years":                                 "Últimos años",
+		"Failed to fetch trade data: %v":               "No se pudieron obtener los datos de operaciones: %v",
+		"From %s to %s\n":                              "Del %s al %s\n",
+		"Performance Summary":                          "Resumen de rendimiento",
+		"Performance Summary for Previous Day":         "Resumen de rendimiento del día anterior",
+		"Performance Summary for Previous Week":        "Resumen de rendimiento de la semana anterior",
+		"Performance Summary for Previous Month":       "Resumen de rendimiento del mes anterior",
+		"Performance Summary for Previous Year":        "Resumen de rendimiento del año anterior",
+		"\nBy Strategy:\n":                             "\nPor estrategia:\n",
+		"Untagged":                                     "Sin etiqueta",
+		"%d trades, %.0f%% won, net %.2f":              "%d operaciones, %.0f%% ganadas, neto %.2f",
+		"Performance Summary:\nTotal Trades: %d\nWinning Trades: %d\nLosing Trades: %d\nWin/Loss Ratio: %.2f\nAverage Profit: %.2f\nAverage Loss: %.2f\nTotal Profit: %.2f\nTotal Loss: %.2f\nGross Profit: %.2f\nFees: %.2f\nNet Profit: %.2f\nMax Drawdown: %.2f\n": "Resumen de rendimiento:\nOperaciones totales: %d\nOperaciones ganadoras: %d\nOperaciones perdedoras: %d\nRatio ganancia/pérdida: %.2f\nBeneficio medio: %.2f\nPérdida media: %.2f\nBeneficio total: %.2f\nPérdida total: %.2f\nBeneficio bruto: %.2f\nComisiones: %.2f\nBeneficio neto: %.2f\nDrawdown máximo: %.2f\n",
+
+		// Daily and weekly summaries
+		"Daily Summary":                "Resumen diario",
+		"Weekly Summary":               "Resumen semanal",
+		"Failed to build the summary.": "No se pudo generar el resumen.",
+		"Usage: /summary [day|week]":   "Uso: /summary [day|week]",
+
+		// Step buttons for signal prices
+		"Type Value":                            "Escribir valor",
+		"Done":                                  "Listo",
+		"\n\n<b>Adjusting %s:</b> %s":           "\n\n<b>Ajustando %s:</b> %s",
+		"This signal has already been handled.": "Esta señal ya ha sido gestionada.",
+		"%s is not set, type a value instead.":  "%s no está definido, escribe un valor.",
+		"Invalid value for %s.":                 "Valor no válido para %s.",
+
+		// Per-signal leverage and amount
+		"Size":         "Tamaño",
+		"Use Settings": "Usar ajustes",
+		"<b>Leverage:</b> %dx (this trade only)\n":     "<b>Apalancamiento:</b> %dx (solo esta operación)\n",
+		"<b>Amount:</b> %.2f USDT (this trade only)\n": "<b>Cantidad:</b> %.2f USDT (solo esta operación)\n",
+
+		// Watchlist
+		"<b>Watchlist Only:</b> %t\n":                                                "<b>Solo lista de seguimiento:</b> %t\n",
+		"Watchlist Only":                                                             "Solo lista de seguimiento",
+		"Watchlist Only has been %s.":                                                "Solo lista de seguimiento: %s.",
+		"Failed to update the watchlist.":                                            "No se pudo actualizar la lista de seguimiento.",
+		"Failed to load the watchlist.":                                              "No se pudo cargar la lista de seguimiento.",
+		"Usage: /unwatch <symbol> [symbol...]":                                       "Uso: /unwatch <símbolo> [símbolo...]",
+		"%s is not on your watchlist.":                                               "%s no está en tu lista de seguimiento.",
+		"Your watchlist is empty. Add symbols with /watch BTCUSDT.":                  "Tu lista de seguimiento está vacía. Añade símbolos con /watch BTCUSDT.",
+		"<b>Watchlist:</b> %s\n":                                                     "<b>Lista de seguimiento:</b> %s\n",
+		"\nOnly signals for these symbols are sent. Others are listed by /signals.":  "\nSolo se envían señales de estos símbolos. Las demás se listan con /signals.",
+		"\nAll signals are sent. Enable Watchlist Only in /settings to filter them.": "\nSe envían todas las señales. Activa Solo lista de seguimiento en /settings para filtrarlas.",
+
+		// Pending signals
+		"Usage: /signals [filtered]":                     "Uso: /signals [filtered]",
+		"No pending signals.":                            "No hay señales pendientes.",
+		"<b>Pending signals: %d</b>\n\n":                 "<b>Señales pendientes: %d</b>\n\n",
+		" (not on watchlist)":                            " (fuera de la lista de seguimiento)",
+		" (rejected by %s)":                              " (rechazada por %s)",
+		"\nButtons are shown for the %d newest signals.": "\nSe muestran botones para las %d señales más recientes.",
+
+		// Command menu and quick actions
+		"Show the welcome message":                     "Mostrar el mensaje de bienvenida",
+		"View and change your trading settings":        "Ver y cambiar tus ajustes de trading",
+		"List pending signals":                         "Listar señales pendientes",
+		"Show open positions":                          "Mostrar posiciones abiertas",
+		"Pin a live positions message":                 "Fijar un mensaje de posiciones en vivo",
+		"Show your futures balance":                    "Mostrar tu saldo de futuros",
+		"Show trading performance":                     "Mostrar el rendimiento",
+		"Page through recent trades":                   "Ver operaciones recientes",
+		"Show a symbol's price, e.g. /price BTCUSDT":   "Mostrar el precio de un símbolo, p. ej. /price BTCUSDT",
+		"Show a symbol's full market data":             "Mostrar todos los datos de mercado de un símbolo",
+		"Add symbols to your watchlist":                "Añadir símbolos a tu lista de seguimiento",
+		"Remove symbols from your watchlist":           "Quitar símbolos de tu lista de seguimiento",
+		"Summarize the last day or week":               "Resumir el último día o semana",
+		"Manage settings profiles":                     "Gestionar perfiles de ajustes",
+		"Mute signal notifications for a while":        "Silenciar las notificaciones de señales un tiempo",
+		"Trade on your own Binance account":            "Operar con tu propia cuenta de Binance",
+		"Remove your Binance API key":                  "Eliminar tu clave API de Binance",
+		"Require a PIN or authenticator code to trade": "Pedir un PIN o código de autenticación para operar",
+		"Choose the bot language":                      "Elegir el idioma del bot",
+		"Settings":                                     "Ajustes",
+		"Positions":                                    "Posiciones",
+		"Performance":                                  "Rendimiento",
+		"Balance":                                      "Saldo",
+		"<b>Quick Actions:</b> %t\n":                   "<b>Acciones rápidas:</b> %t\n",
+		"Quick Actions":                                "Acciones rápidas",
+		"Quick Actions keyboard has been %s.":          "Teclado de acciones rápidas: %s.",
+
+		// Positions and balance
+		"<b>Open positions (%s)</b>\n":                "<b>Posiciones abiertas (%s)</b>\n",
+		"Failed to get positions: %v":                 "No se pudieron obtener las posiciones: %v",
+		"\n\n<i>Updated %s</i>":                       "\n\n<i>Actualizado %s</i>",
+		"Failed to update the ticker: %v":             "No se pudo actualizar el ticker: %v",
+		"Failed to start the ticker: %v":              "No se pudo iniciar el ticker: %v",
+		"No position ticker is running in this chat.": "No hay ningún ticker de posiciones activo en este chat.",
+		"Position ticker stopped.":                    "Ticker de posiciones detenido.",
+		"Usage: /ticker [1-%d minutes|off]":           "Uso: /ticker [1-%d minutos|off]",
+		"Position ticker started: this message is refreshed every %d minutes. Stop it with /ticker off.": "Ticker de posiciones iniciado: este mensaje se actualiza cada %d minutos. Detenlo con /ticker off.",
+		"\nThe message could not be pinned; give the bot permission to pin messages to pin it.":          "\nNo se pudo fijar el mensaje; da al bot permiso para fijar mensajes para fijarlo.",
+		"Long":                              "Largo",
+		"Short":                             "Corto",
+		"Entry: %s | Mark: %s | Liq.: %s\n": "Entrada: %s | Marca: %s | Liq.: %s\n",
+		"Unrealized PnL: %.2f USDT\n":       "PnL no realizado: %.2f USDT\n",
+		"\nNo open positions.":              "\nNo hay posiciones abiertas.",
+		"\n<b>Total unrealized PnL:</b> %.2f USDT":              "\n<b>PnL no realizado total:</b> %.2f USDT",
+		"<b>Futures balance (%s)</b>\n":                         "<b>Saldo de futuros (%s)</b>\n",
+		"Failed to get balance: %v":                             "No se pudo obtener el saldo: %v",
+		"\n<b>%s</b>: %s (available %s, unrealized PnL %.2f)\n": "\n<b>%s</b>: %s (disponible %s, PnL no realizado %.2f)\n",
+		"\nNo funds in the futures wallet.":                     "\nNo hay fondos en la cartera de futuros.",
+
+		// Compact messages
+		"Entry %s":                         "Entrada %s",
+		"SL %s":                            "SL %s",
+		"TP %s":                            "TP %s",
+		"Liq %s":                           "Liq %s",
+		"high funding":                     "financiación alta",
+		"Confirmed":                        "Confirmada",
+		"Dismissed":                        "Descartada",
+		"Dismissed: %s %s":                 "Descartada: %s %s",
+		"<b>Compact Messages:</b> %t\n":    "<b>Mensajes compactos:</b> %t\n",
+		"Compact Messages":                 "Mensajes compactos",
+		"Compact Messages have been %s.":   "Mensajes compactos: %s.",
+		"<b>Indicator Context:</b> %t\n":   "<b>Contexto de indicadores:</b> %t\n",
+		"<b>ATR SL Multiplier:</b> %.2f\n": "<b>Multiplicador ATR del SL:</b> %.2f\n",
+		"<b>ATR SL Multiplier:</b> %s\n":   "<b>Multiplicador ATR del SL:</b> %s\n",
+		"Indicator Context":                "Contexto de indicadores",
+		"ATR SL Multiplier":                "Multiplicador ATR del SL",
+		"Indicator Context has been %s.":   "Contexto de indicadores: %s.",
+		"<b>Context (%s):</b> %s\n":        "<b>Contexto (%s):</b> %s\n",
+		" (overbought)":                    " (sobrecompra)",
+		" (oversold)":                      " (sobreventa)",
+		"EMA50 above EMA200":               "EMA50 por encima de EMA200",
+		"EMA50 below EMA200":               "EMA50 por debajo de EMA200",
+
+		// Risk metrics
+		"Profit Factor: %.2f\n":                     "Factor de beneficio: %.2f\n",
+		"Expectancy: %.2f per trade\n":              "Esperanza: %.2f por operación\n",
+		"Sharpe/Sortino (per trade): %.2f / %.2f\n": "Sharpe/Sortino (por operación): %.2f / %.2f\n",
+		"Net R: %+.2fR\n":                           "R neto: %+.2fR\n",
+		"Average R: %+.2fR over %d trades\n":        "R medio: %+.2fR en %d operaciones\n",
+		"Longest Losing Streak: %d\n":               "Racha perdedora más larga: %d\n",
+
+		// Performance breakdowns
+		"By Symbol":                 "Por símbolo",
+		"Long/Short":                "Largo/Corto",
+		"By Timeframe":              "Por temporalidad",
+		"Worst First":               "Peores primero",
+		"Best First":                "Mejores primero",
+		", worst first:\n":          ", peores primero:\n",
+		", best first:\n":           ", mejores primero:\n",
+		"No trades in this period.": "No hay operaciones en este periodo.",
+		"...and %d more\n":          "...y %d más\n",
+
+		// Backtests
+		"Replay past signals with your settings":                                  "Repetir señales pasadas con tus ajustes",
+		"Usage: /backtest [days], with days from 1 to %d":                         "Uso: /backtest [días], con días de 1 a %d",
+		"Backtest failed: %v":                                                     "El backtest falló: %v",
+		"Backtest not started: %v":                                                "Backtest no iniciado: %v",
+		"Replaying the signals of the last %d days with your current settings...": "Repitiendo las señales de los últimos %d días con tus ajustes actuales...",
+		"Backtest from %s to %s:\n":                                               "Backtest del %s al %s:\n",
+		"%d signals: %d simulated, %d not filled, %d skipped\n":                   "%d señales: %d simuladas, %d sin ejecutar, %d omitidas\n",
+		"Gross Profit: %.2f\n":                                                    "Beneficio bruto: %.2f\n",
+		"Fees: %.2f\n":                                                            "Comisiones: %.2f\n",
+		"Closed by TPs: %d, stopped out: %d, still open: %d\n":                    "Cerradas por TPs: %d, por SL: %d, aún abiertas: %d\n",
+		"\nBy Timeframe:\n":                                                       "\nPor temporalidad:\n",
+		"Equity curve: cumulative net profit, with drawdowns shaded red":          "Curva de capital: beneficio neto acumulado, con los drawdowns sombreados en rojo",
+		"No timeframe":                                                            "Sin temporalidad",
+		"\nHypothetical results over 5m candles up to 5 days after each signal, with standard fees. A candle reaching both the SL and a TP counts as stopped out.": "\nResultados hipotéticos con velas de 5m hasta 5 días después de cada señal, con comisiones estándar. Una vela que alcanza el SL y un TP cuenta como cerrada por SL.",
+
+		// Market lookups
+		"Usage: /%s <symbol>, e.g. /%s BTCUSDT":      "Uso: /%s <símbolo>, p. ej. /%s BTCUSDT",
+		"Binance client is not initialized.":         "El cliente de Binance no está inicializado.",
+		"Failed to fetch market data for %s: %v":     "No se pudieron obtener los datos de mercado de %s: %v",
+		"<b>Mark Price:</b> %s\n":                    "<b>Precio de marca:</b> %s\n",
+		"<b>24h Change:</b> %+.2f%%\n":               "<b>Cambio 24h:</b> %+.2f%%\n",
+		"<b>Last Price:</b> %s\n":                    "<b>Último precio:</b> %s\n",
+		"<b>Index Price:</b> %s\n":                   "<b>Precio índice:</b> %s\n",
+		"<b>24h High/Low:</b> %s / %s\n":             "<b>Máx./Mín. 24h:</b> %s / %s\n",
+		"<b>24h Volume:</b> %.0f USDT\n":             "<b>Volumen 24h:</b> %.0f USDT\n",
+		"<b>Funding Rate:</b> %.4f%% (next at %s)\n": "<b>Tasa de financiación:</b> %.4f%% (próxima a las %s)\n",
+		"<b>Funding Rate:</b> %.4f%%\n":              "<b>Tasa de financiación:</b> %.4f%%\n",
+		"<b>Open Interest:</b> %s (%.0f USDT)\n":     "<b>Interés abierto:</b> %s (%.0f USDT)\n",
+		"\nSignals received: %d\nConfirmed: %d\nDismissed: %d\nExpired: %d\nPending: %d\n\nTrades closed: %d\nNet PnL: %.2f USDT": "\nSeñales recibidas: %d\nConfirmadas: %d\nDescartadas: %d\nCaducadas: %d\nPendientes: %d\n\nOperaciones cerradas: %d\nPnL neto: %.2f USDT",
+		"Get a monthly PDF report":               "Obtener un informe mensual en PDF",
+		"Average Loss":                           "Pérdida media",
+		"Average Profit":                         "Beneficio medio",
+		"Average R":                              "R medio",
+		"Best / Worst Trade":                     "Mejor / peor operación",
+		"Expectancy":                             "Expectativa",
+		"Failed to build the monthly report: %v": "No se pudo generar el informe mensual: %v",
+		"Fees":                                   "Comisiones",
+		"Generated %s":                           "Generado %s",
+		"Gross Profit":                           "Beneficio bruto",
+		"Longest Losing Streak":                  "Racha perdedora más larga",
+		"Max Drawdown":                           "Drawdown máximo",
+		"Monthly Report: %s":                     "Informe mensual: %s",
+		"Monthly report for %s: ":                "Informe mensual de %s: ",
+		"Net Profit":                             "Beneficio neto",
+		"Net R":                                  "R neto",
+		"No trades closed this month.":           "No se cerraron operaciones este mes.",
+		"Profit Factor":                          "Factor de beneficio",
+		"Sharpe / Sortino":                       "Sharpe / Sortino",
+		"Summary":                                "Resumen",
+		"Symbol":                                 "Símbolo",
+		"Trades":                                 "Operaciones",
+		"Trades closed from %s to %s":            "Operaciones cerradas del %s al %s",
+		"Usage: /report [YYYY-MM] (%v)":          "Uso: /report [AAAA-MM] (%v)",
+		"Win Rate":                               "Tasa de acierto",
+		"Won / Lost":                             "Ganadas / perdidas",
+		"January":                                "Enero",
+		"February":                               "Febrero",
+		"March":                                  "Marzo",
+		"April":                                  "Abril",
+		"May":                                    "Mayo",
+		"June":                                   "Junio",
+		"July":                                   "Julio",
+		"August":                                 "Agosto",
+		"September":                              "Septiembre",
+		"October":                                "Octubre",
+		"November":                               "Noviembre",
+		"December":                               "Diciembre",
+		"<b>Buy &amp; Hold Benchmark:</b> %t\n":  "<b>Comparación con comprar y mantener:</b> %t\n",
+		"Buy & Hold Benchmark":                   "Comparar con comprar y mantener",
+		"Buy & Hold Benchmark has been %s.":      "La comparación con comprar y mantener ha sido %s.",
+		"\nVs. Buy & Hold (%.2f USDT capital):\n":                        "\nFrente a comprar y mantener (capital de %.2f USDT):\n",
+		"Strategy: %+.2f USDT (%+.2f%%)\n":                               "Estrategia: %+.2f USDT (%+.2f%%)\n",
+		"Holding %s: %+.2f USDT (%+.2f%%), strategy %+.2f\n":             "Mantener %s: %+.2f USDT (%+.2f%%), estrategia %+.2f\n",
+		"<b>Exposure (%s)</b>\n":                                         "<b>Exposición (%s)</b>\n",
+		"Equity: %.2f USDT\n":                                            "Patrimonio: %.2f USDT\n",
+		"long %.2f (%.0f%%)":                                             "largo %.2f (%.0f%%)",
+		"short %.2f (%.0f%%)":                                            "corto %.2f (%.0f%%)",
+		"\n<b>Long:</b> %.2f USDT (%.0f%% of equity) across %d assets\n": "\n<b>Largo:</b> %.2f USDT (%.0f%% del patrimonio) en %d activos\n",
+		"<b>Short:</b> %.2f USDT (%.0f%% of equity) across %d assets\n":  "<b>Corto:</b> %.2f USDT (%.0f%% del patrimonio) en %d activos\n",
+		"<b>Net:</b> %+.2f USDT\n":                                       "<b>Neto:</b> %+.2f USDT\n",
+		"\n\u26A0\uFE0F Long exposure is %.0f%% of equity, above the %.0f%% limit. Positions in the same direction tend to move together.\n":  "\n\u26A0\uFE0F La exposición larga es el %.0f%% del patrimonio, por encima del límite del %.0f%%. Las posiciones en la misma dirección tienden a moverse juntas.\n",
+		"\n\u26A0\uFE0F Short exposure is %.0f%% of equity, above the %.0f%% limit. Positions in the same direction tend to move together.\n": "\n\u26A0\uFE0F La exposición corta es el %.0f%% del patrimonio, por encima del límite del %.0f%%. Las posiciones en la misma dirección tienden a moverse juntas.\n",
+		"Failed to get exposure: %v": "No se pudo obtener la exposición: %v",
+		"any time":                   "cualquier hora",
+		"info":                       "informativo",
+		"dismiss":                    "descartar",
+		"Trading Hours":              "Horario de Trading",
+		"Outside Hours":              "Fuera de Horario",
+		"<b>Trading Hours:</b> %s (outside: %s)\n":                                                      "<b>Horario de Trading:</b> %s (fuera: %s)\n",
+		"Invalid trading hours: %v":                                                                     "Horario de trading no válido: %v",
+		"\u2139\uFE0F Outside your trading hours (%s): for information only.\n":                         "\u2139\uFE0F Fuera de tu horario de trading (%s): solo informativo.\n",
+		"Trading is closed outside your trading hours (%s). This signal is for information only.":       "No se opera fuera de tu horario de trading (%s). Esta señal es solo informativa.",
+		"Trading is closed outside your trading hours (%s). Confirm this signal again when they start.": "No se opera fuera de tu horario de trading (%s). Confirma esta señal de nuevo cuando empiece.",
+		"Please enter the hours signals may be confirmed in your timezone as DAYS START-END, separated by \";\" (e.g., Mon-Fri 08:00-20:00; Sat 10-14), or \"off\".": "Introduce las horas en que se pueden confirmar señales en tu zona horaria como DÍAS INICIO-FIN, separadas por \";\" (p. ej., Mon-Fri 08:00-20:00; Sat 10-14), o \"off\".",
+		"Outside Hours has been set to %s.":                                                 "Fuera de Horario se ha establecido en %s.",
+		"High-impact news: %s (%s) at %s. Trading is paused until %s.":                      "Noticia de alto impacto: %s (%s) a las %s. El trading está en pausa hasta las %s.",
+		"\u25B6\uFE0F Trading has resumed after %s (%s).":                                   "\u25B6\uFE0F El trading se ha reanudado tras %s (%s).",
+		"News halts are off.":                                                               "Las pausas por noticias están desactivadas.",
+		"The economic calendar has not been fetched yet.":                                   "El calendario económico aún no se ha descargado.",
+		"<b>High-Impact News</b>\nTrading pauses %d minutes before and after each event.\n": "<b>Noticias de Alto Impacto</b>\nEl trading se pausa %d minutos antes y después de cada evento.\n",
+		"\nNo more high-impact events this week.":                                           "\nNo hay más eventos de alto impacto esta semana.",
+		"This signal expired at %s and can no longer be confirmed.":                         "Esta señal caducó a las %s y ya no se puede confirmar.",
+		"<b>Valid Until:</b> %s\n":                                                          "<b>Válida hasta:</b> %s\n",
+		"\n\u231B Signal expired unanswered.":                                               "\n\u231B La señal caducó sin respuesta.",
+		"Expired":                                                                           "Caducada",
+		"long":                                                                              "largo",
+		"short":                                                                             "corto",
+		"You already have a %s %s position of %s at %s.":                                    "Ya tienes una posición de %s en %s de %s a %s.",
+		"Another %s %s signal was confirmed in the last %d minutes.":                        "Otra señal de %s en %s se confirmó en los últimos %d minutos.",
+		"Confirming %s adds to it. Add to the position?":                                    "Confirmar %s la aumenta. ¿Añadir a la posición?",
+		"Add to Position":                                                                   "Añadir a la posición",
+		"This question has expired or was for another user. Press Confirm on the signal again.": "Esta pregunta ha caducado o era para otro usuario. Pulsa Confirmar en la señal de nuevo.",
+		"<b>Liquidity Check:</b> max %.1f%% of book, min %.0f USDT 24h volume (block: %t)\n":    "<b>Control de liquidez:</b> máx. %.1f%% del libro, mín. %.0f USDT de volumen 24h (bloquear: %t)\n",
+		"<b>Liquidity Check:</b> %s\n":                "<b>Control de liquidez:</b> %s\n",
+		"Max Book Share %":                            "Máx. % del libro",
+		"Min 24h Volume":                              "Volumen 24h mín.",
+		"Block Thin Liquidity":                        "Bloquear poca liquidez",
+		"Block on Thin Liquidity has been set to %t.": "Bloquear con poca liquidez se ha establecido en %t.",
+		"thin liquidity":                              "poca liquidez",
+
+		// Entry order types
+		"<b>Entry Order:</b> %s\n":       "<b>Orden de entrada:</b> %s\n",
+		"<b>Entry Order:</b> %s at %s\n": "<b>Orden de entrada:</b> %s a %s\n",
+		"market":                         "mercado",
+		"limit":                          "límite",
+		"midpoint":                       "punto medio",
+		"stop":                           "stop",
+		"TP/SL orders are placed once the stop entry fills.\n": "Las órdenes TP/SL se colocan cuando se ejecuta la entrada stop.\n",
+		"<b>Stop Limit Offset:</b> %.2f%%\n":                   "<b>Desfase límite del stop:</b> %.2f%%\n",
+		"<b>Stop Limit Offset:</b> %s\n":                       "<b>Desfase límite del stop:</b> %s\n",
+		"off (stop-market)":                                    "desactivado (stop-market)",
+		"Set Stop Limit Offset":                                "Desfase límite del stop",
+		"Stop":                                                 "Stop",
+
+		// Trade notifications
+		"The bot is restarting, please try again in a minute.":                                               "El bot se está reiniciando, inténtalo de nuevo en un minuto.",
+		"Signal not found or invalid.":                                                                       "Señal no encontrada o no válida.",
+		"Signal has no symbol specified.":                                                                    "La señal no indica ningún símbolo.",
+		"Failed to execute trade for %s: %v":                                                                 "No se pudo ejecutar la operación de %s: %v",
+		"Failed to execute trade for %s on %s: %v":                                                           "No se pudo ejecutar la operación de %s en %s: %v",
+		"Trade executed for %s (%s) at market price":                                                         "Operación ejecutada para %s (%s) a precio de mercado",
+		"Trade executed for %s (%s) at price %.4f":                                                           "Operación ejecutada para %s (%s) al precio %.4f",
+		"Trade executed for %s on %s (%s) at market price":                                                   "Operación ejecutada para %s en %s (%s) a precio de mercado",
+		"Trade executed for %s on %s (%s) at price %.4f":                                                     "Operación ejecutada para %s en %s (%s) al precio %.4f",
+		"Trade executed for %s (%s, %s contracts)":                                                           "Operación ejecutada para %s (%s, %s contratos)",
+		"Failed to get contract info for %s: %v":                                                             "No se pudo obtener la información del contrato de %s: %v",
+		"Failed to calculate quantity for %s: %v":                                                            "No se pudo calcular la cantidad de %s: %v",
+		"Failed to place TPs/SL for %s: %v":                                                                  "No se pudieron colocar los TP/SL de %s: %v",
+		"TP/SL orders placed for %s.":                                                                        "Órdenes TP/SL colocadas para %s.",
+		"Leverage %dx exceeds the maximum of %dx allowed for a %.2f USDT position on %s. Using %dx instead.": "El apalancamiento %dx supera el máximo de %dx permitido para una posición de %.2f USDT en %s. Se usa %dx en su lugar.",
+		"Leverage %dx exceeds the maximum allowed for a %.2f USDT position on %s. Using %dx instead.":        "El apalancamiento %dx supera el máximo permitido para una posición de %.2f USDT en %s. Se usa %dx en su lugar.",
+		"Margin ratio for %s is %.2f%%, but adding %.2f USDT margin failed: %v":                              "El ratio de margen de %s es %.2f%%, pero no se pudieron añadir %.2f USDT de margen: %v",
+		"Margin ratio for %s reached %.2f%%. Added %.2f USDT isolated margin.":                               "El ratio de margen de %s llegó a %.2f%%. Se añadieron %.2f USDT de margen aislado.",
+		"Order %s for %s has been filled.":                                                                   "La orden %s de %s se ha ejecutado.",
+		"Order %s for %s has been filled on %s.":                                                             "La orden %s de %s se ha ejecutado en %s.",
+		"%s hit for %s.\nFilled: %s at %s":                                                                   "%s alcanzado en %s.\nEjecutado: %s a %s",
+		" (%.0f%% of the position)":                                                                          " (%.0f%% de la posición)",
+		"\nRealized PnL so far: %.4f (fees %.4f)":                                                            "\nPnL realizado hasta ahora: %.4f (comisiones %.4f)",
+		"\nRemaining: %s of %s":                                                                              "\nRestante: %s de %s",
+		"\nRemaining: none, the position is closed":                                                          "\nRestante: nada, la posición está cerrada",
+		"Position closed for %s.\nEntry: %s\nExit: %s\nRealized PnL: %.4f\nFees: %.4f (maker %.4f, taker %.4f)\nNet Profit: %.4f": "Posición cerrada en %s.\nEntrada: %s\nSalida: %s\nPnL realizado: %.4f\nComisiones: %.4f (maker %.4f, taker %.4f)\nBeneficio neto: %.4f",
+		"\nResult: %+.2fR (risk %.4f)": "\nResultado: %+.2fR (riesgo %.4f)",
+		"Restored tracking for %d open position(s) or pending order(s) after restart.":                                                       "Se restauró el seguimiento de %d posición(es) abierta(s) u orden(es) pendiente(s) tras el reinicio.",
+		"Added to the %s position. The earlier signal's %s were cancelled, and this signal's TP/SL orders protect the whole position of %s.": "Se añadió a la posición de %s. Se cancelaron los %s de la señal anterior, y las órdenes TP/SL de esta señal protegen toda la posición de %s.",
+
+		// Pre-trade checks
+		"Trading is halted after /panic. An admin can resume it with /panic off.": "El trading está detenido tras /panic. Un administrador puede reanudarlo con /panic off.",
+		"Stop entries are only available on Binance, not %s.":                     "Las entradas stop solo están disponibles en Binance, no en %s.",
+		"Stop entries are only available for USDT-M futures.":                     "Las entradas stop solo están disponibles en futuros USDT-M.",
+		"COIN-M futures are only available on Binance, not %s.":                   "Los futuros COIN-M solo están disponibles en Binance, no en %s.",
+		"Trade blocked: %s": "Operación bloqueada: %s",
+		"Market order for %s aborted: Book price %s moved %.2f%% from entry %s (max slippage %.2f%%).": "Orden de mercado de %s cancelada: el precio del libro %s se movió un %.2f%% desde la entrada %s (deslizamiento máximo %.2f%%).",
+		"Market order for %s aborted: Mark price %s moved %.2f%% from entry %s (max slippage %.2f%%).": "Orden de mercado de %s cancelada: el precio de marca %s se movió un %.2f%% desde la entrada %s (deslizamiento máximo %.2f%%).",
+		"Funding rate is against this position: current %.4f%%, predicted %.4f%% (threshold %.4f%%)":   "La tasa de financiación va en contra de esta posición: actual %.4f%%, prevista %.4f%% (umbral %.4f%%)",
+		"Thin liquidity: %s": "Poca liquidez: %s",
+		"24h volume is %.0f USDT, below the %.0f USDT minimum":                                  "el volumen de 24h es %.0f USDT, por debajo del mínimo de %.0f USDT",
+		"the order book has no %s levels":                                                       "el libro de órdenes no tiene niveles de %s",
+		"the %.2f USDT order is %.1f%% of the %.0f USDT on the top %d %s levels (limit %.1f%%)": "la orden de %.2f USDT es el %.1f%% de los %.0f USDT en los %d mejores niveles de %s (límite %.1f%%)",
+		"ask": "venta",
+		"bid": "compra",
+
+		// DCA ladders
+		"Failed to place DCA ladder for %s: %v":                      "No se pudo colocar la escalera DCA de %s: %v",
+		"No DCA levels fit between entry and SL for %s.":             "No caben niveles DCA entre la entrada y el SL de %s.",
+		"DCA ladder placed for %s: %d order(s) of %s at %s.":         "Escalera DCA colocada para %s: %d orden(es) de %s a %s.",
+		"Failed to resize the partial TPs for %s after DCA fill: %v": "No se pudieron ajustar los TP parciales de %s tras la ejecución DCA: %v",
+		"Failed to replace %s for %s after DCA fill: %v":             "No se pudo reemplazar %s de %s tras la ejecución DCA: %v",
+		"DCA order %d/%d filled for %s.\nNew average entry: %s":      "Orden DCA %d/%d ejecutada en %s.\nNueva entrada media: %s",
+		"\nTPs moved to: %s":                         "\nTP movidos a: %s",
+		"Cancelled %d unfilled DCA order(s) for %s.": "Se cancelaron %d orden(es) DCA sin ejecutar de %s.",
+
+		// Linked TP/SL orders
+		"%s filled for %s.":                      "%s ejecutado en %s.",
+		" Cancelled linked order(s): %s.":        " Órdenes vinculadas canceladas: %s.",
+		" No linked orders were left to cancel.": " No quedaban órdenes vinculadas que cancelar.",
+
+		// Stop entries
+		"Stop entry placed for %s at %.4f. TP/SL orders are placed once the price triggers it and the entry fills.":            "Entrada stop colocada para %s a %.4f. Las órdenes TP/SL se colocan cuando el precio la activa y la entrada se ejecuta.",
+		"The stop entry at %s for %s is already crossed: the mark price is %s. Confirm it as a market or limit entry instead.": "La entrada stop a %s de %s ya se ha cruzado: el precio de marca es %s. Confírmala como entrada de mercado o límite.",
+		"Stop entry for %s was %s before it filled.": "La entrada stop de %s fue %s antes de ejecutarse.",
+		"canceled":                           "cancelada",
+		"expired":                            "caducada",
+		"rejected":                           "rechazada",
+		"Stop entry triggered for %s at %s.": "Entrada stop activada para %s a %s.",
+		"Stop entry for %s filled while the bot was offline.": "La entrada stop de %s se ejecutó mientras el bot estaba desconectado.",
+
+		// Iceberg entries
+		"<b>Iceberg Above:</b> %.2f USDT\n":                            "<b>Iceberg desde:</b> %.2f USDT\n",
+		"<b>Iceberg Above:</b> %s\n":                                   "<b>Iceberg desde:</b> %s\n",
+		"Iceberg Above":                                                "Iceberg desde",
+		"Iceberg Above must be 0 to turn it off, or at least %d USDT.": "Iceberg desde debe ser 0 para desactivarlo, o al menos %d USDT.",
+		"<b>Iceberg:</b> %d orders of about %s, %d-%d seconds apart\n": "<b>Iceberg:</b> %d órdenes de unos %s, con %d-%d segundos entre ellas\n",
+		"Splitting the %s entry of %s into %d orders.":                 "Dividiendo la entrada de %s de %s en %d órdenes.",
+		"Iceberg entry for %s: %s filled in %d of %d orders":           "Entrada iceberg de %s: %s ejecutado en %d de %d órdenes",
+		" at an average price of %s":                                   " a un precio medio de %s",
+		". The remaining orders were skipped: %v":                      ". Se omitieron las órdenes restantes: %v",
+		// Trailing take-profit
+		"<b>Trail After TP2:</b> %.2fx ATR\n": "<b>Trailing tras TP2:</b> %.2fx ATR\n",
+		"<b>Trail After TP2:</b> %.1f%%\n":    "<b>Trailing tras TP2:</b> %.1f%%\n",
+		"<b>Trail After TP2:</b> %s\n":        "<b>Trailing tras TP2:</b> %s\n",
+		"Trail After TP2":                     "Trailing tras TP2",
+		"Trail %":                             "Trailing %",
+		"Trail ATR Multiplier":                "Multiplicador ATR del trailing",
+		"Trail After TP2 has been set to %t.": "Trailing tras TP2 se ha establecido en %t.",
+		"Once TP2 fills, the later TPs are replaced by a trailing stop for the rest of the position.\n": "Cuando se ejecute el TP2, los TPs siguientes se sustituyen por un trailing stop para el resto de la posición.\n",
+		", %.2fx the %s ATR": ", %.2fx el ATR de %s",
+		"%s filled for %s. The rest of the position keeps its TP/SL orders.":                                      "%s ejecutado en %s. El resto de la posición mantiene sus órdenes TP/SL.",
+		"%s filled for %s, but the position could not be fetched to trail it, so the later TPs stay in place: %v": "%s ejecutado en %s, pero no se pudo obtener la posición para seguirla, así que los TP siguientes se mantienen: %v",
+		"%s filled for %s. No position is left to trail.":                                                         "%s ejecutado en %s. No queda posición que seguir.",
+		"%s filled for %s, but the trailing stop could not be placed, so the later TPs stay in place: %v":         "%s ejecutado en %s, pero no se pudo colocar el trailing stop, así que los TP siguientes se mantienen: %v",
+		"%s filled for %s. The remaining %s now trails %.1f%% behind the price%s.":                                "%s ejecutado en %s. Los %s restantes siguen ahora al precio a un %.1f%%%s.",
+		" Replaced: %s.": " Reemplazados: %s.",
+		// Panic
+		"Usage: /panic to close everything, /panic off to resume trading": "Uso: /panic para cerrarlo todo, /panic off para reanudar el trading",
+		"⚠️ This market-closes every open position and cancels every open order on the bot's accounts and the accounts traders connected, then halts trading until /panic off. Continue?": "⚠️ Esto cierra a mercado todas las posiciones abiertas y cancela todas las órdenes abiertas de las cuentas del bot y de las que conectaron los traders, y detiene el trading hasta /panic off. ¿Continuar?",
+		"Close Everything": "Cerrar todo",
+		"Cancel":           "Cancelar",
+		"Panic cancelled.": "Pánico cancelado.",
+		"This confirmation has expired. Send /panic again.":              "Esta confirmación ha caducado. Envía /panic de nuevo.",
+		"Closing everything...":                                          "Cerrando todo...",
+		"\U0001F6A8 <b>Panic</b>\nTrading is halted until /panic off.\n": "\U0001F6A8 <b>Pánico</b>\nEl trading está detenido hasta /panic off.\n",
+		"Closed: %s\n":                                                 "Cerradas: %s\n",
+		"No open positions.\n":                                         "No hay posiciones abiertas.\n",
+		"Orders cancelled on: %s\n":                                    "Órdenes canceladas en: %s\n",
+		"Trading is not halted.":                                       "El trading no está detenido.",
+		"Trading resumed, but this could not be saved: %v":             "Trading reanudado, pero no se pudo guardar: %v",
+		"The halt could not be saved and ends if the bot restarts: %v": "No se pudo guardar la pausa y termina si el bot se reinicia: %v",
+		"Trading resumed.":                                             "Trading reanudado.",
+	},
+}
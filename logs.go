@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels, from least to most severe, as shown in the admin panel and set in LOG_LEVEL.
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+// logLevels maps the log levels to slog's.
+var logLevels = map[string]slog.Level{LogDebug: slog.LevelDebug, LogInfo: slog.LevelInfo, LogWarn: slog.LevelWarn, LogError: slog.LevelError}
+
+// defaultLogBufferLines is how many recent log entries are kept for the admin panel.
+const defaultLogBufferLines = 2000
+
+// logTimeFormat is how downloaded log lines are timestamped.
+const logTimeFormat = "2006/01/02 15:04:05"
+
+// Loggers of the signal pipeline, from the webhook through Telegram to Binance, of the admin
+// panel and REST API, and of the database, its backups and migrations. Their entries carry a
+// module field, and LOG_LEVEL can set a level per module.
+var (
+	webhookLog  = newModuleLogger("webhook")
+	telegramLog = newModuleLogger("telegram")
+	binanceLog  = newModuleLogger("binance")
+	adminLog    = newModuleLogger("admin")
+	dbLog       = newModuleLogger("db")
+)
+
+// LogField is a key and value attached to a log entry, such as signal_id.
+type LogField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// LogEntry is one line of application log.
+type LogEntry struct {
+	ID      uint64     `json:"id"`
+	Time    time.Time  `json:"time"`
+	Level   string     `json:"level"`
+	Message string     `json:"message"`
+	Fields  []LogField `json:"fields,omitempty"`
+}
+
+// String formats the entry as a log line.
+func (e LogEntry) String() string {
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s [%s] %s", e.Time.Format(logTimeFormat), strings.ToUpper(e.Level), e.Message)
+	for _, field := range e.Fields {
+		fmt.Fprintf(&line, " %s=%s", field.Key, field.Value)
+	}
+	return line.String()
+}
+
+// LogBuffer keeps the most recent log entries and passes new ones to followers.
+type LogBuffer struct {
+	mu        sync.Mutex
+	entries   []LogEntry // Ring of up to size entries, the oldest at next once full
+	size      int
+	next      int
+	lastID    uint64
+	followers map[chan LogEntry]struct{}
+	done      chan struct{} // Closed on shutdown to end follows
+}
+
+// NewLogBuffer returns a buffer keeping up to size entries.
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{size: size, followers: make(map[chan LogEntry]struct{}), done: make(chan struct{})}
+}
+
+// Add stores an entry and hands it to followers. Followers that fall behind miss entries
+// rather than holding up logging.
+func (b *LogBuffer) Add(entry LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	entry.ID = b.lastID
+	if len(b.entries) < b.size {
+		b.entries = append(b.entries, entry)
+	} else {
+		b.entries[b.next] = entry
+		b.next = (b.next + 1) % b.size
+	}
+	for ch := range b.followers {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+// Entries returns the kept entries, oldest first.
+func (b *LogBuffer) Entries() []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := make([]LogEntry, 0, len(b.entries))
+	entries = append(entries, b.entries[b.next:]...)
+	return append(entries, b.entries[:b.next]...)
+}
+
+// Follow returns a channel receiving new entries until unfollow is called, and a channel that
+// is closed when the server shuts down.
+func (b *LogBuffer) Follow() (entries <-chan LogEntry, done <-chan struct{}, unfollow func()) {
+	ch := make(chan LogEntry, 100)
+	b.mu.Lock()
+	b.followers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, b.done, func() {
+		b.mu.Lock()
+		delete(b.followers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// Close ends all follows, so the server can shut down without waiting for them.
+func (b *LogBuffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.done:
+	default:
+		close(b.done)
+	}
+}
+
+// logBuffer holds the recent application log for the admin panel.
+var logBuffer = NewLogBuffer(defaultLogBufferLines)
+
+// logOutput writes log entries to the console and log file, as text or JSON.
+var logOutput slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+// LogLevelConfig is the minimum level logged, overall and per module.
+type LogLevelConfig struct {
+	Default slog.Level
+	Modules map[string]slog.Level
+}
+
+// Level returns the minimum level for a module's entries.
+func (c LogLevelConfig) Level(module string) slog.Level {
+	if level, ok := c.Modules[module]; ok {
+		return level
+	}
+	return c.Default
+}
+
+// logLevelConfig is set from LOG_LEVEL at startup and not changed after.
+var logLevelConfig = LogLevelConfig{Default: slog.LevelInfo}
+
+// parseLogLevelConfig reads LOG_LEVEL, a level optionally followed by levels for modules,
+// e.g. "info" or "warn,binance=debug".
+func parseLogLevelConfig(value string) (LogLevelConfig, error) {
+	config := LogLevelConfig{Default: slog.LevelInfo, Modules: make(map[string]slog.Level)}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		module, name, hasModule := strings.Cut(part, "=")
+		if !hasModule {
+			name = module
+		}
+		level, ok := logLevels[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return config, fmt.Errorf("unknown log level %q", name)
+		}
+		if hasModule {
+			config.Modules[strings.TrimSpace(module)] = level
+		} else {
+			config.Default = level
+		}
+	}
+	return config, nil
+}
+
+// moduleHandler passes a module's entries at or above its level to logBuffer and logOutput.
+// The bot doesn't use slog groups, so attributes are kept flat.
+type moduleHandler struct {
+	module string // Empty for the default logger and the log package
+	attrs  []slog.Attr
+}
+
+// newModuleLogger returns the logger of a part of the bot.
+func newModuleLogger(module string) *slog.Logger {
+	return slog.New(moduleHandler{module: module})
+}
+
+func (h moduleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= logLevelConfig.Level(h.module)
+}
+
+func (h moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	if h.module != "" {
+		record.AddAttrs(slog.String("module", h.module))
+	}
+	record.AddAttrs(h.attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		record.AddAttrs(attr)
+		return true
+	})
+	logBuffer.Add(newLogEntry(record))
+	return logOutput.Handle(ctx, record)
+}
+
+func (h moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)
+	return h
+}
+
+func (h moduleHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// newLogEntry converts a record for logBuffer.
+func newLogEntry(r slog.Record) LogEntry {
+	entry := LogEntry{Time: r.Time, Level: strings.ToLower(r.Level.String()), Message: r.Message}
+	r.Attrs(func(attr slog.Attr) bool {
+		entry.Fields = append(entry.Fields, LogField{Key: attr.Key, Value: attr.Value.String()})
+		return true
+	})
+	return entry
+}
+
+// logWriter receives everything written with the log package and logs it through slog, with
+// a level guessed from its wording.
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	message := strings.TrimRight(string(p), "\n")
+	level := logLevel(message)
+	handler := moduleHandler{}
+	if handler.Enabled(context.Background(), level) {
+		if err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), level, message, 0)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// logLevel guesses the level of a message logged with the log package.
+func logLevel(message string) slog.Level {
+	lower := strings.ToLower(message)
+	for _, word := range []string{"error", "failed", "failure", "panic", "fatal"} {
+		if strings.Contains(lower, word) {
+			return slog.LevelError
+		}
+	}
+	for _, word := range []string{"warning", "invalid", "retry", "retrying", "unable", "not set", "skipp"} {
+		if strings.Contains(lower, word) {
+			return slog.LevelWarn
+		}
+	}
+	return slog.LevelInfo
+}
+
+// initLogging sets up logging from the environment: LOG_LEVEL sets the minimum level,
+// LOG_FORMAT=json writes JSON lines instead of text, LOG_FILE appends the log to a file as
+// well as the console, and LOG_BUFFER_LINES sets how many entries the admin panel can show.
+// Everything logged with the log package goes the same way.
+func initLogging() {
+	var problems []string
+	if config, err := parseLogLevelConfig(os.Getenv("LOG_LEVEL")); err != nil {
+		problems = append(problems, fmt.Sprintf("Invalid LOG_LEVEL, logging at info: %v", err))
+	} else {
+		logLevelConfig = config
+	}
+	if lines := envInt("LOG_BUFFER_LINES", defaultLogBufferLines); lines > 0 && lines != defaultLogBufferLines {
+		logBuffer = NewLogBuffer(lines)
+	}
+
+	var out io.Writer = os.Stderr
+	if path := os.Getenv("LOG_FILE"); path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Failed to open log file %s, logging to the console only: %v", path, err))
+		} else {
+			out = io.MultiWriter(os.Stderr, file)
+		}
+	}
+	// Levels are filtered per module before entries get here
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	switch format := os.Getenv("LOG_FORMAT"); format {
+	case "json":
+		logOutput = slog.NewJSONHandler(out, options)
+	case "", "text":
+		logOutput = slog.NewTextHandler(out, options)
+	default:
+		logOutput = slog.NewTextHandler(out, options)
+		problems = append(problems, fmt.Sprintf("Invalid LOG_FORMAT %q, logging text", format))
+	}
+
+	// SetDefault also redirects the log package, which logWriter takes over
+	slog.SetDefault(slog.New(moduleHandler{}))
+	log.SetFlags(0)
+	log.SetOutput(logWriter{})
+	for _, problem := range problems {
+		slog.Warn(problem)
+	}
+}
+
+// filterLogEntries returns the entries at level or above whose message or fields contain
+// search, ignoring case.
+func filterLogEntries(entries []LogEntry, level, search string) []LogEntry {
+	var matched []LogEntry
+	for _, entry := range entries {
+		if logEntryMatches(entry, level, search) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// logEntryMatches reports whether an entry is at level or above and contains search.
+func logEntryMatches(entry LogEntry, level, search string) bool {
+	if logLevels[entry.Level] < logLevels[level] {
+		return false
+	}
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	if strings.Contains(strings.ToLower(entry.Message), search) {
+		return true
+	}
+	for _, field := range entry.Fields {
+		if strings.Contains(strings.ToLower(field.Value), search) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// smtpSettings are the mail server reports are emailed through, from SMTP_ADDR (host:port),
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM.
+type smtpSettings struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// loadSMTPSettings reads the mail server settings. It returns false when SMTP_ADDR is not set.
+func loadSMTPSettings() (smtpSettings, bool) {
+	settings := smtpSettings{
+		Addr:     os.Getenv("SMTP_ADDR"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if settings.From == "" {
+		settings.From = settings.Username
+	}
+	return settings, settings.Addr != ""
+}
+
+// parseEmailList splits a comma-separated list of email addresses, checking each.
+func parseEmailList(list string) ([]string, error) {
+	var addresses []string
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q", part)
+		}
+		addresses = append(addresses, address.Address)
+	}
+	return addresses, nil
+}
+
+// sendEmail sends a plain text email with one attachment through the configured mail server.
+func sendEmail(to []string, subject, body, attachmentName, attachmentType string, attachment []byte) error {
+	settings, ok := loadSMTPSettings()
+	if !ok {
+		return fmt.Errorf("SMTP_ADDR is not set")
+	}
+	if settings.From == "" {
+		return fmt.Errorf("SMTP_FROM is not set")
+	}
+	host, _, err := net.SplitHostPort(settings.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_ADDR: %v", err)
+	}
+
+	var msg bytes.Buffer
+	writer := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		settings.From, strings.Join(to, ", "), mime.QEncoding.Encode("utf-8", subject), time.Now().Format(time.RFC1123Z), writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return err
+	}
+	part.Write([]byte(body))
+
+	part, err = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {attachmentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachmentName)},
+	})
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(attachment)
+	for len(encoded) > 76 {
+		part.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	part.Write([]byte(encoded + "\r\n"))
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if settings.Username != "" {
+		auth = smtp.PlainAuth("", settings.Username, settings.Password, host)
+	}
+	if err := smtp.SendMail(settings.Addr, auth, settings.From, to, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
@@ -1,11 +1,11 @@
 package main
 
 import (
-	"context"
 	"encoding/hex"
-	"encoding/json"
+	"flag"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,62 +18,130 @@ import (
 )
 
 const (
-	// ServerPort is the port the server listens on
+	// ServerPort is the port the server listens on without HTTPS
 	ServerPort = "8000"
 )
 
 func init() {
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("Error loading .env file")
+		slog.Warn("Error loading .env file")
 	}
 }
 
 func main() {
+	migrateOnly := flag.Bool("migrate", false, "apply pending database migrations and exit")
+	rotateOnly := flag.Bool("rotate-secrets", false, "re-encrypt stored secrets with SECRETS_MASTER_KEY and exit")
+	resetAdmin := flag.String("reset-admin-password", "", "give the named admin account a temporary password, creating it if needed, and exit")
+	flag.Parse()
+
+	// Keep the log for the admin panel's log viewer
+	initLogging()
+	initErrorReporting()
+
+	// Load the master key before any secrets are read
+	if err := initSecrets(); err != nil {
+		slog.Error("Failed to load secrets key", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize the database
-	if err := initDatabase(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	if err := initDatabase(*migrateOnly); err != nil {
+		slog.Error("Failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+	if *migrateOnly {
+		slog.Info("Database migrations are up to date")
+		return
+	}
+	if *rotateOnly {
+		if err := rotateSecrets(); err != nil {
+			slog.Error("Failed to rotate secrets", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Stored secrets are encrypted with the current master key")
+		return
+	}
+	if *resetAdmin != "" {
+		password, err := ResetAdminPassword(*resetAdmin, true)
+		if err != nil {
+			slog.Error("Failed to reset admin password", "error", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Temporary password for %s: %s\nIt must be changed at the next login.\n", *resetAdmin, password)
+		return
 	}
 
 	// Load the initial configuration
 	config, err := getConfig()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
 	}
 
 	// Set the global configuration
 	SetGlobalConfig(*config)
 
+	// Restore recent signals so their buttons keep working after a restart
+	if err := loadSignals(); err != nil {
+		slog.Error("Failed to restore signals", "error", err)
+	}
+	// Restore DCA ladders, trailing TPs and stop entries before open positions are reconciled
+	if err := loadTradeState(); err != nil {
+		slog.Error("Failed to restore trade state", "error", err)
+	}
+	startSignalPersistence()
+	startServerTimeSync()
+	startSignalExpiry()
+	startBackupScheduler()
+	if err := startBridge(); err != nil {
+		slog.Error("Failed to start signal bridge", "error", err)
+		os.Exit(1)
+	}
+
+	// HTTPS decides whether cookies are limited to secure connections
+	tlsSettings, err := loadTLSSettings()
+	if err != nil {
+		slog.Error("Invalid HTTPS settings", "error", err)
+		os.Exit(1)
+	}
+	secure := secureCookies(tlsSettings)
+
 	// Initialize admin components (session store and templates)
-	initAdmin()
+	initAdmin(secure)
 
 	// Retrieve and decode CSRF_AUTH_KEY
 	csrfKeyHex := os.Getenv("CSRF_AUTH_KEY")
 	if csrfKeyHex == "" {
-		log.Fatal("CSRF_AUTH_KEY environment variable is not set")
+		slog.Error("CSRF_AUTH_KEY environment variable is not set")
+		os.Exit(1)
 	}
 
 	csrfKey, err := hex.DecodeString(csrfKeyHex)
 	if err != nil || len(csrfKey) != 32 {
-		log.Fatal("CSRF_AUTH_KEY must be a 64-character hexadecimal string representing 32 bytes")
+		slog.Error("CSRF_AUTH_KEY must be a 64-character hexadecimal string representing 32 bytes")
+		os.Exit(1)
 	}
 
 	// CSRF protection middleware
-	csrfMiddleware := csrf.Protect(csrfKey, csrf.Secure(false)) // Set csrf.Secure(true) if using HTTPS
+	csrfMiddleware := csrf.Protect(csrfKey, csrf.Secure(secure))
 
 	// Initialize the Telegram bot if the configuration is set
 	if GlobalConfig.TelegramBotToken != "" && GlobalConfig.TelegramChatID != 0 {
 		bot, err = initTelegramBot(&GlobalConfig)
 		if err != nil {
-			log.Printf("Telegram bot not initialized: %v", err)
+			slog.Warn("Telegram bot not initialized", "error", err)
 		} else {
 			// Start the Telegram listener
 			startTelegramListener()
 		}
 	} else {
-		log.Println("Telegram configuration is not set. Please configure via the admin panel.")
+		slog.Warn("Telegram configuration is not set. Please configure via the admin panel")
 	}
 
+	// Apply configuration saved in the admin panel from now on without a restart
+	watchConfig()
+
 	// Create a new router
 	r := mux.NewRouter()
 
@@ -87,14 +155,42 @@ func main() {
 
 	// Admin routes with CSRF protection
 	r.Handle("/admin/login", csrfMiddleware(http.HandlerFunc(adminLoginHandler)))
+	r.Handle("/admin/logout", csrfMiddleware(http.HandlerFunc(adminLogoutHandler)))
+	r.Handle("/admin/dashboard", csrfMiddleware(http.HandlerFunc(adminDashboardHandler)))
+	r.Handle("/admin/signals", csrfMiddleware(http.HandlerFunc(adminSignalsHandler)))
 	r.Handle("/admin/config", csrfMiddleware(http.HandlerFunc(adminConfigHandler)))
+	r.Handle("/admin/accounts", csrfMiddleware(http.HandlerFunc(adminAccountsHandler)))
+	r.Handle("/admin/audit", csrfMiddleware(http.HandlerFunc(adminAuditHandler)))
+	r.Handle("/admin/config/history", csrfMiddleware(http.HandlerFunc(adminConfigHistoryHandler)))
+	r.Handle("/admin/tokens", csrfMiddleware(http.HandlerFunc(adminTokensHandler)))
+	r.Handle("/admin/webhooks", csrfMiddleware(http.HandlerFunc(adminWebhooksHandler)))
+	r.Handle("/admin/webhooks/archive", csrfMiddleware(http.HandlerFunc(adminWebhookArchiveHandler)))
+	r.Handle("/admin/filters", csrfMiddleware(http.HandlerFunc(adminFiltersHandler)))
+	r.Handle("/admin/backtest", csrfMiddleware(http.HandlerFunc(adminBacktestHandler)))
+	r.Handle("/admin/password", csrfMiddleware(http.HandlerFunc(adminPasswordHandler)))
+	r.Handle("/admin/admins", csrfMiddleware(http.HandlerFunc(adminAdminUsersHandler)))
+	r.Handle("/admin/users", csrfMiddleware(http.HandlerFunc(adminUsersHandler)))
+	r.Handle("/admin/trade", csrfMiddleware(http.HandlerFunc(adminTradeHandler)))
+	r.Handle("/admin/logs", csrfMiddleware(http.HandlerFunc(adminLogsHandler)))
+	r.Handle("/admin/logs/stream", csrfMiddleware(http.HandlerFunc(adminLogsStreamHandler)))
+	r.Handle("/admin/logs/download", csrfMiddleware(http.HandlerFunc(adminLogsDownloadHandler)))
+
+	// REST API, authenticated with API tokens instead of the admin session
+	r.HandleFunc("/api/v1/signals", apiAuth(apiSignalsHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/trades", apiAuth(apiTradesHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/settings", apiAuth(apiSettingsHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/positions", apiAuth(apiPositionsHandler)).Methods(http.MethodGet)
+
+	// Health checks for orchestrators and uptime monitors, without authentication
+	r.HandleFunc("/healthz", healthzHandler).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/readyz", readyzHandler).Methods(http.MethodGet, http.MethodHead)
 
 	// Webhook handler
 	r.HandleFunc("/webhook", webhookHandler)
 
-	// Root URL redirection
+	// Root URL redirection; the dashboard sends visitors who are not logged in to the login page
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
 	})
 
 	// Create the server
@@ -105,74 +201,87 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Followed logs would otherwise hold up shutting down
+	server.RegisterOnShutdown(logBuffer.Close)
 
 	// Start the server in a goroutine
-	go func() {
-		log.Println("Starting server on port", ServerPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("ListenAndServe error: %v", err)
+	var httpServer *http.Server
+	if tlsSettings.Enabled() {
+		httpServer, err = configureTLS(server, tlsSettings)
+		if err != nil {
+			slog.Error("Failed to set up HTTPS", "error", err)
+			os.Exit(1)
 		}
-	}()
+		go func() {
+			slog.Info("Starting HTTPS server", "addr", server.Addr)
+			if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				slog.Error("HTTPS server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+		if httpServer != nil {
+			go func() {
+				slog.Info("Redirecting HTTP to HTTPS", "addr", httpServer.Addr)
+				if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					slog.Error("HTTP server failed", "error", err)
+					os.Exit(1)
+				}
+			}()
+		}
+	} else {
+		go func() {
+			slog.Info("Starting server", "port", ServerPort)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("HTTP server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
-	}
+	slog.Info("Shutting down server")
 
-	// Stop the Telegram listener if it's running
-	stopTelegramListener()
+	// Let webhooks and trades in progress finish
+	drain(time.Duration(envInt("SHUTDOWN_TIMEOUT", defaultShutdownTimeout))*time.Second, httpServer, server)
 
-	log.Println("Server exited properly")
+	slog.Info("Server exited properly")
 }
 
-// webhookHandler handles incoming webhook requests.
+// webhookHandler handles incoming webhook requests, archiving each body with its headers and
+// outcome for the admin panel.
 func webhookHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
+		webhookStats.Record(WebhookRejected)
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 		return
 	}
 
+	payload := WebhookPayload{RemoteAddr: r.RemoteAddr, Headers: webhookHeaders(r.Header)}
+
 	// Read the request body
 	body, err := io.ReadAll(io.LimitReader(r.Body, 1048576)) // Limit the size to prevent abuse
 	if err != nil {
+		webhookStats.Record(WebhookRejected)
+		archiveWebhook(payload, webhookResult{Status: http.StatusBadRequest, Outcome: WebhookRejected, Detail: err.Error()})
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
+	payload.Body = string(body)
 
-	// Parse the JSON alert message
-	var alert AlertMessage
-	if err := json.Unmarshal(body, &alert); err != nil {
-		log.Printf("JSON Unmarshal error: %v", err)
-		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
-		return
-	}
-
-	// Validate required fields
-	if alert.SignalID == "" || alert.Symbol == "" || alert.Time == "" {
-		log.Printf("Invalid alert data: missing required fields")
-		http.Error(w, "Invalid alert data: missing required fields", http.StatusBadRequest)
-		return
-	}
-
-	log.Printf("Received alert: %+v", alert)
-
-	// Send the message to Telegram
-	if _, err := sendSignalMessage(&alert); err != nil {
-		log.Printf("Failed to send message to Telegram: %v", err)
-		http.Error(w, "Failed to send message to Telegram", http.StatusInternalServerError)
+	result := processWebhook(r.Context(), body)
+	webhookStats.Record(result.Outcome)
+	archiveWebhook(payload, result)
+	if result.Status != http.StatusOK {
+		http.Error(w, result.Message, result.Status)
 		return
 	}
 
 	// Respond to the webhook sender
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Alert received and processed"))
+	w.Write([]byte(result.Message))
 }
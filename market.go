@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MarketQuote is a snapshot of a USDT-M symbol's market data.
+type MarketQuote struct {
+	Symbol        string
+	MarkPrice     float64
+	IndexPrice    float64
+	LastPrice     float64
+	ChangePercent float64 // 24h price change
+	High          float64 // 24h high
+	Low           float64 // 24h low
+	QuoteVolume   float64 // 24h volume in the quote asset
+	FundingRate   float64 // Predicted next funding rate
+	NextFunding   time.Time
+	OpenInterest  float64 // In contracts of the base asset
+}
+
+// getMarketQuote fetches the mark price, 24h statistics, funding rate and open interest for the symbol.
+func (b *BinanceClient) getMarketQuote(symbol string) (*MarketQuote, error) {
+	index, err := b.Client.NewPremiumIndexService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(index) == 0 {
+		return nil, fmt.Errorf("no mark price data for symbol %s", symbol)
+	}
+	stats, err := b.Client.NewListPriceChangeStatsService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("no price data for symbol %s", symbol)
+	}
+	openInterest, err := b.Client.NewGetOpenInterestService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &MarketQuote{
+		Symbol:      symbol,
+		NextFunding: time.UnixMilli(index[0].NextFundingTime),
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"mark price", index[0].MarkPrice, &quote.MarkPrice},
+		{"index price", index[0].IndexPrice, &quote.IndexPrice},
+		{"funding rate", index[0].LastFundingRate, &quote.FundingRate},
+		{"last price", stats[0].LastPrice, &quote.LastPrice},
+		{"price change", stats[0].PriceChangePercent, &quote.ChangePercent},
+		{"high price", stats[0].HighPrice, &quote.High},
+		{"low price", stats[0].LowPrice, &quote.Low},
+		{"volume", stats[0].QuoteVolume, &quote.QuoteVolume},
+		{"open interest", openInterest.OpenInterest, &quote.OpenInterest},
+	} {
+		if *field.dest, err = strconv.ParseFloat(field.value, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", field.name, err)
+		}
+	}
+	return quote, nil
+}
+
+// marketSymbol turns a command argument such as "btc" or "ETHUSDT" into a USDT-M symbol.
+func marketSymbol(arg string) string {
+	symbol := strings.ToUpper(strings.TrimSpace(arg))
+	if symbol != "" && !strings.HasSuffix(symbol, "USDT") && !strings.HasSuffix(symbol, "USDC") {
+		symbol += "USDT"
+	}
+	return symbol
+}
+
+// handleMarketCommand replies to "/price <symbol>" with the mark price and 24h change, and to
+// "/quote <symbol>" with the full market snapshot including funding and open interest.
+func handleMarketCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	command := message.Command()
+	symbol := marketSymbol(message.CommandArguments())
+	if symbol == "" || strings.ContainsAny(symbol, " \t") {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /%s <symbol>, e.g. /%s BTCUSDT", command, command)))
+		return
+	}
+	client := mainBinanceClient()
+	if client == nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Binance client is not initialized.")))
+		return
+	}
+
+	quote, err := client.getMarketQuote(symbol)
+	if err != nil {
+		binanceLog.Error("Failed to fetch market data", "symbol", symbol, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to fetch market data for %s: %v", symbol, err)))
+		return
+	}
+
+	text := fmt.Sprintf("<b>%s</b>\n", quote.Symbol)
+	text += tr(chatID, "<b>Mark Price:</b> %s\n", formatFloat(quote.MarkPrice))
+	text += tr(chatID, "<b>24h Change:</b> %+.2f%%\n", quote.ChangePercent)
+	if command == "quote" {
+		text += tr(chatID, "<b>Last Price:</b> %s\n", formatFloat(quote.LastPrice))
+		text += tr(chatID, "<b>Index Price:</b> %s\n", formatFloat(quote.IndexPrice))
+		text += tr(chatID, "<b>24h High/Low:</b> %s / %s\n", formatFloat(quote.High), formatFloat(quote.Low))
+		text += tr(chatID, "<b>24h Volume:</b> %.0f USDT\n", quote.QuoteVolume)
+		text += tr(chatID, "<b>Funding Rate:</b> %.4f%% (next at %s)\n", quote.FundingRate*100, formatUserTime(chatID, quote.NextFunding))
+		text += tr(chatID, "<b>Open Interest:</b> %s (%.0f USDT)\n", formatFloat(quote.OpenInterest), quote.OpenInterest*quote.MarkPrice)
+	} else {
+		text += tr(chatID, "<b>Funding Rate:</b> %.4f%%\n", quote.FundingRate*100)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
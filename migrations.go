@@ -0,0 +1,615 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned change to the database schema or data. Migrations are applied in
+// version order, each once. Add a migration with the next version for every schema change;
+// never edit one that has been released. Each migration declares the tables and columns it
+// creates in its own structs, as they were at its version, so changing a model later doesn't
+// change what an older migration does; AutoMigrate only adds what is missing, so they also
+// work on a schema that already has their changes.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// migrations lists every migration in version order.
+var migrations = []Migration{
+	{
+		// Databases created before versioned migrations are brought up to this schema as well
+		Version: 1,
+		Name:    "create tables",
+		Up:      migrateCreateTables,
+	},
+	{
+		Version: 2,
+		Name:    "move symbol override TPs into a list",
+		Up:      migrateOverrideTPs,
+	},
+	{
+		Version: 3,
+		Name:    "track signal status",
+		Up:      migrateSignalStatus,
+	},
+	{
+		Version: 4,
+		Name:    "create audit log",
+		Up: func(tx *gorm.DB) error {
+			type auditLog struct {
+				ID        uint      `gorm:"primaryKey"`
+				CreatedAt time.Time `gorm:"index"`
+				UserID    int64     `gorm:"index"`
+				ChatID    int64
+				Action    string `gorm:"index;size:32"`
+				SignalID  string `gorm:"index;size:128"`
+				Details   string
+				Response  string
+			}
+			return tx.Table("audit_logs").AutoMigrate(&auditLog{})
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create orders",
+		Up: func(tx *gorm.DB) error {
+			type order struct {
+				ID              uint   `gorm:"primaryKey"`
+				Symbol          string `gorm:"uniqueIndex:idx_orders_symbol_order_id;size:32"`
+				OrderID         int64  `gorm:"uniqueIndex:idx_orders_symbol_order_id"`
+				ClientOrderID   string `gorm:"index;size:64"`
+				SignalID        string `gorm:"index;size:128"`
+				Tag             string
+				Type            string
+				Side            string
+				Price           float64
+				StopPrice       float64
+				Quantity        float64
+				Status          string `gorm:"index;size:32"`
+				FilledQty       float64
+				AvgPrice        float64
+				RealizedPnL     float64
+				Commission      float64
+				CommissionAsset string
+				CreatedAt       time.Time
+				UpdatedAt       time.Time
+			}
+			return tx.Table("orders").AutoMigrate(&order{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "tag signals and trades with their strategy",
+		Up: func(tx *gorm.DB) error {
+			type signal struct {
+				Strategy string `gorm:"index;size:64"`
+			}
+			type trade struct {
+				Strategy string `gorm:"index;size:64"`
+			}
+			if err := tx.Table("signals").AutoMigrate(&signal{}); err != nil {
+				return err
+			}
+			return tx.Table("trades").AutoMigrate(&trade{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "add import IDs to trades",
+		Up: func(tx *gorm.DB) error {
+			type trade struct {
+				ImportID string `gorm:"index;size:64"`
+			}
+			return tx.Table("trades").AutoMigrate(&trade{})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create config history",
+		Up:      migrateConfigHistory,
+	},
+	{
+		Version: 9,
+		Name:    "create API tokens",
+		Up: func(tx *gorm.DB) error {
+			type apiToken struct {
+				ID         uint   `gorm:"primaryKey"`
+				Name       string `gorm:"size:64"`
+				TokenHash  string `gorm:"uniqueIndex;size:64"`
+				Hint       string
+				CreatedAt  time.Time
+				LastUsedAt time.Time
+			}
+			return tx.Table("api_tokens").AutoMigrate(&apiToken{})
+		},
+	},
+	{
+		Version: 10,
+		Name:    "create admin accounts",
+		Up: func(tx *gorm.DB) error {
+			type adminUser struct {
+				ID                 uint   `gorm:"primaryKey"`
+				Username           string `gorm:"uniqueIndex;size:64"`
+				PasswordHash       string
+				MustChangePassword bool
+				FailedLogins       int
+				LockedUntil        time.Time
+				LastLoginAt        time.Time
+				PasswordChangedAt  time.Time
+				CreatedAt          time.Time
+			}
+			type auditLog struct {
+				Admin string `gorm:"index;size:64"`
+			}
+			if err := tx.Table("admin_users").AutoMigrate(&adminUser{}); err != nil {
+				return err
+			}
+			return tx.Table("audit_logs").AutoMigrate(&auditLog{})
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add admin session versions",
+		Up: func(tx *gorm.DB) error {
+			type adminUser struct {
+				SessionVersion int
+			}
+			return tx.Table("admin_users").AutoMigrate(&adminUser{})
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add error alert chat",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				AlertChatID int64
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 13,
+		Name:    "add binance recv window",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				BinanceRecvWindow int
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 14,
+		Name:    "add user credential exchange",
+		Up: func(tx *gorm.DB) error {
+			type userCredential struct {
+				Exchange string `gorm:"size:16"`
+			}
+			return tx.Table("user_credentials").AutoMigrate(&userCredential{})
+		},
+	},
+	{
+		Version: 15,
+		Name:    "add account exchange and mirroring",
+		Up: func(tx *gorm.DB) error {
+			type binanceAccount struct {
+				Exchange   string `gorm:"size:16"`
+				Mirror     bool
+				SizeFactor float64
+			}
+			return tx.Table("binance_accounts").AutoMigrate(&binanceAccount{})
+		},
+	},
+	{
+		Version: 16,
+		Name:    "add discord webhook",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				DiscordWebhookURL string
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 17,
+		Name:    "create outgoing webhooks",
+		Up: func(tx *gorm.DB) error {
+			type outgoingWebhook struct {
+				ID             uint   `gorm:"primaryKey"`
+				Name           string `gorm:"size:64"`
+				URL            string
+				Secret         string
+				Events         string
+				CreatedAt      time.Time
+				LastDeliveryAt time.Time
+				LastStatus     string
+			}
+			return tx.Table("outgoing_webhooks").AutoMigrate(&outgoingWebhook{})
+		},
+	},
+	{
+		Version: 18,
+		Name:    "create filter rules",
+		Up: func(tx *gorm.DB) error {
+			type filterRule struct {
+				ID        uint   `gorm:"primaryKey"`
+				Name      string `gorm:"size:64"`
+				Source    string `gorm:"size:64"`
+				Rule      string
+				Action    string `gorm:"size:16"`
+				Note      string
+				Enabled   bool
+				CreatedAt time.Time
+			}
+			return tx.Table("filter_rules").AutoMigrate(&filterRule{})
+		},
+	},
+	{
+		Version: 19,
+		Name:    "add signal side and timeframe",
+		Up: func(tx *gorm.DB) error {
+			type signal struct {
+				Side      string `gorm:"size:8"`
+				Timeframe string `gorm:"size:16"`
+			}
+			return tx.Table("signals").AutoMigrate(&signal{})
+		},
+	},
+	{
+		Version: 20,
+		Name:    "add trade initial risk",
+		Up: func(tx *gorm.DB) error {
+			type trade struct {
+				InitialRisk float64
+			}
+			return tx.Table("trades").AutoMigrate(&trade{})
+		},
+	},
+	{
+		Version: 21,
+		Name:    "create position tickers",
+		Up: func(tx *gorm.DB) error {
+			type positionTicker struct {
+				ID              uint  `gorm:"primaryKey"`
+				ChatID          int64 `gorm:"uniqueIndex"`
+				UserID          int64
+				MessageID       int
+				IntervalMinutes int
+				UpdatedAt       time.Time
+			}
+			return tx.Table("position_tickers").AutoMigrate(&positionTicker{})
+		},
+	},
+	{
+		Version: 22,
+		Name:    "add monthly report",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				MonthlyReport bool
+				ReportEmail   string
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 23,
+		Name:    "add exposure limit",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				ExposureLimitPercent float64
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 24,
+		Name:    "create webhook payloads",
+		Up: func(tx *gorm.DB) error {
+			type webhookPayload struct {
+				ID          uint      `gorm:"primaryKey"`
+				CreatedAt   time.Time `gorm:"index"`
+				RemoteAddr  string    `gorm:"size:64"`
+				Headers     string
+				Body        string
+				Status      int
+				Outcome     string `gorm:"index;size:16"`
+				Detail      string
+				SignalID    string `gorm:"index;size:128"`
+				Symbol      string `gorm:"size:32"`
+				ReprocessOf uint
+				Admin       string `gorm:"size:64"`
+			}
+			return tx.Table("webhook_payloads").AutoMigrate(&webhookPayload{})
+		},
+	},
+	{
+		Version: 25,
+		Name:    "add news halt",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				NewsHaltMinutes int
+				NewsCurrencies  string
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 26,
+		Name:    "add signal expiry by source",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				SignalExpiryBySource string
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 27,
+		Name:    "add duplicate guard",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				DuplicateGuard        bool
+				DuplicateGuardMinutes int
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 28,
+		Name:    "add entry type by source",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				EntryTypeBySource string
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 29,
+		Name:    "add trading halt",
+		Up: func(tx *gorm.DB) error {
+			type config struct {
+				TradingHalted bool
+			}
+			return tx.Table("configs").AutoMigrate(&config{})
+		},
+	},
+	{
+		Version: 30,
+		Name:    "create stored trade states",
+		Up: func(tx *gorm.DB) error {
+			type storedTradeState struct {
+				ID       uint   `gorm:"primaryKey"`
+				Kind     string `gorm:"uniqueIndex:idx_trade_state;size:16"`
+				Account  string `gorm:"uniqueIndex:idx_trade_state;size:64"`
+				Symbol   string `gorm:"uniqueIndex:idx_trade_state;size:32"`
+				ClientID string `gorm:"uniqueIndex:idx_trade_state;size:64"`
+				SavedAt  time.Time
+				Data     string
+			}
+			return tx.Table("stored_trade_states").AutoMigrate(&storedTradeState{})
+		},
+	},
+	{
+		Version: 31,
+		Name:    "add trade code lockout",
+		Up: func(tx *gorm.DB) error {
+			type tradeAuth struct {
+				FailedCodes int
+				LockedUntil time.Time
+			}
+			return tx.Table("trade_auths").AutoMigrate(&tradeAuth{})
+		},
+	},
+}
+
+// migrateCreateTables creates the tables the bot had before versioned migrations, as they were
+// then.
+func migrateCreateTables(tx *gorm.DB) error {
+	type config struct {
+		ID                    uint `gorm:"primaryKey"`
+		TelegramBotToken      string
+		TelegramChatID        int64
+		BinanceAPIKey         string
+		BinanceAPISecret      string
+		BinanceAPIURL         string
+		AdminUserID           int64
+		OrderIDPrefix         string
+		BroadcastToTraders    bool
+		DailySummary          bool
+		WeeklySummary         bool
+		SummaryHour           int
+		MessageRetentionHours int
+		DualConfirmNotional   float64
+	}
+	type signal struct {
+		ID         uint   `gorm:"primaryKey"`
+		SignalID   string `gorm:"uniqueIndex;size:128"`
+		EntryPrice float64
+		TPs        []float64 `gorm:"serializer:json"`
+		SL         float64
+		Timestamp  time.Time
+	}
+	type trade struct {
+		ID          uint   `gorm:"primaryKey"`
+		SignalID    string `gorm:"index;size:128"`
+		Symbol      string
+		Side        string
+		EntryPrice  float64
+		ExitPrice   float64
+		GrossProfit float64
+		MakerFees   float64
+		TakerFees   float64
+		Profit      float64
+		OpenedAt    time.Time
+		Timestamp   time.Time
+	}
+	type symbolOverride struct {
+		ID                 uint   `gorm:"primaryKey"`
+		UserID             int64  `gorm:"uniqueIndex:idx_override_user_symbol"`
+		Symbol             string `gorm:"uniqueIndex:idx_override_user_symbol;size:32"`
+		Leverage           int
+		MarginMode         string
+		AmountUSDT         float64
+		TPPercentages      []float64 `gorm:"serializer:json"`
+		ManualSLPercentage float64
+	}
+	type binanceAccount struct {
+		ID        uint   `gorm:"primaryKey"`
+		Name      string `gorm:"uniqueIndex;size:64"`
+		APIKey    string
+		APISecret string
+	}
+	type routingRule struct {
+		ID          uint `gorm:"primaryKey"`
+		MatchType   string
+		Pattern     string
+		AccountName string
+	}
+	type userCredential struct {
+		ID        uint  `gorm:"primaryKey"`
+		UserID    int64 `gorm:"uniqueIndex"`
+		APIKey    string
+		APISecret string
+	}
+	type userRole struct {
+		ID     uint  `gorm:"primaryKey"`
+		UserID int64 `gorm:"uniqueIndex"`
+		Role   string
+	}
+	type settingsProfile struct {
+		ID       uint   `gorm:"primaryKey"`
+		UserID   int64  `gorm:"uniqueIndex:idx_profile_user_name"`
+		Name     string `gorm:"uniqueIndex:idx_profile_user_name;size:64"`
+		Settings string
+	}
+	type watchedSymbol struct {
+		ID     uint   `gorm:"primaryKey"`
+		UserID int64  `gorm:"uniqueIndex:idx_watch_user_symbol"`
+		Symbol string `gorm:"uniqueIndex:idx_watch_user_symbol;size:32"`
+	}
+	type tradeAuth struct {
+		ID         uint  `gorm:"primaryKey"`
+		UserID     int64 `gorm:"uniqueIndex"`
+		PINHash    string
+		TOTPSecret string
+	}
+	type storedSignal struct {
+		ID         uint   `gorm:"primaryKey"`
+		SignalID   string `gorm:"uniqueIndex;size:128"`
+		ChatID     int64
+		MessageID  int
+		ReceivedAt time.Time `gorm:"index"`
+		Data       string
+	}
+
+	tables := []struct {
+		name  string
+		model interface{}
+	}{
+		{"configs", &config{}},
+		{"signals", &signal{}},
+		{"trades", &trade{}},
+		{"symbol_overrides", &symbolOverride{}},
+		{"binance_accounts", &binanceAccount{}},
+		{"routing_rules", &routingRule{}},
+		{"user_credentials", &userCredential{}},
+		{"user_roles", &userRole{}},
+		{"settings_profiles", &settingsProfile{}},
+		{"watched_symbols", &watchedSymbol{}},
+		{"trade_auths", &tradeAuth{}},
+		{"stored_signals", &storedSignal{}},
+	}
+	for _, table := range tables {
+		if err := tx.Table(table.name).AutoMigrate(table.model); err != nil {
+			return fmt.Errorf("failed to create %s: %w", table.name, err)
+		}
+	}
+	return nil
+}
+
+// migrateOnStartup reports whether pending migrations are applied when the bot starts.
+// Set MIGRATE_ON_STARTUP=false to apply them only with the -migrate flag.
+func migrateOnStartup() bool {
+	value := os.Getenv("MIGRATE_ON_STARTUP")
+	if value == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		dbLog.Warn("Invalid MIGRATE_ON_STARTUP, migrating on startup", "value", value)
+		return true
+	}
+	return enabled
+}
+
+// pendingMigrations returns the migrations not yet applied, in version order.
+func pendingMigrations() ([]Migration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	var applied []int
+	if err := db.Model(&SchemaMigration{}).Pluck("version", &applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve applied migrations: %w", err)
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, version := range applied {
+		done[version] = true
+	}
+	var pending []Migration
+	for _, migration := range migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// applyMigrations applies the pending migrations, each in its own transaction.
+func applyMigrations() error {
+	pending, err := pendingMigrations()
+	if err != nil {
+		return err
+	}
+	for _, migration := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		dbLog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return nil
+}
+
+// checkMigrations returns an error if migrations are pending, so the bot does not run on an
+// outdated schema.
+func checkMigrations() error {
+	pending, err := pendingMigrations()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d database migrations are pending, starting with %d (%s); run the bot with -migrate to apply them",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MirrorResult is the outcome of a confirmed signal on one mirror account.
+type MirrorResult struct {
+	Account    string
+	Exchange   string
+	SizeFactor float64
+	Err        error
+}
+
+// mirrorAccounts returns the accounts that mirror signals executed on the account named
+// primary, in name order.
+func mirrorAccounts(primary string) []BinanceAccount {
+	accounts, err := ListBinanceAccounts()
+	if err != nil {
+		telegramLog.Warn("Failed to load mirror accounts", "error", err)
+		return nil
+	}
+
+	var mirrors []BinanceAccount
+	for _, account := range accounts {
+		if account.Mirror && account.Name != primary {
+			mirrors = append(mirrors, account)
+		}
+	}
+	return mirrors
+}
+
+// mirrorSummary lists the mirror accounts a signal executed on account would also execute on,
+// with their size factors, for the trade summary.
+func mirrorSummary(chatID int64, account string) string {
+	if account == personalAccountName {
+		return ""
+	}
+	mirrors := mirrorAccounts(account)
+	if len(mirrors) == 0 {
+		return ""
+	}
+	venues := make([]string, len(mirrors))
+	for i, mirror := range mirrors {
+		venues[i] = fmt.Sprintf("%s (%sx)", html.EscapeString(mirror.Name), formatFloat(mirror.sizeFactor()))
+	}
+	return tr(chatID, "<b>Mirrored To:</b> %s\n", strings.Join(venues, ", "))
+}
+
+// mirrorSignal executes a confirmed signal on every mirror account at once, each with the
+// trade amount scaled by the account's size factor, and returns the results in account order.
+func mirrorSignal(ctx context.Context, chatID, userID int64, signal *AlertMessage, settings *UserSettings, mirrors []BinanceAccount) []MirrorResult {
+	results := make([]MirrorResult, len(mirrors))
+	var wg sync.WaitGroup
+	for i, account := range mirrors {
+		results[i] = MirrorResult{Account: account.Name, Exchange: exchangeName(account.Exchange), SizeFactor: account.sizeFactor()}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exchange, err := accountExchange(account.Name)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			sized := *settings
+			sized.AmountUSDT *= account.sizeFactor()
+			mirrored := *signal
+			mirrored.Account = account.Name
+			results[i].Err = executeSignal(ctx, chatID, userID, exchange, &mirrored, account.Name, &sized)
+			if results[i].Err == nil {
+				fireTradeExecuted(&mirrored, account.Name, strings.ToLower(exchange.Name()), &sized)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		if result.Err != nil {
+			telegramLog.Error("Failed to mirror signal", "signal_id", signal.SignalID, "symbol", signal.Symbol,
+				"account", result.Account, "chat_id", chatID, "error", result.Err)
+		} else {
+			telegramLog.Info("Mirrored signal", "signal_id", signal.SignalID, "symbol", signal.Symbol,
+				"account", result.Account, "size_factor", result.SizeFactor)
+		}
+	}
+	return results
+}
+
+// sendMirrorReport tells the chat how the signal went on each mirror account.
+func sendMirrorReport(chatID int64, signal *AlertMessage, results []MirrorResult) {
+	var text strings.Builder
+	text.WriteString(tr(chatID, "<b>Mirrored %s to %d accounts:</b>\n", signal.Symbol, len(results)))
+	for _, result := range results {
+		venue := fmt.Sprintf("%s (%s, %sx)", html.EscapeString(result.Account), result.Exchange, formatFloat(result.SizeFactor))
+		if result.Err != nil {
+			fmt.Fprintf(&text, "\u274C %s: %s\n", venue, html.EscapeString(handleBinanceError(chatID, result.Err)))
+		} else {
+			text.WriteString(tr(chatID, "\u2705 %s: executed\n", venue))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send mirror report", "chat_id", chatID, "error", err)
+	}
+	postToDiscord(text.String())
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxReportSymbols bounds the rows of the report's by-symbol table.
+const maxReportSymbols = 10
+
+var (
+	reportText    = color.RGBA{R: 0x22, G: 0x26, B: 0x30, A: 0xff}
+	reportMuted   = color.RGBA{R: 0x6b, G: 0x72, B: 0x80, A: 0xff}
+	reportShading = color.RGBA{R: 0xf0, G: 0xf2, B: 0xf5, A: 0xff}
+	reportWhite   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// reportMonthStart returns the start of the month containing t in the chat's timezone.
+func reportMonthStart(chatID int64, t time.Time) time.Time {
+	t = t.In(userLocation(chatID))
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// parseReportMonth parses the month of "/report 2024-03", defaulting to last month.
+func parseReportMonth(chatID int64, arg string) (time.Time, error) {
+	if arg == "" {
+		return reportMonthStart(chatID, time.Now()).AddDate(0, -1, 0), nil
+	}
+	month, err := time.ParseInLocation("2006-01", arg, userLocation(chatID))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("month must be YYYY-MM")
+	}
+	if month.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("month is in the future")
+	}
+	return month, nil
+}
+
+// reportMonthName renders a month as e.g. "March 2024" in the chat's language.
+func reportMonthName(chatID int64, month time.Time) string {
+	return tr(chatID, month.Month().String()) + " " + month.Format("2006")
+}
+
+// renderMonthlyReport lays out the month's report as a one-page PDF: the equity curve, a table
+// of the performance summary and risk metrics, and the symbols ranked by net profit.
+func renderMonthlyReport(chatID int64, month time.Time, trades []Trade) ([]byte, error) {
+	data := calculatePerformanceMetrics(trades)
+	end := month.AddDate(0, 1, 0)
+	page := &pdfPage{}
+	const margin = 40.0
+	width := float64(pdfPageWidth) - 2*margin
+
+	page.Rect(0, pdfPageHeight-64, pdfPageWidth, 64, chartBackground)
+	page.Text(margin, pdfPageHeight-34, 20, true, reportWhite, tr(chatID, "Monthly Report: %s", reportMonthName(chatID, month)))
+	page.Text(margin, pdfPageHeight-52, 10, false, reportShading, tr(chatID, "Trades closed from %s to %s",
+		month.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")))
+
+	// Equity curve, in the chart's aspect ratio
+	chartTop := float64(pdfPageHeight) - 80
+	imageHeight := width * chartHeight / chartWidth
+	if len(trades) > 0 {
+		img, err := drawEquityChart(month, equityCurve(trades))
+		if err != nil {
+			return nil, err
+		}
+		if err := page.Image(margin, chartTop-imageHeight, width, imageHeight, img); err != nil {
+			return nil, err
+		}
+	} else {
+		page.Rect(margin, chartTop-imageHeight, width, imageHeight, reportShading)
+		page.Text(margin+20, chartTop-imageHeight/2, 12, false, reportMuted, tr(chatID, "No trades closed this month."))
+	}
+
+	// Summary table in two columns of label and value
+	y := chartTop - imageHeight - 28
+	page.Text(margin, y, 13, true, reportText, tr(chatID, "Summary"))
+	profitFactor := "-"
+	if data.ProfitFactor > 0 {
+		profitFactor = fmt.Sprintf("%.2f", data.ProfitFactor)
+	}
+	netR, averageR := "-", "-"
+	if data.RTrades > 0 {
+		netR = fmt.Sprintf("%+.2fR", data.TotalR)
+		averageR = fmt.Sprintf("%+.2fR", data.AverageR)
+	}
+	best, worst := 0.0, 0.0
+	for i, trade := range trades {
+		if i == 0 || trade.Profit > best {
+			best = trade.Profit
+		}
+		if i == 0 || trade.Profit < worst {
+			worst = trade.Profit
+		}
+	}
+	rows := [][4]string{
+		{tr(chatID, "Trades"), fmt.Sprintf("%d", data.TotalTrades), tr(chatID, "Average Profit"), fmt.Sprintf("%.2f", data.AverageProfit)},
+		{tr(chatID, "Won / Lost"), fmt.Sprintf("%d / %d", data.WinningTrades, data.LosingTrades), tr(chatID, "Average Loss"), fmt.Sprintf("%.2f", data.AverageLoss)},
+		{tr(chatID, "Win Rate"), fmt.Sprintf("%.1f%%", data.WinLossRatio*100), tr(chatID, "Profit Factor"), profitFactor},
+		{tr(chatID, "Net Profit"), fmt.Sprintf("%.2f USDT", data.NetProfit), tr(chatID, "Expectancy"), fmt.Sprintf("%.2f", data.Expectancy)},
+		{tr(chatID, "Gross Profit"), fmt.Sprintf("%.2f USDT", data.GrossProfit), tr(chatID, "Sharpe / Sortino"), fmt.Sprintf("%.2f / %.2f", data.SharpeRatio, data.SortinoRatio)},
+		{tr(chatID, "Fees"), fmt.Sprintf("%.2f USDT", data.TotalFees), tr(chatID, "Average R"), averageR},
+		{tr(chatID, "Max Drawdown"), fmt.Sprintf("%.2f USDT", data.MaxDrawdown), tr(chatID, "Longest Losing Streak"), fmt.Sprintf("%d", data.LosingStreak)},
+		{tr(chatID, "Net R"), netR, tr(chatID, "Best / Worst Trade"), fmt.Sprintf("%.2f / %.2f", best, worst)},
+	}
+	y -= 8
+	for i, row := range rows {
+		y -= 18
+		if i%2 == 0 {
+			page.Rect(margin, y-5, width, 18, reportShading)
+		}
+		page.Text(margin+6, y, 10, false, reportMuted, row[0])
+		page.Text(margin+150, y, 10, true, reportText, row[1])
+		page.Text(margin+width/2+6, y, 10, false, reportMuted, row[2])
+		page.Text(margin+width/2+150, y, 10, true, reportText, row[3])
+	}
+
+	// Symbols ranked by net profit
+	y -= 34
+	page.Text(margin, y, 13, true, reportText, tr(chatID, "By Symbol"))
+	y -= 22
+	columns := []float64{margin + 6, margin + 170, margin + 250, margin + 340, margin + 440}
+	for i, heading := range []string{tr(chatID, "Symbol"), tr(chatID, "Trades"), tr(chatID, "Win Rate"), tr(chatID, "Net Profit"), tr(chatID, "Net R")} {
+		page.Text(columns[i], y, 9, true, reportMuted, heading)
+	}
+	page.Line(margin, y-5, margin+width, y-5, 0.5, reportMuted)
+	groups := groupPerformance(trades, func(t Trade) string { return t.Symbol })
+	rankPerformanceGroups(groups, BreakdownBest)
+	for i, group := range groups {
+		if i == maxReportSymbols {
+			y -= 16
+			page.Text(columns[0], y, 9, false, reportMuted, strings.TrimSpace(tr(chatID, "...and %d more\n", len(groups)-maxReportSymbols)))
+			break
+		}
+		y -= 16
+		groupR := "-"
+		if group.RTrades > 0 {
+			groupR = fmt.Sprintf("%+.2fR", group.TotalR)
+		}
+		net := chartBullish
+		if group.NetProfit < 0 {
+			net = chartBearish
+		}
+		page.Text(columns[0], y, 10, true, reportText, group.Name)
+		page.Text(columns[1], y, 10, false, reportText, fmt.Sprintf("%d", group.TotalTrades))
+		page.Text(columns[2], y, 10, false, reportText, fmt.Sprintf("%.0f%%", group.WinRate()))
+		page.Text(columns[3], y, 10, true, net, fmt.Sprintf("%.2f", group.NetProfit))
+		page.Text(columns[4], y, 10, false, reportText, groupR)
+	}
+	if len(groups) == 0 {
+		y -= 16
+		page.Text(columns[0], y, 10, false, reportMuted, tr(chatID, "No trades in this period."))
+	}
+
+	page.Text(margin, 24, 8, false, reportMuted, tr(chatID, "Generated %s", formatUserTime(chatID, time.Now())))
+	return page.Bytes(), nil
+}
+
+// sendMonthlyReport builds the month's report in the chat's language and sends it to the chat
+// when toChat is set, and emails it to the addresses given.
+func sendMonthlyReport(chatID int64, month time.Time, toChat bool, emails []string) {
+	trades, err := GetTradesBetween(month, month.AddDate(0, 1, 0))
+	if err != nil {
+		telegramLog.Error("Failed to build monthly report", "error", err)
+		if toChat {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to fetch trade data: %v", err)))
+		}
+		return
+	}
+	report, err := renderMonthlyReport(chatID, month, trades)
+	if err != nil {
+		telegramLog.Error("Failed to render monthly report", "error", err)
+		if toChat {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to build the monthly report: %v", err)))
+		}
+		return
+	}
+
+	name := fmt.Sprintf("report-%s.pdf", month.Format("2006-01"))
+	summary := tr(chatID, "Monthly report for %s: ", reportMonthName(chatID, month)) + formatGroupResult(chatID, calculatePerformanceMetrics(trades))
+	if toChat {
+		document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: report})
+		document.Caption = summary
+		if _, err := bot.Send(document); err != nil {
+			telegramLog.Error("Failed to send monthly report", "chat_id", chatID, "error", err)
+		}
+	}
+	if len(emails) > 0 {
+		subject := tr(chatID, "Monthly Report: %s", reportMonthName(chatID, month))
+		if err := sendEmail(emails, subject, summary, name, "application/pdf", report); err != nil {
+			telegramLog.Error("Failed to email monthly report", "error", err)
+		}
+	}
+}
+
+// sendScheduledMonthlyReport sends last month's report to the signal chat and emails it to the
+// report addresses, as the configuration asks.
+func sendScheduledMonthlyReport(config Config) {
+	emails, err := parseEmailList(config.ReportEmail)
+	if err != nil {
+		telegramLog.Warn("Not emailing the monthly report", "error", err)
+		emails = nil
+	}
+	if !config.MonthlyReport && len(emails) == 0 {
+		return
+	}
+	chatID := config.TelegramChatID
+	month := reportMonthStart(chatID, time.Now()).AddDate(0, -1, 0)
+	sendMonthlyReport(chatID, month, config.MonthlyReport, emails)
+}
+
+// handleReportCommand sends the monthly report on demand with "/report" for last month or
+// "/report 2024-03".
+func handleReportCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	month, err := parseReportMonth(chatID, strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /report [YYYY-MM] (%v)", err)))
+		return
+	}
+	sendMonthlyReport(chatID, month, true, nil)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultNewsCalendarURL is this week's economic calendar from Forex Factory. NEWS_CALENDAR_URL
+// replaces it with another feed in the same format.
+const defaultNewsCalendarURL = "https://nfs.faireconomy.media/ff_calendar_thisweek.json"
+
+// defaultNewsCurrencies are the currencies whose events halt trading when NewsCurrencies is empty.
+const defaultNewsCurrencies = "USD"
+
+// newsRefreshInterval is how often the calendar is fetched again, and newsCheckInterval how
+// often the start and end of a halt are checked for.
+const (
+	newsRefreshInterval = time.Hour
+	newsCheckInterval   = time.Minute
+)
+
+// maxNewsHaltMinutes bounds the halt before and after each event.
+const maxNewsHaltMinutes = 240
+
+// maxNewsEvents bounds the events /news lists.
+const maxNewsEvents = 15
+
+// newsClient fetches the economic calendar.
+var newsClient = &http.Client{Timeout: 10 * time.Second}
+
+// EconomicEvent is a scheduled release from the economic calendar.
+type EconomicEvent struct {
+	Title   string    `json:"title"`
+	Country string    `json:"country"` // Currency the release moves, e.g. USD
+	Date    time.Time `json:"date"`
+	Impact  string    `json:"impact"` // High, Medium, Low or Holiday
+}
+
+// NewsCalendar holds the high-impact events of the last fetch of the calendar.
+type NewsCalendar struct {
+	sync.RWMutex
+	events    []EconomicEvent
+	fetchedAt time.Time
+	lastErr   error
+}
+
+// Set replaces the events after a fetch, or records why the fetch failed.
+func (c *NewsCalendar) Set(events []EconomicEvent, err error) {
+	c.Lock()
+	defer c.Unlock()
+	c.lastErr = err
+	if err == nil {
+		c.events = events
+		c.fetchedAt = time.Now()
+	}
+}
+
+// Events returns the events in time order, when they were fetched and the last fetch error.
+func (c *NewsCalendar) Events() ([]EconomicEvent, time.Time, error) {
+	c.RLock()
+	defer c.RUnlock()
+	return c.events, c.fetchedAt, c.lastErr
+}
+
+var (
+	newsCalendar     = &NewsCalendar{}
+	newsCalendarOnce sync.Once
+)
+
+// newsCurrencies returns the currencies of the configuration's news halts.
+func newsCurrencies(config Config) map[string]bool {
+	list := config.NewsCurrencies
+	if strings.TrimSpace(list) == "" {
+		list = defaultNewsCurrencies
+	}
+	currencies := make(map[string]bool)
+	for _, currency := range strings.Split(list, ",") {
+		if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+			currencies[currency] = true
+		}
+	}
+	return currencies
+}
+
+// fetchNewsCalendar downloads the economic calendar and keeps its high-impact events.
+func fetchNewsCalendar(ctx context.Context) ([]EconomicEvent, error) {
+	url := os.Getenv("NEWS_CALENDAR_URL")
+	if url == "" {
+		url = defaultNewsCalendarURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newsClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the economic calendar: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("economic calendar returned %s", resp.Status)
+	}
+
+	var events []EconomicEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("failed to decode the economic calendar: %v", err)
+	}
+	high := events[:0]
+	for _, event := range events {
+		if strings.EqualFold(event.Impact, "High") && !event.Date.IsZero() {
+			high = append(high, event)
+		}
+	}
+	sort.Slice(high, func(i, j int) bool { return high[i].Date.Before(high[j].Date) })
+	return high, nil
+}
+
+// newsHaltWindow returns how long before and after a high-impact event trading is halted, or 0
+// when news halts are off.
+func newsHaltWindow(config Config) time.Duration {
+	return time.Duration(config.NewsHaltMinutes) * time.Minute
+}
+
+// activeNewsHalt returns the event trading is halted around at t, or nil if there is none.
+func activeNewsHalt(t time.Time) *EconomicEvent {
+	config := GetGlobalConfig()
+	window := newsHaltWindow(config)
+	if window <= 0 {
+		return nil
+	}
+	currencies := newsCurrencies(config)
+	events, _, _ := newsCalendar.Events()
+	for _, event := range events {
+		if !currencies[strings.ToUpper(event.Country)] {
+			continue
+		}
+		if !t.Before(event.Date.Add(-window)) && t.Before(event.Date.Add(window)) {
+			return &event
+		}
+	}
+	return nil
+}
+
+// newsHaltText describes a halt around an event in the chat's timezone.
+func newsHaltText(chatID int64, event *EconomicEvent) string {
+	until := event.Date.Add(newsHaltWindow(GetGlobalConfig()))
+	return tr(chatID, "High-impact news: %s (%s) at %s. Trading is paused until %s.",
+		event.Title, event.Country, formatUserTime(chatID, event.Date), formatUserTime(chatID, until))
+}
+
+// refuseDuringNews tells the chat a signal can't be confirmed while trading is halted around a
+// high-impact event, and reports whether it refused.
+func refuseDuringNews(chatID int64) bool {
+	event := activeNewsHalt(time.Now())
+	if event == nil {
+		return false
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, newsHaltText(chatID, event)))
+	return true
+}
+
+// startNewsCalendar fetches the economic calendar every hour while news halts are on, and tells
+// the signal chat when a halt starts and ends.
+func startNewsCalendar() {
+	newsCalendarOnce.Do(func() {
+		go func() {
+			var lastFetch time.Time
+			var halted *EconomicEvent
+			ticker := time.NewTicker(newsCheckInterval)
+			defer ticker.Stop()
+			for {
+				if newsHaltWindow(GetGlobalConfig()) > 0 && time.Since(lastFetch) >= newsRefreshInterval {
+					lastFetch = time.Now()
+					ctx, cancel := context.WithTimeout(context.Background(), newsClient.Timeout)
+					events, err := fetchNewsCalendar(ctx)
+					cancel()
+					if err != nil {
+						slog.Error("Failed to refresh the economic calendar", "error", err)
+					}
+					newsCalendar.Set(events, err)
+				}
+
+				event := activeNewsHalt(time.Now())
+				if chatID := GetGlobalConfig().TelegramChatID; bot != nil && chatID != 0 {
+					switch {
+					case event != nil && (halted == nil || *event != *halted):
+						bot.Send(tgbotapi.NewMessage(chatID, "\u23F8\uFE0F "+newsHaltText(chatID, event)))
+					case event == nil && halted != nil:
+						bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "\u25B6\uFE0F Trading has resumed after %s (%s).", halted.Title, halted.Country)))
+					}
+				}
+				halted = event
+
+				select {
+				case <-ticker.C:
+				case <-shuttingDown:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// handleNewsCommand lists the week's upcoming high-impact events that halt trading.
+func handleNewsCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	config := GetGlobalConfig()
+	if newsHaltWindow(config) <= 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "News halts are off.")))
+		return
+	}
+	events, fetchedAt, err := newsCalendar.Events()
+	if fetchedAt.IsZero() {
+		text := tr(chatID, "The economic calendar has not been fetched yet.")
+		if err != nil {
+			text += "\n" + err.Error()
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+		return
+	}
+
+	text := tr(chatID, "<b>High-Impact News</b>\nTrading pauses %d minutes before and after each event.\n", config.NewsHaltMinutes)
+	if event := activeNewsHalt(time.Now()); event != nil {
+		text += "\n\u23F8\uFE0F " + html.EscapeString(newsHaltText(chatID, event)) + "\n"
+	}
+	currencies := newsCurrencies(config)
+	cutoff := time.Now().Add(-newsHaltWindow(config))
+	var upcoming []EconomicEvent
+	for _, event := range events {
+		if currencies[strings.ToUpper(event.Country)] && !event.Date.Before(cutoff) {
+			upcoming = append(upcoming, event)
+		}
+	}
+	if len(upcoming) == 0 {
+		text += tr(chatID, "\nNo more high-impact events this week.")
+	} else {
+		text += "\n"
+	}
+	for i, event := range upcoming {
+		if i == maxNewsEvents {
+			text += tr(chatID, "...and %d more\n", len(upcoming)-maxNewsEvents)
+			break
+		}
+		text += fmt.Sprintf("%s <b>%s</b> %s\n", formatUserTime(chatID, event.Date), event.Country, html.EscapeString(event.Title))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
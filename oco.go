@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OCOGroup links a signal's TP and SL orders so a fill on one side cancels the other.
+type OCOGroup struct {
+	SignalID string
+	TPs      []string // Client order IDs of the TP orders
+	Partial  []string // Client order IDs of the TPs that close only part of the position
+	SL       string   // Client order ID of the SL order, empty if none
+}
+
+// OCOStore manages OCO groups by account and symbol with concurrency safety.
+type OCOStore struct {
+	sync.RWMutex
+	groups map[positionKey]*OCOGroup
+}
+
+// NewOCOStore creates a new instance of OCOStore.
+func NewOCOStore() *OCOStore {
+	return &OCOStore{
+		groups: make(map[positionKey]*OCOGroup),
+	}
+}
+
+// Get returns a copy of the symbol's group, as fills of its orders are handled concurrently.
+func (s *OCOStore) Get(key positionKey) (*OCOGroup, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	group, exists := s.groups[key]
+	if !exists {
+		return nil, false
+	}
+	return &OCOGroup{
+		SignalID: group.SignalID,
+		TPs:      slices.Clone(group.TPs),
+		Partial:  slices.Clone(group.Partial),
+		SL:       group.SL,
+	}, true
+}
+
+func (s *OCOStore) Delete(key positionKey) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.groups, key)
+}
+
+// Add links an order to the symbol's group, starting a new group if the signal changed.
+func (s *OCOStore) Add(key positionKey, signalID, clientID string, isSL bool) {
+	s.Lock()
+	defer s.Unlock()
+	group, exists := s.groups[key]
+	if !exists || group.SignalID != signalID {
+		group = &OCOGroup{SignalID: signalID}
+		s.groups[key] = group
+	}
+	if isSL {
+		group.SL = clientID
+		return
+	}
+	for _, id := range group.TPs {
+		if id == clientID {
+			return
+		}
+	}
+	group.TPs = append(group.TPs, clientID)
+}
+
+// AddPartial links a TP order that closes only part of the position to the symbol's group.
+func (s *OCOStore) AddPartial(key positionKey, signalID, clientID string) {
+	s.Add(key, signalID, clientID, false)
+	s.Lock()
+	defer s.Unlock()
+	group := s.groups[key]
+	if !containsString(group.Partial, clientID) {
+		group.Partial = append(group.Partial, clientID)
+	}
+}
+
+// Remove unlinks a TP order from the symbol's group, e.g. once it has filled or was replaced.
+func (s *OCOStore) Remove(key positionKey, clientID string) {
+	s.Lock()
+	defer s.Unlock()
+	group, exists := s.groups[key]
+	if !exists {
+		return
+	}
+	group.TPs = removeString(group.TPs, clientID)
+	group.Partial = removeString(group.Partial, clientID)
+}
+
+var ocoGroups = NewOCOStore()
+
+// handleOCOFill cancels the counterpart orders when a linked TP or SL fills. It makes REST
+// calls, so the user data stream runs it in a goroutine of its own.
+// The last TP closes the rest of the position, so its fill cancels the SL and any TPs left.
+// The TPs before it are partial: their fill leaves the rest of the position and its orders in
+// place (see handlePartialTPFill).
+func (b *BinanceClient) handleOCOFill(symbol, clientID string, userID int64) {
+	group, exists := ocoGroups.Get(b.key(symbol))
+	if !exists {
+		return
+	}
+
+	var counterparts []string
+	switch {
+	case containsString(group.Partial, clientID):
+		ocoGroups.Remove(b.key(symbol), clientID)
+		b.handlePartialTPFill(symbol, clientID, group, userID)
+		return
+	case clientID == group.SL:
+		counterparts = group.TPs
+	case containsString(group.TPs, clientID):
+		for _, id := range group.TPs {
+			if id != clientID {
+				counterparts = append(counterparts, id)
+			}
+		}
+		if group.SL != "" {
+			counterparts = append(counterparts, group.SL)
+		}
+	default:
+		return
+	}
+	ocoGroups.Delete(b.key(symbol))
+	trailingTPs.Delete(b.key(symbol))
+
+	var cancelled []string
+	for _, id := range counterparts {
+		res, err := b.Client.NewCancelOrderService().
+			Symbol(symbol).
+			OrigClientOrderID(id).
+			Do(context.Background())
+		auditOrder(AuditCancelOrder, id, "symbol="+symbol, res, err)
+		if err != nil {
+			// Already filled or cancelled orders are expected here; auditOrder logged it
+			continue
+		}
+		_, tag, _ := parseClientOrderID(id)
+		cancelled = append(cancelled, strings.ToUpper(tag))
+	}
+
+	_, filledTag, _ := parseClientOrderID(clientID)
+	msg := tr(userID, "%s filled for %s.", strings.ToUpper(filledTag), symbol)
+	if len(cancelled) > 0 {
+		msg += tr(userID, " Cancelled linked order(s): %s.", strings.Join(cancelled, ", "))
+	} else {
+		msg += tr(userID, " No linked orders were left to cancel.")
+	}
+	b.sendMessageToUser(userID, msg)
+}
+
+// containsString reports whether list contains value.
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString returns list without value.
+func removeString(list []string, value string) []string {
+	var kept []string
+	for _, v := range list {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/delivery"
+	"github.com/adshao/go-binance/v2/futures"
+	"gorm.io/gorm"
+)
+
+// Order is an order the bot placed on Binance, linked to its signal and kept up to date from
+// the user-data stream.
+type Order struct {
+	ID              uint   `gorm:"primaryKey"`
+	Symbol          string `gorm:"uniqueIndex:idx_orders_symbol_order_id;size:32"`
+	OrderID         int64  `gorm:"uniqueIndex:idx_orders_symbol_order_id"` // Binance order IDs are unique per symbol
+	ClientOrderID   string `gorm:"index;size:64"`                          // Reused when a TP is re-placed, e.g. after a DCA fill
+	SignalID        string `gorm:"index;size:128"`
+	Tag             string // entry, tpN, sl, dcaN or undo
+	Type            string
+	Side            string
+	Price           float64 // 0 for market orders
+	StopPrice       float64 // Trigger price of TP/SL orders
+	Quantity        float64 // 0 for close-position orders
+	Status          string  `gorm:"index;size:32"`
+	FilledQty       float64
+	AvgPrice        float64
+	RealizedPnL     float64
+	Commission      float64
+	CommissionAsset string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// saveOrder adds the order, or applies update to its existing row found by symbol and Binance
+// order ID. A nil update leaves an existing row unchanged.
+func saveOrder(order *Order, update func(existing *Order)) error {
+	var existing Order
+	err := db.Where("symbol = ? AND order_id = ?", order.Symbol, order.OrderID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to retrieve order: %w", err)
+	}
+	if err == nil {
+		if update == nil {
+			return nil
+		}
+		update(&existing)
+		order = &existing
+	}
+	if err := db.Save(order).Error; err != nil {
+		return fmt.Errorf("failed to save order: %w", err)
+	}
+	return nil
+}
+
+// newOrder returns the order row for a placed order with the bot's client order ID.
+func newOrder(symbol string, orderID int64, clientID string) *Order {
+	signalID, tag, _ := orderSignalID(clientID)
+	return &Order{Symbol: symbol, OrderID: orderID, ClientOrderID: clientID, SignalID: signalID, Tag: tag}
+}
+
+// recordFuturesOrder stores a USDT-M order from Binance's response to placing it.
+func recordFuturesOrder(res *futures.CreateOrderResponse) {
+	order := newOrder(res.Symbol, res.OrderID, res.ClientOrderID)
+	order.Type = string(res.Type)
+	order.Side = string(res.Side)
+	order.Price, _ = strconv.ParseFloat(res.Price, 64)
+	order.StopPrice, _ = strconv.ParseFloat(res.StopPrice, 64)
+	order.Quantity, _ = strconv.ParseFloat(res.OrigQuantity, 64)
+	order.Status = string(res.Status)
+	order.FilledQty, _ = strconv.ParseFloat(res.ExecutedQuantity, 64)
+	order.AvgPrice, _ = strconv.ParseFloat(res.AvgPrice, 64)
+	// The stream may already have reported the order, and its status is newer than the response's
+	if err := saveOrder(order, nil); err != nil {
+		reportError(binanceLog, ErrorSourceDatabase, "Failed to record order", err, "signal_id", order.SignalID,
+			"symbol", order.Symbol, "order_id", order.OrderID, "client_order_id", order.ClientOrderID)
+	}
+}
+
+// recordDeliveryOrder stores a COIN-M order from Binance's response to placing it.
+func recordDeliveryOrder(res *delivery.CreateOrderResponse) {
+	order := newOrder(res.Symbol, res.OrderID, res.ClientOrderID)
+	order.Type = string(res.Type)
+	order.Side = string(res.Side)
+	order.Price, _ = strconv.ParseFloat(res.Price, 64)
+	order.StopPrice, _ = strconv.ParseFloat(res.StopPrice, 64)
+	order.Quantity, _ = strconv.ParseFloat(res.OrigQuantity, 64)
+	order.Status = string(res.Status)
+	order.FilledQty, _ = strconv.ParseFloat(res.ExecutedQuantity, 64)
+	order.AvgPrice, _ = strconv.ParseFloat(res.AvgPrice, 64)
+	if err := saveOrder(order, nil); err != nil {
+		reportError(binanceLog, ErrorSourceDatabase, "Failed to record order", err, "signal_id", order.SignalID,
+			"symbol", order.Symbol, "order_id", order.OrderID, "client_order_id", order.ClientOrderID)
+	}
+}
+
+// recordOrderUpdate applies a user-data stream update to the bot's order, adding it if the
+// placement response was lost. Orders placed outside the bot are ignored.
+func recordOrderUpdate(update futures.WsOrderTradeUpdate) {
+	if _, _, ok := parseClientOrderID(update.ClientOrderID); !ok {
+		return
+	}
+	filled, _ := strconv.ParseFloat(update.AccumulatedFilledQty, 64)
+	avgPrice, _ := strconv.ParseFloat(update.AveragePrice, 64)
+	var realized, commission float64
+	if update.ExecutionType == futures.OrderExecutionTypeTrade {
+		realized, _ = strconv.ParseFloat(update.RealizedPnL, 64)
+		commission, _ = strconv.ParseFloat(update.Commission, 64)
+	}
+	apply := func(order *Order) {
+		order.Status = string(update.Status)
+		order.FilledQty = filled
+		order.AvgPrice = avgPrice
+		order.RealizedPnL += realized
+		order.Commission += commission
+		if update.CommissionAsset != "" {
+			order.CommissionAsset = update.CommissionAsset
+		}
+	}
+
+	order := newOrder(update.Symbol, update.ID, update.ClientOrderID)
+	order.Type = string(update.OriginalType)
+	order.Side = string(update.Side)
+	order.Price, _ = strconv.ParseFloat(update.OriginalPrice, 64)
+	order.StopPrice, _ = strconv.ParseFloat(update.StopPrice, 64)
+	order.Quantity, _ = strconv.ParseFloat(update.OriginalQty, 64)
+	apply(order)
+	binanceLog.Debug("Order update", "signal_id", order.SignalID, "symbol", order.Symbol, "order_id", order.OrderID,
+		"client_order_id", order.ClientOrderID, "status", order.Status, "filled", order.FilledQty)
+	if err := saveOrder(order, apply); err != nil {
+		reportError(binanceLog, ErrorSourceDatabase, "Failed to update order", err, "signal_id", order.SignalID,
+			"symbol", order.Symbol, "order_id", order.OrderID, "client_order_id", order.ClientOrderID)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/delivery"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionPanic confirms or cancels a /panic.
+const ActionPanic = "panic"
+
+// OrderTagPanic tags the market orders /panic closes tracked positions with.
+const OrderTagPanic = "panic"
+
+// panicConfirmWindow is how long the /panic confirmation button stays valid, and panicTimeout
+// bounds closing everything on one account once no trade is being placed on it.
+const (
+	panicConfirmWindow = time.Minute
+	panicTimeout       = 30 * time.Second
+)
+
+// PanicResult is what /panic did on one account.
+type PanicResult struct {
+	Account   string
+	Cancelled []string // Symbols whose open orders were cancelled, USDT-M and COIN-M
+	Closed    []string // Positions market-closed, e.g. "BTCUSDT long 0.010" or "BTCUSD_PERP short 3 contracts"
+	Errors    []string
+}
+
+// handlePanicCommand asks the admin to confirm closing everything with "/panic", and resumes
+// trading after a panic with "/panic off".
+func handlePanicCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+	case "off":
+		resumeTrading(chatID, senderID(message))
+		return
+	default:
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /panic to close everything, /panic off to resume trading")))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "⚠️ This market-closes every open position and cancels every open order on the bot's accounts and the accounts traders connected, then halts trading until /panic off. Continue?"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Close Everything"), fmt.Sprintf("%s|go|%d", ActionPanic, time.Now().Unix())),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Cancel"), fmt.Sprintf("%s|cancel", ActionPanic)),
+		),
+	)
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
+
+// handlePanicCallback runs a confirmed /panic, or dismisses it on Cancel. Only admins can
+// confirm, and only within panicConfirmWindow of the command.
+func handlePanicCallback(callback *tgbotapi.CallbackQuery, args []string) {
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+	if !hasRole(userID, RoleAdmin) {
+		bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, tr(chatID, "This command requires the %s role.", RoleAdmin)))
+		return
+	}
+	// The question is answered either way
+	if _, err := bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})); err != nil {
+		telegramLog.Error("Failed to remove keyboard", "chat_id", chatID, "error", err)
+	}
+	if args[0] != "go" || len(args) < 2 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Panic cancelled.")))
+		return
+	}
+	asked, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || time.Since(time.Unix(asked, 0)) > panicConfirmWindow {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This confirmation has expired. Send /panic again.")))
+		return
+	}
+
+	auditAction(userID, chatID, AuditPanic, "", "")
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Closing everything...")))
+	go func() {
+		results, haltErr := panicCloseAll(userID)
+		text := formatPanicResults(chatID, results, haltErr)
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "HTML"
+		if _, err := bot.Send(msg); err != nil {
+			telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+		}
+		postToDiscord(text)
+	}()
+}
+
+// panicCloseAll halts trading, then cancels the open orders and market-closes the positions of
+// the main account, every additional account and every account traders connected with /connect,
+// Binance USDT-M and COIN-M futures or Bybit USDT perpetuals. It returns why the halt could not be
+// saved, in which case trading is only halted until the bot restarts.
+func panicCloseAll(userID int64) ([]PanicResult, error) {
+	// No new trade may start while the accounts are being closed
+	haltErr := setTradingHalted(true, userID)
+	if haltErr != nil {
+		telegramLog.Error("Failed to save the trading halt", "error", haltErr)
+	}
+
+	names := []string{defaultAccountName}
+	accounts, err := ListBinanceAccounts()
+	if err != nil {
+		telegramLog.Error("Failed to load accounts for /panic", "error", err)
+	}
+	for _, account := range accounts {
+		names = append(names, account.Name)
+	}
+
+	results := make([]PanicResult, 0, len(names))
+	for _, name := range names {
+		exchange, err := accountExchange(name)
+		if err != nil {
+			results = append(results, PanicResult{Account: name, Errors: []string{err.Error()}})
+			continue
+		}
+		result := panicCloseExchange(exchange)
+		result.Account = name
+		results = append(results, result)
+	}
+
+	users, err := ListConnectedUsers()
+	if err != nil {
+		results = append(results, PanicResult{Account: personalAccountName, Errors: []string{err.Error()}})
+	}
+	for _, user := range users {
+		name := fmt.Sprintf("%s:%d", personalAccountName, user)
+		exchange, err := userExchange(user)
+		if err != nil || exchange == nil {
+			if err == nil {
+				err = fmt.Errorf("user %d has no connected account", user)
+			}
+			results = append(results, PanicResult{Account: name, Errors: []string{err.Error()}})
+			continue
+		}
+		result := panicCloseExchange(exchange)
+		result.Account = name
+		results = append(results, result)
+	}
+	return results, haltErr
+}
+
+// panicCloseExchange cancels the open orders and closes the positions of an account.
+func panicCloseExchange(exchange Exchange) PanicResult {
+	if client, ok := exchange.(*BinanceClient); ok {
+		return client.panicClose()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), panicTimeout)
+	defer cancel()
+
+	var result PanicResult
+	panicCloseLinear(ctx, exchange, &result)
+	return result
+}
+
+// panicClose cancels every open order on the account's symbols with open orders or tracked
+// positions, then market-closes the open positions with reduce-only orders, first on USDT-M and
+// then on COIN-M futures. It waits for a trade being placed on the account to finish, so
+// panicTimeout only starts once it has the account to itself.
+func (b *BinanceClient) panicClose() PanicResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), panicTimeout)
+	defer cancel()
+
+	var result PanicResult
+	panicCloseLinear(ctx, b, &result)
+	b.panicCloseDelivery(ctx, &result)
+	return result
+}
+
+// panicCloseLinear cancels the open orders and closes the USDT-margined positions of an account.
+// Orders are cancelled first so no DCA or pending entry order reopens a closed position.
+func panicCloseLinear(ctx context.Context, exchange Exchange, result *PanicResult) {
+	positions, err := exchange.Positions(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to get positions: %v", err))
+	}
+	cancelled, err := exchange.CancelOrders(ctx)
+	result.Cancelled = append(result.Cancelled, cancelled...)
+	result.Errors = append(result.Errors, errorLines(err)...)
+
+	for _, position := range positions {
+		direction := "long"
+		if position.Amount < 0 {
+			direction = "short"
+		}
+		if err := exchange.ClosePosition(ctx, position); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to close %s: %v", position.Symbol, err))
+			continue
+		}
+		result.Closed = append(result.Closed, fmt.Sprintf("%s %s %s", position.Symbol, direction, formatFloat(math.Abs(position.Amount))))
+	}
+}
+
+// errorLines returns the messages of the errors joined in err, or of err itself.
+func errorLines(err error) []string {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	var lines []string
+	for _, err := range joined.Unwrap() {
+		lines = append(lines, errorLines(err)...)
+	}
+	return lines
+}
+
+// panicCloseDelivery cancels the open orders and closes the positions of the account's COIN-M
+// futures. COIN-M positions aren't tracked, so only the symbols with open orders are cancelled.
+func (b *BinanceClient) panicCloseDelivery(ctx context.Context, result *PanicResult) {
+	orders, err := b.Delivery.NewListOpenOrdersService().Do(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to list COIN-M open orders: %v", err))
+	}
+	risks, err := b.Delivery.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to get COIN-M positions: %v", err))
+	}
+
+	symbols := make(map[string]bool)
+	for _, order := range orders {
+		symbols[order.Symbol] = true
+	}
+	sorted := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		sorted = append(sorted, symbol)
+	}
+	sort.Strings(sorted)
+
+	for _, symbol := range sorted {
+		err := b.Delivery.NewCancelAllOpenOrdersService().Symbol(symbol).Do(ctx)
+		auditOrder(AuditCancelOrder, "", "symbol="+symbol+" all=true", nil, err)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to cancel %s orders: %v", symbol, err))
+			continue
+		}
+		result.Cancelled = append(result.Cancelled, symbol)
+	}
+
+	for _, risk := range risks {
+		amount, _ := strconv.ParseFloat(risk.PositionAmt, 64)
+		if amount == 0 {
+			continue
+		}
+		side, direction := delivery.SideTypeSell, "long"
+		if amount < 0 {
+			side, direction = delivery.SideTypeBuy, "short"
+		}
+		quantity := strings.TrimPrefix(risk.PositionAmt, "-")
+		res, err := b.Delivery.NewCreateOrderService().
+			Symbol(risk.Symbol).
+			Side(side).
+			Type(delivery.OrderTypeMarket).
+			Quantity(quantity).
+			ReduceOnly(true).
+			Do(ctx)
+		auditOrder(AuditOrder, "", fmt.Sprintf("symbol=%s side=%s type=MARKET quantity=%s reduce_only=true", risk.Symbol, side, quantity), res, err)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to close %s: %v", risk.Symbol, err))
+			continue
+		}
+		recordDeliveryOrder(res)
+		result.Closed = append(result.Closed, fmt.Sprintf("%s %s %s contracts", risk.Symbol, direction, formatFloat(math.Abs(amount))))
+	}
+}
+
+// formatPanicResults summarizes what /panic did on each account.
+func formatPanicResults(chatID int64, results []PanicResult, haltErr error) string {
+	text := tr(chatID, "\U0001F6A8 <b>Panic</b>\nTrading is halted until /panic off.\n")
+	if haltErr != nil {
+		text += "❌ " + html.EscapeString(tr(chatID, "The halt could not be saved and ends if the bot restarts: %v", haltErr)) + "\n"
+	}
+	for _, result := range results {
+		text += fmt.Sprintf("\n<b>%s</b>\n", html.EscapeString(result.Account))
+		if len(result.Closed) > 0 {
+			text += tr(chatID, "Closed: %s\n", strings.Join(result.Closed, ", "))
+		} else {
+			text += tr(chatID, "No open positions.\n")
+		}
+		if len(result.Cancelled) > 0 {
+			text += tr(chatID, "Orders cancelled on: %s\n", strings.Join(result.Cancelled, ", "))
+		}
+		for _, err := range result.Errors {
+			text += "❌ " + html.EscapeString(err) + "\n"
+		}
+	}
+	return text
+}
+
+// setTradingHalted sets whether new trades are refused and saves it, with the user who changed
+// it in the configuration history. The change applies even if it can't be saved, until the bot
+// restarts.
+func setTradingHalted(halted bool, userID int64) error {
+	config := GetGlobalConfig()
+	if config.TradingHalted == halted {
+		return nil
+	}
+	config.TradingHalted = halted
+	note := "Trading resumed with /panic off"
+	if halted {
+		note = "Trading halted with /panic"
+	}
+	err := saveConfig(&config, fmt.Sprintf("Telegram user %d", userID), note)
+	SetGlobalConfig(config)
+	return err
+}
+
+// resumeTrading lets trades through again after a /panic.
+func resumeTrading(chatID, userID int64) {
+	if !GetGlobalConfig().TradingHalted {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Trading is not halted.")))
+		return
+	}
+	auditAction(userID, chatID, AuditPanic, "", "resumed trading")
+	if err := setTradingHalted(false, userID); err != nil {
+		telegramLog.Error("Failed to save resuming trading", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Trading resumed, but this could not be saved: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Trading resumed.")))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// A4 page size in PDF points.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+)
+
+// pdfPage is a single-page PDF document drawn with the standard Helvetica fonts, filled
+// rectangles, lines and RGB images. Coordinates are in points from the bottom left corner.
+type pdfPage struct {
+	content bytes.Buffer
+	images  [][]byte // Image XObjects, complete with their dictionaries
+}
+
+// pdfColor renders a colour as PDF RGB components.
+func pdfColor(c color.RGBA) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// pdfString escapes text as a PDF string in WinAnsiEncoding, replacing characters it can't hold.
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '—' || r == '–':
+			b.WriteByte('-')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Text writes text with its baseline starting at x, y.
+func (p *pdfPage) Text(x, y, size float64, bold bool, c color.RGBA, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT %s rg /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", pdfColor(c), font, size, x, y, pdfString(text))
+}
+
+// Rect fills a rectangle with its bottom left corner at x, y.
+func (p *pdfPage) Rect(x, y, width, height float64, c color.RGBA) {
+	fmt.Fprintf(&p.content, "%s rg %.2f %.2f %.2f %.2f re f\n", pdfColor(c), x, y, width, height)
+}
+
+// Line strokes a line from x0, y0 to x1, y1.
+func (p *pdfPage) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	fmt.Fprintf(&p.content, "%s RG %.2f w %.2f %.2f m %.2f %.2f l S\n", pdfColor(c), width, x0, y0, x1, y1)
+}
+
+// Image draws img scaled to width by height with its bottom left corner at x, y.
+func (p *pdfPage) Image(x, y, width, height float64, img image.Image) error {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			c := color.RGBAModel.Convert(img.At(px, py)).(color.RGBA)
+			pixels = append(pixels, c.R, c.G, c.B)
+		}
+	}
+	var data bytes.Buffer
+	w := zlib.NewWriter(&data)
+	if _, err := w.Write(pixels); err != nil {
+		return fmt.Errorf("failed to compress image: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to compress image: %v", err)
+	}
+
+	var object bytes.Buffer
+	fmt.Fprintf(&object, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n",
+		bounds.Dx(), bounds.Dy(), data.Len())
+	object.Write(data.Bytes())
+	object.WriteString("\nendstream")
+	p.images = append(p.images, object.Bytes())
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", width, height, x, y, len(p.images))
+	return nil
+}
+
+// Bytes returns the PDF document.
+func (p *pdfPage) Bytes() []byte {
+	fonts := "<< /F1 4 0 R /F2 5 0 R >>"
+	var xobjects []string
+	for i := range p.images {
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, 7+i))
+	}
+	resources := "<< /Font " + fonts
+	if len(xobjects) > 0 {
+		resources += " /XObject << " + strings.Join(xobjects, " ") + " >>"
+	}
+	resources += " >>"
+
+	objects := [][]byte{
+		[]byte("<< /Type /Catalog /Pages 2 0 R >>"),
+		[]byte("<< /Type /Pages /Kids [3 0 R] /Count 1 >>"),
+		[]byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources %s /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight, resources)),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"),
+		[]byte(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String())),
+	}
+	objects = append(objects, p.images...)
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n", i+1)
+		doc.Write(object)
+		doc.WriteString("\nendobj\n")
+	}
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return doc.Bytes()
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionAddPosition confirms a signal that adds to a position in the same symbol and direction.
+const ActionAddPosition = "addpos"
+
+// duplicateCheckTimeout bounds fetching positions before a Confirm goes ahead, and
+// addPositionWindow is how long the Add to Position button stays valid.
+const (
+	duplicateCheckTimeout = 5 * time.Second
+	addPositionWindow     = 2 * time.Minute
+)
+
+// AddPositionQuestion is an Add to Position button waiting for the user who pressed Confirm.
+type AddPositionQuestion struct {
+	UserID  int64
+	AskedAt time.Time
+}
+
+// AddPositionStore manages Add to Position questions by signal ID with concurrency safety.
+type AddPositionStore struct {
+	sync.Mutex
+	pending map[string]AddPositionQuestion
+}
+
+// NewAddPositionStore creates a new instance of AddPositionStore.
+func NewAddPositionStore() *AddPositionStore {
+	return &AddPositionStore{
+		pending: make(map[string]AddPositionQuestion),
+	}
+}
+
+// Ask records that userID was asked about adding to a position with the signal.
+func (s *AddPositionStore) Ask(signalID string, userID int64) {
+	s.Lock()
+	defer s.Unlock()
+	s.pending[signalID] = AddPositionQuestion{UserID: userID, AskedAt: time.Now()}
+}
+
+// Take removes the question and reports whether userID was asked within addPositionWindow.
+func (s *AddPositionStore) Take(signalID string, userID int64) bool {
+	s.Lock()
+	defer s.Unlock()
+	asked, exists := s.pending[signalID]
+	if !exists || asked.UserID != userID {
+		return false
+	}
+	delete(s.pending, signalID)
+	return time.Since(asked.AskedAt) < addPositionWindow
+}
+
+var addPositionQuestions = NewAddPositionStore()
+
+// duplicatePosition describes why the signal would stack onto an existing position: an open
+// position in its symbol and direction on the account userID trades on, or another signal for
+// them confirmed in the chat within DuplicateGuardMinutes. It returns "" if there is none or the
+// guard is off. Positions that can't be fetched don't hold the trade back.
+func duplicatePosition(chatID, userID int64, signal *AlertMessage) string {
+	config := GetGlobalConfig()
+	if !config.DuplicateGuard {
+		return ""
+	}
+	direction := tr(chatID, "long")
+	if signal.SignalType == "Sell" {
+		direction = tr(chatID, "short")
+	}
+
+	routed := *signal // tradingExchange records the account on the signal, which must not change the stored one
+	exchange, err := tradingExchange(userID, &routed)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), duplicateCheckTimeout)
+		positions, err := exchange.Positions(ctx)
+		cancel()
+		if err != nil {
+			binanceLog.Error("Failed to check positions before confirming", "signal_id", signal.SignalID, "error", err)
+		}
+		for _, position := range positions {
+			if position.Symbol == signal.Symbol && (position.Amount > 0) == (signal.SignalType == "Buy") && position.Amount != 0 {
+				return tr(chatID, "You already have a %s %s position of %s at %s.", signal.Symbol, direction,
+					formatFloat(math.Abs(position.Amount)), formatFloat(position.EntryPrice))
+			}
+		}
+	}
+
+	if config.DuplicateGuardMinutes <= 0 {
+		return ""
+	}
+	cutoff := time.Now().Add(-time.Duration(config.DuplicateGuardMinutes) * time.Minute)
+	var recent Signal
+	err = db.Model(&Signal{}).
+		Joins("JOIN signal_events ON signal_events.signal_id = signals.signal_id").
+		Where("signal_events.status = ? AND signal_events.at >= ?", SignalConfirmed, cutoff).
+		Where("signals.chat_id = ? AND signals.symbol = ? AND signals.side = ? AND signals.signal_id <> ?",
+			chatID, signal.Symbol, signal.SignalType, signal.SignalID).
+		Order("signal_events.at desc").
+		First(&recent).Error
+	if err != nil {
+		return ""
+	}
+	return tr(chatID, "Another %s %s signal was confirmed in the last %d minutes.", signal.Symbol, direction, config.DuplicateGuardMinutes)
+}
+
+// guardDuplicatePosition asks for an explicit Add to Position before a Confirm stacks onto a
+// position in the same symbol and direction, and reports whether the Confirm may go ahead.
+func guardDuplicatePosition(chatID, userID int64, signal *AlertMessage) bool {
+	reason := duplicatePosition(chatID, userID, signal)
+	if reason == "" {
+		return true
+	}
+	msg := tgbotapi.NewMessage(chatID, reason+"\n"+tr(chatID, "Confirming %s adds to it. Add to the position?", signal.Symbol))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Add to Position"), fmt.Sprintf("%s|%s", ActionAddPosition, signal.SignalID)),
+		),
+	)
+	msg.ReplyMarkup = keyboard
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "signal_id", signal.SignalID, "symbol", signal.Symbol, "chat_id", chatID, "error", err)
+	}
+	addPositionQuestions.Ask(signal.SignalID, userID)
+	return false
+}
+
+// handleAddPosition confirms a signal after its Add to Position button was pressed, taking the
+// same steps as Confirm past the duplicate check. Only the user who pressed Confirm can add.
+func handleAddPosition(chatID, userID int64, messageID int, signalID string) {
+	if !addPositionQuestions.Take(signalID, userID) {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This question has expired or was for another user. Press Confirm on the signal again.")))
+		return
+	}
+	// The question is answered; the signal's own message is edited on confirmation
+	if _, err := bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})); err != nil {
+		telegramLog.Error("Failed to remove keyboard", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+	signalMessageID, _ := messageStore.Get(signalID)
+	proceedConfirm(chatID, userID, signalMessageID, signalID)
+}
+
+// replaceEarlierProtection cancels the TP/SL orders, trailing stop and DCA ladder of the earlier
+// signal on a position the signal was added to with Add to Position, since the signal's own TP/SL
+// orders protect the whole position from then on. It returns the quantity they protect: the size
+// of the position after the earlier orders were cancelled, or quantity if no other signal's
+// orders were linked to it or the position can't be fetched.
+func (b *BinanceClient) replaceEarlierProtection(ctx context.Context, signal *AlertMessage, quantity string, userID int64) string {
+	symbol, key := signal.Symbol, b.key(signal.Symbol)
+	// The earlier ladder would move orders that are cancelled below
+	if ladder, exists := dcaLadders.Get(key); exists && ladder.Signal.SignalID != signal.SignalID {
+		b.cancelDCALadder(symbol, userID)
+	}
+	group, exists := ocoGroups.Get(key)
+	if !exists || group.SignalID == signal.SignalID {
+		return quantity
+	}
+	ocoGroups.Delete(key)
+	trailingTPs.Delete(key)
+
+	var cancelled []string
+	for _, id := range append(group.TPs, group.SL) {
+		if id == "" {
+			continue
+		}
+		res, err := b.Client.NewCancelOrderService().
+			Symbol(symbol).
+			OrigClientOrderID(id).
+			Do(ctx)
+		auditOrder(AuditCancelOrder, id, "symbol="+symbol, res, err)
+		if err != nil {
+			// Already filled or cancelled orders are expected here; auditOrder logged it
+			continue
+		}
+		_, tag, _ := parseClientOrderID(id)
+		cancelled = append(cancelled, strings.ToUpper(tag))
+	}
+	if len(cancelled) == 0 {
+		return quantity
+	}
+
+	size := quantity
+	risks, err := b.Client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		binanceLog.Warn("Failed to get the position to protect after Add to Position", "signal_id", signal.SignalID, "symbol", symbol, "error", err)
+	}
+	for _, risk := range risks {
+		if amount, _ := strconv.ParseFloat(risk.PositionAmt, 64); amount != 0 {
+			size = strings.TrimPrefix(risk.PositionAmt, "-")
+		}
+	}
+	b.sendMessageToUser(userID, tr(userID, "Added to the %s position. The earlier signal's %s were cancelled, and this signal's TP/SL orders protect the whole position of %s.",
+		symbol, strings.Join(cancelled, ", "), size))
+	return size
+}
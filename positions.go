@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// TrackedPosition holds the running fill totals for a position opened from a signal.
+type TrackedPosition struct {
+	SignalID      string
+	Symbol        string
+	Side          futures.SideType // Side of the entry order
+	EntryPrice    float64          // Entry price from the signal, used until fills arrive
+	EntryQty      float64
+	EntryNotional float64
+	ExitQty       float64
+	ExitNotional  float64
+	RealizedPnL   float64
+	MakerFees     float64            // Fees in the quote asset for maker fills
+	TakerFees     float64            // Fees in the quote asset for taker fills
+	OtherFees     map[string]float64 // Fees charged in other assets (e.g. BNB), by asset
+	OtherMaker    map[string]float64 // Maker share of OtherFees, by asset
+	Opened        bool               // Set once Binance reports a non-zero position amount
+	OpenedAt      time.Time          // When the position opened, zero if restored on startup
+	InitialRisk   float64            // Loss at the SL when it opened, set once the position closes
+	Added         []string           // Signals added to the position with Add to Position
+}
+
+// hasSignal reports whether the position was opened by or added to with the signal.
+func (p *TrackedPosition) hasSignal(signalID string) bool {
+	return p.SignalID == signalID || containsString(p.Added, signalID)
+}
+
+// ownsOrder reports whether a client order ID belongs to a signal of the position.
+func (p *TrackedPosition) ownsOrder(clientID string) bool {
+	if isSignalOrder(clientID, p.SignalID) {
+		return true
+	}
+	for _, signalID := range p.Added {
+		if isSignalOrder(clientID, signalID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fees returns the total fees in the quote asset.
+func (p *TrackedPosition) Fees() float64 {
+	return p.MakerFees + p.TakerFees
+}
+
+// AverageEntryPrice returns the filled entry price, falling back to the signal entry.
+func (p *TrackedPosition) AverageEntryPrice() float64 {
+	if p.EntryQty > 0 {
+		return p.EntryNotional / p.EntryQty
+	}
+	return p.EntryPrice
+}
+
+// AverageExitPrice returns the average price of the closing fills.
+func (p *TrackedPosition) AverageExitPrice() float64 {
+	if p.ExitQty > 0 {
+		return p.ExitNotional / p.ExitQty
+	}
+	return 0
+}
+
+// positionKey identifies a symbol on one account. The stores that follow positions and their
+// orders are keyed by it, since a mirrored signal trades the same symbol on several accounts
+// with the same client order IDs.
+type positionKey struct {
+	Account string
+	Symbol  string
+}
+
+// key returns the positionKey of a symbol on the client's account.
+func (b *BinanceClient) key(symbol string) positionKey {
+	return positionKey{Account: b.Account, Symbol: symbol}
+}
+
+// PositionTracker manages tracked positions by account and symbol with concurrency safety.
+type PositionTracker struct {
+	sync.RWMutex
+	positions map[positionKey]*TrackedPosition
+}
+
+// NewPositionTracker creates a new instance of PositionTracker.
+func NewPositionTracker() *PositionTracker {
+	return &PositionTracker{
+		positions: make(map[positionKey]*TrackedPosition),
+	}
+}
+
+func (t *PositionTracker) Set(key positionKey, position *TrackedPosition) {
+	t.Lock()
+	defer t.Unlock()
+	t.positions[key] = position
+}
+
+func (t *PositionTracker) Get(key positionKey) (*TrackedPosition, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	position, exists := t.positions[key]
+	return position, exists
+}
+
+func (t *PositionTracker) Delete(key positionKey) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.positions, key)
+}
+
+// Symbols returns the symbols with a tracked position on the account.
+func (t *PositionTracker) Symbols(account string) []string {
+	t.RLock()
+	defer t.RUnlock()
+	var symbols []string
+	for key := range t.positions {
+		if key.Account == account {
+			symbols = append(symbols, key.Symbol)
+		}
+	}
+	return symbols
+}
+
+var positionTracker = NewPositionTracker()
+
+// recordOrderFill accumulates fill quantity, realized PnL and fees for a tracked position.
+func (b *BinanceClient) recordOrderFill(update futures.WsOrderTradeUpdate) {
+	if update.ExecutionType != futures.OrderExecutionTypeTrade {
+		return
+	}
+
+	positionTracker.Lock()
+	defer positionTracker.Unlock()
+
+	position, exists := positionTracker.positions[b.key(update.Symbol)]
+	if !exists {
+		return
+	}
+
+	qty, _ := strconv.ParseFloat(update.LastFilledQty, 64)
+	price, _ := strconv.ParseFloat(update.LastFilledPrice, 64)
+	realized, _ := strconv.ParseFloat(update.RealizedPnL, 64)
+
+	if update.Side == position.Side {
+		position.EntryQty += qty
+		position.EntryNotional += qty * price
+	} else {
+		position.ExitQty += qty
+		position.ExitNotional += qty * price
+	}
+	position.RealizedPnL += realized
+
+	if update.Commission != "" {
+		fee, _ := strconv.ParseFloat(update.Commission, 64)
+		position.addFee(fee, update.CommissionAsset, update.IsMaker)
+	}
+}
+
+// addFee adds a fill's commission. Fees in the quote asset are netted directly; others are
+// converted when the position closes.
+func (p *TrackedPosition) addFee(fee float64, asset string, maker bool) {
+	switch {
+	case strings.HasSuffix(p.Symbol, asset) && maker:
+		p.MakerFees += fee
+	case strings.HasSuffix(p.Symbol, asset):
+		p.TakerFees += fee
+	default:
+		if p.OtherFees == nil {
+			p.OtherFees = make(map[string]float64)
+			p.OtherMaker = make(map[string]float64)
+		}
+		p.OtherFees[asset] += fee
+		if maker {
+			p.OtherMaker[asset] += fee
+		}
+	}
+}
+
+// positionSettleDelay is how long to wait after a position closes before storing the trade,
+// since the closing ORDER_TRADE_UPDATE may arrive after the ACCOUNT_UPDATE.
+const positionSettleDelay = 2 * time.Second
+
+// recordPositionUpdate reports whether a tracked position has just been fully closed.
+func (b *BinanceClient) recordPositionUpdate(wsPosition futures.WsPosition) bool {
+	amount, err := strconv.ParseFloat(wsPosition.Amount, 64)
+	if err != nil {
+		return false
+	}
+
+	positionTracker.Lock()
+	defer positionTracker.Unlock()
+
+	position, exists := positionTracker.positions[b.key(wsPosition.Symbol)]
+	if !exists {
+		return false
+	}
+	if amount != 0 {
+		if !position.Opened {
+			position.OpenedAt = time.Now()
+		}
+		position.Opened = true
+		return false
+	}
+	if !position.Opened {
+		return false
+	}
+	// Clear the flag so repeated updates don't finalize the position twice
+	position.Opened = false
+	return true
+}
+
+// finalizePosition stops tracking a closed position and stores its trade result with fees.
+func (b *BinanceClient) finalizePosition(symbol string) (*TrackedPosition, error) {
+	positionTracker.Lock()
+	position, exists := positionTracker.positions[b.key(symbol)]
+	if !exists {
+		positionTracker.Unlock()
+		return nil, fmt.Errorf("no tracked position for %s", symbol)
+	}
+	delete(positionTracker.positions, b.key(symbol))
+	positionTracker.Unlock()
+
+	b.convertOtherFees(position)
+	position.InitialRisk = tradeInitialRisk(position.SignalID, position.AverageEntryPrice(), position.EntryQty)
+
+	trade := &Trade{
+		SignalID:    position.SignalID,
+		Symbol:      position.Symbol,
+		Side:        string(position.Side),
+		Strategy:    tradeStrategy(position.SignalID),
+		EntryPrice:  position.AverageEntryPrice(),
+		ExitPrice:   position.AverageExitPrice(),
+		GrossProfit: position.RealizedPnL,
+		MakerFees:   position.MakerFees,
+		TakerFees:   position.TakerFees,
+		InitialRisk: position.InitialRisk,
+		OpenedAt:    position.OpenedAt,
+	}
+	if err := StoreTrade(trade); err != nil {
+		return position, err
+	}
+	fireWebhookEvent(EventPositionClosed, trade)
+	for _, signalID := range append([]string{position.SignalID}, position.Added...) {
+		if err := SetSignalStatus(signalID, SignalClosed); err != nil {
+			binanceLog.Error("Failed to set signal status", "signal_id", signalID, "symbol", position.Symbol, "status", SignalClosed, "error", err)
+		}
+	}
+	return position, nil
+}
+
+// convertOtherFees adds fees paid in other assets to the quote fees at the current asset price.
+// Fees that cannot be priced are logged and left out.
+func (b *BinanceClient) convertOtherFees(position *TrackedPosition) {
+	quote := "USDT"
+	if strings.HasSuffix(position.Symbol, "USDC") {
+		quote = "USDC"
+	}
+	for asset, fee := range position.OtherFees {
+		price, err := b.getCurrentPrice(context.Background(), asset+quote)
+		if err != nil {
+			binanceLog.Warn("Ignoring commission in PnL", "signal_id", position.SignalID, "symbol", position.Symbol,
+				"asset", asset, "commission", fee, "error", err)
+			continue
+		}
+		maker := position.OtherMaker[asset]
+		position.MakerFees += maker * price
+		position.TakerFees += (fee - maker) * price
+	}
+	position.OtherFees, position.OtherMaker = nil, nil
+}
+
+// formatClosedPosition builds the Telegram notification for a closed position, with the net
+// profit in R when its initial risk is known.
+func formatClosedPosition(chatID int64, position *TrackedPosition) string {
+	text := tr(chatID,
+		"Position closed for %s.\nEntry: %s\nExit: %s\nRealized PnL: %.4f\nFees: %.4f (maker %.4f, taker %.4f)\nNet Profit: %.4f",
+		position.Symbol,
+		formatFloat(position.AverageEntryPrice()),
+		formatFloat(position.AverageExitPrice()),
+		position.RealizedPnL,
+		position.Fees(),
+		position.MakerFees,
+		position.TakerFees,
+		position.RealizedPnL-position.Fees(),
+	)
+	if position.InitialRisk > 0 {
+		text += tr(chatID, "\nResult: %+.2fR (risk %.4f)", (position.RealizedPnL-position.Fees())/position.InitialRisk, position.InitialRisk)
+	}
+	return text
+}
+
+// formatOrderFill describes a filled TP, SL or trailing stop order: the level hit, the quantity
+// it filled and the share of the position that was, and the realized PnL and size left on the position when
+// it is tracked for the order's signal. It returns false for other orders.
+func formatOrderFill(chatID int64, key positionKey, clientOrderID string, price, quantity float64) (string, bool) {
+	symbol := key.Symbol
+	_, tag, ok := parseClientOrderID(clientOrderID)
+	if !ok || (!strings.HasPrefix(tag, "tp") && tag != OrderTagSL && tag != OrderTagTrail) {
+		return "", false
+	}
+
+	text := tr(chatID, "%s hit for %s.\nFilled: %s at %s", strings.ToUpper(tag), symbol,
+		formatFloat(roundToSixDecimal(quantity)), formatFloat(roundToSixDecimal(price)))
+
+	positionTracker.RLock()
+	defer positionTracker.RUnlock()
+	position, exists := positionTracker.positions[key]
+	if !exists || !position.ownsOrder(clientOrderID) {
+		return text, true
+	}
+	// Positions restored after a restart have no entry fills to measure against
+	if position.EntryQty > 0 {
+		text += tr(chatID, " (%.0f%% of the position)", quantity/position.EntryQty*100)
+	}
+	text += tr(chatID, "\nRealized PnL so far: %.4f (fees %.4f)", position.RealizedPnL, position.Fees())
+	if position.EntryQty > 0 {
+		if remaining := position.EntryQty - position.ExitQty; remaining > 0 {
+			text += tr(chatID, "\nRemaining: %s of %s", formatFloat(roundToSixDecimal(remaining)), formatFloat(roundToSixDecimal(position.EntryQty)))
+		} else {
+			text += tr(chatID, "\nRemaining: none, the position is closed")
+		}
+	}
+	return text, true
+}
+
+// reconcileOpenPositions rebuilds position tracking after a restart. It matches open orders and
+// positions on Binance to signals via their client order IDs, follows up restored stop entries
+// and resumes the order monitor.
+// Positions already tracked for their signal, as when the client was replaced by a new
+// configuration, keep their tracking.
+func (b *BinanceClient) reconcileOpenPositions(userID int64) error {
+	orders, err := b.Client.NewListOpenOrdersService().Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list open orders: %v", err)
+	}
+	positions, err := b.Client.NewGetPositionRiskService().Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %v", err)
+	}
+
+	// Map each symbol to the signal that owns its open orders, note pending entries and relink TP/SL pairs
+	signalBySymbol := make(map[string]string)
+	pendingEntries := make(map[string]*futures.Order)
+	openOrders := make(map[string]bool)
+	for _, order := range orders {
+		openOrders[order.ClientOrderID] = true
+		signalID, tag, ok := orderSignalID(order.ClientOrderID)
+		if !ok {
+			continue
+		}
+		signalBySymbol[order.Symbol] = signalID
+		switch tag {
+		case OrderTagEntry:
+			pendingEntries[order.Symbol] = order
+		case OrderTagSL:
+			ocoGroups.Add(b.key(order.Symbol), signalID, order.ClientOrderID, true)
+		case OrderTagTrail:
+			ocoGroups.Add(b.key(order.Symbol), signalID, order.ClientOrderID, false)
+		default:
+			// TPs placed as reduce-only orders close only part of the position
+			if isTPOrderTag(tag) && order.ReduceOnly && !order.ClosePosition {
+				ocoGroups.AddPartial(b.key(order.Symbol), signalID, order.ClientOrderID)
+			} else if isTPOrderTag(tag) {
+				ocoGroups.Add(b.key(order.Symbol), signalID, order.ClientOrderID, false)
+			}
+		}
+	}
+
+	restored, followed := 0, 0
+	openPositions := make(map[string]*futures.PositionRisk)
+	for _, position := range positions {
+		amount, err := strconv.ParseFloat(position.PositionAmt, 64)
+		if err != nil || amount == 0 {
+			continue
+		}
+		openPositions[position.Symbol] = position
+		signalID, ok := signalBySymbol[position.Symbol]
+		if !ok {
+			continue
+		}
+		if tracked, exists := positionTracker.Get(b.key(position.Symbol)); exists && tracked.hasSignal(signalID) {
+			delete(pendingEntries, position.Symbol)
+			followed++
+			continue
+		}
+		if _, err := GetSignal(signalID); err != nil {
+			binanceLog.Warn("Reconciling position without a stored signal", "signal_id", signalID, "symbol", position.Symbol, "error", err)
+		}
+
+		side := futures.SideTypeBuy
+		if amount < 0 {
+			side = futures.SideTypeSell
+		}
+		entry, _ := strconv.ParseFloat(position.EntryPrice, 64)
+		positionTracker.Set(b.key(position.Symbol), &TrackedPosition{
+			SignalID:   signalID,
+			Symbol:     position.Symbol,
+			Side:       side,
+			EntryPrice: entry,
+			Opened:     true,
+		})
+		delete(pendingEntries, position.Symbol)
+		restored++
+	}
+
+	// Limit entries that haven't filled yet are tracked so their fills are recorded
+	for symbol, order := range pendingEntries {
+		if tracked, exists := positionTracker.Get(b.key(symbol)); exists && tracked.hasSignal(signalBySymbol[symbol]) {
+			followed++
+			continue
+		}
+		entry, _ := strconv.ParseFloat(order.Price, 64)
+		positionTracker.Set(b.key(symbol), &TrackedPosition{
+			SignalID:   signalBySymbol[symbol],
+			Symbol:     symbol,
+			Side:       order.Side,
+			EntryPrice: entry,
+		})
+		restored++
+	}
+
+	// Stop entries restored from before the restart filled or went away while the bot was down
+	restored += b.reconcileStopEntries(openOrders, openPositions)
+
+	if restored+followed == 0 {
+		binanceLog.Info("Reconciliation found no open positions or orders from previous signals")
+		return nil
+	}
+
+	b.startOrderMonitor(userID)
+	if restored == 0 {
+		return nil
+	}
+	b.sendMessageToUser(userID, tr(userID, "Restored tracking for %d open position(s) or pending order(s) after restart.", restored))
+	return nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionPreview is the callback action for the dry-run order preview on signal messages.
+const ActionPreview = "preview"
+
+// previewSignal shows the exact orders a confirmation by userID would place, validated by Binance's test endpoint.
+func previewSignal(chatID, userID int64, signalID string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+
+	settings := applySignalOverrides(signal, applySymbolOverride(chatID, signal.Symbol, signalSettings(chatID, signal)))
+	routed := *signal // tradingClient records the account on the signal, which must not change the stored one
+	client, err := tradingClient(userID, &routed)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Account %s is unavailable: %v", routed.Account, err)))
+		return
+	}
+
+	// Apply the same TP filtering and recalculation that ExecuteTrade will
+	preview := filterEnabledTPs(signal, settings)
+	if settings.AutoCalculateTPs {
+		recalcSingleTPAndSL(preview, settings)
+	} else {
+		recalcManualTPAndSL(preview, settings)
+	}
+
+	text, err := client.previewTrade(context.Background(), preview, settings)
+	if err != nil {
+		binanceLog.Error("Failed to build preview", "signal_id", signalID, "symbol", signal.Symbol, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to build preview for %s: %v", signal.Symbol, err)))
+		return
+	}
+	text = tr(chatID, "<b>Account:</b> %s\n", routed.Account) + text
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send preview", "signal_id", signalID, "symbol", signal.Symbol, "chat_id", chatID, "error", err)
+	}
+}
+
+// orderEstimate is the entry order ExecuteTrade would place for a signal, sized and rounded
+// the same way, with the leverage clamped to the notional's bracket.
+type orderEstimate struct {
+	Side        futures.SideType
+	Info        *futures.Symbol
+	Quantity    string  // Rounded order quantity
+	Price       string  // Rounded limit or stop price, empty for market entries
+	EntryPrice  float64 // Limit or stop price, or the mark price for market entries
+	Notional    float64
+	Leverage    int
+	Liquidation float64 // Estimated isolated liquidation price, 0 if unavailable
+}
+
+// Margin returns the initial margin the entry would use.
+func (e *orderEstimate) Margin() float64 {
+	return e.Notional / float64(e.Leverage)
+}
+
+// estimateOrder sizes the entry order for the signal without placing it.
+func (b *BinanceClient) estimateOrder(ctx context.Context, signal *AlertMessage, settings *UserSettings) (*orderEstimate, error) {
+	if settings.MarketType == MarketTypeCoinM {
+		return nil, fmt.Errorf("order estimates are only available for USDT-M futures")
+	}
+
+	symbol := signal.Symbol
+	estimate := &orderEstimate{Side: futures.SideTypeBuy}
+	if signal.SignalType == "Sell" {
+		estimate.Side = futures.SideTypeSell
+	}
+
+	info, err := b.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	estimate.Info = info
+	entryPrice := entryOrderPrice(signal)
+	estimate.Quantity, err = b.calculateQuantity(ctx, symbol, settings.AmountUSDT, entryPrice)
+	if err != nil {
+		return nil, err
+	}
+	qty, _ := strconv.ParseFloat(estimate.Quantity, 64)
+
+	// Market orders fill near the mark price; limit and stop orders at the rounded entry
+	if settings.TradingMode == "Limit" || settings.TradingMode == TradingModeStop {
+		estimate.Price, err = b.formatPrice(info, entryPrice)
+		if err != nil {
+			return nil, err
+		}
+		estimate.EntryPrice, _ = strconv.ParseFloat(estimate.Price, 64)
+	} else {
+		estimate.EntryPrice, err = b.getMarkPrice(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Use the leverage that will actually be set after bracket clamping
+	estimate.Notional = qty * estimate.EntryPrice
+	estimate.Leverage = settings.Leverage
+	bracket, err := b.leverageBracketForNotional(ctx, symbol, estimate.Notional)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leverage brackets: %v", err)
+	}
+	if estimate.Leverage > bracket.InitialLeverage {
+		estimate.Leverage = bracket.InitialLeverage
+	}
+	estimate.Liquidation = estimateLiquidationPrice(estimate.Side, estimate.EntryPrice, qty, estimate.Leverage, bracket)
+	return estimate, nil
+}
+
+// previewTrade describes the orders ExecuteTrade would place for the signal without placing them.
+// Quantities and prices are rounded exactly as they would be, and the entry order is checked
+// against Binance's test order endpoint.
+func (b *BinanceClient) previewTrade(ctx context.Context, signal *AlertMessage, settings *UserSettings) (string, error) {
+	estimate, err := b.estimateOrder(ctx, signal, settings)
+	if err != nil {
+		return "", err
+	}
+	symbol, side, info := signal.Symbol, estimate.Side, estimate.Info
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(side))
+	params.Set("quantity", estimate.Quantity)
+	var entryText string
+	if settings.TradingMode == "Limit" {
+		entryText = fmt.Sprintf("LIMIT %s %s @ %s (GTC)", side, estimate.Quantity, estimate.Price)
+		params.Set("type", string(futures.OrderTypeLimit))
+		params.Set("timeInForce", string(futures.TimeInForceTypeGTC))
+		params.Set("price", estimate.Price)
+	} else if settings.TradingMode == TradingModeStop {
+		entryText = fmt.Sprintf("STOP_MARKET %s %s @ %s (%s)", side, estimate.Quantity, estimate.Price, workingType(settings))
+		params.Set("type", string(futures.OrderTypeStopMarket))
+		params.Set("stopPrice", estimate.Price)
+		params.Set("workingType", string(workingType(settings)))
+		if limitPrice := stopLimitPrice(side, estimate.EntryPrice, settings.StopLimitOffset); limitPrice > 0 {
+			price, err := b.formatPrice(info, limitPrice)
+			if err != nil {
+				return "", err
+			}
+			entryText = fmt.Sprintf("STOP %s %s @ %s, limit %s (GTC, %s)", side, estimate.Quantity, estimate.Price, price, workingType(settings))
+			params.Set("type", string(futures.OrderTypeStop))
+			params.Set("timeInForce", string(futures.TimeInForceTypeGTC))
+			params.Set("price", price)
+		}
+	} else {
+		entryText = fmt.Sprintf("MARKET %s %s (mark %s)", side, estimate.Quantity, formatFloat(estimate.EntryPrice))
+		params.Set("type", string(futures.OrderTypeMarket))
+	}
+
+	chatID := signal.ChatID
+	text := tr(chatID, "\U0001F50D <b>Order Preview for %s</b>\n\n", symbol)
+	text += tr(chatID, "<b>Entry:</b> %s\n", entryText)
+	if useIceberg(settings) {
+		step, err := lotStepSize(info)
+		if err != nil {
+			return "", err
+		}
+		qty, _ := strconv.ParseFloat(estimate.Quantity, 64)
+		slices := icebergSlices(qty, step, settings.AmountUSDT, settings.IcebergNotional)
+		text += tr(chatID, "<b>Iceberg:</b> %d orders of about %s, %d-%d seconds apart\n", len(slices), formatDecimal(slices[0], step),
+			int(icebergMinDelay.Seconds()), int(icebergMaxDelay.Seconds()))
+	}
+	text += tr(chatID, "<b>Margin:</b> %s %dx\n", settings.MarginMode, estimate.Leverage)
+	text += tr(chatID, "<b>Notional:</b> %.2f USDT\n", estimate.Notional)
+	text += tr(chatID, "<b>Estimated Margin:</b> %.2f USDT\n", estimate.Margin())
+	if estimate.Liquidation > 0 {
+		text += tr(chatID, "<b>Est. Liquidation Price:</b> %s (isolated)\n", formatFloat(estimate.Liquidation))
+	}
+
+	// TP/SL are only placed right away for market entries
+	if settings.TradingMode == "Market" {
+		closeSide := invertSide(side)
+		tps := placedTPs(signal.TPs, settings)
+		partials, err := splitTPQuantities(ctx, b, symbol, estimate.Quantity, tps, settings)
+		if err != nil {
+			return "", err
+		}
+		for i, tp := range tps {
+			if tp <= 0 {
+				continue
+			}
+			price, err := b.formatPrice(info, tp)
+			if err != nil {
+				return "", err
+			}
+			if i < len(partials) && partials[i] != "" {
+				text += fmt.Sprintf("<b>TP%d:</b> TAKE_PROFIT_MARKET %s %s @ %s (reduce only, %s)\n", i+1, closeSide, partials[i], price, workingType(settings))
+				continue
+			}
+			text += fmt.Sprintf("<b>TP%d:</b> TAKE_PROFIT_MARKET %s @ %s (close position, %s)\n", i+1, closeSide, price, workingType(settings))
+		}
+		if settings.UseSL && signal.SL > 0 {
+			price, err := b.formatPrice(info, signal.SL)
+			if err != nil {
+				return "", err
+			}
+			text += fmt.Sprintf("<b>SL:</b> STOP_MARKET %s @ %s (close position, %s)\n", closeSide, price, workingType(settings))
+		}
+		if partials != nil && useTrailingTP(settings, tps) {
+			text += tr(chatID, "Once TP2 fills, the later TPs are replaced by a trailing stop for the rest of the position.\n")
+		}
+	} else if settings.TradingMode == TradingModeStop {
+		text += tr(chatID, "TP/SL orders are placed once the stop entry fills.\n")
+	} else {
+		text += tr(chatID, "TP/SL orders are not placed for limit entries.\n")
+	}
+
+	if err := b.testOrder(params); err != nil {
+		text += tr(chatID, "\n❌ Binance rejected the test order: %v", err)
+	} else {
+		text += tr(chatID, "\n✅ Binance accepted the test order.")
+	}
+	return text, nil
+}
+
+// testOrder validates an order with POST /fapi/v1/order/test, which the client library does not wrap.
+func (b *BinanceClient) testOrder(params url.Values) error {
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()-b.Client.TimeOffset, 10))
+	body := params.Encode()
+
+	sign, err := common.SignFunc(common.KeyTypeHmac)
+	if err != nil {
+		return err
+	}
+	signature, err := sign(b.Client.SecretKey, body)
+	if err != nil {
+		return err
+	}
+	body += "&signature=" + url.QueryEscape(*signature)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.Client.BaseURL+"/fapi/v1/order/test", strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-MBX-APIKEY", b.Client.APIKey)
+
+	res, err := b.Client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(res.Body)
+		apiErr := new(common.APIError)
+		if err := json.Unmarshal(data, apiErr); err != nil || !apiErr.IsValid() {
+			return fmt.Errorf("unexpected status %d: %s", res.StatusCode, string(data))
+		}
+		return apiErr
+	}
+	return nil
+}
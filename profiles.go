@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// ActionProfile is the callback action for settings profiles.
+const ActionProfile = "prof"
+
+// maxProfileNameLength keeps profile names short enough for callback data.
+const maxProfileNameLength = 16
+
+// SettingsProfile is a named snapshot of a user's settings, e.g. "scalp" or "swing".
+type SettingsProfile struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   int64  `gorm:"uniqueIndex:idx_profile_user_name"`
+	Name     string `gorm:"uniqueIndex:idx_profile_user_name;size:64"`
+	Settings string // UserSettings as JSON
+}
+
+// GetSettingsProfile retrieves a user's profile by name, or nil if it does not exist.
+func GetSettingsProfile(userID int64, name string) (*SettingsProfile, error) {
+	var profile SettingsProfile
+	err := db.Where("user_id = ? AND name = ?", userID, name).First(&profile).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve settings profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// ListSettingsProfiles retrieves all profiles for a user ordered by name.
+func ListSettingsProfiles(userID int64) ([]SettingsProfile, error) {
+	var profiles []SettingsProfile
+	if err := db.Where("user_id = ?", userID).Order("name").Find(&profiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve settings profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// SaveSettingsProfile stores settings under a profile name, replacing any existing profile.
+func SaveSettingsProfile(userID int64, name string, settings *UserSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	profile, err := GetSettingsProfile(userID, name)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		profile = &SettingsProfile{UserID: userID, Name: name}
+	}
+	profile.Settings = string(data)
+	if err := db.Save(profile).Error; err != nil {
+		return fmt.Errorf("failed to save settings profile: %w", err)
+	}
+	return nil
+}
+
+// DeleteSettingsProfile removes a user's profile.
+func DeleteSettingsProfile(userID int64, name string) error {
+	if err := db.Where("user_id = ? AND name = ?", userID, name).Delete(&SettingsProfile{}).Error; err != nil {
+		return fmt.Errorf("failed to delete settings profile: %w", err)
+	}
+	return nil
+}
+
+// profileSettings decodes a user's profile. Settings added since the profile was saved
+// keep their defaults.
+func profileSettings(userID int64, name string) (*UserSettings, error) {
+	profile, err := GetSettingsProfile(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("profile %q does not exist", name)
+	}
+
+	settings := *NewUserSettingsStore().Get(userID)
+	if err := json.Unmarshal([]byte(profile.Settings), &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings profile: %w", err)
+	}
+	if levels := legacyTPLevels([]byte(profile.Settings)); levels != nil {
+		settings.TPLevels = levels
+	}
+	adjustTPClosePercentages(&settings)
+	return &settings, nil
+}
+
+// signalSettings returns the settings a signal will be executed with: the profile picked
+// on the signal if any, otherwise the chat's current settings.
+func signalSettings(chatID int64, signal *AlertMessage) *UserSettings {
+	if signal.Profile != "" {
+		settings, err := profileSettings(chatID, signal.Profile)
+		if err == nil {
+			return settings
+		}
+		telegramLog.Error("Failed to load profile, using current settings", "profile", signal.Profile, "error", err)
+	}
+	return userSettings.Get(chatID)
+}
+
+// handleProfileCallback dispatches settings profile callbacks.
+// Expected data: "prof|list", "prof|save", "prof|load|Name", "prof|del|Name",
+// "prof|pick|SIGNALID" or "prof|use|SIGNALID|Name" (an empty name resets to current settings).
+func handleProfileCallback(chatID int64, messageID int, parts []string) {
+	command := parts[0]
+	var arg, value string
+	if len(parts) > 1 {
+		arg = parts[1]
+	}
+	if len(parts) > 2 {
+		value = parts[2]
+	}
+
+	switch command {
+	case "list":
+		showSettingsProfiles(chatID)
+	case "save":
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter a name for a profile with your current settings (e.g., scalp). An existing profile with that name is replaced."))
+		sent, err := bot.Send(msg)
+		if err != nil {
+			telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+		}
+		trackMessage(sent, messageKindPrompt)
+		editingUsers.Set(chatID, &EditingState{SettingName: "ProfileName"})
+	case "load":
+		settings, err := profileSettings(chatID, arg)
+		if err != nil {
+			telegramLog.Error("Failed to load profile", "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load profile %s.", arg)))
+			return
+		}
+		userSettings.Set(chatID, settings)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Profile %s is now your current settings.", arg)))
+		showSettingsMenu(chatID)
+	case "del":
+		if err := DeleteSettingsProfile(chatID, arg); err != nil {
+			telegramLog.Error("Failed to delete profile", "chat_id", chatID, "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to delete settings profile.")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Profile %s has been removed.", arg)))
+		showSettingsProfiles(chatID)
+	case "pick":
+		showSignalProfileOptions(chatID, messageID, arg)
+	case "use":
+		useSignalProfile(chatID, messageID, arg, value)
+	default:
+		telegramLog.Warn("Unknown profile command", "command", command)
+	}
+}
+
+// showSettingsProfiles lists the user's profiles with buttons to apply, delete or save one.
+func showSettingsProfiles(chatID int64) {
+	profiles, err := ListSettingsProfiles(chatID)
+	if err != nil {
+		telegramLog.Error("Failed to list profiles", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load settings profiles.")))
+		return
+	}
+
+	text := tr(chatID, "<b>Settings Profiles</b>\n\n")
+	if len(profiles) == 0 {
+		text += tr(chatID, "No profiles yet. Save your current settings as a profile to pick it on signals.")
+	} else {
+		text += tr(chatID, "Apply a profile as your current settings, or pick one on a signal to use it for that trade only.")
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, p := range profiles {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Apply %s", p.Name), fmt.Sprintf("%s|load|%s", ActionProfile, p.Name)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Delete"), fmt.Sprintf("%s|del|%s", ActionProfile, p.Name)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Save Current Settings"), fmt.Sprintf("%s|save", ActionProfile)),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send profiles menu", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindMenu)
+}
+
+// handleNewProfileName saves the current settings under the typed profile name.
+func handleNewProfileName(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	name := sanitizeSignalID(strings.TrimSpace(message.Text))
+	if name == "" || len(name) > maxProfileNameLength {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid profile name. Use up to %d letters, digits or underscores.", maxProfileNameLength)))
+		return
+	}
+
+	if err := SaveSettingsProfile(chatID, name, userSettings.Get(chatID)); err != nil {
+		telegramLog.Error("Failed to save profile", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to save settings profile.")))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Profile %s has been saved.", name)))
+	showSettingsProfiles(chatID)
+}
+
+// showSignalProfileOptions replaces a signal's keyboard with its profile choices.
+func showSignalProfileOptions(chatID int64, messageID int, signalID string) {
+	profiles, err := ListSettingsProfiles(chatID)
+	if err != nil {
+		telegramLog.Error("Failed to list profiles", "signal_id", signalID, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load settings profiles.")))
+		return
+	}
+	if len(profiles) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "You have no settings profiles yet. Use /profiles to save one.")))
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, p := range profiles {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.Name, fmt.Sprintf("%s|use|%s|%s", ActionProfile, signalID, p.Name)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Current Settings"), fmt.Sprintf("%s|use|%s|", ActionProfile, signalID)),
+	))
+
+	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard})
+	if _, err := bot.Request(editMessage); err != nil {
+		telegramLog.Error("Failed to send profile options", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
+
+// useSignalProfile picks the profile a signal will be executed with and refreshes the message.
+func useSignalProfile(chatID int64, messageID int, signalID, name string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+
+	signal.Profile = name
+	settings := signalSettings(chatID, signal)
+	originalEntry := signal.EntryPrice
+	recalculateTPAndSL(signal, settings)
+	if signal.ManualEntryEdited {
+		// Keep the manually edited entry but update TPs/SL
+		signal.EntryPrice = originalEntry
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, constructSignalMessageText(signal))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = createSignalInlineKeyboard(chatID, signalID)
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
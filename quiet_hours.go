@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionQuiet is the callback action for opening signals listed in a quiet-hours digest.
+const ActionQuiet = "quiet"
+
+// How signal notifications are handled while muted or during quiet hours.
+const (
+	QuietModeSilent   = "silent"   // Sent without a notification sound
+	QuietModeSuppress = "suppress" // Not sent
+	QuietModeDigest   = "digest"   // Collected into one message when the quiet period ends
+)
+
+// quietModes lists the quiet modes in the order the settings button cycles through them.
+var quietModes = []string{QuietModeSilent, QuietModeDigest, QuietModeSuppress}
+
+// digestCheckInterval is how often held signals are checked for a quiet period that has ended.
+const digestCheckInterval = time.Minute
+
+// maxDigestButtons limits the signals in a digest that get a Show button.
+const maxDigestButtons = 10
+
+// DigestQueue holds the signals received during each chat's quiet period, in arrival order.
+type DigestQueue struct {
+	sync.Mutex
+	signals map[int64][]string
+}
+
+// NewDigestQueue creates a new instance of DigestQueue.
+func NewDigestQueue() *DigestQueue {
+	return &DigestQueue{
+		signals: make(map[int64][]string),
+	}
+}
+
+func (q *DigestQueue) Add(chatID int64, signalID string) {
+	q.Lock()
+	defer q.Unlock()
+	q.signals[chatID] = append(q.signals[chatID], signalID)
+}
+
+// Chats returns the chats with held signals.
+func (q *DigestQueue) Chats() []int64 {
+	q.Lock()
+	defer q.Unlock()
+	chats := make([]int64, 0, len(q.signals))
+	for chatID := range q.signals {
+		chats = append(chats, chatID)
+	}
+	return chats
+}
+
+// Take removes and returns a chat's held signals.
+func (q *DigestQueue) Take(chatID int64) []string {
+	q.Lock()
+	defer q.Unlock()
+	signals := q.signals[chatID]
+	delete(q.signals, chatID)
+	return signals
+}
+
+var (
+	digestQueue     = NewDigestQueue()
+	digestLoopStart sync.Once
+)
+
+// parseQuietHours parses a "22-7" style range of whole hours in the user's timezone.
+func parseQuietHours(text string) (start, end int, err error) {
+	parts := strings.Split(strings.ReplaceAll(text, " ", ""), "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected START-END, e.g. 22-7")
+	}
+	hours := make([]int, 2)
+	for i, part := range parts {
+		part = strings.TrimSuffix(part, ":00")
+		hour, err := strconv.Atoi(part)
+		if err != nil || hour < 0 || hour > 23 {
+			return 0, 0, fmt.Errorf("hours must be whole numbers from 0 to 23")
+		}
+		hours[i] = hour
+	}
+	if hours[0] == hours[1] {
+		return 0, 0, fmt.Errorf("start and end must differ")
+	}
+	return hours[0], hours[1], nil
+}
+
+// inQuietHours reports whether t falls in the settings' quiet hours, which may wrap past midnight.
+func inQuietHours(settings *UserSettings, t time.Time) bool {
+	start, end, err := parseQuietHours(settings.QuietHours)
+	if settings.QuietHours == "" || err != nil {
+		return false
+	}
+	hour := t.Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// quietMode returns how a signal notification to the chat is handled right now,
+// or "" if the chat is neither muted nor in its quiet hours.
+func quietMode(chatID int64) string {
+	settings := userSettings.Get(chatID)
+	now := time.Now()
+	if !now.Before(settings.MutedUntil) && !inQuietHours(settings, now.In(userLocation(chatID))) {
+		return ""
+	}
+	if settings.QuietMode == "" {
+		return QuietModeSilent
+	}
+	return settings.QuietMode
+}
+
+// holdSignal keeps a signal from being sent during a quiet period, queueing it for the digest if
+// the chat asked for one. It reports whether the signal was held.
+func holdSignal(chatID int64, signalID, mode string) bool {
+	switch mode {
+	case QuietModeDigest:
+		digestQueue.Add(chatID, signalID)
+		startDigestLoop()
+		return true
+	case QuietModeSuppress:
+		telegramLog.Info("Signal not sent during quiet hours", "signal_id", signalID, "chat_id", chatID)
+		return true
+	}
+	return false
+}
+
+// startDigestLoop starts sending digests once quiet periods end, unless it is already running.
+func startDigestLoop() {
+	digestLoopStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(digestCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				for _, chatID := range digestQueue.Chats() {
+					if quietMode(chatID) == "" {
+						sendDigest(chatID, digestQueue.Take(chatID))
+					}
+				}
+			}
+		}()
+	})
+}
+
+// sendDigest lists the signals held during the chat's quiet period, with buttons to open them.
+func sendDigest(chatID int64, signalIDs []string) {
+	if len(signalIDs) == 0 {
+		return
+	}
+
+	text := tr(chatID, "<b>Signals received during quiet hours: %d</b>\n\n", len(signalIDs))
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, signalID := range signalIDs {
+		signal, exists := signalStore.Get(signalID)
+		if !exists {
+			continue
+		}
+		text += fmt.Sprintf("%s %s @ %s | %s\n", tr(chatID, signal.SignalType), signal.Symbol,
+			formatFloat(signal.EntryPrice), formatSignalTime(chatID, signal.Time))
+		if len(keyboard) < maxDigestButtons && !signal.Confirmed && !signal.Dismissed {
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Show %s %s", signal.Symbol, tr(chatID, signal.SignalType)),
+					fmt.Sprintf("%s|show|%s", ActionQuiet, signalID)),
+			))
+		}
+	}
+	text += tr(chatID, "\nPrices may have moved since these signals arrived.")
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if len(keyboard) > 0 {
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send digest", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleQuietCallback handles "quiet|show|ID" from digest buttons by sending the held signal
+// with its regular keyboard.
+func handleQuietCallback(chatID int64, parts []string) {
+	if len(parts) < 2 || parts[0] != "show" {
+		telegramLog.Warn("Invalid quiet callback data", "data", parts)
+		return
+	}
+	showStoredSignal(chatID, parts[1])
+}
+
+// handleMuteCommand mutes signal notifications with "/mute 2h" (or 30m, 1d) and unmutes with "/mute off".
+func handleMuteCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	settings := userSettings.Get(chatID)
+
+	switch arg {
+	case "":
+		status := tr(chatID, "Notifications are not muted.")
+		if time.Now().Before(settings.MutedUntil) {
+			status = tr(chatID, "Notifications are muted until %s.", formatUserTime(chatID, settings.MutedUntil))
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, status+"\n"+tr(chatID, "Usage: /mute <30m|2h|1d> or /mute off")))
+		return
+	case "off":
+		settings.MutedUntil = time.Time{}
+		userSettings.Set(chatID, settings)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Notifications have been unmuted.")))
+		return
+	}
+
+	duration, err := parseMuteDuration(arg)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /mute <30m|2h|1d> or /mute off")))
+		return
+	}
+	settings.MutedUntil = time.Now().Add(duration)
+	userSettings.Set(chatID, settings)
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Notifications are muted until %s. Signals are handled as set in Quiet Mode (%s).",
+		formatUserTime(chatID, settings.MutedUntil), tr(chatID, settings.QuietMode))))
+}
+
+// parseMuteDuration parses a Go duration such as "90m" or "2h", or a number of days such as "1d".
+func parseMuteDuration(text string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid number of days: %s", text)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", text)
+	}
+	return duration, nil
+}
+
+// promptQuietHours asks the user for their quiet hours.
+func promptQuietHours(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter your quiet hours as START-END in your timezone (e.g., 22-7), or \"off\"."))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	editingUsers.Set(chatID, &EditingState{SettingName: "QuietHours"})
+}
+
+// cycleQuietMode switches to the next quiet mode.
+func cycleQuietMode(chatID int64) {
+	settings := userSettings.Get(chatID)
+	next := quietModes[0]
+	for i, mode := range quietModes {
+		if mode == settings.QuietMode {
+			next = quietModes[(i+1)%len(quietModes)]
+		}
+	}
+	settings.QuietMode = next
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Quiet Mode has been set to %s.", tr(chatID, next)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
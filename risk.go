@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// tradeInitialRisk returns what a position stood to lose at its SL when it opened: the distance
+// from the entry price to the SL order the bot placed for its signal, times the entry quantity.
+// It is 0 for positions without an SL order, such as ones opened by hand.
+func tradeInitialRisk(signalID string, entryPrice, quantity float64) float64 {
+	if signalID == "" || quantity <= 0 {
+		return 0
+	}
+	var orders []Order
+	if err := db.Where("signal_id = ? AND tag = ? AND stop_price > 0", signalID, OrderTagSL).Order("id").Limit(1).Find(&orders).Error; err != nil || len(orders) == 0 {
+		return 0
+	}
+	return math.Abs(entryPrice-orders[0].StopPrice) * quantity
+}
+
+// RMultiple returns the trade's net profit in units of its initial risk, and false if the risk
+// is unknown.
+func (t *Trade) RMultiple() (float64, bool) {
+	if t.InitialRisk <= 0 {
+		return 0, false
+	}
+	return t.Profit / t.InitialRisk, true
+}
+
+// RMultipleText renders the trade's R-multiple, e.g. "+1.50R", or "" if its risk is unknown.
+func (t *Trade) RMultipleText() string {
+	r, ok := t.RMultiple()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%+.2fR", r)
+}
+
+// formatGroupResult renders the trades, win rate and net profit of a group of trades on a line,
+// with the net profit in R as well when any of the trades has a known risk.
+func formatGroupResult(chatID int64, data PerformanceData) string {
+	text := tr(chatID, "%d trades, %.0f%% won, net %.2f", data.TotalTrades, data.WinLossRatio*100, data.NetProfit)
+	if data.RTrades > 0 {
+		text += fmt.Sprintf(" (%+.2fR)", data.TotalR)
+	}
+	return text + "\n"
+}
+
+// riskRatios returns per-trade approximations of the Sharpe and Sortino ratios of the trades'
+// net profits: the mean profit over their standard deviation, and over their downside
+// deviation. They are not annualized, and are 0 with fewer than two trades or no variation.
+func riskRatios(trades []Trade) (sharpe, sortino float64) {
+	if len(trades) < 2 {
+		return 0, 0
+	}
+	var mean float64
+	for _, trade := range trades {
+		mean += trade.Profit
+	}
+	mean /= float64(len(trades))
+
+	var variance, downside float64
+	for _, trade := range trades {
+		variance += (trade.Profit - mean) * (trade.Profit - mean)
+		if trade.Profit < 0 {
+			downside += trade.Profit * trade.Profit
+		}
+	}
+	if deviation := math.Sqrt(variance / float64(len(trades)-1)); deviation > 0 {
+		sharpe = mean / deviation
+	}
+	if deviation := math.Sqrt(downside / float64(len(trades))); deviation > 0 {
+		sortino = mean / deviation
+	}
+	return sharpe, sortino
+}
+
+// longestLosingStreak returns the most trades in a row, in the order they closed, that didn't
+// make a profit.
+func longestLosingStreak(trades []Trade) int {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var streak, longest int
+	for _, trade := range sorted {
+		if trade.Profit > 0 {
+			streak = 0
+			continue
+		}
+		streak++
+		longest = max(longest, streak)
+	}
+	return longest
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// User roles, from least to most privileged. Viewers only receive signal notifications,
+// traders can act on signals and change their settings, and admins can also manage roles.
+const (
+	RoleViewer = "viewer"
+	RoleTrader = "trader"
+	RoleAdmin  = "admin"
+)
+
+// roleRanks orders the roles so that each role includes the permissions of those below it.
+var roleRanks = map[string]int{
+	RoleViewer: 0,
+	RoleTrader: 1,
+	RoleAdmin:  2,
+}
+
+// commandRoles lists the minimum role for each bot command. Commands not listed require a viewer.
+var commandRoles = map[string]string{
+	"settings":    RoleTrader,
+	"profiles":    RoleTrader,
+	"history":     RoleTrader,
+	"positions":   RoleTrader,
+	"ticker":      RoleTrader,
+	"balance":     RoleTrader,
+	"exposure":    RoleTrader,
+	"performance": RoleTrader,
+	"backtest":    RoleTrader,
+	"report":      RoleTrader,
+	"connect":     RoleTrader,
+	"disconnect":  RoleTrader,
+	"pin":         RoleTrader,
+	"role":        RoleAdmin,
+	"roles":       RoleAdmin,
+	"audit":       RoleAdmin,
+	"backup":      RoleAdmin,
+	"restore":     RoleAdmin,
+	"import":      RoleAdmin,
+	"panic":       RoleAdmin,
+}
+
+// UserRole assigns a role to a Telegram user.
+type UserRole struct {
+	ID     uint  `gorm:"primaryKey"`
+	UserID int64 `gorm:"uniqueIndex"`
+	Role   string
+}
+
+// ListUserRoles retrieves all role assignments ordered by role.
+func ListUserRoles() ([]UserRole, error) {
+	var roles []UserRole
+	if err := db.Order("role, user_id").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// SetUserRole creates or updates a user's role.
+func SetUserRole(userID int64, role string) error {
+	if _, ok := roleRanks[role]; !ok {
+		return fmt.Errorf("unknown role %q", role)
+	}
+
+	var userRole UserRole
+	err := db.Where("user_id = ?", userID).First(&userRole).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to retrieve user role: %w", err)
+	}
+	userRole.UserID = userID
+	userRole.Role = role
+	if err := db.Save(&userRole).Error; err != nil {
+		return fmt.Errorf("failed to save user role: %w", err)
+	}
+	return nil
+}
+
+// GetUserRole returns a user's role. The configured admin user is always an admin, and users
+// without an assignment are viewers. Until an admin user is configured roles are not enforced,
+// so existing single-user setups keep working.
+func GetUserRole(userID int64) string {
+	adminUserID := GetGlobalConfig().AdminUserID
+	if adminUserID == 0 || userID == adminUserID {
+		return RoleAdmin
+	}
+
+	var userRole UserRole
+	if err := db.Where("user_id = ?", userID).First(&userRole).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			telegramLog.Error("Failed to retrieve role", "user_id", userID, "error", err)
+		}
+		return RoleViewer
+	}
+	return userRole.Role
+}
+
+// hasRole reports whether the user's role is at least the required one.
+func hasRole(userID int64, required string) bool {
+	return roleRanks[GetUserRole(userID)] >= roleRanks[required]
+}
+
+// senderID returns the ID of the user who sent a message, or 0 for anonymous channel posts.
+func senderID(message *tgbotapi.Message) int64 {
+	if message.From == nil {
+		return 0
+	}
+	return message.From.ID
+}
+
+// commandAllowed reports whether the sender may run the message's command, telling them if not.
+func commandAllowed(message *tgbotapi.Message) bool {
+	required, ok := commandRoles[message.Command()]
+	if !ok || hasRole(senderID(message), required) {
+		return true
+	}
+	bot.Send(tgbotapi.NewMessage(message.Chat.ID, tr(message.Chat.ID, "This command requires the %s role.", required)))
+	return false
+}
+
+// handleRoleCommand assigns a role with "/role <user_id> <admin|trader|viewer>".
+func handleRoleCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /role <user_id> <admin|trader|viewer>")))
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid user ID.")))
+		return
+	}
+	role := strings.ToLower(args[1])
+	if err := SetUserRole(userID, role); err != nil {
+		telegramLog.Error("Failed to set role", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to set role: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "User %d is now a %s.", userID, role)))
+}
+
+// handleRolesCommand lists the role assignments.
+func handleRolesCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	roles, err := ListUserRoles()
+	if err != nil {
+		telegramLog.Error("Failed to list roles", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load roles.")))
+		return
+	}
+
+	text := tr(chatID, "User Roles:\n")
+	if adminUserID := GetGlobalConfig().AdminUserID; adminUserID != 0 {
+		text += tr(chatID, "%d: %s (configured admin)\n", adminUserID, RoleAdmin)
+	} else {
+		text += tr(chatID, "No admin user is configured, so roles are not enforced.\n")
+	}
+	for _, userRole := range roles {
+		text += fmt.Sprintf("%d: %s\n", userRole.UserID, userRole.Role)
+	}
+	text += tr(chatID, "\nUsers without a role are viewers.")
+	bot.Send(tgbotapi.NewMessage(chatID, text))
+}
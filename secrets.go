@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// encryptedPrefix marks a secret column value encrypted by secretSerializer. Values without it
+// are plaintext from before encryption was enabled.
+const encryptedPrefix = "enc:v1:"
+
+// secretKeys holds the master keys secrets are encrypted with, loaded by initSecrets.
+var secretKeys struct {
+	current   []byte            // Encrypts new values; nil leaves secrets in plaintext
+	currentID string            // Key ID of current
+	byID      map[string][]byte // Current and previous keys for decryption
+}
+
+func init() {
+	schema.RegisterSerializer("encrypted", secretSerializer{})
+}
+
+// initSecrets loads the master key from SECRETS_MASTER_KEY and the keys it replaced from
+// SECRETS_PREVIOUS_KEYS (comma-separated). Keys are 32 random bytes, base64-encoded.
+func initSecrets() error {
+	secretKeys.byID = make(map[string][]byte)
+	if previous := os.Getenv("SECRETS_PREVIOUS_KEYS"); previous != "" {
+		for _, encoded := range strings.Split(previous, ",") {
+			key, err := parseMasterKey(encoded)
+			if err != nil {
+				return fmt.Errorf("invalid SECRETS_PREVIOUS_KEYS: %w", err)
+			}
+			secretKeys.byID[masterKeyID(key)] = key
+		}
+	}
+
+	encoded := os.Getenv("SECRETS_MASTER_KEY")
+	if encoded == "" {
+		slog.Warn("SECRETS_MASTER_KEY is not set, API secrets and the bot token are stored in plaintext")
+		return nil
+	}
+	key, err := parseMasterKey(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid SECRETS_MASTER_KEY: %w", err)
+	}
+	secretKeys.current = key
+	secretKeys.currentID = masterKeyID(key)
+	secretKeys.byID[secretKeys.currentID] = key
+	return nil
+}
+
+// parseMasterKey decodes a base64 AES-256 master key.
+func parseMasterKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key is not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// masterKeyID identifies a master key in encrypted values without revealing it.
+func masterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// sealAESGCM encrypts plaintext with key and prepends the random nonce.
+func sealAESGCM(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openAESGCM decrypts data produced by sealAESGCM.
+func openAESGCM(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+// encryptSecret encrypts a value with a new data key and wraps the data key with the master
+// key, giving "enc:v1:<key ID>:<wrapped data key>:<ciphertext>". Without a master key, or for
+// empty values, the value is returned unchanged.
+func encryptSecret(plaintext string) (string, error) {
+	if secretKeys.current == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	ciphertext, err := sealAESGCM(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	wrappedKey, err := sealAESGCM(secretKeys.current, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return encryptedPrefix + secretKeys.currentID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptSecret reverses encryptSecret using the current or a previous master key. Plaintext
+// values are returned unchanged.
+func decryptSecret(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted secret")
+	}
+	masterKey, ok := secretKeys.byID[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("secret is encrypted with unknown master key %s; set it in SECRETS_MASTER_KEY or SECRETS_PREVIOUS_KEYS", parts[0])
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed data key: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed ciphertext: %w", err)
+	}
+	dataKey, err := openAESGCM(masterKey, wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	plaintext, err := openAESGCM(dataKey, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// secretSerializer encrypts string columns tagged `gorm:"serializer:encrypted"` when they are
+// saved and decrypts them when they are loaded.
+type secretSerializer struct{}
+
+// Scan decrypts a column value into the field.
+func (secretSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported value %T for encrypted field %s", dbValue, field.Name)
+	}
+	plaintext, err := decryptSecret(value)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", field.Name, err)
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value encrypts the field for its column.
+func (secretSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s must be a string", field.Name)
+	}
+	return encryptSecret(value)
+}
+
+// rotateSecrets re-encrypts every secret column with the current master key, encrypting any
+// still in plaintext. Run it with -rotate-secrets after moving the old key to
+// SECRETS_PREVIOUS_KEYS; afterwards the old key is no longer needed.
+func rotateSecrets() error {
+	if secretKeys.current == nil {
+		return errors.New("SECRETS_MASTER_KEY is not set")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := resaveRows[Config](tx); err != nil {
+			return err
+		}
+		if err := resaveRows[BinanceAccount](tx); err != nil {
+			return err
+		}
+		if err := resaveRows[UserCredential](tx); err != nil {
+			return err
+		}
+		if err := resaveRows[TradeAuth](tx); err != nil {
+			return err
+		}
+		return resaveRows[ConfigVersion](tx)
+	})
+}
+
+// resaveRows loads and saves every row of a model, so its secret columns are encrypted again.
+func resaveRows[T any](tx *gorm.DB) error {
+	var rows []T
+	if err := tx.Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load secrets: %w", err)
+	}
+	for i := range rows {
+		if err := tx.Save(&rows[i]).Error; err != nil {
+			return fmt.Errorf("failed to re-encrypt secrets: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/sessions"
+)
+
+// Session management
+var store *sessions.CookieStore
+
+// sessionName is the cookie holding the admin panel session.
+const sessionName = "session-name"
+
+// Defaults for SESSION_IDLE_MINUTES and SESSION_REMEMBER_DAYS.
+const (
+	defaultSessionIdleMinutes  = 30
+	defaultSessionRememberDays = 30
+)
+
+// sessionIdleTimeout logs admins out after that long without a request. Logins with
+// "Remember me" last sessionRememberFor instead, or the option is hidden if it is zero. Every
+// request starts the time again.
+var (
+	sessionIdleTimeout time.Duration
+	sessionRememberFor time.Duration
+)
+
+// initSessionStore creates the session store signed with secret and reads the session
+// lifetimes from the environment. secure limits the cookie to HTTPS.
+func initSessionStore(secret string, secure bool) {
+	idleMinutes := envInt("SESSION_IDLE_MINUTES", defaultSessionIdleMinutes)
+	if idleMinutes == 0 {
+		adminLog.Warn("SESSION_IDLE_MINUTES must be at least 1, using the default", "minutes", defaultSessionIdleMinutes)
+		idleMinutes = defaultSessionIdleMinutes
+	}
+	sessionIdleTimeout = time.Duration(idleMinutes) * time.Minute
+	sessionRememberFor = time.Duration(envInt("SESSION_REMEMBER_DAYS", defaultSessionRememberDays)) * 24 * time.Hour
+
+	store = sessions.NewCookieStore([]byte(secret))
+	// Cookies carry the time they were issued; accept them for as long as a session can last
+	store.MaxAge(int(max(sessionIdleTimeout, sessionRememberFor).Seconds()))
+
+	store.Options = &sessions.Options{
+		HttpOnly: true,
+		Path:     "/",
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// startAdminSession logs user in on the response, replacing any earlier session.
+func startAdminSession(w http.ResponseWriter, r *http.Request, user *AdminUser, remember bool) {
+	session, _ := store.Get(r, sessionName)
+	session.Values = map[interface{}]interface{}{
+		"authenticated": true,
+		"username":      user.Username,
+		"version":       user.SessionVersion,
+		"remember":      remember && sessionRememberFor > 0,
+	}
+	saveAdminSession(w, r, session)
+}
+
+// loadAdminSession returns the username and session version of the request's session and
+// renews it. It returns an empty username without a session, and expired is set for one that
+// timed out, which is cleared.
+func loadAdminSession(w http.ResponseWriter, r *http.Request) (username string, version int, expired bool) {
+	session, _ := store.Get(r, sessionName)
+	auth, _ := session.Values["authenticated"].(bool)
+	username, _ = session.Values["username"].(string)
+	if !auth || username == "" {
+		return "", 0, false
+	}
+	version, _ = session.Values["version"].(int)
+	lastSeen, _ := session.Values["last_seen"].(int64)
+	if time.Since(time.Unix(lastSeen, 0)) > sessionTimeout(session) {
+		endAdminSession(w, r)
+		return "", 0, true
+	}
+	saveAdminSession(w, r, session)
+	return username, version, false
+}
+
+// sessionRemembered reports whether the request's session was started with "Remember me".
+func sessionRemembered(r *http.Request) bool {
+	session, _ := store.Get(r, sessionName)
+	remember, _ := session.Values["remember"].(bool)
+	return remember
+}
+
+// sessionTimeout returns how long a session lasts without requests.
+func sessionTimeout(session *sessions.Session) time.Duration {
+	if remember, _ := session.Values["remember"].(bool); remember {
+		return sessionRememberFor
+	}
+	return sessionIdleTimeout
+}
+
+// saveAdminSession records the request time on the session and saves it. Remembered sessions
+// get a cookie that outlives the browser; others end when it is closed.
+func saveAdminSession(w http.ResponseWriter, r *http.Request, session *sessions.Session) {
+	session.Values["last_seen"] = time.Now().Unix()
+	if remember, _ := session.Values["remember"].(bool); remember {
+		session.Options.MaxAge = int(sessionRememberFor.Seconds())
+	}
+	if err := session.Save(r, w); err != nil {
+		adminLog.Error("Error saving session", "error", err)
+	}
+}
+
+// endAdminSession logs the request's session out.
+func endAdminSession(w http.ResponseWriter, r *http.Request) {
+	session, _ := store.Get(r, sessionName)
+	session.Values = map[interface{}]interface{}{}
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		adminLog.Error("Error clearing session", "error", err)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultShutdownTimeout is how long, in seconds, shutting down waits for webhooks, trades and
+// the order monitors to finish before exiting anyway. SHUTDOWN_TIMEOUT overrides it.
+const defaultShutdownTimeout = 30
+
+// WorkTracker counts work in progress, so shutting down can wait for it to finish. Once closed
+// it refuses new work.
+type WorkTracker struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// Begin records the start of some work, or returns false if the bot is shutting down. Every
+// successful Begin must be followed by End.
+func (t *WorkTracker) Begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+// End records that work started with Begin has finished.
+func (t *WorkTracker) End() {
+	t.wg.Done()
+}
+
+// Close refuses new work and waits for the work in progress until ctx is done.
+func (t *WorkTracker) Close(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var (
+	// inFlightTrades counts ExecuteTrade calls, so a deploy doesn't leave an entry without its
+	// TPs and SL.
+	inFlightTrades WorkTracker
+	// orderMonitors counts running user data stream monitors.
+	orderMonitors WorkTracker
+
+	// shuttingDown is closed when the bot starts shutting down, to stop the order monitors.
+	shuttingDown     = make(chan struct{})
+	shuttingDownOnce sync.Once
+)
+
+// drain shuts down in order, so accepted work finishes before what it depends on stops: the
+// HTTP servers stop taking requests and finish webhooks in progress, the Telegram listener
+// finishes the update it is handling, then trades placed from anywhere else finish, and the
+// order monitors disconnect. Each step gets what is left of the timeout. Signals and trade state
+// are saved last either way.
+func drain(timeout time.Duration, servers ...*http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("Server forced to shutdown", "error", err)
+		}
+	}
+	if err := runUntil(ctx, stopTelegramListener); err != nil {
+		slog.Warn("Telegram listener did not stop in time", "error", err)
+	}
+	if err := inFlightTrades.Close(ctx); err != nil {
+		slog.Error("Failed to wait for trades in progress", "error", err)
+	}
+
+	shuttingDownOnce.Do(func() { close(shuttingDown) })
+	if err := orderMonitors.Close(ctx); err != nil {
+		slog.Warn("Order monitors did not stop in time", "error", err)
+	}
+
+	// Save signals and trade state changed since the last periodic save
+	saveSignals()
+	saveTradeState()
+}
+
+// runUntil runs fn, returning early with the context's error if ctx is done first.
+func runUntil(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
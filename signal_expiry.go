@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// signalExpiryCandles is how many candles of its timeframe a signal stays valid for, so a 15m
+// signal expires after 30 minutes and a 4h signal after 8 hours.
+const signalExpiryCandles = 2
+
+// timeframeDuration returns the length of a candle of a timeframe such as "15m", "4h", "1d",
+// "1w", "1M" or TradingView's "240" minutes, or 0 if the timeframe is not recognised. An
+// uppercase M is a month of 30 days, as on TradingView, and a lowercase m a minute.
+func timeframeDuration(timeframe string) time.Duration {
+	tf := strings.TrimSpace(timeframe)
+	unit := time.Minute
+	if strings.HasSuffix(tf, "M") {
+		unit = 30 * 24 * time.Hour
+		tf = tf[:len(tf)-1]
+	} else {
+		tf = strings.ToLower(tf)
+		units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+		if tf != "" {
+			if u, ok := units[tf[len(tf)-1]]; ok {
+				unit = u
+				tf = tf[:len(tf)-1]
+			}
+		}
+	}
+	if tf == "" && unit != time.Minute {
+		tf = "1" // "D", "W" and "M" are one day, week and month
+	}
+	count, err := strconv.Atoi(tf)
+	if err != nil || count <= 0 {
+		return 0
+	}
+	return time.Duration(count) * unit
+}
+
+// parseSignalExpiry parses per-source validity periods such as "tradingview=2h, telegram=45m"
+// into durations by lowercase source.
+func parseSignalExpiry(text string) (map[string]time.Duration, error) {
+	periods := make(map[string]time.Duration)
+	for _, part := range strings.Split(text, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		source, period, ok := strings.Cut(part, "=")
+		source = strings.ToLower(strings.TrimSpace(source))
+		if !ok || source == "" {
+			return nil, fmt.Errorf("expected SOURCE=PERIOD, e.g. tradingview=2h")
+		}
+		duration := timeframeDuration(period)
+		if duration == 0 {
+			return nil, fmt.Errorf("invalid period %q for %s, e.g. 45m, 2h or 1d", strings.TrimSpace(period), source)
+		}
+		periods[source] = duration
+	}
+	return periods, nil
+}
+
+// signalValidity returns how long a signal may go unanswered: the period configured for its
+// source, otherwise signalExpiryCandles of its timeframe, otherwise signalExpiry.
+func signalValidity(signal *AlertMessage) time.Duration {
+	periods, _ := parseSignalExpiry(GetGlobalConfig().SignalExpiryBySource)
+	if period, ok := periods[strings.ToLower(signal.Source)]; ok {
+		return period
+	}
+	if candle := timeframeDuration(signal.Timeframe); candle > 0 {
+		return signalExpiryCandles * candle
+	}
+	return signalExpiry
+}
+
+// expiryTime returns when the signal expires. Signals received before expiry times were kept
+// expire signalExpiry after they arrived.
+func (a *AlertMessage) expiryTime() time.Time {
+	if a.ExpiresAt.IsZero() {
+		return a.ReceivedAt.Add(signalExpiry)
+	}
+	return a.ExpiresAt
+}
+
+// signalExpired reports whether an unanswered signal has expired.
+func signalExpired(signal *AlertMessage) bool {
+	return signal.Expired || (!signal.Confirmed && !signal.Dismissed && time.Now().After(signal.expiryTime()))
+}
+
+// refuseExpired tells the chat an expired signal can't be confirmed anymore, and reports
+// whether it refused.
+func refuseExpired(chatID int64, signal *AlertMessage) bool {
+	if !signalExpired(signal) {
+		return false
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This signal expired at %s and can no longer be confirmed.", formatUserTime(chatID, signal.expiryTime()))))
+	return true
+}
+
+// expireSignal marks an unanswered signal as expired and removes the keyboard from its message.
+func expireSignal(signal *AlertMessage) {
+	signal.Expired = true
+	recordSignalStatus(signal, SignalExpired)
+	telegramLog.Info("Signal expired unanswered", "signal_id", signal.SignalID, "chat_id", signal.ChatID)
+
+	messageID, _ := messageStore.Get(signal.SignalID)
+	if bot == nil || messageID == 0 {
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(signal.ChatID, messageID, constructSignalMessageText(signal))
+	edit.ParseMode = "HTML"
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit expired signal message", "signal_id", signal.SignalID, "chat_id", signal.ChatID, "error", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Actions of a signal filter when its rule matches.
+const (
+	FilterReject   = "reject"   // The signal is not sent
+	FilterAnnotate = "annotate" // The signal is sent with the filter's note
+)
+
+// FilterRule is a signal filter: a rule incoming signals are checked against before they are
+// sent to Telegram, written in the language parseFilterRule reads.
+type FilterRule struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:64"`
+	Source    string `gorm:"size:64"` // Signal source the rule applies to, empty for every source
+	Rule      string
+	Action    string `gorm:"size:16"` // FilterReject or FilterAnnotate
+	Note      string // Shown on signals an annotate rule matches
+	Enabled   bool
+	CreatedAt time.Time
+}
+
+// ListFilterRules retrieves all signal filters in the order they were added, which is the
+// order they are checked in.
+func ListFilterRules() ([]FilterRule, error) {
+	var filters []FilterRule
+	if err := db.Order("id").Find(&filters).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve signal filters: %w", err)
+	}
+	return filters, nil
+}
+
+// CreateFilterRule adds an enabled signal filter after checking its rule.
+func CreateFilterRule(name, source, rule, action, note string) error {
+	if _, err := parseFilterRule(rule); err != nil {
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+	if action != FilterReject && action != FilterAnnotate {
+		return fmt.Errorf("unknown action %q", action)
+	}
+	if action == FilterAnnotate && note == "" {
+		return fmt.Errorf("annotate rules need a note to show on the signal")
+	}
+
+	filter := FilterRule{Name: name, Source: source, Rule: rule, Action: action, Note: note, Enabled: true}
+	if err := db.Create(&filter).Error; err != nil {
+		return fmt.Errorf("failed to save signal filter: %w", err)
+	}
+	return nil
+}
+
+// SetFilterRuleEnabled turns a signal filter on or off.
+func SetFilterRuleEnabled(id uint, enabled bool) error {
+	result := db.Model(&FilterRule{}).Where("id = ?", id).Update("enabled", enabled)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update signal filter: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("signal filter %d not found", id)
+	}
+	return nil
+}
+
+// DeleteFilterRule removes a signal filter by ID.
+func DeleteFilterRule(id uint) error {
+	if err := db.Delete(&FilterRule{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete signal filter: %w", err)
+	}
+	return nil
+}
+
+// applySignalFilters checks a signal against the enabled filters for its source, in order.
+// Matching annotate rules add their notes to the signal; the first matching reject rule stops
+// the check and is returned. Rules that can't be evaluated, e.g. while Binance is unreachable,
+// are skipped, so an outage doesn't hold back every signal.
+func applySignalFilters(signal *AlertMessage) *FilterRule {
+	filters, err := ListFilterRules()
+	if err != nil {
+		webhookLog.Warn("Failed to load signal filters, sending signal unfiltered", "signal_id", signal.SignalID, "error", err)
+		return nil
+	}
+
+	env := newFilterEnv(signal)
+	for _, filter := range filters {
+		if !filter.Enabled || (filter.Source != "" && !strings.EqualFold(filter.Source, signal.Source)) {
+			continue
+		}
+		expr, err := parseFilterRule(filter.Rule)
+		if err != nil {
+			webhookLog.Warn("Skipping invalid signal filter", "filter", filter.Name, "error", err)
+			continue
+		}
+		matched, err := expr.eval(env)
+		if err != nil {
+			webhookLog.Warn("Skipping signal filter", "filter", filter.Name, "signal_id", signal.SignalID, "error", err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		if filter.Action == FilterReject {
+			return &filter
+		}
+		signal.FilterNotes = append(signal.FilterNotes, filter.Note)
+	}
+	return nil
+}
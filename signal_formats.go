@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Patterns for Cornix-formatted signals, which list entries, targets and stops as numbered lines:
+//
+//	#BTC/USDT
+//	Signal Type: Regular (Long)
+//	Leverage: Cross (20X)
+//	Entry Targets:
+//	1) 64000
+//	2) 63500
+//	Take-Profit Targets:
+//	1) 65000
+//	2) 66000
+//	Stop Targets:
+//	1) 62000
+//
+// They run on text normalized like parseSignalText's.
+var (
+	cornixRe         = regexp.MustCompile(`\b(?:ENTRY|TAKE[\s\-]?PROFIT|STOP)\s+TARGETS?\b`)
+	cornixHeaderRe   = regexp.MustCompile(`^\s*(?:[^A-Z0-9\s]+\s*)?(ENTRY|TAKE[\s\-]?PROFIT|TARGET|STOP)[A-Z\s\-]*:?(.*)$`)
+	cornixItemRe     = regexp.MustCompile(`^\s*\d+\s*[).:\-]\s*` + signalNumber)
+	cornixLeverageRe = regexp.MustCompile(`\bLEVERAGE\s*:?\s*(?:CROSS|ISOLATED)?\s*\(?\s*` + signalNumber + `\s*X`)
+)
+
+// isCornixSignal reports whether normalized text is laid out like a Cornix signal.
+func isCornixSignal(upper string) bool {
+	return cornixRe.MatchString(upper)
+}
+
+// parseCornixSignal converts normalized Cornix-formatted text into an AlertMessage. Several
+// entry targets make an entry range, and the first stop target is the SL. The leverage, if
+// given, applies to this signal only.
+func parseCornixSignal(upper string) (*AlertMessage, error) {
+	alert := &AlertMessage{}
+	direction := directionRe.FindStringSubmatch(upper)
+	if direction == nil {
+		return nil, errors.New("no direction (LONG/SHORT or BUY/SELL) found")
+	}
+	alert.SignalType = "Buy"
+	if direction[1] == "SHORT" || direction[1] == "SELL" {
+		alert.SignalType = "Sell"
+	}
+
+	if pair := pairRe.FindStringSubmatch(upper); pair != nil {
+		alert.Symbol = pair[1] + pair[2]
+	} else if tag := hashtagRe.FindStringSubmatch(upper); tag != nil {
+		alert.Symbol = tag[1] + "USDT"
+	} else {
+		return nil, errors.New("no symbol found")
+	}
+
+	// Numbered lines belong to the section whose header precedes them
+	var entries, targets, stops []float64
+	var section *[]float64
+	for _, line := range strings.Split(upper, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if item := cornixItemRe.FindStringSubmatch(line); item != nil {
+			if section != nil {
+				value, _ := strconv.ParseFloat(item[1], 64)
+				*section = append(*section, value)
+			}
+			continue
+		}
+		header := cornixHeaderRe.FindStringSubmatch(line)
+		if header == nil {
+			section = nil
+			continue
+		}
+		switch {
+		case header[1] == "ENTRY":
+			section = &entries
+		case header[1] == "STOP":
+			section = &stops
+		default:
+			section = &targets
+		}
+		// Values may follow the header, e.g. "Stop Targets: 62000"
+		for _, num := range signalNumRe.FindAllString(header[2], -1) {
+			value, _ := strconv.ParseFloat(num, 64)
+			*section = append(*section, value)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no entry targets found")
+	}
+	alert.EntryPrice = entries[0]
+	if len(entries) > 1 {
+		alert.LowPrice, alert.HighPrice = entries[0], entries[0]
+		for _, entry := range entries[1:] {
+			alert.LowPrice = min(alert.LowPrice, entry)
+			alert.HighPrice = max(alert.HighPrice, entry)
+		}
+		alert.Midpoint = (alert.LowPrice + alert.HighPrice) / 2
+		alert.EntryPrice = alert.Midpoint
+	}
+	if len(targets) > maxTPLevels {
+		targets = targets[:maxTPLevels]
+	}
+	alert.TPs = targets
+	if len(stops) > 0 {
+		alert.SL = stops[0]
+	}
+	if leverage := cornixLeverageRe.FindStringSubmatch(upper); leverage != nil {
+		value, _ := strconv.ParseFloat(leverage[1], 64)
+		alert.LeverageOverride = int(value)
+	}
+
+	if err := validateParsedSignal(alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// threeCommasSignal is the JSON a 3Commas Signal Bot webhook is sent, e.g. from a TradingView
+// alert set up for 3Commas. Prices may be sent as numbers or strings.
+type threeCommasSignal struct {
+	Action       string          `json:"action"`        // enter_long, enter_short, exit_long or exit_short
+	TVInstrument string          `json:"tv_instrument"` // TradingView ticker, e.g. BTCUSDT.P
+	Pair         string          `json:"pair"`          // 3Commas pair, e.g. USDT_BTC
+	TriggerPrice json.RawMessage `json:"trigger_price"`
+	Timestamp    string          `json:"timestamp"`
+	Order        *struct {
+		Amount       json.RawMessage `json:"amount"`
+		CurrencyType string          `json:"currency_type"` // quote for an amount in USDT
+	} `json:"order"`
+}
+
+// jsonNumber reads a JSON number that may be sent as a string.
+func jsonNumber(raw json.RawMessage) float64 {
+	value, _ := strconv.ParseFloat(strings.Trim(string(raw), `" `), 64)
+	return value
+}
+
+// parseThreeCommasSignal converts a 3Commas Signal Bot webhook into an AlertMessage. 3Commas
+// bots keep their TPs and SL in the bot, so they come from the chat's settings. Exit signals
+// are rejected, since the bot's positions close through their TPs and SL.
+func parseThreeCommasSignal(body []byte) (*AlertMessage, error) {
+	var signal threeCommasSignal
+	if err := json.Unmarshal(body, &signal); err != nil {
+		return nil, fmt.Errorf("invalid 3Commas signal: %w", err)
+	}
+
+	alert := &AlertMessage{Source: "3commas"}
+	switch signal.Action {
+	case "enter_long":
+		alert.SignalType = "Buy"
+	case "enter_short":
+		alert.SignalType = "Sell"
+	case "exit_long", "exit_short":
+		return nil, fmt.Errorf("3Commas %s signals are not supported", signal.Action)
+	default:
+		return nil, fmt.Errorf("unknown 3Commas action %q", signal.Action)
+	}
+
+	// TradingView tickers may carry the exchange and a perpetual suffix, e.g. BINANCE:BTCUSDT.P
+	symbol := strings.ToUpper(signal.TVInstrument)
+	if i := strings.LastIndex(symbol, ":"); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	symbol = strings.TrimSuffix(strings.TrimSuffix(symbol, ".P"), "PERP")
+	if symbol == "" {
+		if quote, base, ok := strings.Cut(strings.ToUpper(signal.Pair), "_"); ok {
+			symbol = base + quote
+		}
+	}
+	if symbol == "" {
+		return nil, errors.New("no tv_instrument or pair found")
+	}
+	alert.Symbol = symbol
+
+	alert.EntryPrice = jsonNumber(signal.TriggerPrice)
+	if alert.EntryPrice <= 0 {
+		return nil, errors.New("trigger_price must be positive")
+	}
+	if signal.Order != nil && signal.Order.CurrencyType == "quote" {
+		alert.AmountOverride = jsonNumber(signal.Order.Amount)
+	}
+
+	alert.Time = time.Now().UTC().Format(time.RFC3339)
+	if signal.Timestamp != "" {
+		alert.Time = signal.Timestamp
+	}
+	alert.SignalID = fmt.Sprintf("3c%d", time.Now().UnixMilli())
+	return alert, nil
+}
+
+// decodeWebhookAlert reads a webhook body: the bot's own JSON alert, a 3Commas Signal Bot
+// webhook, or a signal in text, such as a Cornix-formatted message from a TradingView alert.
+func decodeWebhookAlert(body []byte) (*AlertMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		if json.Valid(body) {
+			return nil, errors.New("Invalid JSON format")
+		}
+		alert, err := parseSignalText(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid signal text: %v", err)
+		}
+		alert.SignalID = fmt.Sprintf("wh%d", time.Now().UnixMilli())
+		alert.Time = time.Now().UTC().Format(time.RFC3339)
+		alert.Source = "webhook"
+		return alert, nil
+	}
+
+	if _, hasAction := fields["action"]; hasAction {
+		if _, hasSignalID := fields["signal_id"]; !hasSignalID {
+			return parseThreeCommasSignal(body)
+		}
+	}
+	var alert AlertMessage
+	if err := json.Unmarshal(body, &alert); err != nil {
+		return nil, errors.New("Invalid JSON format")
+	}
+	return &alert, nil
+}
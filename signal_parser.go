@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionParsed is the callback action for accepting or discarding a parsed text signal.
+const ActionParsed = "parsed"
+
+// Patterns for free-text signals such as "LONG BTCUSDT Entry 64000 TP 65000/66000 SL 63000".
+// They run on upper-cased text with thousands separators removed.
+const signalNumber = `(\d+(?:\.\d+)?)`
+
+var (
+	thousandsRe  = regexp.MustCompile(`(\d),(\d{3})\b`)
+	directionRe  = regexp.MustCompile(`\b(LONG|BUY|SHORT|SELL)\b`)
+	pairRe       = regexp.MustCompile(`\b([A-Z0-9]{2,15}?)[/\-]?(USDT|USDC)(?:\.P|PERP)?\b`)
+	hashtagRe    = regexp.MustCompile(`#([A-Z0-9]{2,15})\b`)
+	entryRe      = regexp.MustCompile(`(?:\bENTRY(?:\s+ZONE|\s+PRICE)?|\bENTRIES|\b(?:BUY|SELL)\s+ZONE|@)\s*[:=\-]?\s*` + signalNumber + `(?:\s*(?:-|–|TO|/)\s*` + signalNumber + `)?`)
+	targetsRe    = regexp.MustCompile(`\b(?:TP\d?|TARGETS?(?:\s*\d)?|TAKE[\s\-]?PROFITS?(?:\s*\d)?)\b\s*[:=\-]?\s*(` + signalNumber + `\b(?:\s*[/,|]\s*` + signalNumber + `\b|\s+` + signalNumber + `\b)*)`)
+	stopLossRe   = regexp.MustCompile(`\b(?:SL|STOP[\s\-]?LOSS|STOP)\b\s*[:=\-]?\s*` + signalNumber)
+	timeframeRe  = regexp.MustCompile(`\b(?:TF|TIMEFRAME)\s*[:=]?\s*(\w+)`)
+	signalNumRe  = regexp.MustCompile(signalNumber)
+	parsedSignal = NewSignalStore() // Parsed signals awaiting the user's confirmation, by signal ID
+)
+
+// parseSignalText converts a free-text signal into an AlertMessage. Up to maxTPLevels targets are used;
+// an entry range sets the high/low prices and uses the midpoint as entry. Cornix-formatted text is
+// read by parseCornixSignal.
+func parseSignalText(text string) (*AlertMessage, error) {
+	upper := strings.ToUpper(text)
+	for thousandsRe.MatchString(upper) {
+		upper = thousandsRe.ReplaceAllString(upper, "$1$2")
+	}
+	if isCornixSignal(upper) {
+		return parseCornixSignal(upper)
+	}
+
+	alert := &AlertMessage{}
+
+	direction := directionRe.FindStringSubmatch(upper)
+	if direction == nil {
+		return nil, errors.New("no direction (LONG/SHORT or BUY/SELL) found")
+	}
+	alert.SignalType = "Buy"
+	if direction[1] == "SHORT" || direction[1] == "SELL" {
+		alert.SignalType = "Sell"
+	}
+
+	if pair := pairRe.FindStringSubmatch(upper); pair != nil {
+		alert.Symbol = pair[1] + pair[2]
+	} else if tag := hashtagRe.FindStringSubmatch(upper); tag != nil {
+		alert.Symbol = tag[1] + "USDT"
+	} else {
+		return nil, errors.New("no symbol found")
+	}
+
+	entry := entryRe.FindStringSubmatch(upper)
+	if entry == nil {
+		return nil, errors.New("no entry price found")
+	}
+	alert.EntryPrice, _ = strconv.ParseFloat(entry[1], 64)
+	if entry[2] != "" {
+		other, _ := strconv.ParseFloat(entry[2], 64)
+		alert.LowPrice = min(alert.EntryPrice, other)
+		alert.HighPrice = max(alert.EntryPrice, other)
+		alert.Midpoint = (alert.LowPrice + alert.HighPrice) / 2
+		alert.EntryPrice = alert.Midpoint
+	}
+
+	var targets []float64
+	for _, match := range targetsRe.FindAllStringSubmatch(upper, -1) {
+		for _, num := range signalNumRe.FindAllString(match[1], -1) {
+			value, _ := strconv.ParseFloat(num, 64)
+			targets = append(targets, value)
+		}
+	}
+	if len(targets) > maxTPLevels {
+		targets = targets[:maxTPLevels]
+	}
+	alert.TPs = targets
+
+	if sl := stopLossRe.FindStringSubmatch(upper); sl != nil {
+		alert.SL, _ = strconv.ParseFloat(sl[1], 64)
+	}
+	if tf := timeframeRe.FindStringSubmatch(upper); tf != nil {
+		alert.Timeframe = strings.ToLower(tf[1])
+	}
+
+	if err := validateParsedSignal(alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// validateParsedSignal rejects signals whose targets or stop are on the wrong side of the entry,
+// which usually means the text was misread.
+func validateParsedSignal(alert *AlertMessage) error {
+	if alert.EntryPrice <= 0 {
+		return errors.New("entry price must be positive")
+	}
+	long := alert.SignalType == "Buy"
+	for i, tp := range alert.TPs {
+		if tp > 0 && (tp > alert.EntryPrice) != long {
+			return fmt.Errorf("TP%d %s is on the wrong side of entry %s", i+1, formatFloat(tp), formatFloat(alert.EntryPrice))
+		}
+	}
+	if alert.SL > 0 && (alert.SL < alert.EntryPrice) != long {
+		return fmt.Errorf("SL %s is on the wrong side of entry %s", formatFloat(alert.SL), formatFloat(alert.EntryPrice))
+	}
+	return nil
+}
+
+// handleSignalText parses a pasted or forwarded signal and asks the user to confirm the parsed
+// fields before it enters the normal signal pipeline. It reports whether the text looked like a signal.
+func handleSignalText(message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+	text := message.Text
+	if text == "" {
+		text = message.Caption
+	}
+
+	alert, err := parseSignalText(text)
+	if err != nil {
+		if message.ForwardDate != 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Could not read a signal from the forwarded message: %v", err)))
+			return true
+		}
+		return false
+	}
+
+	alert.SignalID = fmt.Sprintf("tg%d", time.Now().UnixMilli())
+	alert.Time = time.Now().UTC().Format(time.RFC3339)
+	alert.Source = "telegram"
+	if message.ForwardFromChat != nil {
+		// Route by the originating channel, e.g. its @username
+		alert.Source = message.ForwardFromChat.UserName
+		if alert.Source == "" {
+			alert.Source = message.ForwardFromChat.Title
+		}
+	}
+	parsedSignal.Set(alert.SignalID, alert)
+
+	summary := tr(chatID, "<b>Parsed Signal</b>\n\n"+
+		"<b>Direction:</b> %s\n<b>Symbol:</b> %s\n<b>Entry:</b> %s\n",
+		tr(chatID, alert.SignalType), alert.Symbol, formatFloat(alert.EntryPrice))
+	for i := 0; i < max(len(alert.TPs), 1); i++ {
+		summary += tr(chatID, "<b>TP%d:</b> %s\n", i+1, formatFloat(signalTP(alert, i)))
+	}
+	summary += tr(chatID, "<b>SL:</b> %s\n<b>Source:</b> %s\n\nSend it as a signal?", formatFloat(alert.SL), alert.Source)
+	msg := tgbotapi.NewMessage(chatID, summary)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Use Signal"), fmt.Sprintf("%s|use|%s", ActionParsed, alert.SignalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Discard"), fmt.Sprintf("%s|discard|%s", ActionParsed, alert.SignalID)),
+		),
+	)
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send parsed signal", "chat_id", chatID, "error", err)
+	}
+	return true
+}
+
+// handleParsedCallback handles "parsed|use|ID" and "parsed|discard|ID".
+func handleParsedCallback(chatID int64, messageID int, parts []string) {
+	if len(parts) < 2 {
+		telegramLog.Warn("Invalid parsed signal callback data", "data", parts)
+		return
+	}
+	command, signalID := parts[0], parts[1]
+
+	alert, exists := parsedSignal.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Parsed signal not found.")))
+		return
+	}
+	parsedSignal.Delete(signalID)
+
+	result := tr(chatID, "Parsed signal discarded.")
+	if command == "use" {
+		if _, err := sendSignalMessage(context.Background(), alert); err != nil {
+			telegramLog.Error("Failed to send parsed signal", "signal_id", signalID, "chat_id", chatID, "error", err)
+			result = tr(chatID, "Failed to send the signal: %v", err)
+		} else {
+			result = tr(chatID, "Signal for %s sent.", alert.Symbol)
+		}
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, result)
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// signalRetention is how long signals are kept in the database, so their buttons keep
+// working after a restart.
+const signalRetention = 7 * 24 * time.Hour
+
+// signalSaveInterval is how often changed signals are written to the database.
+const signalSaveInterval = 30 * time.Second
+
+// StoredSignal is a signal and its Telegram message saved across restarts.
+type StoredSignal struct {
+	ID         uint   `gorm:"primaryKey"`
+	SignalID   string `gorm:"uniqueIndex;size:128"`
+	ChatID     int64
+	MessageID  int       // 0 if the signal was not sent, e.g. held for a digest
+	ReceivedAt time.Time `gorm:"index"`
+	Data       string    // JSON of signalState
+}
+
+// signalState is a signal with the fields the alert JSON leaves out.
+type signalState struct {
+	Alert            *AlertMessage
+	FundingWarning   string
+	LiquidityWarning string
+	Account          string
+	Liquidation      *LiquidationInfo
+	Filtered         bool
+	FilterNotes      []string
+	RejectedBy       string
+	Indicators       *SignalIndicators
+	LeverageOverride int
+	AmountOverride   float64
+	Profile          string
+	InfoOnly         bool
+	NewsHalt         *EconomicEvent
+	ExpiresAt        time.Time
+	Expired          bool
+}
+
+// newSignalState returns the state of a signal to save.
+func newSignalState(signal *AlertMessage) signalState {
+	return signalState{
+		Alert:            signal,
+		FundingWarning:   signal.FundingWarning,
+		LiquidityWarning: signal.LiquidityWarning,
+		Account:          signal.Account,
+		Liquidation:      signal.Liquidation,
+		Filtered:         signal.Filtered,
+		FilterNotes:      signal.FilterNotes,
+		RejectedBy:       signal.RejectedBy,
+		Indicators:       signal.Indicators,
+		LeverageOverride: signal.LeverageOverride,
+		AmountOverride:   signal.AmountOverride,
+		Profile:          signal.Profile,
+		InfoOnly:         signal.InfoOnly,
+		NewsHalt:         signal.NewsHalt,
+		ExpiresAt:        signal.ExpiresAt,
+		Expired:          signal.Expired,
+	}
+}
+
+// restore returns the saved signal with the fields the alert JSON leaves out, except its chat
+// and when it was received, which are saved next to the state.
+func (state signalState) restore() *AlertMessage {
+	signal := state.Alert
+	signal.FundingWarning = state.FundingWarning
+	signal.LiquidityWarning = state.LiquidityWarning
+	signal.Account = state.Account
+	signal.Liquidation = state.Liquidation
+	signal.Filtered = state.Filtered
+	signal.FilterNotes = state.FilterNotes
+	signal.RejectedBy = state.RejectedBy
+	signal.Indicators = state.Indicators
+	signal.LeverageOverride = state.LeverageOverride
+	signal.AmountOverride = state.AmountOverride
+	signal.InfoOnly = state.InfoOnly
+	signal.NewsHalt = state.NewsHalt
+	signal.ExpiresAt = state.ExpiresAt
+	signal.Expired = state.Expired
+	signal.Profile = state.Profile
+	return signal
+}
+
+// savedSignals remembers the data last written for each signal so unchanged ones are skipped.
+var (
+	savedSignals   = make(map[string]string)
+	savedSignalsMu sync.Mutex
+)
+
+// saveSignals writes the signals received within signalRetention that changed since they
+// were last saved, and removes older ones from the database.
+func saveSignals() {
+	savedSignalsMu.Lock()
+	defer savedSignalsMu.Unlock()
+
+	cutoff := time.Now().Add(-signalRetention)
+	signals := signalStore.Recent(func(signal *AlertMessage) bool { return signal.ReceivedAt.After(cutoff) })
+	for _, signal := range signals {
+		messageID, _ := messageStore.Get(signal.SignalID)
+		data, err := json.Marshal(newSignalState(signal))
+		if err != nil {
+			dbLog.Error("Failed to encode signal", "signal_id", signal.SignalID, "error", err)
+			continue
+		}
+		key := fmt.Sprintf("%d|%s", messageID, data)
+		if savedSignals[signal.SignalID] == key {
+			continue
+		}
+
+		if err := saveSignal(signal, messageID, string(data)); err != nil {
+			dbLog.Error("Failed to save signal", "signal_id", signal.SignalID, "error", err)
+			continue
+		}
+		savedSignals[signal.SignalID] = key
+	}
+
+	if err := db.Where("received_at < ?", cutoff).Delete(&StoredSignal{}).Error; err != nil {
+		dbLog.Error("Failed to remove old signals", "error", err)
+	}
+}
+
+// saveSignal creates or updates a signal's row.
+func saveSignal(signal *AlertMessage, messageID int, data string) error {
+	var stored StoredSignal
+	err := db.Where("signal_id = ?", signal.SignalID).First(&stored).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to retrieve stored signal: %w", err)
+	}
+	stored.SignalID = signal.SignalID
+	stored.ChatID = signal.ChatID
+	stored.MessageID = messageID
+	stored.ReceivedAt = signal.ReceivedAt
+	stored.Data = data
+	if err := db.Save(&stored).Error; err != nil {
+		return fmt.Errorf("failed to save stored signal: %w", err)
+	}
+	return nil
+}
+
+// loadSignals restores the saved signals and their message IDs into signalStore and messageStore.
+func loadSignals() error {
+	var stored []StoredSignal
+	if err := db.Where("received_at >= ?", time.Now().Add(-signalRetention)).Find(&stored).Error; err != nil {
+		return fmt.Errorf("failed to retrieve stored signals: %w", err)
+	}
+
+	savedSignalsMu.Lock()
+	defer savedSignalsMu.Unlock()
+	for _, row := range stored {
+		var state signalState
+		if err := json.Unmarshal([]byte(row.Data), &state); err != nil || state.Alert == nil {
+			dbLog.Warn("Skipping unreadable stored signal", "signal_id", row.SignalID, "error", err)
+			continue
+		}
+		signal := state.restore()
+		signal.ChatID = row.ChatID
+		signal.ReceivedAt = row.ReceivedAt
+
+		signalStore.Set(row.SignalID, signal)
+		if row.MessageID != 0 {
+			messageStore.Set(row.SignalID, row.MessageID)
+		}
+		savedSignals[row.SignalID] = fmt.Sprintf("%d|%s", row.MessageID, row.Data)
+	}
+	dbLog.Info("Restored signals", "count", len(stored))
+	return nil
+}
+
+var signalPersistenceStart sync.Once
+
+// startSignalPersistence saves changed signals and trade state every signalSaveInterval.
+func startSignalPersistence() {
+	signalPersistenceStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(signalSaveInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				saveSignals()
+				saveTradeState()
+			}
+		}()
+	})
+}
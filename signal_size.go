@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionSize is the callback action for overriding leverage and amount on a single signal.
+const ActionSize = "size"
+
+// Preset leverages and USDT amounts offered on the signal size keyboard.
+var (
+	leveragePresets = []int{2, 5, 10, 20}
+	amountPresets   = []float64{50, 100, 250, 500}
+)
+
+// applySignalOverrides returns settings with the leverage and amount picked for this signal and
+// the trading mode of its entry order type, which take precedence over the user's settings,
+// profile and symbol override.
+func applySignalOverrides(signal *AlertMessage, settings *UserSettings) *UserSettings {
+	effective := *settings
+	effective.TradingMode = entryTypeMode(signalEntryType(signal), effective.TradingMode)
+	if signal.LeverageOverride > 0 {
+		effective.Leverage = signal.LeverageOverride
+	}
+	if signal.AmountOverride > 0 {
+		effective.AmountUSDT = signal.AmountOverride
+	}
+	return &effective
+}
+
+// showSignalSizeOptions replaces a signal's keyboard with the leverage and amount presets.
+// The current choices are ticked.
+func showSignalSizeOptions(chatID int64, messageID int, signalID string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+	settings := applySignalOverrides(signal, applySymbolOverride(chatID, signal.Symbol, signalSettings(chatID, signal)))
+
+	var leverageRow, amountRow []tgbotapi.InlineKeyboardButton
+	for _, leverage := range leveragePresets {
+		label := fmt.Sprintf("%dx", leverage)
+		if leverage == settings.Leverage {
+			label = "\u2705 " + label
+		}
+		leverageRow = append(leverageRow, tgbotapi.NewInlineKeyboardButtonData(label,
+			fmt.Sprintf("%s|%s|lev|%d", ActionSize, signalID, leverage)))
+	}
+	for _, amount := range amountPresets {
+		label := fmt.Sprintf("%.0f USDT", amount)
+		if amount == settings.AmountUSDT {
+			label = "\u2705 " + label
+		}
+		amountRow = append(amountRow, tgbotapi.NewInlineKeyboardButtonData(label,
+			fmt.Sprintf("%s|%s|amt|%.0f", ActionSize, signalID, amount)))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		leverageRow,
+		amountRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Use Settings"), fmt.Sprintf("%s|%s|reset", ActionSize, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Done"), fmt.Sprintf("%s|%s|done", ActionSize, signalID)),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, constructSignalMessageText(signal))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = &keyboard
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "symbol", signal.Symbol, "chat_id", chatID, "error", err)
+	}
+}
+
+// handleSizeCallback handles "size|ID" to open the presets, "size|ID|lev|N" and "size|ID|amt|N"
+// to pick one, "size|ID|reset" to go back to the saved settings and "size|ID|done" to close them.
+func handleSizeCallback(chatID int64, messageID int, parts []string) {
+	signalID := parts[0]
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+	if len(parts) < 2 {
+		showSignalSizeOptions(chatID, messageID, signalID)
+		return
+	}
+	if signal.Confirmed || signal.Dismissed {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This signal has already been handled.")))
+		return
+	}
+
+	switch parts[1] {
+	case "done":
+		restoreSignalMessage(chatID, messageID, signalID)
+		return
+	case "reset":
+		signal.LeverageOverride = 0
+		signal.AmountOverride = 0
+	case "lev", "amt":
+		if len(parts) < 3 {
+			telegramLog.Warn("Invalid size callback data", "signal_id", signalID, "data", parts)
+			return
+		}
+		value, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || value <= 0 {
+			telegramLog.Warn("Invalid size callback data", "signal_id", signalID, "data", parts)
+			return
+		}
+		if parts[1] == "lev" {
+			signal.LeverageOverride = int(value)
+		} else {
+			signal.AmountOverride = value
+		}
+	default:
+		telegramLog.Warn("Invalid size callback data", "signal_id", signalID, "data", parts)
+		return
+	}
+
+	// The liquidation estimate depends on leverage and position size
+	settings := applySignalOverrides(signal, applySymbolOverride(chatID, signal.Symbol, signalSettings(chatID, signal)))
+	if client := mainBinanceClient(); client != nil && settings.MarketType != MarketTypeCoinM {
+		info, err := client.liquidationInfo(context.Background(), signal.Symbol, settings)
+		if err != nil {
+			binanceLog.Warn("Failed to estimate liquidation price", "signal_id", signalID, "symbol", signal.Symbol, "error", err)
+		}
+		signal.Liquidation = info
+	}
+
+	showSignalSizeOptions(chatID, messageID, signalID)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Signal lifecycle statuses, stored on the signal's row with a SignalEvent for each transition.
+// TP fills use signalTPHit, e.g. "tp1_hit".
+const (
+	SignalReceived  = "received"
+	SignalEdited    = "edited"
+	SignalConfirmed = "confirmed"
+	SignalExecuted  = "executed"
+	SignalSLHit     = "sl_hit"
+	SignalClosed    = "closed"
+	SignalDismissed = "dismissed"
+	SignalExpired   = "expired"
+	SignalRejected  = "rejected" // Stopped by a signal filter
+)
+
+// signalStatuses lists every status in lifecycle order.
+func signalStatuses() []string {
+	statuses := []string{SignalReceived, SignalEdited, SignalConfirmed, SignalExecuted}
+	for level := 0; level < maxTPLevels; level++ {
+		statuses = append(statuses, signalTPHit(level))
+	}
+	return append(statuses, SignalSLHit, SignalClosed, SignalDismissed, SignalExpired, SignalRejected)
+}
+
+// signalExpiryCheckInterval is how often unanswered signals are checked for expiry, short
+// enough for 15m signals to expire close to on time.
+const signalExpiryCheckInterval = time.Minute
+
+// signalTPHit returns the status for a fill of the TP at the zero-based level.
+func signalTPHit(level int) string {
+	return fmt.Sprintf("%s_hit", tpOrderTag(level))
+}
+
+// SignalEvent records when a signal reached a status.
+type SignalEvent struct {
+	ID       uint   `gorm:"primaryKey"`
+	SignalID string `gorm:"index;size:128"`
+	Status   string `gorm:"index;size:16"`
+	At       time.Time
+}
+
+// SetSignalStatus moves a stored signal to the status and records the transition.
+func SetSignalStatus(signalID, status string) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Signal{}).Where("signal_id = ?", signalID).Updates(Signal{Status: status, StatusAt: now})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update signal status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("signal %s is not stored", signalID)
+		}
+		if err := tx.Create(&SignalEvent{SignalID: signalID, Status: status, At: now}).Error; err != nil {
+			return fmt.Errorf("failed to record signal event: %w", err)
+		}
+		return nil
+	})
+}
+
+// recordSignalStatus saves the signal's current prices and moves it to the status, logging failures.
+func recordSignalStatus(signal *AlertMessage, status string) {
+	if err := StoreSignal(signal); err != nil {
+		reportError(telegramLog, ErrorSourceDatabase, "Failed to store signal", err, "signal_id", signal.SignalID, "symbol", signal.Symbol)
+		return
+	}
+	if err := SetSignalStatus(signal.SignalID, status); err != nil {
+		reportError(telegramLog, ErrorSourceDatabase, "Failed to set signal status", err, "signal_id", signal.SignalID, "status", status)
+	}
+}
+
+// recordOrderStatus moves the signal of a filled TP or SL order to the matching status.
+func recordOrderStatus(clientID string) {
+	signalID, tag, ok := orderSignalID(clientID)
+	if !ok {
+		return
+	}
+	status := SignalSLHit
+	if level, isTP := tpField(strings.ToUpper(tag)); isTP {
+		status = signalTPHit(level)
+	} else if tag != OrderTagSL {
+		return
+	}
+	if err := SetSignalStatus(signalID, status); err != nil {
+		binanceLog.Error("Failed to set signal status", "signal_id", signalID, "client_order_id", clientID, "status", status, "error", err)
+	}
+}
+
+// expireSignals marks signals left unanswered past their validity as expired. Stored signals
+// the bot no longer holds expire signalExpiry after their last update.
+func expireSignals() {
+	now := time.Now()
+	held := signalStore.Recent(func(signal *AlertMessage) bool {
+		return !signal.Confirmed && !signal.Dismissed && !signal.Expired && !signal.Filtered && now.After(signal.expiryTime())
+	})
+	for _, signal := range held {
+		expireSignal(signal)
+	}
+
+	var signals []Signal
+	err := db.Where("status IN ? AND status_at < ?", []string{SignalReceived, SignalEdited}, now.Add(-signalExpiry)).
+		Find(&signals).Error
+	if err != nil {
+		telegramLog.Error("Failed to find unanswered signals", "error", err)
+		return
+	}
+	for _, signal := range signals {
+		if _, exists := signalStore.Get(signal.SignalID); exists {
+			continue
+		}
+		if err := SetSignalStatus(signal.SignalID, SignalExpired); err != nil {
+			telegramLog.Error("Failed to expire signal", "signal_id", signal.SignalID, "error", err)
+		}
+	}
+}
+
+var signalExpiryStart sync.Once
+
+// startSignalExpiry expires unanswered signals every signalExpiryCheckInterval.
+func startSignalExpiry() {
+	signalExpiryStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(signalExpiryCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				expireSignals()
+			}
+		}()
+	})
+}
+
+// migrateSignalStatus adds the status columns and events table. Signals stored before statuses
+// were tracked are marked executed, since they were only stored once their trade was placed.
+func migrateSignalStatus(tx *gorm.DB) error {
+	type signal struct {
+		ChatID   int64
+		Symbol   string
+		Status   string `gorm:"index;size:16"`
+		StatusAt time.Time
+	}
+	type signalEvent struct {
+		ID       uint   `gorm:"primaryKey"`
+		SignalID string `gorm:"index;size:128"`
+		Status   string `gorm:"index;size:16"`
+		At       time.Time
+	}
+	if err := tx.Table("signals").AutoMigrate(&signal{}); err != nil {
+		return err
+	}
+	if err := tx.Table("signal_events").AutoMigrate(&signalEvent{}); err != nil {
+		return err
+	}
+	err := tx.Table("signals").Where("status IS NULL OR status = ''").
+		Updates(map[string]interface{}{"status": SignalExecuted, "status_at": gorm.Expr("?", clause.Column{Name: "timestamp"})}).Error
+	if err != nil {
+		return fmt.Errorf("failed to backfill signal status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionSignals is the callback action for opening stored signals listed by /signals.
+const ActionSignals = "sigs"
+
+// maxSignalButtons limits the signals in a /signals list that get a Show button.
+const maxSignalButtons = 10
+
+// Pending returns the chat's signals that are neither confirmed, dismissed nor expired, newest first.
+func (s *SignalStore) Pending(chatID int64) []*AlertMessage {
+	s.RLock()
+	defer s.RUnlock()
+
+	var signals []*AlertMessage
+	for _, signal := range s.signals {
+		if signal.ChatID == chatID && !signal.Confirmed && !signal.Dismissed && !signal.Expired {
+			signals = append(signals, signal)
+		}
+	}
+	sort.Slice(signals, func(i, j int) bool { return signals[i].ReceivedAt.After(signals[j].ReceivedAt) })
+	return signals
+}
+
+// handleSignalsCommand lists the chat's pending signals with buttons to post them again, so a
+// signal buried in the chat history can still be acted on. "/signals filtered" lists only the
+// signals the watchlist or a signal filter kept back.
+func handleSignalsCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	onlyFiltered := false
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+	case "filtered":
+		onlyFiltered = true
+	default:
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /signals [filtered]")))
+		return
+	}
+
+	var signals []*AlertMessage
+	for _, signal := range signalStore.Pending(chatID) {
+		if !onlyFiltered || signal.Filtered {
+			signals = append(signals, signal)
+		}
+	}
+	if len(signals) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "No pending signals.")))
+		return
+	}
+
+	text := tr(chatID, "<b>Pending signals: %d</b>\n\n", len(signals))
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, signal := range signals {
+		text += fmt.Sprintf("%s %s @ %s | %s", tr(chatID, signal.SignalType), signal.Symbol,
+			formatFloat(signal.EntryPrice), formatSignalTime(chatID, signal.Time))
+		if signal.RejectedBy != "" {
+			text += tr(chatID, " (rejected by %s)", html.EscapeString(signal.RejectedBy))
+		} else if signal.Filtered {
+			text += tr(chatID, " (not on watchlist)")
+		}
+		text += "\n"
+		if len(keyboard) < maxSignalButtons {
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Show %s %s", signal.Symbol, tr(chatID, signal.SignalType)),
+					fmt.Sprintf("%s|show|%s", ActionSignals, signal.SignalID)),
+			))
+		}
+	}
+	if len(signals) > maxSignalButtons {
+		text += tr(chatID, "\nButtons are shown for the %d newest signals.", maxSignalButtons)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleSignalsCallback handles "sigs|show|ID" from the /signals list.
+func handleSignalsCallback(chatID int64, parts []string) {
+	if len(parts) < 2 || parts[0] != "show" {
+		telegramLog.Warn("Invalid signals callback data", "data", parts)
+		return
+	}
+	showStoredSignal(chatID, parts[1])
+}
+
+// showStoredSignal posts a stored signal to the chat again, with its keyboard while it is still
+// open. Edits then go to the new message. Broadcast signals in the main chat stay without a keyboard.
+func showStoredSignal(chatID int64, signalID string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists || signal.ChatID != chatID {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, constructSignalMessageText(signal))
+	msg.ParseMode = "HTML"
+	config := GetGlobalConfig()
+	broadcast := config.BroadcastToTraders && chatID == config.TelegramChatID
+	if !signal.Confirmed && !signal.Dismissed && !signal.Expired && !broadcast {
+		msg.ReplyMarkup = createSignalInlineKeyboard(chatID, signalID)
+	}
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send stored signal", "signal_id", signalID, "chat_id", chatID, "error", err)
+		return
+	}
+	messageStore.Set(signalID, sent.MessageID)
+}
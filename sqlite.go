@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sqliteBusyTimeout is how long a SQLite connection waits for a lock held by another process,
+// such as a backup tool, before failing with "database is locked".
+const sqliteBusyTimeout = 10 * time.Second
+
+// sqliteReaders is how many connections serve SQLite reads alongside the single writer.
+const sqliteReaders = 4
+
+// sqliteReadPool serves reads outside transactions; nil for in-memory databases and other drivers.
+var sqliteReadPool *sql.DB
+
+// sqliteDSN adds connection parameters to a SQLite DSN, keeping any the DSN sets itself.
+func sqliteDSN(dsn string, params map[string]string) string {
+	base, query, _ := strings.Cut(dsn, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return dsn
+	}
+	for key, value := range params {
+		if !values.Has(key) {
+			values.Set(key, value)
+		}
+	}
+	return base + "?" + values.Encode()
+}
+
+// sqliteWriterDSN returns the DSN of the write connection. WAL lets readers run while a write
+// is in progress, and immediate transactions take the write lock up front instead of failing
+// when a read inside them has to be upgraded.
+func sqliteWriterDSN(dsn string) string {
+	return sqliteDSN(dsn, map[string]string{
+		"_journal_mode": "WAL",
+		"_synchronous":  "NORMAL",
+		"_busy_timeout": fmt.Sprint(sqliteBusyTimeout.Milliseconds()),
+		"_txlock":       "immediate",
+	})
+}
+
+// configureSQLite queues the bot's writes on one connection, so webhook bursts and Telegram
+// callbacks wait their turn instead of failing with "database is locked", and sends reads
+// outside transactions to a pool of read-only connections so they don't wait behind writes.
+func configureSQLite(gormDB *gorm.DB, dsn string) error {
+	writer, err := gormDB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	// database/sql makes callers wait for the connection, which is the write queue
+	writer.SetMaxOpenConns(1)
+
+	// Every connection to an in-memory database has its own database
+	if _, err := sqlitePath(dsn); err != nil {
+		return nil
+	}
+	readPool, err := sql.Open("sqlite3", sqliteDSN(dsn, map[string]string{
+		"_busy_timeout": fmt.Sprint(sqliteBusyTimeout.Milliseconds()),
+		"_query_only":   "true",
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to open read connections: %w", err)
+	}
+	readPool.SetMaxOpenConns(sqliteReaders)
+
+	routeRead := func(tx *gorm.DB) {
+		// Reads in a transaction must see its writes
+		if _, inTx := tx.Statement.ConnPool.(gorm.TxCommitter); inTx {
+			return
+		}
+		tx.Statement.ConnPool = readPool
+	}
+	if err := gormDB.Callback().Query().Before("gorm:query").Register("sqlite:read_pool", routeRead); err != nil {
+		readPool.Close()
+		return fmt.Errorf("failed to route reads: %w", err)
+	}
+	if err := gormDB.Callback().Row().Before("gorm:row").Register("sqlite:read_pool", routeRead); err != nil {
+		readPool.Close()
+		return fmt.Errorf("failed to route reads: %w", err)
+	}
+	sqliteReadPool = readPool
+	return nil
+}
+
+// closeDatabase closes the database connections.
+func closeDatabase() {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	if sqliteReadPool != nil {
+		sqliteReadPool.Close()
+		sqliteReadPool = nil
+	}
+}
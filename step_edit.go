@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionStep is the callback action for nudging a signal price with the +/- step buttons.
+const ActionStep = "step"
+
+// priceSteps are the step buttons, in tenths of a percent of the current value.
+var priceSteps = []int{-10, -1, 1, 10}
+
+// stepFields maps the short field codes used in step callback data to field names.
+// TPs use the lower-case field name as their code, e.g. "tp4".
+var stepFields = map[string]string{
+	"entry": "Entry Price",
+	"sl":    "SL",
+}
+
+// stepFieldCode returns the callback code for a field name, or "" if it has no step buttons.
+func stepFieldCode(fieldName string) string {
+	for code, name := range stepFields {
+		if name == fieldName {
+			return code
+		}
+	}
+	if _, ok := tpField(fieldName); ok {
+		return strings.ToLower(fieldName)
+	}
+	return ""
+}
+
+// stepFieldName returns the field name for a callback code, or "" if the code is unknown.
+func stepFieldName(code string) string {
+	if name, ok := stepFields[code]; ok {
+		return name
+	}
+	if _, ok := tpField(strings.ToUpper(code)); ok {
+		return strings.ToUpper(code)
+	}
+	return ""
+}
+
+// signalPrice returns a pointer to the signal's price for the field name, or nil if there is none.
+func signalPrice(signal *AlertMessage, fieldName string) *float64 {
+	switch fieldName {
+	case "Entry Price":
+		return &signal.EntryPrice
+	case "SL":
+		return &signal.SL
+	}
+	if level, ok := tpField(fieldName); ok && level < len(signal.TPs) {
+		return &signal.TPs[level]
+	}
+	return nil
+}
+
+// TickSizeStore caches symbol tick sizes so step buttons don't fetch exchange info on every press.
+type TickSizeStore struct {
+	sync.RWMutex
+	sizes map[string]float64
+}
+
+// NewTickSizeStore creates a new instance of TickSizeStore.
+func NewTickSizeStore() *TickSizeStore {
+	return &TickSizeStore{
+		sizes: make(map[string]float64),
+	}
+}
+
+func (s *TickSizeStore) Set(symbol string, tickSize float64) {
+	s.Lock()
+	defer s.Unlock()
+	s.sizes[symbol] = tickSize
+}
+
+func (s *TickSizeStore) Get(symbol string) (float64, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	tickSize, exists := s.sizes[symbol]
+	return tickSize, exists
+}
+
+var tickSizes = NewTickSizeStore()
+
+// tickSize returns the symbol's PRICE_FILTER tick size.
+func (b *BinanceClient) tickSize(symbol string) (float64, error) {
+	if tickSize, exists := tickSizes.Get(symbol); exists {
+		return tickSize, nil
+	}
+	symbolInfo, err := b.getSymbolInfo(context.Background(), symbol)
+	if err != nil {
+		return 0, err
+	}
+	tsStr, err := getFilterValue(symbolInfo.Filters, "PRICE_FILTER", "tickSize")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tick size: %v", err)
+	}
+	tickSize, err := strconv.ParseFloat(tsStr, 64)
+	if err != nil || tickSize <= 0 {
+		return 0, fmt.Errorf("failed to parse tick size: %s", tsStr)
+	}
+	tickSizes.Set(symbol, tickSize)
+	return tickSize, nil
+}
+
+// stepPrice moves price by permille/10 percent, rounded to the tick size. A step
+// smaller than one tick still moves the price by one tick. Without a tick size the result is
+// rounded to six decimals.
+func stepPrice(price float64, permille int, tickSize float64) float64 {
+	delta := price * float64(permille) / 1000
+	if tickSize <= 0 {
+		return roundToSixDecimal(price + delta)
+	}
+	ticks := math.Round(delta / tickSize)
+	if ticks == 0 {
+		ticks = math.Copysign(1, delta)
+	}
+	stepped := (math.Round(price/tickSize) + ticks) * tickSize
+	rounded, _ := strconv.ParseFloat(formatDecimal(stepped, tickSize), 64)
+	return rounded
+}
+
+// showStepKeyboard shows the signal with +/- step buttons for one of its prices.
+func showStepKeyboard(chatID int64, messageID int, signalID, fieldName string) {
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+	price := signalPrice(signal, fieldName)
+	if price == nil {
+		// The TP was removed by a settings change since the edit started
+		promptNewFieldValue(chatID, signalID, fieldName)
+		return
+	}
+	code := stepFieldCode(fieldName)
+
+	var steps []tgbotapi.InlineKeyboardButton
+	for _, permille := range priceSteps {
+		steps = append(steps, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%+g%%", float64(permille)/10),
+			fmt.Sprintf("%s|%s|%s|%d", ActionStep, signalID, code, permille)))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		steps,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Type Value"), fmt.Sprintf("%s|%s|type|%s", ActionStep, signalID, code)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Done"), fmt.Sprintf("%s|%s|done", ActionStep, signalID)),
+		),
+	)
+
+	text := constructSignalMessageText(signal)
+	text += tr(chatID, "\n\n<b>Adjusting %s:</b> %s", tr(chatID, fieldName), formatFloat(*price))
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = &keyboard
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
+
+// handleStepCallback handles "step|ID|<field>|<permille>" from the step buttons, along with
+// "step|ID|type|<field>" to type a value instead and "step|ID|done" to return to the signal keyboard.
+func handleStepCallback(chatID, userID int64, messageID int, parts []string) {
+	if len(parts) < 2 {
+		telegramLog.Warn("Invalid step callback data", "data", parts)
+		return
+	}
+	signalID := parts[0]
+	signal, exists := signalStore.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+
+	switch parts[1] {
+	case "done":
+		restoreSignalMessage(chatID, messageID, signalID)
+		return
+	case "type":
+		if len(parts) < 3 || stepFieldName(parts[2]) == "" {
+			telegramLog.Warn("Invalid step callback data", "signal_id", signalID, "data", parts)
+			return
+		}
+		promptNewFieldValue(chatID, signalID, stepFieldName(parts[2]))
+		return
+	}
+
+	fieldName := stepFieldName(parts[1])
+	if fieldName == "" || len(parts) < 3 {
+		telegramLog.Warn("Invalid step callback data", "signal_id", signalID, "data", parts)
+		return
+	}
+	permille, err := strconv.Atoi(parts[2])
+	if err != nil {
+		telegramLog.Warn("Invalid step callback data", "signal_id", signalID, "data", parts)
+		return
+	}
+	if signal.Confirmed || signal.Dismissed {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "This signal has already been handled.")))
+		return
+	}
+
+	price := signalPrice(signal, fieldName)
+	if price == nil || *price <= 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "%s is not set, type a value instead.", tr(chatID, fieldName))))
+		return
+	}
+
+	var tickSize float64
+	if client := mainBinanceClient(); client != nil {
+		if tickSize, err = client.tickSize(signal.Symbol); err != nil {
+			binanceLog.Error("Failed to get tick size", "signal_id", signalID, "symbol", signal.Symbol, "error", err)
+		}
+	}
+	oldValue := *price
+	*price = stepPrice(*price, permille, tickSize)
+	if fieldName == "Entry Price" {
+		signal.ManualEntryEdited = true
+		recalculateTPAndSL(signal, userSettings.Get(chatID))
+	}
+	auditFieldChange(userID, chatID, signal, fieldName, oldValue)
+	recordSignalStatus(signal, SignalEdited)
+
+	showStepKeyboard(chatID, messageID, signalID, fieldName)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// StopEntry is a stop entry waiting for the price to trigger it, with what is needed to
+// protect the position once it fills.
+type StopEntry struct {
+	Signal    AlertMessage // Copy of the signal as executed, with its recalculated TPs and SL
+	Settings  UserSettings // Copy of the settings used for the trade
+	Quantity  string
+	UserID    int64
+	Triggered bool
+	Protected bool // TP/SL orders were placed after the first fill
+}
+
+// stopEntryKey identifies the entry order of a stop entry on one account. Mirrored signals place
+// entries with the same client order ID on several accounts.
+type stopEntryKey struct {
+	Account  string
+	ClientID string
+}
+
+// StopEntryStore manages pending stop entries by account and the client order ID of their entry
+// order with concurrency safety.
+type StopEntryStore struct {
+	sync.Mutex
+	entries map[stopEntryKey]*StopEntry
+}
+
+// NewStopEntryStore creates a new instance of StopEntryStore.
+func NewStopEntryStore() *StopEntryStore {
+	return &StopEntryStore{
+		entries: make(map[stopEntryKey]*StopEntry),
+	}
+}
+
+func (s *StopEntryStore) Set(key stopEntryKey, entry *StopEntry) {
+	s.Lock()
+	defer s.Unlock()
+	s.entries[key] = entry
+}
+
+func (s *StopEntryStore) Delete(key stopEntryKey) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.entries, key)
+}
+
+// All returns a copy of every pending stop entry.
+func (s *StopEntryStore) All() map[stopEntryKey]StopEntry {
+	s.Lock()
+	defer s.Unlock()
+	entries := make(map[stopEntryKey]StopEntry, len(s.entries))
+	for key, entry := range s.entries {
+		entries[key] = *entry
+	}
+	return entries
+}
+
+// Mark records that a stop entry's order was triggered or filled, and returns a copy of the
+// entry with whether it was triggered and first filled by this update.
+func (s *StopEntryStore) Mark(key stopEntryKey, triggered, filled bool) (entry StopEntry, firstTrigger, firstFill, exists bool) {
+	s.Lock()
+	defer s.Unlock()
+	pending, exists := s.entries[key]
+	if !exists {
+		return StopEntry{}, false, false, false
+	}
+	triggered = triggered || filled
+	firstTrigger = triggered && !pending.Triggered
+	firstFill = filled && !pending.Protected
+	pending.Triggered = pending.Triggered || triggered
+	pending.Protected = pending.Protected || filled
+	return *pending, firstTrigger, firstFill, true
+}
+
+var stopEntries = NewStopEntryStore()
+
+// stopLimitPrice returns the limit price of a stop-limit entry offsetPct percent beyond its
+// trigger, so it still fills if the price jumps past the trigger, or 0 for a stop-market entry.
+func stopLimitPrice(side futures.SideType, stopPrice, offsetPct float64) float64 {
+	if offsetPct <= 0 {
+		return 0
+	}
+	if side == futures.SideTypeSell {
+		return stopPrice * (1 - offsetPct/100)
+	}
+	return stopPrice * (1 + offsetPct/100)
+}
+
+// checkStopTrigger refuses a stop entry the price has already crossed, which Binance would
+// trigger on the spot: a buy stop must be above the mark price and a sell stop below it.
+func (b *BinanceClient) checkStopTrigger(ctx context.Context, symbol string, side futures.SideType, stopPrice float64) error {
+	markPrice, err := b.getMarkPrice(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get mark price: %v", err)
+	}
+	if side == futures.SideTypeBuy && markPrice >= stopPrice {
+		return newTradeGuardError("The stop entry at %s for %s is already crossed: the mark price is %s. Confirm it as a market or limit entry instead.",
+			formatFloat(stopPrice), symbol, formatFloat(markPrice))
+	}
+	if side == futures.SideTypeSell && markPrice <= stopPrice {
+		return newTradeGuardError("The stop entry at %s for %s is already crossed: the mark price is %s. Confirm it as a market or limit entry instead.",
+			formatFloat(stopPrice), symbol, formatFloat(markPrice))
+	}
+	return nil
+}
+
+// handleStopEntryUpdate follows the order of a pending stop entry on the user data stream. It
+// tells the user when the stop is triggered, places the TP/SL orders and DCA ladder once the
+// entry first fills, and forgets the entry once it is filled or cancelled.
+func (b *BinanceClient) handleStopEntryUpdate(update futures.WsOrderTradeUpdate, userID int64) {
+	key := stopEntryKey{Account: b.Account, ClientID: update.ClientOrderID}
+	switch update.Status {
+	case futures.OrderStatusTypeCanceled, futures.OrderStatusTypeExpired, futures.OrderStatusTypeRejected:
+		if _, _, _, exists := stopEntries.Mark(key, false, false); exists {
+			stopEntries.Delete(key)
+			b.sendMessageToUser(userID, tr(userID, "Stop entry for %s was %s before it filled.", update.Symbol, tr(userID, strings.ToLower(string(update.Status)))))
+		}
+		return
+	}
+
+	// A triggered stop order becomes a market or limit order
+	triggered := update.OriginalType != "" && update.Type != update.OriginalType
+	filled := update.Status == futures.OrderStatusTypePartiallyFilled || update.Status == futures.OrderStatusTypeFilled
+	entry, firstTrigger, firstFill, exists := stopEntries.Mark(key, triggered, filled)
+	if !exists {
+		return
+	}
+	if update.Status == futures.OrderStatusTypeFilled {
+		stopEntries.Delete(key)
+	}
+	if firstTrigger {
+		stopPrice, _ := strconv.ParseFloat(update.StopPrice, 64)
+		b.sendMessageToUser(userID, tr(userID, "Stop entry triggered for %s at %s.", update.Symbol, formatFloat(stopPrice)))
+	}
+	if firstFill {
+		// Placing orders waits on the REST API, which must not hold up the stream
+		b.safeGo("protectStopEntry", func() {
+			b.protectStopEntry(&entry)
+		})
+	}
+}
+
+// protectStopEntry places the TP/SL orders and DCA ladder of a stop entry that has filled, as
+// ExecuteTrade does right after a market entry.
+func (b *BinanceClient) protectStopEntry(entry *StopEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	signal, settings, userID := &entry.Signal, &entry.Settings, entry.UserID
+	symbol, side := signal.Symbol, signalSide(signal)
+	ctx := context.Background()
+	if signalTP(signal, 0) != 0 || (settings.UseSL && signal.SL > 0) {
+		protected := b.replaceEarlierProtection(ctx, signal, entry.Quantity, userID)
+		if err := b.placeOCOOrder(ctx, symbol, side, protected, signal, settings); err != nil {
+			b.sendMessageToUser(userID, tr(userID, "Failed to place TPs/SL for %s: %v", symbol, err))
+			return
+		}
+		b.sendMessageToUser(userID, tr(userID, "TP/SL orders placed for %s.", symbol))
+	}
+	if settings.DCAEnabled {
+		if err := b.placeDCALadder(ctx, symbol, side, entry.Quantity, signal, settings, userID); err != nil {
+			b.sendMessageToUser(userID, tr(userID, "Failed to place DCA ladder for %s: %v", symbol, err))
+		}
+	}
+}
+
+// reconcileStopEntries follows up the stop entries of the account that were restored after a
+// restart and whose entry order is no longer open. An entry that filled while the bot was down
+// gets its TP/SL orders now, and one without a position was cancelled or expired and is
+// forgotten. It returns the number of entries that filled.
+func (b *BinanceClient) reconcileStopEntries(openOrders map[string]bool, openPositions map[string]*futures.PositionRisk) int {
+	filled := 0
+	for key, entry := range stopEntries.All() {
+		if key.Account != b.Account || openOrders[key.ClientID] {
+			continue
+		}
+		stopEntries.Delete(key)
+		symbol := entry.Signal.Symbol
+		position, open := openPositions[symbol]
+		if entry.Protected || !open {
+			binanceLog.Info("Forgetting stop entry that is no longer open", "signal_id", entry.Signal.SignalID, "symbol", symbol)
+			continue
+		}
+		if _, exists := positionTracker.Get(b.key(symbol)); !exists {
+			price, _ := strconv.ParseFloat(position.EntryPrice, 64)
+			positionTracker.Set(b.key(symbol), &TrackedPosition{
+				SignalID:   entry.Signal.SignalID,
+				Symbol:     symbol,
+				Side:       signalSide(&entry.Signal),
+				EntryPrice: price,
+				Opened:     true,
+			})
+		}
+		filled++
+		b.sendMessageToUser(entry.UserID, tr(entry.UserID, "Stop entry for %s filled while the bot was offline.", symbol))
+		b.safeGo("protectStopEntry", func() {
+			b.protectStopEntry(&entry)
+		})
+	}
+	return filled
+}
+
+// stopEntryClient returns the client of an account with restored stop entries.
+func stopEntryClient(account string, userID int64) (*BinanceClient, error) {
+	if strings.HasPrefix(account, personalAccountName+":") {
+		client, err := userClient(userID)
+		if err == nil && client == nil {
+			err = errors.New("the user's account is no longer connected")
+		}
+		return client, err
+	}
+	return accountClient(account)
+}
+
+// resumeStopEntries reconciles the accounts other than the main one that have stop entries
+// restored after a restart, so their order monitors follow the entries again. The main account
+// is reconciled at startup anyway.
+func resumeStopEntries() {
+	users := make(map[string]int64)
+	for key, entry := range stopEntries.All() {
+		if key.Account != defaultAccountName {
+			users[key.Account] = entry.UserID
+		}
+	}
+	for account, userID := range users {
+		client, err := stopEntryClient(account, userID)
+		if err != nil {
+			binanceLog.Warn("Stop entries restored for an unavailable account won't get their TP/SL", "account", account, "error", err)
+			continue
+		}
+		client.safeGo("reconcileOpenPositions", func() {
+			if err := client.reconcileOpenPositions(userID); err != nil {
+				binanceLog.Error("Failed to reconcile open positions", "account", account, "error", err)
+			}
+		})
+	}
+}
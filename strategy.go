@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// signalStrategy returns the strategy a signal's results are attributed to: its strategy tag,
+// or its source if it has none.
+func signalStrategy(alert *AlertMessage) string {
+	if alert.Strategy != "" {
+		return alert.Strategy
+	}
+	return alert.Source
+}
+
+// tradeStrategy returns the strategy of a stored signal, or "" if it is not stored.
+func tradeStrategy(signalID string) string {
+	if signalID == "" {
+		return ""
+	}
+	signal, err := GetSignal(signalID)
+	if err != nil {
+		return ""
+	}
+	return signal.Strategy
+}
+
+// groupTradesByStrategy splits trades by strategy and returns the strategies in name order,
+// with untagged trades last under "".
+func groupTradesByStrategy(trades []Trade) ([]string, map[string][]Trade) {
+	groups := make(map[string][]Trade)
+	for _, trade := range trades {
+		groups[trade.Strategy] = append(groups[trade.Strategy], trade)
+	}
+	strategies := make([]string, 0, len(groups))
+	for strategy := range groups {
+		if strategy != "" {
+			strategies = append(strategies, strategy)
+		}
+	}
+	sort.Strings(strategies)
+	if _, untagged := groups[""]; untagged {
+		strategies = append(strategies, "")
+	}
+	return strategies, groups
+}
+
+// formatStrategyBreakdown renders each strategy's trades, win rate and net profit, or "" if
+// none of the trades have a strategy.
+func formatStrategyBreakdown(chatID int64, trades []Trade) string {
+	strategies, groups := groupTradesByStrategy(trades)
+	if len(strategies) == 0 || (len(strategies) == 1 && strategies[0] == "") {
+		return ""
+	}
+
+	text := tr(chatID, "\nBy Strategy:\n")
+	for _, strategy := range strategies {
+		data := calculatePerformanceMetrics(groups[strategy])
+		name := strategy
+		if name == "" {
+			name = tr(chatID, "Untagged")
+		}
+		text += fmt.Sprintf("%s: ", name) + formatGroupResult(chatID, data)
+	}
+	return text
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// signalExpiry is how long a signal without a timeframe or source period may go unanswered
+// before it expires.
+const signalExpiry = 4 * time.Hour
+
+// summaryCheckInterval is how often the scheduler checks whether a summary is due.
+const summaryCheckInterval = time.Minute
+
+// SignalSummary counts a chat's signals and closed trades over a period.
+type SignalSummary struct {
+	Received     int
+	Confirmed    int
+	Dismissed    int
+	Expired      int // Unanswered for longer than signalExpiry
+	Pending      int
+	TradesClosed int
+	NetProfit    float64
+}
+
+// ReceivedBetween returns the signals received in [from, to), including traders' private copies.
+func (s *SignalStore) ReceivedBetween(from, to time.Time) []*AlertMessage {
+	s.RLock()
+	defer s.RUnlock()
+
+	var signals []*AlertMessage
+	for _, signal := range s.signals {
+		if !signal.ReceivedAt.Before(from) && signal.ReceivedAt.Before(to) {
+			signals = append(signals, signal)
+		}
+	}
+	return signals
+}
+
+// summarizeSignals counts the chat's signals received in [from, to) and the trades closed then.
+// A broadcast signal counts as confirmed if any trader confirmed their private copy, and as
+// dismissed if none did but one dismissed it.
+func summarizeSignals(chatID int64, from, to time.Time) (*SignalSummary, error) {
+	signals := signalStore.ReceivedBetween(from, to)
+	confirmedCopies := make(map[string]bool)
+	dismissedCopies := make(map[string]bool)
+	for _, signal := range signals {
+		i := strings.LastIndex(signal.SignalID, "_")
+		if signal.ChatID == chatID || i < 0 {
+			continue
+		}
+		original := signal.SignalID[:i]
+		confirmedCopies[original] = confirmedCopies[original] || signal.Confirmed
+		dismissedCopies[original] = dismissedCopies[original] || signal.Dismissed
+	}
+
+	summary := &SignalSummary{}
+	for _, signal := range signals {
+		if signal.ChatID != chatID {
+			continue
+		}
+		summary.Received++
+		switch {
+		case signal.Confirmed || confirmedCopies[signal.SignalID]:
+			summary.Confirmed++
+		case signal.Dismissed || dismissedCopies[signal.SignalID]:
+			summary.Dismissed++
+		case signal.Expired || to.After(signal.expiryTime()):
+			summary.Expired++
+		default:
+			summary.Pending++
+		}
+	}
+
+	trades, err := GetTradesBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	summary.TradesClosed = len(trades)
+	for _, trade := range trades {
+		summary.NetProfit += trade.Profit
+	}
+	return summary, nil
+}
+
+// sendSummary sends the chat a summary of the last day or week.
+func sendSummary(chatID int64, period string) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -1)
+	title := tr(chatID, "Daily Summary")
+	if period == "week" {
+		from = to.AddDate(0, 0, -7)
+		title = tr(chatID, "Weekly Summary")
+	}
+
+	summary, err := summarizeSignals(chatID, from, to)
+	if err != nil {
+		telegramLog.Error("Failed to build summary", "period", period, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to build the summary.")))
+		return
+	}
+
+	text := fmt.Sprintf("<b>%s</b>\n", title)
+	text += tr(chatID, "From %s to %s\n", formatUserTime(chatID, from), formatUserTime(chatID, to))
+	text += tr(chatID, "\nSignals received: %d\nConfirmed: %d\nDismissed: %d\nExpired: %d\nPending: %d\n\nTrades closed: %d\nNet PnL: %.2f USDT",
+		summary.Received, summary.Confirmed, summary.Dismissed, summary.Expired, summary.Pending,
+		summary.TradesClosed, summary.NetProfit)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send summary", "chat_id", chatID, "period", period, "error", err)
+	}
+	// Only the signal chat's summaries are the team's
+	if chatID == GetGlobalConfig().TelegramChatID {
+		postToDiscord(text)
+	}
+}
+
+// handleSummaryCommand sends a summary on demand with "/summary" or "/summary week".
+func handleSummaryCommand(message *tgbotapi.Message) {
+	period := "day"
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "", "day", "daily":
+	case "week", "weekly":
+		period = "week"
+	default:
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, tr(message.Chat.ID, "Usage: /summary [day|week]")))
+		return
+	}
+	sendSummary(message.Chat.ID, period)
+}
+
+var summarySchedulerStart sync.Once
+
+// startSummaryScheduler sends the configured daily and weekly summaries to the signal chat at
+// SummaryHour in the chat's timezone. Weekly summaries go out on Mondays, and monthly reports
+// on the 1st.
+func startSummaryScheduler() {
+	summarySchedulerStart.Do(func() {
+		go func() {
+			var lastDaily, lastWeekly, lastMonthly string
+			ticker := time.NewTicker(summaryCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				config := GetGlobalConfig()
+				chatID := config.TelegramChatID
+				if bot == nil || chatID == 0 || (!config.DailySummary && !config.WeeklySummary && !config.MonthlyReport && config.ReportEmail == "") {
+					continue
+				}
+
+				now := time.Now().In(userLocation(chatID))
+				today := now.Format("2006-01-02")
+				if now.Hour() != config.SummaryHour {
+					continue
+				}
+				if config.DailySummary && lastDaily != today {
+					lastDaily = today
+					sendSummary(chatID, "day")
+				}
+				if config.WeeklySummary && now.Weekday() == time.Monday && lastWeekly != today {
+					lastWeekly = today
+					sendSummary(chatID, "week")
+				}
+				if (config.MonthlyReport || config.ReportEmail != "") && now.Day() == 1 && lastMonthly != today {
+					lastMonthly = today
+					sendScheduledMonthlyReport(config)
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Delays between retries of a failing Binance connection, doubling from the first up to the
+// most.
+const (
+	firstRetryDelay = 5 * time.Second
+	maxRetryDelay   = 5 * time.Minute
+)
+
+// nextRetryDelay doubles a retry delay, up to maxRetryDelay.
+func nextRetryDelay(delay time.Duration) time.Duration {
+	return min(delay*2, maxRetryDelay)
+}
+
+// waitToRetry waits for delay and returns true, or false if the client is closed or the bot
+// starts shutting down first.
+func (b *BinanceClient) waitToRetry(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-b.closed:
+		return false
+	case <-shuttingDown:
+		return false
+	}
+}
+
+// superviseAPIKey checks the client's API key, retrying until it works or the client is closed.
+// The first failure is reported to the alert chat; trades fail with Binance's error in the
+// meantime.
+func (b *BinanceClient) superviseAPIKey() {
+	delay := firstRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := b.testAPIKey()
+		if err == nil {
+			binanceLog.Info("Binance API key is valid and has required permissions")
+			return
+		}
+		if attempt == 1 {
+			reportError(binanceLog, ErrorSourceBinance, "Binance API key test failed", err)
+		} else {
+			binanceLog.Warn("Binance API key test failed", "attempt", attempt, "retry_in", delay, "error", err)
+		}
+		if !b.waitToRetry(delay) {
+			return
+		}
+		delay = nextRetryDelay(delay)
+	}
+}
+
+// superviseOrderMonitor keeps the user data stream monitor running, reconnecting after it fails
+// or panics, until the client is closed or the bot shuts down. A stream that stays up for
+// maxRetryDelay starts the delays over.
+func (b *BinanceClient) superviseOrderMonitor(userID int64) {
+	delay := firstRetryDelay
+	for {
+		started := time.Now()
+		err := b.runOrderMonitor(userID)
+		if err == nil {
+			b.monitorState.setConnected(false)
+			return
+		}
+		if time.Since(started) > maxRetryDelay {
+			delay = firstRetryDelay
+		}
+
+		// Report the stream dropping, not every failed attempt to reconnect
+		_, connected, _ := b.monitorState.Snapshot()
+		failures := b.monitorState.recordFailure()
+		if connected {
+			reportError(binanceLog, ErrorSourceWebSocket, "User stream disconnected", err, "user_id", userID)
+		} else {
+			binanceLog.Warn("Failed to connect user stream", "user_id", userID, "attempt", failures,
+				"retry_in", delay, "error", err)
+		}
+		if !b.waitToRetry(delay) {
+			return
+		}
+		delay = nextRetryDelay(delay)
+	}
+}
+
+// runOrderMonitor runs the monitor once, turning a panic into an error so it is restarted.
+func (b *BinanceClient) runOrderMonitor(userID int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			reportError(binanceLog, ErrorSourcePanic, "Panic in goroutine", err,
+				"goroutine", "monitorOrdersViaWebSocket", "stack", string(debug.Stack()))
+		}
+	}()
+	return b.monitorOrdersViaWebSocket(userID)
+}
+
+// exchangeWatches holds the cancel functions of the event streams watchExchangeEvents started.
+var exchangeWatches = struct {
+	sync.Mutex
+	cancels map[Exchange]context.CancelFunc
+}{cancels: make(map[Exchange]context.CancelFunc)}
+
+// watchExchangeEvents starts following an exchange's order updates for the user, unless it is
+// already followed, so they are told about fills and the signal's status is updated. It stops
+// when the bot shuts down or stopExchangeEvents is called.
+func watchExchangeEvents(exchange Exchange, userID int64) {
+	exchangeWatches.Lock()
+	defer exchangeWatches.Unlock()
+	if _, exists := exchangeWatches.cancels[exchange]; exists || !orderMonitors.Begin() {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	exchangeWatches.cancels[exchange] = cancel
+	go func() {
+		defer orderMonitors.End()
+		select {
+		case <-shuttingDown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go superviseExchangeEvents(ctx, exchange, userID)
+}
+
+// stopExchangeEvents stops following an exchange's order updates.
+func stopExchangeEvents(exchange Exchange) {
+	exchangeWatches.Lock()
+	defer exchangeWatches.Unlock()
+	if cancel, exists := exchangeWatches.cancels[exchange]; exists {
+		cancel()
+		delete(exchangeWatches.cancels, exchange)
+	}
+}
+
+// superviseExchangeEvents keeps an exchange's event stream running, reconnecting with the same
+// delays as the Binance order monitor, until ctx is done.
+func superviseExchangeEvents(ctx context.Context, exchange Exchange, userID int64) {
+	delay := firstRetryDelay
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+		err := runExchangeEvents(ctx, exchange, userID)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxRetryDelay {
+			delay = firstRetryDelay
+		}
+		if attempt == 1 {
+			reportError(binanceLog, ErrorSourceWebSocket, "Exchange event stream disconnected", err,
+				"exchange", exchange.Name(), "user_id", userID)
+		} else {
+			binanceLog.Warn("Failed to connect exchange event stream", "exchange", exchange.Name(), "user_id", userID,
+				"attempt", attempt, "retry_in", delay, "error", err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		delay = nextRetryDelay(delay)
+	}
+}
+
+// runExchangeEvents runs an exchange's event stream once, turning a panic into an error so it
+// is restarted.
+func runExchangeEvents(ctx context.Context, exchange Exchange, userID int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			reportError(binanceLog, ErrorSourcePanic, "Panic in goroutine", err,
+				"goroutine", "StreamEvents", "stack", string(debug.Stack()))
+		}
+	}()
+	return exchange.StreamEvents(ctx, func(event ExchangeEvent) {
+		if !event.Filled {
+			return
+		}
+		if _, _, ok := parseClientOrderID(event.ClientOrderID); !ok {
+			return
+		}
+		recordOrderStatus(event.ClientOrderID)
+		// Positions are only tracked on Binance, so the account doesn't matter
+		msg, ok := formatOrderFill(userID, positionKey{Symbol: event.Symbol}, event.ClientOrderID, event.Price, event.Quantity)
+		if !ok {
+			msg = tr(userID, "Order %s for %s has been filled on %s.", event.ClientOrderID, event.Symbol, exchange.Name())
+		}
+		sendTradeMessage(userID, msg)
+		postToDiscord(msg)
+		fireOrderFill(strings.ToLower(exchange.Name()), event.Symbol, event.ClientOrderID, event.Price, event.Quantity)
+	})
+}
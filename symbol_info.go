@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/delivery"
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// symbolInfoTTL is how long exchange info and leverage brackets are used before they are fetched
+// again. They rarely change, and Binance skips the requests when the request budget runs low, so
+// a trade's TP/SL orders don't depend on fetching them again.
+const symbolInfoTTL = time.Hour
+
+type cachedValue[T any] struct {
+	value     T
+	fetchedAt time.Time
+}
+
+// SymbolInfoCache keeps exchange details fetched from Binance for symbolInfoTTL, by host and what
+// they describe, with concurrency safety.
+type SymbolInfoCache[T any] struct {
+	sync.Mutex
+	values map[string]cachedValue[T]
+}
+
+// NewSymbolInfoCache creates a new instance of SymbolInfoCache.
+func NewSymbolInfoCache[T any]() *SymbolInfoCache[T] {
+	return &SymbolInfoCache[T]{
+		values: make(map[string]cachedValue[T]),
+	}
+}
+
+// Get returns the value cached for key, fetching it if it is missing or older than
+// symbolInfoTTL. If fetching fails, an older value is returned rather than the error.
+func (c *SymbolInfoCache[T]) Get(key string, fetch func() (T, error)) (T, error) {
+	c.Lock()
+	cached, exists := c.values[key]
+	c.Unlock()
+	if exists && time.Since(cached.fetchedAt) < symbolInfoTTL {
+		return cached.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		if exists {
+			binanceLog.Warn("Using exchange info fetched earlier", "key", key, "fetched_at", cached.fetchedAt, "error", err)
+			return cached.value, nil
+		}
+		return value, err
+	}
+	c.Lock()
+	c.values[key] = cachedValue[T]{value: value, fetchedAt: time.Now()}
+	c.Unlock()
+	return value, nil
+}
+
+var (
+	// futuresExchangeInfo and deliveryExchangeInfo are keyed by API URL.
+	futuresExchangeInfo  = NewSymbolInfoCache[*futures.ExchangeInfo]()
+	deliveryExchangeInfo = NewSymbolInfoCache[*delivery.ExchangeInfo]()
+	// leverageBrackets are keyed by API URL, account and symbol, since Binance may give an
+	// account brackets of its own.
+	leverageBrackets = NewSymbolInfoCache[[]*futures.LeverageBracket]()
+)
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// ActionOverride is the callback action for the symbol override submenu.
+const ActionOverride = "ovr"
+
+// SymbolOverride holds per-symbol trading parameters for a user.
+// Zero values fall back to the user's default settings.
+type SymbolOverride struct {
+	ID                 uint   `gorm:"primaryKey"`
+	UserID             int64  `gorm:"uniqueIndex:idx_override_user_symbol"`
+	Symbol             string `gorm:"uniqueIndex:idx_override_user_symbol;size:32"`
+	Leverage           int
+	MarginMode         string
+	AmountUSDT         float64
+	TPPercentages      []float64 `gorm:"serializer:json"` // Per TP level, in order
+	ManualSLPercentage float64
+}
+
+// overrideFields lists the editable override fields in menu order, with one TP percentage per level.
+func overrideFields(tpLevels int) []string {
+	fields := []string{"Leverage", "MarginMode", "AmountUSDT"}
+	for i := 1; i <= tpLevels; i++ {
+		fields = append(fields, fmt.Sprintf("TP%dPercentage", i))
+	}
+	return append(fields, "ManualSLPercentage")
+}
+
+// migrateOverrideTPs moves TP percentages from the TP1-TP3 columns used before TP levels were a
+// list into TPPercentages, then drops the old columns.
+func migrateOverrideTPs(tx *gorm.DB) error {
+	type symbolOverride struct {
+		ID            uint
+		TPPercentages []float64 `gorm:"serializer:json"`
+	}
+	migrator := tx.Table("symbol_overrides").Migrator()
+	if !migrator.HasColumn(&symbolOverride{}, "tp1_percentage") {
+		return nil
+	}
+
+	var rows []struct {
+		ID                                          uint
+		TP1Percentage, TP2Percentage, TP3Percentage float64
+	}
+	if err := tx.Table("symbol_overrides").Select("id, tp1_percentage, tp2_percentage, tp3_percentage").Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to read symbol override TPs: %w", err)
+	}
+	for _, row := range rows {
+		tps := []float64{row.TP1Percentage, row.TP2Percentage, row.TP3Percentage}
+		for len(tps) > 0 && tps[len(tps)-1] == 0 {
+			tps = tps[:len(tps)-1]
+		}
+		if len(tps) == 0 {
+			continue
+		}
+		if err := tx.Table("symbol_overrides").Model(&symbolOverride{ID: row.ID}).Updates(symbolOverride{TPPercentages: tps}).Error; err != nil {
+			return fmt.Errorf("failed to migrate symbol override TPs: %w", err)
+		}
+	}
+	for _, column := range []string{"tp1_percentage", "tp2_percentage", "tp3_percentage"} {
+		if err := migrator.DropColumn(&symbolOverride{}, column); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// GetSymbolOverride retrieves a user's override for a symbol, or nil if none exists.
+func GetSymbolOverride(userID int64, symbol string) (*SymbolOverride, error) {
+	var override SymbolOverride
+	err := db.Where("user_id = ? AND symbol = ?", userID, symbol).First(&override).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve symbol override: %w", err)
+	}
+	return &override, nil
+}
+
+// ListSymbolOverrides retrieves all overrides for a user ordered by symbol.
+func ListSymbolOverrides(userID int64) ([]SymbolOverride, error) {
+	var overrides []SymbolOverride
+	if err := db.Where("user_id = ?", userID).Order("symbol").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve symbol overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// SaveSymbolOverride creates or updates a symbol override.
+func SaveSymbolOverride(override *SymbolOverride) error {
+	if err := db.Save(override).Error; err != nil {
+		return fmt.Errorf("failed to save symbol override: %w", err)
+	}
+	return nil
+}
+
+// DeleteSymbolOverride removes a user's override for a symbol.
+func DeleteSymbolOverride(userID int64, symbol string) error {
+	if err := db.Where("user_id = ? AND symbol = ?", userID, symbol).Delete(&SymbolOverride{}).Error; err != nil {
+		return fmt.Errorf("failed to delete symbol override: %w", err)
+	}
+	return nil
+}
+
+// applySymbolOverride returns a copy of settings with the user's override for the symbol applied.
+func applySymbolOverride(userID int64, symbol string, settings *UserSettings) *UserSettings {
+	effective := *settings
+
+	override, err := GetSymbolOverride(userID, symbol)
+	if err != nil {
+		telegramLog.Error("Failed to load override", "symbol", symbol, "error", err)
+		return &effective
+	}
+	if override == nil {
+		return &effective
+	}
+
+	if override.Leverage > 0 {
+		effective.Leverage = override.Leverage
+	}
+	if override.MarginMode != "" {
+		effective.MarginMode = override.MarginMode
+	}
+	if override.AmountUSDT > 0 {
+		effective.AmountUSDT = override.AmountUSDT
+	}
+	effective.TPLevels = slices.Clone(settings.TPLevels)
+	for i, pct := range override.TPPercentages {
+		if pct > 0 && i < len(effective.TPLevels) {
+			effective.TPLevels[i].Percentage = pct
+		}
+	}
+	if override.ManualSLPercentage > 0 {
+		effective.ManualSLPercentage = override.ManualSLPercentage
+	}
+	return &effective
+}
+
+// handleOverrideCallback dispatches symbol override submenu callbacks.
+// Expected data: "ovr|list", "ovr|add", "ovr|show|SYMBOL", "ovr|edit|SYMBOL|Field",
+// "ovr|margin|SYMBOL|Mode" or "ovr|del|SYMBOL".
+func handleOverrideCallback(chatID int64, parts []string) {
+	command := parts[0]
+	var symbol, value string
+	if len(parts) > 1 {
+		symbol = parts[1]
+	}
+	if len(parts) > 2 {
+		value = parts[2]
+	}
+
+	switch command {
+	case "list":
+		showSymbolOverrides(chatID)
+	case "add":
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter the symbol to override (e.g., BTCUSDT)."))
+		sent, err := bot.Send(msg)
+		if err != nil {
+			telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+		}
+		trackMessage(sent, messageKindPrompt)
+		editingUsers.Set(chatID, &EditingState{SettingName: "OverrideSymbol"})
+	case "show":
+		showSymbolOverride(chatID, symbol)
+	case "edit":
+		if value == "MarginMode" {
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Cross"), fmt.Sprintf("%s|margin|%s|Cross", ActionOverride, symbol)),
+					tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Isolated"), fmt.Sprintf("%s|margin|%s|Isolated", ActionOverride, symbol)),
+					tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Default"), fmt.Sprintf("%s|margin|%s|", ActionOverride, symbol)),
+				),
+			)
+			msg := tgbotapi.NewMessage(chatID, tr(chatID, "Select Margin Mode for %s:", symbol))
+			msg.ReplyMarkup = keyboard
+			sent, err := bot.Send(msg)
+			if err != nil {
+				telegramLog.Error("Failed to send Margin Mode options", "chat_id", chatID, "error", err)
+			}
+			trackMessage(sent, messageKindMenu)
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter the new value for %s on %s (0 to use your default).", value, symbol))
+		sent, err := bot.Send(msg)
+		if err != nil {
+			telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+		}
+		trackMessage(sent, messageKindPrompt)
+		editingUsers.Set(chatID, &EditingState{SettingName: value, OverrideSymbol: symbol})
+	case "margin":
+		override := loadOrNewOverride(chatID, symbol)
+		override.MarginMode = value
+		if err := SaveSymbolOverride(override); err != nil {
+			telegramLog.Error("Failed to save override", "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to save symbol override.")))
+			return
+		}
+		showSymbolOverride(chatID, symbol)
+	case "del":
+		if err := DeleteSymbolOverride(chatID, symbol); err != nil {
+			telegramLog.Error("Failed to delete override", "chat_id", chatID, "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to delete symbol override.")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Override for %s has been removed.", symbol)))
+		showSymbolOverrides(chatID)
+	default:
+		telegramLog.Warn("Unknown override command", "command", command)
+	}
+}
+
+// loadOrNewOverride returns the user's override for a symbol, or a new empty one.
+func loadOrNewOverride(userID int64, symbol string) *SymbolOverride {
+	override, err := GetSymbolOverride(userID, symbol)
+	if err != nil {
+		telegramLog.Error("Failed to load override", "symbol", symbol, "error", err)
+	}
+	if override == nil {
+		override = &SymbolOverride{UserID: userID, Symbol: symbol}
+	}
+	return override
+}
+
+// showSymbolOverrides lists the user's symbol overrides with buttons to open or add one.
+func showSymbolOverrides(chatID int64) {
+	overrides, err := ListSymbolOverrides(chatID)
+	if err != nil {
+		telegramLog.Error("Failed to list overrides", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load symbol overrides.")))
+		return
+	}
+
+	text := tr(chatID, "<b>Symbol Overrides</b>\n\n")
+	if len(overrides) == 0 {
+		text += tr(chatID, "No overrides yet. Symbols use your default settings.")
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, o := range overrides {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(o.Symbol, fmt.Sprintf("%s|show|%s", ActionOverride, o.Symbol)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Add Override"), fmt.Sprintf("%s|add", ActionOverride)),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send overrides menu", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindMenu)
+}
+
+// showSymbolOverride displays one symbol's override values with edit buttons.
+func showSymbolOverride(chatID int64, symbol string) {
+	o := loadOrNewOverride(chatID, symbol)
+
+	orDefault := func(v float64) string {
+		if v == 0 {
+			return "default"
+		}
+		return formatFloat(v)
+	}
+	marginMode := o.MarginMode
+	if marginMode == "" {
+		marginMode = "default"
+	}
+
+	text := fmt.Sprintf(
+		"<b>Override for %s</b>\n\n"+
+			"<b>Leverage:</b> %s\n"+
+			"<b>Margin Mode:</b> %s\n"+
+			"<b>Amount (USDT):</b> %s\n",
+		symbol,
+		orDefault(float64(o.Leverage)),
+		marginMode,
+		orDefault(o.AmountUSDT),
+	)
+	// Overrides can be set for each of the user's TP levels
+	tpLevels := max(len(userSettings.Get(chatID).TPLevels), len(o.TPPercentages))
+	for i := 0; i < tpLevels; i++ {
+		var pct float64
+		if i < len(o.TPPercentages) {
+			pct = o.TPPercentages[i]
+		}
+		text += fmt.Sprintf("<b>TP%d Percentage:</b> %s\n", i+1, orDefault(pct))
+	}
+	text += fmt.Sprintf("<b>SL Percentage:</b> %s\n", orDefault(o.ManualSLPercentage))
+
+	overrideFields := overrideFields(tpLevels)
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(overrideFields); i += 2 {
+		row := []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(overrideFields[i], fmt.Sprintf("%s|edit|%s|%s", ActionOverride, symbol, overrideFields[i])),
+		}
+		if i+1 < len(overrideFields) {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(overrideFields[i+1],
+				fmt.Sprintf("%s|edit|%s|%s", ActionOverride, symbol, overrideFields[i+1])))
+		}
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Delete Override"), fmt.Sprintf("%s|del|%s", ActionOverride, symbol)),
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Back"), fmt.Sprintf("%s|list", ActionOverride)),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send override menu", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindMenu)
+}
+
+// handleNewOverrideValue stores a typed value for a symbol override field.
+func handleNewOverrideValue(message *tgbotapi.Message, editingState *EditingState) {
+	chatID := message.Chat.ID
+	text := strings.TrimSpace(message.Text)
+
+	// The first step of "Add Override" asks for the symbol itself
+	if editingState.SettingName == "OverrideSymbol" {
+		symbol := strings.ToUpper(sanitizeSignalID(text))
+		if symbol == "" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid symbol.")))
+			return
+		}
+		showSymbolOverride(chatID, symbol)
+		return
+	}
+
+	symbol := editingState.OverrideSymbol
+	override := loadOrNewOverride(chatID, symbol)
+
+	switch editingState.SettingName {
+	case "Leverage":
+		val, err := strconv.Atoi(text)
+		if err != nil || val < 0 || val > 125 {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid leverage value. Enter an integer up to 125, or 0 for default.")))
+			return
+		}
+		override.Leverage = val
+	case "AmountUSDT", "ManualSLPercentage":
+		val, err := strconv.ParseFloat(text, 64)
+		if err != nil || val < 0 || val > 1000000 {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid value. Enter a positive number, or 0 for default.")))
+			return
+		}
+		if editingState.SettingName == "AmountUSDT" {
+			override.AmountUSDT = val
+		} else {
+			override.ManualSLPercentage = val
+		}
+	default:
+		level, kind, ok := tpLevelSetting(editingState.SettingName)
+		if !ok || kind != "Percentage" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Unknown override field.")))
+			return
+		}
+		val, err := strconv.ParseFloat(text, 64)
+		if err != nil || val < 0 || val > 1000000 {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid value. Enter a positive number, or 0 for default.")))
+			return
+		}
+		tps := slices.Clone(override.TPPercentages)
+		for len(tps) <= level {
+			tps = append(tps, 0)
+		}
+		tps[level] = val
+		for len(tps) > 0 && tps[len(tps)-1] == 0 {
+			tps = tps[:len(tps)-1]
+		}
+		override.TPPercentages = tps
+	}
+
+	if err := SaveSymbolOverride(override); err != nil {
+		telegramLog.Error("Failed to save override", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to save symbol override.")))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "%s for %s has been updated.", editingState.SettingName, symbol)))
+	showSymbolOverride(chatID, symbol)
+}
@@ -1,19 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"html"
 	"math"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/adshao/go-binance/v2/futures"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Global variables
-var binanceClient *BinanceClient
 var bot *tgbotapi.BotAPI
 
 // Synchronization primitives
@@ -40,35 +44,67 @@ const (
 
 // EditingState represents the state of a user editing a signal or settings.
 type EditingState struct {
-	SignalID    string
-	Field       string
-	SettingName string
+	SignalID         string
+	Field            string
+	SettingName      string
+	OverrideSymbol   string // Set when editing a per-symbol override instead of a default setting
+	PendingAPIKey    string // API key held between the two steps of the /connect flow
+	PendingExchange  string // Exchange the /connect flow registers the key for
+	PendingSecret    string // TOTP secret held until the first code from /pin totp is checked
+	PendingPinChange string // /pin argument to carry out once the current trade code is checked
 }
 
 // UserSettings represents a user's settings for trading options.
 type UserSettings struct {
-	MarginMode                  string  // Cross or Isolated
-	Leverage                    int     // e.g., 5x
-	AssetMode                   string  // Multi or Single
-	TradingMode                 string  // Limit or Market
-	AmountUSDT                  float64 // Trading amount in USDT
-	UseSL                       bool    // Whether to use Stop Loss
-	AutoCalculateTPs            bool    // Whether to auto-calculate TPs/SL
-	TP1Percentage               float64 // +1% from entry for TP1 (example)
-	TP2Percentage               float64 // +3% from entry for TP2 (example)
-	TP3Percentage               float64 // +7% from entry for TP3 (example)
-	ManualSLPercentage          float64 // SL percentage for manual calculation
-	AutoSLPercentage            float64 // SL percentage for auto calculation
-	AutoTPPercentage            float64 // (Not strictly needed if we use TP1-3, but kept if you want a single "TP1" only calc)
-	MarketPriceTolerance        float64 // Tolerance for market price difference
-	TP1ClosePct                 float64
-	TP2ClosePct                 float64
-	TP3ClosePct                 float64
-	TP1Enabled                  bool
-	TP2Enabled                  bool
-	TP3Enabled                  bool
-	DynamicCalculationEnabled   bool // New field to enable/disable dynamic calculation
-	EnableToleranceInMarketMode bool // New field to enable/disable tolerance in Market mode
+	MarginMode                  string    // Cross or Isolated
+	Leverage                    int       // e.g., 5x
+	AssetMode                   string    // Multi or Single
+	TradingMode                 string    // Limit, Market or Stop
+	AmountUSDT                  float64   // Trading amount in USDT
+	UseSL                       bool      // Whether to use Stop Loss
+	AutoCalculateTPs            bool      // Whether to auto-calculate TPs/SL
+	TPLevels                    []TPLevel // TP distances and close percentages, in order; the close percentages add up to 100
+	ManualSLPercentage          float64   // SL percentage for manual calculation
+	AutoSLPercentage            float64   // SL percentage for auto calculation
+	AutoTPPercentage            float64   // Single TP percentage used instead of TPLevels when auto-calculating
+	MarketPriceTolerance        float64   // Tolerance for market price difference
+	DynamicCalculationEnabled   bool      // New field to enable/disable dynamic calculation
+	EnableToleranceInMarketMode bool      // New field to enable/disable tolerance in Market mode
+	FundingRateThreshold        float64   // Funding rate (%) against the position that triggers a warning
+	BlockOnHighFunding          bool      // Whether to block trades when funding exceeds the threshold
+	MaxBookShare                float64   // Largest share (%) of the top-of-book liquidity an order may take before a warning, 0 for off
+	MinQuoteVolume              float64   // 24h volume in USDT below which a symbol gets a warning, 0 for off
+	BlockOnThinLiquidity        bool      // Whether to block trades that get a liquidity warning
+	MaxSlippage                 float64   // Max fraction the book/mark price may move from entry before a market order is aborted
+	StopLimitOffset             float64   // Distance (%) beyond the trigger of a stop entry's limit price, 0 for stop-market entries
+	IcebergNotional             float64   // USDT above which market entries are split into iceberg slices of at most this size, 0 for off
+	MarketType                  string    // USDT-M or COIN-M
+	DCAEnabled                  bool      // Whether to place a DCA ladder after market entries
+	DCAStepPercentage           float64   // Distance (%) between DCA ladder levels, against the position
+	DCAMaxOrders                int       // Maximum number of DCA ladder orders per position
+	WorkingType                 string    // Price TP/SL orders trigger on: MARK_PRICE or CONTRACT_PRICE
+	AutoMarginEnabled           bool      // Whether to top up isolated positions nearing liquidation
+	AutoMarginThreshold         float64   // Margin ratio (%) that triggers a top-up
+	AutoMarginAmount            float64   // USDT added per top-up
+	ShowChart                   bool      // Whether to attach a candlestick chart to signal messages
+	Language                    string    // Bot language code, e.g. "en" or "es"
+	Timezone                    string    // IANA timezone for displayed times, e.g. "Europe/Madrid"
+	TimeFormat                  string    // Clock for displayed times: 24h or 12h
+	TwoStepConfirm              bool      // Whether Confirm shows a trade summary with Execute/Back before trading
+	QuietHours                  string    // Daily quiet period as START-END hours in Timezone, e.g. "22-7"; empty for none
+	QuietMode                   string    // Handling of signals while muted or in quiet hours: silent, digest or suppress
+	MutedUntil                  time.Time // Signal notifications are muted until this time (/mute)
+	TradingHours                string    // Weekly windows signals may be confirmed in, in Timezone, e.g. "Mon-Fri 08:00-20:00"; empty for any time
+	OutsideHoursMode            string    // Handling of signals outside the trading hours: info or dismiss
+	WatchlistOnly               bool      // Whether only signals for watched symbols are sent (/watch)
+	QuickActions                bool      // Whether the Settings/Positions/Performance/Balance reply keyboard is shown
+	CompactMessages             bool      // Whether signals are rendered in a few short lines without emoji
+	ShowIndicators              bool      // Whether signal messages show RSI, EMA(50/200) and ATR
+	ShowBenchmark               bool      // Whether performance reports compare the net profit with holding BTC and ETH
+	ATRSLMultiplier             float64   // ATR multiple the recalculated SL is placed at instead of the SL percentage, 0 for off
+	TrailAfterTP2               bool      // Whether the TPs after TP2 are replaced by a trailing stop once TP2 fills
+	TrailPercent                float64   // Percentage the trailing stop follows the price at
+	TrailATRMultiplier          float64   // ATR multiple the trailing stop follows the price at instead of TrailPercent, 0 for off
 }
 
 // UserSettingsStore manages user settings with concurrency safety.
@@ -109,26 +145,34 @@ func (s *UserSettingsStore) Get(userID int64) *UserSettings {
 			AmountUSDT:                  100,
 			UseSL:                       false,
 			AutoCalculateTPs:            false,
-			TP1Percentage:               0.75,
-			TP2Percentage:               1.5,
-			TP3Percentage:               2.0,
+			TPLevels:                    defaultTPLevels(),
 			ManualSLPercentage:          1.0,
 			AutoSLPercentage:            1.0,
 			AutoTPPercentage:            1.0,
 			MarketPriceTolerance:        0, // Set to 0 for Market mode
-			TP1ClosePct:                 60.0,
-			TP2ClosePct:                 20.0,
-			TP3ClosePct:                 20.0,
-			TP1Enabled:                  true,
-			TP2Enabled:                  true,
-			TP3Enabled:                  true,
 			DynamicCalculationEnabled:   true,
 			EnableToleranceInMarketMode: true, // Default to true
+			FundingRateThreshold:        0.05,
+			BlockOnHighFunding:          false,
+			MaxSlippage:                 0.01,
+			MarketType:                  MarketTypeUSDTM,
+			DCAEnabled:                  false,
+			DCAStepPercentage:           2.0,
+			DCAMaxOrders:                3,
+			WorkingType:                 string(futures.WorkingTypeMarkPrice),
+			AutoMarginEnabled:           false,
+			AutoMarginThreshold:         80,
+			AutoMarginAmount:            10,
+			ShowChart:                   true,
+			ShowIndicators:              true,
+			Language:                    LangEnglish,
+			Timezone:                    "UTC",
+			TimeFormat:                  TimeFormat24h,
+			QuietMode:                   QuietModeSilent,
+			OutsideHoursMode:            OutsideHoursInfo,
+			TrailPercent:                1.0,
 		}
 
-		// Initialize TP visibility based on close percentages
-		adjustTPClosePercentages(settings)
-
 		// Store the settings in the map
 		s.RUnlock()
 		s.Lock()
@@ -150,101 +194,63 @@ func (s *UserSettingsStore) Set(userID int64, settings *UserSettings) {
 		settings.MarketPriceTolerance = 0
 	}
 
-	// Validate and adjust TP percentages
-	if settings.TP1ClosePct > 100 {
-		settings.TP1ClosePct = 100
-	}
-	if settings.TP2ClosePct > 100 {
-		settings.TP2ClosePct = 100
-	}
-	if settings.TP3ClosePct > 100 {
-		settings.TP3ClosePct = 100
-	}
-
-	// Calculate total percentage
-	total := settings.TP1ClosePct + settings.TP2ClosePct + settings.TP3ClosePct
-
-	// If total exceeds 100%, adjust proportionally
-	if total > 100 {
-		ratio := 100 / total
-		settings.TP1ClosePct *= ratio
-		settings.TP2ClosePct *= ratio
-		settings.TP3ClosePct *= ratio
-	}
-
-	// Reset all TPs to visible first
-	settings.TP1Enabled = true // TP1 is always enabled
-	settings.TP2Enabled = true
-	settings.TP3Enabled = true
-
-	// If TP1 is 100% or more, disable other TPs
-	if settings.TP1ClosePct >= 100 {
-		settings.TP1ClosePct = 100
-		settings.TP2ClosePct = 0
-		settings.TP3ClosePct = 0
-		settings.TP2Enabled = false
-		settings.TP3Enabled = false
-	} else {
-		// Calculate remaining percentage after TP1
-		remainingPct := 100 - settings.TP1ClosePct
-
-		// Handle TP2
-		if settings.TP2ClosePct > remainingPct {
-			settings.TP2ClosePct = remainingPct
-			settings.TP3ClosePct = 0
-			settings.TP3Enabled = false
-		} else {
-			// Calculate remaining percentage after TP2
-			remainingPct -= settings.TP2ClosePct
-
-			// Handle TP3
-			if remainingPct <= 0 {
-				settings.TP3ClosePct = 0
-				settings.TP3Enabled = false
-			} else {
-				settings.TP3ClosePct = remainingPct
-				settings.TP3Enabled = true
-			}
-		}
-	}
-
-	// Update TP enabled states based on final percentages
-	settings.TP2Enabled = settings.TP2ClosePct > 0
-	settings.TP3Enabled = settings.TP3ClosePct > 0
+	// Keep the TP close percentages within 100%
+	adjustTPClosePercentages(settings)
 
 	// Store the validated settings
 	s.settings[userID] = settings
 
 	// Log the update
-	log.Printf("Updated settings for user %d: Mode=%s, TPs=[%.2f, %.2f, %.2f], Enabled=[%v, %v, %v]",
-		userID,
-		settings.TradingMode,
-		settings.TP1ClosePct,
-		settings.TP2ClosePct,
-		settings.TP3ClosePct,
-		settings.TP1Enabled,
-		settings.TP2Enabled,
-		settings.TP3Enabled)
+	telegramLog.Info("Updated settings", "user_id", userID, "trading_mode", settings.TradingMode, "tp_levels", settings.TPLevels)
+}
+
+// IDs returns the users and chats that have settings.
+func (s *UserSettingsStore) IDs() []int64 {
+	s.RLock()
+	defer s.RUnlock()
+	ids := make([]int64, 0, len(s.settings))
+	for id := range s.settings {
+		ids = append(ids, id)
+	}
+	return ids
 }
 
 // AlertMessage represents a trading signal or alert.
 type AlertMessage struct {
-	SignalID          string  `json:"signal_id"`
-	SignalType        string  `json:"signal"` // "Buy" or "Sell"
-	Symbol            string  `json:"symbol"`
-	Timeframe         string  `json:"timeframe"`
-	Time              string  `json:"time"`
-	EntryPrice        float64 `json:"entry_price"`
-	TP1               float64 `json:"tp1"`
-	TP2               float64 `json:"tp2"`
-	TP3               float64 `json:"tp3"`
-	SL                float64 `json:"sl"`
-	HighPrice         float64 `json:"high_price"`
-	LowPrice          float64 `json:"low_price"`
-	Midpoint          float64 `json:"midpoint"`
-	Confirmed         bool    `json:"confirmed"`
-	Dismissed         bool    `json:"dismissed"`
-	ManualEntryEdited bool    `json:"manual_entry_edited"`
+	SignalID          string            `json:"signal_id"`
+	SignalType        string            `json:"signal"` // "Buy" or "Sell"
+	Symbol            string            `json:"symbol"`
+	Timeframe         string            `json:"timeframe"`
+	Time              string            `json:"time"`
+	EntryPrice        float64           `json:"entry_price"`
+	TPs               []float64         `json:"tps"` // Take profits in order; alerts may also send them as tp1, tp2, ...
+	SL                float64           `json:"sl"`
+	HighPrice         float64           `json:"high_price"`
+	LowPrice          float64           `json:"low_price"`
+	Midpoint          float64           `json:"midpoint"`
+	Confirmed         bool              `json:"confirmed"`
+	Dismissed         bool              `json:"dismissed"`
+	ManualEntryEdited bool              `json:"manual_entry_edited"`
+	Source            string            `json:"source"`     // Optional signal source, used for account routing
+	Strategy          string            `json:"strategy"`   // Optional strategy tag for performance attribution, defaults to the source
+	OrderType         string            `json:"order_type"` // Optional entry order type: market, limit, midpoint or stop
+	FundingWarning    string            `json:"-"`          // Set when funding is expensive for the signal's direction
+	LiquidityWarning  string            `json:"-"`          // Set when the market is too thin for the order size
+	Account           string            `json:"-"`          // Account the signal will be executed on
+	Liquidation       *LiquidationInfo  `json:"-"`          // Used to estimate the liquidation price, nil if unavailable
+	ChatID            int64             `json:"-"`          // Chat the signal message was sent to
+	ReceivedAt        time.Time         `json:"-"`          // When the bot received the signal, for summaries
+	Filtered          bool              `json:"-"`          // Not sent because the symbol is not on the chat's watchlist, or a filter rejected it
+	FilterNotes       []string          `json:"-"`          // Notes of the signal filters it matched
+	RejectedBy        string            `json:"-"`          // Name of the signal filter that rejected it, if any
+	Indicators        *SignalIndicators `json:"-"`          // Technical context from recent candles, nil if unavailable
+	LeverageOverride  int               `json:"-"`          // Leverage picked for this signal only, 0 for the settings
+	AmountOverride    float64           `json:"-"`          // USDT amount picked for this signal only, 0 for the settings
+	Profile           string            `json:"-"`          // Settings profile picked for this signal, empty for current settings
+	InfoOnly          bool              `json:"-"`          // Arrived outside the chat's trading hours, so it can't be confirmed
+	NewsHalt          *EconomicEvent    `json:"-"`          // High-impact event trading was paused around when it arrived
+	ExpiresAt         time.Time         `json:"-"`          // When the signal expires if still unanswered
+	Expired           bool              `json:"-"`          // Went unanswered past ExpiresAt
 }
 
 // SignalStore manages signals with concurrency safety.
@@ -273,6 +279,12 @@ func (s *SignalStore) Get(signalID string) (*AlertMessage, bool) {
 	return alert, exists
 }
 
+func (s *SignalStore) Delete(signalID string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.signals, signalID)
+}
+
 func (s *SignalStore) GetLatestUnconfirmedSignals(limit int) []*AlertMessage {
 	s.RLock()
 	defer s.RUnlock()
@@ -329,10 +341,11 @@ func (e *EditingUsers) Delete(userID int64) {
 	delete(e.users, userID)
 }
 
-// MessageStore stores message IDs associated with signals.
+// MessageStore stores message IDs associated with signals and the bot messages tracked for cleanup.
 type MessageStore struct {
 	sync.RWMutex
 	messages map[string]int
+	tracked  []TrackedMessage
 }
 
 // NewMessageStore creates a new instance of MessageStore.
@@ -365,16 +378,25 @@ type PerformanceData struct {
 	AverageLoss   float64
 	TotalProfit   float64
 	TotalLoss     float64
+	TotalFees     float64
+	GrossProfit   float64 // Net profit before fees
 	NetProfit     float64
+	MaxDrawdown   float64 // Largest fall of the cumulative net profit below an earlier peak
+	ProfitFactor  float64 // Total profit over total loss, 0 without losses
+	Expectancy    float64 // Average net profit per trade
+	SharpeRatio   float64 // Per trade, not annualized
+	SortinoRatio  float64 // Per trade, not annualized
+	TotalR        float64 // Net profit in R of the trades with a known initial risk
+	AverageR      float64 // Average R-multiple of the trades with a known initial risk
+	RTrades       int     // Trades with a known initial risk
+	LosingStreak  int     // Most losing trades in a row
 }
 
 // initTelegramBot initializes the Telegram bot.
 func initTelegramBot(config *Config) (*tgbotapi.BotAPI, error) {
 	if bot != nil {
-		log.Println("Re-initializing Telegram bot with new configuration.")
+		telegramLog.Info("Re-initializing Telegram bot with new configuration")
 		stopTelegramListener()
-		// Recreate the BinanceClient with the existing bot
-		binanceClient = NewBinanceClient(bot)
 		bot = nil
 	}
 
@@ -388,10 +410,27 @@ func initTelegramBot(config *Config) (*tgbotapi.BotAPI, error) {
 		return nil, fmt.Errorf("failed to create Telegram bot: %v", err)
 	}
 	bot.Debug = false
-	log.Printf("Authorized on account %s", bot.Self.UserName)
+	telegramLog.Info("Authorized on account", "username", bot.Self.UserName)
 
-	binanceClient = NewBinanceClient(bot)
+	// Binance clients send their notifications with the new bot
+	setBinanceClient(NewBinanceClient(bot))
+	accountClients.Clear()
+	userClients.Clear()
 	startTelegramListener()
+	startSummaryScheduler()
+	startCleanupScheduler()
+	startPositionTickers()
+	startNewsCalendar()
+	registerBotCommands()
+
+	// Pick up positions and orders left open by a previous run
+	client := mainBinanceClient()
+	client.safeGo("reconcileOpenPositions", func() {
+		if err := client.reconcileOpenPositions(config.TelegramChatID); err != nil {
+			binanceLog.Error("Failed to reconcile open positions", "error", err)
+		}
+	})
+	go resumeStopEntries()
 
 	return bot, nil
 }
@@ -415,6 +454,8 @@ func startTelegramListener() {
 				handleCallbackQuery(update.CallbackQuery)
 			} else if update.Message != nil {
 				handleMessage(update.Message)
+			} else if update.InlineQuery != nil {
+				handleInlineQuery(update.InlineQuery)
 			}
 		}
 	}()
@@ -435,26 +476,50 @@ func handleMessage(message *tgbotapi.Message) {
 	chatID := message.Chat.ID
 	editingState, editing := editingUsers.Get(chatID)
 
-	if editing {
+	if handleTradeCode(message) {
+		// Code for a Confirm waiting on the user's PIN or authenticator
+		return
+	} else if editing && !hasRole(senderID(message), RoleTrader) {
+		// Only traders may answer prompts, even in a shared chat where one is open
+		return
+	} else if editing {
 		// If user is currently editing a signal field or setting
 		if editingState.Field != "" {
 			handleNewFieldValue(message, editingState)
 			editingUsers.Delete(chatID)
+		} else if editingState.SettingName == "ConnectAPIKey" || editingState.SettingName == "ConnectAPISecret" {
+			editingUsers.Delete(chatID)
+			handleConnectValue(message, editingState)
+		} else if editingState.SettingName == "TradePIN" || editingState.SettingName == "TradeTOTP" || editingState.SettingName == "TradeCodeCurrent" {
+			editingUsers.Delete(chatID)
+			handleTradeAuthValue(message, editingState)
+		} else if editingState.SettingName == "ProfileName" {
+			editingUsers.Delete(chatID)
+			handleNewProfileName(message)
+		} else if editingState.OverrideSymbol != "" || editingState.SettingName == "OverrideSymbol" {
+			editingUsers.Delete(chatID)
+			handleNewOverrideValue(message, editingState)
 		} else if editingState.SettingName != "" {
 			handleNewSettingValue(message, editingState)
 			editingUsers.Delete(chatID)
 		} else {
-			msg := tgbotapi.NewMessage(chatID, "Please select an option from the menu.")
+			msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please select an option from the menu."))
 			if _, err := bot.Send(msg); err != nil {
-				log.Printf("Failed to send message: %v", err)
+				telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 			}
 		}
 	} else if message.IsCommand() {
 		handleCommand(message)
+	} else if handleQuickAction(message) {
+		// Reply keyboard button, runs its command
+		return
+	} else if hasRole(senderID(message), RoleTrader) && handleSignalText(message) {
+		// Pasted or forwarded signal text, confirmed via the parsed signal prompt
+		return
 	} else {
-		msg := tgbotapi.NewMessage(chatID, "Please use the /settings commands to interact.")
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please use the /settings commands to interact."))
 		if _, err := bot.Send(msg); err != nil {
-			log.Printf("Failed to send message: %v", err)
+			telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 		}
 	}
 }
@@ -462,18 +527,81 @@ func handleMessage(message *tgbotapi.Message) {
 // handleCommand processes bot commands.
 func handleCommand(message *tgbotapi.Message) {
 	chatID := message.Chat.ID
+	if !commandAllowed(message) {
+		return
+	}
+
 	switch message.Command() {
 	case "start":
-		msg := tgbotapi.NewMessage(chatID, "Welcome! Use /settings to configure your trading options.")
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Welcome! Use /settings to configure your trading options.\n"+
+			"Use /connect in a private chat to trade signals on your own Binance account.\n"+
+			"Use /language to change the bot language."))
+		if userSettings.Get(chatID).QuickActions {
+			msg.ReplyMarkup = quickActionKeyboard(chatID)
+		}
 		if _, err := bot.Send(msg); err != nil {
-			log.Printf("Failed to send message: %v", err)
+			telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 		}
 	case "settings":
 		showSettingsMenu(chatID)
+	case "profiles":
+		showSettingsProfiles(chatID)
+	case "history":
+		handleHistoryCommand(message)
+	case "connect":
+		handleConnectCommand(message)
+	case "disconnect":
+		handleDisconnectCommand(message)
+	case "pin":
+		handlePinCommand(message)
+	case "role":
+		handleRoleCommand(message)
+	case "roles":
+		handleRolesCommand(message)
+	case "audit":
+		handleAuditCommand(message)
+	case "backup":
+		handleBackupCommand(message)
+	case "restore":
+		handleRestoreCommand(message)
+	case "import":
+		handleImportCommand(message)
+	case "panic":
+		handlePanicCommand(message)
+	case "language":
+		handleLanguageCommand(chatID)
+	case "mute":
+		handleMuteCommand(message)
+	case "summary":
+		handleSummaryCommand(message)
+	case "news":
+		handleNewsCommand(message)
+	case "price", "quote":
+		handleMarketCommand(message)
+	case "watch":
+		handleWatchCommand(message)
+	case "unwatch":
+		handleUnwatchCommand(message)
+	case "signals":
+		handleSignalsCommand(message)
+	case "positions":
+		handlePositionsCommand(message)
+	case "ticker":
+		handleTickerCommand(message)
+	case "balance":
+		handleBalanceCommand(message)
+	case "exposure":
+		handleExposureCommand(message)
+	case "performance":
+		showPerformanceOptions(chatID)
+	case "backtest":
+		handleBacktestCommand(message)
+	case "report":
+		handleReportCommand(message)
 	default:
-		msg := tgbotapi.NewMessage(chatID, "Unknown command.")
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Unknown command."))
 		if _, err := bot.Send(msg); err != nil {
-			log.Printf("Failed to send message: %v", err)
+			telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 		}
 	}
 }
@@ -498,9 +626,10 @@ func showSettingsMenu(chatID int64) {
 		toleranceEmoji = "\U00002705" // Green circle for true
 	}
 
-	// Here is the key fix: consolidate everything into a single format string
-	menuText := fmt.Sprintf(
+	menuText := tr(chatID,
 		"Your Current Settings:\n\n"+
+			"<b>Market Type:</b> %s\n"+
+			"<b>TP/SL Trigger Price:</b> %s\n"+
 			"<b>Margin Mode:</b> %s\n"+
 			"<b>Leverage:</b> %dx\n"+
 			"<b>Asset Mode:</b> %s\n"+
@@ -509,7 +638,14 @@ func showSettingsMenu(chatID int64) {
 			"<b>Use Stop Loss:</b> %t\n"+
 			"<b>Simplified TP/SL:</b> %s %t\n"+
 			"<b>Dynamic Calculation:</b> %s %t\n"+
-			"<b>Tolerance in Market Mode:</b> %s %t\n",
+			"<b>Tolerance in Market Mode:</b> %s %t\n"+
+			"<b>Funding Warning Threshold:</b> %.4f%%\n"+
+			"<b>Block on High Funding:</b> %t\n"+
+			"<b>DCA Ladder:</b> %t\n"+
+			"<b>Auto Margin Top-Up:</b> %t\n"+
+			"<b>Chart Snapshot:</b> %t\n",
+		settings.MarketType,
+		settings.WorkingType,
 		settings.MarginMode,
 		settings.Leverage,
 		settings.AssetMode,
@@ -522,17 +658,97 @@ func showSettingsMenu(chatID int64) {
 		settings.DynamicCalculationEnabled,
 		toleranceEmoji,
 		settings.EnableToleranceInMarketMode,
+		settings.FundingRateThreshold,
+		settings.BlockOnHighFunding,
+		settings.DCAEnabled,
+		settings.AutoMarginEnabled,
+		settings.ShowChart,
 	)
 
+	menuText += tr(chatID, "<b>Timezone:</b> %s (%s)\n", settings.Timezone, settings.TimeFormat)
+	menuText += tr(chatID, "<b>Two-Step Confirm:</b> %t\n", settings.TwoStepConfirm)
+	quietHours := settings.QuietHours
+	if quietHours == "" {
+		quietHours = tr(chatID, "off")
+	}
+	menuText += tr(chatID, "<b>Quiet Hours:</b> %s (%s)\n", quietHours, tr(chatID, settings.QuietMode))
+	tradingHours, outsideHours := settings.TradingHours, settings.OutsideHoursMode
+	if tradingHours == "" {
+		tradingHours = tr(chatID, "any time")
+	}
+	if outsideHours == "" {
+		outsideHours = OutsideHoursInfo
+	}
+	menuText += tr(chatID, "<b>Trading Hours:</b> %s (outside: %s)\n", tradingHours, tr(chatID, outsideHours))
+	menuText += tr(chatID, "<b>Watchlist Only:</b> %t\n", settings.WatchlistOnly)
+	menuText += tr(chatID, "<b>Quick Actions:</b> %t\n", settings.QuickActions)
+	menuText += tr(chatID, "<b>Compact Messages:</b> %t\n", settings.CompactMessages)
+	menuText += tr(chatID, "<b>Indicator Context:</b> %t\n", settings.ShowIndicators)
+	menuText += tr(chatID, "<b>Buy &amp; Hold Benchmark:</b> %t\n", settings.ShowBenchmark)
+	if settings.ATRSLMultiplier > 0 {
+		menuText += tr(chatID, "<b>ATR SL Multiplier:</b> %.2f\n", settings.ATRSLMultiplier)
+	} else {
+		menuText += tr(chatID, "<b>ATR SL Multiplier:</b> %s\n", tr(chatID, "off"))
+	}
+	if settings.TrailAfterTP2 && settings.TrailATRMultiplier > 0 {
+		menuText += tr(chatID, "<b>Trail After TP2:</b> %.2fx ATR\n", settings.TrailATRMultiplier)
+	} else if settings.TrailAfterTP2 {
+		menuText += tr(chatID, "<b>Trail After TP2:</b> %.1f%%\n", settings.TrailPercent)
+	} else {
+		menuText += tr(chatID, "<b>Trail After TP2:</b> %s\n", tr(chatID, "off"))
+	}
+	if settings.MaxBookShare > 0 || settings.MinQuoteVolume > 0 {
+		menuText += tr(chatID, "<b>Liquidity Check:</b> max %.1f%% of book, min %.0f USDT 24h volume (block: %t)\n",
+			settings.MaxBookShare, settings.MinQuoteVolume, settings.BlockOnThinLiquidity)
+	} else {
+		menuText += tr(chatID, "<b>Liquidity Check:</b> %s\n", tr(chatID, "off"))
+	}
+
 	// Only show Market Price Tolerance for Limit orders
 	if settings.TradingMode == "Limit" {
-		menuText += fmt.Sprintf("<b>Market Price Tolerance (fraction):</b> %.4f\n",
+		menuText += tr(chatID, "<b>Market Price Tolerance (fraction):</b> %.4f\n",
 			settings.MarketPriceTolerance)
 	}
 
+	// Only show Max Slippage for Market orders
+	if settings.TradingMode == "Market" {
+		menuText += tr(chatID, "<b>Max Slippage (fraction):</b> %.4f\n",
+			settings.MaxSlippage)
+	}
+
+	// Only show the iceberg size for Market orders
+	if settings.TradingMode == "Market" {
+		if settings.IcebergNotional > 0 {
+			menuText += tr(chatID, "<b>Iceberg Above:</b> %.2f USDT\n", settings.IcebergNotional)
+		} else {
+			menuText += tr(chatID, "<b>Iceberg Above:</b> %s\n", tr(chatID, "off"))
+		}
+	}
+
+	// Only show the stop-limit offset for Stop orders
+	if settings.TradingMode == TradingModeStop {
+		if settings.StopLimitOffset > 0 {
+			menuText += tr(chatID, "<b>Stop Limit Offset:</b> %.2f%%\n", settings.StopLimitOffset)
+		} else {
+			menuText += tr(chatID, "<b>Stop Limit Offset:</b> %s\n", tr(chatID, "off (stop-market)"))
+		}
+	}
+
+	// Only show the top-up parameters when auto margin is enabled
+	if settings.AutoMarginEnabled {
+		menuText += tr(chatID, "<b>Top-Up at Margin Ratio:</b> %.2f%%\n<b>Top-Up Amount:</b> %.2f USDT\n",
+			settings.AutoMarginThreshold, settings.AutoMarginAmount)
+	}
+
+	// Only show the DCA ladder parameters when it is enabled
+	if settings.DCAEnabled {
+		menuText += tr(chatID, "<b>DCA Step:</b> %.2f%%\n<b>DCA Max Orders:</b> %d\n",
+			settings.DCAStepPercentage, settings.DCAMaxOrders)
+	}
+
 	// Show TP/SL settings based on mode
 	if settings.AutoCalculateTPs {
-		menuText += fmt.Sprintf(
+		menuText += tr(chatID,
 			"<b>Auto SL Percentage:</b> %.2f%%\n"+
 				"<b>Auto TP Percentage:</b> %.2f%%\n",
 			settings.AutoSLPercentage,
@@ -540,23 +756,16 @@ func showSettingsMenu(chatID int64) {
 		)
 	} else {
 		// Show TP percentages
-		menuText += fmt.Sprintf("\n<b>TP1 Percentage:</b> %.2f%%\n", settings.TP1Percentage)
-		if settings.TP2Enabled {
-			menuText += fmt.Sprintf("<b>TP2 Percentage:</b> %.2f%%\n", settings.TP2Percentage)
-		}
-		if settings.TP3Enabled {
-			menuText += fmt.Sprintf("<b>TP3 Percentage:</b> %.2f%%\n", settings.TP3Percentage)
+		menuText += "\n"
+		for i, level := range settings.TPLevels {
+			menuText += tr(chatID, "<b>TP%d Percentage:</b> %.2f%%\n", i+1, level.Percentage)
 		}
-		menuText += fmt.Sprintf("<b>SL Percentage:</b> %.2f%%\n", settings.ManualSLPercentage)
+		menuText += tr(chatID, "<b>SL Percentage:</b> %.2f%%\n", settings.ManualSLPercentage)
 
-		// Show close percentages for enabled TPs
-		menuText += "\nClose Percentage for Each TP:\n"
-		menuText += fmt.Sprintf("TP1: %.2f%%\n", settings.TP1ClosePct)
-		if settings.TP2Enabled {
-			menuText += fmt.Sprintf("TP2: %.2f%%\n", settings.TP2ClosePct)
-		}
-		if settings.TP3Enabled {
-			menuText += fmt.Sprintf("TP3: %.2f%%\n", settings.TP3ClosePct)
+		// Show close percentages for each TP
+		menuText += tr(chatID, "\nClose Percentage for Each TP:\n")
+		for i, level := range settings.TPLevels {
+			menuText += tr(chatID, "TP%d: %.2f%%\n", i+1, level.ClosePct)
 		}
 	}
 
@@ -569,97 +778,219 @@ func showSettingsMenu(chatID int64) {
 	// Add base buttons
 	keyboard = append(keyboard,
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Margin Mode", fmt.Sprintf("%s|%s", ActionSetOption, "MarginMode")),
-			tgbotapi.NewInlineKeyboardButtonData("Leverage", fmt.Sprintf("%s|%s", ActionSetOption, "Leverage")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Market Type"), fmt.Sprintf("%s|%s", ActionSetOption, "MarketType")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "TP/SL Trigger Price"), fmt.Sprintf("%s|%s", ActionSetOption, "WorkingType")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Margin Mode"), fmt.Sprintf("%s|%s", ActionSetOption, "MarginMode")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Leverage"), fmt.Sprintf("%s|%s", ActionSetOption, "Leverage")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Asset Mode", fmt.Sprintf("%s|%s", ActionSetOption, "AssetMode")),
-			tgbotapi.NewInlineKeyboardButtonData("Trading Mode", fmt.Sprintf("%s|%s", ActionSetOption, "TradingMode")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Asset Mode"), fmt.Sprintf("%s|%s", ActionSetOption, "AssetMode")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Trading Mode"), fmt.Sprintf("%s|%s", ActionSetOption, "TradingMode")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Amount (USDT)", fmt.Sprintf("%s|%s", ActionSetOption, "AmountUSDT")),
-			tgbotapi.NewInlineKeyboardButtonData("Use Stop Loss", fmt.Sprintf("%s|%s", ActionSetOption, "UseSL")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Amount (USDT)"), fmt.Sprintf("%s|%s", ActionSetOption, "AmountUSDT")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Use Stop Loss"), fmt.Sprintf("%s|%s", ActionSetOption, "UseSL")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s Simplified TP/SL", autoCalcEmoji),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "%s Simplified TP/SL", autoCalcEmoji),
 				fmt.Sprintf("%s|%s", ActionSetOption, "AutoCalculateTPs")),
-			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s Dynamic Calculation", dynamicCalcEmoji),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "%s Dynamic Calculation", dynamicCalcEmoji),
 				fmt.Sprintf("%s|%s", ActionSetOption, "DynamicCalculationEnabled")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s Tolerance in Market Mode", toleranceEmoji),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "%s Tolerance in Market Mode", toleranceEmoji),
 				fmt.Sprintf("%s|%s", ActionSetOption, "EnableToleranceInMarketMode")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Chart Snapshot"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "ShowChart")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Funding Threshold %"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "FundingRateThreshold")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Block High Funding"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "BlockOnHighFunding")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Max Book Share %"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "MaxBookShare")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Min 24h Volume"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "MinQuoteVolume")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Block Thin Liquidity"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "BlockOnThinLiquidity")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "DCA Ladder"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "DCAEnabled")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Auto Margin Top-Up"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "AutoMarginEnabled")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Timezone"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "Timezone")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Time Format"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "TimeFormat")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Two-Step Confirm"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "TwoStepConfirm")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Compact Messages"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "CompactMessages")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Quiet Hours"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "QuietHours")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Quiet Mode"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "QuietMode")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Trading Hours"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "TradingHours")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Outside Hours"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "OutsideHoursMode")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Watchlist Only"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "WatchlistOnly")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Quick Actions"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "QuickActions")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Indicator Context"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "ShowIndicators")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "ATR SL Multiplier"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "ATRSLMultiplier")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Buy & Hold Benchmark"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "ShowBenchmark")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Trail After TP2"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "TrailAfterTP2")),
 		),
 	)
 
+	// Add top-up buttons only when auto margin is enabled
+	if settings.AutoMarginEnabled {
+		keyboard = append(keyboard,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Top-Up Ratio %"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "AutoMarginThreshold")),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Top-Up Amount"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "AutoMarginAmount")),
+			),
+		)
+	}
+
+	// Add trailing stop buttons only when Trail After TP2 is on
+	if settings.TrailAfterTP2 {
+		keyboard = append(keyboard,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Trail %"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "TrailPercent")),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Trail ATR Multiplier"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "TrailATRMultiplier")),
+			),
+		)
+	}
+
+	// Add DCA ladder buttons only when it is enabled
+	if settings.DCAEnabled {
+		keyboard = append(keyboard,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "DCA Step %"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "DCAStepPercentage")),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "DCA Max Orders"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "DCAMaxOrders")),
+			),
+		)
+	}
+
 	// Add Market Tolerance button only for Limit orders
 	if settings.TradingMode == "Limit" {
 		keyboard = append(keyboard,
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Set Market Tolerance",
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Market Tolerance"),
 					fmt.Sprintf("%s|%s", ActionSetOption, "MarketPriceTolerance")),
 			),
 		)
 	}
 
-	// Add TP/SL buttons based on mode
-	if settings.AutoCalculateTPs {
+	// Add Max Slippage button only for Market orders
+	if settings.TradingMode == "Market" {
 		keyboard = append(keyboard,
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Set Auto SL %",
-					fmt.Sprintf("%s|%s", ActionSetOption, "AutoSLPercentage")),
-				tgbotapi.NewInlineKeyboardButtonData("Set Auto TP %",
-					fmt.Sprintf("%s|%s", ActionSetOption, "AutoTPPercentage")),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Max Slippage"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "MaxSlippage")),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Iceberg Above"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "IcebergNotional")),
 			),
 		)
-	} else {
-		// Always show TP1 buttons
+	}
+
+	// Add Stop Limit Offset button only for Stop orders
+	if settings.TradingMode == TradingModeStop {
 		keyboard = append(keyboard,
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Set TP1 %",
-					fmt.Sprintf("%s|TP1Percentage", ActionSetOption)),
-				tgbotapi.NewInlineKeyboardButtonData("TP1 Close %",
-					fmt.Sprintf("%s|TP1ClosePct", ActionSetOption)),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Stop Limit Offset"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "StopLimitOffset")),
 			),
 		)
+	}
 
-		// Only show TP2 buttons if enabled
-		if settings.TP2Enabled {
+	// Add TP/SL buttons based on mode
+	if settings.AutoCalculateTPs {
+		keyboard = append(keyboard,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Auto SL %"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "AutoSLPercentage")),
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Auto TP %"),
+					fmt.Sprintf("%s|%s", ActionSetOption, "AutoTPPercentage")),
+			),
+		)
+	} else {
+		// One row of buttons per TP level
+		for i := range settings.TPLevels {
 			keyboard = append(keyboard,
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Set TP2 %",
-						fmt.Sprintf("%s|TP2Percentage", ActionSetOption)),
-					tgbotapi.NewInlineKeyboardButtonData("TP2 Close %",
-						fmt.Sprintf("%s|TP2ClosePct", ActionSetOption)),
+					tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set TP%d %%", i+1),
+						fmt.Sprintf("%s|TP%dPercentage", ActionSetOption, i+1)),
+					tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "TP%d Close %%", i+1),
+						fmt.Sprintf("%s|TP%dClosePct", ActionSetOption, i+1)),
 				),
 			)
 		}
 
-		// Only show TP3 buttons if enabled
-		if settings.TP3Enabled {
-			keyboard = append(keyboard,
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Set TP3 %",
-						fmt.Sprintf("%s|TP3Percentage", ActionSetOption)),
-					tgbotapi.NewInlineKeyboardButtonData("TP3 Close %",
-						fmt.Sprintf("%s|TP3ClosePct", ActionSetOption)),
-				),
-			)
+		// Add or remove the last TP level
+		var levelRow []tgbotapi.InlineKeyboardButton
+		if len(settings.TPLevels) < maxTPLevels {
+			levelRow = append(levelRow, tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Add TP"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "AddTPLevel")))
 		}
+		if len(settings.TPLevels) > 1 {
+			levelRow = append(levelRow, tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Remove TP"),
+				fmt.Sprintf("%s|%s", ActionSetOption, "RemoveTPLevel")))
+		}
+		keyboard = append(keyboard, levelRow)
 
 		// Add SL button
 		keyboard = append(keyboard,
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Set SL %",
+				tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set SL %"),
 					fmt.Sprintf("%s|%s", ActionSetOption, "ManualSLPercentage")),
 			),
 		)
 	}
 
-	// Add Performance button
+	// Add Symbol Overrides, Profiles and Performance buttons
 	keyboard = append(keyboard,
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("View Performance",
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Symbol Overrides"),
+				fmt.Sprintf("%s|list", ActionOverride)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Settings Profiles"),
+				fmt.Sprintf("%s|list", ActionProfile)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "View Performance"),
 				fmt.Sprintf("%s|%s", ActionSetOption, "ViewPerformance")),
 		),
 	)
@@ -668,9 +999,11 @@ func showSettingsMenu(chatID int64) {
 		InlineKeyboard: keyboard,
 	}
 
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send settings menu: %v", err)
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send settings menu", "chat_id", chatID, "error", err)
 	}
+	trackMessage(sent, messageKindMenu)
 }
 
 // toggleToleranceInMarketMode toggles the EnableToleranceInMarketMode setting.
@@ -679,9 +1012,9 @@ func toggleAutoCalculateTPs(chatID int64) {
 	settings.AutoCalculateTPs = !settings.AutoCalculateTPs
 	userSettings.Set(chatID, settings)
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Auto Calculate TPs has been set to %t.", settings.AutoCalculateTPs))
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Auto Calculate TPs has been set to %t.", settings.AutoCalculateTPs))
 	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send message: %v", err)
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 	}
 
 	showSettingsMenu(chatID)
@@ -699,51 +1032,104 @@ func handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 
 	parts := strings.Split(data, "|")
 	if len(parts) < 2 {
-		log.Printf("Invalid callback data: '%s'", data)
+		telegramLog.Warn("Invalid callback data", "data", data)
 		return
 	}
 
 	action := parts[0]
 	payload := parts[1]
 
+	// Every signal and settings action needs at least the trader role; anyone may pick a language
+	if action != ActionLanguage && !hasRole(callback.From.ID, RoleTrader) {
+		callbackConfig := tgbotapi.NewCallbackWithAlert(callback.ID, tr(chatID, "Your role does not allow this action."))
+		if _, err := bot.Request(callbackConfig); err != nil {
+			telegramLog.Error("Callback acknowledgement failed", "error", err)
+		}
+		return
+	}
+
 	switch action {
 	case ActionEdit:
+		auditAction(callback.From.ID, chatID, AuditEdit, payload, "")
 		showEditOptions(chatID, messageID, payload)
 	case ActionField:
 		if len(parts) < 3 {
-			log.Printf("Field name missing in callback data: '%s'", data)
+			telegramLog.Warn("Field name missing in callback data", "data", data)
 			return
 		}
 		fieldName := parts[2]
 		handleFieldSelection(chatID, messageID, payload, fieldName)
+	case ActionStep:
+		handleStepCallback(chatID, callback.From.ID, messageID, parts[1:])
 	case ActionConfirm:
-		confirmSignal(chatID, messageID, payload)
+		auditAction(callback.From.ID, chatID, AuditConfirm, payload, "")
+		if !requestTradeCode(chatID, callback.From.ID, messageID, payload) {
+			handleConfirm(chatID, callback.From.ID, messageID, payload)
+		}
+	case ActionAddPosition:
+		auditAction(callback.From.ID, chatID, AuditConfirm, payload, "add to position")
+		handleAddPosition(chatID, callback.From.ID, messageID, payload)
+	case ActionExecute:
+		executeConfirmation(chatID, callback.From.ID, messageID, payload)
+	case ActionBack:
+		cancelConfirmation(chatID, messageID, payload)
+	case ActionUndo:
+		handleUndo(chatID, callback.From.ID, payload)
+	case ActionPanic:
+		handlePanicCallback(callback, parts[1:])
+	case ActionQuiet:
+		handleQuietCallback(chatID, parts[1:])
+	case ActionSignals:
+		handleSignalsCallback(chatID, parts[1:])
 	case ActionDismiss:
+		auditAction(callback.From.ID, chatID, AuditDismiss, payload, "")
 		dismissSignal(chatID, messageID, payload)
+	case ActionPreview:
+		previewSignal(chatID, callback.From.ID, payload)
 	case ActionSetOption:
 		setUserOption(chatID, messageID, payload)
+	case ActionOverride:
+		handleOverrideCallback(chatID, parts[1:])
+	case ActionProfile:
+		handleProfileCallback(chatID, messageID, parts[1:])
+	case ActionSize:
+		handleSizeCallback(chatID, messageID, parts[1:])
+	case ActionHistory:
+		handleHistoryCallback(chatID, messageID, parts[1:])
+	case ActionParsed:
+		handleParsedCallback(chatID, messageID, parts[1:])
+	case ActionLanguage:
+		setLanguage(chatID, payload)
 	case ActionChangeOption:
 		if len(parts) < 3 {
-			log.Printf("Option value missing in callback data: '%s'", data)
+			telegramLog.Warn("Option value missing in callback data", "data", data)
 			return
 		}
 		value := parts[2]
 		handleCallbackQueryOptionChange(chatID, payload, value)
 	case ActionPerformance:
 		if len(parts) < 2 {
-			log.Printf("Time period missing in callback data: '%s'", data)
+			telegramLog.Warn("Time period missing in callback data", "data", data)
 			return
 		}
 		timePeriod := parts[1]
-		showPerformanceData(chatID, timePeriod)
+		switch {
+		case len(parts) >= 4:
+			// Breakdown message buttons, "performance|week|symbol|worst"
+			showPerformanceBreakdown(chatID, messageID, timePeriod, parts[2], parts[3])
+		case len(parts) == 3:
+			showPerformanceBreakdown(chatID, 0, timePeriod, parts[2], BreakdownBest)
+		default:
+			showPerformanceData(chatID, timePeriod)
+		}
 	default:
-		log.Printf("Unknown callback action: '%s'", action)
+		telegramLog.Warn("Unknown callback action", "action", action)
 	}
 
 	// Acknowledge callback
 	callbackConfig := tgbotapi.NewCallback(callback.ID, "")
 	if _, err := bot.Request(callbackConfig); err != nil {
-		log.Printf("Callback acknowledgement failed: %v", err)
+		telegramLog.Error("Callback acknowledgement failed", "error", err)
 	}
 }
 
@@ -751,7 +1137,7 @@ func handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 func handleFieldSelection(chatID int64, messageID int, signalID string, fieldName string) {
 	signal, exists := signalStore.Get(signalID)
 	if !exists {
-		bot.Send(tgbotapi.NewMessage(chatID, "Signal not found."))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
 		return
 	}
 
@@ -765,7 +1151,12 @@ func handleFieldSelection(chatID int64, messageID int, signalID string, fieldNam
 	case "Midpoint":
 		signal.EntryPrice = signal.Midpoint
 	default:
-		promptNewFieldValue(chatID, signalID, fieldName)
+		// A TP being added has no price to step from yet
+		if stepFieldCode(fieldName) != "" && signalPrice(signal, fieldName) != nil {
+			showStepKeyboard(chatID, messageID, signalID, fieldName)
+		} else {
+			promptNewFieldValue(chatID, signalID, fieldName)
+		}
 		return
 	}
 
@@ -776,16 +1167,20 @@ func handleFieldSelection(chatID int64, messageID int, signalID string, fieldNam
 	updatedText := constructSignalMessageText(signal)
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, updatedText)
 	edit.ParseMode = "HTML"
-	edit.ReplyMarkup = createSignalInlineKeyboard(signalID)
+	edit.ReplyMarkup = createSignalInlineKeyboard(chatID, signalID)
 
 	if _, err := bot.Send(edit); err != nil {
-		log.Printf("Failed to edit message: %v", err)
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
 	}
 }
 
 // setUserOption handles the user's selection of a setting to change.
 func setUserOption(chatID int64, messageID int, option string) {
 	switch option {
+	case "MarketType":
+		showMarketTypeOptions(chatID, messageID)
+	case "WorkingType":
+		showWorkingTypeOptions(chatID, messageID)
 	case "MarginMode":
 		showMarginModeOptions(chatID, messageID)
 	case "Leverage":
@@ -804,12 +1199,10 @@ func setUserOption(chatID int64, messageID int, option string) {
 		toggleDynamicCalculation(chatID)
 	case "EnableToleranceInMarketMode": // Add this case
 		toggleToleranceInMarketMode(chatID)
-	case "TP1Percentage":
-		promptNewTPPercentage(chatID, "TP1Percentage")
-	case "TP2Percentage":
-		promptNewTPPercentage(chatID, "TP2Percentage")
-	case "TP3Percentage":
-		promptNewTPPercentage(chatID, "TP3Percentage")
+	case "AddTPLevel":
+		addTPLevel(chatID)
+	case "RemoveTPLevel":
+		removeTPLevel(chatID)
 	case "ManualSLPercentage":
 		promptNewTPPercentage(chatID, "ManualSLPercentage")
 	case "AutoSLPercentage":
@@ -818,18 +1211,82 @@ func setUserOption(chatID int64, messageID int, option string) {
 		promptNewTPPercentage(chatID, "AutoTPPercentage")
 	case "MarketPriceTolerance":
 		promptNewSettingValue(chatID, "MarketPriceTolerance")
-	case "TP1ClosePct":
-		promptNewSettingValue(chatID, "TP1ClosePct")
-	case "TP2ClosePct":
-		promptNewSettingValue(chatID, "TP2ClosePct")
-	case "TP3ClosePct":
-		promptNewSettingValue(chatID, "TP3ClosePct")
+	case "FundingRateThreshold":
+		promptNewTPPercentage(chatID, "FundingRateThreshold")
+	case "MaxSlippage":
+		promptNewTPPercentage(chatID, "MaxSlippage")
+	case "StopLimitOffset":
+		promptNewTPPercentage(chatID, "StopLimitOffset")
+	case "IcebergNotional":
+		promptNewSettingValue(chatID, "IcebergNotional")
+	case "BlockOnHighFunding":
+		toggleBlockOnHighFunding(chatID)
+	case "DCAEnabled":
+		toggleDCA(chatID)
+	case "DCAStepPercentage":
+		promptNewTPPercentage(chatID, "DCAStepPercentage")
+	case "DCAMaxOrders":
+		promptNewSettingValue(chatID, "DCAMaxOrders")
+	case "AutoMarginEnabled":
+		toggleAutoMargin(chatID)
+	case "ShowChart":
+		toggleShowChart(chatID)
+	case "Timezone":
+		promptTimezone(chatID)
+	case "TimeFormat":
+		toggleTimeFormat(chatID)
+	case "TwoStepConfirm":
+		toggleTwoStepConfirm(chatID)
+	case "QuietHours":
+		promptQuietHours(chatID)
+	case "QuietMode":
+		cycleQuietMode(chatID)
+	case "TradingHours":
+		promptTradingHours(chatID)
+	case "OutsideHoursMode":
+		toggleOutsideHoursMode(chatID)
+	case "WatchlistOnly":
+		toggleWatchlistOnly(chatID)
+	case "QuickActions":
+		toggleQuickActions(chatID)
+	case "CompactMessages":
+		toggleCompactMessages(chatID)
+	case "ShowIndicators":
+		toggleShowIndicators(chatID)
+	case "ShowBenchmark":
+		toggleShowBenchmark(chatID)
+	case "ATRSLMultiplier":
+		promptNewSettingValue(chatID, "ATRSLMultiplier")
+	case "TrailAfterTP2":
+		toggleTrailAfterTP2(chatID)
+	case "TrailPercent":
+		promptNewTPPercentage(chatID, "TrailPercent")
+	case "TrailATRMultiplier":
+		promptNewSettingValue(chatID, "TrailATRMultiplier")
+	case "MaxBookShare":
+		promptNewTPPercentage(chatID, "MaxBookShare")
+	case "MinQuoteVolume":
+		promptNewSettingValue(chatID, "MinQuoteVolume")
+	case "BlockOnThinLiquidity":
+		toggleBlockOnThinLiquidity(chatID)
+	case "AutoMarginThreshold":
+		promptNewTPPercentage(chatID, "AutoMarginThreshold")
+	case "AutoMarginAmount":
+		promptNewSettingValue(chatID, "AutoMarginAmount")
 	case "ViewPerformance":
 		showPerformanceOptions(chatID)
 	default:
-		msg := tgbotapi.NewMessage(chatID, "Unknown setting.")
+		if _, kind, ok := tpLevelSetting(option); ok {
+			if kind == "ClosePct" {
+				promptNewSettingValue(chatID, option)
+			} else {
+				promptNewTPPercentage(chatID, option)
+			}
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, tr(chatID, "Unknown setting."))
 		if _, err := bot.Send(msg); err != nil {
-			log.Printf("Failed to send message: %v", err)
+			telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 		}
 	}
 }
@@ -841,25 +1298,92 @@ func toggleToleranceInMarketMode(chatID int64) {
 	userSettings.Set(chatID, settings)
 
 	// Send confirmation message
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Tolerance in Market Mode has been %s.",
-		map[bool]string{true: "enabled", false: "disabled"}[settings.EnableToleranceInMarketMode]))
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Tolerance in Market Mode has been %s.",
+		enabledText(chatID, settings.EnableToleranceInMarketMode)))
 	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send message: %v", err)
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 	}
 
 	// Show updated settings menu
 	showSettingsMenu(chatID)
 }
 
+// toggleBlockOnHighFunding toggles whether trades are blocked when funding exceeds the threshold.
+func toggleBlockOnHighFunding(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.BlockOnHighFunding = !settings.BlockOnHighFunding
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Block on High Funding has been set to %t.", settings.BlockOnHighFunding))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
+
+// toggleDCA toggles the DCA ladder for market entries.
+func toggleDCA(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.DCAEnabled = !settings.DCAEnabled
+	userSettings.Set(chatID, settings)
+
+	text := tr(chatID, "DCA Ladder has been %s.",
+		enabledText(chatID, settings.DCAEnabled))
+	if settings.DCAEnabled {
+		text += tr(chatID, " It applies to Market entries on USDT-M.")
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
+
+// toggleShowChart toggles the chart snapshot sent with signal messages.
+func toggleShowChart(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.ShowChart = !settings.ShowChart
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Chart Snapshot has been %s.",
+		enabledText(chatID, settings.ShowChart)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
+
+// toggleAutoMargin toggles automatic margin top-ups for isolated positions.
+func toggleAutoMargin(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.AutoMarginEnabled = !settings.AutoMarginEnabled
+	userSettings.Set(chatID, settings)
+
+	text := tr(chatID, "Auto Margin Top-Up has been %s.",
+		enabledText(chatID, settings.AutoMarginEnabled))
+	if settings.AutoMarginEnabled {
+		text += tr(chatID, " It applies to Isolated positions only.")
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
+
 // toggleDynamicCalculation toggles the Dynamic Calculation setting.
 func toggleDynamicCalculation(chatID int64) {
 	settings := userSettings.Get(chatID)
 	settings.DynamicCalculationEnabled = !settings.DynamicCalculationEnabled
 	userSettings.Set(chatID, settings)
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Dynamic Calculation has been set to %t.", settings.DynamicCalculationEnabled))
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Dynamic Calculation has been set to %t.", settings.DynamicCalculationEnabled))
 	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send message: %v", err)
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 	}
 
 	showSettingsMenu(chatID)
@@ -867,24 +1391,54 @@ func toggleDynamicCalculation(chatID int64) {
 
 // promptNewTPPercentage prompts the user to enter a new percentage for TPs or SL.
 func promptNewTPPercentage(chatID int64, setting string) {
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Please enter the new percentage for %s (e.g., 1.5).", setting))
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send prompt message: %v", err)
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter the new percentage for %s (e.g., 1.5).", setting))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
 	}
+	trackMessage(sent, messageKindPrompt)
 	editingUsers.Set(chatID, &EditingState{SettingName: setting})
 }
 
+// showMarketTypeOptions displays choices for Market Type.
+func showMarketTypeOptions(chatID int64, messageID int) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "USDT-M"), fmt.Sprintf("%s|MarketType|%s", ActionChangeOption, MarketTypeUSDTM)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "COIN-M"), fmt.Sprintf("%s|MarketType|%s", ActionChangeOption, MarketTypeCoinM)),
+		),
+	)
+	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
+	if _, err := bot.Request(editMessage); err != nil {
+		telegramLog.Error("Failed to send Market Type options", "chat_id", chatID, "error", err)
+	}
+}
+
+// showWorkingTypeOptions displays choices for the price TP/SL orders trigger on.
+func showWorkingTypeOptions(chatID int64, messageID int) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Mark Price"), fmt.Sprintf("%s|WorkingType|%s", ActionChangeOption, futures.WorkingTypeMarkPrice)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Last Price"), fmt.Sprintf("%s|WorkingType|%s", ActionChangeOption, futures.WorkingTypeContractPrice)),
+		),
+	)
+	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
+	if _, err := bot.Request(editMessage); err != nil {
+		telegramLog.Error("Failed to send TP/SL Trigger Price options", "chat_id", chatID, "error", err)
+	}
+}
+
 // showMarginModeOptions displays choices for Margin Mode.
 func showMarginModeOptions(chatID int64, messageID int) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Cross", fmt.Sprintf("%s|MarginMode|Cross", ActionChangeOption)),
-			tgbotapi.NewInlineKeyboardButtonData("Isolated", fmt.Sprintf("%s|MarginMode|Isolated", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Cross"), fmt.Sprintf("%s|MarginMode|Cross", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Isolated"), fmt.Sprintf("%s|MarginMode|Isolated", ActionChangeOption)),
 		),
 	)
 	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
 	if _, err := bot.Request(editMessage); err != nil {
-		log.Printf("Failed to send Margin Mode options: %v", err)
+		telegramLog.Error("Failed to send Margin Mode options", "chat_id", chatID, "error", err)
 	}
 }
 
@@ -892,13 +1446,13 @@ func showMarginModeOptions(chatID int64, messageID int) {
 func showAssetModeOptions(chatID int64, messageID int) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Multi", fmt.Sprintf("%s|AssetMode|Multi", ActionChangeOption)),
-			tgbotapi.NewInlineKeyboardButtonData("Single", fmt.Sprintf("%s|AssetMode|Single", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Multi"), fmt.Sprintf("%s|AssetMode|Multi", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Single"), fmt.Sprintf("%s|AssetMode|Single", ActionChangeOption)),
 		),
 	)
 	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
 	if _, err := bot.Request(editMessage); err != nil {
-		log.Printf("Failed to send Asset Mode options: %v", err)
+		telegramLog.Error("Failed to send Asset Mode options", "chat_id", chatID, "error", err)
 	}
 }
 
@@ -906,13 +1460,14 @@ func showAssetModeOptions(chatID int64, messageID int) {
 func showTradingModeOptions(chatID int64, messageID int) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Market", fmt.Sprintf("%s|TradingMode|Market", ActionChangeOption)),
-			tgbotapi.NewInlineKeyboardButtonData("Limit", fmt.Sprintf("%s|TradingMode|Limit", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Market"), fmt.Sprintf("%s|TradingMode|Market", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Limit"), fmt.Sprintf("%s|TradingMode|Limit", ActionChangeOption)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Stop"), fmt.Sprintf("%s|TradingMode|%s", ActionChangeOption, TradingModeStop)),
 		),
 	)
 	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
 	if _, err := bot.Request(editMessage); err != nil {
-		log.Printf("Failed to send Trading Mode options: %v", err)
+		telegramLog.Error("Failed to send Trading Mode options", "chat_id", chatID, "error", err)
 	}
 }
 
@@ -922,19 +1477,21 @@ func toggleUseSL(chatID int64, messageID int) {
 	settings.UseSL = !settings.UseSL
 	userSettings.Set(chatID, settings)
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Use Stop Loss has been set to %t.", settings.UseSL))
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Use Stop Loss has been set to %t.", settings.UseSL))
 	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send message: %v", err)
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
 	}
 	showSettingsMenu(chatID)
 }
 
 // promptNewSettingValue prompts the user to enter a new float/int for a setting.
 func promptNewSettingValue(chatID int64, settingName string) {
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Please enter the new value for %s.", settingName))
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send prompt message: %v", err)
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter the new value for %s.", settingName))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
 	}
+	trackMessage(sent, messageKindPrompt)
 	editingUsers.Set(chatID, &EditingState{SettingName: settingName})
 }
 
@@ -959,7 +1516,7 @@ func handleNewSettingValue(message *tgbotapi.Message, editingState *EditingState
 	case "Leverage":
 		newValInt, err := strconv.Atoi(text)
 		if err != nil || newValInt <= 0 || newValInt > 125 {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid leverage value. Enter a positive integer up to 125."))
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid leverage value. Enter a positive integer up to 125.")))
 			return
 		}
 		settings.Leverage = newValInt
@@ -974,7 +1531,7 @@ func handleNewSettingValue(message *tgbotapi.Message, editingState *EditingState
 
 	case "MarketPriceTolerance":
 		if settings.TradingMode == "Market" {
-			bot.Send(tgbotapi.NewMessage(chatID, "Market Price Tolerance is not applicable in Market mode."))
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Market Price Tolerance is not applicable in Market mode.")))
 			return
 		}
 		val, err := parseFloat(text, 0, 100)
@@ -984,55 +1541,29 @@ func handleNewSettingValue(message *tgbotapi.Message, editingState *EditingState
 		}
 		settings.MarketPriceTolerance = val / 100 // Convert percentage to decimal
 
-	case "TP1ClosePct", "TP2ClosePct", "TP3ClosePct":
+	case "MaxSlippage":
 		val, err := parseFloat(text, 0, 100)
 		if err != nil {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid slippage value. "+err.Error()))
 			return
 		}
+		settings.MaxSlippage = val / 100 // Convert percentage to decimal
 
-		switch settingName {
-		case "TP1ClosePct":
-			settings.TP1ClosePct = val
-		case "TP2ClosePct":
-			if settings.TP1ClosePct >= 100 {
-				bot.Send(tgbotapi.NewMessage(chatID, "Cannot set TP2 close percentage when TP1 is 100%."))
-				return
-			}
-			settings.TP2ClosePct = val
-		case "TP3ClosePct":
-			if settings.TP1ClosePct+settings.TP2ClosePct >= 100 {
-				bot.Send(tgbotapi.NewMessage(chatID, "Cannot set TP3 close percentage when TP1 + TP2 is 100%."))
-				return
-			}
-			settings.TP3ClosePct = val
-		}
-
-		// Adjust TP percentages and visibility
-		adjustTPClosePercentages(settings)
-
-	case "TP1Percentage", "TP2Percentage", "TP3Percentage":
-		val, err := parseFloat(text, 0, 1000)
+	case "StopLimitOffset":
+		val, err := parseFloat(text, 0, 10)
 		if err != nil {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid offset. "+err.Error()))
 			return
 		}
-		switch settingName {
-		case "TP1Percentage":
-			settings.TP1Percentage = val
-		case "TP2Percentage":
-			if !settings.TP2Enabled {
-				bot.Send(tgbotapi.NewMessage(chatID, "TP2 is currently disabled due to TP1 close percentage."))
-				return
-			}
-			settings.TP2Percentage = val
-		case "TP3Percentage":
-			if !settings.TP3Enabled {
-				bot.Send(tgbotapi.NewMessage(chatID, "TP3 is currently disabled due to TP1/TP2 close percentages."))
-				return
-			}
-			settings.TP3Percentage = val
+		settings.StopLimitOffset = val
+
+	case "IcebergNotional":
+		val, err := parseFloat(text, 0, 1e12)
+		if err != nil || (val > 0 && val < minIcebergNotional) {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Iceberg Above must be 0 to turn it off, or at least %d USDT.", minIcebergNotional)))
+			return
 		}
+		settings.IcebergNotional = val
 
 	case "ManualSLPercentage", "AutoSLPercentage", "AutoTPPercentage":
 		val, err := parseFloat(text, 0, 100)
@@ -1048,14 +1579,145 @@ func handleNewSettingValue(message *tgbotapi.Message, editingState *EditingState
 		case "AutoTPPercentage":
 			settings.AutoTPPercentage = val
 		}
+
+	case "FundingRateThreshold":
+		val, err := parseFloat(text, 0, 100)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			return
+		}
+		settings.FundingRateThreshold = val
+
+	case "DCAStepPercentage":
+		val, err := parseFloat(text, 0.1, 50)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			return
+		}
+		settings.DCAStepPercentage = val
+
+	case "DCAMaxOrders":
+		newValInt, err := strconv.Atoi(text)
+		if err != nil || newValInt <= 0 || newValInt > maxDCAOrders {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid value. Enter a positive integer up to %d.", maxDCAOrders)))
+			return
+		}
+		settings.DCAMaxOrders = newValInt
+
+	case "AutoMarginThreshold":
+		val, err := parseFloat(text, 1, 100)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			return
+		}
+		settings.AutoMarginThreshold = val
+
+	case "AutoMarginAmount":
+		val, err := parseFloat(text, 1, 1000000)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid amount. "+err.Error()))
+			return
+		}
+		settings.AutoMarginAmount = val
+
+	case "ATRSLMultiplier":
+		val, err := parseFloat(text, 0, maxATRSLMultiplier)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid multiplier. "+err.Error()))
+			return
+		}
+		settings.ATRSLMultiplier = val
+
+	case "TrailPercent":
+		val, err := parseFloat(text, minTrailCallbackRate, maxTrailCallbackRate)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			return
+		}
+		settings.TrailPercent = val
+
+	case "TrailATRMultiplier":
+		val, err := parseFloat(text, 0, maxTrailATRMultiplier)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid multiplier. "+err.Error()))
+			return
+		}
+		settings.TrailATRMultiplier = val
+
+	case "MaxBookShare":
+		val, err := parseFloat(text, 0, 100)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid percentage. "+err.Error()))
+			return
+		}
+		settings.MaxBookShare = val
+
+	case "MinQuoteVolume":
+		val, err := parseFloat(text, 0, 1e12)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "Invalid amount. "+err.Error()))
+			return
+		}
+		settings.MinQuoteVolume = val
+
+	case "Timezone":
+		loc, err := time.LoadLocation(text)
+		if err != nil || text == "" || text == "Local" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Unknown timezone. Use a name such as Europe/Madrid, America/New_York or UTC.")))
+			return
+		}
+		settings.Timezone = loc.String()
+
+	case "QuietHours":
+		if strings.EqualFold(text, "off") {
+			settings.QuietHours = ""
+			break
+		}
+		start, end, err := parseQuietHours(text)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid quiet hours: %v", err)))
+			return
+		}
+		settings.QuietHours = fmt.Sprintf("%d-%d", start, end)
+
+	case "TradingHours":
+		if strings.EqualFold(text, "off") {
+			settings.TradingHours = ""
+			break
+		}
+		windows, err := parseTradingHours(text)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid trading hours: %v", err)))
+			return
+		}
+		settings.TradingHours = formatTradingHours(windows)
+
+	default:
+		// TP level settings, e.g. TP4Percentage or TP2ClosePct
+		if !handleTPLevelValue(chatID, settings, settingName, text) {
+			return
+		}
 	}
 
 	// Save updated settings
 	userSettings.Set(chatID, settings)
+	updatePendingSignals(chatID, settings)
 
+	// Acknowledge success and re-show settings
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "%s has been updated.", settingName)))
+	showSettingsMenu(chatID)
+}
+
+// updatePendingSignals recalculates the TPs/SL of the chat's latest unconfirmed signals after a
+// settings change and updates their messages.
+func updatePendingSignals(chatID int64, settings *UserSettings) {
 	// Update the latest 20 unconfirmed signals
 	unconfirmedSignals := signalStore.GetLatestUnconfirmedSignals(20)
 	for _, sig := range unconfirmedSignals {
+		if sig.ChatID != chatID {
+			// Signals shown in other chats follow those chats' settings
+			continue
+		}
 		if sig.ManualEntryEdited {
 			// Keep original entry price but update TPs/SL
 			originalEntry := sig.EntryPrice
@@ -1071,78 +1733,42 @@ func handleNewSettingValue(message *tgbotapi.Message, editingState *EditingState
 			updatedText := constructSignalMessageText(sig)
 			edit := tgbotapi.NewEditMessageText(chatID, msgID, updatedText)
 			edit.ParseMode = "HTML"
-			edit.ReplyMarkup = createSignalInlineKeyboard(sig.SignalID)
+			edit.ReplyMarkup = createSignalInlineKeyboard(chatID, sig.SignalID)
 
 			if _, err := bot.Send(edit); err != nil {
-				log.Printf("Failed to edit message: %v", err)
+				telegramLog.Error("Failed to edit message", "chat_id", chatID, "error", err)
 			}
 		}
 	}
-
-	// Acknowledge success and re-show settings
-	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("%s has been updated.", settingName)))
-	showSettingsMenu(chatID)
 }
 
-// adjustTPClosePercentages adjusts the TP close percentages and manages TP visibility
+// adjustTPClosePercentages caps the TP close percentages so they add up to 100, giving the last
+// level what is left. Levels that would close nothing are dropped, keeping at least one.
 func adjustTPClosePercentages(settings *UserSettings) {
-	// Reset all TPs to visible first
-	settings.TP1Enabled = true // TP1 is always enabled
-	settings.TP2Enabled = true
-	settings.TP3Enabled = true
-
-	// If TP1 is 100% or more, disable other TPs
-	if settings.TP1ClosePct >= 100 {
-		settings.TP1ClosePct = 100
-		settings.TP2ClosePct = 0
-		settings.TP3ClosePct = 0
-		settings.TP2Enabled = false
-		settings.TP3Enabled = false
-		return
+	if len(settings.TPLevels) == 0 {
+		settings.TPLevels = defaultTPLevels()
 	}
-
-	// Calculate remaining percentage after TP1
-	remainingPct := 100 - settings.TP1ClosePct
-
-	// Handle TP2
-	if settings.TP2ClosePct > remainingPct {
-		settings.TP2ClosePct = remainingPct
-		settings.TP3ClosePct = 0
-		settings.TP3Enabled = false
-		return
+	if len(settings.TPLevels) > maxTPLevels {
+		settings.TPLevels = settings.TPLevels[:maxTPLevels]
 	}
 
-	// Calculate remaining percentage after TP2
-	remainingPct -= settings.TP2ClosePct
-
-	// Handle TP3
-	if remainingPct <= 0 {
-		settings.TP3ClosePct = 0
-		settings.TP3Enabled = false
-	} else {
-		settings.TP3ClosePct = remainingPct
-		settings.TP3Enabled = true
-	}
-
-	// Validate final percentages
-	total := settings.TP1ClosePct + settings.TP2ClosePct + settings.TP3ClosePct
-	if total > 100 {
-		// If somehow total is still > 100, adjust TP3 down
-		settings.TP3ClosePct = math.Max(0, 100-(settings.TP1ClosePct+settings.TP2ClosePct))
+	var levels []TPLevel
+	remaining := 100.0
+	for i, level := range settings.TPLevels {
+		if remaining <= 0 {
+			break
+		}
+		level.ClosePct = math.Max(0, math.Min(level.ClosePct, remaining))
+		if i == len(settings.TPLevels)-1 {
+			level.ClosePct = remaining
+		}
+		if level.ClosePct == 0 {
+			continue
+		}
+		levels = append(levels, level)
+		remaining = roundToSixDecimal(remaining - level.ClosePct)
 	}
-
-	// Update TP enabled states based on final percentages
-	settings.TP2Enabled = settings.TP2ClosePct > 0
-	settings.TP3Enabled = settings.TP3ClosePct > 0
-
-	// Log the adjustment results
-	log.Printf("Adjusted TP percentages - TP1: %.2f%%, TP2: %.2f%%, TP3: %.2f%% (Enabled: %v, %v, %v)",
-		settings.TP1ClosePct,
-		settings.TP2ClosePct,
-		settings.TP3ClosePct,
-		settings.TP1Enabled,
-		settings.TP2Enabled,
-		settings.TP3Enabled)
+	settings.TPLevels = levels
 }
 
 // handleCallbackQueryOptionChange merges logic for direct mode changes, e.g. margin or trading mode selection.
@@ -1150,23 +1776,37 @@ func handleCallbackQueryOptionChange(chatID int64, key string, value string) {
 	settings := userSettings.Get(chatID)
 
 	switch key {
+	case "MarketType":
+		if value != MarketTypeUSDTM && value != MarketTypeCoinM {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid Market Type selected.")))
+			return
+		}
+		settings.MarketType = value
+
+	case "WorkingType":
+		if value != string(futures.WorkingTypeMarkPrice) && value != string(futures.WorkingTypeContractPrice) {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid TP/SL Trigger Price selected.")))
+			return
+		}
+		settings.WorkingType = value
+
 	case "MarginMode":
 		if value != "Cross" && value != "Isolated" {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid Margin Mode selected."))
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid Margin Mode selected.")))
 			return
 		}
 		settings.MarginMode = value
 
 	case "AssetMode":
 		if value != "Multi" && value != "Single" {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid Asset Mode selected."))
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid Asset Mode selected.")))
 			return
 		}
 		settings.AssetMode = value
 
 	case "TradingMode":
-		if value != "Market" && value != "Limit" {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid Trading Mode selected."))
+		if value != "Market" && value != "Limit" && value != TradingModeStop {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid Trading Mode selected.")))
 			return
 		}
 		settings.TradingMode = value
@@ -1176,93 +1816,179 @@ func handleCallbackQueryOptionChange(chatID int64, key string, value string) {
 		}
 
 	default:
-		bot.Send(tgbotapi.NewMessage(chatID, "Unknown setting."))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Unknown setting.")))
 		return
 	}
 
 	userSettings.Set(chatID, settings)
-	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("%s has been updated to %s.", key, value)))
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "%s has been updated to %s.", key, value)))
 	showSettingsMenu(chatID)
 }
 
+// handleConfirm checks a Confirm against the signal's expiry, the trading hours, news halts and
+// the duplicate-position guard, then applies the two-trader rule and shows the trade summary
+// or confirms the signal right away.
+func handleConfirm(chatID, userID int64, messageID int, signalID string) {
+	if signal, exists := signalStore.Get(signalID); exists {
+		if refuseExpired(chatID, signal) || refuseOutsideHours(chatID, signal) || refuseDuringNews(chatID) {
+			return
+		}
+		if !guardDuplicatePosition(chatID, userID, signal) {
+			return
+		}
+	}
+	proceedConfirm(chatID, userID, messageID, signalID)
+}
+
+// proceedConfirm takes a Confirm through the two-trader rule and the two-step summary, if
+// enabled, to confirmSignal.
+func proceedConfirm(chatID, userID int64, messageID int, signalID string) {
+	if !approveLargeTrade(chatID, userID, signalID) {
+		return
+	}
+	if userSettings.Get(chatID).TwoStepConfirm {
+		requestConfirmation(chatID, userID, messageID, signalID)
+	} else {
+		confirmSignal(chatID, userID, messageID, signalID)
+	}
+}
+
 // confirmSignal marks a signal as confirmed and updates the message.
-func confirmSignal(chatID int64, messageID int, signalID string) {
-	log.Printf("confirmSignal called => chatID: %d, messageID: %d, signalID: %s", chatID, messageID, signalID)
+// The trade executes on the confirming user's own account, with the settings of their private
+// chat, if they have connected one, otherwise on the routed account with the chat's settings
+// and, at the same time, on every mirror account. The signal
+// is also republished to the MetaTrader/cTrader bridge, if one is set up.
+func confirmSignal(chatID, userID int64, messageID int, signalID string) {
+	telegramLog.Info("Confirming signal", "signal_id", signalID, "chat_id", chatID, "user_id", userID, "message_id", messageID)
 
 	signal, exists := signalStore.Get(signalID)
 	if !exists {
-		bot.Send(tgbotapi.NewMessage(chatID, "Signal not found."))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
+		return
+	}
+	if refuseExpired(chatID, signal) || refuseOutsideHours(chatID, signal) || refuseDuringNews(chatID) {
 		return
 	}
 
 	signal.Confirmed = true
+	recordSignalStatus(signal, SignalConfirmed)
 	confirmationText := constructSignalMessageText(signal)
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, confirmationText)
 	edit.ParseMode = "HTML"
 	edit.ReplyMarkup = nil // remove inline keyboard on confirm
 
 	if _, err := bot.Send(edit); err != nil {
-		log.Printf("Failed to edit message: %v", err)
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
 	}
 
-	settings := userSettings.Get(chatID)
-	err := sendToBinance(signal, settings)
+	// Give up on the trade rather than hold up the confirmation if Binance stops responding
+	ctx, cancel := tradeContext()
+	defer cancel()
+	settingsChat := settingsChatID(chatID, userID)
+	settings := signalSettings(settingsChat, signal)
+	publishToBridge(filterEnabledTPs(signal, applySignalOverrides(signal, applySymbolOverride(settingsChat, signal.Symbol, settings))))
+
+	// Mirror accounts execute the signal alongside the account it is routed to
+	var mirrors []BinanceAccount
+	if credential, err := GetUserCredential(userID); err == nil && credential == nil {
+		mirrors = mirrorAccounts(resolveAccount(signal))
+	}
+	mirrored := make(chan []MirrorResult, 1)
+	if len(mirrors) > 0 {
+		mirrorSettings := applySignalOverrides(signal, applySymbolOverride(chatID, signal.Symbol, settings))
+		mirrorCopy := *signal
+		go func() {
+			mirrored <- mirrorSignal(ctx, chatID, userID, &mirrorCopy, mirrorSettings, mirrors)
+		}()
+	}
+
+	err := sendToBinance(ctx, chatID, userID, signal, settings)
 	if err != nil {
-		log.Printf("Failed to send signal to Binance: %v", err)
-		bot.Send(tgbotapi.NewMessage(chatID, handleBinanceError(err)))
+		telegramLog.Error("Failed to send signal to Binance", "signal_id", signalID, "symbol", signal.Symbol,
+			"chat_id", chatID, "user_id", userID, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, handleBinanceError(chatID, err)))
 	} else {
-		bot.Send(tgbotapi.NewMessage(chatID, "Trade executed on Binance successfully."))
+		offerUndo(chatID, userID, signal, settings)
 		// Store the signal details for tracking
 		trackSignal(signal)
+		executed := applySignalOverrides(signal, applySymbolOverride(settingsChat, signal.Symbol, settings))
+		fireTradeExecuted(signal, signal.Account, signalExchange(userID, signal.Account), executed)
+	}
+	postConfirmationToDiscord(signal, userID, err)
+	if len(mirrors) > 0 {
+		sendMirrorReport(chatID, signal, <-mirrored)
 	}
 }
 
 // trackSignal stores the signal details for later performance tracking.
 func trackSignal(signal *AlertMessage) {
-	if err := StoreSignal(signal.SignalID, signal.EntryPrice, signal.TP1, signal.TP2, signal.TP3, signal.SL); err != nil {
-		log.Printf("Failed to store signal: %v", err)
-	}
+	recordSignalStatus(signal, SignalExecuted)
 }
 
 // dismissSignal handles the dismissal of a signal by the user.
 func dismissSignal(chatID int64, messageID int, signalID string) {
-	log.Printf("dismissSignal called => chatID: %d, messageID: %d, signalID: %s", chatID, messageID, signalID)
+	telegramLog.Info("Dismissing signal", "signal_id", signalID, "chat_id", chatID, "message_id", messageID)
 
 	signal, exists := signalStore.Get(signalID)
 	if !exists {
-		bot.Send(tgbotapi.NewMessage(chatID, "Signal not found."))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
 		return
 	}
 
 	signal.Dismissed = true
+	recordSignalStatus(signal, SignalDismissed)
 	dismissalText := constructSignalMessageText(signal)
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, dismissalText)
 	edit.ParseMode = "HTML"
 	edit.ReplyMarkup = nil // remove inline keyboard on dismiss
 
 	if _, err := bot.Send(edit); err != nil {
-		log.Printf("Failed to edit message: %v", err)
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	} else {
+		trackDismissedSignal(chatID, messageID, signalID)
 	}
-	bot.Send(tgbotapi.NewMessage(chatID, "Signal has been dismissed."))
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal has been dismissed.")))
 }
 
-// sendToBinance sends the confirmed signal to Binance API using the user's settings.
-func sendToBinance(signal *AlertMessage, settings *UserSettings) error {
-	// Create a filtered signal with only enabled TPs
-	filteredSignal := &AlertMessage{
-		SignalID:   signal.SignalID,
-		SignalType: signal.SignalType,
-		Symbol:     signal.Symbol,
-		EntryPrice: signal.EntryPrice,
-		TP1:        signal.TP1, // TP1 is always enabled
-		SL:         signal.SL,  // SL is included if UseSL is true
+// sendToBinance sends the confirmed signal to Binance API using the given settings, with any
+// per-symbol override for the signal's symbol applied on top. The trade runs on the confirming
+// user's own account, with the overrides of their private chat, or on the routed account with
+// the chat's overrides if they have not connected one.
+// Users who connected a Bybit account trade there instead, without the Binance price checks.
+func sendToBinance(ctx context.Context, chatID, userID int64, signal *AlertMessage, settings *UserSettings) error {
+	settings = applySignalOverrides(signal, applySymbolOverride(settingsChatID(chatID, userID), signal.Symbol, settings))
+
+	exchange, err := tradingExchange(userID, signal)
+	if err != nil {
+		return newTradeGuardError("Account %s is unavailable: %v", signal.Account, err)
 	}
+	return executeSignal(ctx, chatID, userID, exchange, signal, signal.Account, settings)
+}
 
-	// Perform price tolerance check only if enabled in Market mode
+// executeSignal executes a confirmed signal on an exchange account with settings that already
+// have the chat's overrides applied. Binance trades are checked against the market price
+// tolerance and funding first.
+func executeSignal(ctx context.Context, chatID, userID int64, exchange Exchange, signal *AlertMessage, account string, settings *UserSettings) error {
+	if GetGlobalConfig().TradingHalted {
+		return newTradeGuardError("Trading is halted after /panic. An admin can resume it with /panic off.")
+	}
+	client, isBinance := exchange.(*BinanceClient)
+	if !isBinance {
+		if settings.TradingMode == TradingModeStop {
+			return newTradeGuardError("Stop entries are only available on Binance, not %s.", exchange.Name())
+		}
+		filteredSignal := filterEnabledTPs(signal, settings)
+		binanceLog.Debug("Sending signal to exchange", "exchange", exchange.Name(), "signal_id", signal.SignalID,
+			"symbol", signal.Symbol, "user_id", userID, "account", account, "settings", fmt.Sprintf("%+v", *settings))
+		return executeExchangeTrade(ctx, exchange, filteredSignal, settings, chatID)
+	}
+
+	// Perform price tolerance check only if enabled in Market mode. Mark price is used
+	// so a last-price wick does not reject an otherwise valid entry.
 	if settings.TradingMode == "Market" && settings.EnableToleranceInMarketMode {
-		currentPrice, err := binanceClient.getCurrentPrice(signal.Symbol)
+		currentPrice, err := client.getMarkPrice(ctx, signal.Symbol)
 		if err != nil {
-			return fmt.Errorf("failed to get current price: %v", err)
+			return fmt.Errorf("failed to get mark price: %v", err)
 		}
 
 		diff := math.Abs(currentPrice-signal.EntryPrice) / signal.EntryPrice
@@ -1275,32 +2001,57 @@ func sendToBinance(signal *AlertMessage, settings *UserSettings) error {
 		}
 	}
 
-	// Calculate total close percentage for validation
-	totalClosePct := settings.TP1ClosePct
-
-	// Only include TP2 if it's enabled and there's remaining percentage
-	if settings.TP2Enabled && totalClosePct < 100 {
-		filteredSignal.TP2 = signal.TP2
-		totalClosePct += settings.TP2ClosePct
+	// Block the trade if funding is too expensive and the user opted in
+	if settings.BlockOnHighFunding {
+		if warning, err := client.fundingWarning(ctx, chatID, signal, settings); err != nil {
+			binanceLog.Warn("Failed to check funding rate", "signal_id", signal.SignalID, "symbol", signal.Symbol, "error", err)
+		} else if warning != "" {
+			return newTradeGuardError("Trade blocked: %s", warning)
+		}
 	}
 
-	// Only include TP3 if it's enabled and there's remaining percentage
-	if settings.TP3Enabled && totalClosePct < 100 {
-		filteredSignal.TP3 = signal.TP3
-		totalClosePct += settings.TP3ClosePct
+	// Block the trade if the market is too thin for its size and the user opted in
+	if settings.BlockOnThinLiquidity {
+		if warning, err := client.liquidityWarning(ctx, chatID, signal, settings); err != nil {
+			binanceLog.Warn("Failed to check liquidity", "signal_id", signal.SignalID, "symbol", signal.Symbol, "error", err)
+		} else if warning != "" {
+			return newTradeGuardError("Trade blocked: %s", warning)
+		}
 	}
 
-	// If totalClosePct is 100%, deactivate TP3
-	if totalClosePct >= 100 {
-		filteredSignal.TP3 = 0
-	}
+	filteredSignal := filterEnabledTPs(signal, settings)
+	binanceLog.Debug("Sending signal to Binance", "signal_id", signal.SignalID, "symbol", signal.Symbol, "user_id", userID,
+		"account", account, "settings", fmt.Sprintf("%+v", *settings), "signal", fmt.Sprintf("%+v", *filteredSignal))
+	return client.ExecuteTrade(ctx, filteredSignal, settings, chatID)
+}
 
-	log.Printf("Executing trade => settings: %+v, signal: %+v", settings, filteredSignal)
-	return binanceClient.ExecuteTrade(filteredSignal, settings, GlobalConfig.TelegramChatID)
+// filterEnabledTPs returns a copy of the signal with only the TPs that have a level in the settings,
+// entering at the price of the signal's entry order type. The timeframe and source are kept for
+// the indicators and account routing of the trade.
+func filterEnabledTPs(signal *AlertMessage, settings *UserSettings) *AlertMessage {
+	tps := signal.TPs
+	if len(tps) > len(settings.TPLevels) {
+		tps = tps[:len(settings.TPLevels)]
+	}
+	return &AlertMessage{
+		SignalID:   signal.SignalID,
+		SignalType: signal.SignalType,
+		Symbol:     signal.Symbol,
+		EntryPrice: entryOrderPrice(signal),
+		TPs:        slices.Clone(tps),
+		SL:         signal.SL, // SL is included if UseSL is true
+		Timeframe:  signal.Timeframe,
+		Source:     signal.Source,
+	}
 }
 
 // constructSignalMessageText constructs the text of a signal message for Telegram.
 func constructSignalMessageText(signal *AlertMessage) string {
+	chatID := signal.ChatID
+	if userSettings.Get(chatID).CompactMessages {
+		return compactSignalText(signal)
+	}
+
 	var emoji string
 	if signal.SignalType == "Buy" {
 		emoji = "\U0001F7E2"
@@ -1310,27 +2061,108 @@ func constructSignalMessageText(signal *AlertMessage) string {
 		emoji = "\U000026AA"
 	}
 
-	msg := fmt.Sprintf("%s <b>%s Signal</b>\n\n", emoji, signal.SignalType)
-	msg += fmt.Sprintf("<b>Symbol:</b> %s\n", signal.Symbol)
-	msg += fmt.Sprintf("<b>Timeframe:</b> %s\n", signal.Timeframe)
-	msg += fmt.Sprintf("<b>Time:</b> %s\n", signal.Time)
-	msg += fmt.Sprintf("<b>Entry Price:</b> %s\n", formatFloat(signal.EntryPrice))
-	msg += fmt.Sprintf("<b>TP1:</b> %s\n", formatFloat(signal.TP1))
-	msg += fmt.Sprintf("<b>TP2:</b> %s\n", formatFloat(signal.TP2))
-	msg += fmt.Sprintf("<b>TP3:</b> %s\n", formatFloat(signal.TP3))
-	msg += fmt.Sprintf("<b>SL:</b> %s\n", formatFloat(signal.SL))
-	msg += fmt.Sprintf("<b>High Price:</b> %s\n", formatFloat(signal.HighPrice))
-	msg += fmt.Sprintf("<b>Low Price:</b> %s\n", formatFloat(signal.LowPrice))
-	msg += fmt.Sprintf("<b>Midpoint:</b> %s\n", formatFloat(signal.Midpoint))
+	msg := tr(chatID, "%s <b>%s Signal</b>\n\n", emoji, tr(chatID, signal.SignalType))
+	msg += tr(chatID, "<b>Symbol:</b> %s\n", signal.Symbol)
+	msg += tr(chatID, "<b>Timeframe:</b> %s\n", signal.Timeframe)
+	msg += tr(chatID, "<b>Time:</b> %s\n", formatSignalTime(chatID, signal.Time))
+	msg += tr(chatID, "<b>Entry Price:</b> %s\n", formatFloat(signal.EntryPrice))
+	for i := 0; i < max(len(signal.TPs), 1); i++ {
+		msg += tr(chatID, "<b>TP%d:</b> %s\n", i+1, formatFloat(signalTP(signal, i)))
+	}
+	msg += tr(chatID, "<b>SL:</b> %s\n", formatFloat(signal.SL))
+	msg += tr(chatID, "<b>High Price:</b> %s\n", formatFloat(signal.HighPrice))
+	msg += tr(chatID, "<b>Low Price:</b> %s\n", formatFloat(signal.LowPrice))
+	msg += tr(chatID, "<b>Midpoint:</b> %s\n", formatFloat(signal.Midpoint))
+	if entryType := signalEntryType(signal); entryType == EntryMarket {
+		msg += tr(chatID, "<b>Entry Order:</b> %s\n", tr(chatID, entryType))
+	} else if entryType != "" {
+		msg += tr(chatID, "<b>Entry Order:</b> %s at %s\n", tr(chatID, entryType), formatFloat(entryOrderPrice(signal)))
+	}
+	if signal.Account != "" {
+		msg += tr(chatID, "<b>Account:</b> %s\n", signal.Account)
+	}
+	if signal.Profile != "" {
+		msg += tr(chatID, "<b>Profile:</b> %s\n", signal.Profile)
+	}
+	if signal.LeverageOverride > 0 {
+		msg += tr(chatID, "<b>Leverage:</b> %dx (this trade only)\n", signal.LeverageOverride)
+	}
+	if signal.AmountOverride > 0 {
+		msg += tr(chatID, "<b>Amount:</b> %.2f USDT (this trade only)\n", signal.AmountOverride)
+	}
+	if !signal.Confirmed && !signal.Dismissed && !signal.Expired && !signal.ExpiresAt.IsZero() {
+		msg += tr(chatID, "<b>Valid Until:</b> %s\n", formatUserTime(chatID, signal.ExpiresAt))
+	}
+
+	if signal.Liquidation != nil {
+		msg += liquidationText(signal)
+	}
+
+	if signal.Indicators != nil && userSettings.Get(chatID).ShowIndicators {
+		msg += indicatorText(chatID, signal.Indicators)
+	}
+
+	if signal.FundingWarning != "" {
+		msg += fmt.Sprintf("\n\u26A0\uFE0F %s\n", signal.FundingWarning)
+	}
+	if signal.LiquidityWarning != "" {
+		msg += fmt.Sprintf("\u26A0\uFE0F %s\n", signal.LiquidityWarning)
+	}
+	for _, note := range signal.FilterNotes {
+		msg += fmt.Sprintf("\u2139\uFE0F %s\n", html.EscapeString(note))
+	}
+	if signal.NewsHalt != nil {
+		msg += fmt.Sprintf("\u26A0\uFE0F %s\n", html.EscapeString(newsHaltText(chatID, signal.NewsHalt)))
+	}
+	if signal.InfoOnly {
+		msg += tr(chatID, "\u2139\uFE0F Outside your trading hours (%s): for information only.\n", userSettings.Get(chatID).TradingHours)
+	}
 
 	if signal.Confirmed {
-		msg += "\n\u2705 Signal confirmed and sent to Binance."
+		msg += tr(chatID, "\n\u2705 Signal confirmed and sent to Binance.")
 	} else if signal.Dismissed {
-		msg += "\n\u274C Signal has been dismissed."
+		msg += tr(chatID, "\n\u274C Signal has been dismissed.")
+	} else if signal.Expired {
+		msg += tr(chatID, "\n\u231B Signal expired unanswered.")
 	}
 	return msg
 }
 
+// liquidationText shows the estimated liquidation price and warns when the SL lies beyond it.
+func liquidationText(signal *AlertMessage) string {
+	chatID := signal.ChatID
+	side := futures.SideTypeBuy
+	if signal.SignalType == "Sell" {
+		side = futures.SideTypeSell
+	}
+	liq := signal.Liquidation.Price(side, signal.EntryPrice)
+	if liq <= 0 {
+		return ""
+	}
+
+	text := tr(chatID, "<b>Est. Liquidation (%s %dx):</b> %s\n",
+		signal.Liquidation.MarginMode, signal.Liquidation.Leverage, formatFloat(roundToSignificant(liq, 6)))
+	if signal.Liquidation.MarginMode == "Cross" {
+		text += tr(chatID, "<i>Isolated estimate; with cross margin your balance pushes liquidation further away.</i>\n")
+	}
+
+	beyond := (side == futures.SideTypeBuy && signal.SL > 0 && signal.SL <= liq) ||
+		(side == futures.SideTypeSell && signal.SL >= liq)
+	if beyond {
+		text += tr(chatID, "\n\u26A0\uFE0F SL is beyond the estimated liquidation price and may never execute. Lower the leverage or tighten the SL.\n")
+	}
+	return text
+}
+
+// roundToSignificant rounds a value to the given number of significant digits.
+func roundToSignificant(value float64, digits int) float64 {
+	if value == 0 {
+		return 0
+	}
+	magnitude := math.Pow(10, float64(digits)-math.Ceil(math.Log10(math.Abs(value))))
+	return math.Round(value*magnitude) / magnitude
+}
+
 func formatFloat(num float64) string {
 	if num == 0 {
 		return "-"
@@ -1354,21 +2186,26 @@ func handleNewFieldValue(message *tgbotapi.Message, editingState *EditingState)
 	fieldName := editingState.Field
 
 	// Log the signal ID and field being edited
-	log.Printf("Editing signal: %s, field: %s", signalID, fieldName)
+	telegramLog.Info("Editing signal", "signal_id", signalID, "field", fieldName)
 
 	signal, exists := signalStore.Get(signalID)
 	if !exists {
-		bot.Send(tgbotapi.NewMessage(chatID, "Signal not found."))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Signal not found.")))
 		return
 	}
 
 	settings := userSettings.Get(chatID)
 
+	var oldValue float64
+	if price := signalPrice(signal, fieldName); price != nil {
+		oldValue = *price
+	}
+
 	switch fieldName {
 	case "Entry Price":
 		value, err := strconv.ParseFloat(text, 64)
 		if err != nil {
-			bot.Send(tgbotapi.NewMessage(chatID, "Invalid value for Entry Price."))
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid value for Entry Price.")))
 			return
 		}
 
@@ -1381,42 +2218,67 @@ func handleNewFieldValue(message *tgbotapi.Message, editingState *EditingState)
 		// Recalculate TPs/SL if dynamic calculation is enabled
 		recalculateTPAndSL(signal, settings)
 
+	case "SL":
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || value < 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid value for %s.", fieldName)))
+			return
+		}
+		signal.SL = value
+
 	default:
-		bot.Send(tgbotapi.NewMessage(chatID, "Unknown field."))
-		return
+		level, ok := tpField(fieldName)
+		if !ok || level > len(signal.TPs) {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Unknown field.")))
+			return
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || value < 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid value for %s.", fieldName)))
+			return
+		}
+		// The level after the last TP adds a new one
+		if level == len(signal.TPs) {
+			signal.TPs = append(signal.TPs, 0)
+		}
+		signal.TPs[level] = value
 	}
 
+	auditFieldChange(message.From.ID, chatID, signal, fieldName, oldValue)
+	recordSignalStatus(signal, SignalEdited)
+
 	// Update the Telegram message to reflect changes
 	msgID, ok := messageStore.Get(signalID)
 	if !ok {
-		bot.Send(tgbotapi.NewMessage(chatID, "Unable to find the message to update."))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Unable to find the message to update.")))
 		return
 	}
 
 	updatedText := constructSignalMessageText(signal)
 	edit := tgbotapi.NewEditMessageText(chatID, msgID, updatedText)
 	edit.ParseMode = "HTML"
-	edit.ReplyMarkup = createSignalInlineKeyboard(signalID)
+	edit.ReplyMarkup = createSignalInlineKeyboard(chatID, signalID)
 
 	if _, err := bot.Send(edit); err != nil {
-		log.Printf("Failed to edit message: %v", err)
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
 		return
 	}
 
 	// Notify the user
 	bot.Send(tgbotapi.NewMessage(
 		chatID,
-		fmt.Sprintf(
+		tr(chatID,
 			"Signal updated successfully.\nSymbol: %s\nTime: %s\nField: %s\nNew Value: %s",
 			signal.Symbol,
-			signal.Time,
+			formatSignalTime(chatID, signal.Time),
 			fieldName,
 			text,
 		),
 	))
 }
 
-// recalculateTPAndSL recalculates TP1, TP2, TP3, and SL based on entry price & user-defined percentages.
+// recalculateTPAndSL recalculates the TPs and SL based on entry price & user-defined percentages,
+// or the SL from the ATR when an ATR multiplier is set.
 func recalculateTPAndSL(signal *AlertMessage, settings *UserSettings) {
 	if !settings.DynamicCalculationEnabled {
 		// If dynamic calculation is disabled, use the exact values from the alert
@@ -1432,13 +2294,13 @@ func recalculateTPAndSL(signal *AlertMessage, settings *UserSettings) {
 		// Simplified TP/SL is true
 		if signal.SignalType == "Buy" {
 			// For Buy signals, TP1 is above entry and SL is below entry
-			signal.TP1 = roundToSixDecimal(entryPrice * (1 + (settings.AutoTPPercentage / 100.0)))
+			signal.TPs = []float64{roundToSixDecimal(entryPrice * (1 + (settings.AutoTPPercentage / 100.0)))}
 			if settings.UseSL {
 				signal.SL = roundToSixDecimal(entryPrice * (1 - (settings.AutoSLPercentage / 100.0)))
 			}
 		} else if signal.SignalType == "Sell" {
 			// For Sell signals, TP1 is below entry and SL is above entry
-			signal.TP1 = roundToSixDecimal(entryPrice * (1 - (settings.AutoTPPercentage / 100.0)))
+			signal.TPs = []float64{roundToSixDecimal(entryPrice * (1 - (settings.AutoTPPercentage / 100.0)))}
 			if settings.UseSL {
 				signal.SL = roundToSixDecimal(entryPrice * (1 + (settings.AutoSLPercentage / 100.0)))
 			}
@@ -1447,9 +2309,7 @@ func recalculateTPAndSL(signal *AlertMessage, settings *UserSettings) {
 		// Simplified TP/SL is false
 		if signal.SignalType == "Buy" {
 			// For Buy signals, TPs are above entry
-			signal.TP1 = roundToSixDecimal(entryPrice * (1 + (settings.TP1Percentage / 100.0)))
-			signal.TP2 = roundToSixDecimal(entryPrice * (1 + (settings.TP2Percentage / 100.0)))
-			signal.TP3 = roundToSixDecimal(entryPrice * (1 + (settings.TP3Percentage / 100.0)))
+			signal.TPs = tpPrices(signal.SignalType, entryPrice, settings.TPLevels)
 
 			// SL is below entry, only if UseSL is turned on
 			if settings.UseSL {
@@ -1457,15 +2317,14 @@ func recalculateTPAndSL(signal *AlertMessage, settings *UserSettings) {
 			}
 		} else if signal.SignalType == "Sell" {
 			// For Sell signals, TPs are below entry
-			signal.TP1 = roundToSixDecimal(entryPrice * (1 - (settings.TP1Percentage / 100.0)))
-			signal.TP2 = roundToSixDecimal(entryPrice * (1 - (settings.TP2Percentage / 100.0)))
-			signal.TP3 = roundToSixDecimal(entryPrice * (1 - (settings.TP3Percentage / 100.0)))
+			signal.TPs = tpPrices(signal.SignalType, entryPrice, settings.TPLevels)
 
 			if settings.UseSL {
 				signal.SL = roundToSixDecimal(entryPrice * (1 + (settings.ManualSLPercentage / 100.0)))
 			}
 		}
 	}
+	applyATRStopLoss(signal, settings)
 }
 
 // roundToSixDecimal rounds a float64 to six decimal places.
@@ -1475,59 +2334,83 @@ func roundToSixDecimal(num float64) float64 {
 
 // showEditOptions displays fields that can be edited for a signal.
 func showEditOptions(chatID int64, messageID int, signalID string) {
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Entry Price", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Entry Price")),
-			tgbotapi.NewInlineKeyboardButtonData("SL", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "SL")),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("TP1", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "TP1")),
-			tgbotapi.NewInlineKeyboardButtonData("TP2", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "TP2")),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("TP3", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "TP3")),
-		),
+	var tpCount int
+	if signal, exists := signalStore.Get(signalID); exists {
+		tpCount = len(signal.TPs)
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Set High Price", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "High Price")),
-			tgbotapi.NewInlineKeyboardButtonData("Set Low Price", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Low Price")),
-			tgbotapi.NewInlineKeyboardButtonData("Set Midpoint", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Midpoint")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Entry Price"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Entry Price")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "SL"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "SL")),
 		),
-	)
+	}
 
-	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
+	// One button per TP, two to a row, plus one to add a TP after the last
+	var tpButtons []tgbotapi.InlineKeyboardButton
+	for i := 0; i < tpCount; i++ {
+		field := fmt.Sprintf("TP%d", i+1)
+		tpButtons = append(tpButtons, tgbotapi.NewInlineKeyboardButtonData(field, fmt.Sprintf("%s|%s|%s", ActionField, signalID, field)))
+	}
+	if tpCount < maxTPLevels {
+		tpButtons = append(tpButtons, tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Add TP%d", tpCount+1),
+			fmt.Sprintf("%s|%s|TP%d", ActionField, signalID, tpCount+1)))
+	}
+	for i := 0; i < len(tpButtons); i += 2 {
+		rows = append(rows, tpButtons[i:min(i+2, len(tpButtons))])
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set High Price"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "High Price")),
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Low Price"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Low Price")),
+		tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Midpoint"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Midpoint")),
+	))
+
+	editMessage := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
 	if _, err := bot.Request(editMessage); err != nil {
-		log.Printf("Failed to send edit options: %v", err)
+		telegramLog.Error("Failed to send edit options", "signal_id", signalID, "chat_id", chatID, "error", err)
 	}
 }
 
 // promptNewFieldValue prompts the user to enter a new value for a specific signal field.
 func promptNewFieldValue(chatID int64, signalID string, fieldName string) {
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Please enter the new value for %s.", fieldName))
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send prompt: %v", err)
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter the new value for %s.", fieldName))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt", "signal_id", signalID, "chat_id", chatID, "error", err)
 	}
+	trackMessage(sent, messageKindPrompt)
 	editingUsers.Set(chatID, &EditingState{SignalID: signalID, Field: fieldName})
 }
 
 // createSignalInlineKeyboard creates the inline keyboard for a signal message (Edit, Confirm, Dismiss, High, Low, Midpoint).
-func createSignalInlineKeyboard(signalID string) *tgbotapi.InlineKeyboardMarkup {
+func createSignalInlineKeyboard(chatID int64, signalID string) *tgbotapi.InlineKeyboardMarkup {
+	if signal, exists := signalStore.Get(signalID); exists && signal.InfoOnly {
+		return infoOnlyKeyboard(chatID, signalID)
+	}
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Edit", fmt.Sprintf("%s|%s", ActionEdit, signalID)),
-			tgbotapi.NewInlineKeyboardButtonData("Confirm", fmt.Sprintf("%s|%s", ActionConfirm, signalID)),
-			tgbotapi.NewInlineKeyboardButtonData("Dismiss", fmt.Sprintf("%s|%s", ActionDismiss, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Edit"), fmt.Sprintf("%s|%s", ActionEdit, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Confirm"), fmt.Sprintf("%s|%s", ActionConfirm, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Dismiss"), fmt.Sprintf("%s|%s", ActionDismiss, signalID)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Set High Price", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "High Price")),
-			tgbotapi.NewInlineKeyboardButtonData("Set Low Price", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Low Price")),
-			tgbotapi.NewInlineKeyboardButtonData("Set Midpoint", fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Midpoint")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Preview"), fmt.Sprintf("%s|%s", ActionPreview, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Profile"), fmt.Sprintf("%s|pick|%s", ActionProfile, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Size"), fmt.Sprintf("%s|%s", ActionSize, signalID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set High Price"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "High Price")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Low Price"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Low Price")),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Set Midpoint"), fmt.Sprintf("%s|%s|%s", ActionField, signalID, "Midpoint")),
 		),
 	)
 	return &keyboard
 }
 
-// sendSignalMessage sends an alert message to the Telegram chat.
-func sendSignalMessage(alert *AlertMessage) (int, error) {
+// sendSignalMessage sends an alert message to the Telegram chat. ctx bounds the Binance lookups
+// for the funding warning, liquidation estimate and chart.
+func sendSignalMessage(ctx context.Context, alert *AlertMessage) (int, error) {
 	chatID := GlobalConfig.TelegramChatID
 	if chatID == 0 {
 		return 0, fmt.Errorf("Telegram Chat ID is not set in your config.")
@@ -1536,28 +2419,130 @@ func sendSignalMessage(alert *AlertMessage) (int, error) {
 	signalID := alert.SignalID
 	signalID = sanitizeSignalID(signalID)
 
-	settings := userSettings.Get(chatID)
-	// If dynamic calculation is enabled and alert has a nonzero entry, recalc TPs & SL:
-	if settings.DynamicCalculationEnabled && alert.EntryPrice > 0 {
-		recalculateTPAndSL(alert, settings)
+	// Traders get their own copies in private chats, prepared with their own settings
+	broadcast := GetGlobalConfig().BroadcastToTraders
+	alert.ReceivedAt = time.Now()
+	alert.ExpiresAt = alert.ReceivedAt.Add(signalValidity(alert))
+
+	// Signals a filter rejects are only kept for /signals
+	if filter := applySignalFilters(alert); filter != nil {
+		alert.ChatID = chatID
+		alert.Filtered = true
+		alert.RejectedBy = filter.Name
+		signalStore.Set(signalID, alert)
+		recordSignalStatus(alert, SignalRejected)
+		telegramLog.Info("Signal rejected by filter", "signal_id", signalID, "symbol", alert.Symbol, "filter", filter.Name)
+		return 0, nil
+	}
+
+	// Indicators are fetched once and shared with traders' copies
+	client := mainBinanceClient()
+	if client != nil && (broadcast || userSettings.Get(chatID).ShowIndicators || userSettings.Get(chatID).ATRSLMultiplier > 0) {
+		indicators, err := client.signalIndicators(ctx, alert)
+		if err != nil {
+			binanceLog.Warn("Failed to compute indicators", "signal_id", signalID, "symbol", alert.Symbol, "error", err)
+		}
+		alert.Indicators = indicators
 	}
+	original := *alert
+	original.TPs = slices.Clone(alert.TPs)
 
+	prepareSignal(ctx, alert, chatID)
 	signalStore.Set(signalID, alert)
+	recordSignalStatus(alert, SignalReceived)
+	fireWebhookEvent(EventSignalReceived, alert)
+	dismissOutsideHours(alert)
+
+	// The chart goes out just before the signal text so it shows directly above it.
+	// Traders' copies reuse it, so it is rendered whenever broadcasting.
+	showChart := userSettings.Get(chatID).ShowChart
+	var chart []byte
+	if client != nil && (showChart || broadcast) {
+		rendered, err := client.signalChart(ctx, alert)
+		if err != nil {
+			telegramLog.Warn("Failed to render chart", "signal_id", signalID, "symbol", alert.Symbol, "error", err)
+		}
+		chart = rendered
+	}
+
+	// Muted chats and chats in their quiet hours get the signal silently, in a digest or not at all.
+	// Signals outside the watchlist are only kept for /signals.
+	quiet := quietMode(chatID)
+	if filterSignal(chatID, alert) || holdSignal(chatID, signalID, quiet) {
+		telegramLog.Info("Held signal back from chat", "signal_id", signalID, "symbol", alert.Symbol, "chat_id", chatID)
+		if broadcast {
+			sendTraderSignals(ctx, &original, signalID, chart)
+		}
+		return 0, nil
+	}
+
+	if chart != nil && showChart {
+		sendSignalChart(chatID, alert, chart, quiet == QuietModeSilent)
+	}
 
 	messageText := constructSignalMessageText(alert)
 	msg := tgbotapi.NewMessage(chatID, messageText)
 	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = createSignalInlineKeyboard(signalID)
+	msg.DisableNotification = quiet == QuietModeSilent
+	if broadcast {
+		msg.Text += "\n\nTraders confirm this signal in their private chat with the bot."
+	} else if !alert.Dismissed {
+		msg.ReplyMarkup = createSignalInlineKeyboard(chatID, signalID)
+	}
 
 	sentMessage, err := bot.Send(msg)
 	if err != nil {
 		return 0, fmt.Errorf("failed to send signal message: %v", err)
 	}
+	telegramLog.Info("Sent signal", "signal_id", signalID, "symbol", alert.Symbol, "chat_id", chatID, "message_id", sentMessage.MessageID)
+	postToDiscord(msg.Text)
 
 	messageStore.Set(signalID, sentMessage.MessageID)
+	if broadcast {
+		sendTraderSignals(ctx, &original, signalID, chart)
+	}
 	return sentMessage.MessageID, nil
 }
 
+// prepareSignal applies a chat's settings to a signal before it is shown there: dynamic
+// TP/SL recalculation, the funding warning, the liquidation estimate and the account.
+func prepareSignal(ctx context.Context, alert *AlertMessage, chatID int64) {
+	alert.ChatID = chatID
+	settings := userSettings.Get(chatID)
+	// If dynamic calculation is enabled and alert has a nonzero entry, recalc TPs & SL:
+	if settings.DynamicCalculationEnabled && alert.EntryPrice > 0 {
+		recalculateTPAndSL(alert, settings)
+	}
+
+	if client := mainBinanceClient(); client != nil {
+		warning, err := client.fundingWarning(ctx, chatID, alert, settings)
+		if err != nil {
+			binanceLog.Warn("Failed to check funding rate", "signal_id", alert.SignalID, "symbol", alert.Symbol, "error", err)
+		}
+		alert.FundingWarning = warning
+
+		warning, err = client.liquidityWarning(ctx, chatID, alert, applySymbolOverride(chatID, alert.Symbol, settings))
+		if err != nil {
+			binanceLog.Warn("Failed to check liquidity", "signal_id", alert.SignalID, "symbol", alert.Symbol, "error", err)
+		}
+		alert.LiquidityWarning = warning
+
+		// USDT-M brackets don't apply to COIN-M contracts
+		effective := applySymbolOverride(chatID, alert.Symbol, settings)
+		if effective.MarketType != MarketTypeCoinM {
+			info, err := client.liquidationInfo(ctx, alert.Symbol, effective)
+			if err != nil {
+				binanceLog.Warn("Failed to estimate liquidation price", "signal_id", alert.SignalID, "symbol", alert.Symbol, "error", err)
+			}
+			alert.Liquidation = info
+		}
+	}
+
+	alert.Account = resolveAccount(alert)
+	alert.InfoOnly = outsideTradingHours(chatID)
+	alert.NewsHalt = activeNewsHalt(alert.ReceivedAt)
+}
+
 // sanitizeSignalID sanitizes the signal ID to ensure it is safe for usage in callback data.
 func sanitizeSignalID(signalID string) string {
 	re := regexp.MustCompile(`\W`)
@@ -1565,19 +2550,29 @@ func sanitizeSignalID(signalID string) string {
 }
 
 // handleBinanceError provides a user-friendly error message for Binance API errors.
-func handleBinanceError(err error) string {
+func handleBinanceError(chatID int64, err error) string {
+	if guardErr, ok := err.(*TradeGuardError); ok {
+		return guardErr.Text(chatID)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return tr(chatID, "Binance did not respond in time. Check your open orders and positions before trying again, as the order may still have been placed.")
+	}
+	if errors.Is(err, errBinanceBusy) {
+		return tr(chatID, "Binance's request limit is nearly reached. Please try again in a minute.")
+	}
+
 	apiErr, ok := err.(*APIError)
 	if !ok {
 		// If the error is not of type APIError, just return a generic message.
-		return "An unexpected error occurred. Please try again later."
+		return tr(chatID, "An unexpected error occurred. Please try again later.")
 	}
 
 	switch apiErr.Code {
 	case -4131:
-		return "Trade could not be placed because the requested price is outside Binance's allowable range. Please move closer to the current market price and try again."
+		return tr(chatID, "Trade could not be placed because the requested price is outside Binance's allowable range. Please move closer to the current market price and try again.")
 	default:
 		// Instead of returning the raw message, return a generic text to users.
-		return "An unexpected error occurred. Please try again later."
+		return tr(chatID, "An unexpected error occurred. Please try again later.")
 	}
 }
 
@@ -1591,34 +2586,68 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("code=%d, msg=%s", e.Code, e.Message)
 }
 
+// TradeGuardError represents a trade rejected by a pre-trade check before reaching Binance.
+// Its Reason is safe to show to users. It is the English message, formatted with Args, so it
+// can be translated for each chat.
+type TradeGuardError struct {
+	Reason string
+	Args   []interface{}
+}
+
+// newTradeGuardError creates a TradeGuardError whose reason is formatted from an English message.
+func newTradeGuardError(reason string, args ...interface{}) *TradeGuardError {
+	return &TradeGuardError{Reason: reason, Args: args}
+}
+
+func (e *TradeGuardError) Error() string {
+	return translate(LangEnglish, e.Reason, e.Args...)
+}
+
+// Text returns the reason in the chat's language.
+func (e *TradeGuardError) Text(chatID int64) string {
+	return tr(chatID, e.Reason, e.Args...)
+}
+
+// tradeErrorText describes an error for a trade notification: the reason in the chat's language
+// if a pre-trade check refused the trade, otherwise the error itself.
+func tradeErrorText(chatID int64, err error) string {
+	var guardErr *TradeGuardError
+	if errors.As(err, &guardErr) {
+		return guardErr.Text(chatID)
+	}
+	return err.Error()
+}
+
 // showPerformanceOptions displays performance options for different time periods.
 func showPerformanceOptions(chatID int64) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Previous Day", "performance|day"),
-			tgbotapi.NewInlineKeyboardButtonData("Previous Week", "performance|week"),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Previous Day"), "performance|day"),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Previous Week"), "performance|week"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Previous Month", "performance|month"),
-			tgbotapi.NewInlineKeyboardButtonData("Previous Year", "performance|year"),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Previous Month"), "performance|month"),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Previous Year"), "performance|year"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Recent Years", "performance|years"),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Recent Years"), "performance|years"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, "Select the time period for performance data:")
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Select the time period for performance data:"))
 	msg.ReplyMarkup = keyboard
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send performance options: %v", err)
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send performance options", "chat_id", chatID, "error", err)
 	}
+	trackMessage(sent, messageKindMenu)
 }
 
 // showPerformanceData fetches and displays performance data for a given time period.
 func showPerformanceData(chatID int64, timePeriod string) {
 	trades, err := GetTradesForPeriod(timePeriod)
 	if err != nil {
-		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Failed to fetch trade data: %v", err)))
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to fetch trade data: %v", err)))
 		return
 	}
 
@@ -1627,31 +2656,49 @@ func showPerformanceData(chatID int64, timePeriod string) {
 	var title string
 	switch timePeriod {
 	case "day":
-		title = "Performance Summary for Previous Day"
+		title = tr(chatID, "Performance Summary for Previous Day")
 	case "week":
-		title = "Performance Summary for Previous Week"
+		title = tr(chatID, "Performance Summary for Previous Week")
 	case "month":
-		title = "Performance Summary for Previous Month"
+		title = tr(chatID, "Performance Summary for Previous Month")
 	case "year":
-		title = "Performance Summary for Previous Year"
+		title = tr(chatID, "Performance Summary for Previous Year")
 	default:
-		title = "Performance Summary"
+		title = tr(chatID, "Performance Summary")
 	}
 
-	msgText := fmt.Sprintf("%s:\n%s", title, formatPerformanceData(performanceData))
+	msgText := fmt.Sprintf("%s:\n", title)
+	if start, now := calculateStartTime(timePeriod), time.Now(); start.Before(now) {
+		msgText += tr(chatID, "From %s to %s\n", formatUserTime(chatID, start), formatUserTime(chatID, now))
+	}
+	msgText += formatPerformanceData(chatID, performanceData)
+	msgText += formatStrategyBreakdown(chatID, trades)
+	msgText += benchmarkText(chatID, calculateStartTime(timePeriod), performanceData)
+
+	// The equity curve carries the summary as its caption; without trades there is nothing to draw
+	if len(trades) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, msgText))
+		return
+	}
+	keyboard := breakdownKeyboard(chatID, timePeriod, "")
+	if sendPerformanceChart(chatID, calculateStartTime(timePeriod), trades, msgText, keyboard) {
+		return
+	}
 	msg := tgbotapi.NewMessage(chatID, msgText)
+	msg.ReplyMarkup = keyboard
 	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Failed to send performance data: %v", err)
+		telegramLog.Error("Failed to send performance data", "chat_id", chatID, "error", err)
 	}
 }
 
 // Calculate Performance Metrics
 func calculatePerformanceMetrics(trades []Trade) PerformanceData {
-	var totalTrades, winningTrades, losingTrades int
-	var totalProfit, totalLoss float64
+	var totalTrades, winningTrades, losingTrades, rTrades int
+	var totalProfit, totalLoss, totalFees, totalR float64
 
 	for _, trade := range trades {
 		totalTrades++
+		totalFees += trade.Fees()
 		if trade.Profit > 0 {
 			winningTrades++
 			totalProfit += trade.Profit
@@ -1659,12 +2706,24 @@ func calculatePerformanceMetrics(trades []Trade) PerformanceData {
 			losingTrades++
 			totalLoss += trade.Profit
 		}
+		if r, ok := trade.RMultiple(); ok {
+			rTrades++
+			totalR += r
+		}
 	}
 
-	winLossRatio := float64(winningTrades) / float64(totalTrades)
-	averageProfit := totalProfit / float64(winningTrades)
-	averageLoss := totalLoss / float64(losingTrades)
+	// Averages and ratios of no trades are 0
+	ratio := func(a, b float64) float64 {
+		if b == 0 {
+			return 0
+		}
+		return a / b
+	}
+	winLossRatio := ratio(float64(winningTrades), float64(totalTrades))
+	averageProfit := ratio(totalProfit, float64(winningTrades))
+	averageLoss := ratio(totalLoss, float64(losingTrades))
 	netProfit := totalProfit + totalLoss
+	sharpe, sortino := riskRatios(trades)
 
 	return PerformanceData{
 		TotalTrades:   totalTrades,
@@ -1675,13 +2734,24 @@ func calculatePerformanceMetrics(trades []Trade) PerformanceData {
 		AverageLoss:   averageLoss,
 		TotalProfit:   totalProfit,
 		TotalLoss:     totalLoss,
+		TotalFees:     totalFees,
+		GrossProfit:   netProfit + totalFees,
 		NetProfit:     netProfit,
+		MaxDrawdown:   maxDrawdown(equityCurve(trades)),
+		ProfitFactor:  ratio(totalProfit, -totalLoss),
+		Expectancy:    ratio(netProfit, float64(totalTrades)),
+		SharpeRatio:   sharpe,
+		SortinoRatio:  sortino,
+		TotalR:        totalR,
+		AverageR:      ratio(totalR, float64(rTrades)),
+		RTrades:       rTrades,
+		LosingStreak:  longestLosingStreak(trades),
 	}
 }
 
 // Example function to format performance data for display
-func formatPerformanceData(data PerformanceData) string {
-	return fmt.Sprintf(
+func formatPerformanceData(chatID int64, data PerformanceData) string {
+	return tr(chatID,
 		"Performance Summary:\n"+
 			"Total Trades: %d\n"+
 			"Winning Trades: %d\n"+
@@ -1691,7 +2761,10 @@ func formatPerformanceData(data PerformanceData) string {
 			"Average Loss: %.2f\n"+
 			"Total Profit: %.2f\n"+
 			"Total Loss: %.2f\n"+
-			"Net Profit: %.2f\n",
+			"Gross Profit: %.2f\n"+
+			"Fees: %.2f\n"+
+			"Net Profit: %.2f\n"+
+			"Max Drawdown: %.2f\n",
 		data.TotalTrades,
 		data.WinningTrades,
 		data.LosingTrades,
@@ -1700,6 +2773,26 @@ func formatPerformanceData(data PerformanceData) string {
 		data.AverageLoss,
 		data.TotalProfit,
 		data.TotalLoss,
+		data.GrossProfit,
+		data.TotalFees,
 		data.NetProfit,
-	)
+		data.MaxDrawdown,
+	) + formatRiskMetrics(chatID, data)
+}
+
+// formatRiskMetrics renders the risk metrics of performance data, leaving out the profit factor
+// without losses and the average R-multiple without trades of known risk.
+func formatRiskMetrics(chatID int64, data PerformanceData) string {
+	text := ""
+	if data.ProfitFactor > 0 {
+		text += tr(chatID, "Profit Factor: %.2f\n", data.ProfitFactor)
+	}
+	text += tr(chatID, "Expectancy: %.2f per trade\n", data.Expectancy)
+	text += tr(chatID, "Sharpe/Sortino (per trade): %.2f / %.2f\n", data.SharpeRatio, data.SortinoRatio)
+	if data.RTrades > 0 {
+		text += tr(chatID, "Net R: %+.2fR\n", data.TotalR)
+		text += tr(chatID, "Average R: %+.2fR over %d trades\n", data.AverageR, data.RTrades)
+	}
+	text += tr(chatID, "Longest Losing Streak: %d\n", data.LosingStreak)
+	return text
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TelegramUser is a Telegram user or chat the bot knows about, as listed in the admin panel.
+type TelegramUser struct {
+	ID        int64
+	Chat      bool   // A group or channel rather than a user, so it has no role
+	Role      string // Empty for chats
+	Trading   bool   // Whether the role lets the user confirm signals
+	Connected bool   // Trades on their own Binance account (/connect)
+	Settings  UserSettings
+}
+
+// listTelegramUsers returns the users and chats with settings, a role, Binance credentials or
+// settings profiles, and the configured chat and admin user, by ID.
+func listTelegramUsers() ([]TelegramUser, error) {
+	config := GetGlobalConfig()
+	ids := userSettings.IDs()
+	if config.TelegramChatID != 0 {
+		ids = append(ids, config.TelegramChatID)
+	}
+	if config.AdminUserID != 0 {
+		ids = append(ids, config.AdminUserID)
+	}
+	roles, err := ListUserRoles()
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		ids = append(ids, role.UserID)
+	}
+	var connected, profiles []int64
+	if err := db.Model(&UserCredential{}).Pluck("user_id", &connected).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve connected users: %w", err)
+	}
+	if err := db.Model(&SettingsProfile{}).Distinct().Pluck("user_id", &profiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve profile users: %w", err)
+	}
+	ids = append(append(ids, connected...), profiles...)
+
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	users := make([]TelegramUser, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, telegramUser(id, slices.Contains(connected, id)))
+	}
+	return users, nil
+}
+
+// telegramUser describes the user or chat with the ID.
+func telegramUser(id int64, connected bool) TelegramUser {
+	user := TelegramUser{ID: id, Chat: id < 0, Connected: connected, Settings: *userSettings.Get(id)}
+	if !user.Chat {
+		user.Role = GetUserRole(id)
+		user.Trading = roleRanks[user.Role] >= roleRanks[RoleTrader]
+	}
+	return user
+}
+
+// formatTPLevels writes TP levels as "distance:close%" pairs, e.g. "0.75:60, 1.5:40".
+func formatTPLevels(levels []TPLevel) string {
+	parts := make([]string, len(levels))
+	for i, level := range levels {
+		parts[i] = formatFloat(level.Percentage) + ":" + formatFloat(level.ClosePct)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseTPLevels reads TP levels written by formatTPLevels.
+func parseTPLevels(text string) ([]TPLevel, error) {
+	var levels []TPLevel
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		distance, closePct, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("TP levels must be distance:close pairs, e.g. 0.75:60, 1.5:40")
+		}
+		var level TPLevel
+		var err error
+		if level.Percentage, err = parseFormFloat(strings.TrimSpace(distance), 0, 1000); err != nil {
+			return nil, fmt.Errorf("Invalid TP distance %q: %v", distance, err)
+		}
+		if level.ClosePct, err = parseFormFloat(strings.TrimSpace(closePct), 0, 100); err != nil {
+			return nil, fmt.Errorf("Invalid TP close percentage %q: %v", closePct, err)
+		}
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("At least one TP level is required")
+	}
+	if len(levels) > maxTPLevels {
+		return nil, fmt.Errorf("At most %d TP levels can be set up", maxTPLevels)
+	}
+	return levels, nil
+}
+
+// parseFormFloat parses a number from a form field within min and max.
+func parseFormFloat(text string, min, max float64) (float64, error) {
+	val, err := strconv.ParseFloat(text, 64)
+	if err != nil || val < min || val > max {
+		return 0, fmt.Errorf("enter a number between %s and %s", strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64))
+	}
+	return val, nil
+}
+
+// parseUserSettingsForm applies the admin panel's settings form to a copy of settings, with
+// the same limits as editing them in Telegram.
+func parseUserSettingsForm(form func(string) string, settings UserSettings) (UserSettings, error) {
+	leverage, err := strconv.Atoi(form("leverage"))
+	if err != nil || leverage <= 0 || leverage > 125 {
+		return settings, fmt.Errorf("Leverage must be a whole number from 1 to 125")
+	}
+	settings.Leverage = leverage
+	if settings.AmountUSDT, err = parseFormFloat(form("amount_usdt"), 0, 1000000); err != nil {
+		return settings, fmt.Errorf("Invalid amount: %v", err)
+	}
+
+	marginMode := form("margin_mode")
+	if marginMode != "Cross" && marginMode != "Isolated" {
+		return settings, fmt.Errorf("Invalid margin mode")
+	}
+	settings.MarginMode = marginMode
+	tradingMode := form("trading_mode")
+	if tradingMode != "Market" && tradingMode != "Limit" && tradingMode != TradingModeStop {
+		return settings, fmt.Errorf("Invalid trading mode")
+	}
+	settings.TradingMode = tradingMode
+
+	settings.UseSL = form("use_sl") == "on"
+	settings.AutoCalculateTPs = form("auto_calculate_tps") == "on"
+	for name, value := range map[string]*float64{
+		"manual_sl_percentage": &settings.ManualSLPercentage,
+		"auto_sl_percentage":   &settings.AutoSLPercentage,
+		"auto_tp_percentage":   &settings.AutoTPPercentage,
+	} {
+		if *value, err = parseFormFloat(form(name), 0, 100); err != nil {
+			return settings, fmt.Errorf("Invalid %s: %v", strings.ReplaceAll(name, "_", " "), err)
+		}
+	}
+	if settings.TPLevels, err = parseTPLevels(form("tp_levels")); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
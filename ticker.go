@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// Refresh intervals a position ticker may be set to, in minutes.
+const (
+	defaultTickerMinutes = 5
+	maxTickerMinutes     = 60
+)
+
+// tickerCheckInterval is how often the scheduler looks for tickers due a refresh.
+const tickerCheckInterval = time.Minute
+
+// PositionTicker is a pinned message in a chat that the bot keeps editing with the open
+// positions on the account of the user who started it.
+type PositionTicker struct {
+	ID              uint  `gorm:"primaryKey"`
+	ChatID          int64 `gorm:"uniqueIndex"`
+	UserID          int64
+	MessageID       int
+	IntervalMinutes int
+	UpdatedAt       time.Time
+}
+
+// Due reports whether the ticker's interval has passed since it was last refreshed.
+func (t *PositionTicker) Due(now time.Time) bool {
+	return !now.Before(t.UpdatedAt.Add(time.Duration(t.IntervalMinutes) * time.Minute))
+}
+
+// GetPositionTicker retrieves the chat's ticker, or nil if it has none.
+func GetPositionTicker(chatID int64) (*PositionTicker, error) {
+	var ticker PositionTicker
+	if err := db.Where("chat_id = ?", chatID).First(&ticker).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve position ticker: %w", err)
+	}
+	return &ticker, nil
+}
+
+// SavePositionTicker creates the chat's ticker, replacing any it had.
+func SavePositionTicker(ticker *PositionTicker) error {
+	if err := db.Where("chat_id = ?", ticker.ChatID).Delete(&PositionTicker{}).Error; err != nil {
+		return fmt.Errorf("failed to delete position ticker: %w", err)
+	}
+	if err := db.Create(ticker).Error; err != nil {
+		return fmt.Errorf("failed to save position ticker: %w", err)
+	}
+	return nil
+}
+
+// DeletePositionTicker removes the chat's ticker.
+func DeletePositionTicker(chatID int64) error {
+	if err := db.Where("chat_id = ?", chatID).Delete(&PositionTicker{}).Error; err != nil {
+		return fmt.Errorf("failed to delete position ticker: %w", err)
+	}
+	return nil
+}
+
+// tickerText renders the open positions on the ticker user's account with the time they were fetched.
+func tickerText(chatID, userID int64) (string, error) {
+	exchange, account, err := userAccountExchange(userID)
+	if err != nil {
+		return "", fmt.Errorf("account %s is unavailable: %v", account, err)
+	}
+	positions, err := exchange.Positions(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to get positions: %v", err)
+	}
+	return formatPositions(chatID, account, positions) + tr(chatID, "\n\n<i>Updated %s</i>", formatUserTime(chatID, time.Now())), nil
+}
+
+// handleTickerCommand starts a pinned positions message refreshed every few minutes with
+// "/ticker [minutes]", or stops it with "/ticker off".
+func handleTickerCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+
+	previous, err := GetPositionTicker(chatID)
+	if err != nil {
+		telegramLog.Error("Failed to get position ticker", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to update the ticker: %v", err)))
+		return
+	}
+
+	if arg == "off" {
+		if previous == nil {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "No position ticker is running in this chat.")))
+			return
+		}
+		if err := DeletePositionTicker(chatID); err != nil {
+			telegramLog.Error("Failed to delete position ticker", "chat_id", chatID, "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to update the ticker: %v", err)))
+			return
+		}
+		unpinTicker(previous)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Position ticker stopped.")))
+		return
+	}
+
+	minutes := defaultTickerMinutes
+	if arg != "" {
+		minutes, err = strconv.Atoi(arg)
+		if err != nil || minutes < 1 || minutes > maxTickerMinutes {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /ticker [1-%d minutes|off]", maxTickerMinutes)))
+			return
+		}
+	}
+
+	userID := senderID(message)
+	text, err := tickerText(chatID, userID)
+	if err != nil {
+		telegramLog.Error("Failed to start position ticker", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to start the ticker: %v", err)))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send position ticker", "chat_id", chatID, "error", err)
+		return
+	}
+
+	ticker := &PositionTicker{ChatID: chatID, UserID: userID, MessageID: sent.MessageID, IntervalMinutes: minutes, UpdatedAt: time.Now()}
+	if err := SavePositionTicker(ticker); err != nil {
+		telegramLog.Error("Failed to save position ticker", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to start the ticker: %v", err)))
+		return
+	}
+	if previous != nil {
+		unpinTicker(previous)
+	}
+
+	reply := tr(chatID, "Position ticker started: this message is refreshed every %d minutes. Stop it with /ticker off.", minutes)
+	pin := tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: sent.MessageID, DisableNotification: true}
+	if _, err := bot.Request(pin); err != nil {
+		telegramLog.Error("Failed to pin position ticker", "chat_id", chatID, "error", err)
+		reply += tr(chatID, "\nThe message could not be pinned; give the bot permission to pin messages to pin it.")
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, reply))
+}
+
+// unpinTicker unpins a ticker's message, leaving it in the chat with its last positions.
+func unpinTicker(ticker *PositionTicker) {
+	unpin := tgbotapi.UnpinChatMessageConfig{ChatID: ticker.ChatID, MessageID: ticker.MessageID}
+	if _, err := bot.Request(unpin); err != nil {
+		telegramLog.Error("Failed to unpin position ticker", "chat_id", ticker.ChatID, "error", err)
+	}
+}
+
+// refreshTicker edits a ticker's message with the current positions. Tickers whose message was
+// deleted are removed.
+func refreshTicker(ticker *PositionTicker) {
+	text, err := tickerText(ticker.ChatID, ticker.UserID)
+	if err != nil {
+		telegramLog.Error("Failed to refresh position ticker", "chat_id", ticker.ChatID, "error", err)
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(ticker.ChatID, ticker.MessageID, text)
+	edit.ParseMode = "HTML"
+	if _, err := bot.Send(edit); err != nil {
+		if strings.Contains(err.Error(), "message to edit not found") {
+			telegramLog.Info("Position ticker message was deleted, stopping the ticker", "chat_id", ticker.ChatID)
+			if err := DeletePositionTicker(ticker.ChatID); err != nil {
+				telegramLog.Error("Failed to delete position ticker", "error", err)
+			}
+			return
+		}
+		if !strings.Contains(err.Error(), "message is not modified") {
+			telegramLog.Error("Failed to edit position ticker", "chat_id", ticker.ChatID, "error", err)
+			return
+		}
+	}
+	if err := db.Model(ticker).Update("updated_at", time.Now()).Error; err != nil {
+		telegramLog.Error("Failed to save position ticker", "error", err)
+	}
+}
+
+var tickerSchedulerStart sync.Once
+
+// startPositionTickers refreshes each chat's position ticker once its interval has passed.
+func startPositionTickers() {
+	tickerSchedulerStart.Do(func() {
+		go func() {
+			ticker := time.NewTicker(tickerCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-shuttingDown:
+					return
+				case now := <-ticker.C:
+					if bot == nil {
+						continue
+					}
+					var tickers []PositionTicker
+					if err := db.Find(&tickers).Error; err != nil {
+						telegramLog.Error("Failed to retrieve position tickers", "error", err)
+						continue
+					}
+					for i := range tickers {
+						if tickers[i].Due(now) {
+							refreshTicker(&tickers[i])
+						}
+					}
+				}
+			}
+		}()
+	})
+}
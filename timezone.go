@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezone database for hosts without one, e.g. minimal containers
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Time formats a user can choose for displayed times.
+const (
+	TimeFormat24h = "24h"
+	TimeFormat12h = "12h"
+)
+
+var timeLayouts = map[string]string{
+	TimeFormat24h: "2006-01-02 15:04:05 MST",
+	TimeFormat12h: "2006-01-02 03:04:05 PM MST",
+}
+
+// signalTimeLayouts are the text formats accepted for alert times besides unix timestamps.
+var signalTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseSignalTime parses an alert time given as RFC3339, a plain date-time (taken as UTC)
+// or a unix timestamp in seconds or milliseconds.
+func parseSignalTime(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if unix > 1e12 {
+			return time.UnixMilli(unix).UTC(), true
+		}
+		return time.Unix(unix, 0).UTC(), true
+	}
+	for _, layout := range signalTimeLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// userLocation returns the chat's configured timezone, falling back to UTC.
+func userLocation(chatID int64) *time.Location {
+	loc, err := time.LoadLocation(userSettings.Get(chatID).Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// formatUserTime renders t in the chat's timezone and time format.
+func formatUserTime(chatID int64, t time.Time) string {
+	layout, ok := timeLayouts[userSettings.Get(chatID).TimeFormat]
+	if !ok {
+		layout = timeLayouts[TimeFormat24h]
+	}
+	return t.In(userLocation(chatID)).Format(layout)
+}
+
+// formatSignalTime renders an alert time in the chat's timezone. Unrecognized times are shown as received.
+func formatSignalTime(chatID int64, raw string) string {
+	t, ok := parseSignalTime(raw)
+	if !ok {
+		return raw
+	}
+	return formatUserTime(chatID, t)
+}
+
+// promptTimezone asks the user for an IANA timezone name.
+func promptTimezone(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter your timezone, e.g. Europe/Madrid, America/New_York or UTC."))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	editingUsers.Set(chatID, &EditingState{SettingName: "Timezone"})
+}
+
+// toggleTimeFormat switches displayed times between the 24-hour and 12-hour clock.
+func toggleTimeFormat(chatID int64) {
+	settings := userSettings.Get(chatID)
+	if settings.TimeFormat == TimeFormat12h {
+		settings.TimeFormat = TimeFormat24h
+	} else {
+		settings.TimeFormat = TimeFormat12h
+	}
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Time Format has been set to %s.", settings.TimeFormat))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
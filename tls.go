@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Defaults for the listeners and certificate cache when HTTPS is on.
+const (
+	defaultTLSPort     = 443
+	defaultTLSHTTPPort = 80
+	defaultTLSCacheDir = "certs"
+)
+
+// TLSSettings is how the server serves HTTPS: from certificate files, from Let's Encrypt
+// certificates for Domains, or not at all, in which case it serves plain HTTP on ServerPort.
+type TLSSettings struct {
+	CertFile string
+	KeyFile  string
+	Domains  []string // Managed with Let's Encrypt
+	CacheDir string   // Where Let's Encrypt certificates are kept across restarts
+	Email    string   // Let's Encrypt contact for expiry notices, optional
+	Port     int      // HTTPS port
+	HTTPPort int      // Port redirecting to HTTPS and answering Let's Encrypt challenges, 0 for none
+}
+
+// Enabled reports whether the server serves HTTPS.
+func (s TLSSettings) Enabled() bool {
+	return s.CertFile != "" || len(s.Domains) > 0
+}
+
+// loadTLSSettings reads the HTTPS settings from the environment.
+func loadTLSSettings() (TLSSettings, error) {
+	settings := TLSSettings{
+		CertFile: os.Getenv("TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("TLS_KEY_FILE"),
+		CacheDir: os.Getenv("TLS_CACHE_DIR"),
+		Email:    os.Getenv("TLS_EMAIL"),
+		Port:     envInt("TLS_PORT", defaultTLSPort),
+		HTTPPort: envInt("TLS_HTTP_PORT", defaultTLSHTTPPort),
+	}
+	for _, domain := range strings.Split(os.Getenv("TLS_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			settings.Domains = append(settings.Domains, domain)
+		}
+	}
+	if settings.CacheDir == "" {
+		settings.CacheDir = defaultTLSCacheDir
+	}
+
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return settings, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if settings.CertFile != "" && len(settings.Domains) > 0 {
+		return settings, fmt.Errorf("set either TLS_CERT_FILE and TLS_KEY_FILE or TLS_DOMAINS, not both")
+	}
+	if settings.Enabled() && settings.Port == 0 {
+		return settings, fmt.Errorf("TLS_PORT must be a port number")
+	}
+	return settings, nil
+}
+
+// secureCookies reports whether session and CSRF cookies are limited to HTTPS. They are when
+// the server serves HTTPS itself; set SECURE_COOKIES=true behind a reverse proxy that does.
+func secureCookies(settings TLSSettings) bool {
+	value := os.Getenv("SECURE_COOKIES")
+	if value == "" {
+		return settings.Enabled()
+	}
+	secure, err := strconv.ParseBool(value)
+	if err != nil {
+		adminLog.Warn("Invalid SECURE_COOKIES, using the default", "value", value, "secure_cookies", settings.Enabled())
+		return settings.Enabled()
+	}
+	return secure
+}
+
+// configureTLS sets up server to serve HTTPS with settings and returns the server for
+// settings.HTTPPort, or nil if there is none. Start server with ListenAndServeTLS("", "").
+func configureTLS(server *http.Server, settings TLSSettings) (*http.Server, error) {
+	server.Addr = ":" + strconv.Itoa(settings.Port)
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirectToHTTPS(w, r, settings.Port)
+	})
+	var httpHandler http.Handler = redirect
+
+	if len(settings.Domains) > 0 {
+		if err := os.MkdirAll(settings.CacheDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create certificate cache: %w", err)
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(settings.Domains...),
+			Cache:      autocert.DirCache(settings.CacheDir),
+			Email:      settings.Email,
+		}
+		// Besides certificates, the manager answers TLS-ALPN challenges on the HTTPS port
+		server.TLSConfig = manager.TLSConfig()
+		server.TLSConfig.MinVersion = tls.VersionTLS12
+		httpHandler = manager.HTTPHandler(redirect)
+	} else {
+		certificate, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		server.TLSConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if settings.HTTPPort == 0 {
+		return nil, nil
+	}
+	return &http.Server{
+		Addr:         ":" + strconv.Itoa(settings.HTTPPort),
+		Handler:      httpHandler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}, nil
+}
+
+// redirectToHTTPS sends a plain HTTP request to the same URL over HTTPS. The redirect keeps
+// the method and body, so webhooks posted to http:// still arrive.
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request, port int) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if port != defaultTLSPort {
+		host = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+}
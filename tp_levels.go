@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxTPLevels caps the number of take profit levels so signal messages and keyboards stay readable.
+const maxTPLevels = 6
+
+// TPLevel is one take profit level in a user's settings.
+type TPLevel struct {
+	Percentage float64 // Distance from entry, e.g. 1.5 for +1.5%
+	ClosePct   float64 // Share of the position closed at this level
+}
+
+// defaultTPLevels returns the TP levels new users start with.
+func defaultTPLevels() []TPLevel {
+	return []TPLevel{
+		{Percentage: 0.75, ClosePct: 60},
+		{Percentage: 1.5, ClosePct: 20},
+		{Percentage: 2.0, ClosePct: 20},
+	}
+}
+
+// UnmarshalJSON reads the TPs from "tps" or, as older alerts send them, from "tp1", "tp2", ... keys.
+func (a *AlertMessage) UnmarshalJSON(data []byte) error {
+	type alertMessage AlertMessage
+	if err := json.Unmarshal(data, (*alertMessage)(a)); err != nil {
+		return err
+	}
+	if len(a.TPs) > 0 {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	tps := make([]float64, maxTPLevels)
+	for i := range tps {
+		raw, ok := fields[fmt.Sprintf("tp%d", i+1)]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, &tps[i]); err != nil {
+			return fmt.Errorf("invalid tp%d: %w", i+1, err)
+		}
+	}
+	for len(tps) > 0 && tps[len(tps)-1] == 0 {
+		tps = tps[:len(tps)-1]
+	}
+	a.TPs = tps
+	return nil
+}
+
+// signalTP returns the signal's TP at the zero-based level, or 0 if it has none there.
+func signalTP(signal *AlertMessage, level int) float64 {
+	if level < len(signal.TPs) {
+		return signal.TPs[level]
+	}
+	return 0
+}
+
+// tpField returns the zero-based level for a signal field name such as "TP2".
+func tpField(fieldName string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(fieldName, "TP"))
+	if !strings.HasPrefix(fieldName, "TP") || err != nil || n < 1 || n > maxTPLevels {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// tpLevelSetting splits a TP level setting name such as "TP2Percentage" or "TP4ClosePct" into the
+// zero-based level and the kind of value, "Percentage" or "ClosePct".
+func tpLevelSetting(settingName string) (int, string, bool) {
+	for _, kind := range []string{"Percentage", "ClosePct"} {
+		if name, ok := strings.CutSuffix(settingName, kind); ok {
+			if level, ok := tpField(name); ok {
+				return level, kind, true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+// tpPrices returns one TP price per level at the levels' distances from entry.
+func tpPrices(signalType string, entry float64, levels []TPLevel) []float64 {
+	direction := 1.0
+	if signalType == "Sell" {
+		direction = -1
+	}
+	tps := make([]float64, len(levels))
+	for i, level := range levels {
+		tps[i] = roundToSixDecimal(entry * (1 + direction*level.Percentage/100.0))
+	}
+	return tps
+}
+
+// placedTPs returns the TPs orders are placed for: only TP1 when auto-calculating, otherwise
+// one per level.
+func placedTPs(tps []float64, settings *UserSettings) []float64 {
+	if settings.AutoCalculateTPs && len(tps) > 1 {
+		return tps[:1]
+	}
+	return tps
+}
+
+// tpCloseQuantities returns the quantity each TP closes: its level's ClosePct share of quantity,
+// rounded down to the step size, and "" for the last TP with a price, which closes the rest of
+// the position. It returns false if a share rounds to nothing or they leave nothing for the
+// last TP.
+func tpCloseQuantities(quantity, step float64, tps []float64, levels []TPLevel) ([]string, bool) {
+	last := -1
+	for i, tp := range tps {
+		if tp > 0 {
+			last = i
+		}
+	}
+	quantities := make([]string, len(tps))
+	var total float64
+	for i := 0; i < last; i++ {
+		if tps[i] <= 0 {
+			continue
+		}
+		var closePct float64
+		if i < len(levels) {
+			closePct = levels[i].ClosePct
+		}
+		// The epsilon keeps shares that are whole steps from rounding down a step
+		share := math.Floor(quantity*closePct/100/step+1e-9) * step
+		if share <= 0 {
+			return nil, false
+		}
+		total += share
+		quantities[i] = formatDecimal(share, step)
+	}
+	if math.Round((quantity-total)/step) < 1 {
+		return nil, false
+	}
+	return quantities, true
+}
+
+// legacyTPLevels converts the fixed TP1-TP3 settings of profiles saved before TP levels were a list.
+// It returns nil if the data already has TP levels or no TP settings at all.
+func legacyTPLevels(data []byte) []TPLevel {
+	var legacy struct {
+		TPLevels                                    []TPLevel
+		TP1Percentage, TP2Percentage, TP3Percentage float64
+		TP1ClosePct, TP2ClosePct, TP3ClosePct       float64
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil || legacy.TPLevels != nil || legacy.TP1Percentage == 0 {
+		return nil
+	}
+	return []TPLevel{
+		{Percentage: legacy.TP1Percentage, ClosePct: legacy.TP1ClosePct},
+		{Percentage: legacy.TP2Percentage, ClosePct: legacy.TP2ClosePct},
+		{Percentage: legacy.TP3Percentage, ClosePct: legacy.TP3ClosePct},
+	}
+}
+
+// handleTPLevelValue stores a typed value for a TP level setting, telling the user and
+// returning false if it is invalid.
+func handleTPLevelValue(chatID int64, settings *UserSettings, settingName, text string) bool {
+	level, kind, ok := tpLevelSetting(settingName)
+	if !ok {
+		return true
+	}
+	if level >= len(settings.TPLevels) {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "TP%d is not set up. Use Add TP first.", level+1)))
+		return false
+	}
+
+	limit := 1000.0
+	if kind == "ClosePct" {
+		limit = 100
+	}
+	val, err := strconv.ParseFloat(text, 64)
+	if err != nil || val < 0 || val > limit {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Invalid percentage. Please enter a number between 0 and %.0f.", limit)))
+		return false
+	}
+
+	levels := slices.Clone(settings.TPLevels)
+	if kind == "ClosePct" {
+		levels[level].ClosePct = val
+	} else {
+		levels[level].Percentage = val
+	}
+	settings.TPLevels = levels
+
+	// A close percentage of 0 removes the level; the last level closes what is left
+	adjustTPClosePercentages(settings)
+	return true
+}
+
+// addTPLevel appends a TP level past the last one, splitting the last level's close percentage with it.
+func addTPLevel(chatID int64) {
+	settings := userSettings.Get(chatID)
+	if len(settings.TPLevels) >= maxTPLevels {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "You can set up at most %d TPs.", maxTPLevels)))
+		return
+	}
+
+	levels := slices.Clone(settings.TPLevels)
+	last := &levels[len(levels)-1]
+	step := last.Percentage
+	if len(levels) > 1 {
+		if gap := last.Percentage - levels[len(levels)-2].Percentage; gap > 0 {
+			step = gap
+		}
+	}
+	last.ClosePct /= 2
+	levels = append(levels, TPLevel{Percentage: last.Percentage + step, ClosePct: last.ClosePct})
+	settings.TPLevels = levels
+	userSettings.Set(chatID, settings)
+	updatePendingSignals(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "TP%d has been added.", len(settings.TPLevels)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+	showSettingsMenu(chatID)
+}
+
+// removeTPLevel drops the last TP level; the level before it then closes the rest of the position.
+func removeTPLevel(chatID int64) {
+	settings := userSettings.Get(chatID)
+	if len(settings.TPLevels) <= 1 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "At least one TP is required.")))
+		return
+	}
+
+	removed := len(settings.TPLevels)
+	settings.TPLevels = slices.Clone(settings.TPLevels[:removed-1])
+	userSettings.Set(chatID, settings)
+	updatePendingSignals(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "TP%d has been removed.", removed))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+	showSettingsMenu(chatID)
+}
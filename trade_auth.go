@@ -0,0 +1,434 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// tradeCodeTimeout is how long a user has to enter their code after pressing Confirm.
+const tradeCodeTimeout = 60 * time.Second
+
+// maxFailedTradeCodes wrong codes in a row lock a user's trade code for tradeCodeLockout.
+const (
+	maxFailedTradeCodes = 5
+	tradeCodeLockout    = 15 * time.Minute
+)
+
+// Trade code failures returned by TradeAuth.Check.
+var (
+	errWrongTradeCode  = errors.New("wrong trade code")
+	errTradeCodeLocked = errors.New("trade code locked")
+)
+
+// TOTP parameters, matching the defaults of common authenticator apps.
+const (
+	totpStep   = 30 * time.Second
+	totpDigits = 6
+)
+
+// TradeAuth holds the PIN or TOTP secret a user must enter before their confirmations execute.
+type TradeAuth struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      int64  `gorm:"uniqueIndex"`
+	PINHash     string // bcrypt hash of the PIN, if a PIN is used
+	TOTPSecret  string `gorm:"serializer:encrypted"` // Base32 secret, if an authenticator app is used
+	FailedCodes int    // Wrong codes since the last right one or lockout
+	LockedUntil time.Time
+}
+
+// Locked reports whether the trade code is locked after too many wrong codes.
+func (a *TradeAuth) Locked() bool {
+	return time.Now().Before(a.LockedUntil)
+}
+
+// GetTradeAuth retrieves a user's trade code settings, or nil if they have none.
+func GetTradeAuth(userID int64) (*TradeAuth, error) {
+	var auth TradeAuth
+	err := db.Where("user_id = ?", userID).First(&auth).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve trade code: %w", err)
+	}
+	return &auth, nil
+}
+
+// SaveTradeAuth creates or replaces a user's trade code with a PIN hash or a TOTP secret.
+func SaveTradeAuth(userID int64, pinHash, totpSecret string) error {
+	auth, err := GetTradeAuth(userID)
+	if err != nil {
+		return err
+	}
+	if auth == nil {
+		auth = &TradeAuth{UserID: userID}
+	}
+	auth.PINHash = pinHash
+	auth.TOTPSecret = totpSecret
+	auth.FailedCodes = 0
+	auth.LockedUntil = time.Time{}
+	if err := db.Save(auth).Error; err != nil {
+		return fmt.Errorf("failed to save trade code: %w", err)
+	}
+	return nil
+}
+
+// DeleteTradeAuth removes a user's trade code.
+func DeleteTradeAuth(userID int64) error {
+	if err := db.Where("user_id = ?", userID).Delete(&TradeAuth{}).Error; err != nil {
+		return fmt.Errorf("failed to delete trade code: %w", err)
+	}
+	return nil
+}
+
+// Verify reports whether code is the user's PIN or a current TOTP code.
+func (a *TradeAuth) Verify(code string) bool {
+	if a.TOTPSecret != "" {
+		return verifyTOTP(a.TOTPSecret, code, time.Now())
+	}
+	return bcrypt.CompareHashAndPassword([]byte(a.PINHash), []byte(code)) == nil
+}
+
+// Check verifies a code like Verify, counting wrong codes towards locking the trade code. Codes
+// for a locked trade code are refused without checking them.
+func (a *TradeAuth) Check(code string) error {
+	if a.Locked() {
+		return errTradeCodeLocked
+	}
+	if a.Verify(code) {
+		if a.FailedCodes > 0 {
+			a.FailedCodes = 0
+			if err := db.Model(a).Update("failed_codes", 0).Error; err != nil {
+				telegramLog.Error("Failed to reset wrong trade codes", "user_id", a.UserID, "error", err)
+			}
+		}
+		return nil
+	}
+
+	a.FailedCodes++
+	if a.FailedCodes >= maxFailedTradeCodes {
+		a.FailedCodes = 0
+		a.LockedUntil = time.Now().Add(tradeCodeLockout)
+	}
+	if err := db.Model(a).Updates(map[string]interface{}{"failed_codes": a.FailedCodes, "locked_until": a.LockedUntil}).Error; err != nil {
+		return fmt.Errorf("failed to record wrong trade code: %w", err)
+	}
+	if a.Locked() {
+		return errTradeCodeLocked
+	}
+	return errWrongTradeCode
+}
+
+// tradeCodeRefusal returns the message for a code Check refused.
+func tradeCodeRefusal(chatID int64, auth *TradeAuth, err error) string {
+	if errors.Is(err, errTradeCodeLocked) {
+		return tr(chatID, "Too many wrong codes. Your trade code is locked until %s.", formatUserTime(chatID, auth.LockedUntil))
+	}
+	if !errors.Is(err, errWrongTradeCode) {
+		telegramLog.Error("Failed to check trade code", "user_id", auth.UserID, "error", err)
+	}
+	return tr(chatID, "Wrong code.")
+}
+
+// newTOTPSecret generates a random base32 TOTP secret.
+func newTOTPSecret() (string, error) {
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key), nil
+}
+
+// totpCode returns the RFC 6238 code for the secret in the time step containing t.
+func totpCode(secret string, t time.Time) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(totpStep.Seconds())))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// verifyTOTP checks a code against the current time step and the ones either side of it,
+// allowing for clock drift and codes typed just before they rolled over.
+func verifyTOTP(secret, code string, now time.Time) bool {
+	for _, drift := range []time.Duration{0, -totpStep, totpStep} {
+		expected, err := totpCode(secret, now.Add(drift))
+		if err == nil && hmac.Equal([]byte(expected), []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingTradeCode is a Confirm waiting for the user's code.
+type PendingTradeCode struct {
+	ChatID    int64
+	MessageID int
+	SignalID  string
+	Deadline  time.Time
+}
+
+// PendingTradeCodeStore manages pending code requests by user ID with concurrency safety.
+type PendingTradeCodeStore struct {
+	sync.Mutex
+	pending map[int64]*PendingTradeCode
+}
+
+// NewPendingTradeCodeStore creates a new instance of PendingTradeCodeStore.
+func NewPendingTradeCodeStore() *PendingTradeCodeStore {
+	return &PendingTradeCodeStore{
+		pending: make(map[int64]*PendingTradeCode),
+	}
+}
+
+func (s *PendingTradeCodeStore) Set(userID int64, p *PendingTradeCode) {
+	s.Lock()
+	defer s.Unlock()
+	s.pending[userID] = p
+}
+
+// Take removes and returns the user's pending code request for the chat.
+func (s *PendingTradeCodeStore) Take(userID, chatID int64) (*PendingTradeCode, bool) {
+	s.Lock()
+	defer s.Unlock()
+	p, exists := s.pending[userID]
+	if !exists || p.ChatID != chatID {
+		return nil, false
+	}
+	delete(s.pending, userID)
+	return p, true
+}
+
+var pendingTradeCodes = NewPendingTradeCodeStore()
+
+// requestTradeCode asks for the user's code if they have set one, reporting whether the
+// confirmation now waits for it. The confirmation is refused if the code settings can't be read.
+func requestTradeCode(chatID, userID int64, messageID int, signalID string) bool {
+	auth, err := GetTradeAuth(userID)
+	if err != nil {
+		telegramLog.Error("Failed to load trade code", "signal_id", signalID, "user_id", userID, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Could not check your trade code. The signal was not executed.")))
+		return true
+	}
+	if auth == nil {
+		return false
+	}
+	if auth.Locked() {
+		bot.Send(tgbotapi.NewMessage(chatID, tradeCodeRefusal(chatID, auth, errTradeCodeLocked)+" "+tr(chatID, "The signal was not executed.")))
+		return true
+	}
+
+	pendingTradeCodes.Set(userID, &PendingTradeCode{
+		ChatID:    chatID,
+		MessageID: messageID,
+		SignalID:  signalID,
+		Deadline:  time.Now().Add(tradeCodeTimeout),
+	})
+	prompt := tr(chatID, "Please enter your PIN within %d seconds to confirm this signal.", int(tradeCodeTimeout.Seconds()))
+	if auth.TOTPSecret != "" {
+		prompt = tr(chatID, "Please enter the code from your authenticator app within %d seconds to confirm this signal.", int(tradeCodeTimeout.Seconds()))
+	}
+	sent, err := bot.Send(tgbotapi.NewMessage(chatID, prompt))
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	return true
+}
+
+// handleTradeCode checks a message from a user with a pending code request and continues
+// their confirmation if the code is right. The message is deleted so the code doesn't stay
+// in the chat. It reports whether the message was a code.
+func handleTradeCode(message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+	userID := senderID(message)
+	p, exists := pendingTradeCodes.Take(userID, chatID)
+	if !exists {
+		return false
+	}
+	if time.Now().After(p.Deadline) {
+		return false // Expired; treat the message as usual
+	}
+
+	if _, err := bot.Request(tgbotapi.NewDeleteMessage(chatID, message.MessageID)); err != nil {
+		telegramLog.Error("Failed to delete trade code message", "chat_id", chatID, "error", err)
+	}
+	auth, err := GetTradeAuth(userID)
+	if err != nil || auth == nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Could not check your trade code. The signal was not executed.")))
+		return true
+	}
+	if err := auth.Check(strings.TrimSpace(message.Text)); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, tradeCodeRefusal(chatID, auth, err)+" "+tr(chatID, "The signal was not executed.")))
+		return true
+	}
+	handleConfirm(chatID, userID, p.MessageID, p.SignalID)
+	return true
+}
+
+// handlePinCommand sets up the code required before confirmations execute:
+// "/pin" to set a PIN, "/pin totp" to use an authenticator app and "/pin off" to remove it.
+// Changing or removing a code asks for the current one first.
+func handlePinCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	userID := message.From.ID
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	switch arg {
+	case "", "totp", "off":
+	default:
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /pin, /pin totp or /pin off")))
+		return
+	}
+
+	if !message.Chat.IsPrivate() {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "For your security, please use /pin in a private chat with the bot.")))
+		return
+	}
+
+	auth, err := GetTradeAuth(userID)
+	if err != nil {
+		telegramLog.Error("Failed to load trade code", "user_id", userID, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Could not check your trade code.")))
+		return
+	}
+	if auth == nil {
+		if arg == "off" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "You have no trade code set.")))
+			return
+		}
+		startTradeCodeSetup(chatID, userID, arg)
+		return
+	}
+	if auth.Locked() {
+		bot.Send(tgbotapi.NewMessage(chatID, tradeCodeRefusal(chatID, auth, errTradeCodeLocked)))
+		return
+	}
+
+	prompt := tr(chatID, "Please send your current PIN to continue.")
+	if auth.TOTPSecret != "" {
+		prompt = tr(chatID, "Please send the current code from your authenticator app to continue.")
+	}
+	sent, err := bot.Send(tgbotapi.NewMessage(chatID, prompt))
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	editingUsers.Set(chatID, &EditingState{SettingName: "TradeCodeCurrent", PendingPinChange: arg})
+}
+
+// startTradeCodeSetup asks for a new PIN, or for "totp" shows a new authenticator key and asks
+// for its first code.
+func startTradeCodeSetup(chatID, userID int64, arg string) {
+	if arg == "" {
+		sent, err := bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Please send a PIN of 4 to 8 digits. You will be asked for it each time you confirm a signal.")))
+		if err != nil {
+			telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+		}
+		trackMessage(sent, messageKindPrompt)
+		editingUsers.Set(chatID, &EditingState{SettingName: "TradePIN"})
+		return
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		telegramLog.Error("Failed to generate TOTP secret", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to set up your authenticator.")))
+		return
+	}
+	uri := fmt.Sprintf("otpauth://totp/%s:%d?secret=%s&issuer=%s",
+		url.PathEscape(bot.Self.UserName), userID, secret, url.QueryEscape(bot.Self.UserName))
+	text := tr(chatID, "Add this key to your authenticator app, then send the 6-digit code it shows to finish.\n\n<b>Key:</b> <code>%s</code>\n<b>Link:</b> <code>%s</code>", secret, uri)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	editingUsers.Set(chatID, &EditingState{SettingName: "TradeTOTP", PendingSecret: secret})
+}
+
+// handleTradeAuthValue handles the current code, PIN and first authenticator code steps of the
+// /pin flow.
+// The messages are deleted as soon as they are read so the code doesn't stay in the chat.
+func handleTradeAuthValue(message *tgbotapi.Message, editingState *EditingState) {
+	chatID := message.Chat.ID
+	text := strings.TrimSpace(message.Text)
+
+	if _, err := bot.Request(tgbotapi.NewDeleteMessage(chatID, message.MessageID)); err != nil {
+		telegramLog.Error("Failed to delete trade code message", "chat_id", chatID, "error", err)
+	}
+
+	var pinHash, totpSecret string
+	switch editingState.SettingName {
+	case "TradeCodeCurrent":
+		auth, err := GetTradeAuth(message.From.ID)
+		if err != nil || auth == nil {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Could not check your trade code.")))
+			return
+		}
+		if err := auth.Check(text); err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, tradeCodeRefusal(chatID, auth, err)))
+			return
+		}
+		if editingState.PendingPinChange != "off" {
+			startTradeCodeSetup(chatID, message.From.ID, editingState.PendingPinChange)
+			return
+		}
+		if err := DeleteTradeAuth(message.From.ID); err != nil {
+			telegramLog.Error("Failed to delete trade code", "chat_id", chatID, "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to remove your trade code.")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Your trade code has been removed. Confirm now executes right away.")))
+		return
+
+	case "TradePIN":
+		if len(text) < 4 || len(text) > 8 || strings.Trim(text, "0123456789") != "" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "The PIN must be 4 to 8 digits. Use /pin to try again.")))
+			return
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(text), bcrypt.DefaultCost)
+		if err != nil {
+			telegramLog.Error("Failed to hash PIN", "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to save your trade code.")))
+			return
+		}
+		pinHash = string(hash)
+
+	case "TradeTOTP":
+		if !verifyTOTP(editingState.PendingSecret, text, time.Now()) {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "That code doesn't match. Use /pin totp to try again.")))
+			return
+		}
+		totpSecret = editingState.PendingSecret
+	}
+
+	if err := SaveTradeAuth(message.From.ID, pinHash, totpSecret); err != nil {
+		telegramLog.Error("Failed to save trade code", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to save your trade code.")))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Your trade code is set. You will be asked for it each time you confirm a signal.")))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// manualSignalSource is the source of signals created in the admin panel's trade console, which
+// routing rules can match.
+const manualSignalSource = "admin"
+
+// parseTradeForm builds a signal from the trade console form, checked like a pasted signal.
+func parseTradeForm(form func(string) string) (*AlertMessage, error) {
+	alert := &AlertMessage{
+		Symbol:     strings.ToUpper(strings.TrimSpace(form("symbol"))),
+		SignalType: form("side"),
+		Timeframe:  strings.TrimSpace(form("timeframe")),
+		Strategy:   strings.TrimSpace(form("strategy")),
+	}
+	if alert.Symbol == "" {
+		return nil, fmt.Errorf("A symbol is required")
+	}
+	if alert.SignalType != "Buy" && alert.SignalType != "Sell" {
+		return nil, fmt.Errorf("Invalid side")
+	}
+
+	var err error
+	if alert.EntryPrice, err = strconv.ParseFloat(strings.TrimSpace(form("entry")), 64); err != nil {
+		return nil, fmt.Errorf("Invalid entry price")
+	}
+	for _, field := range strings.Split(form("tps"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		tp, err := strconv.ParseFloat(field, 64)
+		if err != nil || tp <= 0 {
+			return nil, fmt.Errorf("Invalid TP %q", field)
+		}
+		alert.TPs = append(alert.TPs, tp)
+	}
+	if len(alert.TPs) > maxTPLevels {
+		return nil, fmt.Errorf("At most %d TPs can be set", maxTPLevels)
+	}
+	if sl := strings.TrimSpace(form("sl")); sl != "" {
+		if alert.SL, err = strconv.ParseFloat(sl, 64); err != nil || alert.SL < 0 {
+			return nil, fmt.Errorf("Invalid SL")
+		}
+	}
+	if err := validateParsedSignal(alert); err != nil {
+		return nil, fmt.Errorf("Invalid signal: %v", err)
+	}
+
+	now := time.Now()
+	alert.SignalID = fmt.Sprintf("web%d", now.UnixMilli())
+	alert.Time = now.UTC().Format(time.RFC3339)
+	alert.Source = manualSignalSource
+	return alert, nil
+}
+
+// describeManualSignal summarizes a trade console signal for the audit log.
+func describeManualSignal(alert *AlertMessage) string {
+	tps := make([]string, len(alert.TPs))
+	for i, tp := range alert.TPs {
+		tps[i] = formatFloat(tp)
+	}
+	return fmt.Sprintf("%s %s entry=%s tps=%s sl=%s", alert.SignalType, alert.Symbol,
+		formatFloat(alert.EntryPrice), strings.Join(tps, ","), formatFloat(alert.SL))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of trade state saved across restarts.
+const (
+	tradeStateDCALadder  = "dca_ladder"
+	tradeStateTrailingTP = "trailing_tp"
+	tradeStateStopEntry  = "stop_entry"
+)
+
+// StoredTradeState is what the bot remembers about an open trade between its orders, e.g. a DCA
+// ladder, saved across restarts so the trade is still followed after a deploy.
+type StoredTradeState struct {
+	ID       uint   `gorm:"primaryKey"`
+	Kind     string `gorm:"uniqueIndex:idx_trade_state;size:16"`
+	Account  string `gorm:"uniqueIndex:idx_trade_state;size:64"`
+	Symbol   string `gorm:"uniqueIndex:idx_trade_state;size:32"`
+	ClientID string `gorm:"uniqueIndex:idx_trade_state;size:64"` // Empty unless the state belongs to one order
+	SavedAt  time.Time
+	Data     string // JSON of the state
+}
+
+// savedTrade is the JSON saved for a trade state. The signal is saved on its own, as the alert
+// JSON leaves out some of its fields.
+type savedTrade struct {
+	State      json.RawMessage
+	Signal     signalState
+	ChatID     int64
+	ReceivedAt time.Time
+}
+
+// encodeTrade returns the JSON saved for the state of a trade and a copy of its signal.
+func encodeTrade(state any, signal AlertMessage) (string, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	data, err = json.Marshal(savedTrade{State: data, Signal: newSignalState(&signal), ChatID: signal.ChatID, ReceivedAt: signal.ReceivedAt})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeTrade reads the state of a trade saved by encodeTrade into state, and returns its signal.
+func decodeTrade(data string, state any) (AlertMessage, error) {
+	var saved savedTrade
+	if err := json.Unmarshal([]byte(data), &saved); err != nil {
+		return AlertMessage{}, err
+	}
+	if saved.Signal.Alert == nil {
+		return AlertMessage{}, errors.New("no signal")
+	}
+	if err := json.Unmarshal(saved.State, state); err != nil {
+		return AlertMessage{}, err
+	}
+	signal := saved.Signal.restore()
+	signal.ChatID = saved.ChatID
+	signal.ReceivedAt = saved.ReceivedAt
+	return *signal, nil
+}
+
+// savedTradeState remembers the rows last written so an unchanged state isn't written again.
+var (
+	savedTradeState   string
+	savedTradeStateMu sync.Mutex
+)
+
+// tradeStateRows returns a row for every DCA ladder, trailing TP and stop entry in memory.
+func tradeStateRows() ([]StoredTradeState, error) {
+	var rows []StoredTradeState
+	add := func(kind string, key positionKey, clientID string, state any, signal AlertMessage) error {
+		data, err := encodeTrade(state, signal)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s for %s on %s: %w", kind, key.Symbol, key.Account, err)
+		}
+		rows = append(rows, StoredTradeState{Kind: kind, Account: key.Account, Symbol: key.Symbol, ClientID: clientID, Data: data})
+		return nil
+	}
+	for key, ladder := range dcaLadders.All() {
+		if err := add(tradeStateDCALadder, key, "", ladder, ladder.Signal); err != nil {
+			return nil, err
+		}
+	}
+	for key, trail := range trailingTPs.All() {
+		if err := add(tradeStateTrailingTP, key, "", trail, trail.Signal); err != nil {
+			return nil, err
+		}
+	}
+	for key, entry := range stopEntries.All() {
+		account := positionKey{Account: key.Account, Symbol: entry.Signal.Symbol}
+		if err := add(tradeStateStopEntry, account, key.ClientID, entry, entry.Signal); err != nil {
+			return nil, err
+		}
+	}
+	// The stores are maps, so sort the rows to tell whether anything changed
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Account != b.Account {
+			return a.Account < b.Account
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.ClientID < b.ClientID
+	})
+	return rows, nil
+}
+
+// saveTradeState replaces the saved trade state with what is in memory, if it changed since it
+// was last saved.
+func saveTradeState() {
+	savedTradeStateMu.Lock()
+	defer savedTradeStateMu.Unlock()
+
+	rows, err := tradeStateRows()
+	if err != nil {
+		dbLog.Error("Failed to save trade state", "error", err)
+		return
+	}
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		dbLog.Error("Failed to encode trade state", "error", err)
+		return
+	}
+	if string(encoded) == savedTradeState {
+		return
+	}
+
+	now := time.Now()
+	for i := range rows {
+		rows[i].SavedAt = now
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&StoredTradeState{}).Error; err != nil {
+			return fmt.Errorf("failed to remove saved trade state: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("failed to save trade state: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		dbLog.Error("Failed to save trade state", "error", err)
+		return
+	}
+	savedTradeState = string(encoded)
+}
+
+// loadTradeState restores the saved DCA ladders, trailing TPs and stop entries, before open
+// positions are reconciled.
+func loadTradeState() error {
+	var stored []StoredTradeState
+	if err := db.Find(&stored).Error; err != nil {
+		return fmt.Errorf("failed to retrieve saved trade state: %w", err)
+	}
+
+	restored := 0
+	for _, row := range stored {
+		key := positionKey{Account: row.Account, Symbol: row.Symbol}
+		var err error
+		switch row.Kind {
+		case tradeStateDCALadder:
+			var ladder DCALadder
+			if ladder.Signal, err = decodeTrade(row.Data, &ladder); err == nil {
+				dcaLadders.Set(key, &ladder)
+			}
+		case tradeStateTrailingTP:
+			var trail TrailingTP
+			if trail.Signal, err = decodeTrade(row.Data, &trail); err == nil {
+				trailingTPs.Set(key, &trail)
+			}
+		case tradeStateStopEntry:
+			var entry StopEntry
+			if entry.Signal, err = decodeTrade(row.Data, &entry); err == nil {
+				stopEntries.Set(stopEntryKey{Account: row.Account, ClientID: row.ClientID}, &entry)
+			}
+		default:
+			err = fmt.Errorf("unknown kind %q", row.Kind)
+		}
+		if err != nil {
+			dbLog.Warn("Skipping unreadable trade state", "kind", row.Kind, "symbol", row.Symbol, "account", row.Account, "error", err)
+			continue
+		}
+		restored++
+	}
+
+	savedTradeStateMu.Lock()
+	defer savedTradeStateMu.Unlock()
+	if rows, err := tradeStateRows(); err == nil {
+		if encoded, err := json.Marshal(rows); err == nil {
+			savedTradeState = string(encoded)
+		}
+	}
+	dbLog.Info("Restored DCA ladders, trailing TPs and stop entries", "count", restored)
+	return nil
+}
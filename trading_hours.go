@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// How signals arriving outside a chat's trading hours are handled.
+const (
+	OutsideHoursInfo    = "info"    // Posted for information, without a Confirm button
+	OutsideHoursDismiss = "dismiss" // Posted and dismissed straight away
+)
+
+// tradingWeekdays are the day names accepted in trading hours, in time.Weekday order.
+var tradingWeekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// TradingWindow is a daily time range on some days of the week. A range that ends past midnight
+// belongs to the day it starts on.
+type TradingWindow struct {
+	Days       [7]bool // Indexed by time.Weekday
+	Start, End int     // Minutes after midnight; End may be 1440
+}
+
+// parseWeekday parses a day name of at least three letters such as "Mon", "tues" or "Monday".
+func parseWeekday(text string) (time.Weekday, error) {
+	text = strings.ToLower(text)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if len(text) >= 3 && strings.HasPrefix(strings.ToLower(day.String()), text) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q", text)
+}
+
+// parseClockTime parses "8", "08:30" or "24:00" as minutes after midnight.
+func parseClockTime(text string) (int, error) {
+	hourText, minuteText, hasMinutes := strings.Cut(text, ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time %q", text)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteText)
+		if err != nil || minute < 0 || minute > 59 || len(minuteText) != 2 {
+			return 0, fmt.Errorf("invalid time %q", text)
+		}
+	}
+	if hour == 24 && minute != 0 {
+		return 0, fmt.Errorf("invalid time %q", text)
+	}
+	return hour*60 + minute, nil
+}
+
+// parseTradingHours parses trading windows such as "Mon-Fri 08:00-20:00; Sat 10-14". Each
+// window is a list of days and day ranges, which may be left out for every day, and a time range
+// that may wrap past midnight, e.g. "Sun-Thu 22-2".
+func parseTradingHours(text string) ([]TradingWindow, error) {
+	var windows []TradingWindow
+	for _, part := range strings.Split(text, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("expected DAYS START-END, e.g. Mon-Fri 08:00-20:00")
+		}
+
+		var window TradingWindow
+		if len(fields) == 1 {
+			for i := range window.Days {
+				window.Days[i] = true
+			}
+		} else {
+			for _, days := range strings.Split(fields[0], ",") {
+				first, last, isRange := strings.Cut(days, "-")
+				from, err := parseWeekday(first)
+				if err != nil {
+					return nil, err
+				}
+				to := from
+				if isRange {
+					if to, err = parseWeekday(last); err != nil {
+						return nil, err
+					}
+				}
+				for day := from; ; day = (day + 1) % 7 {
+					window.Days[day] = true
+					if day == to {
+						break
+					}
+				}
+			}
+		}
+
+		start, end, ok := strings.Cut(fields[len(fields)-1], "-")
+		if !ok {
+			return nil, fmt.Errorf("expected a time range START-END, e.g. 08:00-20:00")
+		}
+		var err error
+		if window.Start, err = parseClockTime(start); err != nil {
+			return nil, err
+		}
+		if window.End, err = parseClockTime(end); err != nil {
+			return nil, err
+		}
+		if window.Start == window.End || window.Start == 24*60 {
+			return nil, fmt.Errorf("start and end must differ")
+		}
+		windows = append(windows, window)
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("expected DAYS START-END, e.g. Mon-Fri 08:00-20:00")
+	}
+	return windows, nil
+}
+
+// formatWeekdays renders days as "Mon-Fri" or "Mon,Wed,Sat-Sun", with runs of three or more
+// days as ranges, or "" for every day.
+func formatWeekdays(days [7]bool) string {
+	// Start after a day that is off, from Monday if possible, so runs aren't split
+	start := -1
+	for i := 1; i <= 7; i++ {
+		if days[i%7] && !days[(i+6)%7] {
+			start = i % 7
+			break
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	var parts []string
+	for i := 0; i < 7; i++ {
+		day := (start + i) % 7
+		if !days[day] {
+			continue
+		}
+		run := 1
+		for i+run < 7 && days[(start+i+run)%7] {
+			run++
+		}
+		if run >= 3 {
+			parts = append(parts, tradingWeekdays[day]+"-"+tradingWeekdays[(day+run-1)%7])
+		} else {
+			for j := 0; j < run; j++ {
+				parts = append(parts, tradingWeekdays[(day+j)%7])
+			}
+		}
+		i += run - 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatTradingHours renders trading windows the way they are entered.
+func formatTradingHours(windows []TradingWindow) string {
+	var parts []string
+	for _, window := range windows {
+		times := fmt.Sprintf("%02d:%02d-%02d:%02d", window.Start/60, window.Start%60, window.End/60, window.End%60)
+		if days := formatWeekdays(window.Days); days != "" {
+			times = days + " " + times
+		}
+		parts = append(parts, times)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Contains reports whether t, in the timezone the window is set in, falls in the window.
+func (w TradingWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.Start < w.End {
+		return w.Days[day] && minute >= w.Start && minute < w.End
+	}
+	// Past midnight the window belongs to the day before
+	return (w.Days[day] && minute >= w.Start) || (w.Days[(day+6)%7] && minute < w.End)
+}
+
+// inTradingHours reports whether t falls in the settings' trading hours. Without trading hours
+// every time does.
+func inTradingHours(settings *UserSettings, t time.Time) bool {
+	windows, err := parseTradingHours(settings.TradingHours)
+	if settings.TradingHours == "" || err != nil {
+		return true
+	}
+	for _, window := range windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// outsideTradingHours reports whether the chat is outside its trading hours right now.
+func outsideTradingHours(chatID int64) bool {
+	return !inTradingHours(userSettings.Get(chatID), time.Now().In(userLocation(chatID)))
+}
+
+// dismissOutsideHours dismisses an info-only signal on arrival if the chat asked for that,
+// and reports whether it did.
+func dismissOutsideHours(signal *AlertMessage) bool {
+	if !signal.InfoOnly || userSettings.Get(signal.ChatID).OutsideHoursMode != OutsideHoursDismiss {
+		return false
+	}
+	signal.Dismissed = true
+	recordSignalStatus(signal, SignalDismissed)
+	telegramLog.Info("Dismissed signal outside trading hours", "signal_id", signal.SignalID, "chat_id", signal.ChatID)
+	return true
+}
+
+// refuseOutsideHours tells the chat a signal can't be confirmed when it arrived outside the
+// chat's trading hours or the chat is outside them now, and reports whether it refused.
+func refuseOutsideHours(chatID int64, signal *AlertMessage) bool {
+	if !signal.InfoOnly && !outsideTradingHours(chatID) {
+		return false
+	}
+	text := tr(chatID, "Trading is closed outside your trading hours (%s). This signal is for information only.", userSettings.Get(chatID).TradingHours)
+	if !signal.InfoOnly {
+		text = tr(chatID, "Trading is closed outside your trading hours (%s). Confirm this signal again when they start.", userSettings.Get(chatID).TradingHours)
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, text))
+	return true
+}
+
+// infoOnlyKeyboard is the keyboard of a signal posted outside trading hours, which can be
+// previewed and dismissed but not confirmed or edited.
+func infoOnlyKeyboard(chatID int64, signalID string) *tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Preview"), fmt.Sprintf("%s|%s", ActionPreview, signalID)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Dismiss"), fmt.Sprintf("%s|%s", ActionDismiss, signalID)),
+		),
+	)
+	return &keyboard
+}
+
+// promptTradingHours asks the user for their trading hours.
+func promptTradingHours(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please enter the hours signals may be confirmed in your timezone as DAYS START-END, separated by \";\" (e.g., Mon-Fri 08:00-20:00; Sat 10-14), or \"off\"."))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	editingUsers.Set(chatID, &EditingState{SettingName: "TradingHours"})
+}
+
+// toggleOutsideHoursMode switches between posting signals outside trading hours for
+// information and dismissing them.
+func toggleOutsideHoursMode(chatID int64) {
+	settings := userSettings.Get(chatID)
+	if settings.OutsideHoursMode == OutsideHoursDismiss {
+		settings.OutsideHoursMode = OutsideHoursInfo
+	} else {
+		settings.OutsideHoursMode = OutsideHoursDismiss
+	}
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Outside Hours has been set to %s.", tr(chatID, settings.OutsideHoursMode)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// OrderTagTrail tags the trailing stop that replaces the TPs after TP2 with Trail After TP2 on.
+const OrderTagTrail = "trail"
+
+// trailAfterLevel is the zero-based TP level whose fill replaces the later TPs with a trailing stop.
+const trailAfterLevel = 1
+
+// Binance accepts trailing stop callback rates in this range, in percent, in steps of 0.1.
+const (
+	minTrailCallbackRate = 0.1
+	maxTrailCallbackRate = 10
+)
+
+// maxTrailATRMultiplier caps the ATR multiple the trailing stop may follow the price at.
+const maxTrailATRMultiplier = 10
+
+// TrailingTP is a trade whose TPs after TP2 are replaced by a trailing stop once TP2 fills.
+type TrailingTP struct {
+	Signal   AlertMessage // Copy of the signal as executed, for its timeframe
+	Settings UserSettings // Copy of the settings used for the trade
+}
+
+// TrailingTPStore manages trailing TPs waiting for TP2 by account and symbol with concurrency
+// safety.
+type TrailingTPStore struct {
+	sync.Mutex
+	trails map[positionKey]*TrailingTP
+}
+
+// NewTrailingTPStore creates a new instance of TrailingTPStore.
+func NewTrailingTPStore() *TrailingTPStore {
+	return &TrailingTPStore{
+		trails: make(map[positionKey]*TrailingTP),
+	}
+}
+
+func (s *TrailingTPStore) Set(key positionKey, trail *TrailingTP) {
+	s.Lock()
+	defer s.Unlock()
+	s.trails[key] = trail
+}
+
+func (s *TrailingTPStore) Get(key positionKey) (*TrailingTP, bool) {
+	s.Lock()
+	defer s.Unlock()
+	trail, exists := s.trails[key]
+	return trail, exists
+}
+
+func (s *TrailingTPStore) Delete(key positionKey) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.trails, key)
+}
+
+// All returns a copy of every trailing TP by account and symbol.
+func (s *TrailingTPStore) All() map[positionKey]TrailingTP {
+	s.Lock()
+	defer s.Unlock()
+	trails := make(map[positionKey]TrailingTP, len(s.trails))
+	for key, trail := range s.trails {
+		trails[key] = *trail
+	}
+	return trails
+}
+
+var trailingTPs = NewTrailingTPStore()
+
+// useTrailingTP reports whether the trade's TPs after TP2 are replaced by a trailing stop once
+// TP2 fills: Trail After TP2 is on for USDT-M manual TPs, with a TP beyond TP2 to replace.
+func useTrailingTP(settings *UserSettings, tps []float64) bool {
+	if !settings.TrailAfterTP2 || settings.AutoCalculateTPs || settings.MarketType == MarketTypeCoinM || len(tps) <= trailAfterLevel+1 {
+		return false
+	}
+	for _, tp := range tps[:trailAfterLevel+1] {
+		if tp <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// placeTrailingStopOrder places a reduce-only Trailing-Stop-Market order for quantity that
+// follows the price callbackRate percent behind its best level since the order was placed.
+func (b *BinanceClient) placeTrailingStopOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, callbackRate float64, clientID string, workingType futures.WorkingType) error {
+	rate := strconv.FormatFloat(callbackRate, 'f', 1, 64)
+	res, err := b.Client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(futures.OrderTypeTrailingStopMarket).
+		Quantity(quantity).
+		CallbackRate(rate).
+		ReduceOnly(true).
+		WorkingType(workingType).
+		PriceProtect(true).
+		NewClientOrderID(clientID).
+		Do(ctx)
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=TRAILING_STOP_MARKET quantity=%s callback_rate=%s working_type=%s reduce_only=true", symbol, side, quantity, rate, workingType), res, err)
+	if err == nil {
+		recordFuturesOrder(res)
+	}
+	return err
+}
+
+// trailCallbackRate returns the callback rate the trailing stop follows the price at, and how
+// it was set: TrailATRMultiplier times the ATR of the signal's timeframe as a share of the
+// price, or TrailPercent when that is off or the ATR can't be fetched. It is clamped to what
+// Binance accepts. How it was set is in the language of the trade's settings.
+func (b *BinanceClient) trailCallbackRate(ctx context.Context, trail *TrailingTP) (float64, string) {
+	rate := trail.Settings.TrailPercent
+	source := ""
+	if trail.Settings.TrailATRMultiplier > 0 {
+		indicators, err := b.signalIndicators(ctx, &trail.Signal)
+		if err == nil && indicators.ATR > 0 && indicators.Close > 0 {
+			rate = trail.Settings.TrailATRMultiplier * indicators.ATR / indicators.Close * 100
+			source = translate(trail.Settings.Language, ", %.2fx the %s ATR", trail.Settings.TrailATRMultiplier, indicators.Interval)
+		} else {
+			binanceLog.Warn("Failed to get ATR for trailing stop, using the trail percentage", "symbol", trail.Signal.Symbol, "error", err)
+		}
+	}
+	rate = math.Round(math.Max(minTrailCallbackRate, math.Min(rate, maxTrailCallbackRate))*10) / 10
+	return rate, source
+}
+
+// handlePartialTPFill follows a TP that closed only part of the position. Once TP2 fills it
+// places a trailing stop for the rest of the position and cancels the TPs after TP2, which the
+// trailing stop replaces; the SL stays in place. Trails are kept in memory, so a TP2 that fills
+// after a restart leaves the later TPs as they are.
+func (b *BinanceClient) handlePartialTPFill(symbol, clientID string, group *OCOGroup, userID int64) {
+	_, filledTag, _ := parseClientOrderID(clientID)
+	trail, exists := trailingTPs.Get(b.key(symbol))
+	if filledTag != tpOrderTag(trailAfterLevel) || !exists || trail.Signal.SignalID != group.SignalID {
+		b.sendMessageToUser(userID, tr(userID, "%s filled for %s. The rest of the position keeps its TP/SL orders.", strings.ToUpper(filledTag), symbol))
+		return
+	}
+	trailingTPs.Delete(b.key(symbol))
+
+	ctx := context.Background()
+	risks, err := b.Client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		b.sendMessageToUser(userID, tr(userID, "%s filled for %s, but the position could not be fetched to trail it, so the later TPs stay in place: %v", strings.ToUpper(filledTag), symbol, err))
+		return
+	}
+	var remaining string
+	for _, risk := range risks {
+		if amount, _ := strconv.ParseFloat(risk.PositionAmt, 64); amount != 0 {
+			remaining = strings.TrimPrefix(risk.PositionAmt, "-")
+		}
+	}
+	if remaining == "" {
+		b.sendMessageToUser(userID, tr(userID, "%s filled for %s. No position is left to trail.", strings.ToUpper(filledTag), symbol))
+		return
+	}
+
+	// Place the trailing stop before cancelling the TPs it replaces, so the rest always has an exit
+	rate, source := b.trailCallbackRate(ctx, trail)
+	side := invertSide(signalSide(&trail.Signal))
+	trailID := clientOrderID(group.SignalID, OrderTagTrail)
+	if err := b.placeTrailingStopOrder(ctx, symbol, side, remaining, rate, trailID, workingType(&trail.Settings)); err != nil {
+		b.sendMessageToUser(userID, tr(userID, "%s filled for %s, but the trailing stop could not be placed, so the later TPs stay in place: %v", strings.ToUpper(filledTag), symbol, err))
+		return
+	}
+
+	var replaced []string
+	for _, id := range group.TPs {
+		if containsString(group.Partial, id) {
+			continue
+		}
+		res, err := b.Client.NewCancelOrderService().
+			Symbol(symbol).
+			OrigClientOrderID(id).
+			Do(ctx)
+		auditOrder(AuditCancelOrder, id, "symbol="+symbol, res, err)
+		if err != nil {
+			// Already filled or cancelled orders are expected here; auditOrder logged it
+			continue
+		}
+		ocoGroups.Remove(b.key(symbol), id)
+		_, tag, _ := parseClientOrderID(id)
+		replaced = append(replaced, strings.ToUpper(tag))
+	}
+	ocoGroups.Add(b.key(symbol), group.SignalID, trailID, false)
+
+	msg := tr(userID, "%s filled for %s. The remaining %s now trails %.1f%% behind the price%s.", strings.ToUpper(filledTag), symbol, remaining, rate, source)
+	if len(replaced) > 0 {
+		msg += tr(userID, " Replaced: %s.", strings.Join(replaced, ", "))
+	}
+	b.sendMessageToUser(userID, msg)
+}
+
+// toggleTrailAfterTP2 toggles whether the TPs after TP2 are replaced by a trailing stop.
+func toggleTrailAfterTP2(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.TrailAfterTP2 = !settings.TrailAfterTP2
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Trail After TP2 has been set to %t.", settings.TrailAfterTP2))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// TestTrailCallbackRateUsesSignalTimeframe checks that a trade's trailing stop follows the ATR
+// of its signal's timeframe, which the copy of the signal the trade is executed with keeps.
+func TestTrailCallbackRateUsesSignalTimeframe(t *testing.T) {
+	var interval string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		interval = r.URL.Query().Get("interval")
+		// Candles 2 apart from high to low, closing at 100, so the ATR is 2% of the price
+		klines := make([]string, 30)
+		for i := range klines {
+			klines[i] = fmt.Sprintf(`[%d,"100","101","99","100","1",%d,"100",1,"1","100","0"]`, i*60000, i*60000+59999)
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(klines, ","))
+	}))
+	defer server.Close()
+
+	b := &BinanceClient{Client: futures.NewClient("", "")}
+	b.Client.BaseURL = server.URL
+	settings := UserSettings{TPLevels: defaultTPLevels(), TrailAfterTP2: true, TrailPercent: 1, TrailATRMultiplier: 1}
+	signal := filterEnabledTPs(&AlertMessage{
+		SignalID:   "test",
+		SignalType: "Buy",
+		Symbol:     "BTCUSDT",
+		EntryPrice: 100,
+		TPs:        []float64{101, 102, 103},
+		Timeframe:  "15m",
+	}, &settings)
+
+	rate, source := b.trailCallbackRate(context.Background(), &TrailingTP{Signal: *signal, Settings: settings})
+	if interval != "15m" {
+		t.Errorf("klines fetched for %q, want 15m", interval)
+	}
+	if rate != 2 {
+		t.Errorf("callback rate = %.1f, want 2.0", rate)
+	}
+	if !strings.Contains(source, "15m ATR") {
+		t.Errorf("source = %q, want the 15m ATR", source)
+	}
+}
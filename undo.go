@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ActionUndo is the callback action for undoing a just-executed market entry.
+const ActionUndo = "undo"
+
+// OrderTagUndo tags the order that closes an undone entry.
+const OrderTagUndo = "undo"
+
+// undoWindow is how long after a market entry the Undo button works.
+const undoWindow = 30 * time.Second
+
+// undoOrderLookback is how many of the symbol's latest orders are searched for the entry.
+const undoOrderLookback = 50
+
+// UndoableTrade is a market entry that can still be undone.
+type UndoableTrade struct {
+	UserID    int64 // User whose account the entry was placed on
+	Signal    *AlertMessage
+	MessageID int // Message carrying the Undo button
+}
+
+// UndoStore manages undoable trades by signal ID with concurrency safety.
+type UndoStore struct {
+	sync.RWMutex
+	trades map[string]*UndoableTrade
+}
+
+// NewUndoStore creates a new instance of UndoStore.
+func NewUndoStore() *UndoStore {
+	return &UndoStore{
+		trades: make(map[string]*UndoableTrade),
+	}
+}
+
+func (s *UndoStore) Set(signalID string, trade *UndoableTrade) {
+	s.Lock()
+	defer s.Unlock()
+	s.trades[signalID] = trade
+}
+
+func (s *UndoStore) Get(signalID string) (*UndoableTrade, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	trade, exists := s.trades[signalID]
+	return trade, exists
+}
+
+// Take removes and returns the undoable trade, so it is undone or expired only once.
+func (s *UndoStore) Take(signalID string) (*UndoableTrade, bool) {
+	s.Lock()
+	defer s.Unlock()
+	trade, exists := s.trades[signalID]
+	delete(s.trades, signalID)
+	return trade, exists
+}
+
+var undoableTrades = NewUndoStore()
+
+// offerUndo sends the execution confirmation with an Undo button that expires after undoWindow.
+// Only USDT-M market entries can be undone; other trades get the plain confirmation.
+func offerUndo(chatID, userID int64, signal *AlertMessage, settings *UserSettings) {
+	exchange := "Binance"
+	if credential, err := GetUserCredential(userID); err == nil && credential != nil {
+		exchange = exchangeName(credential.Exchange)
+	}
+	text := tr(chatID, "Trade executed on %s successfully.", exchange)
+	// Undo closes the position with the Binance futures client
+	if settings.TradingMode != "Market" || settings.MarketType == MarketTypeCoinM || exchange != "Binance" {
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(chatID, "Undo (%ds)", int(undoWindow.Seconds())),
+				fmt.Sprintf("%s|%s", ActionUndo, signal.SignalID)),
+		),
+	)
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send message", "signal_id", signal.SignalID, "symbol", signal.Symbol, "chat_id", chatID, "error", err)
+		return
+	}
+
+	undoableTrades.Set(signal.SignalID, &UndoableTrade{UserID: userID, Signal: signal, MessageID: sent.MessageID})
+	time.AfterFunc(undoWindow, func() {
+		if _, ok := undoableTrades.Take(signal.SignalID); ok {
+			edit := tgbotapi.NewEditMessageReplyMarkup(chatID, sent.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+			if _, err := bot.Send(edit); err != nil {
+				telegramLog.Error("Failed to remove undo button", "signal_id", signal.SignalID, "symbol", signal.Symbol, "chat_id", chatID, "error", err)
+			}
+		}
+	})
+}
+
+// handleUndo closes the position opened by a signal's market entry and cancels its TP/SL
+// and DCA orders, if the undo window is still open and userID placed the trade.
+func handleUndo(chatID, userID int64, signalID string) {
+	trade, exists := undoableTrades.Get(signalID)
+	if !exists {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "The undo window for this trade has closed.")))
+		return
+	}
+	if trade.UserID != userID {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Only the user who executed this trade can undo it.")))
+		return
+	}
+	if _, ok := undoableTrades.Take(signalID); !ok {
+		return // Expired in the meantime
+	}
+
+	text := tr(chatID, "Trade undone: the %s position was closed and its TP/SL orders cancelled.", trade.Signal.Symbol)
+	routed := *trade.Signal
+	client, err := tradingClient(userID, &routed)
+	if err == nil {
+		err = client.undoEntry(trade.Signal)
+	}
+	if err != nil {
+		binanceLog.Error("Failed to undo trade", "signal_id", signalID, "symbol", trade.Signal.Symbol, "user_id", userID, "error", err)
+		text = tr(chatID, "Failed to undo the trade for %s: %v", trade.Signal.Symbol, err)
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, trade.MessageID, text)
+	if _, err := bot.Send(edit); err != nil {
+		telegramLog.Error("Failed to edit message", "signal_id", signalID, "chat_id", chatID, "error", err)
+	}
+}
+
+// undoEntry cancels the signal's open orders and market-closes the quantity its entry orders filled.
+func (b *BinanceClient) undoEntry(signal *AlertMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	symbol := signal.Symbol
+	orders, err := b.Client.NewListOpenOrdersService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list open orders: %v", err)
+	}
+	for _, order := range orders {
+		_, tag, ok := parseClientOrderID(order.ClientOrderID)
+		if !ok || order.ClientOrderID != clientOrderID(signal.SignalID, tag) {
+			continue // Another signal's order
+		}
+		res, err := b.Client.NewCancelOrderService().
+			Symbol(symbol).
+			OrigClientOrderID(order.ClientOrderID).
+			Do(context.Background())
+		auditOrder(AuditCancelOrder, order.ClientOrderID, "symbol="+symbol, res, err)
+		if err != nil {
+			return fmt.Errorf("failed to cancel %s: %v", tag, err)
+		}
+	}
+	if group, exists := ocoGroups.Get(b.key(symbol)); exists && group.SignalID == signal.SignalID {
+		ocoGroups.Delete(b.key(symbol))
+		trailingTPs.Delete(b.key(symbol))
+	}
+
+	// An iceberg entry is spread over several orders, all of which are closed
+	recent, err := b.Client.NewListOrdersService().Symbol(symbol).Limit(undoOrderLookback).Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list orders: %v", err)
+	}
+	var side futures.SideType
+	var filled float64
+	for _, order := range recent {
+		_, tag, ok := parseClientOrderID(order.ClientOrderID)
+		if !ok || !isEntryOrderTag(tag) || order.ClientOrderID != clientOrderID(signal.SignalID, tag) {
+			continue
+		}
+		executed, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+		filled += executed
+		side = order.Side
+	}
+	if filled <= 0 {
+		return nil
+	}
+	info, err := b.getSymbolInfo(context.Background(), symbol)
+	if err != nil {
+		return err
+	}
+	step, err := lotStepSize(info)
+	if err != nil {
+		return err
+	}
+	quantity := formatDecimal(filled, step)
+
+	clientID := clientOrderID(signal.SignalID, OrderTagUndo)
+	res, err := b.Client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(invertSide(side)).
+		Type(futures.OrderTypeMarket).
+		Quantity(quantity).
+		ReduceOnly(true).
+		NewClientOrderID(clientID).
+		Do(context.Background())
+	auditOrder(AuditOrder, clientID, fmt.Sprintf("symbol=%s side=%s type=MARKET quantity=%s reduce_only=true",
+		symbol, invertSide(side), quantity), res, err)
+	if err != nil {
+		return fmt.Errorf("failed to close position: %v", err)
+	}
+	recordFuturesOrder(res)
+	return nil
+}
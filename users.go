@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// personalAccountName labels trades executed on a user's own credentials.
+const personalAccountName = "personal"
+
+// UserCredential holds a Telegram user's own exchange API key pair, registered via /connect.
+type UserCredential struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    int64  `gorm:"uniqueIndex"`
+	Exchange  string `gorm:"size:16"` // ExchangeBinance or ExchangeBybit; empty for Binance
+	APIKey    string `gorm:"serializer:encrypted"`
+	APISecret string `gorm:"serializer:encrypted"`
+}
+
+// exchangeName returns the name of an ExchangeBinance or ExchangeBybit exchange as shown to users.
+func exchangeName(exchange string) string {
+	if exchange == ExchangeBybit {
+		return "Bybit"
+	}
+	return "Binance"
+}
+
+// signalExchange returns the exchange, ExchangeBinance or ExchangeBybit, of the account a
+// signal confirmed by userID was executed on.
+func signalExchange(userID int64, account string) string {
+	var exchange string
+	switch account {
+	case "", defaultAccountName:
+	case personalAccountName:
+		if credential, err := GetUserCredential(userID); err == nil && credential != nil {
+			exchange = credential.Exchange
+		}
+	default:
+		if record, err := GetBinanceAccount(account); err == nil {
+			exchange = record.Exchange
+		}
+	}
+	if exchange == "" {
+		return ExchangeBinance
+	}
+	return exchange
+}
+
+// GetUserCredential retrieves a user's exchange credentials, or nil if none are registered.
+func GetUserCredential(userID int64) (*UserCredential, error) {
+	var credential UserCredential
+	err := db.Where("user_id = ?", userID).First(&credential).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve user credentials: %w", err)
+	}
+	return &credential, nil
+}
+
+// ListConnectedUsers returns the IDs of the users who connected an account of their own.
+func ListConnectedUsers() ([]int64, error) {
+	var users []int64
+	if err := db.Model(&UserCredential{}).Order("user_id").Pluck("user_id", &users).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve connected users: %w", err)
+	}
+	return users, nil
+}
+
+// SaveUserCredential creates or replaces a user's credentials for the exchange.
+func SaveUserCredential(userID int64, exchange, apiKey, apiSecret string) error {
+	credential, err := GetUserCredential(userID)
+	if err != nil {
+		return err
+	}
+	if credential == nil {
+		credential = &UserCredential{UserID: userID}
+	}
+	credential.Exchange = exchange
+	credential.APIKey = apiKey
+	credential.APISecret = apiSecret
+	if err := db.Save(credential).Error; err != nil {
+		return fmt.Errorf("failed to save user credentials: %w", err)
+	}
+	userClients.Delete(strconv.FormatInt(userID, 10))
+	bybitClients.Delete(strconv.FormatInt(userID, 10))
+	return nil
+}
+
+// DeleteUserCredential removes a user's exchange credentials.
+func DeleteUserCredential(userID int64) error {
+	if err := db.Where("user_id = ?", userID).Delete(&UserCredential{}).Error; err != nil {
+		return fmt.Errorf("failed to delete user credentials: %w", err)
+	}
+	userClients.Delete(strconv.FormatInt(userID, 10))
+	bybitClients.Delete(strconv.FormatInt(userID, 10))
+	return nil
+}
+
+// userClients caches Binance clients for users with their own credentials, keyed by user ID.
+var userClients = NewAccountClientStore()
+
+// userClient returns the Binance client for a user's own credentials, or nil if the user
+// has not connected an account. It returns errNotBinance if the user connected another exchange.
+func userClient(userID int64) (*BinanceClient, error) {
+	key := strconv.FormatInt(userID, 10)
+	if client, exists := userClients.Get(key); exists {
+		return client, nil
+	}
+
+	credential, err := GetUserCredential(userID)
+	if err != nil || credential == nil {
+		return nil, err
+	}
+	if credential.Exchange == ExchangeBybit {
+		return nil, errNotBinance
+	}
+
+	client := newBinanceClientWithKeys(bot, personalAccountName+":"+key, credential.APIKey, credential.APISecret)
+	if err := client.testAPIKey(); err != nil {
+		return nil, err
+	}
+	userClients.Set(key, client)
+	return client, nil
+}
+
+// userExchange returns the exchange client for a user's own credentials, Binance or Bybit, or
+// nil if the user has not connected an account.
+func userExchange(userID int64) (Exchange, error) {
+	key := strconv.FormatInt(userID, 10)
+	if client, exists := bybitClients.Get(key); exists {
+		return client, nil
+	}
+
+	client, err := userClient(userID)
+	if errors.Is(err, errNotBinance) {
+		credential, err := GetUserCredential(userID)
+		if err != nil || credential == nil {
+			return nil, err
+		}
+		bybit := newBybitClient(credential.APIKey, credential.APISecret)
+		bybitClients.Set(key, bybit)
+		return bybit, nil
+	}
+	if err != nil || client == nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// tradingExchange picks the exchange account a user's confirmed signal executes on, like
+// tradingClient, but also for users who connected a Bybit account and for routed Bybit accounts.
+func tradingExchange(userID int64, signal *AlertMessage) (Exchange, error) {
+	exchange, err := userExchange(userID)
+	if err != nil {
+		return nil, err
+	}
+	if exchange != nil {
+		signal.Account = personalAccountName
+		return exchange, nil
+	}
+
+	signal.Account = resolveAccount(signal)
+	return accountExchange(signal.Account)
+}
+
+// tradingClient picks the account a user's confirmed signal executes on: the user's own
+// credentials if connected, otherwise the account chosen by the routing rules.
+func tradingClient(userID int64, signal *AlertMessage) (*BinanceClient, error) {
+	client, err := userClient(userID)
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		signal.Account = personalAccountName
+		return client, nil
+	}
+
+	signal.Account = resolveAccount(signal)
+	return accountClient(signal.Account)
+}
+
+// settingsChatID returns the chat whose settings and symbol overrides a signal confirmed by
+// userID in chatID trades with: the user's private chat with the bot if they connected an
+// account of their own, since the trade is theirs, otherwise the chat the signal was posted to.
+func settingsChatID(chatID, userID int64) int64 {
+	credential, err := GetUserCredential(userID)
+	if err != nil {
+		telegramLog.Warn("Failed to load credentials, using the chat's settings", "user_id", userID, "error", err)
+		return chatID
+	}
+	if credential == nil {
+		return chatID
+	}
+	return userID
+}
+
+// handleConnectCommand starts the private /connect flow for registering exchange credentials.
+// "/connect bybit" registers a Bybit key instead of a Binance one.
+func handleConnectCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if !message.Chat.IsPrivate() {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "For your security, please use /connect in a private chat with the bot.")))
+		return
+	}
+
+	exchange := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if exchange == "" {
+		exchange = ExchangeBinance
+	}
+	if exchange != ExchangeBinance && exchange != ExchangeBybit {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Unknown exchange %q. Use /connect binance or /connect bybit.", exchange)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Please send your %s API key. Enable futures trading on the key and keep withdrawals disabled.", exchangeName(exchange)))
+	sent, err := bot.Send(msg)
+	if err != nil {
+		telegramLog.Error("Failed to send prompt message", "chat_id", chatID, "error", err)
+	}
+	trackMessage(sent, messageKindPrompt)
+	editingUsers.Set(chatID, &EditingState{SettingName: "ConnectAPIKey", PendingExchange: exchange})
+}
+
+// handleDisconnectCommand removes the user's exchange credentials.
+func handleDisconnectCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	exchange := "Binance"
+	if credential, err := GetUserCredential(message.From.ID); err == nil && credential != nil {
+		exchange = exchangeName(credential.Exchange)
+	}
+	if err := DeleteUserCredential(message.From.ID); err != nil {
+		telegramLog.Error("Failed to delete credentials", "chat_id", chatID, "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to disconnect your %s account.", exchange)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Your %s account has been disconnected.", exchange)))
+}
+
+// handleConnectValue handles the API key and secret steps of the /connect flow.
+// The messages are deleted as soon as they are read so the secrets don't stay in the chat.
+func handleConnectValue(message *tgbotapi.Message, editingState *EditingState) {
+	chatID := message.Chat.ID
+	text := strings.TrimSpace(message.Text)
+
+	if _, err := bot.Request(tgbotapi.NewDeleteMessage(chatID, message.MessageID)); err != nil {
+		telegramLog.Error("Failed to delete credential message", "chat_id", chatID, "error", err)
+	}
+
+	switch editingState.SettingName {
+	case "ConnectAPIKey":
+		if text == "" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "API key cannot be empty. Use /connect to try again.")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Now send your %s API secret.", exchangeName(editingState.PendingExchange))))
+		editingUsers.Set(chatID, &EditingState{SettingName: "ConnectAPISecret", PendingAPIKey: text, PendingExchange: editingState.PendingExchange})
+
+	case "ConnectAPISecret":
+		if text == "" {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "API secret cannot be empty. Use /connect to try again.")))
+			return
+		}
+		name := exchangeName(editingState.PendingExchange)
+		var err error
+		if editingState.PendingExchange == ExchangeBybit {
+			err = validateBybitAPIKeys(editingState.PendingAPIKey, text)
+		} else {
+			err = validateBinanceAPIKeys(editingState.PendingAPIKey, text, GetGlobalConfig().BinanceAPIURL)
+		}
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "%s API key validation failed: %v\nUse /connect to try again.", name, err)))
+			return
+		}
+		if err := SaveUserCredential(message.From.ID, editingState.PendingExchange, editingState.PendingAPIKey, text); err != nil {
+			telegramLog.Error("Failed to save credentials", "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to save your %s credentials.", name)))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Your %s account is connected. Signals you confirm will now execute on it.", name)))
+	}
+}
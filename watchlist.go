@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// WatchedSymbol is a symbol on a user's watchlist.
+type WatchedSymbol struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID int64  `gorm:"uniqueIndex:idx_watch_user_symbol"`
+	Symbol string `gorm:"uniqueIndex:idx_watch_user_symbol;size:32"`
+}
+
+// WatchSymbol adds a symbol to a user's watchlist. Watching a symbol twice is not an error.
+func WatchSymbol(userID int64, symbol string) error {
+	watched := WatchedSymbol{UserID: userID, Symbol: symbol}
+	if err := db.Where(&watched).FirstOrCreate(&watched).Error; err != nil {
+		return fmt.Errorf("failed to save watched symbol: %w", err)
+	}
+	return nil
+}
+
+// UnwatchSymbol removes a symbol from a user's watchlist and reports whether it was on it.
+func UnwatchSymbol(userID int64, symbol string) (bool, error) {
+	result := db.Where("user_id = ? AND symbol = ?", userID, symbol).Delete(&WatchedSymbol{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete watched symbol: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListWatchedSymbols retrieves a user's watchlist ordered by symbol.
+func ListWatchedSymbols(userID int64) ([]string, error) {
+	var symbols []string
+	if err := db.Model(&WatchedSymbol{}).Where("user_id = ?", userID).Order("symbol").Pluck("symbol", &symbols).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve watchlist: %w", err)
+	}
+	return symbols, nil
+}
+
+// IsWatched reports whether the symbol is on the user's watchlist.
+func IsWatched(userID int64, symbol string) (bool, error) {
+	var watched WatchedSymbol
+	err := db.Where("user_id = ? AND symbol = ?", userID, symbol).First(&watched).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to retrieve watched symbol: %w", err)
+	}
+	return true, nil
+}
+
+// filterSignal marks a signal as filtered and reports true when the chat only wants signals for
+// its watchlist and the symbol is not on it. Filtered signals are kept for /signals without a message.
+func filterSignal(chatID int64, signal *AlertMessage) bool {
+	if !userSettings.Get(chatID).WatchlistOnly {
+		return false
+	}
+	watched, err := IsWatched(chatID, signal.Symbol)
+	if err != nil {
+		telegramLog.Error("Failed to check watchlist, sending signal anyway", "signal_id", signal.SignalID, "symbol", signal.Symbol, "error", err)
+		return false
+	}
+	if watched {
+		return false
+	}
+	signal.Filtered = true
+	telegramLog.Info("Signal not sent: not on the watchlist", "signal_id", signal.SignalID, "symbol", signal.Symbol, "chat_id", chatID)
+	return true
+}
+
+// handleWatchCommand adds symbols to the watchlist with "/watch BTCUSDT ETHUSDT", or lists it with "/watch".
+func handleWatchCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		showWatchlist(chatID)
+		return
+	}
+
+	for _, arg := range args {
+		if err := WatchSymbol(chatID, marketSymbol(arg)); err != nil {
+			telegramLog.Error("Failed to watch", "symbol", arg, "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to update the watchlist.")))
+			return
+		}
+	}
+	showWatchlist(chatID)
+}
+
+// handleUnwatchCommand removes symbols from the watchlist with "/unwatch BTCUSDT".
+func handleUnwatchCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Usage: /unwatch <symbol> [symbol...]")))
+		return
+	}
+
+	for _, arg := range args {
+		symbol := marketSymbol(arg)
+		removed, err := UnwatchSymbol(chatID, symbol)
+		if err != nil {
+			telegramLog.Error("Failed to unwatch", "symbol", symbol, "error", err)
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to update the watchlist.")))
+			return
+		}
+		if !removed {
+			bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "%s is not on your watchlist.", symbol)))
+		}
+	}
+	showWatchlist(chatID)
+}
+
+// showWatchlist lists the chat's watched symbols and whether other signals are filtered out.
+func showWatchlist(chatID int64) {
+	symbols, err := ListWatchedSymbols(chatID)
+	if err != nil {
+		telegramLog.Error("Failed to list watchlist", "error", err)
+		bot.Send(tgbotapi.NewMessage(chatID, tr(chatID, "Failed to load the watchlist.")))
+		return
+	}
+
+	var text string
+	if len(symbols) == 0 {
+		text = tr(chatID, "Your watchlist is empty. Add symbols with /watch BTCUSDT.")
+	} else {
+		text = tr(chatID, "<b>Watchlist:</b> %s\n", strings.Join(symbols, ", "))
+	}
+	if userSettings.Get(chatID).WatchlistOnly {
+		text += tr(chatID, "\nOnly signals for these symbols are sent. Others are listed by /signals.")
+	} else {
+		text += tr(chatID, "\nAll signals are sent. Enable Watchlist Only in /settings to filter them.")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+}
+
+// toggleWatchlistOnly toggles sending only signals for watched symbols.
+func toggleWatchlistOnly(chatID int64) {
+	settings := userSettings.Get(chatID)
+	settings.WatchlistOnly = !settings.WatchlistOnly
+	userSettings.Set(chatID, settings)
+
+	msg := tgbotapi.NewMessage(chatID, tr(chatID, "Watchlist Only has been %s.", enabledText(chatID, settings.WatchlistOnly)))
+	if _, err := bot.Send(msg); err != nil {
+		telegramLog.Error("Failed to send message", "chat_id", chatID, "error", err)
+	}
+
+	showSettingsMenu(chatID)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultWebhookArchiveDays is how long received webhook payloads are kept.
+// WEBHOOK_ARCHIVE_DAYS overrides it, and 0 keeps them forever.
+const defaultWebhookArchiveDays = 14
+
+// webhookArchivePageEntries is how many payloads the admin webhook archive page shows.
+const webhookArchivePageEntries = 100
+
+// redactedWebhookHeaders are left out of archived headers, so credentials sent by proxies or
+// misconfigured senders don't end up in the database.
+var redactedWebhookHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+}
+
+// WebhookPayload is a webhook request as it arrived, with what became of it, kept for finding
+// out why an alert did not produce a signal.
+type WebhookPayload struct {
+	ID          uint      `gorm:"primaryKey"`
+	CreatedAt   time.Time `gorm:"index"`
+	RemoteAddr  string    `gorm:"size:64"`
+	Headers     string    // "Name: value" lines, sorted, with credentials redacted
+	Body        string
+	Status      int    // HTTP status the sender got
+	Outcome     string `gorm:"index;size:16"` // WebhookAccepted, WebhookRejected or WebhookFailed
+	Detail      string // Why it was rejected or failed, or where the signal went
+	SignalID    string `gorm:"index;size:128"`
+	Symbol      string `gorm:"size:32"`
+	ReprocessOf uint   // Payload this one replayed from the admin panel, 0 for webhook requests
+	Admin       string `gorm:"size:64"` // Admin panel account that replayed it
+}
+
+// webhookResult is what processing a webhook body came to.
+type webhookResult struct {
+	Status   int
+	Outcome  string
+	Message  string // Response body
+	Detail   string
+	SignalID string
+	Symbol   string
+}
+
+// webhookHeaders renders request headers for the archive.
+func webhookHeaders(header http.Header) string {
+	var lines []string
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if redactedWebhookHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[redacted]"
+		}
+		lines = append(lines, name+": "+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// processWebhook parses a webhook body as an alert and sends it to Telegram.
+func processWebhook(ctx context.Context, body []byte) webhookResult {
+	// Parse the alert: the bot's JSON, a 3Commas signal or a text signal
+	alert, err := decodeWebhookAlert(body)
+	if err != nil {
+		webhookLog.Warn("Rejected webhook with invalid alert", "error", err)
+		return webhookResult{Status: http.StatusBadRequest, Outcome: WebhookRejected, Message: err.Error(), Detail: err.Error()}
+	}
+	result := webhookResult{SignalID: alert.SignalID, Symbol: alert.Symbol}
+
+	// Validate required fields
+	if alert.SignalID == "" || alert.Symbol == "" || alert.Time == "" {
+		webhookLog.Warn("Rejected webhook missing required fields", "signal_id", alert.SignalID, "symbol", alert.Symbol)
+		var missing []string
+		for field, value := range map[string]string{"signal_id": alert.SignalID, "symbol": alert.Symbol, "time": alert.Time} {
+			if value == "" {
+				missing = append(missing, field)
+			}
+		}
+		sort.Strings(missing)
+		result.Status, result.Outcome = http.StatusBadRequest, WebhookRejected
+		result.Message = "Invalid alert data: missing required fields"
+		result.Detail = "Missing " + strings.Join(missing, ", ")
+		return result
+	}
+
+	webhookLog.Info("Received alert", "signal_id", alert.SignalID, "symbol", alert.Symbol, "signal", alert.SignalType,
+		"entry", alert.EntryPrice, "source", alert.Source, "strategy", alert.Strategy)
+
+	// Send the message to Telegram
+	messageID, err := sendSignalMessage(ctx, alert)
+	if err != nil {
+		webhookLog.Error("Failed to send alert to Telegram", "signal_id", alert.SignalID, "symbol", alert.Symbol, "error", err)
+		result.Status, result.Outcome = http.StatusInternalServerError, WebhookFailed
+		result.Message = "Failed to send message to Telegram"
+		result.Detail = err.Error()
+		return result
+	}
+
+	result.Status, result.Outcome = http.StatusOK, WebhookAccepted
+	result.Message = "Alert received and processed"
+	switch {
+	case alert.Filtered:
+		result.Detail = fmt.Sprintf("Rejected by signal filter %q", alert.RejectedBy)
+	case messageID == 0:
+		result.Detail = "Held back from the signal chat by its watchlist, mute or quiet hours"
+	default:
+		result.Detail = "Sent to the signal chat"
+	}
+	return result
+}
+
+// archiveWebhook stores a processed webhook payload, logging failures, and removes payloads
+// older than WEBHOOK_ARCHIVE_DAYS now and then.
+func archiveWebhook(payload WebhookPayload, result webhookResult) {
+	payload.Status = result.Status
+	payload.Outcome = result.Outcome
+	payload.Detail = result.Detail
+	payload.SignalID = result.SignalID
+	payload.Symbol = result.Symbol
+	if err := db.Create(&payload).Error; err != nil {
+		webhookLog.Error("Failed to archive webhook payload", "error", err)
+	}
+	pruneWebhookPayloads()
+}
+
+var (
+	webhookPruneMu   sync.Mutex
+	lastWebhookPrune time.Time
+)
+
+// pruneWebhookPayloads removes expired payloads, at most once an hour.
+func pruneWebhookPayloads() {
+	days := envInt("WEBHOOK_ARCHIVE_DAYS", defaultWebhookArchiveDays)
+	if days <= 0 {
+		return
+	}
+	webhookPruneMu.Lock()
+	if time.Since(lastWebhookPrune) < time.Hour {
+		webhookPruneMu.Unlock()
+		return
+	}
+	lastWebhookPrune = time.Now()
+	webhookPruneMu.Unlock()
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	if err := db.Where("created_at < ?", cutoff).Delete(&WebhookPayload{}).Error; err != nil {
+		webhookLog.Error("Failed to prune webhook archive", "error", err)
+	}
+}
+
+// WebhookPayloadFilter narrows the webhook archive. Empty fields match everything.
+type WebhookPayloadFilter struct {
+	Outcome string
+	Search  string // Text in the body, signal ID, symbol or detail
+}
+
+// ListWebhookPayloads returns the most recent archived payloads matching the filter, newest first.
+func ListWebhookPayloads(filter WebhookPayloadFilter, limit int) ([]WebhookPayload, error) {
+	query := db.Order("id desc").Limit(limit)
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.Search != "" {
+		like := "%" + filter.Search + "%"
+		query = query.Where("body LIKE ? OR signal_id LIKE ? OR symbol LIKE ? OR detail LIKE ?", like, like, like, like)
+	}
+	var payloads []WebhookPayload
+	if err := query.Find(&payloads).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve webhook archive: %w", err)
+	}
+	return payloads, nil
+}
+
+// GetWebhookPayload returns an archived payload by ID.
+func GetWebhookPayload(id uint) (*WebhookPayload, error) {
+	var payload WebhookPayload
+	if err := db.First(&payload, id).Error; err != nil {
+		return nil, fmt.Errorf("webhook payload %d not found", id)
+	}
+	return &payload, nil
+}
+
+// reprocessWebhookPayload runs an archived payload through the webhook pipeline again, as if
+// it had just arrived, and archives the replay with its own outcome.
+func reprocessWebhookPayload(ctx context.Context, id uint, admin string) (webhookResult, error) {
+	original, err := GetWebhookPayload(id)
+	if err != nil {
+		return webhookResult{}, err
+	}
+	result := processWebhook(ctx, []byte(original.Body))
+	archiveWebhook(WebhookPayload{
+		RemoteAddr:  original.RemoteAddr,
+		Headers:     original.Headers,
+		Body:        original.Body,
+		ReprocessOf: original.ID,
+		Admin:       admin,
+	}, result)
+	return result, nil
+}